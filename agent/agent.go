@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -20,6 +21,17 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// Started is called, if set, once all plugins are started and the agent
+	// is running.
+	Started func()
+
+	paused int32
+
+	flushLock sync.Mutex
+	// flushRequests holds a channel per running output loop, Flush puts a
+	// request into every channel unless one is pending already.
+	flushRequests map[chan struct{}]bool
 }
 
 // NewAgent returns an Agent for the given Config.
@@ -30,6 +42,62 @@ func NewAgent(config *config.Config) (*Agent, error) {
 	return a, nil
 }
 
+// Pause suspends gathering metrics from the inputs and flushing them to the
+// outputs until Resume is called. Service inputs keep running and buffered
+// metrics are kept, so no metrics are lost while the agent is paused.
+func (a *Agent) Pause() {
+	atomic.StoreInt32(&a.paused, 1)
+	log.Printf("I! [agent] Paused")
+}
+
+// Resume continues gathering and flushing metrics after Pause.
+func (a *Agent) Resume() {
+	atomic.StoreInt32(&a.paused, 0)
+	log.Printf("I! [agent] Resumed")
+}
+
+// Paused returns true if the agent is paused.
+func (a *Agent) Paused() bool {
+	return atomic.LoadInt32(&a.paused) != 0
+}
+
+// Flush writes the buffered metrics of all outputs immediately, also if the
+// agent is paused.
+func (a *Agent) Flush() {
+	a.flushLock.Lock()
+	defer a.flushLock.Unlock()
+
+	log.Printf("I! [agent] Flushing outputs on request")
+	for requests := range a.flushRequests {
+		select {
+		case requests <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// watchFlushRequests returns a channel receiving the requests of Flush. The
+// channel keeps a request made while the output is being written, so it is
+// not missed. Call stopFlushRequests once done.
+func (a *Agent) watchFlushRequests() chan struct{} {
+	a.flushLock.Lock()
+	defer a.flushLock.Unlock()
+
+	if a.flushRequests == nil {
+		a.flushRequests = make(map[chan struct{}]bool)
+	}
+	requests := make(chan struct{}, 1)
+	a.flushRequests[requests] = true
+	return requests
+}
+
+func (a *Agent) stopFlushRequests(requests chan struct{}) {
+	a.flushLock.Lock()
+	defer a.flushLock.Unlock()
+
+	delete(a.flushRequests, requests)
+}
+
 // inputUnit is a group of input plugins and the shared channel they write to.
 //
 // ┌───────┐
@@ -107,6 +175,9 @@ func (a *Agent) Run(ctx context.Context) error {
 		return err
 	}
 
+	log.Printf("D! [agent] Restoring the state of the inputs")
+	restoreState(a.Config.Inputs)
+
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
@@ -177,12 +248,38 @@ func (a *Agent) Run(ctx context.Context) error {
 		a.runInputs(ctx, startTime, iu)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		checkpointState(ctx, time.Duration(a.Config.Agent.FlushInterval), a.Config.Inputs)
+	}()
+
+	if a.Started != nil {
+		a.Started()
+	}
+
 	wg.Wait()
 
+	log.Printf("D! [agent] Saving the state of the inputs")
+	saveState(a.Config.Inputs)
+
 	log.Printf("D! [agent] Stopped Successfully")
 	return err
 }
 
+// SaveState saves the state of the inputs immediately, e.g. before exiting
+// without waiting for the agent to stop.
+func (a *Agent) SaveState() {
+	log.Printf("D! [agent] Saving the state of the inputs")
+	saveState(a.Config.Inputs)
+}
+
+// Validate runs the Init function on all plugins without starting them, to
+// detect config errors only reported by the plugins before running the agent.
+func (a *Agent) Validate() error {
+	return a.initPlugins()
+}
+
 // initPlugins runs the Init function on plugins.
 func (a *Agent) initPlugins() error {
 	for _, input := range a.Config.Inputs {
@@ -446,6 +543,9 @@ func (a *Agent) gatherLoop(
 	for {
 		select {
 		case <-ticker.Elapsed():
+			if a.Paused() {
+				continue
+			}
 			err := a.gatherOnce(acc, input, ticker, interval)
 			if err != nil {
 				acc.AddError(err)
@@ -788,6 +888,10 @@ func (a *Agent) flushLoop(
 	watchForFlushSignal(flushRequested)
 	defer stopListeningForFlushSignal(flushRequested)
 
+	// watch for flush requests of the service
+	requests := a.watchFlushRequests()
+	defer a.stopFlushRequests(requests)
+
 	for {
 		// Favor shutdown over other methods.
 		select {
@@ -802,10 +906,18 @@ func (a *Agent) flushLoop(
 			logError(a.flushOnce(output, ticker, output.Write))
 			return
 		case <-ticker.Elapsed():
+			if a.Paused() {
+				continue
+			}
 			logError(a.flushOnce(output, ticker, output.Write))
 		case <-flushRequested:
 			logError(a.flushOnce(output, ticker, output.Write))
+		case <-requests:
+			logError(a.flushOnce(output, ticker, output.Write))
 		case <-output.BatchReady:
+			if a.Paused() {
+				continue
+			}
 			// Favor the ticker over batch ready
 			select {
 			case <-ticker.Elapsed():
@@ -957,8 +1069,8 @@ func (a *Agent) Once(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
-	if models.GlobalGatherErrors.Get() != 0 {
-		return fmt.Errorf("input plugins recorded %d errors", models.GlobalGatherErrors.Get())
+	if n := models.GlobalGatherErrors.Get(); n != 0 {
+		return &GatherError{Count: n}
 	}
 
 	unsent := 0
@@ -966,11 +1078,30 @@ func (a *Agent) Once(ctx context.Context, wait time.Duration) error {
 		unsent += output.BufferLength()
 	}
 	if unsent != 0 {
-		return fmt.Errorf("output plugins unable to send %d metrics", unsent)
+		return &UnsentError{Count: unsent}
 	}
 	return nil
 }
 
+// GatherError is returned by Once if input plugins recorded errors.
+type GatherError struct {
+	Count int64
+}
+
+func (e *GatherError) Error() string {
+	return fmt.Sprintf("input plugins recorded %d errors", e.Count)
+}
+
+// UnsentError is returned by Once if output plugins were unable to send all
+// metrics.
+type UnsentError struct {
+	Count int
+}
+
+func (e *UnsentError) Error() string {
+	return fmt.Sprintf("output plugins unable to send %d metrics", e.Count)
+}
+
 // On runs the agent and performs a single gather sending output to the
 // outputF.  After gathering pauses for the wait duration to allow service
 // inputs to run.
@@ -981,6 +1112,9 @@ func (a *Agent) once(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
+	log.Printf("D! [agent] Restoring the state of the inputs")
+	restoreState(a.Config.Inputs)
+
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
@@ -1050,6 +1184,9 @@ func (a *Agent) once(ctx context.Context, wait time.Duration) error {
 
 	wg.Wait()
 
+	log.Printf("D! [agent] Saving the state of the inputs")
+	saveState(a.Config.Inputs)
+
 	log.Printf("D! [agent] Stopped Successfully")
 
 	return nil
@@ -1,12 +1,16 @@
 package agent
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
+	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -166,3 +170,174 @@ func TestWindow(t *testing.T) {
 		})
 	}
 }
+
+type manualTicker chan time.Time
+
+func (t manualTicker) Elapsed() <-chan time.Time {
+	return t
+}
+
+func (t manualTicker) Stop() {}
+
+type gatherNotifier struct {
+	gathered chan struct{}
+}
+
+func (g *gatherNotifier) SampleConfig() string {
+	return ""
+}
+
+func (g *gatherNotifier) Description() string {
+	return ""
+}
+
+func (g *gatherNotifier) Gather(telegraf.Accumulator) error {
+	g.gathered <- struct{}{}
+	return nil
+}
+
+type writeNotifier struct {
+	written chan struct{}
+}
+
+func (w *writeNotifier) SampleConfig() string {
+	return ""
+}
+
+func (w *writeNotifier) Description() string {
+	return ""
+}
+
+func (w *writeNotifier) Connect() error {
+	return nil
+}
+
+func (w *writeNotifier) Close() error {
+	return nil
+}
+
+func (w *writeNotifier) Write(metrics []telegraf.Metric) error {
+	if len(metrics) > 0 {
+		w.written <- struct{}{}
+	}
+	return nil
+}
+
+func TestAgent_Pause(t *testing.T) {
+	a, err := NewAgent(config.NewConfig())
+	require.NoError(t, err)
+
+	gathered := make(chan struct{}, 10)
+	input := models.NewRunningInput(&gatherNotifier{gathered: gathered}, &models.InputConfig{Name: "notifier"})
+	ticker := make(manualTicker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.gatherLoop(ctx, &testutil.Accumulator{}, input, ticker, time.Minute)
+	}()
+
+	a.Pause()
+	require.True(t, a.Paused())
+	// The second tick is only received after the first one was handled.
+	ticker <- time.Now()
+	ticker <- time.Now()
+	require.Len(t, gathered, 0)
+
+	a.Resume()
+	require.False(t, a.Paused())
+	ticker <- time.Now()
+	select {
+	case <-gathered:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "input not gathered after resume")
+	}
+
+	cancel()
+	<-done
+}
+
+// blockingWriter notifies about every write and blocks it until released.
+type blockingWriter struct {
+	writeNotifier
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(metrics []telegraf.Metric) error {
+	w.written <- struct{}{}
+	<-w.release
+	return nil
+}
+
+func TestAgent_Flush(t *testing.T) {
+	a, err := NewAgent(config.NewConfig())
+	require.NoError(t, err)
+
+	written := make(chan struct{}, 10)
+	output := models.NewRunningOutput(&writeNotifier{written: written}, &models.OutputConfig{Name: "notifier"}, 1000, 10000)
+	output.AddMetric(testutil.TestMetric(42))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.flushLoop(ctx, output, make(manualTicker))
+	}()
+
+	// Flushing also applies to paused agents. Requests issued before the
+	// loop is started are lost, so retry until the output is written.
+	a.Pause()
+	require.Eventually(t, func() bool {
+		a.Flush()
+		return len(written) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestAgent_FlushWhileWriting(t *testing.T) {
+	a, err := NewAgent(config.NewConfig())
+	require.NoError(t, err)
+
+	plugin := &blockingWriter{
+		writeNotifier: writeNotifier{written: make(chan struct{}, 10)},
+		release:       make(chan struct{}),
+	}
+	output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "blocking"}, 1000, 10000)
+	output.AddMetric(testutil.TestMetric(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.flushLoop(ctx, output, make(manualTicker))
+	}()
+	require.Eventually(t, func() bool {
+		a.flushLock.Lock()
+		defer a.flushLock.Unlock()
+		return len(a.flushRequests) == 1
+	}, 10*time.Second, time.Millisecond)
+
+	a.Flush()
+	select {
+	case <-plugin.written:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "output not written on flush")
+	}
+
+	// A flush requested while the output is written is not lost
+	output.AddMetric(testutil.TestMetric(2))
+	a.Flush()
+	plugin.release <- struct{}{}
+	select {
+	case <-plugin.written:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "flush requested while writing was lost")
+	}
+	plugin.release <- struct{}{}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,187 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
+)
+
+// stateFile is the file in the state directory of the agent the state of the
+// inputs is saved in.
+const stateFile = "inputs.json"
+
+// stateLock serializes saving the state, which reads and replaces the file.
+var stateLock sync.Mutex
+
+// statefulInputs returns the inputs implementing telegraf.StatefulPlugin by
+// instance id. The id is the name and the alias of the input, so it does not
+// depend on the order of the configuration. Ids shared by several instances,
+// e.g. without alias, are ambiguous; their state is neither restored nor
+// saved, as one instance could get the state of another one. The ambiguous
+// ids are returned in the second value.
+func statefulInputs(inputs []*models.RunningInput) (map[string]telegraf.StatefulPlugin, []string) {
+	plugins := make(map[string]telegraf.StatefulPlugin)
+	seen := make(map[string]int)
+	for _, input := range inputs {
+		p, ok := input.Input.(telegraf.StatefulPlugin)
+		if !ok {
+			continue
+		}
+		id := input.LogName()
+		seen[id]++
+		plugins[id] = p
+	}
+
+	var ambiguous []string
+	for id, n := range seen {
+		if n > 1 {
+			delete(plugins, id)
+			ambiguous = append(ambiguous, id)
+		}
+	}
+	sort.Strings(ambiguous)
+	return plugins, ambiguous
+}
+
+// restoreState restores the state of the inputs saved by saveState. Inputs
+// whose state cannot be restored start without state.
+func restoreState(inputs []*models.RunningInput) {
+	plugins, ambiguous := statefulInputs(inputs)
+	for _, id := range ambiguous {
+		log.Printf("W! [agent] Not restoring or saving the state of %s, as several instances share the name, set an alias to tell them apart", id)
+	}
+	if len(plugins) == 0 {
+		return
+	}
+
+	states, err := readStates()
+	if errors.Is(err, internal.ErrNoStateDirectory) {
+		log.Printf("D! [agent] Not restoring the state of the inputs: %v", err)
+		return
+	}
+	if err != nil {
+		log.Printf("E! [agent] Reading the state of the inputs failed: %v", err)
+		return
+	}
+	for id, p := range plugins {
+		state, ok := states[id]
+		if !ok {
+			continue
+		}
+		if err := p.SetState(state); err != nil {
+			log.Printf("E! [agent] Restoring the state of %s failed: %v", id, err)
+			continue
+		}
+		log.Printf("D! [agent] Restored the state of %s", id)
+	}
+}
+
+// checkpointState saves the state of the running inputs every interval until
+// the context is done, so little is lost if the agent is killed.
+func checkpointState(ctx context.Context, interval time.Duration, inputs []*models.RunningInput) {
+	if plugins, _ := statefulInputs(inputs); interval <= 0 || len(plugins) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			saveState(inputs)
+		}
+	}
+}
+
+// saveState saves the state of the inputs. The state of inputs not running,
+// e.g. removed from the configuration for a test, is kept.
+func saveState(inputs []*models.RunningInput) {
+	plugins, _ := statefulInputs(inputs)
+	if len(plugins) == 0 {
+		return
+	}
+
+	stateLock.Lock()
+	defer stateLock.Unlock()
+
+	states, err := readStates()
+	if errors.Is(err, internal.ErrNoStateDirectory) {
+		log.Printf("D! [agent] Not saving the state of the inputs: %v", err)
+		return
+	}
+	if err != nil {
+		log.Printf("W! [agent] Reading the state of the inputs failed, overwriting it: %v", err)
+		states = make(map[string][]byte)
+	}
+	for id, p := range plugins {
+		state, err := p.GetState()
+		if err != nil {
+			log.Printf("E! [agent] Getting the state of %s failed: %v", id, err)
+			continue
+		}
+		states[id] = state
+	}
+	if err := writeStates(states); err != nil {
+		log.Printf("E! [agent] Saving the state of the inputs failed: %v", err)
+	}
+}
+
+// readStates returns the saved state of the inputs by id.
+func readStates() (map[string][]byte, error) {
+	dir, err := internal.StateDirectory("agent")
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string][]byte), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string][]byte)
+	if err := json.Unmarshal(content, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// writeStates replaces the saved state of the inputs. The file is replaced
+// at once, so a crash while saving keeps the previous state.
+func writeStates(states map[string][]byte) error {
+	dir, err := internal.StateDirectory("agent")
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, stateFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, stateFile))
+}
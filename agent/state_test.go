@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/models"
+)
+
+type statefulInput struct {
+	state string
+}
+
+func (i *statefulInput) Description() string                 { return "" }
+func (i *statefulInput) SampleConfig() string                { return "" }
+func (i *statefulInput) Gather(_ telegraf.Accumulator) error { return nil }
+func (i *statefulInput) GetState() ([]byte, error)           { return []byte(i.state), nil }
+func (i *statefulInput) SetState(state []byte) error         { i.state = string(state); return nil }
+
+func newStatefulInputs() ([]*models.RunningInput, []*statefulInput) {
+	plugins := []*statefulInput{{}, {}, {}}
+	inputs := []*models.RunningInput{
+		models.NewRunningInput(plugins[0], &models.InputConfig{Name: "test"}),
+		models.NewRunningInput(plugins[1], &models.InputConfig{Name: "test", Alias: "other"}),
+		models.NewRunningInput(plugins[2], &models.InputConfig{Name: "test", Alias: "second"}),
+	}
+	return inputs, plugins
+}
+
+func TestState(t *testing.T) {
+	defer internal.SetStateDirectory("")
+	dir := t.TempDir()
+	internal.SetStateDirectory(dir)
+
+	inputs, plugins := newStatefulInputs()
+	ids, ambiguous := statefulInputs(inputs)
+	require.ElementsMatch(t, []string{"inputs.test", "inputs.test::other", "inputs.test::second"}, keysOf(ids))
+	require.Empty(t, ambiguous)
+
+	// Nothing is restored before the state is saved the first time
+	restoreState(inputs)
+	for _, p := range plugins {
+		require.Empty(t, p.state)
+	}
+
+	plugins[0].state = "first"
+	plugins[1].state = "alias"
+	plugins[2].state = "second"
+	saveState(inputs)
+	require.FileExists(t, filepath.Join(dir, "agent", stateFile))
+
+	// The state of inputs not configured anymore is kept
+	saveState(inputs[:1])
+
+	restored, plugins := newStatefulInputs()
+	restoreState(restored)
+	require.Equal(t, "first", plugins[0].state)
+	require.Equal(t, "alias", plugins[1].state)
+	require.Equal(t, "second", plugins[2].state)
+}
+
+func TestStateOrderIndependent(t *testing.T) {
+	defer internal.SetStateDirectory("")
+	internal.SetStateDirectory(t.TempDir())
+
+	inputs, plugins := newStatefulInputs()
+	plugins[0].state = "first"
+	plugins[1].state = "alias"
+	plugins[2].state = "second"
+	saveState(inputs)
+
+	// Reordering the configuration keeps the state with its instance
+	restored, plugins := newStatefulInputs()
+	restored[0], restored[2] = restored[2], restored[0]
+	restoreState(restored)
+	require.Equal(t, "first", plugins[0].state)
+	require.Equal(t, "alias", plugins[1].state)
+	require.Equal(t, "second", plugins[2].state)
+}
+
+func TestStateAmbiguous(t *testing.T) {
+	defer internal.SetStateDirectory("")
+	internal.SetStateDirectory(t.TempDir())
+
+	plugins := []*statefulInput{{state: "first"}, {state: "second"}, {state: "alias"}}
+	inputs := []*models.RunningInput{
+		models.NewRunningInput(plugins[0], &models.InputConfig{Name: "test"}),
+		models.NewRunningInput(plugins[1], &models.InputConfig{Name: "test"}),
+		models.NewRunningInput(plugins[2], &models.InputConfig{Name: "test", Alias: "other"}),
+	}
+	ids, ambiguous := statefulInputs(inputs)
+	require.Equal(t, []string{"inputs.test::other"}, keysOf(ids))
+	require.Equal(t, []string{"inputs.test"}, ambiguous)
+
+	// Instances without a distinct id are neither saved nor restored
+	saveState(inputs)
+	states, err := readStates()
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	require.Contains(t, states, "inputs.test::other")
+
+	require.NoError(t, writeStates(map[string][]byte{"inputs.test": []byte("saved")}))
+	for _, p := range plugins {
+		p.state = ""
+	}
+	restoreState(inputs)
+	require.Empty(t, plugins[0].state)
+	require.Empty(t, plugins[1].state)
+}
+
+func TestStateCorrupted(t *testing.T) {
+	defer internal.SetStateDirectory("")
+	dir := t.TempDir()
+	internal.SetStateDirectory(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "agent"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "agent", stateFile), []byte("{"), 0600))
+
+	inputs, plugins := newStatefulInputs()
+	restoreState(inputs)
+	require.Empty(t, plugins[0].state)
+
+	// The corrupted state is replaced
+	plugins[0].state = "first"
+	saveState(inputs)
+
+	restored, plugins := newStatefulInputs()
+	restoreState(restored)
+	require.Equal(t, "first", plugins[0].state)
+}
+
+func TestStateCheckpoint(t *testing.T) {
+	defer internal.SetStateDirectory("")
+	dir := t.TempDir()
+	internal.SetStateDirectory(dir)
+
+	inputs, plugins := newStatefulInputs()
+	plugins[0].state = "first"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		checkpointState(ctx, 10*time.Millisecond, inputs)
+	}()
+
+	// The state is saved while the inputs are running
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "agent", stateFile))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	restored, plugins := newStatefulInputs()
+	restoreState(restored)
+	require.Equal(t, "first", plugins[0].state)
+}
+
+func keysOf(m map[string]telegraf.StatefulPlugin) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,169 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Microsoft/go-winio"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// controlPipeSDDL restricts access to the control pipe to the local
+// administrators and the local system account.
+const controlPipeSDDL = "D:P(A;;GA;;;BA)(A;;GA;;;SY)"
+
+// controlTimeout limits the time to read a command from and to write the
+// response to a control pipe client.
+const controlTimeout = 10 * time.Second
+
+// controlErrorPrefix marks responses of failed commands.
+const controlErrorPrefix = "error: "
+
+// controlPipeName returns the path of the control pipe of the given service.
+func controlPipeName(name string) string {
+	return `\\.\pipe\` + name + `-control`
+}
+
+// serveControl listens on the control pipe of the service and handles the
+// commands of the connecting clients until the returned listener is closed.
+// Every client sends a single command line and receives the response until
+// the pipe is closed.
+func (h *serviceHandler) serveControl(name string) (io.Closer, error) {
+	l, err := winio.ListenPipe(controlPipeName(name), &winio.PipeConfig{
+		SecurityDescriptor: controlPipeSDDL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				if !errors.Is(err, winio.ErrPipeListenerClosed) {
+					log.Printf("E! Accepting control pipe connection failed: %v", err)
+				}
+				return
+			}
+			go h.handleControl(conn)
+		}
+	}()
+	return l, nil
+}
+
+func (h *serviceHandler) handleControl(conn net.Conn) {
+	defer conn.Close()
+
+	//nolint:errcheck,revive // deadlines are not supported by all pipes
+	conn.SetDeadline(time.Now().Add(controlTimeout))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && line == "" {
+		log.Printf("W! Reading control command failed: %v", err)
+		return
+	}
+
+	command := strings.TrimSpace(line)
+	response, err := h.control(command)
+	if err != nil {
+		log.Printf("W! Control command %q failed: %v", command, err)
+		response = controlErrorPrefix + err.Error() + "\n"
+	}
+	if _, err := io.WriteString(conn, response); err != nil {
+		log.Printf("W! Writing control response failed: %v", err)
+	}
+}
+
+// control executes the command and returns the response.
+func (h *serviceHandler) control(command string) (string, error) {
+	h.Lock()
+	ag, paused := h.agent, h.paused
+	h.Unlock()
+
+	switch command {
+	case "reload":
+		requestReload()
+		return "reload requested\n", nil
+	case "flush":
+		if ag == nil {
+			return "", errors.New("agent is not running")
+		}
+		ag.Flush()
+		return "flush requested\n", nil
+	case "status":
+		state := "starting"
+		switch {
+		case ag == nil:
+		case paused:
+			state = "paused"
+		default:
+			state = "running"
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "state: %s\n", state)
+		fmt.Fprintf(&b, "version: %s\n", internal.Version())
+		fmt.Fprintf(&b, "pid: %d\n", os.Getpid())
+		fmt.Fprintf(&b, "uptime: %s\n", time.Since(h.started).Truncate(time.Second))
+		if ag != nil {
+			fmt.Fprintf(&b, "inputs: %s\n", strings.Join(ag.Config.InputNames(), " "))
+			fmt.Fprintf(&b, "outputs: %s\n", strings.Join(ag.Config.OutputNames(), " "))
+		}
+		return b.String(), nil
+	case "plugin-stats":
+		serializer := influx.NewSerializer()
+		serializer.SetFieldSortOrder(influx.SortFields)
+		buf, err := serializer.SerializeBatch(selfstat.Metrics())
+		if err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	case "":
+		return "", errors.New("missing command")
+	default:
+		return "", fmt.Errorf("unknown command %q, use one of reload, flush, status or plugin-stats", command)
+	}
+}
+
+// sendControl sends the command to the control pipe of the running service
+// with the given name and prints the response. It returns the exit code.
+func sendControl(name, command string) int {
+	timeout := controlTimeout
+	conn, err := winio.DialPipe(controlPipeName(name), &timeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Connecting to service %q failed: %v\n", name, err)
+		return 1
+	}
+	defer conn.Close()
+
+	//nolint:errcheck,revive // deadlines are not supported by all pipes
+	conn.SetDeadline(time.Now().Add(controlTimeout))
+	if _, err := io.WriteString(conn, command+"\n"); err != nil {
+		fmt.Fprintf(os.Stderr, "Sending command failed: %v\n", err)
+		return 1
+	}
+
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Reading response failed: %v\n", err)
+		return 1
+	}
+	if strings.HasPrefix(string(response), controlErrorPrefix) {
+		fmt.Fprint(os.Stderr, string(response))
+		return 1
+	}
+	fmt.Print(string(response))
+	return 0
+}
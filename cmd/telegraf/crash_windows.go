@@ -0,0 +1,128 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf/logger"
+)
+
+// miniDumpType selects the content of the minidump: the data segments, the
+// handles and the thread information but not the full memory.
+const miniDumpType = 0x1 | 0x4 | 0x1000
+
+var procMiniDumpWriteDump = windows.NewLazySystemDLL("dbghelp.dll").NewProc("MiniDumpWriteDump")
+
+// crashFile is the file in the dump directory the standard error of the
+// service is redirected to.
+const crashFile = "telegraf-crash.log"
+
+// redirectCrashOutput redirects the standard error of the process to the
+// crash file in the directory. The runtime writes the stack traces of panics
+// in any goroutine and of fatal errors to standard error, which is discarded
+// for services otherwise. Panics of the agent goroutine are additionally
+// handled by recoverServicePanic.
+func redirectCrashOutput(dir string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, crashFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	if err := windows.SetStdHandle(windows.STD_ERROR_HANDLE, windows.Handle(f.Fd())); err != nil {
+		f.Close()
+		return err
+	}
+	// The file is kept open until the process exits.
+	os.Stderr = f
+	fmt.Fprintf(f, "Telegraf service started at %s\n", time.Now().Format(time.RFC3339))
+	return nil
+}
+
+// recoverServicePanic handles a panic of the service by writing the stack
+// trace to the event log and, if a dump directory is configured, a dump of all
+// goroutines and a minidump of the process before exiting. The exit code makes
+// the service control manager apply the configured recovery actions.
+func recoverServicePanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	msg := fmt.Sprintf("Telegraf service panicked: %v\n\n%s", r, debug.Stack())
+	if err := logger.ReportCrash(*fServiceName, msg); err != nil {
+		log.Printf("E! Reporting the crash to the event log failed: %v", err)
+	}
+	log.Printf("E! FATAL: %s", msg)
+
+	if *fServiceDumpDir != "" {
+		if err := writeCrashDumps(*fServiceDumpDir); err != nil {
+			log.Printf("E! Writing crash dumps failed: %v", err)
+		}
+	}
+	os.Exit(2)
+}
+
+// writeCrashDumps writes the stack traces of all goroutines and a minidump of
+// the process to files named after the current time into the directory.
+func writeCrashDumps(dir string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	base := filepath.Join(dir, "telegraf-"+time.Now().Format("20060102-150405"))
+
+	if err := os.WriteFile(base+".txt", goroutineStacks(), 0640); err != nil {
+		return err
+	}
+	if err := writeMiniDump(base + ".dmp"); err != nil {
+		return fmt.Errorf("writing minidump failed: %w", err)
+	}
+	log.Printf("I! Crash dumps written to %s.*", base)
+	return nil
+}
+
+// goroutineStacks returns the stack traces of all goroutines.
+func goroutineStacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func writeMiniDump(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := procMiniDumpWriteDump.Find(); err != nil {
+		return err
+	}
+	r, _, err := procMiniDumpWriteDump.Call(
+		uintptr(windows.CurrentProcess()),
+		uintptr(windows.GetCurrentProcessId()),
+		f.Fd(),
+		miniDumpType,
+		0, 0, 0,
+	)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
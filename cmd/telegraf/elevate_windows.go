@@ -0,0 +1,129 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Flags of the SHELLEXECUTEINFO structure.
+const (
+	seeMaskNoCloseProcess = 0x00000040
+	seeMaskNoAsync        = 0x00000100
+)
+
+var procShellExecuteExW = windows.NewLazySystemDLL("shell32.dll").NewProc("ShellExecuteExW")
+
+// shellExecuteInfo is the SHELLEXECUTEINFOW structure.
+type shellExecuteInfo struct {
+	size       uint32
+	mask       uint32
+	hwnd       windows.Handle
+	verb       *uint16
+	file       *uint16
+	parameters *uint16
+	directory  *uint16
+	show       int32
+	instApp    windows.Handle
+	idList     uintptr
+	class      *uint16
+	keyClass   windows.Handle
+	hotKey     uint32
+	icon       windows.Handle
+	process    windows.Handle
+}
+
+// elevatedServiceOperations are the service operations requiring
+// administrator rights.
+var elevatedServiceOperations = map[string]bool{
+	"install":   true,
+	"uninstall": true,
+	"start":     true,
+	"stop":      true,
+	"restart":   true,
+	"update":    true,
+	"upgrade":   true,
+	"control":   true,
+}
+
+// isElevated returns true if the process runs with administrator rights.
+func isElevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// checkElevation fails with an actionable error, or relaunches the process
+// elevated if requested, before a service operation fails with access denied
+// half way through.
+func checkElevation(operation string) {
+	if !elevatedServiceOperations[operation] || isElevated() {
+		return
+	}
+	if *fElevate {
+		os.Exit(runElevated())
+	}
+	fmt.Fprintf(os.Stderr, "E! The service operation %q requires administrator rights. "+
+		"Run it from a command prompt started with \"Run as administrator\" or add the --elevate flag.\n", operation)
+	os.Exit(1)
+}
+
+// runElevated runs the executable with the same arguments elevated, showing
+// the UAC prompt, waits for it to exit and returns its exit code. The elevated
+// process runs in a new console window.
+func runElevated() int {
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E! Determining executable failed: %v\n", err)
+		return 1
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "E! Determining working directory failed: %v\n", err)
+		return 1
+	}
+
+	args := make([]string, 0, len(os.Args)-1)
+	for _, arg := range os.Args[1:] {
+		// The elevated process must not try to elevate again.
+		if arg == "--elevate" || arg == "-elevate" || strings.HasPrefix(arg, "--elevate=") || strings.HasPrefix(arg, "-elevate=") {
+			continue
+		}
+		args = append(args, syscall.EscapeArg(arg))
+	}
+
+	info := shellExecuteInfo{
+		mask:       seeMaskNoCloseProcess | seeMaskNoAsync,
+		verb:       windows.StringToUTF16Ptr("runas"),
+		file:       windows.StringToUTF16Ptr(exe),
+		parameters: windows.StringToUTF16Ptr(strings.Join(args, " ")),
+		directory:  windows.StringToUTF16Ptr(cwd),
+		show:       windows.SW_SHOWNORMAL,
+	}
+	info.size = uint32(unsafe.Sizeof(info))
+	if r, _, err := procShellExecuteExW.Call(uintptr(unsafe.Pointer(&info))); r == 0 {
+		// Declining the UAC prompt is reported as ERROR_CANCELLED.
+		fmt.Fprintf(os.Stderr, "E! Starting elevated process failed: %v\n", err)
+		return 1
+	}
+	defer windows.CloseHandle(info.process) //nolint:errcheck // nothing to do on error
+
+	if _, err := windows.WaitForSingleObject(info.process, windows.INFINITE); err != nil {
+		fmt.Fprintf(os.Stderr, "E! Waiting for elevated process failed: %v\n", err)
+		return 1
+	}
+	var code uint32
+	if err := windows.GetExitCodeProcess(info.process, &code); err != nil {
+		fmt.Fprintf(os.Stderr, "E! Querying exit code of elevated process failed: %v\n", err)
+		return 1
+	}
+	if code != 0 {
+		fmt.Fprintf(os.Stderr, "E! Elevated process exited with code %d\n", code)
+	}
+	return int(code)
+}
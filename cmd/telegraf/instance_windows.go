@@ -0,0 +1,64 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var instanceNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// applyInstance derives the service name, the display name and the default
+// config paths from the instance name, so several isolated agents can run on
+// one host. Explicitly given flags take precedence. The event log source is
+// named after the service and thus unique per instance as well.
+func applyInstance() error {
+	if *fInstance == "" {
+		return nil
+	}
+	if !instanceNameRe.MatchString(*fInstance) {
+		return fmt.Errorf("invalid instance name %q, only letters, digits, '-' and '_' are allowed", *fInstance)
+	}
+
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if !set["service-name"] {
+		*fServiceName = "telegraf-" + *fInstance
+	}
+	if !set["service-display-name"] {
+		*fServiceDisplayName = fmt.Sprintf("Telegraf Data Collector Service (%s)", *fInstance)
+	}
+
+	if len(fConfigs) == 0 && len(fConfigDirs) == 0 {
+		dir := instanceDir(*fInstance)
+		fConfigs = append(fConfigs, filepath.Join(dir, "telegraf.conf"))
+		if info, err := os.Stat(filepath.Join(dir, "telegraf.d")); err == nil && info.IsDir() {
+			fConfigDirs = append(fConfigDirs, filepath.Join(dir, "telegraf.d"))
+		}
+	}
+	return nil
+}
+
+// defaultStateDirectory returns the state directory of the instance or, if no
+// instance is given, of the service name, so services never share their state.
+func defaultStateDirectory() string {
+	if *fInstance != "" {
+		return filepath.Join(instanceDir(*fInstance), "state")
+	}
+	return filepath.Join(instanceDir(*fServiceName), "state")
+}
+
+// instanceDir returns the directory of the files of the instance.
+func instanceDir(instance string) string {
+	programData := os.Getenv("ProgramData")
+	if programData == "" { // Should never happen
+		programData = "C:\\ProgramData"
+	}
+	return filepath.Join(programData, "Telegraf", instance)
+}
@@ -0,0 +1,74 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyInstance(t *testing.T) {
+	t.Setenv("ProgramData", `C:\Data`)
+
+	tests := []struct {
+		name        string
+		instance    string
+		expectedErr string
+		serviceName string
+		displayName string
+		configs     []string
+		stateDir    string
+	}{
+		{
+			name:        "no instance",
+			serviceName: "telegraf",
+			displayName: "Telegraf Data Collector Service",
+			stateDir:    filepath.Join(`C:\Data`, "Telegraf", "telegraf", "state"),
+		},
+		{
+			name:        "instance",
+			instance:    "edge_1",
+			serviceName: "telegraf-edge_1",
+			displayName: "Telegraf Data Collector Service (edge_1)",
+			configs:     []string{filepath.Join(`C:\Data`, "Telegraf", "edge_1", "telegraf.conf")},
+			stateDir:    filepath.Join(`C:\Data`, "Telegraf", "edge_1", "state"),
+		},
+		{
+			name:        "path separator",
+			instance:    `..\edge`,
+			expectedErr: `invalid instance name "..\\edge"`,
+		},
+		{
+			name:        "space",
+			instance:    "edge 1",
+			expectedErr: `invalid instance name "edge 1"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(instance, serviceName, displayName string) {
+				*fInstance, *fServiceName, *fServiceDisplayName = instance, serviceName, displayName
+				fConfigs, fConfigDirs = nil, nil
+			}(*fInstance, *fServiceName, *fServiceDisplayName)
+
+			*fInstance = tt.instance
+			fConfigs, fConfigDirs = nil, nil
+
+			err := applyInstance()
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.serviceName, *fServiceName)
+			require.Equal(t, tt.displayName, *fServiceDisplayName)
+			require.Equal(t, tt.configs, []string(fConfigs))
+			require.Empty(t, fConfigDirs)
+			require.Equal(t, tt.stateDir, defaultStateDirectory())
+		})
+	}
+}
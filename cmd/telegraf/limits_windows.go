@@ -0,0 +1,101 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// Flags of the JOBOBJECT_CPU_RATE_CONTROL_INFORMATION structure.
+const (
+	jobObjectCPURateControlEnable  = 0x1
+	jobObjectCPURateControlHardCap = 0x4
+)
+
+// jobObjectCPURateControlInformation is the
+// JOBOBJECT_CPU_RATE_CONTROL_INFORMATION structure using the CpuRate member
+// of the union, the CPU cycles per 10000 cycles.
+type jobObjectCPURateControlInformation struct {
+	ControlFlags uint32
+	CPURate      uint32
+}
+
+var (
+	jobLock sync.Mutex
+	// job is the job object the process is assigned to once limits are
+	// configured. A process cannot leave a job, so it is kept across config
+	// reloads and only its limits are updated.
+	job windows.Handle
+)
+
+// applyResourceLimits places the process in a job object limiting the memory
+// of every process and the CPU rate of all processes in the job. Processes
+// started by plugins are added to the job automatically.
+func applyResourceLimits(c *config.AgentConfig) error {
+	jobLock.Lock()
+	defer jobLock.Unlock()
+
+	if c.CPULimit < 0 || c.CPULimit > 100 {
+		return fmt.Errorf("cpu_limit must be between 0 and 100, got %v", c.CPULimit)
+	}
+	if c.MemoryLimit < 0 {
+		return fmt.Errorf("memory_limit must not be negative, got %d", c.MemoryLimit)
+	}
+	if job == 0 && c.MemoryLimit == 0 && c.CPULimit == 0 {
+		return nil
+	}
+
+	if job == 0 {
+		h, err := windows.CreateJobObject(nil, nil)
+		if err != nil {
+			return fmt.Errorf("creating job object failed: %w", err)
+		}
+		if err := windows.AssignProcessToJobObject(h, windows.CurrentProcess()); err != nil {
+			windows.CloseHandle(h) //nolint:errcheck,revive // nothing to do on error
+			return fmt.Errorf("assigning process to job object failed: %w", err)
+		}
+		job = h
+	}
+
+	var limits windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+	if c.MemoryLimit > 0 {
+		limits.BasicLimitInformation.LimitFlags = windows.JOB_OBJECT_LIMIT_PROCESS_MEMORY
+		limits.ProcessMemoryLimit = uintptr(c.MemoryLimit)
+	}
+	if _, err := windows.SetInformationJobObject(
+		job,
+		windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&limits)),
+		uint32(unsafe.Sizeof(limits)),
+	); err != nil {
+		return fmt.Errorf("setting memory limit failed: %w", err)
+	}
+
+	var rate jobObjectCPURateControlInformation
+	if c.CPULimit > 0 {
+		rate.ControlFlags = jobObjectCPURateControlEnable | jobObjectCPURateControlHardCap
+		rate.CPURate = uint32(c.CPULimit * 100)
+		if rate.CPURate == 0 {
+			rate.CPURate = 1
+		}
+	}
+	if _, err := windows.SetInformationJobObject(
+		job,
+		windows.JobObjectCpuRateControlInformation,
+		uintptr(unsafe.Pointer(&rate)),
+		uint32(unsafe.Sizeof(rate)),
+	); err != nil {
+		return fmt.Errorf("setting CPU limit failed: %w", err)
+	}
+
+	log.Printf("I! Resource limits: memory: %d bytes per process, CPU: %v%%", c.MemoryLimit, c.CPULimit)
+	return nil
+}
@@ -0,0 +1,404 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/influxdata/telegraf/agent"
+)
+
+// pendingWaitHint is the time the service control manager is told to wait
+// for the next checkpoint while the agent is starting or stopping. Checkpoints
+// are reported at half of that interval so slow plugin initialization or
+// flushing is not mistaken for a hanging service.
+const pendingWaitHint = 10 * time.Second
+
+// reloadControl is the user-defined service control code triggering a config
+// reload, e.g. using 'sc.exe control telegraf 130'.
+const reloadControl = svc.Cmd(130)
+
+// serviceStatePollInterval is the interval for checking the state of the
+// services to wait for before starting the agent.
+const serviceStatePollInterval = time.Second
+
+// serviceHandler runs the agent as a Windows service. It reports the start
+// progress to the service control manager until the agent is running and
+// stops the agent on request.
+type serviceHandler struct {
+	// name is the name of the service, also naming the control pipe.
+	name string
+
+	inputFilters  []string
+	outputFilters []string
+
+	// startDelay delays the start of the agent, e.g. to give the network
+	// time to come up on boot.
+	startDelay time.Duration
+	// waitFor are the names of the services which must be running before
+	// the agent is started.
+	waitFor []string
+	// startTimeout is the time to wait for all plugins to be started before
+	// reporting the service as running anyway, zero waits forever.
+	startTimeout time.Duration
+	// stopTimeout is the time to wait for the agent to stop, zero waits
+	// forever. It is also reported as wait hint while stopping, so the
+	// service control manager does not kill the agent while flushing.
+	stopTimeout time.Duration
+	// started is the time the service was started.
+	started time.Time
+	// run runs the agent until it is stopped, reloading it on request.
+	// Defaults to the reload loop using the filters.
+	run func()
+
+	sync.Mutex
+	// agent is the running agent, replaced on every config reload.
+	agent  *agent.Agent
+	paused bool
+}
+
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptPauseAndContinue
+
+	h.started = time.Now()
+	if l, err := h.serveControl(h.name); err != nil {
+		log.Printf("E! Listening on control pipe failed: %v", err)
+	} else {
+		defer l.Close()
+	}
+
+	started := make(chan struct{})
+	var once sync.Once
+	agentStarted = func(ag *agent.Agent) {
+		h.setAgent(ag)
+		once.Do(func() { close(started) })
+	}
+
+	run := h.run
+	if run == nil {
+		run = func() { reloadLoop(h.inputFilters, h.outputFilters) }
+	}
+
+	stop = make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer recoverServicePanic()
+		h.waitForStart()
+		run()
+	}()
+
+	status := svc.Status{
+		State:    svc.StartPending,
+		WaitHint: uint32(pendingWaitHint / time.Millisecond),
+	}
+	changes <- status
+
+	// The service is only reported as running once all outputs are
+	// connected and all inputs are started, so failing plugins are visible
+	// to the service manager and orchestration tools.
+	var timeout <-chan time.Time
+	if h.startTimeout > 0 {
+		timer := time.NewTimer(h.startTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	ticker := time.NewTicker(pendingWaitHint / 2)
+	for status.State == svc.StartPending {
+		select {
+		case <-started:
+			status = svc.Status{State: svc.Running, Accepts: accepts}
+		case <-timeout:
+			log.Printf("W! Plugins did not start within %s, reporting the service as running", h.startTimeout)
+			status = svc.Status{State: svc.Running, Accepts: accepts}
+		case <-done:
+			ticker.Stop()
+			return false, 0
+		case <-ticker.C:
+			status.CheckPoint++
+		case c := <-requests:
+			if c.Cmd != svc.Interrogate {
+				continue
+			}
+		}
+		changes <- status
+	}
+	ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return false, 0
+		case c := <-requests:
+			switch c.Cmd {
+			case svc.Interrogate:
+				changes <- c.CurrentStatus
+			case svc.Pause:
+				h.setPaused(true)
+				changes <- svc.Status{State: svc.Paused, Accepts: accepts}
+			case svc.Continue:
+				h.setPaused(false)
+				changes <- svc.Status{State: svc.Running, Accepts: accepts}
+			case reloadControl:
+				requestReload()
+			case svc.Stop, svc.Shutdown:
+				h.stopAgent(requests, changes, done)
+				return false, 0
+			}
+		}
+	}
+}
+
+// stopAgent stops the agent, which flushes the outputs, and reports the
+// progress until it is done or the stop timeout is exceeded.
+func (h *serviceHandler) stopAgent(requests <-chan svc.ChangeRequest, changes chan<- svc.Status, done <-chan struct{}) {
+	waitHint := pendingWaitHint
+	if h.stopTimeout > waitHint {
+		waitHint = h.stopTimeout
+	}
+	status := svc.Status{
+		State:    svc.StopPending,
+		WaitHint: uint32(waitHint / time.Millisecond),
+	}
+	changes <- status
+	close(stop)
+
+	var timeout <-chan time.Time
+	if h.stopTimeout > 0 {
+		timer := time.NewTimer(h.stopTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	ticker := time.NewTicker(pendingWaitHint / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-timeout:
+			log.Printf("W! Agent did not stop within %s, exiting without waiting for the outputs", h.stopTimeout)
+			h.saveState()
+			return
+		case <-ticker.C:
+			status.CheckPoint++
+			changes <- status
+		case c := <-requests:
+			if c.Cmd == svc.Interrogate {
+				changes <- status
+			}
+		}
+	}
+}
+
+// setAgent sets the running agent, pausing it if the service is paused.
+func (h *serviceHandler) setAgent(ag *agent.Agent) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.agent = ag
+	if h.paused {
+		ag.Pause()
+	}
+}
+
+// saveState saves the state of the inputs of the running agent, as it is
+// not saved by the agent if the service exits before the agent stopped.
+func (h *serviceHandler) saveState() {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.agent != nil {
+		h.agent.SaveState()
+	}
+}
+
+// setPaused pauses or resumes the running agent. The state is kept across
+// config reloads.
+func (h *serviceHandler) setPaused(paused bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.paused = paused
+	switch {
+	case h.agent == nil:
+	case paused:
+		h.agent.Pause()
+	default:
+		h.agent.Resume()
+	}
+}
+
+// waitForStart blocks for the configured start delay and until all services
+// to wait for are running. Services which cannot be queried are logged and
+// skipped so a typo does not prevent the agent from starting.
+func (h *serviceHandler) waitForStart() {
+	if h.startDelay > 0 {
+		log.Printf("I! Delaying start by %s", h.startDelay)
+		time.Sleep(h.startDelay)
+	}
+
+	if len(h.waitFor) == 0 {
+		return
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		log.Printf("E! Connecting to service manager failed, not waiting for services: %v", err)
+		return
+	}
+	defer m.Disconnect() //nolint:errcheck // nothing to do on error
+
+	for _, name := range h.waitFor {
+		if err := waitForService(m, name); err != nil {
+			log.Printf("W! Not waiting for service %q: %v", name, err)
+		}
+	}
+}
+
+// waitForService blocks until the service with the given name is running.
+func waitForService(m *mgr.Mgr, name string) error {
+	s, err := m.OpenService(name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	for logged := false; ; logged = true {
+		status, err := s.Query()
+		if err != nil {
+			return err
+		}
+		if status.State == svc.Running {
+			return nil
+		}
+		if !logged {
+			log.Printf("I! Waiting for service %q to be running", name)
+		}
+		time.Sleep(serviceStatePollInterval)
+	}
+}
+
+// configureService applies the settings not supported by the service library
+// to the installed service with the given name.
+func configureService(name string) error {
+	if !*fServiceDelayedStart && *fServiceRestartDelay <= 0 {
+		return nil
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect() //nolint:errcheck // nothing to do on error
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if *fServiceDelayedStart {
+		c, err := s.Config()
+		if err != nil {
+			return err
+		}
+		c.DelayedAutoStart = true
+		if err := s.UpdateConfig(c); err != nil {
+			return fmt.Errorf("setting delayed start failed: %w", err)
+		}
+	}
+
+	if *fServiceRestartDelay > 0 {
+		actions := recoveryActions(*fServiceRestartDelay, *fServiceMaxRestarts)
+		resetPeriod := uint32(fServiceRestartReset.Seconds())
+		if err := s.SetRecoveryActions(actions, resetPeriod); err != nil {
+			return fmt.Errorf("setting recovery actions failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// updateService changes the settings of the installed service with the given
+// name to the ones given by the flags. Settings without a flag given are kept.
+func updateService(name string) error {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect() //nolint:errcheck // nothing to do on error
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	c, err := s.Config()
+	if err != nil {
+		return err
+	}
+	if given["service-display-name"] {
+		c.DisplayName = *fServiceDisplayName
+	}
+	if given["service-description"] {
+		c.Description = *fServiceDescription
+	}
+	if given["service-depends"] {
+		c.Dependencies = splitServiceNames(*fServiceDepends)
+	}
+	if given["service-delayed-start"] {
+		c.DelayedAutoStart = *fServiceDelayedStart
+	}
+	if err := s.UpdateConfig(c); err != nil {
+		return err
+	}
+
+	if *fServiceRestartDelay > 0 {
+		actions := recoveryActions(*fServiceRestartDelay, *fServiceMaxRestarts)
+		resetPeriod := uint32(fServiceRestartReset.Seconds())
+		if err := s.SetRecoveryActions(actions, resetPeriod); err != nil {
+			return fmt.Errorf("setting recovery actions failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// recoveryActions returns the actions restarting the service after the given
+// delay. The service control manager repeats the last action for subsequent
+// failures, so a final no-op limits the number of restarts if requested.
+func recoveryActions(delay time.Duration, maxRestarts int) []mgr.RecoveryAction {
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: delay}
+	if maxRestarts <= 0 {
+		return []mgr.RecoveryAction{restart}
+	}
+
+	actions := make([]mgr.RecoveryAction, 0, maxRestarts+1)
+	for i := 0; i < maxRestarts; i++ {
+		actions = append(actions, restart)
+	}
+	return append(actions, mgr.RecoveryAction{Type: mgr.NoAction})
+}
+
+// splitServiceNames returns the names of a comma separated list of services.
+func splitServiceNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
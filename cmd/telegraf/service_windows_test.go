@@ -0,0 +1,170 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/influxdata/telegraf/agent"
+)
+
+func TestRecoveryActions(t *testing.T) {
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: time.Minute}
+	noop := mgr.RecoveryAction{Type: mgr.NoAction}
+
+	tests := []struct {
+		name        string
+		maxRestarts int
+		expected    []mgr.RecoveryAction
+	}{
+		{
+			name:        "unlimited",
+			maxRestarts: 0,
+			expected:    []mgr.RecoveryAction{restart},
+		},
+		{
+			name:        "negative is unlimited",
+			maxRestarts: -1,
+			expected:    []mgr.RecoveryAction{restart},
+		},
+		{
+			name:        "single restart",
+			maxRestarts: 1,
+			expected:    []mgr.RecoveryAction{restart, noop},
+		},
+		{
+			name:        "limited restarts",
+			maxRestarts: 3,
+			expected:    []mgr.RecoveryAction{restart, restart, restart, noop},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, recoveryActions(time.Minute, tt.maxRestarts))
+		})
+	}
+}
+
+func TestSplitServiceNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		list     string
+		expected []string
+	}{
+		{
+			name: "empty",
+			list: "",
+		},
+		{
+			name: "only separators",
+			list: " , ,",
+		},
+		{
+			name:     "single",
+			list:     "MSSQLSERVER",
+			expected: []string{"MSSQLSERVER"},
+		},
+		{
+			name:     "multiple with spaces",
+			list:     " MSSQLSERVER, W3SVC ,,Dnscache",
+			expected: []string{"MSSQLSERVER", "W3SVC", "Dnscache"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, splitServiceNames(tt.list))
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptPauseAndContinue
+
+	defer func() { agentStarted = nil }()
+
+	ag := &agent.Agent{}
+	release := make(chan struct{})
+	h := &serviceHandler{
+		name: "telegraf-execute-test",
+		run: func() {
+			<-release
+			agentStarted(ag)
+			<-stop
+		},
+	}
+
+	requests := make(chan svc.ChangeRequest)
+	changes := make(chan svc.Status)
+	exited := make(chan struct{})
+	var ssec bool
+	var errno uint32
+	go func() {
+		defer close(exited)
+		ssec, errno = h.Execute(nil, requests, changes)
+	}()
+
+	// The service is pending until the agent is started
+	status := <-changes
+	require.Equal(t, svc.StartPending, status.State)
+	require.NotZero(t, status.WaitHint)
+
+	requests <- svc.ChangeRequest{Cmd: svc.Interrogate}
+	require.Equal(t, svc.StartPending, (<-changes).State)
+
+	close(release)
+	status = <-changes
+	require.Equal(t, svc.Running, status.State)
+	require.Equal(t, accepts, status.Accepts)
+
+	requests <- svc.ChangeRequest{Cmd: svc.Interrogate, CurrentStatus: status}
+	require.Equal(t, status, <-changes)
+
+	requests <- svc.ChangeRequest{Cmd: svc.Pause}
+	require.Equal(t, svc.Paused, (<-changes).State)
+	require.True(t, ag.Paused())
+	requests <- svc.ChangeRequest{Cmd: svc.Continue}
+	require.Equal(t, svc.Running, (<-changes).State)
+	require.False(t, ag.Paused())
+
+	requests <- svc.ChangeRequest{Cmd: svc.Stop}
+	require.Equal(t, svc.StopPending, (<-changes).State)
+
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "service did not exit after stop request")
+	}
+	require.False(t, ssec)
+	require.Zero(t, errno)
+}
+
+func TestExecuteStartTimeout(t *testing.T) {
+	defer func() { agentStarted = nil }()
+
+	h := &serviceHandler{
+		name:         "telegraf-execute-timeout-test",
+		startTimeout: 10 * time.Millisecond,
+		run:          func() { <-stop },
+	}
+
+	requests := make(chan svc.ChangeRequest)
+	changes := make(chan svc.Status)
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		h.Execute(nil, requests, changes)
+	}()
+
+	require.Equal(t, svc.StartPending, (<-changes).State)
+	require.Equal(t, svc.Running, (<-changes).State)
+
+	requests <- svc.ChangeRequest{Cmd: svc.Shutdown}
+	require.Equal(t, svc.StopPending, (<-changes).State)
+	<-exited
+}
@@ -0,0 +1,137 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Exit codes of the service status, following the LSB init script actions.
+const (
+	statusRunning      = 0
+	statusNotRunning   = 3
+	statusNotInstalled = 4
+)
+
+var serviceStates = map[svc.State]string{
+	svc.Stopped:         "stopped",
+	svc.StartPending:    "start pending",
+	svc.StopPending:     "stop pending",
+	svc.Running:         "running",
+	svc.ContinuePending: "continue pending",
+	svc.PausePending:    "pause pending",
+	svc.Paused:          "paused",
+}
+
+var startTypes = map[uint32]string{
+	mgr.StartManual:    "manual",
+	mgr.StartAutomatic: "automatic",
+	mgr.StartDisabled:  "disabled",
+}
+
+// printServiceStatus prints whether the service is installed, its state and
+// configuration and returns the exit code for the status.
+func printServiceStatus(name string) int {
+	m, err := mgr.Connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Connecting to service manager failed: %v\n", err)
+		return statusNotInstalled
+	}
+	defer m.Disconnect() //nolint:errcheck // nothing to do on error
+
+	s, err := m.OpenService(name)
+	if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+		fmt.Printf("Service %s is not installed\n", name)
+		return statusNotInstalled
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Opening service %s failed: %v\n", name, err)
+		return statusNotInstalled
+	}
+	defer s.Close()
+
+	config, err := s.Config()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Querying config of service %s failed: %v\n", name, err)
+		return statusNotInstalled
+	}
+	status, err := s.Query()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Querying state of service %s failed: %v\n", name, err)
+		return statusNotInstalled
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Service:\t%s (%s)\n", name, config.DisplayName)
+	fmt.Fprintf(w, "State:\t%s\n", serviceStates[status.State])
+	if status.ProcessId != 0 {
+		fmt.Fprintf(w, "Process ID:\t%d\n", status.ProcessId)
+		if uptime, err := processUptime(status.ProcessId); err == nil {
+			fmt.Fprintf(w, "Uptime:\t%s\n", uptime.Truncate(time.Second))
+		}
+	}
+
+	startType := startTypes[config.StartType]
+	if config.StartType == mgr.StartAutomatic && config.DelayedAutoStart {
+		startType += " (delayed start)"
+	}
+	fmt.Fprintf(w, "Start type:\t%s\n", startType)
+	account := config.ServiceStartName
+	if account == "" {
+		account = "LocalSystem"
+	}
+	fmt.Fprintf(w, "Account:\t%s\n", account)
+	if len(config.Dependencies) > 0 {
+		fmt.Fprintf(w, "Dependencies:\t%s\n", strings.Join(config.Dependencies, ", "))
+	}
+
+	if args, err := windows.DecomposeCommandLine(config.BinaryPathName); err == nil {
+		configs, configDirs := configPaths(args)
+		for _, path := range configs {
+			fmt.Fprintf(w, "Config:\t%s\n", path)
+		}
+		for _, path := range configDirs {
+			fmt.Fprintf(w, "Config directory:\t%s\n", path)
+		}
+	}
+
+	if status.State == svc.Stopped && status.Win32ExitCode != 0 {
+		exitErr := error(windows.Errno(status.Win32ExitCode))
+		if status.Win32ExitCode == uint32(windows.ERROR_SERVICE_SPECIFIC_ERROR) {
+			exitErr = fmt.Errorf("service specific error %d", status.ServiceSpecificExitCode)
+		}
+		fmt.Fprintf(w, "Last error:\t%v\n", exitErr)
+	}
+	//nolint:errcheck,revive // nothing to do if writing to stdout fails
+	w.Flush()
+
+	if status.State == svc.Stopped {
+		return statusNotRunning
+	}
+	return statusRunning
+}
+
+// processUptime returns the time since the process was created.
+func processUptime(pid uint32) (time.Duration, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(h) //nolint:errcheck // nothing to do on error
+
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(h, &creation, &exit, &kernel, &user); err != nil {
+		return 0, err
+	}
+	return time.Since(time.Unix(0, creation.Nanoseconds())), nil
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/outputs"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/all"
 	"gopkg.in/tomb.v1"
 )
 
@@ -89,9 +90,86 @@ var fServiceName = flag.String("service-name", "telegraf",
 var fServiceDisplayName = flag.String("service-display-name", "Telegraf Data Collector Service",
 	"service display name (windows only)")
 
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceDescription = flag.String("service-description",
+	"Collects data using a series of plugins and publishes it to another series of plugins.",
+	"service description (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceStartDelay = flag.Duration("service-start-delay", 0,
+	"delay the start of the agent when running as service (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceWaitFor = flag.String("service-wait-for", "",
+	"comma separated services to wait for before starting the agent (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceDepends = flag.String("service-depends", "",
+	"comma separated services the service depends on (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceUser = flag.String("service-user", "",
+	"account the service runs as, set on install (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServicePassword = flag.String("service-password", "",
+	"password of the service account, set on install (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceRestartDelay = flag.Duration("service-restart-delay", 0,
+	"delay restarting the failed service, set on install, 0 disables restarts (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceRestartReset = flag.Duration("service-restart-reset", 24*time.Hour,
+	"period without failures after which the restart count is reset, set on install (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceMaxRestarts = flag.Int("service-max-restarts", 0,
+	"maximum number of restarts within the reset period, 0 means unlimited, set on install (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceDelayedStart = flag.Bool("service-delayed-start", false,
+	"install the service as automatic with delayed start (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceStartTimeout = flag.Duration("service-start-timeout", 0,
+	"time to wait for the plugins to start before reporting the service as running, 0 waits forever (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceStopTimeout = flag.Duration("service-stop-timeout", 30*time.Second,
+	"time to wait for the agent to flush the outputs when the service is stopped, 0 waits forever (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fServiceDumpDir = flag.String("service-dump-dir", "",
+	"directory to write crash dumps to when the service panics (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fUpgradeFrom = flag.String("from", "",
+	"path or URL of the new executable to upgrade the service to (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fUpgradeSHA256 = flag.String("sha256", "",
+	"SHA-256 checksum of the new executable to upgrade the service to (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fInstance = flag.String("instance", "",
+	"name of the instance deriving the service name and config paths (windows only)")
+
 //nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
 var fRunAsConsole = flag.Bool("console", false,
 	"run as console application (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fElevate = flag.Bool("elevate", false,
+	"relaunch with administrator rights showing the UAC prompt if required (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fForceConsole = flag.Bool("force-console", false,
+	"run as console application regardless of the session type (windows only)")
+
+//nolint:varcheck,unused // False positive - this var is used for non-default build tag: windows
+var fForceService = flag.Bool("force-service", false,
+	"run as service regardless of the session type (windows only)")
 var fPlugins = flag.String("plugin-directory", "",
 	"path to directory containing external plugins")
 var fRunOnce = flag.Bool("once", false, "run one gather and exit")
@@ -104,6 +182,14 @@ var (
 
 var stop chan struct{}
 
+// reloadRequests triggers a config reload like SIGHUP, e.g. from the Windows
+// service on a custom control code.
+var reloadRequests = make(chan struct{}, 1)
+
+// agentStarted is called with the agent once it is running, e.g. to report
+// the state to the Windows service control manager.
+var agentStarted func(*agent.Agent)
+
 func reloadLoop(
 	inputFilters []string,
 	outputFilters []string,
@@ -118,13 +204,7 @@ func reloadLoop(
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
 		if *fWatchConfig != "" {
-			for _, fConfig := range fConfigs {
-				if _, err := os.Stat(fConfig); err == nil {
-					go watchLocalConfig(signals, fConfig)
-				} else {
-					log.Printf("W! Cannot watch config %s: %s", fConfig, err)
-				}
-			}
+			watchConfig(ctx, signals)
 		}
 		go func() {
 			select {
@@ -135,6 +215,11 @@ func reloadLoop(
 					reload <- true
 				}
 				cancel()
+			case <-reloadRequests:
+				log.Printf("I! Reloading Telegraf config")
+				<-reload
+				reload <- true
+				cancel()
 			case <-stop:
 				cancel()
 			}
@@ -142,7 +227,51 @@ func reloadLoop(
 
 		err := runAgent(ctx, inputFilters, outputFilters)
 		if err != nil && err != context.Canceled {
-			log.Fatalf("E! [telegraf] Error running agent: %v", err)
+			log.Printf("E! [telegraf] Error running agent: %v", err)
+			os.Exit(exitCode(err))
+		}
+	}
+}
+
+// Exit codes of the agent, allowing schedulers running Telegraf in once mode
+// to tell failed inputs and outputs apart.
+const (
+	exitError        = 1
+	exitGatherErrors = 2
+	exitUnsent       = 3
+)
+
+// exitCode returns the exit code for the error the agent failed with.
+func exitCode(err error) int {
+	var gatherErr *agent.GatherError
+	var unsentErr *agent.UnsentError
+	switch {
+	case errors.As(err, &gatherErr):
+		return exitGatherErrors
+	case errors.As(err, &unsentErr):
+		return exitUnsent
+	default:
+		return exitError
+	}
+}
+
+// requestReload triggers a config reload unless one is pending already.
+//
+//nolint:deadcode,unused // False positive - this func is used for non-default build tag: windows
+func requestReload() {
+	select {
+	case reloadRequests <- struct{}{}:
+	default:
+	}
+}
+
+// watchConfigFiles watches every local config file for changes.
+func watchConfigFiles(signals chan os.Signal) {
+	for _, fConfig := range fConfigs {
+		if _, err := os.Stat(fConfig); err == nil {
+			go watchLocalConfig(signals, fConfig)
+		} else {
+			log.Printf("W! Cannot watch config %s: %s", fConfig, err)
 		}
 	}
 }
@@ -194,44 +323,9 @@ func runAgent(ctx context.Context,
 	log.Printf("I! Starting Telegraf %s", version)
 
 	// If no other options are specified, load the config file and run.
-	c := config.NewConfig()
-	c.OutputFilters = outputFilters
-	c.InputFilters = inputFilters
-	var err error
-	// providing no "config" flag should load default config
-	if len(fConfigs) == 0 {
-		err = c.LoadConfig("")
-		if err != nil {
-			return err
-		}
-	}
-	for _, fConfig := range fConfigs {
-		err = c.LoadConfig(fConfig)
-		if err != nil {
-			return err
-		}
-	}
-
-	for _, fConfigDirectory := range fConfigDirs {
-		err = c.LoadDirectory(fConfigDirectory)
-		if err != nil {
-			return err
-		}
-	}
-
-	if !*fTest && len(c.Outputs) == 0 {
-		return errors.New("Error: no outputs found, did you provide a valid config file?")
-	}
-	if *fPlugins == "" && len(c.Inputs) == 0 {
-		return errors.New("Error: no inputs found, did you provide a valid config file?")
-	}
-
-	if int64(c.Agent.Interval) <= 0 {
-		return fmt.Errorf("Agent interval must be positive, found %v", c.Agent.Interval)
-	}
-
-	if int64(c.Agent.FlushInterval) <= 0 {
-		return fmt.Errorf("Agent flush_interval must be positive; found %v", c.Agent.Interval)
+	c, err := loadConfig(fConfigs, fConfigDirs, inputFilters, outputFilters)
+	if err != nil {
+		return err
 	}
 
 	ag, err := agent.NewAgent(c)
@@ -242,18 +336,38 @@ func runAgent(ctx context.Context,
 	// Setup logging as configured.
 	telegraf.Debug = ag.Config.Agent.Debug || *fDebug
 	logConfig := logger.LogConfig{
-		Debug:               telegraf.Debug,
-		Quiet:               ag.Config.Agent.Quiet || *fQuiet,
-		LogTarget:           ag.Config.Agent.LogTarget,
-		Logfile:             ag.Config.Agent.Logfile,
-		RotationInterval:    ag.Config.Agent.LogfileRotationInterval,
-		RotationMaxSize:     ag.Config.Agent.LogfileRotationMaxSize,
-		RotationMaxArchives: ag.Config.Agent.LogfileRotationMaxArchives,
-		LogWithTimezone:     ag.Config.Agent.LogWithTimezone,
+		Debug:                 telegraf.Debug,
+		Quiet:                 ag.Config.Agent.Quiet || *fQuiet,
+		LogTarget:             ag.Config.Agent.LogTarget,
+		Logfile:               ag.Config.Agent.Logfile,
+		RotationInterval:      ag.Config.Agent.LogfileRotationInterval,
+		RotationMaxSize:       ag.Config.Agent.LogfileRotationMaxSize,
+		RotationMaxArchives:   ag.Config.Agent.LogfileRotationMaxArchives,
+		RotationMaxArchiveAge: ag.Config.Agent.LogfileRotationMaxArchiveAge,
+		EventlogRateLimit:     ag.Config.Agent.EventlogRateLimit,
+		EventlogBurst:         ag.Config.Agent.EventlogBurst,
+		LogWithTimezone:       ag.Config.Agent.LogWithTimezone,
+		LogColor:              ag.Config.Agent.LogColor,
 	}
 
 	logger.SetupLogging(logConfig)
 
+	if ag.Config.Agent.UseSystemProxy {
+		if err := internal.ApplySystemProxy(); err != nil {
+			log.Printf("W! Using the system proxy settings failed: %v", err)
+		}
+	}
+
+	stateDir := ag.Config.Agent.StateDirectory
+	if stateDir == "" {
+		stateDir = defaultStateDirectory()
+	}
+	internal.SetStateDirectory(stateDir)
+
+	if err := applyResourceLimits(ag.Config.Agent); err != nil {
+		return fmt.Errorf("applying resource limits failed: %w", err)
+	}
+
 	if *fRunOnce {
 		wait := time.Duration(*fTestWait) * time.Second
 		return ag.Once(ctx, wait)
@@ -288,11 +402,58 @@ func runAgent(ctx context.Context,
 		}
 	}
 
+	if agentStarted != nil {
+		ag.Started = func() { agentStarted(ag) }
+	}
 	return ag.Run(ctx)
 }
 
+// loadConfig loads and checks the given config files and directories.
+func loadConfig(configs, configDirs, inputFilters, outputFilters []string) (*config.Config, error) {
+	c := config.NewConfig()
+	c.OutputFilters = outputFilters
+	c.InputFilters = inputFilters
+	var err error
+	// providing no "config" flag should load default config
+	if len(configs) == 0 {
+		err = c.LoadConfig("")
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, fConfig := range configs {
+		err = c.LoadConfig(fConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, fConfigDirectory := range configDirs {
+		err = c.LoadDirectory(fConfigDirectory)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !*fTest && len(c.Outputs) == 0 {
+		return nil, errors.New("Error: no outputs found, did you provide a valid config file?")
+	}
+	if *fPlugins == "" && len(c.Inputs) == 0 {
+		return nil, errors.New("Error: no inputs found, did you provide a valid config file?")
+	}
+
+	if int64(c.Agent.Interval) <= 0 {
+		return nil, fmt.Errorf("Agent interval must be positive, found %v", c.Agent.Interval)
+	}
+
+	if int64(c.Agent.FlushInterval) <= 0 {
+		return nil, fmt.Errorf("Agent flush_interval must be positive; found %v", c.Agent.Interval)
+	}
+	return c, nil
+}
+
 func usageExit(rc int) {
-	fmt.Println(internal.Usage)
+	fmt.Print(internal.Usage)
 	os.Exit(rc)
 }
 
@@ -322,6 +483,8 @@ func formatFullVersion() string {
 func main() {
 	flag.Var(&fConfigs, "config", "configuration file to load")
 	flag.Var(&fConfigDirs, "config-directory", "directory containing additional *.conf files")
+	flag.DurationVar(fServiceStopTimeout, "service-stop-wait", *fServiceStopTimeout,
+		"same as --service-stop-timeout (windows only)")
 
 	flag.Usage = func() { usageExit(0) }
 	flag.Parse()
@@ -387,6 +550,15 @@ func main() {
 				processorFilters,
 			)
 			return
+		case "parsers":
+			if len(args) > 1 {
+				if err := config.PrintParserConfig(args[1]); err != nil {
+					log.Fatalf("E! %s", err)
+				}
+				return
+			}
+			config.PrintParserList()
+			return
 		}
 	}
 
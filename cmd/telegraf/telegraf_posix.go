@@ -3,6 +3,14 @@
 
 package main
 
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/influxdata/telegraf/config"
+)
+
 func run(inputFilters, outputFilters []string) {
 	stop = make(chan struct{})
 	reloadLoop(
@@ -10,3 +18,21 @@ func run(inputFilters, outputFilters []string) {
 		outputFilters,
 	)
 }
+
+func watchConfig(_ context.Context, signals chan os.Signal) {
+	watchConfigFiles(signals)
+}
+
+// defaultStateDirectory returns no directory, it must be configured.
+func defaultStateDirectory() string {
+	return ""
+}
+
+// applyResourceLimits warns about the limits only supported on Windows, use
+// the service manager, e.g. systemd, to limit the resources instead.
+func applyResourceLimits(c *config.AgentConfig) error {
+	if c.MemoryLimit > 0 || c.CPULimit > 0 {
+		log.Printf("W! The memory_limit and cpu_limit options are only supported on Windows")
+	}
+	return nil
+}
@@ -4,15 +4,24 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/influxdata/telegraf/logger"
 	"github.com/kardianos/service"
+	"golang.org/x/sys/windows/svc"
 )
 
 func run(inputFilters, outputFilters []string) {
+	if err := applyInstance(); err != nil {
+		log.Fatal("E! " + err.Error())
+	}
+
 	// Register the eventlog logging target for windows.
 	logger.RegisterEventLogger(*fServiceName)
 
@@ -22,6 +31,9 @@ func run(inputFilters, outputFilters []string) {
 			outputFilters,
 		)
 	} else {
+		if *fElevate && !isElevated() {
+			os.Exit(runElevated())
+		}
 		stop = make(chan struct{})
 		reloadLoop(
 			inputFilters,
@@ -52,16 +64,27 @@ func (p *program) Stop(s service.Service) error {
 }
 
 func runAsWindowsService(inputFilters, outputFilters []string) {
+	checkElevation(*fService)
+
 	programFiles := os.Getenv("ProgramFiles")
 	if programFiles == "" { // Should never happen
 		programFiles = "C:\\Program Files"
 	}
 	svcConfig := &service.Config{
-		Name:        *fServiceName,
-		DisplayName: *fServiceDisplayName,
-		Description: "Collects data using a series of plugins and publishes it to " +
-			"another series of plugins.",
-		Arguments: []string{"--config", programFiles + "\\Telegraf\\telegraf.conf"},
+		Name:         *fServiceName,
+		DisplayName:  *fServiceDisplayName,
+		Description:  *fServiceDescription,
+		Arguments:    []string{"--config", programFiles + "\\Telegraf\\telegraf.conf"},
+		Dependencies: splitServiceNames(*fServiceDepends),
+		UserName:     *fServiceUser,
+	}
+	if *fServicePassword != "" {
+		// Group managed service accounts, named with a trailing '$', get
+		// their password from the domain controller.
+		if strings.HasSuffix(*fServiceUser, "$") {
+			log.Fatal("E! A password must not be given for group managed service account " + *fServiceUser)
+		}
+		svcConfig.Option = service.KeyValue{"Password": *fServicePassword}
 	}
 
 	prg := &program{
@@ -74,6 +97,24 @@ func runAsWindowsService(inputFilters, outputFilters []string) {
 	}
 	// Handle the --service flag here to prevent any issues with tooling that
 	// may not have an interactive session, e.g. installing from Ansible.
+	if *fService == "status" {
+		os.Exit(printServiceStatus(*fServiceName))
+	}
+	if *fService == "control" {
+		os.Exit(sendControl(*fServiceName, flag.Arg(0)))
+	}
+	if *fService == "update" {
+		if err := updateService(*fServiceName); err != nil {
+			log.Fatal("E! Updating service failed: " + err.Error())
+		}
+		os.Exit(0)
+	}
+	if *fService == "upgrade" {
+		if err := upgradeService(*fServiceName, *fUpgradeFrom, *fUpgradeSHA256); err != nil {
+			log.Fatal("E! Upgrading service failed: " + err.Error())
+		}
+		os.Exit(0)
+	}
 	if *fService != "" {
 		if len(fConfigs) > 0 {
 			svcConfig.Arguments = []string{}
@@ -88,15 +129,59 @@ func runAsWindowsService(inputFilters, outputFilters []string) {
 
 		//set servicename to service cmd line, to have a custom name after relaunch as a service
 		svcConfig.Arguments = append(svcConfig.Arguments, "--service-name", *fServiceName)
+		if *fInstance != "" {
+			svcConfig.Arguments = append(svcConfig.Arguments, "--instance", *fInstance)
+		}
+		svcConfig.Arguments = append(svcConfig.Arguments, serviceFlagArguments()...)
+
+		// Refuse to install or start a service which would fail on its
+		// config right away and be restarted over and over again.
+		switch *fService {
+		case "install":
+			configs, configDirs := configPaths(svcConfig.Arguments)
+			if err := validateConfig(configs, configDirs); err != nil {
+				log.Fatal("E! Invalid config, not installing the service: " + err.Error())
+			}
+		case "start", "restart":
+			if err := validateServiceConfig(*fServiceName); err != nil {
+				log.Fatalf("E! Invalid config, not %sing the service: %v", *fService, err)
+			}
+		}
 
 		err := service.Control(s, *fService)
 		if err != nil {
 			log.Fatal("E! " + err.Error())
 		}
+		switch *fService {
+		case "install":
+			if err := configureService(*fServiceName); err != nil {
+				log.Fatal("E! Configuring service failed: " + err.Error())
+			}
+			if err := logger.InstallEventSource(*fServiceName); err != nil {
+				log.Fatal("E! Registering event log source failed: " + err.Error())
+			}
+		case "uninstall":
+			if err := logger.RemoveEventSource(*fServiceName); err != nil {
+				log.Fatal("E! Removing event log source failed: " + err.Error())
+			}
+		}
 		os.Exit(0)
 	} else {
 		logger.SetupLogging(logger.LogConfig{LogTarget: logger.LogTargetEventlog})
-		err = s.Run()
+		if *fServiceDumpDir != "" {
+			if err := redirectCrashOutput(*fServiceDumpDir); err != nil {
+				log.Printf("E! Redirecting the crash output failed: %v", err)
+			}
+		}
+		err = svc.Run(*fServiceName, &serviceHandler{
+			name:          *fServiceName,
+			inputFilters:  inputFilters,
+			outputFilters: outputFilters,
+			startDelay:    *fServiceStartDelay,
+			waitFor:       splitServiceNames(*fServiceWaitFor),
+			startTimeout:  *fServiceStartTimeout,
+			stopTimeout:   *fServiceStopTimeout,
+		})
 
 		if err != nil {
 			log.Println("E! " + err.Error())
@@ -104,15 +189,97 @@ func runAsWindowsService(inputFilters, outputFilters []string) {
 	}
 }
 
+// serviceFlags are the flags stored with the service on installation if given
+// on the command line, so the service runs as invoked by the operator.
+var serviceFlags = []string{
+	"debug",
+	"quiet",
+	"pprof-addr",
+	"pidfile",
+	"plugin-directory",
+	"input-filter",
+	"output-filter",
+	"aggregator-filter",
+	"processor-filter",
+	"watch-config",
+	"service-dump-dir",
+	"service-start-delay",
+	"service-wait-for",
+	"service-start-timeout",
+	"service-stop-timeout",
+	"service-stop-wait",
+}
+
+// serviceFlagArguments returns the arguments for the service flags given on
+// the command line. The values are passed as '--flag=value' as boolean flags
+// do not accept a separate value.
+func serviceFlagArguments() []string {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	var args []string
+	for _, name := range serviceFlags {
+		if given[name] {
+			args = append(args, "--"+name+"="+flag.Lookup(name).Value.String())
+		}
+	}
+	return args
+}
+
+// Run modes to force instead of detecting whether Telegraf is started by the
+// service control manager.
+const (
+	runModeService = "service"
+	runModeConsole = "console"
+)
+
+// runModeEnv is the environment variable forcing the run mode if none of the
+// flags is given.
+const runModeEnv = "TELEGRAF_RUN_MODE"
+
 // Return true if Telegraf should create a Windows service.
 func windowsRunAsService() bool {
 	if *fService != "" {
 		return true
 	}
 
-	if *fRunAsConsole {
+	mode, err := forcedRunMode()
+	if err != nil {
+		log.Fatal("E! " + err.Error())
+	}
+	if mode != "" {
+		return mode == runModeService
+	}
+
+	// Scheduled tasks run non-interactively but not as a service.
+	if *fRunOnce || *fTest {
 		return false
 	}
 
+	// The detection fails for some session types, e.g. SSH sessions or
+	// nested schedulers, which is why the mode can be forced.
 	return !service.Interactive()
 }
+
+// forcedRunMode returns the run mode forced by the flags or the environment
+// variable, or an empty string to detect the mode.
+func forcedRunMode() (string, error) {
+	forceConsole := *fForceConsole || *fRunAsConsole
+	switch {
+	case *fForceService && forceConsole:
+		return "", errors.New("--force-service and --force-console are mutually exclusive")
+	case *fForceService:
+		return runModeService, nil
+	case forceConsole:
+		return runModeConsole, nil
+	}
+
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv(runModeEnv))); mode {
+	case "", runModeService, runModeConsole:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q, must be %q or %q", runModeEnv, mode, runModeService, runModeConsole)
+	}
+}
@@ -0,0 +1,245 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// upgradeStateTimeout is the time to wait for the service to stop or run
+// during an upgrade.
+const upgradeStateTimeout = 2 * time.Minute
+
+// upgradeService replaces the executable of the installed service with the
+// given name by the one at the path or URL and restarts the service. The new
+// executable must match the SHA-256 checksum before it is run. The
+// executable of the service is renamed instead of overwritten, as it cannot
+// be written while it is running, e.g. if this is the process upgrading it.
+// If the new version fails to start, the old one is restored and restarted.
+func upgradeService(name, from, checksum string) error {
+	if from == "" {
+		return errors.New("missing path or URL of the new executable, use --from")
+	}
+	if checksum == "" {
+		return errors.New("missing SHA-256 checksum of the new executable, use --sha256")
+	}
+	sum, err := hex.DecodeString(checksum)
+	if err != nil || len(sum) != sha256.Size {
+		return fmt.Errorf("invalid SHA-256 checksum %q", checksum)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect() //nolint:errcheck // nothing to do on error
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	c, err := s.Config()
+	if err != nil {
+		return err
+	}
+	args, err := windows.DecomposeCommandLine(c.BinaryPathName)
+	if err != nil || len(args) == 0 {
+		return fmt.Errorf("parsing command line of service failed: %v", err)
+	}
+	exe := args[0]
+	newExe, oldExe := exe+".new", exe+".old"
+
+	log.Printf("I! Fetching new executable from %s", from)
+	if err := fetchExecutable(from, newExe, sum); err != nil {
+		return err
+	}
+	defer os.Remove(newExe) //nolint:errcheck // only exists if the upgrade failed
+
+	newVersion, err := executableVersion(newExe)
+	if err != nil {
+		return fmt.Errorf("checking new executable failed: %w", err)
+	}
+	log.Printf("I! Upgrading service %s to %s", name, newVersion)
+
+	if err := stopService(s); err != nil {
+		return err
+	}
+
+	// A leftover of a previous upgrade is removed first, this fails if the
+	// process upgrading the service is running from it.
+	if err := os.Remove(oldExe); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing executable of previous upgrade failed: %w", err)
+	}
+	if err := os.Rename(exe, oldExe); err != nil {
+		return fmt.Errorf("renaming executable failed: %w", err)
+	}
+	if err := os.Rename(newExe, exe); err != nil {
+		if rerr := os.Rename(oldExe, exe); rerr != nil {
+			return fmt.Errorf("installing new executable failed: %v, restoring old executable failed: %w", err, rerr)
+		}
+		return fmt.Errorf("installing new executable failed: %w", err)
+	}
+
+	if err := startService(s); err != nil {
+		log.Printf("E! Starting upgraded service failed, restoring old executable: %v", err)
+		//nolint:errcheck,revive // failing to stop makes restoring fail below
+		stopService(s)
+		if rerr := os.Rename(exe, newExe); rerr != nil {
+			return fmt.Errorf("starting upgraded service failed: %v, removing new executable failed: %w", err, rerr)
+		}
+		if rerr := os.Rename(oldExe, exe); rerr != nil {
+			return fmt.Errorf("starting upgraded service failed: %v, restoring old executable failed: %w", err, rerr)
+		}
+		if rerr := startService(s); rerr != nil {
+			return fmt.Errorf("starting upgraded service failed: %v, starting old version failed: %w", err, rerr)
+		}
+		return fmt.Errorf("starting upgraded service failed, old version restored: %w", err)
+	}
+
+	version, err := executableVersion(exe)
+	if err != nil {
+		return fmt.Errorf("checking upgraded executable failed: %w", err)
+	}
+	if version != newVersion {
+		return fmt.Errorf("service runs %s instead of %s", version, newVersion)
+	}
+
+	// Fails if this process runs from the old executable, it is removed on
+	// the next upgrade then.
+	if err := os.Remove(oldExe); err != nil {
+		log.Printf("I! Keeping old executable %s: %v", oldExe, err)
+	}
+	log.Printf("I! Service %s upgraded to %s", name, version)
+	return nil
+}
+
+// fetchExecutable copies the executable at the path or, for https URLs,
+// downloads it to the destination. The destination is removed if the
+// content does not match the SHA-256 checksum.
+func fetchExecutable(from, dest string, checksum []byte) error {
+	var src io.ReadCloser
+	switch {
+	case strings.HasPrefix(from, "http://"):
+		return fmt.Errorf("refusing to download %s without TLS, use an https URL", from)
+	case strings.HasPrefix(from, "https://"):
+		req, err := http.NewRequest("GET", from, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", internal.ProductToken())
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close() //nolint:errcheck,revive // nothing to do on error
+			return fmt.Errorf("downloading %s failed: %s", from, resp.Status)
+		}
+		src = resp.Body
+	default:
+		f, err := os.Open(from)
+		if err != nil {
+			return err
+		}
+		src = f
+	}
+	defer src.Close()
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), src); err != nil {
+		f.Close()       //nolint:errcheck,revive // writing failed already
+		os.Remove(dest) //nolint:errcheck,revive // writing failed already
+		return fmt.Errorf("writing %s failed: %w", dest, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(dest) //nolint:errcheck,revive // writing failed already
+		return err
+	}
+	if sum := hash.Sum(nil); !bytes.Equal(sum, checksum) {
+		os.Remove(dest) //nolint:errcheck,revive // checking failed already
+		return fmt.Errorf("SHA-256 checksum %x of %s does not match %x", sum, from, checksum)
+	}
+	return nil
+}
+
+// executableVersion returns the version printed by the executable, which also
+// checks the executable can be run.
+func executableVersion(path string) (string, error) {
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimSpace(string(out))
+	if !strings.HasPrefix(version, "Telegraf") {
+		return "", fmt.Errorf("unexpected version %q", version)
+	}
+	return version, nil
+}
+
+// stopService stops the service unless it is stopped already and waits until
+// it is stopped.
+func stopService(s *mgr.Service) error {
+	status, err := s.Query()
+	if err != nil {
+		return err
+	}
+	if status.State != svc.Stopped && status.State != svc.StopPending {
+		if _, err := s.Control(svc.Stop); err != nil {
+			return fmt.Errorf("stopping service failed: %w", err)
+		}
+	}
+	return waitForState(s, svc.Stopped)
+}
+
+// startService starts the service and waits until it is running.
+func startService(s *mgr.Service) error {
+	if err := s.Start(); err != nil {
+		return err
+	}
+	return waitForState(s, svc.Running)
+}
+
+// waitForState waits until the service is in the given state. Waiting fails
+// if a starting service stops or the state is not reached in time.
+func waitForState(s *mgr.Service, state svc.State) error {
+	deadline := time.Now().Add(upgradeStateTimeout)
+	for {
+		status, err := s.Query()
+		if err != nil {
+			return err
+		}
+		switch {
+		case status.State == state:
+			return nil
+		case state == svc.Running && status.State == svc.Stopped:
+			return errors.New("service stopped while starting")
+		case time.Now().After(deadline):
+			return fmt.Errorf("service not %s within %s", serviceStates[state], upgradeStateTimeout)
+		}
+		time.Sleep(serviceStatePollInterval)
+	}
+}
@@ -0,0 +1,61 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"crypto/sha256"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeServiceInvalidArguments(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        string
+		checksum    string
+		expectedErr string
+	}{
+		{
+			name:        "missing source",
+			checksum:    "00",
+			expectedErr: "use --from",
+		},
+		{
+			name:        "missing checksum",
+			from:        `C:\telegraf.exe`,
+			expectedErr: "use --sha256",
+		},
+		{
+			name:        "invalid checksum",
+			from:        `C:\telegraf.exe`,
+			checksum:    "not-hex",
+			expectedErr: `invalid SHA-256 checksum "not-hex"`,
+		},
+		{
+			name:        "short checksum",
+			from:        `C:\telegraf.exe`,
+			checksum:    "abcd",
+			expectedErr: `invalid SHA-256 checksum "abcd"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := upgradeService("telegraf", tt.from, tt.checksum)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+
+func TestFetchExecutableRefusesHTTP(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "telegraf.exe")
+	sum := sha256.Sum256(nil)
+
+	err := fetchExecutable("http://example.com/telegraf.exe", dest, sum[:])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "without TLS")
+	require.NoFileExists(t, dest)
+}
@@ -0,0 +1,70 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/influxdata/telegraf/agent"
+)
+
+// validateConfig loads the config files and directories and initializes all
+// plugins, so a service is not installed or started with a config it would
+// fail on. The errors name the file and, if known, the line and plugin.
+func validateConfig(configs, configDirs []string) error {
+	c, err := loadConfig(configs, configDirs, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	ag, err := agent.NewAgent(c)
+	if err != nil {
+		return err
+	}
+	return ag.Validate()
+}
+
+// validateServiceConfig validates the config the installed service with the
+// given name is started with.
+func validateServiceConfig(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect() //nolint:errcheck // nothing to do on error
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	c, err := s.Config()
+	if err != nil {
+		return err
+	}
+	args, err := windows.DecomposeCommandLine(c.BinaryPathName)
+	if err != nil {
+		return fmt.Errorf("parsing command line of service failed: %w", err)
+	}
+	configs, configDirs := configPaths(args)
+	return validateConfig(configs, configDirs)
+}
+
+// configPaths returns the config files and directories given in the command
+// line arguments.
+func configPaths(args []string) (configs, configDirs []string) {
+	for i := 0; i < len(args)-1; i++ {
+		switch args[i] {
+		case "--config", "-config":
+			configs = append(configs, args[i+1])
+		case "--config-directory", "-config-directory":
+			configDirs = append(configDirs, args[i+1])
+		}
+	}
+	return configs, configDirs
+}
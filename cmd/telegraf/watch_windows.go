@@ -0,0 +1,150 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/fsnotify.v1"
+)
+
+// configWatchDebounce is the time to wait for further changes before
+// reloading, so copying several config files triggers a single reload.
+const configWatchDebounce = 2 * time.Second
+
+// watchConfig watches the config files and directories for changes. Unless
+// polling is requested, the directories are watched instead of the files, so
+// new files in the config directories and files replaced by configuration
+// management tools are noticed as well.
+func watchConfig(ctx context.Context, signals chan os.Signal) {
+	if *fWatchConfig == "poll" {
+		watchConfigFiles(signals)
+		return
+	}
+	go watchConfigDirs(ctx, signals)
+}
+
+// configWatcher tracks the config files and directories to be watched. Paths
+// are compared case-insensitively like the Windows file system does.
+type configWatcher struct {
+	*fsnotify.Watcher
+
+	files map[string]bool
+	dirs  map[string]bool
+}
+
+func watchConfigDirs(ctx context.Context, signals chan os.Signal) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("E! Error watching config: %s", err)
+		return
+	}
+	w := &configWatcher{
+		Watcher: watcher,
+		files:   make(map[string]bool),
+		dirs:    make(map[string]bool),
+	}
+	defer w.Close()
+
+	for _, fConfig := range fConfigs {
+		if _, err := os.Stat(fConfig); err != nil {
+			log.Printf("W! Cannot watch config %s: %s", fConfig, err)
+			continue
+		}
+		path, err := filepath.Abs(fConfig)
+		if err != nil {
+			log.Printf("W! Cannot watch config %s: %s", fConfig, err)
+			continue
+		}
+		w.files[strings.ToLower(path)] = true
+		w.add(filepath.Dir(path))
+	}
+	for _, fConfigDir := range fConfigDirs {
+		path, err := filepath.Abs(fConfigDir)
+		if err != nil {
+			log.Printf("W! Cannot watch config directory %s: %s", fConfigDir, err)
+			continue
+		}
+		w.dirs[strings.ToLower(path)] = true
+		w.addTree(path)
+	}
+	log.Println("I! Config watcher started")
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-w.Errors:
+			log.Printf("E! Error watching config: %s", err)
+		case event := <-w.Events:
+			if event.Op&fsnotify.Create != 0 && w.inConfigDir(event.Name) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.addTree(event.Name)
+					continue
+				}
+			}
+			if !w.isConfig(event.Name) {
+				continue
+			}
+			log.Printf("D! Config %s changed", event.Name)
+			debounce = time.After(configWatchDebounce)
+		case <-debounce:
+			log.Println("I! Config modified")
+			select {
+			case signals <- syscall.SIGHUP:
+			case <-ctx.Done():
+			}
+			return
+		}
+	}
+}
+
+func (w *configWatcher) add(dir string) {
+	if err := w.Add(dir); err != nil {
+		log.Printf("W! Cannot watch config directory %s: %s", dir, err)
+	}
+}
+
+// addTree watches the directory and all its sub-directories, as they are
+// loaded as well.
+func (w *configWatcher) addTree(root string) {
+	//nolint:errcheck,revive // errors are logged by the walk function
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			log.Printf("W! Cannot watch config directory %s: %s", path, err)
+			return nil
+		}
+		if info.IsDir() {
+			w.add(path)
+		}
+		return nil
+	})
+}
+
+// isConfig returns true for the config files and the files loaded from the
+// config directories.
+func (w *configWatcher) isConfig(path string) bool {
+	if w.files[strings.ToLower(path)] {
+		return true
+	}
+	return strings.HasSuffix(strings.ToLower(path), ".conf") && w.inConfigDir(path)
+}
+
+func (w *configWatcher) inConfigDir(path string) bool {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if w.dirs[strings.ToLower(dir)] {
+			return true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false
+		}
+	}
+}
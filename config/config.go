@@ -27,6 +27,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/parsers/json_v2"
 	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/plugins/secretstores"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/toml"
 	"github.com/influxdata/toml/ast"
@@ -47,6 +48,9 @@ var (
 	// envVarRe is a regex to find environment variables in the config file
 	envVarRe = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)`)
 
+	// secretRe is a regex to find references to secrets in the settings
+	secretRe = regexp.MustCompile(`@\{(\w+):([^}\n]+)\}`)
+
 	envVarEscaper = strings.NewReplacer(
 		`"`, `\"`,
 		`\`, `\\`,
@@ -77,6 +81,8 @@ type Config struct {
 	// Processors have a slice wrapper type because they need to be sorted
 	Processors    models.RunningProcessors
 	AggProcessors models.RunningProcessors
+	// SecretStores are the secret stores by id
+	SecretStores map[string]telegraf.SecretStore
 }
 
 // NewConfig creates a new struct to hold the Telegraf config.
@@ -102,6 +108,7 @@ func NewConfig() *Config {
 		AggProcessors: make([]*models.RunningProcessor, 0),
 		InputFilters:  make([]string, 0),
 		OutputFilters: make([]string, 0),
+		SecretStores:  make(map[string]telegraf.SecretStore),
 	}
 
 	tomlCfg := &toml.Config{
@@ -194,9 +201,35 @@ type AgentConfig struct {
 	// If set to -1, no archives are removed.
 	LogfileRotationMaxArchives int `toml:"logfile_rotation_max_archives"`
 
+	// Maximum age of rotated archives to keep, older logs are deleted when
+	// rotating.  When set to 0 archives are not deleted based on their age.
+	LogfileRotationMaxArchiveAge Duration `toml:"logfile_rotation_max_archive_age"`
+
+	// Maximum number of events per minute written to the event log when
+	// using the "eventlog" logtarget, allowing bursts of up to EventlogBurst
+	// events.  When set to 0 the number of events is not limited.
+	EventlogRateLimit int `toml:"eventlog_rate_limit"`
+	EventlogBurst     int `toml:"eventlog_burst"`
+
 	// Pick a timezone to use when logging or type 'local' for local time.
 	LogWithTimezone string `toml:"log_with_timezone"`
 
+	// Colorize the log levels when logging to a terminal.
+	LogColor bool `toml:"log_color"`
+
+	// Use the WinHTTP or Internet Explorer proxy settings of the machine for
+	// outgoing HTTP connections, Windows only.
+	UseSystemProxy bool `toml:"use_system_proxy"`
+
+	// Directory plugins keep their state in across restarts.  On Windows it
+	// defaults to a directory per service name below ProgramData.
+	StateDirectory string `toml:"state_directory"`
+
+	// Limit the memory of every process and the CPU rate of the agent and
+	// its child processes using a job object, Windows only.
+	MemoryLimit Size    `toml:"memory_limit"`
+	CPULimit    float64 `toml:"cpu_limit"`
+
 	Hostname     string
 	OmitHostname bool
 }
@@ -365,10 +398,47 @@ var agentConfig = `
   ## If set to -1, no archives are removed.
   # logfile_rotation_max_archives = 5
 
+  ## Maximum age of rotated archives to keep, older logs are deleted when
+  ## rotating.  When set to 0 archives are not deleted based on their age.
+  # logfile_rotation_max_archive_age = "0d"
+
+  ## Maximum number of events per minute written to the event log when using
+  ## the "eventlog" logtarget, so a failing plugin does not evict the other
+  ## entries of the Application log.  Up to eventlog_burst events, by default
+  ## the rate limit, are written at once.  The number of suppressed messages
+  ## is logged once events are written again.  When set to 0 the number of
+  ## events is not limited.
+  # eventlog_rate_limit = 0
+  # eventlog_burst = 0
+
   ## Pick a timezone to use when logging or type 'local' for local time.
   ## Example: America/Chicago
   # log_with_timezone = ""
 
+  ## Colorize the log levels when logging to a terminal. The colors are
+  ## disabled if the output is redirected.
+  # log_color = false
+
+  ## Use the WinHTTP or Internet Explorer proxy settings of the machine,
+  ## including the bypass list, for all outgoing HTTP connections. Proxy
+  ## environment variables take precedence. Proxy auto-config scripts and
+  ## automatic detection are not supported. Windows only.
+  # use_system_proxy = false
+
+  ## Directory plugins keep their state in across restarts, e.g. bookmarks or
+  ## caches.  Every agent running on a host needs its own directory.  On
+  ## Windows it defaults to "%ProgramData%\Telegraf\<service name>\state" or,
+  ## when using the --instance flag, "%ProgramData%\Telegraf\<instance>\state".
+  # state_directory = ""
+
+  ## Limit the resources of the agent and the processes it starts, e.g. by
+  ## the exec input, by placing them in a job object. The memory limit
+  ## applies to every process, which fails to allocate more memory. The CPU
+  ## limit is the percentage of the total CPU time of all cores used by all
+  ## processes together. Zero disables the limit. Windows only.
+  # memory_limit = "0MB"
+  # cpu_limit = 0.0
+
   ## Override default hostname, if empty use os.Hostname()
   hostname = ""
   ## If set to true, do no set the "host" tag in the telegraf agent.
@@ -668,6 +738,26 @@ func PrintOutputConfig(name string) error {
 	return nil
 }
 
+// PrintParserList prints the registered data formats and the parser options
+// applying to all of them.
+func PrintParserList() {
+	fmt.Println("Available Data Formats:")
+	for _, name := range parsers.FormatNames() {
+		fmt.Printf("  %-22s %s\n", name, parsers.Formats[name].Description)
+	}
+	fmt.Printf("\nOptions for all data formats:\n%s", parsers.CommonSampleConfig)
+}
+
+// PrintParserConfig prints the parser options of a single data format.
+func PrintParserConfig(name string) error {
+	format, ok := parsers.Formats[name]
+	if !ok {
+		return fmt.Errorf("Data format %s not found", name)
+	}
+	fmt.Printf("# %s\n  data_format = %q\n%s", format.Description, name, format.SampleConfig)
+	return nil
+}
+
 // LoadDirectory loads all toml config files found in the specified path, recursively.
 func (c *Config) LoadDirectory(path string) error {
 	walkfn := func(thispath string, info os.FileInfo, _ error) error {
@@ -761,6 +851,38 @@ func (c *Config) LoadConfigData(data []byte) error {
 		return fmt.Errorf("Error parsing data: %s", err)
 	}
 
+	// Set up the secret stores first, they resolve the secret references in
+	// the settings of all other plugins:
+	if val, ok := tbl.Fields["secretstores"]; ok {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid configuration, error parsing secretstores table")
+		}
+		for pluginName, pluginVal := range subTable.Fields {
+			switch pluginSubTable := pluginVal.(type) {
+			case []*ast.Table:
+				for _, t := range pluginSubTable {
+					if err = c.addSecretStore(pluginName, t); err != nil {
+						return fmt.Errorf("error parsing %s, %w", pluginName, err)
+					}
+				}
+			default:
+				return fmt.Errorf("unsupported config format: %s", pluginName)
+			}
+			if len(c.UnusedFields) > 0 {
+				return fmt.Errorf("plugin secretstores.%s: line %d: configuration specified the fields %q, but they weren't used", pluginName, subTable.Line, keys(c.UnusedFields))
+			}
+		}
+	}
+	for name, val := range tbl.Fields {
+		if name == "secretstores" {
+			continue
+		}
+		if err = c.resolveSecrets(val); err != nil {
+			return err
+		}
+	}
+
 	// Parse tags tables first:
 	for _, tableName := range []string{"tags", "global_tags"} {
 		if val, ok := tbl.Fields[tableName]; ok {
@@ -810,7 +932,7 @@ func (c *Config) LoadConfigData(data []byte) error {
 		}
 
 		switch name {
-		case "agent", "global_tags", "tags":
+		case "agent", "global_tags", "tags", "secretstores":
 		case "outputs":
 			for pluginName, pluginVal := range subTable.Fields {
 				switch pluginSubTable := pluginVal.(type) {
@@ -1001,6 +1123,94 @@ func parseConfig(contents []byte) (*ast.Table, error) {
 	return toml.Parse(contents)
 }
 
+func (c *Config) addSecretStore(name string, table *ast.Table) error {
+	creator, ok := secretstores.SecretStores[name]
+	if !ok {
+		return fmt.Errorf("Undefined but requested secret store: %s", name)
+	}
+	store := creator()
+
+	// The id names the store in the secret references, it is not a setting
+	// of the store itself.
+	id := name
+	c.getFieldString(table, "id", &id)
+	delete(table.Fields, "id")
+	if _, ok := c.SecretStores[id]; ok {
+		return fmt.Errorf("duplicate secret store id %q", id)
+	}
+
+	if err := c.toml.UnmarshalTable(table, store); err != nil {
+		return err
+	}
+
+	models.SetLoggerOnPlugin(store, models.NewLogger("secretstores", name, id))
+	if s, ok := store.(telegraf.Initializer); ok {
+		if err := s.Init(); err != nil {
+			return fmt.Errorf("initializing secret store %q failed: %w", id, err)
+		}
+	}
+
+	c.SecretStores[id] = store
+	return nil
+}
+
+// resolveSecrets replaces the secret references, '@{<store id>:<key>}', in the
+// string values of the settings with the secrets.
+func (c *Config) resolveSecrets(node interface{}) error {
+	switch n := node.(type) {
+	case *ast.Table:
+		for _, val := range n.Fields {
+			if err := c.resolveSecrets(val); err != nil {
+				return err
+			}
+		}
+	case []*ast.Table:
+		for _, t := range n {
+			if err := c.resolveSecrets(t); err != nil {
+				return err
+			}
+		}
+	case *ast.KeyValue:
+		if err := c.resolveSecretsInValue(n.Value); err != nil {
+			return fmt.Errorf("line %d: %w", n.Line, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) resolveSecretsInValue(value ast.Value) error {
+	switch v := value.(type) {
+	case *ast.String:
+		var err error
+		v.Value = secretRe.ReplaceAllStringFunc(v.Value, func(ref string) string {
+			if err != nil {
+				return ref
+			}
+			match := secretRe.FindStringSubmatch(ref)
+			store, ok := c.SecretStores[match[1]]
+			if !ok {
+				err = fmt.Errorf("unknown secret store %q in %q", match[1], ref)
+				return ref
+			}
+			var secret string
+			secret, err = store.Get(match[2])
+			if err != nil {
+				err = fmt.Errorf("resolving secret %q failed: %w", ref, err)
+				return ref
+			}
+			return secret
+		})
+		return err
+	case *ast.Array:
+		for _, elem := range v.Value {
+			if err := c.resolveSecretsInValue(elem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (c *Config) addAggregator(name string, table *ast.Table) error {
 	creator, ok := aggregators.Aggregators[name]
 	if !ok {
@@ -1122,7 +1332,11 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 	// If the input has a SetParser function, then this means it can accept
 	// arbitrary types of input, so build the parser and set it.
 	if t, ok := input.(parsers.ParserInput); ok {
-		parser, err := c.buildParser(name, table)
+		config, err := c.getInputParserConfig(name, table, input)
+		if err != nil {
+			return err
+		}
+		parser, err := c.buildParser(name, config)
 		if err != nil {
 			return err
 		}
@@ -1130,7 +1344,7 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 	}
 
 	if t, ok := input.(parsers.ParserFuncInput); ok {
-		config, err := c.getParserConfig(name, table)
+		config, err := c.getInputParserConfig(name, table, input)
 		if err != nil {
 			return err
 		}
@@ -1283,14 +1497,9 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 	return cp, nil
 }
 
-// buildParser grabs the necessary entries from the ast.Table for creating
-// a parsers.Parser object, and creates it, which can then be added onto
-// an Input object.
-func (c *Config) buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
-	config, err := c.getParserConfig(name, tbl)
-	if err != nil {
-		return nil, err
-	}
+// buildParser creates a parsers.Parser object from the given config, which
+// can then be added onto an Input object.
+func (c *Config) buildParser(name string, config *parsers.Config) (parsers.Parser, error) {
 	parser, err := parsers.NewParser(config)
 	if err != nil {
 		return nil, err
@@ -1306,6 +1515,27 @@ func (c *Config) buildParser(name string, tbl *ast.Table) (parsers.Parser, error
 	return parser, nil
 }
 
+// getInputParserConfig grabs the necessary entries from the ast.Table for
+// creating the parser of the given input.
+func (c *Config) getInputParserConfig(name string, tbl *ast.Table, input telegraf.Input) (*parsers.Config, error) {
+	config, err := c.getParserConfig(name, tbl)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inputs decoding the character encoding themselves, like tail or file,
+	// must not have their data decoded a second time by the parser.
+	if hasTomlField(input, "character_encoding") {
+		config.CharacterEncoding = ""
+	}
+	// The same applies to inputs handling the content encoding, like http or
+	// socket_listener.
+	if hasTomlField(input, "content_encoding") {
+		config.ContentEncoding = ""
+	}
+	return config, nil
+}
+
 func (c *Config) getParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 	pc := &parsers.Config{
 		JSONStrict: true,
@@ -1331,6 +1561,10 @@ func (c *Config) getParserConfig(name string, tbl *ast.Table) (*parsers.Config,
 	c.getFieldString(tbl, "json_time_format", &pc.JSONTimeFormat)
 	c.getFieldString(tbl, "json_timezone", &pc.JSONTimezone)
 	c.getFieldBool(tbl, "json_strict", &pc.JSONStrict)
+	c.getFieldBool(tbl, "json_lines", &pc.JSONLines)
+	c.getFieldString(tbl, "json_flatten_separator", &pc.JSONFlattenSeparator)
+	c.getFieldInt(tbl, "json_flatten_max_depth", &pc.JSONFlattenMaxDepth)
+	c.getFieldString(tbl, "json_array_mode", &pc.JSONArrayMode)
 	c.getFieldString(tbl, "data_type", &pc.DataType)
 	c.getFieldString(tbl, "collectd_auth_file", &pc.CollectdAuthFile)
 	c.getFieldString(tbl, "collectd_security_level", &pc.CollectdSecurityLevel)
@@ -1351,6 +1585,15 @@ func (c *Config) getParserConfig(name string, tbl *ast.Table) (*parsers.Config,
 	c.getFieldStringSlice(tbl, "grok_custom_pattern_files", &pc.GrokCustomPatternFiles)
 	c.getFieldString(tbl, "grok_timezone", &pc.GrokTimezone)
 	c.getFieldString(tbl, "grok_unique_timestamp", &pc.GrokUniqueTimestamp)
+	c.getFieldDuration(tbl, "grok_reload_interval", &pc.GrokReloadInterval)
+	c.getFieldDuration(tbl, "grok_timeout", &pc.GrokTimeout)
+	c.getFieldInt(tbl, "grok_max_line_length", &pc.GrokMaxLineLength)
+	c.getFieldString(tbl, "grok_pattern_tag", &pc.GrokPatternTag)
+	c.getFieldString(tbl, "grok_unmatched_measurement", &pc.GrokUnmatchedMeasurement)
+	c.getFieldString(tbl, "grok_multiline_pattern", &pc.GrokMultilinePattern)
+	c.getFieldBool(tbl, "grok_multiline_negate", &pc.GrokMultilineNegate)
+	c.getFieldDuration(tbl, "grok_multiline_timeout", &pc.GrokMultilineTimeout)
+	c.getFieldInt(tbl, "grok_multiline_max_lines", &pc.GrokMultilineMaxLines)
 
 	//for csv parser
 	c.getFieldStringSlice(tbl, "csv_column_names", &pc.CSVColumnNames)
@@ -1368,6 +1611,27 @@ func (c *Config) getParserConfig(name string, tbl *ast.Table) (*parsers.Config,
 	c.getFieldBool(tbl, "csv_trim_space", &pc.CSVTrimSpace)
 	c.getFieldStringSlice(tbl, "csv_skip_values", &pc.CSVSkipValues)
 
+	c.getFieldStringSlice(tbl, "xsv_delimiters", &pc.XSVDelimiters)
+
+	c.getFieldString(tbl, "influx_parser_type", &pc.InfluxParserType)
+
+	c.getFieldString(tbl, "otlp_signal", &pc.OTLPSignal)
+	c.getFieldString(tbl, "otlp_encoding", &pc.OTLPEncoding)
+	c.getFieldString(tbl, "otlp_metrics_schema", &pc.OTLPMetricsSchema)
+
+	c.getFieldString(tbl, "html_table_selector", &pc.HTMLTableSelector)
+	c.getFieldStringSlice(tbl, "html_table_tag_columns", &pc.HTMLTableTagColumns)
+	c.getFieldString(tbl, "html_table_measurement_column", &pc.HTMLTableMeasurementColumn)
+	c.getFieldString(tbl, "html_table_timestamp_column", &pc.HTMLTableTimestampColumn)
+	c.getFieldString(tbl, "html_table_timestamp_format", &pc.HTMLTableTimestampFormat)
+	c.getFieldString(tbl, "html_table_timezone", &pc.HTMLTableTimezone)
+
+	c.getFieldStringSlice(tbl, "parquet_tag_columns", &pc.ParquetTagColumns)
+	c.getFieldString(tbl, "parquet_measurement_column", &pc.ParquetMeasurementColumn)
+	c.getFieldString(tbl, "parquet_timestamp_column", &pc.ParquetTimestampColumn)
+	c.getFieldString(tbl, "parquet_timestamp_format", &pc.ParquetTimestampFormat)
+	c.getFieldString(tbl, "parquet_timezone", &pc.ParquetTimezone)
+
 	c.getFieldStringSlice(tbl, "form_urlencoded_tag_keys", &pc.FormUrlencodedTagKeys)
 
 	c.getFieldString(tbl, "value_field_name", &pc.ValueFieldName)
@@ -1452,8 +1716,43 @@ func (c *Config) getParserConfig(name string, tbl *ast.Table) (*parsers.Config,
 		}
 	}
 
+	c.getFieldStringSlice(tbl, "auto_formats", &pc.AutoFormats)
+	c.getFieldString(tbl, "character_encoding", &pc.CharacterEncoding)
+	c.getFieldString(tbl, "content_encoding", &pc.ContentEncoding)
+	c.getFieldInt64(tbl, "max_decompressed_size", &pc.MaxDecompressedSize)
+	c.getFieldBool(tbl, "parse_errors_count", &pc.ParseErrorsCount)
+	c.getFieldString(tbl, "parse_errors_measurement", &pc.ParseErrorsMeasurement)
+	c.getFieldString(tbl, "parse_errors_file", &pc.ParseErrorsFile)
+	c.getFieldInt(tbl, "parse_errors_max_length", &pc.ParseErrorsMaxLength)
+	c.getFieldString(tbl, "parse_mode", &pc.ParseMode)
+	c.getFieldStringSlice(tbl, "timestamp_formats", &pc.TimestampFormats)
+	c.getFieldString(tbl, "timestamp_timezone", &pc.TimestampTimezone)
+	c.getFieldBool(tbl, "timestamp_fallback_to_now", &pc.TimestampFallbackToNow)
+	c.getFieldString(tbl, "measurement_key", &pc.MeasurementKey)
+	c.getFieldString(tbl, "measurement_template", &pc.MeasurementTemplate)
+
+	if _, ok := tbl.Fields["default_tags"]; ok {
+		c.getFieldStringMap(tbl, "default_tags", &pc.DefaultTags)
+	}
+
+	if _, ok := tbl.Fields["field_types"]; ok {
+		c.getFieldStringMap(tbl, "field_types", &pc.FieldTypes)
+	}
+
 	pc.MetricName = name
 
+	//for parser chaining
+	if node, ok := tbl.Fields["next_parser"]; ok {
+		if subtbl, ok := node.(*ast.Table); ok {
+			next, err := c.getParserConfig(name, subtbl)
+			if err != nil {
+				return nil, err
+			}
+			c.getFieldString(subtbl, "source_field", &next.SourceField)
+			pc.NextParser = next
+		}
+	}
+
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
@@ -1578,27 +1877,35 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 
 func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 	switch key {
-	case "alias", "carbon2_format", "carbon2_sanitize_replace_char", "collectd_auth_file",
-		"collectd_parse_multivalue", "collectd_security_level", "collectd_typesdb", "collection_jitter",
+	case "alias", "auto_formats", "carbon2_format", "carbon2_sanitize_replace_char", "character_encoding", "collectd_auth_file",
+		"collectd_parse_multivalue", "collectd_security_level", "collectd_typesdb", "collection_jitter", "content_encoding",
 		"csv_column_names", "csv_column_types", "csv_comment", "csv_delimiter", "csv_header_row_count",
 		"csv_measurement_column", "csv_skip_columns", "csv_skip_rows", "csv_tag_columns",
 		"csv_timestamp_column", "csv_timestamp_format", "csv_timezone", "csv_trim_space", "csv_skip_values",
-		"data_format", "data_type", "delay", "drop", "drop_original", "dropwizard_metric_registry_path",
+		"data_format", "data_type", "default_tags", "delay", "drop", "drop_original", "dropwizard_metric_registry_path",
 		"dropwizard_tag_paths", "dropwizard_tags_path", "dropwizard_time_format", "dropwizard_time_path",
-		"fielddrop", "fieldpass", "flush_interval", "flush_jitter", "form_urlencoded_tag_keys",
+		"field_types", "fielddrop", "fieldpass", "flush_interval", "flush_jitter", "form_urlencoded_tag_keys",
 		"grace", "graphite_separator", "graphite_tag_sanitize_mode", "graphite_tag_support",
-		"grok_custom_pattern_files", "grok_custom_patterns", "grok_named_patterns", "grok_patterns",
-		"grok_timezone", "grok_unique_timestamp", "influx_max_line_bytes", "influx_sort_fields",
-		"influx_uint_support", "interval", "json_name_key", "json_query", "json_strict",
+		"grok_custom_pattern_files", "grok_custom_patterns", "grok_max_line_length", "grok_multiline_max_lines", "grok_multiline_negate",
+		"grok_multiline_pattern", "grok_multiline_timeout", "grok_named_patterns", "grok_pattern_tag", "grok_patterns",
+		"grok_reload_interval", "grok_timeout", "grok_timezone", "grok_unique_timestamp",
+		"grok_unmatched_measurement", "html_table_measurement_column", "html_table_selector",
+		"html_table_tag_columns", "html_table_timestamp_column", "html_table_timestamp_format",
+		"html_table_timezone", "influx_max_line_bytes", "influx_parser_type", "influx_sort_fields",
+		"influx_uint_support", "interval", "json_array_mode", "json_flatten_max_depth", "json_flatten_separator",
+		"json_lines", "json_name_key", "json_query", "json_strict",
 		"json_string_fields", "json_time_format", "json_time_key", "json_timestamp_format", "json_timestamp_units", "json_timezone", "json_v2",
-		"lvm", "metric_batch_size", "metric_buffer_limit", "name_override", "name_prefix",
-		"name_suffix", "namedrop", "namepass", "order", "pass", "period", "precision",
+		"lvm", "max_decompressed_size", "measurement_key", "measurement_template", "metric_batch_size", "metric_buffer_limit", "name_override", "name_prefix",
+		"name_suffix", "namedrop", "namepass", "next_parser", "order", "otlp_encoding", "otlp_metrics_schema", "otlp_signal",
+		"parse_errors_count", "parse_errors_file", "parse_errors_max_length", "parse_errors_measurement", "parse_mode",
+		"parquet_measurement_column", "parquet_tag_columns", "parquet_timestamp_column", "parquet_timestamp_format", "parquet_timezone", "pass", "period", "precision",
 		"prefix", "prometheus_export_timestamp", "prometheus_ignore_timestamp", "prometheus_sort_metrics", "prometheus_string_as_label",
 		"separator", "splunkmetric_hec_routing", "splunkmetric_multimetric", "tag_keys",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "template", "templates",
+		"timestamp_fallback_to_now", "timestamp_formats", "timestamp_timezone",
 		"value_field_name", "wavefront_source_override", "wavefront_use_strict",
 		"xml", "xpath", "xpath_json", "xpath_msgpack", "xpath_protobuf", "xpath_print_document",
-		"xpath_protobuf_file", "xpath_protobuf_type":
+		"xpath_protobuf_file", "xpath_protobuf_type", "xsv_delimiters":
 
 		// ignore fields that are common to all plugins.
 	default:
@@ -1743,6 +2050,24 @@ func (c *Config) getFieldStringMap(tbl *ast.Table, fieldName string, target *map
 	}
 }
 
+// hasTomlField returns true if the given plugin struct contains a field with
+// the given toml tag.
+func hasTomlField(plugin interface{}, name string) bool {
+	t := reflect.TypeOf(plugin)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("toml"), ",")[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
 func keys(m map[string]bool) []string {
 	result := []string{}
 	for k := range m {
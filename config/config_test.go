@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/secretstores"
 	"github.com/stretchr/testify/require"
 )
 
@@ -229,6 +230,55 @@ func TestConfig_FieldNotDefined(t *testing.T) {
 	require.Equal(t, "Error loading config file ./testdata/invalid_field.toml: plugin inputs.http_listener_v2: line 1: configuration specified the fields [\"not_a_field\"], but they weren't used", err.Error())
 }
 
+func TestConfig_NextParser(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/next_parser.toml"))
+	require.Len(t, c.Inputs, 1)
+
+	input, ok := c.Inputs[0].Input.(*MockupInputPlugin)
+	require.True(t, ok)
+	parser, ok := input.parser.(*parsers.ChainParser)
+	require.True(t, ok)
+	require.Equal(t, "json_payload", parser.SourceField)
+
+	metrics, err := parser.Parse([]byte(`ERROR {"app": "web", "latency": 42}`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]string{"level": "ERROR", "app": "web"}, metrics[0].Tags())
+	require.Equal(t, map[string]interface{}{"latency": float64(42)}, metrics[0].Fields())
+}
+
+func TestConfig_ParserCharacterEncoding(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/character_encoding.toml"))
+	require.Len(t, c.Inputs, 1)
+
+	input, ok := c.Inputs[0].Input.(*MockupInputPlugin)
+	require.True(t, ok)
+	parser, ok := input.parser.(*parsers.DecodingParser)
+	require.True(t, ok)
+	require.Equal(t, "utf-16le", parser.Encoding)
+}
+
+func TestConfig_ParserDefaultTags(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/parser_default_tags.toml"))
+	require.Len(t, c.Inputs, 1)
+
+	input, ok := c.Inputs[0].Input.(*MockupInputPlugin)
+	require.True(t, ok)
+	require.IsType(t, &parsers.TagTemplateParser{}, input.parser)
+
+	m, err := input.parser.ParseLine(`cpu app_name="web"`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"region": "eu-west", "app": "web"}, m.Tags())
+}
+
+func TestConfig_HasTomlField(t *testing.T) {
+	require.True(t, hasTomlField(&MockupInputPlugin{}, "read_timeout"))
+	require.False(t, hasTomlField(&MockupInputPlugin{}, "character_encoding"))
+}
+
 func TestConfig_WrongFieldType(t *testing.T) {
 	c := NewConfig()
 	err := c.LoadConfig("./testdata/wrong_field_type.toml")
@@ -288,6 +338,27 @@ func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	}
 }
 
+func TestConfig_SecretStores(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/secret_stores.toml"))
+	require.Len(t, c.SecretStores, 1)
+	require.True(t, c.SecretStores["creds"].(*MockupSecretStore).initialized)
+	require.Len(t, c.Outputs, 1)
+
+	output, ok := c.Outputs[0].Output.(*MockupOuputPlugin)
+	require.True(t, ok)
+	require.Equal(t, `https://telegraf:p@ss"w\ord@example.com`, output.URL)
+	require.Equal(t, map[string]string{"Authorization": "Token secret-token"}, output.Headers)
+	require.Equal(t, []string{"metrics", "plain"}, output.Scopes)
+}
+
+func TestConfig_SecretStoreUnknown(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfig("./testdata/secret_stores_unknown.toml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `line 4: unknown secret store "creds"`)
+}
+
 func TestConfig_URLRetries3Fails(t *testing.T) {
 	httpLoadConfigRetryInterval = 0 * time.Second
 	responseCounter := 0
@@ -391,6 +462,31 @@ func (m *MockupOuputPlugin) Description() string                   { return "Moc
 func (m *MockupOuputPlugin) SampleConfig() string                  { return "Mockup test output plugin" }
 func (m *MockupOuputPlugin) Write(metrics []telegraf.Metric) error { return nil }
 
+/*** Mockup SECRETSTORE plugin for testing to avoid cyclic dependencies ***/
+type MockupSecretStore struct {
+	initialized bool
+}
+
+func (m *MockupSecretStore) Description() string  { return "Mockup test secret store" }
+func (m *MockupSecretStore) SampleConfig() string { return "Mockup test secret store" }
+func (m *MockupSecretStore) Init() error {
+	m.initialized = true
+	return nil
+}
+func (m *MockupSecretStore) Get(key string) (string, error) {
+	secrets := map[string]string{
+		"user":     "telegraf",
+		"password": `p@ss"w\ord`,
+		"token":    "secret-token",
+		"scope":    "metrics",
+	}
+	secret, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("unknown secret %q", key)
+	}
+	return secret, nil
+}
+
 // Register the mockup plugin on loading
 func init() {
 	// Register the mockup input plugin for the required names
@@ -402,4 +498,7 @@ func init() {
 	// Register the mockup output plugin for the required names
 	outputs.Add("azure_monitor", func() telegraf.Output { return &MockupOuputPlugin{NamespacePrefix: "Telegraf/"} })
 	outputs.Add("http", func() telegraf.Output { return &MockupOuputPlugin{} })
+
+	// Register the mockup secret store
+	secretstores.Add("mock", func() telegraf.SecretStore { return &MockupSecretStore{} })
 }
@@ -5,7 +5,11 @@ import (
 	"bytes"
 	"compress/gzip"
 	"errors"
+	"fmt"
 	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
 )
 
 // NewStreamContentDecoder returns a reader that will decode the stream
@@ -79,11 +83,49 @@ func NewContentEncoder(encoding string) (ContentEncoder, error) {
 	}
 }
 
+// ErrDecompressedSizeExceeded is returned by the decoders if the decompressed
+// payload is larger than the configured maximum size.
+var ErrDecompressedSizeExceeded = errors.New("decompressed size exceeds limit")
+
+// DecodingOption configures the decoders returned by NewContentDecoder.
+type DecodingOption func(*decoderConfig)
+
+type decoderConfig struct {
+	maxDecompressedSize int64
+}
+
+// WithMaxDecompressedSize limits the size of the decompressed payload to the
+// given number of bytes, protecting against decompression bombs. A size of
+// zero disables the limit.
+func WithMaxDecompressedSize(size int64) DecodingOption {
+	return func(cfg *decoderConfig) {
+		cfg.maxDecompressedSize = size
+	}
+}
+
 // NewContentDecoder returns a ContentDecoder for the encoding type.
-func NewContentDecoder(encoding string) (ContentDecoder, error) {
+func NewContentDecoder(encoding string, options ...DecodingOption) (ContentDecoder, error) {
+	var cfg decoderConfig
+	for _, option := range options {
+		option(&cfg)
+	}
+
 	switch encoding {
 	case "gzip":
-		return NewGzipDecoder()
+		d, err := NewGzipDecoder()
+		if err != nil {
+			return nil, err
+		}
+		d.maxSize = cfg.maxDecompressedSize
+		return d, nil
+	case "zstd":
+		d := NewZstdDecoder()
+		d.maxSize = cfg.maxDecompressedSize
+		return d, nil
+	case "snappy":
+		d := NewSnappyDecoder()
+		d.maxSize = cfg.maxDecompressedSize
+		return d, nil
 	case "identity", "":
 		return NewIdentityDecoder(), nil
 	default:
@@ -143,8 +185,9 @@ type ContentDecoder interface {
 
 // GzipDecoder decompresses buffers with gzip compression.
 type GzipDecoder struct {
-	reader *gzip.Reader
-	buf    *bytes.Buffer
+	reader  *gzip.Reader
+	buf     *bytes.Buffer
+	maxSize int64
 }
 
 func NewGzipDecoder() (*GzipDecoder, error) {
@@ -158,8 +201,8 @@ func (d *GzipDecoder) Decode(data []byte) ([]byte, error) {
 	d.reader.Reset(bytes.NewBuffer(data))
 	d.buf.Reset()
 
-	_, err := d.buf.ReadFrom(d.reader)
-	if err != nil && err != io.EOF {
+	err := readLimited(d.buf, d.reader, d.maxSize)
+	if err != nil {
 		return nil, err
 	}
 	err = d.reader.Close()
@@ -169,6 +212,76 @@ func (d *GzipDecoder) Decode(data []byte) ([]byte, error) {
 	return d.buf.Bytes(), nil
 }
 
+// ZstdDecoder decompresses buffers with zstd compression.
+type ZstdDecoder struct {
+	buf     *bytes.Buffer
+	maxSize int64
+}
+
+func NewZstdDecoder() *ZstdDecoder {
+	return &ZstdDecoder{buf: new(bytes.Buffer)}
+}
+
+func (d *ZstdDecoder) Decode(data []byte) ([]byte, error) {
+	// The zstd decoder runs its own goroutines until it is closed, so use a
+	// single one per buffer instead of keeping it for the life of the decoder.
+	r, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	d.buf.Reset()
+	if err := readLimited(d.buf, r, d.maxSize); err != nil {
+		return nil, err
+	}
+	return d.buf.Bytes(), nil
+}
+
+// SnappyDecoder decompresses buffers in the snappy block format.
+type SnappyDecoder struct {
+	maxSize int64
+}
+
+func NewSnappyDecoder() *SnappyDecoder {
+	return &SnappyDecoder{}
+}
+
+func (d *SnappyDecoder) Decode(data []byte) ([]byte, error) {
+	// The block format stores the decoded length up front, so check it
+	// before allocating the buffer.
+	n, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if d.maxSize > 0 && int64(n) > d.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrDecompressedSizeExceeded, d.maxSize)
+	}
+	return snappy.Decode(nil, data)
+}
+
+// readLimited reads the decompressed data into the buffer, failing if it
+// exceeds the maximum size. A maximum size of zero disables the limit.
+func readLimited(buf *bytes.Buffer, r io.Reader, maxSize int64) error {
+	if maxSize <= 0 {
+		_, err := buf.ReadFrom(r)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		return nil
+	}
+
+	// Read one byte more than allowed to detect payloads exceeding the limit
+	n, err := buf.ReadFrom(io.LimitReader(r, maxSize+1))
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if n > maxSize {
+		return fmt.Errorf("%w: %d bytes", ErrDecompressedSizeExceeded, maxSize)
+	}
+	return nil
+}
+
 // IdentityDecoder is a null decoder that returns the input.
 type IdentityDecoder struct{}
 
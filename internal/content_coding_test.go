@@ -2,9 +2,12 @@ package internal
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"testing"
 
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,6 +49,56 @@ func TestGzipReuse(t *testing.T) {
 	require.Equal(t, "doody", string(actual))
 }
 
+func TestZstdDecode(t *testing.T) {
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer enc.Close()
+	dec, err := NewContentDecoder("zstd")
+	require.NoError(t, err)
+
+	payload := enc.EncodeAll([]byte("howdy"), nil)
+
+	actual, err := dec.Decode(payload)
+	require.NoError(t, err)
+
+	require.Equal(t, "howdy", string(actual))
+}
+
+func TestSnappyDecode(t *testing.T) {
+	dec, err := NewContentDecoder("snappy")
+	require.NoError(t, err)
+
+	actual, err := dec.Decode(snappy.Encode(nil, []byte("howdy")))
+	require.NoError(t, err)
+
+	require.Equal(t, "howdy", string(actual))
+}
+
+func TestDecodeMaxDecompressedSize(t *testing.T) {
+	enc, err := NewGzipEncoder()
+	require.NoError(t, err)
+	payload, err := enc.Encode([]byte("howdy"))
+	require.NoError(t, err)
+
+	dec, err := NewContentDecoder("gzip", WithMaxDecompressedSize(5))
+	require.NoError(t, err)
+	actual, err := dec.Decode(payload)
+	require.NoError(t, err)
+	require.Equal(t, "howdy", string(actual))
+
+	dec, err = NewContentDecoder("gzip", WithMaxDecompressedSize(4))
+	require.NoError(t, err)
+	_, err = dec.Decode(payload)
+	require.True(t, errors.Is(err, ErrDecompressedSizeExceeded))
+}
+
+func TestContentEncoderUnsupported(t *testing.T) {
+	for _, encoding := range []string{"zstd", "snappy"} {
+		_, err := NewContentEncoder(encoding)
+		require.Error(t, err)
+	}
+}
+
 func TestIdentityEncodeDecode(t *testing.T) {
 	enc := NewIdentityEncoder()
 	dec := NewIdentityDecoder()
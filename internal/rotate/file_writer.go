@@ -25,6 +25,7 @@ const (
 // Will rotate at the specified interval and/or when the current file size exceeds maxSizeInBytes
 // At rotation time, current file is renamed and a new file is created.
 // If the number of archives exceeds maxArchives, older files are deleted.
+// Archives last modified longer than maxArchiveAge ago are deleted as well.
 type FileWriter struct {
 	filename                 string
 	filenameRotationTemplate string
@@ -32,13 +33,14 @@ type FileWriter struct {
 	interval                 time.Duration
 	maxSizeInBytes           int64
 	maxArchives              int
+	maxArchiveAge            time.Duration
 	expireTime               time.Time
 	bytesWritten             int64
 	sync.Mutex
 }
 
 // NewFileWriter creates a new file writer.
-func NewFileWriter(filename string, interval time.Duration, maxSizeInBytes int64, maxArchives int) (io.WriteCloser, error) {
+func NewFileWriter(filename string, interval time.Duration, maxSizeInBytes int64, maxArchives int, maxArchiveAge time.Duration) (io.WriteCloser, error) {
 	if interval == 0 && maxSizeInBytes <= 0 {
 		// No rotation needed so a basic io.Writer will do the trick
 		return openFile(filename)
@@ -49,6 +51,7 @@ func NewFileWriter(filename string, interval time.Duration, maxSizeInBytes int64
 		interval:                 interval,
 		maxSizeInBytes:           maxSizeInBytes,
 		maxArchives:              maxArchives,
+		maxArchiveAge:            maxArchiveAge,
 		filenameRotationTemplate: getFilenameRotationTemplate(filename),
 	}
 
@@ -143,27 +146,93 @@ func (w *FileWriter) rotate() (err error) {
 		return err
 	}
 
-	// Use year-month-date for readability, unix time to make the file name unique with second precision
-	now := time.Now()
-	rotatedFilename := fmt.Sprintf(w.filenameRotationTemplate, now.Format(DateFormat), strconv.FormatInt(now.Unix(), 10))
-	if err = os.Rename(w.filename, rotatedFilename); err != nil {
+	if err = archive(w.filename, w.archiveFilename()); err != nil {
 		return err
 	}
 
 	return w.purgeArchivesIfNeeded()
 }
 
-func (w *FileWriter) purgeArchivesIfNeeded() (err error) {
-	if w.maxArchives == -1 {
-		//Skip archiving
+// archiveFilename returns the name of a new archive. The name uses the
+// year-month-date for readability and the unix time to make it unique with
+// second precision. If several files are rotated within a second, the time is
+// increased to not overwrite the previous archive and to keep the names
+// sorted by age.
+func (w *FileWriter) archiveFilename() string {
+	now := time.Now()
+	for ts := now.Unix(); ; ts++ {
+		filename := fmt.Sprintf(w.filenameRotationTemplate, now.Format(DateFormat), strconv.FormatInt(ts, 10))
+		if _, err := os.Lstat(filename); os.IsNotExist(err) {
+			return filename
+		}
+	}
+}
+
+// archive moves the file to the archive. Renaming fails on Windows if another
+// process, e.g. a log viewer or a virus scanner, has the file open without
+// allowing to delete it. The content is copied to the archive and the file is
+// truncated instead then.
+func archive(filename, archiveFilename string) error {
+	err := os.Rename(filename, archiveFilename)
+	if err == nil {
 		return nil
 	}
 
+	if cerr := copyFile(filename, archiveFilename); cerr != nil {
+		return fmt.Errorf("%v, copying instead failed: %w", err, cerr)
+	}
+	if terr := os.Truncate(filename, 0); terr != nil {
+		return fmt.Errorf("%v, truncating after copying failed: %w", err, terr)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, FilePerm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()    //nolint:errcheck,revive // copying failed already
+		os.Remove(dst) //nolint:errcheck,revive // copying failed already
+		return err
+	}
+	return out.Close()
+}
+
+func (w *FileWriter) purgeArchivesIfNeeded() (err error) {
 	var matches []string
 	if matches, err = filepath.Glob(fmt.Sprintf(w.filenameRotationTemplate, "*", "*")); err != nil {
 		return err
 	}
 
+	//remove the archives older than the configured maximum age
+	if w.maxArchiveAge > 0 {
+		remaining := matches[:0]
+		for _, filename := range matches {
+			info, err := os.Stat(filename)
+			if err != nil || time.Since(info.ModTime()) <= w.maxArchiveAge {
+				remaining = append(remaining, filename)
+				continue
+			}
+			if err = os.Remove(filename); err != nil {
+				return err
+			}
+		}
+		matches = remaining
+	}
+
+	if w.maxArchives == -1 {
+		//Skip archiving
+		return nil
+	}
+
 	//if there are more archives than the configured maximum, then purge older files
 	if len(matches) > w.maxArchives {
 		//sort files alphanumerically to delete older files first
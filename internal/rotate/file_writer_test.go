@@ -13,7 +13,7 @@ import (
 func TestFileWriter_NoRotation(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "RotationNo")
 	require.NoError(t, err)
-	writer, err := NewFileWriter(filepath.Join(tempDir, "test"), 0, 0, 0)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test"), 0, 0, 0, 0)
 	require.NoError(t, err)
 	defer func() { writer.Close(); os.RemoveAll(tempDir) }()
 
@@ -29,7 +29,7 @@ func TestFileWriter_TimeRotation(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "RotationTime")
 	require.NoError(t, err)
 	interval, _ := time.ParseDuration("1s")
-	writer, err := NewFileWriter(filepath.Join(tempDir, "test"), interval, 0, -1)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test"), interval, 0, -1, 0)
 	require.NoError(t, err)
 	defer func() { writer.Close(); os.RemoveAll(tempDir) }()
 
@@ -50,7 +50,7 @@ func TestFileWriter_ReopenTimeRotation(t *testing.T) {
 	err = os.WriteFile(filePath, []byte("Hello World"), 0644)
 	time.Sleep(1 * time.Second)
 	assert.NoError(t, err)
-	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), interval, 0, -1)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), interval, 0, -1, 0)
 	require.NoError(t, err)
 	defer func() { writer.Close(); os.RemoveAll(tempDir) }()
 
@@ -62,7 +62,7 @@ func TestFileWriter_SizeRotation(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "RotationSize")
 	require.NoError(t, err)
 	maxSize := int64(9)
-	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, -1)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, -1, 0)
 	require.NoError(t, err)
 	defer func() { writer.Close(); os.RemoveAll(tempDir) }()
 
@@ -81,7 +81,7 @@ func TestFileWriter_ReopenSizeRotation(t *testing.T) {
 	filePath := filepath.Join(tempDir, "test.log")
 	err = os.WriteFile(filePath, []byte("Hello World"), 0644)
 	assert.NoError(t, err)
-	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, -1)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, -1, 0)
 	require.NoError(t, err)
 	defer func() { writer.Close(); os.RemoveAll(tempDir) }()
 
@@ -95,7 +95,7 @@ func TestFileWriter_DeleteArchives(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "RotationDeleteArchives")
 	require.NoError(t, err)
 	maxSize := int64(5)
-	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, 2)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, 2, 0)
 	require.NoError(t, err)
 	defer func() { writer.Close(); os.RemoveAll(tempDir) }()
 
@@ -136,7 +136,7 @@ func TestFileWriter_CloseRotates(t *testing.T) {
 	require.NoError(t, err)
 	defer os.RemoveAll(tempDir)
 	maxSize := int64(9)
-	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, -1)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, -1, 0)
 	require.NoError(t, err)
 
 	writer.Close()
@@ -145,3 +145,50 @@ func TestFileWriter_CloseRotates(t *testing.T) {
 	assert.Equal(t, 1, len(files))
 	assert.Regexp(t, "^test\\.[^\\.]+\\.log$", files[0].Name())
 }
+
+func TestFileWriter_UniqueArchiveNames(t *testing.T) {
+	tempDir := t.TempDir()
+	maxSize := int64(5)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, -1, 0)
+	require.NoError(t, err)
+
+	// Rotating several times within a second must not overwrite archives.
+	for _, content := range []string{"First file", "Second file", "Third file"} {
+		_, err = writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	files, _ := os.ReadDir(tempDir)
+	var contents []string
+	for _, file := range files {
+		if file.Name() == "test.log" {
+			continue
+		}
+		buf, err := os.ReadFile(filepath.Join(tempDir, file.Name()))
+		require.NoError(t, err)
+		contents = append(contents, string(buf))
+	}
+	// The archives are sorted by age.
+	require.Equal(t, []string{"First file", "Second file", "Third file", ""}, contents)
+}
+
+func TestFileWriter_DeleteOldArchives(t *testing.T) {
+	tempDir := t.TempDir()
+	old := filepath.Join(tempDir, "test.2000-01-01-946684800.log")
+	recent := filepath.Join(tempDir, "test.2000-01-02-946771200.log")
+	require.NoError(t, os.WriteFile(old, []byte("old"), 0644))
+	require.NoError(t, os.WriteFile(recent, []byte("recent"), 0644))
+	require.NoError(t, os.Chtimes(old, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+
+	maxSize := int64(5)
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, maxSize, -1, 24*time.Hour)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("Hello World"))
+	require.NoError(t, err)
+
+	require.NoFileExists(t, old)
+	require.FileExists(t, recent)
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	stateDirLock sync.Mutex
+	stateDir     string
+)
+
+// ErrNoStateDirectory is returned by StateDirectory if no state directory is
+// configured.
+var ErrNoStateDirectory = errors.New("no state directory configured, set 'state_directory' in the agent section")
+
+// SetStateDirectory sets the directory plugins keep their state in across
+// restarts, e.g. bookmarks, caches or spilled buffers. Every agent running on
+// a host must use its own directory.
+func SetStateDirectory(dir string) {
+	stateDirLock.Lock()
+	defer stateDirLock.Unlock()
+	stateDir = dir
+}
+
+// StateDirectory returns the directory for the state of the plugin with the
+// given name, e.g. "inputs.win_eventlog", creating it if it does not exist.
+// Several instances of a plugin share the directory, so they must use
+// distinct file names, e.g. derived from their alias.
+func StateDirectory(plugin string) (string, error) {
+	stateDirLock.Lock()
+	dir := stateDir
+	stateDirLock.Unlock()
+
+	if dir == "" {
+		return "", ErrNoStateDirectory
+	}
+	dir = filepath.Join(dir, plugin)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,23 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateDirectory(t *testing.T) {
+	defer SetStateDirectory("")
+
+	SetStateDirectory("")
+	_, err := StateDirectory("inputs.test")
+	require.Error(t, err)
+
+	root := t.TempDir()
+	SetStateDirectory(root)
+	dir, err := StateDirectory("inputs.test")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "inputs.test"), dir)
+	require.DirExists(t, dir)
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+var (
+	systemProxyLock sync.RWMutex
+	// systemProxyFunc selects the proxy for a URL if the system proxy
+	// settings are used.
+	systemProxyFunc func(*url.URL) (*url.URL, error)
+)
+
+// Proxy returns the proxy to use for the request like
+// http.ProxyFromEnvironment, also considering the proxy settings of the
+// operating system if loaded by ApplySystemProxy. Plugins use it for the
+// Proxy of their HTTP transports.
+func Proxy(req *http.Request) (*url.URL, error) {
+	systemProxyLock.RLock()
+	fn := systemProxyFunc
+	systemProxyLock.RUnlock()
+
+	if fn == nil {
+		return http.ProxyFromEnvironment(req)
+	}
+	return fn(req.URL)
+}
+
+// systemProxy holds the proxy settings of the operating system in the format
+// of the proxy environment variables.
+type systemProxy struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// parseProxyList parses a proxy list of the form "host:port" using the same
+// proxy for all schemes or "http=host:port;https=host:port" using one per
+// scheme, as used by WinHTTP and the Internet Explorer settings.
+func parseProxyList(list string) (httpProxy, httpsProxy string) {
+	for _, entry := range strings.FieldsFunc(list, func(r rune) bool { return r == ';' || r == ' ' }) {
+		scheme, proxy := "", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			scheme, proxy = strings.ToLower(entry[:i]), entry[i+1:]
+		}
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "://") {
+			proxy = "http://" + proxy
+		}
+
+		switch scheme {
+		case "":
+			if httpProxy == "" {
+				httpProxy = proxy
+			}
+			if httpsProxy == "" {
+				httpsProxy = proxy
+			}
+		case "http":
+			httpProxy = proxy
+		case "https":
+			httpsProxy = proxy
+		}
+	}
+	return httpProxy, httpsProxy
+}
+
+// convertProxyBypass converts a proxy bypass list like "<local>;*.example.com"
+// to the format of the NO_PROXY environment variable. The "<local>" entry,
+// bypassing the proxy for host names without a period, cannot be expressed
+// and is replaced by the loopback addresses.
+func convertProxyBypass(bypass string) string {
+	var entries []string
+	for _, entry := range strings.FieldsFunc(bypass, func(r rune) bool { return r == ';' || r == ' ' || r == ',' }) {
+		if strings.EqualFold(entry, "<local>") {
+			entries = append(entries, "localhost", "127.0.0.1", "::1")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+// apply makes Proxy use the system proxy settings for the values not given
+// by the proxy environment variables, so explicit settings take precedence.
+// The transport used by default is switched to Proxy as well.
+func (p *systemProxy) apply() {
+	cfg := httpproxy.FromEnvironment()
+	if cfg.HTTPProxy == "" {
+		cfg.HTTPProxy = p.HTTPProxy
+	}
+	if cfg.HTTPSProxy == "" {
+		cfg.HTTPSProxy = p.HTTPSProxy
+	}
+	if cfg.NoProxy == "" {
+		cfg.NoProxy = p.NoProxy
+	}
+
+	systemProxyLock.Lock()
+	systemProxyFunc = cfg.ProxyFunc()
+	systemProxyLock.Unlock()
+
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.Proxy = Proxy
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package internal
+
+import (
+	"errors"
+)
+
+// ApplySystemProxy is only supported on Windows, on other systems the proxy
+// is configured by the environment variables already.
+func ApplySystemProxy() error {
+	return errors.New("using the system proxy settings is only supported on Windows")
+}
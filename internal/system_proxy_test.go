@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProxyList(t *testing.T) {
+	tests := []struct {
+		name  string
+		list  string
+		http  string
+		https string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "single proxy",
+			list:  "proxy.example.com:8080",
+			http:  "http://proxy.example.com:8080",
+			https: "http://proxy.example.com:8080",
+		},
+		{
+			name:  "proxy per scheme",
+			list:  "http=proxy1:8080;https=proxy2:8443;ftp=proxy3:21",
+			http:  "http://proxy1:8080",
+			https: "http://proxy2:8443",
+		},
+		{
+			name:  "scheme overrides default",
+			list:  "proxy1:8080 https=https://proxy2:8443",
+			http:  "http://proxy1:8080",
+			https: "https://proxy2:8443",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			http, https := parseProxyList(tt.list)
+			require.Equal(t, tt.http, http)
+			require.Equal(t, tt.https, https)
+		})
+	}
+}
+
+func TestConvertProxyBypass(t *testing.T) {
+	require.Equal(t, "", convertProxyBypass(""))
+	require.Equal(t,
+		"*.example.com,10.0.0.1,localhost,127.0.0.1,::1",
+		convertProxyBypass("*.example.com;10.0.0.1; <local>"),
+	)
+}
+
+func TestSystemProxyKeepsExplicitSettings(t *testing.T) {
+	for _, name := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("https_proxy", "http://explicit:3128")
+	transport := http.DefaultTransport.(*http.Transport)
+	defaultProxy := transport.Proxy
+	defer func() {
+		transport.Proxy = defaultProxy
+		systemProxyLock.Lock()
+		systemProxyFunc = nil
+		systemProxyLock.Unlock()
+	}()
+
+	p := &systemProxy{
+		HTTPProxy:  "http://proxy:8080",
+		HTTPSProxy: "http://proxy:8080",
+		NoProxy:    "internal.example.com",
+	}
+	p.apply()
+
+	// The environment is left untouched
+	require.Equal(t, "", os.Getenv("HTTP_PROXY"))
+
+	tests := []struct {
+		url   string
+		proxy string
+	}{
+		{"http://example.com", "http://proxy:8080"},
+		{"https://example.com", "http://explicit:3128"},
+		{"http://internal.example.com", ""},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest("GET", tt.url, nil)
+		require.NoError(t, err)
+		proxy, err := Proxy(req)
+		require.NoError(t, err)
+		if tt.proxy == "" {
+			require.Nil(t, proxy, tt.url)
+			continue
+		}
+		require.Equal(t, tt.proxy, proxy.String(), tt.url)
+	}
+}
@@ -0,0 +1,124 @@
+//go:build windows
+// +build windows
+
+package internal
+
+import (
+	"log"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// winhttpAccessTypeNamedProxy is the access type of a configured proxy.
+const winhttpAccessTypeNamedProxy = 3
+
+var (
+	modwinhttp                                = windows.NewLazySystemDLL("winhttp.dll")
+	procWinHttpGetDefaultProxyConfiguration   = modwinhttp.NewProc("WinHttpGetDefaultProxyConfiguration")
+	procWinHttpGetIEProxyConfigForCurrentUser = modwinhttp.NewProc("WinHttpGetIEProxyConfigForCurrentUser")
+	procGlobalFree                            = windows.NewLazySystemDLL("kernel32.dll").NewProc("GlobalFree")
+)
+
+// winhttpProxyInfo is the WINHTTP_PROXY_INFO structure.
+type winhttpProxyInfo struct {
+	accessType  uint32
+	proxy       *uint16
+	proxyBypass *uint16
+}
+
+// autoConfigWarning makes the warning about unsupported automatic proxy
+// configuration logged once, not on every reload of the configuration.
+var autoConfigWarning sync.Once
+
+// winhttpCurrentUserIEProxyConfig is the
+// WINHTTP_CURRENT_USER_IE_PROXY_CONFIG structure.
+type winhttpCurrentUserIEProxyConfig struct {
+	autoDetect    int32
+	autoConfigURL *uint16
+	proxy         *uint16
+	proxyBypass   *uint16
+}
+
+// ApplySystemProxy makes Proxy, used by the HTTP clients of the plugins, use
+// the WinHTTP proxy configuration of the machine or, if there is none, the
+// Internet Explorer proxy settings of the user. Proxy environment variables
+// set explicitly take precedence. Proxy auto-config scripts and the automatic
+// detection of the proxy are not supported, connections are made directly
+// unless a proxy is configured as well.
+func ApplySystemProxy() error {
+	p, err := winhttpProxy()
+	if err != nil {
+		return err
+	}
+	if p.HTTPProxy == "" && p.HTTPSProxy == "" {
+		if p, err = ieProxy(); err != nil {
+			return err
+		}
+	}
+	if p.HTTPProxy == "" && p.HTTPSProxy == "" {
+		log.Printf("D! No system proxy configured")
+		return nil
+	}
+
+	log.Printf("I! Using system proxy settings: http: %q, https: %q, bypass: %q", p.HTTPProxy, p.HTTPSProxy, p.NoProxy)
+	p.apply()
+	return nil
+}
+
+// winhttpProxy returns the machine wide proxy configured for WinHTTP, e.g. by
+// 'netsh winhttp set proxy'.
+func winhttpProxy() (*systemProxy, error) {
+	var info winhttpProxyInfo
+	r, _, err := procWinHttpGetDefaultProxyConfiguration.Call(uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return nil, err
+	}
+	defer globalFree(info.proxy)
+	defer globalFree(info.proxyBypass)
+
+	p := &systemProxy{}
+	if info.accessType == winhttpAccessTypeNamedProxy {
+		p.HTTPProxy, p.HTTPSProxy = parseProxyList(windows.UTF16PtrToString(info.proxy))
+		p.NoProxy = convertProxyBypass(windows.UTF16PtrToString(info.proxyBypass))
+	}
+	return p, nil
+}
+
+// ieProxy returns the proxy of the Internet Explorer settings of the user.
+func ieProxy() (*systemProxy, error) {
+	var config winhttpCurrentUserIEProxyConfig
+	r, _, err := procWinHttpGetIEProxyConfigForCurrentUser.Call(uintptr(unsafe.Pointer(&config)))
+	if r == 0 {
+		return nil, err
+	}
+	defer globalFree(config.autoConfigURL)
+	defer globalFree(config.proxy)
+	defer globalFree(config.proxyBypass)
+
+	p := &systemProxy{}
+	p.HTTPProxy, p.HTTPSProxy = parseProxyList(windows.UTF16PtrToString(config.proxy))
+	p.NoProxy = convertProxyBypass(windows.UTF16PtrToString(config.proxyBypass))
+
+	if config.autoConfigURL != nil || config.autoDetect != 0 {
+		autoConfigWarning.Do(func() {
+			direct := ""
+			if p.HTTPProxy == "" && p.HTTPSProxy == "" {
+				direct = ", connecting directly"
+			}
+			log.Printf("W! Automatic proxy configuration (auto-config script %q, auto-detect %t) is not supported%s; "+
+				"set the HTTP_PROXY and HTTPS_PROXY environment variables to use a proxy",
+				windows.UTF16PtrToString(config.autoConfigURL), config.autoDetect != 0, direct)
+		})
+	}
+	return p, nil
+}
+
+// globalFree releases a string allocated by WinHTTP.
+func globalFree(p *uint16) {
+	if p != nil {
+		//nolint:errcheck,revive // nothing to do on error
+		procGlobalFree.Call(uintptr(unsafe.Pointer(p)))
+	}
+}
@@ -30,13 +30,6 @@ func (t *Template) Apply(line string, joiner string) (string, map[string]string,
 	}
 
 	// See if an invalid combination has been specified in the template:
-	for _, tag := range t.parts {
-		if tag == "measurement*" {
-			t.greedyMeasurement = true
-		} else if tag == "field*" {
-			t.greedyField = true
-		}
-	}
 	if t.greedyField && t.greedyMeasurement {
 		return "", nil, "",
 			fmt.Errorf("either 'field*' or 'measurement*' can be used in each "+
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampParser parses timestamps trying an ordered list of formats. Each
+// format can be any format supported by ParseTimestamp. It is shared by the
+// parsers to handle timestamps consistently.
+type TimestampParser struct {
+	// Formats are tried in order until the timestamp can be parsed.
+	Formats []string
+	// Timezone is the location of timestamps without timezone information.
+	Timezone string
+	// FallbackToNow returns the current time instead of an error if the
+	// timestamp does not match any of the formats.
+	FallbackToNow bool
+	// Now returns the current time, time.Now is used if unset.
+	Now func() time.Time
+}
+
+// NewTimestampParser returns a parser for the given formats skipping empty
+// ones.
+func NewTimestampParser(formats []string, timezone string, fallbackToNow bool) *TimestampParser {
+	p := &TimestampParser{
+		Formats:       make([]string, 0, len(formats)),
+		Timezone:      timezone,
+		FallbackToNow: fallbackToNow,
+	}
+	for _, format := range formats {
+		if format != "" {
+			p.Formats = append(p.Formats, format)
+		}
+	}
+	return p
+}
+
+// Parse returns the time of the first format matching the timestamp. If no
+// format matches the error of the last format is returned or, if fallback is
+// enabled, the current time.
+func (p *TimestampParser) Parse(timestamp interface{}) (time.Time, error) {
+	err := fmt.Errorf("no timestamp format specified")
+	for _, format := range p.Formats {
+		var ts time.Time
+		ts, err = p.parse(format, timestamp)
+		if err == nil {
+			return ts, nil
+		}
+	}
+
+	if p.FallbackToNow {
+		return p.now(), nil
+	}
+	return time.Time{}, err
+}
+
+// Missing returns the time for a missing timestamp value, which is the current
+// time if fallback is enabled or the given error otherwise.
+func (p *TimestampParser) Missing(err error) (time.Time, error) {
+	if p.FallbackToNow {
+		return p.now(), nil
+	}
+	return time.Time{}, err
+}
+
+func (p *TimestampParser) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+	return p.Now()
+}
+
+func (p *TimestampParser) parse(format string, timestamp interface{}) (time.Time, error) {
+	// Allow unix timestamps given as strings in scientific notation.
+	s, ok := timestamp.(string)
+	if ok && strings.HasPrefix(format, "unix") && strings.ContainsAny(s, "eE") {
+		return parseUnixFloat(format, s)
+	}
+	return ParseTimestamp(format, timestamp, p.Timezone)
+}
+
+func parseUnixFloat(format string, timestamp string) (time.Time, error) {
+	f, err := strconv.ParseFloat(timestamp, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch format {
+	case "unix":
+		f *= 1e9
+	case "unix_ms":
+		f *= 1e6
+	case "unix_us":
+		f *= 1e3
+	case "unix_ns":
+	default:
+		return time.Time{}, fmt.Errorf("unsupported unix format %q", format)
+	}
+	return time.Unix(0, int64(f)).UTC(), nil
+}
@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampParserFormatOrder(t *testing.T) {
+	p := NewTimestampParser([]string{"", "rfc3339", "unix_ms", "2006-01-02"}, "", false)
+	require.Equal(t, []string{"rfc3339", "unix_ms", "2006-01-02"}, p.Formats)
+
+	tests := []struct {
+		name      string
+		timestamp interface{}
+		expected  time.Time
+	}{
+		{
+			name:      "first format",
+			timestamp: "2021-03-04T05:06:07Z",
+			expected:  time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		},
+		{
+			name:      "second format",
+			timestamp: int64(1614834367000),
+			expected:  time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		},
+		{
+			name:      "third format",
+			timestamp: "2021-03-04",
+			expected:  time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, err := p.Parse(tt.timestamp)
+			require.NoError(t, err)
+			require.True(t, tt.expected.Equal(ts), "expected %v, got %v", tt.expected, ts)
+		})
+	}
+
+	_, err := p.Parse("not a timestamp")
+	require.Error(t, err)
+}
+
+func TestTimestampParserTimezone(t *testing.T) {
+	p := NewTimestampParser([]string{"2006-01-02 15:04:05"}, "America/New_York", false)
+	ts, err := p.Parse("2021-03-04 05:06:07")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2021, 3, 4, 10, 6, 7, 0, time.UTC), ts.UTC())
+}
+
+func TestTimestampParserUnixFloatString(t *testing.T) {
+	p := NewTimestampParser([]string{"unix"}, "", false)
+	ts, err := p.Parse("1.614834367e9")
+	require.NoError(t, err)
+	require.Equal(t, int64(1614834367), ts.Unix())
+}
+
+func TestTimestampParserFallbackToNow(t *testing.T) {
+	now := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	p := NewTimestampParser([]string{"unix"}, "", true)
+	p.Now = func() time.Time { return now }
+
+	ts, err := p.Parse("invalid")
+	require.NoError(t, err)
+	require.Equal(t, now, ts)
+
+	ts, err = p.Missing(nil)
+	require.NoError(t, err)
+	require.Equal(t, now, ts)
+
+	p.FallbackToNow = false
+	_, err = p.Parse("invalid")
+	require.Error(t, err)
+}
@@ -12,6 +12,7 @@ Usage:
 The commands & flags are:
 
   config              print out full sample configuration to stdout
+  parsers [format]    print available data formats or the options of a format
   version             print the version to stdout
 
   --aggregator-filter <filter>   filter the aggregators to enable, separator is :
@@ -48,6 +49,9 @@ Examples:
   # generate a telegraf config file:
   telegraf config > telegraf.conf
 
+  # show the options of the json data format
+  telegraf parsers json
+
   # generate config with only cpu input & influxdb output plugins defined
   telegraf --input-filter cpu --output-filter influxdb config
 
@@ -12,6 +12,7 @@ Usage:
 The commands & flags are:
 
   config              print out full sample configuration to stdout
+  parsers [format]    print available data formats or the options of a format
   version             print the version to stdout
 
   --aggregator-filter <filter>   filter the aggregators to enable, separator is :
@@ -19,7 +20,8 @@ The commands & flags are:
   --config-directory <directory> directory containing additional *.conf files
   --watch-config                 Telegraf will restart on local config changes. Monitor changes 
                                  using either fs notifications or polling.  Valid values: 'inotify' or 'poll'. 
-                                 Monitoring is off by default.
+                                 Monitoring is off by default. Using notifications, files added to the
+                                 config directories are noticed as well.
   --debug                        turn on debug logging
   --input-filter <filter>        filter the inputs to enable, separator is :
   --input-list                   print available input plugins.
@@ -41,15 +43,65 @@ The commands & flags are:
   --version                      display the version and exit
 
   --console                      run as console application (windows only)
-  --service <service>            operate on the service (windows only)
+  --elevate                      relaunch with administrator rights showing the
+                                 UAC prompt if required (windows only)
+  --force-console                run as console application regardless of the
+                                 session type, like setting TELEGRAF_RUN_MODE
+                                 to 'console' (windows only)
+  --force-service                run as service regardless of the session type,
+                                 like setting TELEGRAF_RUN_MODE to 'service'
+                                 (windows only)
+  --instance <name>              name of the instance deriving the service name,
+                                 display name and default config paths from
+                                 %ProgramData%\Telegraf\<name> (windows only)
+  --service <service>            operate on the service, one of install, uninstall,
+                                 start, stop, restart, status, update, upgrade
+                                 or control <command> (windows only)
+  --from <path|url>              path or https URL of the new executable for
+                                 the upgrade service operation (windows only)
+  --sha256 <checksum>            SHA-256 checksum of the new executable, checked
+                                 before it is run for the upgrade (windows only)
   --service-name                 service name (windows only)
   --service-display-name         service display name (windows only)
+  --service-description          service description (windows only)
+  --service-depends <services>   comma separated services the service depends
+                                 on, set on install (windows only)
+  --service-user <account>       account the service runs as, set on install,
+                                 e.g. 'DOMAIN\user' or 'DOMAIN\gmsa$' for a
+                                 group managed service account (windows only)
+  --service-password <password>  password of the service account, omit for
+                                 group managed service accounts (windows only)
+  --service-restart-delay <delay> restart the failed service after the delay,
+                                 set on install, 0 disables restarts (windows only)
+  --service-restart-reset <period> reset the restart count after the period
+                                 without failures, default 24h (windows only)
+  --service-max-restarts <count> maximum number of restarts within the reset
+                                 period, 0 means unlimited (windows only)
+  --service-dump-dir <directory> directory to write a goroutine dump and a
+                                 minidump to when the service crashes, and the
+                                 standard error to (windows only)
+  --service-delayed-start        install the service as automatic with delayed
+                                 start (windows only)
+  --service-start-delay <delay>  delay the start of the agent when running as
+                                 service, e.g. 30s (windows only)
+  --service-start-timeout <timeout> time to wait for the plugins to start
+                                 before reporting the service as running, 0
+                                 waits forever (windows only)
+  --service-stop-timeout <timeout> time to wait for the agent to flush the
+                                 outputs on stop, 0 waits forever, default 30s
+                                 (windows only)
+  --service-stop-wait <timeout>  same as --service-stop-timeout (windows only)
+  --service-wait-for <services>  comma separated services to wait for before
+                                 starting the agent (windows only)
 
 Examples:
 
   # generate a telegraf config file:
   telegraf config > telegraf.conf
 
+  # show the options of the json data format
+  telegraf parsers json
+
   # generate config with only cpu input & influxdb output plugins defined
   telegraf --input-filter cpu --output-filter influxdb config
 
@@ -71,6 +123,18 @@ Examples:
   # install telegraf service
   telegraf --service install --config "C:\Program Files\Telegraf\telegraf.conf"
 
+  # show the state of the telegraf service
+  telegraf --service status
+
+  # flush the outputs of the running telegraf service
+  telegraf --service control flush
+
+  # upgrade the telegraf service to a new executable
+  telegraf --service upgrade --from "C:\Downloads\telegraf.exe" --sha256 <checksum>
+
   # install telegraf service with custom name
   telegraf --service install --service-name=my-telegraf --service-display-name="My Telegraf"
+
+  # install telegraf service starting after SQL Server is running
+  telegraf --service install --service-wait-for=MSSQLSERVER --service-start-delay=30s
 `
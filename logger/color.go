@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"io"
+	"regexp"
+)
+
+// levelRegex matches the level prefix following the timestamp of a log line.
+var levelRegex = regexp.MustCompile(`^\S+ ([DIWE]!)`)
+
+// levelColors are the ANSI escape sequences of the colors of the log levels.
+var levelColors = map[byte]string{
+	'D': "\x1b[36m",
+	'I': "\x1b[32m",
+	'W': "\x1b[33m",
+	'E': "\x1b[31m",
+}
+
+const colorReset = "\x1b[0m"
+
+// colorWriter colorizes the level prefix of the log lines for terminals.
+type colorWriter struct {
+	writer io.Writer
+}
+
+func (c *colorWriter) Write(b []byte) (int, error) {
+	loc := levelRegex.FindSubmatchIndex(b)
+	if loc == nil {
+		return c.writer.Write(b)
+	}
+
+	start, end := loc[2], loc[3]
+	line := make([]byte, 0, len(b)+len(levelColors[b[start]])+len(colorReset))
+	line = append(line, b[:start]...)
+	line = append(line, levelColors[b[start]]...)
+	line = append(line, b[start:end]...)
+	line = append(line, colorReset...)
+	line = append(line, b[end:]...)
+	if _, err := c.writer.Write(line); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
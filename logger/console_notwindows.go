@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package logger
+
+import (
+	"os"
+)
+
+// enableConsoleColor returns true if the file is a terminal.
+func enableConsoleColor(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+}
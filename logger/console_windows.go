@@ -0,0 +1,35 @@
+//go:build windows
+// +build windows
+
+package logger
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// codePageUTF8 is the code page identifier of UTF-8.
+const codePageUTF8 = 65001
+
+var procSetConsoleOutputCP = windows.NewLazySystemDLL("kernel32.dll").NewProc("SetConsoleOutputCP")
+
+// enableConsoleColor returns true if the file is a console supporting colors.
+// Virtual terminal processing, available on Windows 10 and later, is enabled
+// to interpret the escape sequences, and the output code page is set to UTF-8.
+func enableConsoleColor(f *os.File) bool {
+	h := windows.Handle(f.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		// Not a console, e.g. redirected to a file or pipe.
+		return false
+	}
+
+	//nolint:errcheck,revive // keep the current code page on error
+	procSetConsoleOutputCP.Call(codePageUTF8)
+
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
@@ -4,51 +4,105 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"strings"
+	"syscall"
 
 	"github.com/influxdata/wlog"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
 	"golang.org/x/sys/windows/svc/eventlog"
 )
 
+// The event IDs of the log levels. Messages are formatted by EventCreate.exe
+// which supports the IDs 1 to 1000.
 const (
 	LogTargetEventlog = "eventlog"
 	eidInfo           = 1
 	eidWarning        = 2
 	eidError          = 3
+	eidDebug          = 4
+	eidCrash          = 5
 )
 
+// maxEventMessageLen is the maximum length of an event message in characters.
+const maxEventMessageLen = 31839
+
+// The event categories of the log levels, allowing to filter the events in the
+// Event Viewer by level independently of the event type.
+const (
+	categoryError = iota + 1
+	categoryWarning
+	categoryInfo
+	categoryDebug
+	categoryCount = categoryDebug
+)
+
+// eventSourceKey is the registry key of the event sources of the application
+// event log.
+const eventSourceKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application`
+
 type eventLogger struct {
 	logger *eventlog.Log
+	// limiter limits the number of events if set.
+	limiter *rateLimiter
 }
 
 func (t *eventLogger) Write(b []byte) (n int, err error) {
 	loc := prefixRegex.FindIndex(b)
 	n = len(b)
+
+	if t.limiter != nil {
+		ok, suppressed := t.limiter.allow()
+		if !ok {
+			return n, nil
+		}
+		if suppressed > 0 {
+			msg := fmt.Sprintf("%d log messages were suppressed by the event log rate limit", suppressed)
+			if err := t.report(windows.EVENTLOG_WARNING_TYPE, categoryWarning, eidWarning, msg); err != nil {
+				return n, err
+			}
+		}
+	}
+
 	if loc == nil {
-		err = t.logger.Info(1, string(b))
+		err = t.report(windows.EVENTLOG_INFORMATION_TYPE, categoryInfo, eidInfo, string(b))
 	} else if n > 2 { //skip empty log messages
 		line := strings.Trim(string(b[loc[1]:]), " \t\r\n")
 		switch rune(b[loc[0]]) {
+		case 'D':
+			err = t.report(windows.EVENTLOG_INFORMATION_TYPE, categoryDebug, eidDebug, line)
 		case 'I':
-			err = t.logger.Info(eidInfo, line)
+			err = t.report(windows.EVENTLOG_INFORMATION_TYPE, categoryInfo, eidInfo, line)
 		case 'W':
-			err = t.logger.Warning(eidWarning, line)
+			err = t.report(windows.EVENTLOG_WARNING_TYPE, categoryWarning, eidWarning, line)
 		case 'E':
-			err = t.logger.Error(eidError, line)
+			err = t.report(windows.EVENTLOG_ERROR_TYPE, categoryError, eidError, line)
 		}
 	}
 
 	return
 }
 
+// report writes the event like eventlog.Log does, but with a category.
+func (t *eventLogger) report(etype uint16, category uint16, eid uint32, msg string) error {
+	ss := []*uint16{syscall.StringToUTF16Ptr(msg)}
+	return windows.ReportEvent(t.logger.Handle, etype, category, eid, 0, 1, 0, &ss[0], nil)
+}
+
 type eventLoggerCreator struct {
 	logger *eventlog.Log
 }
 
 func (e *eventLoggerCreator) CreateLogger(config LogConfig) (io.Writer, error) {
-	return wlog.NewWriter(&eventLogger{logger: e.logger}), nil
+	writer := &eventLogger{logger: e.logger}
+	if config.EventlogRateLimit > 0 {
+		writer.limiter = newRateLimiter(config.EventlogRateLimit, config.EventlogBurst)
+	}
+	return wlog.NewWriter(writer), nil
 }
 
 func RegisterEventLogger(name string) error {
@@ -61,3 +115,51 @@ func RegisterEventLogger(name string) error {
 	registerLogger(LogTargetEventlog, &eventLoggerCreator{logger: eventLog})
 	return nil
 }
+
+// ReportCrash writes the message about a crash, e.g. a panic with the stack
+// trace, directly to the event log of the given source, independently of the
+// configured log target. Too long messages are truncated.
+func ReportCrash(name, msg string) error {
+	l, err := eventlog.Open(name)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	if r := []rune(msg); len(r) > maxEventMessageLen {
+		msg = string(r[:maxEventMessageLen])
+	}
+	t := &eventLogger{logger: l}
+	return t.report(windows.EVENTLOG_ERROR_TYPE, categoryError, eidCrash, msg)
+}
+
+// InstallEventSource registers the event source of the given name, replacing
+// an existing registration. The messages are formatted by EventCreate.exe and
+// the categories of the log levels are declared, so the events can be filtered
+// by level in the Event Viewer.
+func InstallEventSource(name string) error {
+	if err := RemoveEventSource(name); err != nil {
+		return err
+	}
+	err := eventlog.InstallAsEventCreate(name, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil {
+		return err
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, eventSourceKey+`\`+name, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	return key.SetDWordValue("CategoryCount", categoryCount)
+}
+
+// RemoveEventSource removes the registration of the event source of the given
+// name if there is one.
+func RemoveEventSource(name string) error {
+	err := eventlog.Remove(name)
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return err
+	}
+	return nil
+}
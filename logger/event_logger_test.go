@@ -22,11 +22,22 @@ const (
 	Info Levels = iota + 1
 	Warning
 	Error
+	Debug
+)
+
+type Categories int
+
+const (
+	ErrorCategory Categories = iota + 1
+	WarningCategory
+	InfoCategory
+	DebugCategory
 )
 
 type Event struct {
-	Message string `xml:"EventData>Data"`
-	Level   Levels `xml:"System>EventID"`
+	Message  string     `xml:"EventData>Data"`
+	Level    Levels     `xml:"System>EventID"`
+	Category Categories `xml:"System>Task"`
 }
 
 func getEventLog(t *testing.T, since time.Time) []Event {
@@ -64,9 +75,30 @@ func TestEventLogIntegration(t *testing.T) {
 	log.Println("E! Err message")
 	events := getEventLog(t, now)
 	assert.Len(t, events, 3)
-	assert.Contains(t, events, Event{Message: "Info message", Level: Info})
-	assert.Contains(t, events, Event{Message: "Warn message", Level: Warning})
-	assert.Contains(t, events, Event{Message: "Err message", Level: Error})
+	assert.Contains(t, events, Event{Message: "Info message", Level: Info, Category: InfoCategory})
+	assert.Contains(t, events, Event{Message: "Warn message", Level: Warning, Category: WarningCategory})
+	assert.Contains(t, events, Event{Message: "Err message", Level: Error, Category: ErrorCategory})
+}
+
+func TestDebugEventLogIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	prepareLogger(t)
+
+	config := LogConfig{
+		LogTarget: LogTargetEventlog,
+		Debug:     true,
+	}
+
+	SetupLogging(config)
+	//separate previous log messages by small delay
+	time.Sleep(time.Second)
+	now := time.Now()
+	log.Println("D! Debug message")
+	events := getEventLog(t, now)
+	assert.Len(t, events, 1)
+	assert.Contains(t, events, Event{Message: "Debug message", Level: Debug, Category: DebugCategory})
 }
 
 func TestRestrictedEventLogIntegration(t *testing.T) {
@@ -89,7 +121,7 @@ func TestRestrictedEventLogIntegration(t *testing.T) {
 	log.Println("E! Error message")
 	events := getEventLog(t, now)
 	assert.Len(t, events, 1)
-	assert.Contains(t, events, Event{Message: "Error message", Level: Error})
+	assert.Contains(t, events, Event{Message: "Error message", Level: Error, Category: ErrorCategory})
 }
 
 func prepareLogger(t *testing.T) {
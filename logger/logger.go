@@ -39,8 +39,16 @@ type LogConfig struct {
 	RotationMaxSize config.Size
 	// maximum rotated files to keep (older ones will be deleted)
 	RotationMaxArchives int
+	// maximum age of rotated files to keep (older ones will be deleted)
+	RotationMaxArchiveAge config.Duration
+	// maximum number of events per minute written to the event log
+	EventlogRateLimit int
+	// number of events written to the event log at once before rate limiting
+	EventlogBurst int
 	// pick a timezone to use when logging. or type 'local' for local time.
 	LogWithTimezone string
+	// colorize the log levels when logging to a terminal
+	LogColor bool
 }
 
 type LoggerCreator interface {
@@ -101,8 +109,15 @@ func newTelegrafWriter(w io.Writer, c LogConfig) (io.Writer, error) {
 		return nil, errors.New("error while setting logging timezone: " + err.Error())
 	}
 
+	// Colorize after filtering the levels, the level filter expects the
+	// level prefix at the same position in every line.
+	writer := w
+	if c.LogColor && w == os.Stderr && enableConsoleColor(os.Stderr) {
+		writer = &colorWriter{writer: w}
+	}
+
 	return &telegrafLog{
-		writer:         wlog.NewWriter(w),
+		writer:         wlog.NewWriter(writer),
 		internalWriter: w,
 		timezone:       tz,
 	}, nil
@@ -124,7 +139,7 @@ func (t *telegrafLogCreator) CreateLogger(config LogConfig) (io.Writer, error) {
 	case LogTargetFile:
 		if config.Logfile != "" {
 			var err error
-			if writer, err = rotate.NewFileWriter(config.Logfile, time.Duration(config.RotationInterval), int64(config.RotationMaxSize), config.RotationMaxArchives); err != nil {
+			if writer, err = rotate.NewFileWriter(config.Logfile, time.Duration(config.RotationInterval), int64(config.RotationMaxSize), config.RotationMaxArchives, time.Duration(config.RotationMaxArchiveAge)); err != nil {
 				log.Printf("E! Unable to open %s (%s), using stderr", config.Logfile, err)
 				writer = defaultWriter
 			}
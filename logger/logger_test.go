@@ -133,6 +133,31 @@ func TestLogTargetSettings(t *testing.T) {
 	assert.Equal(t, logger.internalWriter, os.Stderr)
 }
 
+func TestColorWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &colorWriter{writer: &buf}
+
+	line := []byte("2021-10-12T10:00:00Z E! [agent] failed\n")
+	n, err := w.Write(line)
+	require.NoError(t, err)
+	require.Equal(t, len(line), n)
+	require.Equal(t, "2021-10-12T10:00:00Z \x1b[31mE!\x1b[0m [agent] failed\n", buf.String())
+
+	buf.Reset()
+	_, err = w.Write([]byte("no level\n"))
+	require.NoError(t, err)
+	require.Equal(t, "no level\n", buf.String())
+}
+
+func TestColorOnlyOnTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newTelegrafWriter(&buf, LogConfig{LogColor: true})
+	require.NoError(t, err)
+	_, err = w.Write([]byte("W! TEST\n"))
+	require.NoError(t, err)
+	require.NotContains(t, buf.String(), "\x1b[")
+}
+
 func BenchmarkTelegrafLogWrite(b *testing.B) {
 	var msg = []byte("test")
 	var buf bytes.Buffer
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter limits the number of log messages using a token bucket of the
+// size of the burst, which is refilled at the given rate.
+type rateLimiter struct {
+	burst float64
+	// rate is the number of messages per second.
+	rate float64
+	now  func() time.Time
+
+	sync.Mutex
+	tokens float64
+	last   time.Time
+	// suppressed is the number of messages dropped since the last allowed
+	// message.
+	suppressed int
+}
+
+// newRateLimiter returns a limiter for the given number of messages per
+// minute, allowing bursts of the given size. The burst defaults to the rate.
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &rateLimiter{
+		burst:  float64(burst),
+		rate:   float64(perMinute) / 60,
+		now:    time.Now,
+		tokens: float64(burst),
+	}
+}
+
+// allow returns true if a message may be written and the number of messages
+// suppressed before it, which is reset afterwards.
+func (r *rateLimiter) allow() (bool, int) {
+	r.Lock()
+	defer r.Unlock()
+
+	now := r.now()
+	if !r.last.IsZero() {
+		r.tokens += now.Sub(r.last).Seconds() * r.rate
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+	}
+	r.last = now
+
+	if r.tokens < 1 {
+		r.suppressed++
+		return false, 0
+	}
+	r.tokens--
+	suppressed := r.suppressed
+	r.suppressed = 0
+	return true, suppressed
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := newRateLimiter(6, 2)
+	r.now = func() time.Time { return now }
+
+	// The burst is allowed at once.
+	for i := 0; i < 2; i++ {
+		ok, suppressed := r.allow()
+		require.True(t, ok)
+		require.Zero(t, suppressed)
+	}
+	for i := 0; i < 3; i++ {
+		ok, _ := r.allow()
+		require.False(t, ok)
+	}
+
+	// One message every ten seconds afterwards.
+	now = now.Add(5 * time.Second)
+	ok, _ := r.allow()
+	require.False(t, ok)
+	now = now.Add(5 * time.Second)
+	ok, suppressed := r.allow()
+	require.True(t, ok)
+	require.Equal(t, 4, suppressed)
+
+	// The bucket does not fill up beyond the burst.
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		ok, _ := r.allow()
+		require.True(t, ok)
+	}
+	ok, _ = r.allow()
+	require.False(t, ok)
+}
+
+func TestRateLimiterBurstDefaultsToRate(t *testing.T) {
+	r := newRateLimiter(3, 0)
+	r.now = func() time.Time { return time.Unix(0, 0) }
+	for i := 0; i < 3; i++ {
+		ok, _ := r.allow()
+		require.True(t, ok)
+	}
+	ok, _ := r.allow()
+	require.False(t, ok)
+}
@@ -63,6 +63,27 @@ func New(
 	return m
 }
 
+// FromLists returns a metric using the given tag and field lists without
+// copying them. The tags must be sorted by key and the keys of tags and fields
+// must be unique. The field values must already be of a supported type. It is
+// intended for parsers building the lists themselves to avoid intermediate
+// maps and allocations.
+func FromLists(
+	name string,
+	tags []*telegraf.Tag,
+	fields []*telegraf.Field,
+	tm time.Time,
+	tp telegraf.ValueType,
+) telegraf.Metric {
+	return &metric{
+		name:   name,
+		tags:   tags,
+		fields: fields,
+		tm:     tm,
+		tp:     tp,
+	}
+}
+
 // FromMetric returns a deep copy of the metric with any tracking information
 // removed.
 func FromMetric(other telegraf.Metric) telegraf.Metric {
@@ -11,6 +11,19 @@ type Initializer interface {
 	Init() error
 }
 
+// StatefulPlugin is an interface plugins can optionally implement to keep a
+// small state, e.g. bookmarks or offsets, across restarts of the agent. The
+// agent restores the state after Init and before the plugin is started, and
+// saves it every flush interval while running and after the plugin is
+// stopped.
+type StatefulPlugin interface {
+	// GetState returns the state to save. It is called while the plugin is
+	// running and must be safe for concurrent use.
+	GetState() ([]byte, error)
+	// SetState restores the state returned by GetState before the restart.
+	SetState(state []byte) error
+}
+
 // PluginDescriber contains the functions all plugins must implement to describe
 // themselves to Telegraf. Note that all plugins may define a logger that is
 // not part of the interface, but will receive an injected logger if it's set.
@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
 	"golang.org/x/text/encoding/unicode"
 )
 
@@ -16,17 +18,24 @@ import (
 // encoding if you want invalid bytes replaced using the the unicode
 // replacement character.
 //
-// Detection of utf-16 endianness using the BOM is not currently provided due
-// to the tail input plugins requirement to be able to start at the middle or
-// end of the file.
+// The "utf-16" encoding detects the endianness using the BOM and falls back to
+// little endian without a BOM.  It is only suitable for data read from the
+// start, the tail input plugin can start at the middle or end of the file and
+// should use one of the explicit utf-16 encodings.
 func NewDecoder(enc string) (*Decoder, error) {
 	switch enc {
 	case "utf-8":
 		return &Decoder{Transformer: unicode.UTF8.NewDecoder()}, nil
+	case "utf-16":
+		return newDecoder(unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()), nil
 	case "utf-16le":
 		return newDecoder(unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()), nil
 	case "utf-16be":
 		return newDecoder(unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder()), nil
+	case "windows-1252":
+		return newDecoder(charmap.Windows1252.NewDecoder()), nil
+	case "shift-jis":
+		return newDecoder(japanese.ShiftJIS.NewDecoder()), nil
 	case "none", "":
 		return newDecoder(encoding.Nop.NewDecoder()), nil
 	}
@@ -58,6 +58,36 @@ func TestDecoder(t *testing.T) {
 			input:    []byte("\xfe\xff\x00h\x00o\x00w\x00d\x00y"),
 			expected: []byte("\xef\xbb\xbfhowdy"),
 		},
+		{
+			name:     "utf-16 decoder little endian BOM",
+			encoding: "utf-16",
+			input:    []byte("\xff\xfeh\x00o\x00w\x00d\x00y\x00"),
+			expected: []byte("howdy"),
+		},
+		{
+			name:     "utf-16 decoder big endian BOM",
+			encoding: "utf-16",
+			input:    []byte("\xfe\xff\x00h\x00o\x00w\x00d\x00y"),
+			expected: []byte("howdy"),
+		},
+		{
+			name:     "utf-16 decoder no BOM",
+			encoding: "utf-16",
+			input:    []byte("h\x00o\x00w\x00d\x00y\x00"),
+			expected: []byte("howdy"),
+		},
+		{
+			name:     "windows-1252 decoder",
+			encoding: "windows-1252",
+			input:    []byte("caf\xe9 \x80"),
+			expected: []byte("café €"),
+		},
+		{
+			name:     "shift-jis decoder",
+			encoding: "shift-jis",
+			input:    []byte("\x93\xfa\x96\x7b"),
+			expected: []byte("日本"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+
+	"github.com/influxdata/telegraf/internal"
 )
 
 type HTTPProxy struct {
@@ -20,5 +22,5 @@ func (p *HTTPProxy) Proxy() (proxyFunc, error) {
 		}
 		return http.ProxyURL(url), nil
 	}
-	return http.ProxyFromEnvironment, nil
+	return internal.Proxy, nil
 }
@@ -0,0 +1,56 @@
+//go:build windows
+// +build windows
+
+package wmi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QueryFunc runs the WQL query in the namespace and calls the function with
+// the properties of every returned object, like Connection.Query. Plugins
+// keep the function to replace the queries in tests.
+type QueryFunc func(namespace, query string, fn func(properties map[string]interface{}) error) error
+
+// AddUintFields adds the properties as unsigned integer fields with the given
+// names, skipping missing properties.
+func AddUintFields(fields map[string]interface{}, properties map[string]interface{}, names map[string]string) {
+	for property, field := range names {
+		value, ok := properties[property]
+		if !ok {
+			continue
+		}
+		if v, err := Uint64(value); err == nil {
+			fields[field] = v
+		}
+	}
+}
+
+// FakeQuery returns a QueryFunc for tests returning the objects of the class
+// named in the FROM clause of the query. Queries of other classes fail.
+func FakeQuery(objects map[string][]map[string]interface{}) QueryFunc {
+	return func(_, query string, fn func(properties map[string]interface{}) error) error {
+		items, ok := objects[queryClass(query)]
+		if !ok {
+			return fmt.Errorf("invalid class in query %q", query)
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// queryClass returns the class following the FROM keyword of the query.
+func queryClass(query string) string {
+	fields := strings.Fields(query)
+	for i := 0; i+1 < len(fields); i++ {
+		if strings.EqualFold(fields[i], "FROM") {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,253 @@
+//go:build windows
+// +build windows
+
+package wmi
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+)
+
+// Flags of the WMI scripting API.
+const (
+	wbemConnectFlagUseMaxWait = 0x80
+	wbemFlagReturnImmediately = 0x10
+	wbemFlagForwardOnly       = 0x20
+)
+
+// sFalse is returned by CoInitializeEx if COM is initialized already.
+const sFalse = 0x00000001
+
+// Connection are the host and credentials to connect to WMI with. The local
+// host is used if the host is empty, the credentials of the process are used
+// if the username is empty.
+type Connection struct {
+	Host     string
+	Username string
+	Password string
+}
+
+// Query runs the WQL query in the namespace and calls the function with the
+// properties of every returned object. Null values are left out, arrays are
+// returned as []interface{}.
+func (c *Connection) Query(namespace, query string, fn func(properties map[string]interface{}) error) error {
+	return c.connect(namespace, func(service *ole.IDispatch) error {
+		resultRaw, err := oleutil.CallMethod(service, "ExecQuery",
+			query, "WQL", wbemFlagReturnImmediately|wbemFlagForwardOnly)
+		if err != nil {
+			return err
+		}
+		result := resultRaw.ToIDispatch()
+		defer resultRaw.Clear() //nolint:errcheck // nothing to do on error
+
+		return oleutil.ForEach(result, func(v *ole.VARIANT) error {
+			item := v.ToIDispatch()
+			defer item.Release()
+
+			properties, err := objectProperties(item)
+			if err != nil {
+				return err
+			}
+			return fn(properties)
+		})
+	})
+}
+
+// CallMethod calls the method of the object with the given path, e.g.
+// 'Win32_Process.Handle="4"' or the class name for static methods, in the
+// namespace and returns the output parameters.
+func (c *Connection) CallMethod(namespace, path, method string, params map[string]interface{}) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	err := c.connect(namespace, func(service *ole.IDispatch) error {
+		objectRaw, err := oleutil.CallMethod(service, "Get", path)
+		if err != nil {
+			return fmt.Errorf("getting object %q failed: %w", path, err)
+		}
+		object := objectRaw.ToIDispatch()
+		defer objectRaw.Clear() //nolint:errcheck // nothing to do on error
+
+		args := []interface{}{method}
+		if len(params) > 0 {
+			in, err := inParameters(object, method, params)
+			if err != nil {
+				return err
+			}
+			defer in.Release()
+			args = append(args, in)
+		}
+
+		outRaw, err := oleutil.CallMethod(object, "ExecMethod_", args...)
+		if err != nil {
+			return fmt.Errorf("calling method %q failed: %w", method, err)
+		}
+		defer outRaw.Clear() //nolint:errcheck // nothing to do on error
+
+		out, err = objectProperties(outRaw.ToIDispatch())
+		return err
+	})
+	return out, err
+}
+
+// WithCOM calls the function with COM initialized, for plugins using COM
+// APIs other than WMI. COM is initialized per thread, so the goroutine is
+// locked to its thread until the function returns.
+func WithCOM(fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		var oleErr *ole.OleError
+		if !errors.As(err, &oleErr) || oleErr.Code() != sFalse {
+			return fmt.Errorf("initializing COM failed: %w", err)
+		}
+	}
+	defer ole.CoUninitialize()
+
+	return fn()
+}
+
+// connect connects to the namespace and calls the function with the
+// SWbemServices object of the connection.
+func (c *Connection) connect(namespace string, fn func(service *ole.IDispatch) error) error {
+	return WithCOM(func() error {
+		return c.connectCOM(namespace, fn)
+	})
+}
+
+func (c *Connection) connectCOM(namespace string, fn func(service *ole.IDispatch) error) error {
+	unknown, err := oleutil.CreateObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return fmt.Errorf("creating locator failed: %w", err)
+	}
+	defer unknown.Release()
+
+	locator, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return fmt.Errorf("creating locator failed: %w", err)
+	}
+	defer locator.Release()
+
+	// Credentials cannot be used for the local host.
+	var username, password string
+	if c.Host != "" {
+		username, password = c.Username, c.Password
+	}
+	serviceRaw, err := oleutil.CallMethod(locator, "ConnectServer",
+		c.Host, namespace, username, password, "", "", wbemConnectFlagUseMaxWait)
+	if err != nil {
+		return fmt.Errorf("connecting to namespace %q failed: %w", namespace, err)
+	}
+	defer serviceRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	return fn(serviceRaw.ToIDispatch())
+}
+
+// inParameters returns an instance of the input parameters of the method
+// with the given values set.
+func inParameters(object *ole.IDispatch, method string, params map[string]interface{}) (*ole.IDispatch, error) {
+	methodsRaw, err := oleutil.GetProperty(object, "Methods_")
+	if err != nil {
+		return nil, err
+	}
+	defer methodsRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	methodRaw, err := oleutil.CallMethod(methodsRaw.ToIDispatch(), "Item", method)
+	if err != nil {
+		return nil, fmt.Errorf("unknown method %q: %w", method, err)
+	}
+	defer methodRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	classRaw, err := oleutil.GetProperty(methodRaw.ToIDispatch(), "InParameters")
+	if err != nil {
+		return nil, err
+	}
+	defer classRaw.Clear() //nolint:errcheck // nothing to do on error
+	if classRaw.VT != ole.VT_DISPATCH || classRaw.Val == 0 {
+		return nil, fmt.Errorf("method %q has no input parameters", method)
+	}
+
+	inRaw, err := oleutil.CallMethod(classRaw.ToIDispatch(), "SpawnInstance_")
+	if err != nil {
+		return nil, err
+	}
+	in := inRaw.ToIDispatch()
+
+	for name, value := range params {
+		if _, err := oleutil.PutProperty(in, name, value); err != nil {
+			in.Release()
+			return nil, fmt.Errorf("setting parameter %q failed: %w", name, err)
+		}
+	}
+	return in, nil
+}
+
+// objectProperties returns the properties of the WMI object.
+func objectProperties(item *ole.IDispatch) (map[string]interface{}, error) {
+	propertiesRaw, err := oleutil.GetProperty(item, "Properties_")
+	if err != nil {
+		return nil, err
+	}
+	defer propertiesRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	values := make(map[string]interface{})
+	err = oleutil.ForEach(propertiesRaw.ToIDispatch(), func(v *ole.VARIANT) error {
+		property := v.ToIDispatch()
+		defer property.Release()
+
+		nameRaw, err := oleutil.GetProperty(property, "Name")
+		if err != nil {
+			return err
+		}
+		name := nameRaw.ToString()
+		nameRaw.Clear() //nolint:errcheck,revive // nothing to do on error
+
+		valueRaw, err := oleutil.GetProperty(property, "Value")
+		if err != nil {
+			return err
+		}
+		defer valueRaw.Clear() //nolint:errcheck // nothing to do on error
+
+		if array := valueRaw.ToArray(); array != nil {
+			values[name] = array.ToValueArray()
+		} else if value := valueRaw.Value(); value != nil {
+			values[name] = value
+		}
+		return nil
+	})
+	return values, err
+}
+
+// Uint64 converts the property value to an unsigned integer. WMI returns
+// 64 bit integers as strings.
+func Uint64(value interface{}) (uint64, error) {
+	return strconv.ParseUint(fmt.Sprint(value), 10, 64)
+}
+
+// Float64 converts the property value to a float.
+func Float64(value interface{}) (float64, error) {
+	return strconv.ParseFloat(fmt.Sprint(value), 64)
+}
+
+// Time converts the property value in the CIM datetime format, e.g.
+// '20211014120000.000000+060' with the offset to UTC in minutes, to a time.
+func Time(value interface{}) (time.Time, error) {
+	s := fmt.Sprint(value)
+	if len(s) != 25 || (s[21] != '+' && s[21] != '-') {
+		return time.Time{}, fmt.Errorf("invalid datetime %q", s)
+	}
+	offset, err := strconv.Atoi(s[22:])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid datetime %q", s)
+	}
+	if s[21] == '-' {
+		offset = -offset
+	}
+	zone := time.FixedZone("", offset*60)
+	return time.ParseInLocation("20060102150405.000000", s[:21], zone)
+}
@@ -0,0 +1,59 @@
+//go:build windows
+// +build windows
+
+package wmi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTime(t *testing.T) {
+	tm, err := Time("20211014120000.500000+060")
+	require.NoError(t, err)
+	require.True(t, time.Date(2021, 10, 14, 11, 0, 0, 500000000, time.UTC).Equal(tm))
+
+	tm, err = Time("20211014120000.000000-300")
+	require.NoError(t, err)
+	require.True(t, time.Date(2021, 10, 14, 17, 0, 0, 0, time.UTC).Equal(tm))
+
+	_, err = Time("20211014120000")
+	require.Error(t, err)
+	_, err = Time("20211014120000.000000*000")
+	require.Error(t, err)
+}
+
+func TestAddUintFields(t *testing.T) {
+	fields := make(map[string]interface{})
+	AddUintFields(fields, map[string]interface{}{
+		"BytesPersec": "1024",
+		"Connections": int32(5),
+		"Name":        "_Total",
+	}, map[string]string{
+		"BytesPersec": "bytes_per_sec",
+		"Connections": "connections",
+		"Name":        "name",
+		"Missing":     "missing",
+	})
+	require.Equal(t, map[string]interface{}{"bytes_per_sec": uint64(1024), "connections": uint64(5)}, fields)
+}
+
+func TestFakeQuery(t *testing.T) {
+	query := FakeQuery(map[string][]map[string]interface{}{
+		"Win32_Service":   {{"Name": "a"}, {"Name": "b"}},
+		"Win32_Service_X": {{"Name": "x"}},
+	})
+
+	var names []interface{}
+	err := query(`root\cimv2`, "SELECT Name FROM Win32_Service WHERE State = 'Running'", func(p map[string]interface{}) error {
+		names = append(names, p["Name"])
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"a", "b"}, names)
+
+	err = query(`root\cimv2`, "SELECT Name FROM Win32_Process", func(map[string]interface{}) error { return nil })
+	require.Error(t, err)
+}
@@ -0,0 +1,260 @@
+//go:build windows
+// +build windows
+
+package active_directory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the DFS replication groups to report the state of, none if
+  ## empty. Globs accepted.
+  # dfsr_replication_groups = ["Domain System Volume"]
+
+  ## Report the number of files waiting to be replicated to each partner of
+  ## the DFS replication groups. Requires Telegraf to run with an account
+  ## allowed to query WMI on the partners.
+  # dfsr_backlog = false
+`
+
+// Namespaces of the queried classes.
+const (
+	cimv2Namespace          = `root\cimv2`
+	directoryNamespace      = `root\MicrosoftActiveDirectory`
+	dfsReplicationNamespace = `root\MicrosoftDfs`
+)
+
+// callFunc calls the method of the object on the host, the local host if
+// empty, and returns the output parameters.
+type callFunc func(host, namespace, path, method string, params map[string]interface{}) (map[string]interface{}, error)
+
+// ActiveDirectory collects the health metrics of a domain controller.
+type ActiveDirectory struct {
+	DFSRReplicationGroups []string `toml:"dfsr_replication_groups"`
+	DFSRBacklog           bool     `toml:"dfsr_backlog"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	groupFilter filter.Filter
+	query       wmi.QueryFunc
+	call        callFunc
+	now         func() time.Time
+}
+
+func (a *ActiveDirectory) Description() string {
+	return "Collect replication, LDAP and authentication metrics of a domain controller"
+}
+
+func (a *ActiveDirectory) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *ActiveDirectory) Init() error {
+	f, err := filter.Compile(a.DFSRReplicationGroups)
+	if err != nil {
+		return fmt.Errorf("compiling dfsr_replication_groups failed: %w", err)
+	}
+	a.groupFilter = f
+	return nil
+}
+
+func (a *ActiveDirectory) Gather(acc telegraf.Accumulator) error {
+	if err := a.gatherDirectoryService(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying directory service counters failed: %w", err))
+	}
+	if err := a.gatherReplication(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying replication partners failed: %w", err))
+	}
+	if a.groupFilter != nil {
+		if err := a.gatherDFSR(acc); err != nil {
+			acc.AddError(fmt.Errorf("querying DFS replication failed: %w", err))
+		}
+	}
+	return nil
+}
+
+// gatherDirectoryService adds the counters of the directory service.
+func (a *ActiveDirectory) gatherDirectoryService(acc telegraf.Accumulator) error {
+	return a.query(cimv2Namespace,
+		"SELECT DRAPendingReplicationSynchronizations, DRAPendingReplicationOperations, DRAInboundBytesTotalPersec, DRAOutboundBytesTotalPersec, LDAPBindTime, LDAPSuccessfulBindsPersec, LDAPClientSessions, LDAPSearchesPersec, KerberosAuthentications, NTLMAuthentications FROM Win32_PerfFormattedData_NTDS_NTDS",
+		func(p map[string]interface{}) error {
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"DRAPendingReplicationSynchronizations": "dra_pending_synchronizations",
+				"DRAPendingReplicationOperations":       "dra_pending_operations",
+				"DRAInboundBytesTotalPersec":            "dra_inbound_bytes_per_sec",
+				"DRAOutboundBytesTotalPersec":           "dra_outbound_bytes_per_sec",
+				"LDAPBindTime":                          "ldap_bind_time_ms",
+				"LDAPSuccessfulBindsPersec":             "ldap_binds_per_sec",
+				"LDAPClientSessions":                    "ldap_client_sessions",
+				"LDAPSearchesPersec":                    "ldap_searches_per_sec",
+				"KerberosAuthentications":               "kerberos_authentications_per_sec",
+				"NTLMAuthentications":                   "ntlm_authentications_per_sec",
+			})
+			acc.AddFields("active_directory", fields, nil)
+			return nil
+		})
+}
+
+// partnerKey identifies the replication of a naming context from a partner.
+type partnerKey struct {
+	namingContext string
+	partner       string
+}
+
+// gatherReplication adds the inbound replication status of the naming
+// contexts by replication partner.
+func (a *ActiveDirectory) gatherReplication(acc telegraf.Accumulator) error {
+	// The pending operations reference the partner by the distinguished name
+	// of its NTDS settings object.
+	pending := make(map[partnerKey]int)
+	err := a.query(directoryNamespace,
+		"SELECT NamingContextDN, DsaDN FROM MSAD_ReplPendingOp",
+		func(p map[string]interface{}) error {
+			key := partnerKey{
+				namingContext: strings.ToUpper(fmt.Sprint(p["NamingContextDN"])),
+				partner:       strings.ToUpper(fmt.Sprint(p["DsaDN"])),
+			}
+			pending[key]++
+			return nil
+		})
+	if err != nil {
+		acc.AddError(fmt.Errorf("querying pending replication operations failed: %w", err))
+	}
+
+	now := a.now()
+	return a.query(directoryNamespace,
+		"SELECT NamingContextDN, SourceDsaCN, SourceDsaDN, SourceDsaSite, NumConsecutiveSyncFailures, LastSyncResult, TimeOfLastSyncSuccess FROM MSAD_ReplNeighbor",
+		func(p map[string]interface{}) error {
+			namingContext := fmt.Sprint(p["NamingContextDN"])
+			key := partnerKey{
+				namingContext: strings.ToUpper(namingContext),
+				partner:       strings.ToUpper(fmt.Sprint(p["SourceDsaDN"])),
+			}
+
+			fields := map[string]interface{}{
+				"pending_operations": pending[key],
+			}
+			wmi.AddUintFields(fields, p, map[string]string{
+				"NumConsecutiveSyncFailures": "consecutive_sync_failures",
+				"LastSyncResult":             "last_sync_result",
+			})
+			// Partners that never replicated report the zero CIM datetime.
+			if success, err := wmi.Time(p["TimeOfLastSyncSuccess"]); err == nil && success.Year() > 1601 {
+				fields["last_sync_success_time"] = success.Unix()
+				fields["replication_latency"] = int64(now.Sub(success).Seconds())
+			}
+
+			tags := map[string]string{
+				"naming_context": namingContext,
+				"partner":        fmt.Sprint(p["SourceDsaCN"]),
+			}
+			if site, ok := p["SourceDsaSite"]; ok {
+				tags["partner_site"] = fmt.Sprint(site)
+			}
+			acc.AddFields("active_directory_replication", fields, tags)
+			return nil
+		})
+}
+
+// replicatedFolder is a folder replicated by DFS Replication.
+type replicatedFolder struct {
+	id    string
+	name  string
+	group string
+}
+
+// gatherDFSR adds the state of the replicated folders of the replication
+// groups matching the filter and, if enabled, their backlog by partner.
+func (a *ActiveDirectory) gatherDFSR(acc telegraf.Accumulator) error {
+	folders := make(map[string][]replicatedFolder)
+	err := a.query(dfsReplicationNamespace,
+		"SELECT ReplicatedFolderGuid, ReplicatedFolderName, ReplicationGroupGuid, ReplicationGroupName, State FROM DfsrReplicatedFolderInfo",
+		func(p map[string]interface{}) error {
+			group := fmt.Sprint(p["ReplicationGroupName"])
+			if !a.groupFilter.Match(group) {
+				return nil
+			}
+			folder := replicatedFolder{
+				id:    fmt.Sprint(p["ReplicatedFolderGuid"]),
+				name:  fmt.Sprint(p["ReplicatedFolderName"]),
+				group: group,
+			}
+			groupID := strings.ToUpper(fmt.Sprint(p["ReplicationGroupGuid"]))
+			folders[groupID] = append(folders[groupID], folder)
+
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{"State": "state"})
+			tags := map[string]string{"replication_group": group, "replicated_folder": folder.name}
+			acc.AddFields("active_directory_dfsr", fields, tags)
+			return nil
+		})
+	if err != nil || !a.DFSRBacklog || len(folders) == 0 {
+		return err
+	}
+
+	// The backlog to a partner is the number of files missing in the version
+	// vector of the partner compared to the local folder.
+	return a.query(dfsReplicationNamespace,
+		"SELECT PartnerName, ReplicationGroupGuid FROM DfsrConnectionInfo WHERE Inbound = FALSE",
+		func(p map[string]interface{}) error {
+			partner := fmt.Sprint(p["PartnerName"])
+			for _, folder := range folders[strings.ToUpper(fmt.Sprint(p["ReplicationGroupGuid"]))] {
+				backlog, err := a.backlog(partner, folder)
+				if err != nil {
+					acc.AddError(fmt.Errorf("querying backlog of %q to %q failed: %w", folder.name, partner, err))
+					continue
+				}
+				tags := map[string]string{
+					"replication_group": folder.group,
+					"replicated_folder": folder.name,
+					"partner":           partner,
+				}
+				acc.AddFields("active_directory_dfsr_backlog", map[string]interface{}{"backlog_files": backlog}, tags)
+			}
+			return nil
+		})
+}
+
+// backlog returns the number of files of the folder waiting to be replicated
+// to the partner.
+func (a *ActiveDirectory) backlog(partner string, folder replicatedFolder) (uint64, error) {
+	path := fmt.Sprintf("DfsrReplicatedFolderInfo.ReplicatedFolderGuid=%q", folder.id)
+
+	out, err := a.call(partner, dfsReplicationNamespace, path, "GetVersionVector", nil)
+	if err != nil {
+		return 0, fmt.Errorf("getting version vector failed: %w", err)
+	}
+	vector, ok := out["VersionVector"]
+	if !ok {
+		return 0, fmt.Errorf("no version vector returned")
+	}
+
+	out, err = a.call("", dfsReplicationNamespace, path, "GetOutboundBacklogFileCount",
+		map[string]interface{}{"VersionVector": vector})
+	if err != nil {
+		return 0, err
+	}
+	return wmi.Uint64(out["BacklogFileCount"])
+}
+
+func init() {
+	inputs.Add("active_directory", func() telegraf.Input {
+		return &ActiveDirectory{
+			DFSRReplicationGroups: []string{"Domain System Volume"},
+			query:                 (&wmi.Connection{}).Query,
+			call: func(host, namespace, path, method string, params map[string]interface{}) (map[string]interface{}, error) {
+				return (&wmi.Connection{Host: host}).CallMethod(namespace, path, method, params)
+			},
+			now: time.Now,
+		}
+	})
+}
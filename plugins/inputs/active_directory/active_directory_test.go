@@ -0,0 +1,163 @@
+//go:build windows
+// +build windows
+
+package active_directory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	now := time.Date(2021, 10, 14, 12, 0, 0, 0, time.UTC)
+	a := &ActiveDirectory{
+		DFSRReplicationGroups: []string{"Domain System Volume"},
+		DFSRBacklog:           true,
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Win32_PerfFormattedData_NTDS_NTDS": {
+				{
+					"DRAPendingReplicationSynchronizations": int32(1),
+					"DRAPendingReplicationOperations":       int32(2),
+					"DRAInboundBytesTotalPersec":            int32(1024),
+					"DRAOutboundBytesTotalPersec":           int32(2048),
+					"LDAPBindTime":                          int32(3),
+					"LDAPSuccessfulBindsPersec":             int32(10),
+					"LDAPClientSessions":                    int32(50),
+					"LDAPSearchesPersec":                    int32(100),
+					"KerberosAuthentications":               int32(20),
+					"NTLMAuthentications":                   int32(5),
+				},
+			},
+			"MSAD_ReplPendingOp": {
+				{"NamingContextDN": "DC=example,DC=com", "DsaDN": "CN=NTDS Settings,CN=DC2,CN=Servers,CN=HQ"},
+				{"NamingContextDN": "DC=example,DC=com", "DsaDN": "CN=NTDS Settings,CN=DC2,CN=Servers,CN=HQ"},
+			},
+			"MSAD_ReplNeighbor": {
+				{
+					"NamingContextDN":            "DC=example,DC=com",
+					"SourceDsaCN":                "DC2",
+					"SourceDsaDN":                "CN=NTDS Settings,CN=DC2,CN=Servers,CN=HQ",
+					"SourceDsaSite":              "HQ",
+					"NumConsecutiveSyncFailures": int32(0),
+					"LastSyncResult":             int32(0),
+					"TimeOfLastSyncSuccess":      "20211014115500.000000+000",
+				},
+				{
+					"NamingContextDN":            "CN=Configuration,DC=example,DC=com",
+					"SourceDsaCN":                "DC3",
+					"SourceDsaDN":                "CN=NTDS Settings,CN=DC3,CN=Servers,CN=Branch",
+					"SourceDsaSite":              "Branch",
+					"NumConsecutiveSyncFailures": int32(4),
+					"LastSyncResult":             int32(1722),
+					"TimeOfLastSyncSuccess":      "16010101000000.000000+000",
+				},
+			},
+			"DfsrReplicatedFolderInfo": {
+				{
+					"ReplicatedFolderGuid": "F1",
+					"ReplicatedFolderName": "SYSVOL Share",
+					"ReplicationGroupGuid": "G1",
+					"ReplicationGroupName": "Domain System Volume",
+					"State":                int32(4),
+				},
+				{
+					"ReplicatedFolderGuid": "F2",
+					"ReplicatedFolderName": "Files",
+					"ReplicationGroupGuid": "G2",
+					"ReplicationGroupName": "Branch Files",
+					"State":                int32(4),
+				},
+			},
+			"DfsrConnectionInfo": {
+				{"PartnerName": "DC2", "ReplicationGroupGuid": "G1"},
+				{"PartnerName": "FS1", "ReplicationGroupGuid": "G2"},
+			},
+		}),
+		call: func(host, _, path, method string, params map[string]interface{}) (map[string]interface{}, error) {
+			require.Equal(t, `DfsrReplicatedFolderInfo.ReplicatedFolderGuid="F1"`, path)
+			switch method {
+			case "GetVersionVector":
+				require.Equal(t, "DC2", host)
+				return map[string]interface{}{"VersionVector": "vv-dc2"}, nil
+			case "GetOutboundBacklogFileCount":
+				require.Equal(t, "", host)
+				require.Equal(t, "vv-dc2", params["VersionVector"])
+				return map[string]interface{}{"BacklogFileCount": int32(7)}, nil
+			}
+			return nil, fmt.Errorf("unexpected method %q", method)
+		},
+		now: func() time.Time { return now },
+	}
+	require.NoError(t, a.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, a.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("active_directory",
+			map[string]string{},
+			map[string]interface{}{
+				"dra_pending_synchronizations":     uint64(1),
+				"dra_pending_operations":           uint64(2),
+				"dra_inbound_bytes_per_sec":        uint64(1024),
+				"dra_outbound_bytes_per_sec":       uint64(2048),
+				"ldap_bind_time_ms":                uint64(3),
+				"ldap_binds_per_sec":               uint64(10),
+				"ldap_client_sessions":             uint64(50),
+				"ldap_searches_per_sec":            uint64(100),
+				"kerberos_authentications_per_sec": uint64(20),
+				"ntlm_authentications_per_sec":     uint64(5),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("active_directory_replication",
+			map[string]string{"naming_context": "DC=example,DC=com", "partner": "DC2", "partner_site": "HQ"},
+			map[string]interface{}{
+				"pending_operations":        2,
+				"consecutive_sync_failures": uint64(0),
+				"last_sync_result":          uint64(0),
+				"last_sync_success_time":    now.Add(-5 * time.Minute).Unix(),
+				"replication_latency":       int64(300),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("active_directory_replication",
+			map[string]string{"naming_context": "CN=Configuration,DC=example,DC=com", "partner": "DC3", "partner_site": "Branch"},
+			map[string]interface{}{
+				"pending_operations":        0,
+				"consecutive_sync_failures": uint64(4),
+				"last_sync_result":          uint64(1722),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("active_directory_dfsr",
+			map[string]string{"replication_group": "Domain System Volume", "replicated_folder": "SYSVOL Share"},
+			map[string]interface{}{"state": uint64(4)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("active_directory_dfsr_backlog",
+			map[string]string{"replication_group": "Domain System Volume", "replicated_folder": "SYSVOL Share", "partner": "DC2"},
+			map[string]interface{}{"backlog_files": uint64(7)},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherWithoutDFSR(t *testing.T) {
+	a := &ActiveDirectory{
+		query: func(_, query string, _ func(map[string]interface{}) error) error {
+			require.NotContains(t, query, "Dfsr")
+			return nil
+		},
+		now: time.Now,
+	}
+	require.NoError(t, a.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, a.Gather(&acc))
+	require.Empty(t, acc.Errors)
+}
@@ -2,6 +2,7 @@ package all
 
 import (
 	//Blank imports for plugins to register themselves
+	_ "github.com/influxdata/telegraf/plugins/inputs/active_directory"
 	_ "github.com/influxdata/telegraf/plugins/inputs/activemq"
 	_ "github.com/influxdata/telegraf/plugins/inputs/aerospike"
 	_ "github.com/influxdata/telegraf/plugins/inputs/aliyuncms"
@@ -15,6 +16,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/beanstalkd"
 	_ "github.com/influxdata/telegraf/plugins/inputs/beat"
 	_ "github.com/influxdata/telegraf/plugins/inputs/bind"
+	_ "github.com/influxdata/telegraf/plugins/inputs/bitlocker"
 	_ "github.com/influxdata/telegraf/plugins/inputs/bond"
 	_ "github.com/influxdata/telegraf/plugins/inputs/burrow"
 	_ "github.com/influxdata/telegraf/plugins/inputs/cassandra"
@@ -32,6 +34,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/couchdb"
 	_ "github.com/influxdata/telegraf/plugins/inputs/cpu"
 	_ "github.com/influxdata/telegraf/plugins/inputs/csgo"
+	_ "github.com/influxdata/telegraf/plugins/inputs/csv_fs"
 	_ "github.com/influxdata/telegraf/plugins/inputs/dcos"
 	_ "github.com/influxdata/telegraf/plugins/inputs/directory_monitor"
 	_ "github.com/influxdata/telegraf/plugins/inputs/disk"
@@ -41,15 +44,18 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/dns_query"
 	_ "github.com/influxdata/telegraf/plugins/inputs/docker"
 	_ "github.com/influxdata/telegraf/plugins/inputs/docker_log"
+	_ "github.com/influxdata/telegraf/plugins/inputs/dotnet"
 	_ "github.com/influxdata/telegraf/plugins/inputs/dovecot"
 	_ "github.com/influxdata/telegraf/plugins/inputs/dpdk"
 	_ "github.com/influxdata/telegraf/plugins/inputs/ecs"
 	_ "github.com/influxdata/telegraf/plugins/inputs/elasticsearch"
 	_ "github.com/influxdata/telegraf/plugins/inputs/elasticsearch_query"
 	_ "github.com/influxdata/telegraf/plugins/inputs/ethtool"
+	_ "github.com/influxdata/telegraf/plugins/inputs/etw"
 	_ "github.com/influxdata/telegraf/plugins/inputs/eventhub_consumer"
 	_ "github.com/influxdata/telegraf/plugins/inputs/exec"
 	_ "github.com/influxdata/telegraf/plugins/inputs/execd"
+	_ "github.com/influxdata/telegraf/plugins/inputs/exchange"
 	_ "github.com/influxdata/telegraf/plugins/inputs/fail2ban"
 	_ "github.com/influxdata/telegraf/plugins/inputs/fibaro"
 	_ "github.com/influxdata/telegraf/plugins/inputs/file"
@@ -66,7 +72,10 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/http_listener_v2"
 	_ "github.com/influxdata/telegraf/plugins/inputs/http_response"
 	_ "github.com/influxdata/telegraf/plugins/inputs/httpjson"
+	_ "github.com/influxdata/telegraf/plugins/inputs/hyperv"
+	_ "github.com/influxdata/telegraf/plugins/inputs/hyperv_replica"
 	_ "github.com/influxdata/telegraf/plugins/inputs/icinga2"
+	_ "github.com/influxdata/telegraf/plugins/inputs/iis"
 	_ "github.com/influxdata/telegraf/plugins/inputs/infiniband"
 	_ "github.com/influxdata/telegraf/plugins/inputs/influxdb"
 	_ "github.com/influxdata/telegraf/plugins/inputs/influxdb_listener"
@@ -113,6 +122,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/mongodb"
 	_ "github.com/influxdata/telegraf/plugins/inputs/monit"
 	_ "github.com/influxdata/telegraf/plugins/inputs/mqtt_consumer"
+	_ "github.com/influxdata/telegraf/plugins/inputs/msmq"
 	_ "github.com/influxdata/telegraf/plugins/inputs/multifile"
 	_ "github.com/influxdata/telegraf/plugins/inputs/mysql"
 	_ "github.com/influxdata/telegraf/plugins/inputs/nats"
@@ -158,6 +168,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/raindrops"
 	_ "github.com/influxdata/telegraf/plugins/inputs/ras"
 	_ "github.com/influxdata/telegraf/plugins/inputs/ravendb"
+	_ "github.com/influxdata/telegraf/plugins/inputs/rds"
 	_ "github.com/influxdata/telegraf/plugins/inputs/redfish"
 	_ "github.com/influxdata/telegraf/plugins/inputs/redis"
 	_ "github.com/influxdata/telegraf/plugins/inputs/rethinkdb"
@@ -167,6 +178,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/sensors"
 	_ "github.com/influxdata/telegraf/plugins/inputs/sflow"
 	_ "github.com/influxdata/telegraf/plugins/inputs/smart"
+	_ "github.com/influxdata/telegraf/plugins/inputs/smb"
 	_ "github.com/influxdata/telegraf/plugins/inputs/snmp"
 	_ "github.com/influxdata/telegraf/plugins/inputs/snmp_legacy"
 	_ "github.com/influxdata/telegraf/plugins/inputs/snmp_trap"
@@ -196,12 +208,35 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/inputs/uwsgi"
 	_ "github.com/influxdata/telegraf/plugins/inputs/varnish"
 	_ "github.com/influxdata/telegraf/plugins/inputs/vsphere"
+	_ "github.com/influxdata/telegraf/plugins/inputs/vss"
 	_ "github.com/influxdata/telegraf/plugins/inputs/webhooks"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_accounts"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_certstore"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_cluster"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_containers"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_dhcp"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_disk_health"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_dns"
 	_ "github.com/influxdata/telegraf/plugins/inputs/win_eventlog"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_gpu"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_netstat"
 	_ "github.com/influxdata/telegraf/plugins/inputs/win_perf_counters"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_power"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_printing"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_registry"
 	_ "github.com/influxdata/telegraf/plugins/inputs/win_services"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_software"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_system"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_time"
+	_ "github.com/influxdata/telegraf/plugins/inputs/win_volumes"
+	_ "github.com/influxdata/telegraf/plugins/inputs/windows_defender"
+	_ "github.com/influxdata/telegraf/plugins/inputs/windows_firewall"
+	_ "github.com/influxdata/telegraf/plugins/inputs/windows_scheduled_tasks"
+	_ "github.com/influxdata/telegraf/plugins/inputs/windows_update"
 	_ "github.com/influxdata/telegraf/plugins/inputs/wireguard"
 	_ "github.com/influxdata/telegraf/plugins/inputs/wireless"
+	_ "github.com/influxdata/telegraf/plugins/inputs/wmi"
+	_ "github.com/influxdata/telegraf/plugins/inputs/wsus"
 	_ "github.com/influxdata/telegraf/plugins/inputs/x509_cert"
 	_ "github.com/influxdata/telegraf/plugins/inputs/zfs"
 	_ "github.com/influxdata/telegraf/plugins/inputs/zipkin"
@@ -0,0 +1,218 @@
+//go:build windows
+// +build windows
+
+package bitlocker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Collect the types of the key protectors of the volumes.
+  # key_protectors = true
+`
+
+const namespace = `root\CIMV2\Security\MicrosoftVolumeEncryption`
+
+// Names of the protection and conversion states, volume types and key
+// protector types of the encryptable volumes.
+var (
+	protectionStates = map[int64]string{
+		0: "off",
+		1: "on",
+		2: "unknown",
+	}
+	conversionStates = map[int64]string{
+		0: "fully_decrypted",
+		1: "fully_encrypted",
+		2: "encryption_in_progress",
+		3: "decryption_in_progress",
+		4: "encryption_paused",
+		5: "decryption_paused",
+	}
+	volumeTypes = map[string]string{
+		"0": "os",
+		"1": "fixed",
+		"2": "removable",
+	}
+	keyProtectorTypes = map[int64]string{
+		0:  "unknown",
+		1:  "tpm",
+		2:  "external_key",
+		3:  "numerical_password",
+		4:  "tpm_pin",
+		5:  "tpm_startup_key",
+		6:  "tpm_pin_startup_key",
+		7:  "public_key",
+		8:  "passphrase",
+		9:  "tpm_certificate",
+		10: "sid",
+	}
+)
+
+// callFunc calls the method of the object in the namespace and returns the
+// output parameters.
+type callFunc func(namespace, path, method string, params map[string]interface{}) (map[string]interface{}, error)
+
+// BitLocker collects the encryption status of the volumes.
+type BitLocker struct {
+	KeyProtectors bool `toml:"key_protectors"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	query wmi.QueryFunc
+	call  callFunc
+}
+
+func (b *BitLocker) Description() string {
+	return "Collect the BitLocker encryption status of the volumes"
+}
+
+func (b *BitLocker) SampleConfig() string {
+	return sampleConfig
+}
+
+func (b *BitLocker) Gather(acc telegraf.Accumulator) error {
+	type volume struct {
+		path   string
+		tags   map[string]string
+		fields map[string]interface{}
+	}
+	var volumes []volume
+	err := b.query(namespace, "SELECT DeviceID, DriveLetter, VolumeType, ProtectionStatus FROM Win32_EncryptableVolume",
+		func(p map[string]interface{}) error {
+			id := fmt.Sprint(p["DeviceID"])
+			tags := map[string]string{
+				"volume":      id,
+				"volume_type": lookup(volumeTypes, p["VolumeType"]),
+			}
+			if drive, ok := p["DriveLetter"].(string); ok && drive != "" {
+				tags["drive"] = drive
+			}
+			fields := make(map[string]interface{})
+			addStateFields(fields, "protection_status", protectionStates, p["ProtectionStatus"])
+
+			escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+			path := fmt.Sprintf(`Win32_EncryptableVolume.DeviceID="%s"`, escape.Replace(id))
+			volumes = append(volumes, volume{path: path, tags: tags, fields: fields})
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("querying volumes failed: %w", err)
+	}
+
+	for _, v := range volumes {
+		if err := b.conversionStatus(v.path, v.fields); err != nil {
+			acc.AddError(fmt.Errorf("getting conversion status of volume %q failed: %w", v.tags["volume"], err))
+		}
+		if b.KeyProtectors {
+			if err := b.keyProtectors(v.path, v.fields); err != nil {
+				acc.AddError(fmt.Errorf("getting key protectors of volume %q failed: %w", v.tags["volume"], err))
+			}
+		}
+		acc.AddFields("bitlocker", v.fields, v.tags)
+	}
+	return nil
+}
+
+// conversionStatus adds the conversion status and encryption percentage of
+// the volume.
+func (b *BitLocker) conversionStatus(path string, fields map[string]interface{}) error {
+	out, err := b.callMethod(path, "GetConversionStatus", nil)
+	if err != nil {
+		return err
+	}
+	addStateFields(fields, "conversion_status", conversionStates, out["ConversionStatus"])
+	if v, err := wmi.Uint64(out["EncryptionPercentage"]); err == nil {
+		fields["encryption_percentage"] = v
+	}
+	return nil
+}
+
+// keyProtectors adds the number and the sorted types of the key protectors
+// of the volume.
+func (b *BitLocker) keyProtectors(path string, fields map[string]interface{}) error {
+	out, err := b.callMethod(path, "GetKeyProtectors", nil)
+	if err != nil {
+		return err
+	}
+	ids, _ := out["VolumeKeyProtectorID"].([]interface{})
+
+	types := make([]string, 0, len(ids))
+	for _, id := range ids {
+		out, err := b.callMethod(path, "GetKeyProtectorType", map[string]interface{}{"VolumeKeyProtectorID": id})
+		if err != nil {
+			return err
+		}
+		code, err := strconv.ParseInt(fmt.Sprint(out["KeyProtectorType"]), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key protector type %v", out["KeyProtectorType"])
+		}
+		name, ok := keyProtectorTypes[code]
+		if !ok {
+			name = "unknown"
+		}
+		types = append(types, name)
+	}
+	sort.Strings(types)
+
+	fields["key_protectors"] = strings.Join(types, ",")
+	fields["key_protectors_count"] = uint64(len(ids))
+	return nil
+}
+
+// callMethod calls the method of the volume and returns the output
+// parameters, the return value of the method is returned as error.
+func (b *BitLocker) callMethod(path, method string, params map[string]interface{}) (map[string]interface{}, error) {
+	out, err := b.call(namespace, path, method, params)
+	if err != nil {
+		return nil, err
+	}
+	code, err := wmi.Uint64(out["ReturnValue"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid return value %v", out["ReturnValue"])
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("%s returned 0x%08x", method, code)
+	}
+	return out, nil
+}
+
+// addStateFields adds the state and its code as fields with the prefix.
+func addStateFields(fields map[string]interface{}, prefix string, names map[int64]string, value interface{}) {
+	code, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil {
+		return
+	}
+	state, ok := names[code]
+	if !ok {
+		state = "unknown"
+	}
+	fields[prefix] = state
+	fields[prefix+"_code"] = code
+}
+
+// lookup returns the name of the property value, "unknown" if not found.
+func lookup(names map[string]string, value interface{}) string {
+	if name, ok := names[fmt.Sprint(value)]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func init() {
+	inputs.Add("bitlocker", func() telegraf.Input {
+		return &BitLocker{
+			KeyProtectors: true,
+			query:         (&wmi.Connection{}).Query,
+			call:          (&wmi.Connection{}).CallMethod,
+		}
+	})
+}
@@ -0,0 +1,104 @@
+//go:build windows
+// +build windows
+
+package bitlocker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const (
+	osVolume   = `Win32_EncryptableVolume.DeviceID="\\\\?\\Volume{11111111-0000-0000-0000-100000000000}\\"`
+	dataVolume = `Win32_EncryptableVolume.DeviceID="\\\\?\\Volume{22222222-0000-0000-0000-100000000000}\\"`
+)
+
+func TestGather(t *testing.T) {
+	b := &BitLocker{
+		KeyProtectors: true,
+		Log:           testutil.Logger{},
+		query: func(_, _ string, fn func(properties map[string]interface{}) error) error {
+			if err := fn(map[string]interface{}{
+				"DeviceID":         `\\?\Volume{11111111-0000-0000-0000-100000000000}\`,
+				"DriveLetter":      "C:",
+				"VolumeType":       uint32(0),
+				"ProtectionStatus": uint32(1),
+			}); err != nil {
+				return err
+			}
+			return fn(map[string]interface{}{
+				"DeviceID":         `\\?\Volume{22222222-0000-0000-0000-100000000000}\`,
+				"DriveLetter":      nil,
+				"VolumeType":       uint32(1),
+				"ProtectionStatus": uint32(0),
+			})
+		},
+		call: func(_, path, method string, params map[string]interface{}) (map[string]interface{}, error) {
+			switch {
+			case path == osVolume && method == "GetConversionStatus":
+				return map[string]interface{}{"ReturnValue": uint32(0), "ConversionStatus": uint32(1), "EncryptionPercentage": uint32(100)}, nil
+			case path == osVolume && method == "GetKeyProtectors":
+				return map[string]interface{}{"ReturnValue": uint32(0), "VolumeKeyProtectorID": []interface{}{"{a}", "{b}"}}, nil
+			case path == osVolume && method == "GetKeyProtectorType":
+				protectorType := uint32(1)
+				if params["VolumeKeyProtectorID"] == "{b}" {
+					protectorType = 3
+				}
+				return map[string]interface{}{"ReturnValue": uint32(0), "KeyProtectorType": protectorType}, nil
+			case path == dataVolume && method == "GetConversionStatus":
+				return map[string]interface{}{"ReturnValue": uint32(0), "ConversionStatus": uint32(2), "EncryptionPercentage": uint32(42)}, nil
+			case path == dataVolume && method == "GetKeyProtectors":
+				return map[string]interface{}{"ReturnValue": uint32(0x80310000)}, nil
+			}
+			return nil, errors.New("unexpected call")
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, b.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Contains(t, acc.Errors[0].Error(), "GetKeyProtectors returned 0x80310000")
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"bitlocker",
+			map[string]string{
+				"volume":      `\\?\Volume{11111111-0000-0000-0000-100000000000}\`,
+				"drive":       "C:",
+				"volume_type": "os",
+			},
+			map[string]interface{}{
+				"protection_status":      "on",
+				"protection_status_code": int64(1),
+				"conversion_status":      "fully_encrypted",
+				"conversion_status_code": int64(1),
+				"encryption_percentage":  uint64(100),
+				"key_protectors":         "numerical_password,tpm",
+				"key_protectors_count":   uint64(2),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"bitlocker",
+			map[string]string{
+				"volume":      `\\?\Volume{22222222-0000-0000-0000-100000000000}\`,
+				"volume_type": "fixed",
+			},
+			map[string]interface{}{
+				"protection_status":      "off",
+				"protection_status_code": int64(0),
+				"conversion_status":      "encryption_in_progress",
+				"conversion_status_code": int64(2),
+				"encryption_percentage":  uint64(42),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
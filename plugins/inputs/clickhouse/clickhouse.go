@@ -144,7 +144,7 @@ func (ch *ClickHouse) Start(telegraf.Accumulator) error {
 		Timeout: timeout,
 		Transport: &http.Transport{
 			TLSClientConfig:     tlsCfg,
-			Proxy:               http.ProxyFromEnvironment,
+			Proxy:               internal.Proxy,
 			MaxIdleConnsPerHost: 1,
 		},
 	}
@@ -0,0 +1,344 @@
+//go:build windows
+// +build windows
+
+package csv_fs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the volumes to collect, all if empty. Globs accepted. The names
+  ## are the folders of the volumes in C:\ClusterStorage, e.g. 'Volume1'.
+  # volumes = []
+
+  ## Collect the I/O mode of the volumes on this node, i.e. direct or
+  ## redirected, using the FailoverClusters PowerShell module.
+  # volume_state = true
+
+  ## Timeout of the FailoverClusters PowerShell module.
+  # timeout = "30s"
+`
+
+const namespace = `root\cimv2`
+
+// stateScript returns the state of the volumes on the local node as JSON.
+// Enumerations are converted to strings, ConvertTo-Json writes them as
+// numbers.
+const stateScript = `
+$ErrorActionPreference = 'Stop'
+$ProgressPreference = 'SilentlyContinue'
+try {
+  Import-Module FailoverClusters
+  $states = Get-ClusterSharedVolumeState -Node $env:COMPUTERNAME | ForEach-Object {
+    [pscustomobject]@{
+      Name = "$($_.Name)"; VolumeFriendlyName = "$($_.VolumeFriendlyName)"; StateInfo = "$($_.StateInfo)"
+      FileSystemRedirectedIOReason = "$($_.FileSystemRedirectedIOReason)"; BlockRedirectedIOReason = "$($_.BlockRedirectedIOReason)"
+    }
+  }
+  ConvertTo-Json -InputObject @($states) -Compress
+} catch {
+  Write-Output $_.Exception.Message
+  exit 1
+}
+`
+
+// clusterStorage is the folder of the Cluster Shared Volumes.
+const clusterStorage = `\clusterstorage\`
+
+// Names and codes of the I/O modes of the volumes.
+var volumeStates = map[string]int64{
+	"unavailable":            0,
+	"paused":                 1,
+	"direct":                 2,
+	"file_system_redirected": 3,
+	"block_redirected":       4,
+}
+
+// Rate and average timer counters of the CSV file system, the averages are
+// divided by the base counter with the '_Base' suffix.
+var (
+	rateCounters = map[string]string{
+		"ReadsPersec":                "reads_per_sec",
+		"WritesPersec":               "writes_per_sec",
+		"ReadBytesPersec":            "read_bytes_per_sec",
+		"WriteBytesPersec":           "write_bytes_per_sec",
+		"RedirectedReadsPersec":      "redirected_reads_per_sec",
+		"RedirectedWritesPersec":     "redirected_writes_per_sec",
+		"RedirectedReadBytesPersec":  "redirected_read_bytes_per_sec",
+		"RedirectedWriteBytesPersec": "redirected_write_bytes_per_sec",
+	}
+	averageCounters = map[string]string{
+		"AvgsecPerRead":  "read_latency_ms",
+		"AvgsecPerWrite": "write_latency_ms",
+	}
+)
+
+// CSVFS collects the I/O mode, performance and free space of the Cluster
+// Shared Volumes.
+type CSVFS struct {
+	Volumes     []string        `toml:"volumes"`
+	VolumeState bool            `toml:"volume_state"`
+	Timeout     config.Duration `toml:"timeout"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	query  wmi.QueryFunc
+	run    func(timeout time.Duration) ([]byte, error)
+	// samples are the raw counters of the previous interval by volume.
+	samples map[string]sample
+}
+
+// sample are the raw counters of a volume and their timestamp.
+type sample struct {
+	timestamp uint64
+	frequency uint64
+	counters  map[string]uint64
+}
+
+// volume is a Cluster Shared Volume and its metrics.
+type volume struct {
+	fields map[string]interface{}
+	tags   map[string]string
+}
+
+func (c *CSVFS) Description() string {
+	return "Collect the I/O mode, performance and free space of Cluster Shared Volumes"
+}
+
+func (c *CSVFS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CSVFS) Init() error {
+	names := make([]string, 0, len(c.Volumes))
+	for _, name := range c.Volumes {
+		names = append(names, strings.ToLower(name))
+	}
+	f, err := filter.Compile(names)
+	if err != nil {
+		return fmt.Errorf("compiling volumes failed: %w", err)
+	}
+	c.filter = f
+	c.samples = make(map[string]sample)
+	return nil
+}
+
+func (c *CSVFS) Gather(acc telegraf.Accumulator) error {
+	volumes, err := c.gatherVolumes()
+	if err != nil {
+		return fmt.Errorf("querying volumes failed: %w", err)
+	}
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	if err := c.gatherCounters(volumes); err != nil {
+		acc.AddError(fmt.Errorf("querying CSV file system counters failed: %w", err))
+	}
+	if c.VolumeState {
+		if err := c.gatherStates(volumes); err != nil {
+			acc.AddError(fmt.Errorf("querying volume states failed: %w", err))
+		}
+	}
+	for _, v := range volumes {
+		acc.AddFields("csv_fs", v.fields, v.tags)
+	}
+	return nil
+}
+
+// gatherVolumes returns the Cluster Shared Volumes matching the filter, i.e.
+// the volumes mounted in C:\ClusterStorage, by lower case name.
+func (c *CSVFS) gatherVolumes() (map[string]*volume, error) {
+	volumes := make(map[string]*volume)
+	err := c.query(namespace, "SELECT Name, Capacity, FreeSpace FROM Win32_Volume", func(p map[string]interface{}) error {
+		path := strings.TrimSuffix(fmt.Sprint(p["Name"]), `\`)
+		i := strings.Index(strings.ToLower(path), clusterStorage)
+		if i < 0 {
+			return nil
+		}
+		name := path[i+len(clusterStorage):]
+		key := strings.ToLower(name)
+		if name == "" || (c.filter != nil && !c.filter.Match(key)) {
+			return nil
+		}
+
+		fields := make(map[string]interface{})
+		capacity, err1 := wmi.Uint64(p["Capacity"])
+		free, err2 := wmi.Uint64(p["FreeSpace"])
+		if err1 == nil && err2 == nil && capacity > 0 && free <= capacity {
+			fields["total"] = capacity
+			fields["free"] = free
+			fields["used"] = capacity - free
+			fields["used_percent"] = float64(capacity-free) / float64(capacity) * 100
+		}
+		volumes[key] = &volume{fields: fields, tags: map[string]string{"volume": name, "path": path}}
+		return nil
+	})
+	return volumes, err
+}
+
+// gatherCounters adds the rates and latencies computed from the raw counters
+// of the current and the previous interval. The instances are named by the
+// volume or its path.
+func (c *CSVFS) gatherCounters(volumes map[string]*volume) error {
+	samples := make(map[string]sample)
+	err := c.query(namespace, "SELECT * FROM Win32_PerfRawData_CsvFsPerfProvider_ClusterCSVFS", func(p map[string]interface{}) error {
+		name := strings.ToLower(strings.TrimSuffix(fmt.Sprint(p["Name"]), `\`))
+		if i := strings.LastIndex(name, `\`); i >= 0 {
+			name = name[i+1:]
+		}
+		if _, ok := volumes[name]; !ok {
+			return nil
+		}
+		timestamp, err1 := wmi.Uint64(p["Timestamp_PerfTime"])
+		frequency, err2 := wmi.Uint64(p["Frequency_PerfTime"])
+		if err1 != nil || err2 != nil || frequency == 0 {
+			return nil
+		}
+		s := sample{timestamp: timestamp, frequency: frequency, counters: make(map[string]uint64)}
+		for property := range rateCounters {
+			if v, err := wmi.Uint64(p[property]); err == nil {
+				s.counters[property] = v
+			}
+		}
+		for property := range averageCounters {
+			for _, name := range []string{property, property + "_Base"} {
+				if v, err := wmi.Uint64(p[name]); err == nil {
+					s.counters[name] = v
+				}
+			}
+		}
+		samples[name] = s
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for name, current := range samples {
+		previous, ok := c.samples[name]
+		if !ok || current.timestamp <= previous.timestamp {
+			continue
+		}
+		addRates(volumes[name].fields, previous, current)
+	}
+	c.samples = samples
+	return nil
+}
+
+// addRates adds the rates and averages of the counters between the samples,
+// skipping counters reset or wrapped around in between.
+func addRates(fields map[string]interface{}, previous, current sample) {
+	elapsed := float64(current.timestamp-previous.timestamp) / float64(current.frequency)
+	delta := func(name string) (float64, bool) {
+		v0, ok0 := previous.counters[name]
+		v1, ok1 := current.counters[name]
+		if !ok0 || !ok1 || v1 < v0 {
+			return 0, false
+		}
+		return float64(v1 - v0), true
+	}
+
+	for property, field := range rateCounters {
+		if d, ok := delta(property); ok {
+			fields[field] = d / elapsed
+		}
+	}
+	for property, field := range averageCounters {
+		ticks, ok1 := delta(property)
+		count, ok2 := delta(property + "_Base")
+		if !ok1 || !ok2 {
+			continue
+		}
+		if count == 0 {
+			fields[field] = float64(0)
+			continue
+		}
+		fields[field] = ticks / float64(current.frequency) / count * 1000
+	}
+}
+
+// gatherStates adds the I/O mode of the volumes on this node and the reasons
+// of redirected I/O.
+func (c *CSVFS) gatherStates(volumes map[string]*volume) error {
+	out, err := c.run(time.Duration(c.Timeout))
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	var states []struct {
+		Name                         string
+		VolumeFriendlyName           string
+		StateInfo                    string
+		FileSystemRedirectedIOReason string
+		BlockRedirectedIOReason      string
+	}
+	if err := json.Unmarshal(out, &states); err != nil {
+		return fmt.Errorf("parsing output failed: %w", err)
+	}
+
+	for _, s := range states {
+		v, ok := volumes[strings.ToLower(s.VolumeFriendlyName)]
+		if !ok {
+			continue
+		}
+		v.tags["csv"] = s.Name
+		state := internal.SnakeCase(s.StateInfo)
+		code, ok := volumeStates[state]
+		if !ok {
+			state = "unknown"
+			code = -1
+		}
+		v.fields["state"] = state
+		v.fields["state_code"] = code
+		v.fields["redirected"] = state == "file_system_redirected" || state == "block_redirected"
+		if reason := s.FileSystemRedirectedIOReason; reason != "" && reason != "NotFileSystemRedirected" {
+			v.fields["file_system_redirected_reason"] = reason
+		}
+		if reason := s.BlockRedirectedIOReason; reason != "" && reason != "NotBlockRedirected" {
+			v.fields["block_redirected_reason"] = reason
+		}
+	}
+	return nil
+}
+
+// runStateScript runs the script returning the volume states. The script is
+// passed encoded to avoid quoting the command line.
+func runStateScript(timeout time.Duration) ([]byte, error) {
+	chars := utf16.Encode([]rune(stateScript))
+	encoded := make([]byte, 0, 2*len(chars))
+	for _, ch := range chars {
+		encoded = append(encoded, byte(ch), byte(ch>>8))
+	}
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-EncodedCommand",
+		base64.StdEncoding.EncodeToString(encoded))
+	return internal.CombinedOutputTimeout(cmd, timeout)
+}
+
+func init() {
+	inputs.Add("csv_fs", func() telegraf.Input {
+		return &CSVFS{
+			VolumeState: true,
+			Timeout:     config.Duration(30 * time.Second),
+			query:       (&wmi.Connection{}).Query,
+			run:         runStateScript,
+		}
+	})
+}
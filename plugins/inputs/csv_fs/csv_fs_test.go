@@ -0,0 +1,175 @@
+//go:build windows
+// +build windows
+
+package csv_fs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeQuery returns the objects of the class named in the query, the objects
+// of the next call for classes with several sets of objects.
+func fakeQuery(objects map[string][][]map[string]interface{}) wmi.QueryFunc {
+	return func(_, query string, fn func(map[string]interface{}) error) error {
+		for class, sets := range objects {
+			if !strings.Contains(query, "FROM "+class) {
+				continue
+			}
+			items := sets[0]
+			if len(sets) > 1 {
+				objects[class] = sets[1:]
+			}
+			for _, item := range items {
+				if err := fn(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("invalid class in query %q", query)
+	}
+}
+
+const statesJSON = `[` +
+	`{"Name":"Cluster Disk 1","VolumeFriendlyName":"Volume1","StateInfo":"Direct",` +
+	`"FileSystemRedirectedIOReason":"NotFileSystemRedirected","BlockRedirectedIOReason":"NotBlockRedirected"},` +
+	`{"Name":"Cluster Disk 2","VolumeFriendlyName":"Volume2","StateInfo":"FileSystemRedirected",` +
+	`"FileSystemRedirectedIOReason":"IncompatibleFileSystemFilter","BlockRedirectedIOReason":"NotBlockRedirected"}]`
+
+func counters(timestamp, reads, readBytes, redirectedReads, readTicks, readCount string) map[string]interface{} {
+	return map[string]interface{}{
+		"Name":                  `C:\ClusterStorage\Volume1`,
+		"Timestamp_PerfTime":    timestamp,
+		"Frequency_PerfTime":    "10000000",
+		"ReadsPersec":           reads,
+		"ReadBytesPersec":       readBytes,
+		"RedirectedReadsPersec": redirectedReads,
+		"AvgsecPerRead":         readTicks,
+		"AvgsecPerRead_Base":    readCount,
+	}
+}
+
+func TestGather(t *testing.T) {
+	c := &CSVFS{
+		Volumes:     []string{"volume*"},
+		VolumeState: true,
+		query: fakeQuery(map[string][][]map[string]interface{}{
+			"Win32_Volume": {{
+				{"Name": `C:\ClusterStorage\Volume1\`, "Capacity": "1000", "FreeSpace": "250"},
+				{"Name": `C:\ClusterStorage\Volume2\`, "Capacity": "2000", "FreeSpace": "2000"},
+				{"Name": `C:\ClusterStorage\Data\`, "Capacity": "2000", "FreeSpace": "1000"},
+				{"Name": `C:\`, "Capacity": "500", "FreeSpace": "100"},
+			}},
+			"Win32_PerfRawData_CsvFsPerfProvider_ClusterCSVFS": {
+				{counters("100000000", "1000", "4096000", "0", "50000", "1000"), {"Name": "Data", "Timestamp_PerfTime": "1", "Frequency_PerfTime": "1"}},
+				{counters("200000000", "3000", "12288000", "500", "90000", "3000")},
+				{counters("300000000", "10", "0", "500", "0", "0")},
+			},
+		}),
+		run: func(time.Duration) ([]byte, error) { return []byte(statesJSON), nil },
+	}
+	require.NoError(t, c.Init())
+
+	// The rates are computed from the second interval on.
+	var acc testutil.Accumulator
+	require.NoError(t, c.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	_, ok := acc.GetTelegrafMetrics()[0].GetField("reads_per_sec")
+	require.False(t, ok)
+
+	acc.ClearMetrics()
+	require.NoError(t, c.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("csv_fs",
+			map[string]string{"volume": "Volume1", "path": `C:\ClusterStorage\Volume1`, "csv": "Cluster Disk 1"},
+			map[string]interface{}{
+				"total":                    uint64(1000),
+				"free":                     uint64(250),
+				"used":                     uint64(750),
+				"used_percent":             float64(75),
+				"reads_per_sec":            float64(200),
+				"read_bytes_per_sec":       float64(819200),
+				"redirected_reads_per_sec": float64(50),
+				"read_latency_ms":          float64(0.002),
+				"state":                    "direct",
+				"state_code":               int64(2),
+				"redirected":               false,
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("csv_fs",
+			map[string]string{"volume": "Volume2", "path": `C:\ClusterStorage\Volume2`, "csv": "Cluster Disk 2"},
+			map[string]interface{}{
+				"total":                         uint64(2000),
+				"free":                          uint64(2000),
+				"used":                          uint64(0),
+				"used_percent":                  float64(0),
+				"state":                         "file_system_redirected",
+				"state_code":                    int64(3),
+				"redirected":                    true,
+				"file_system_redirected_reason": "IncompatibleFileSystemFilter",
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Counters reset in between are skipped, the latency of intervals
+	// without reads is zero.
+	acc.ClearMetrics()
+	require.NoError(t, c.Gather(&acc))
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Tags()["volume"] != "Volume1" {
+			continue
+		}
+		_, ok := m.GetField("reads_per_sec")
+		require.False(t, ok)
+		require.Equal(t, float64(0), m.Fields()["redirected_reads_per_sec"])
+	}
+}
+
+func TestGatherStateError(t *testing.T) {
+	c := &CSVFS{
+		VolumeState: true,
+		query: fakeQuery(map[string][][]map[string]interface{}{
+			"Win32_Volume": {{{"Name": `C:\ClusterStorage\Volume1\`, "Capacity": "1000", "FreeSpace": "250"}}},
+			"Win32_PerfRawData_CsvFsPerfProvider_ClusterCSVFS": {{}},
+		}),
+		run: func(time.Duration) ([]byte, error) {
+			return []byte("The specified module 'FailoverClusters' was not loaded.\r\n"), errors.New("exit status 1")
+		},
+	}
+	require.NoError(t, c.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, c.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Contains(t, acc.Errors[0].Error(), "FailoverClusters")
+	require.True(t, acc.HasMeasurement("csv_fs"))
+}
+
+func TestGatherNoVolumes(t *testing.T) {
+	c := &CSVFS{
+		VolumeState: true,
+		query: fakeQuery(map[string][][]map[string]interface{}{
+			"Win32_Volume": {{{"Name": `C:\`, "Capacity": "1000", "FreeSpace": "250"}}},
+		}),
+		run: func(time.Duration) ([]byte, error) { return nil, errors.New("not called") },
+	}
+	require.NoError(t, c.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, c.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/plugins/parsers/parquet"
+	"github.com/influxdata/telegraf/plugins/parsers/xsv"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -66,6 +68,7 @@ const sampleConfig = `
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
   ## NOTE: We currently only support parsing newline-delimited JSON. See the format here: https://github.com/ndjson/ndjson-spec
+  ## Parquet files are read as a whole and parsed one row group at a time.
   data_format = "influx"
 `
 
@@ -261,6 +264,12 @@ func (monitor *DirectoryMonitor) ingestFile(filePath string) error {
 }
 
 func (monitor *DirectoryMonitor) parseFile(parser parsers.Parser, reader io.Reader, fileName string) error {
+	// Parquet files cannot be split into lines, stream them by row group.
+	if p, ok := parsers.Unwrap(parser).(*parquet.Parser); ok {
+		sp := parsers.WrapStreamParser(parser, parquet.NewStreamParser(p, reader))
+		return monitor.parseStream(sp, fileName)
+	}
+
 	// Read the file line-by-line and parse with the configured parse method.
 	firstLine := true
 	scanner := bufio.NewScanner(reader)
@@ -285,8 +294,28 @@ func (monitor *DirectoryMonitor) parseFile(parser parsers.Parser, reader io.Read
 	return nil
 }
 
+func (monitor *DirectoryMonitor) parseStream(sp parsers.StreamParser, fileName string) error {
+	for {
+		m, err := sp.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if monitor.FileTag != "" {
+			m.AddTag(monitor.FileTag, filepath.Base(fileName))
+		}
+
+		if err := monitor.sendMetrics([]telegraf.Metric{m}); err != nil {
+			return err
+		}
+	}
+}
+
 func (monitor *DirectoryMonitor) parseLine(parser parsers.Parser, line []byte, firstLine bool) ([]telegraf.Metric, error) {
-	switch parser.(type) {
+	switch parsers.Unwrap(parser).(type) {
 	case *csv.Parser:
 		// The CSV parser parses headers in Parse and skips them in ParseLine.
 		if firstLine {
@@ -302,6 +331,18 @@ func (monitor *DirectoryMonitor) parseLine(parser parsers.Parser, line []byte, f
 			return []telegraf.Metric{m}, nil
 		}
 
+		return []telegraf.Metric{}, nil
+	case *xsv.Parser:
+		// The xsv parser detects the format and consumes the header from the
+		// first line passed to ParseLine and keeps it for the following ones.
+		m, err := parser.ParseLine(string(line))
+		if err != nil {
+			return nil, err
+		}
+
+		if m != nil {
+			return []telegraf.Metric{m}, nil
+		}
 		return []telegraf.Metric{}, nil
 	default:
 		return parser.Parse(line)
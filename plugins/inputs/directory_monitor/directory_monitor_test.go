@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go/writer"
 
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
@@ -193,3 +194,75 @@ func TestFileTag(t *testing.T) {
 		}
 	}
 }
+
+func TestParquetImport(t *testing.T) {
+	acc := testutil.Accumulator{}
+	testParquetFile := "test.parquet"
+
+	// Establish process directory and finished directory.
+	finishedDirectory, err := os.MkdirTemp("", "finished")
+	require.NoError(t, err)
+	processDirectory, err := os.MkdirTemp("", "test")
+	require.NoError(t, err)
+	defer os.RemoveAll(processDirectory)
+	defer os.RemoveAll(finishedDirectory)
+
+	// Init plugin.
+	r := DirectoryMonitor{
+		Directory:          processDirectory,
+		FinishedDirectory:  finishedDirectory,
+		FileTag:            "filename",
+		MaxBufferedMetrics: 1000,
+		FileQueueSize:      1000,
+	}
+	err = r.Init()
+	require.NoError(t, err)
+
+	parserConfig := parsers.Config{
+		DataFormat:        "parquet",
+		MetricName:        "export",
+		ParquetTagColumns: []string{"thing"},
+		// Options wrapping the parser apply to the streamed rows as well
+		FieldTypes:       map[string]string{"count": "float"},
+		ParseErrorsCount: true,
+	}
+	r.SetParserFunc(func() (parsers.Parser, error) {
+		return parsers.NewParser(&parserConfig)
+	})
+	r.Log = testutil.Logger{}
+
+	// Write parquet file with a row group per row into the 'process'
+	// directory.
+	type row struct {
+		Thing string `parquet:"name=thing, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Count int64  `parquet:"name=count, type=INT64"`
+	}
+	var b bytes.Buffer
+	pw, err := writer.NewParquetWriterFromWriter(&b, new(row), 1)
+	require.NoError(t, err)
+	for i, thing := range []string{"sky", "grass", "clifford"} {
+		require.NoError(t, pw.Write(row{Thing: thing, Count: int64(i)}))
+		require.NoError(t, pw.Flush(true))
+	}
+	require.NoError(t, pw.WriteStop())
+	err = os.WriteFile(filepath.Join(processDirectory, testParquetFile), b.Bytes(), 0666)
+	require.NoError(t, err)
+
+	err = r.Start(&acc)
+	require.NoError(t, err)
+	err = r.Gather(&acc)
+	require.NoError(t, err)
+	acc.Wait(3)
+	r.Stop()
+
+	require.Len(t, acc.Metrics, 3)
+	for i, thing := range []string{"sky", "grass", "clifford"} {
+		m := acc.Metrics[i]
+		require.Equal(t, "export", m.Measurement)
+		require.Equal(t, map[string]string{"thing": thing, "filename": testParquetFile}, m.Tags)
+		require.Equal(t, map[string]interface{}{"count": float64(i)}, m.Fields)
+	}
+
+	_, err = os.Stat(filepath.Join(finishedDirectory, testParquetFile))
+	require.NoError(t, err)
+}
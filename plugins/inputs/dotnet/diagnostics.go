@@ -0,0 +1,162 @@
+package dotnet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+// ipcMagic starts the messages of the diagnostics IPC protocol.
+const ipcMagic = "DOTNET_IPC_V1\x00"
+
+// ipcHeaderSize is the size of the message header, i.e. the magic, the size
+// of the message, the command set and ID and two reserved bytes.
+const ipcHeaderSize = 20
+
+// Command sets and IDs of the diagnostics IPC protocol.
+const (
+	commandSetEventPipe    = 0x02
+	commandSetServer       = 0xFF
+	commandCollectTracing2 = 0x03
+	commandOK              = 0x00
+	commandError           = 0xFF
+)
+
+// formatNetTrace is the nettrace format of the streamed trace.
+const formatNetTrace = 1
+
+// runtimeProvider is the EventSource of the runtime counters.
+const runtimeProvider = "System.Runtime"
+
+// errNoDiagnosticsPort is returned if the process has no diagnostics port,
+// i.e. it is not a .NET Core process or diagnostics are disabled.
+var errNoDiagnosticsPort = errors.New("no diagnostics port")
+
+// collectEventCounters starts an EventPipe session of the runtime counters on
+// the diagnostics port and returns the payloads of the counters of the first
+// interval by name. The session is stopped by the runtime when the connection
+// is closed.
+func collectEventCounters(conn io.ReadWriter, interval time.Duration) (map[string]map[string]interface{}, error) {
+	filter := "EventCounterIntervalSec=" + strconv.FormatFloat(interval.Seconds(), 'f', -1, 64)
+	if err := writeCollectTracing(conn, runtimeProvider, filter); err != nil {
+		return nil, fmt.Errorf("starting session failed: %w", err)
+	}
+	if err := readResponse(conn); err != nil {
+		return nil, fmt.Errorf("starting session failed: %w", err)
+	}
+
+	r := newNettraceReader(conn)
+	if err := r.readHeader(); err != nil {
+		return nil, fmt.Errorf("reading trace failed: %w", err)
+	}
+	counters := make(map[string]map[string]interface{})
+	for {
+		e, err := r.next()
+		if err != nil {
+			// The counters read until the timeout are used if the next
+			// interval is not received in time.
+			if len(counters) > 0 {
+				return counters, nil
+			}
+			return nil, fmt.Errorf("reading trace failed: %w", err)
+		}
+		if e.provider != runtimeProvider || e.name != "EventCounters" {
+			continue
+		}
+		payload, ok := e.payload["Payload"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, ok := payload["Name"].(string)
+		if !ok {
+			continue
+		}
+		// The counters of an interval are written together, a counter read
+		// again belongs to the next interval.
+		if _, ok := counters[name]; ok {
+			return counters, nil
+		}
+		counters[name] = payload
+	}
+}
+
+// writeCollectTracing writes the command to start a session with the
+// provider streaming the trace in the nettrace format.
+func writeCollectTracing(w io.Writer, provider, filter string) error {
+	var payload []byte
+	payload = appendUint32(payload, 16) // circular buffer size in MB
+	payload = appendUint32(payload, formatNetTrace)
+	payload = append(payload, 0) // no rundown
+	payload = appendUint32(payload, 1)
+	payload = appendUint64(payload, 0xFFFFFFFF) // keywords
+	payload = appendUint32(payload, 4)          // informational level
+	payload = appendString(payload, provider)
+	payload = appendString(payload, filter)
+
+	msg := make([]byte, 0, ipcHeaderSize+len(payload))
+	msg = append(msg, ipcMagic...)
+	msg = appendUint16(msg, uint16(ipcHeaderSize+len(payload)))
+	msg = append(msg, commandSetEventPipe, commandCollectTracing2, 0, 0)
+	msg = append(msg, payload...)
+	_, err := w.Write(msg)
+	return err
+}
+
+// readResponse reads the response to a command and returns the error code
+// of failed commands as error.
+func readResponse(r io.Reader) error {
+	header := make([]byte, ipcHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if string(header[:len(ipcMagic)]) != ipcMagic {
+		return errors.New("invalid response")
+	}
+	size := int(binary.LittleEndian.Uint16(header[14:]))
+	if size < ipcHeaderSize || header[16] != commandSetServer {
+		return errors.New("invalid response")
+	}
+	payload := make([]byte, size-ipcHeaderSize)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return err
+	}
+
+	switch header[17] {
+	case commandOK:
+		return nil
+	case commandError:
+		if len(payload) < 4 {
+			return errors.New("command failed")
+		}
+		return fmt.Errorf("command failed with 0x%08x", binary.LittleEndian.Uint32(payload))
+	default:
+		return fmt.Errorf("invalid response command %d", header[17])
+	}
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v), byte(v>>8))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v)), uint32(v>>32))
+}
+
+// appendString appends the length in characters including the terminating
+// null and the null-terminated UTF-16 string.
+func appendString(b []byte, s string) []byte {
+	chars := utf16.Encode([]rune(s))
+	b = appendUint32(b, uint32(len(chars)+1))
+	for _, ch := range chars {
+		b = appendUint16(b, ch)
+	}
+	return appendUint16(b, 0)
+}
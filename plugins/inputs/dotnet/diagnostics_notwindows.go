@@ -0,0 +1,30 @@
+//go:build !windows
+// +build !windows
+
+package dotnet
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// dialDiagnostics connects to the diagnostics port of the process, the socket
+// 'dotnet-diagnostic-<pid>-<start time>-socket' in the temporary directory.
+func dialDiagnostics(pid int32, timeout time.Duration) (net.Conn, error) {
+	pattern := filepath.Join(os.TempDir(), fmt.Sprintf("dotnet-diagnostic-%d-*-socket", pid))
+	sockets, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(sockets) == 0 {
+		return nil, errNoDiagnosticsPort
+	}
+	// Sockets of exited processes with the same ID may be left over, the
+	// socket of the latest started process is used.
+	sort.Strings(sockets)
+	return net.DialTimeout("unix", sockets[len(sockets)-1], timeout)
+}
@@ -0,0 +1,24 @@
+//go:build windows
+// +build windows
+
+package dotnet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// dialDiagnostics connects to the diagnostics port of the process, the named
+// pipe 'dotnet-diagnostic-<pid>'.
+func dialDiagnostics(pid int32, timeout time.Duration) (net.Conn, error) {
+	conn, err := winio.DialPipe(fmt.Sprintf(`\\.\pipe\dotnet-diagnostic-%d`, pid), &timeout)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errNoDiagnosticsPort
+	}
+	return conn, err
+}
@@ -0,0 +1,262 @@
+package dotnet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the processes to collect, all if empty. Globs accepted. The
+  ## names are matched without the '.exe' extension and are case-insensitive.
+  process_names = ["w3wp", "dotnet"]
+
+  ## Collect the performance counters of .NET Framework processes, Windows
+  ## only.
+  # framework_counters = true
+
+  ## Collect the EventCounters of .NET Core 3.0 and later processes using
+  ## their diagnostics port.
+  # event_counters = true
+
+  ## Interval of the EventCounters, the collection takes at least one
+  ## interval per process. Rates are per second of the interval.
+  # event_counter_interval = "1s"
+
+  ## Timeout of the collection of the EventCounters per process.
+  # timeout = "5s"
+`
+
+// eventCounter is the field of a runtime counter.
+type eventCounter struct {
+	field string
+	// scale converts the mean value to the unit of the field, zero for
+	// counters reported as rates per second.
+	scale float64
+	// integer is true for counters added as unsigned integers.
+	integer bool
+}
+
+// eventCounters are the fields of the runtime counters by name.
+var eventCounters = map[string]eventCounter{
+	"gen-0-size":                       {field: "gen0_size_bytes", scale: 1, integer: true},
+	"gen-1-size":                       {field: "gen1_size_bytes", scale: 1, integer: true},
+	"gen-2-size":                       {field: "gen2_size_bytes", scale: 1, integer: true},
+	"loh-size":                         {field: "loh_size_bytes", scale: 1, integer: true},
+	"poh-size":                         {field: "poh_size_bytes", scale: 1, integer: true},
+	"gc-heap-size":                     {field: "heap_size_bytes", scale: 1 << 20, integer: true},
+	"time-in-gc":                       {field: "time_in_gc_percent", scale: 1},
+	"gen-0-gc-count":                   {field: "gen0_collections_per_sec"},
+	"gen-1-gc-count":                   {field: "gen1_collections_per_sec"},
+	"gen-2-gc-count":                   {field: "gen2_collections_per_sec"},
+	"alloc-rate":                       {field: "allocated_bytes_per_sec"},
+	"exception-count":                  {field: "exceptions_per_sec"},
+	"monitor-lock-contention-count":    {field: "lock_contentions_per_sec"},
+	"threadpool-thread-count":          {field: "threadpool_threads", scale: 1, integer: true},
+	"threadpool-queue-length":          {field: "threadpool_queue_length", scale: 1, integer: true},
+	"threadpool-completed-items-count": {field: "threadpool_completed_items_per_sec"},
+}
+
+// frameworkFunc returns the fields of the .NET Framework performance counters
+// of the processes by process ID.
+type frameworkFunc func(processes map[int32]string) (map[int32]map[string]interface{}, error)
+
+// DotNet collects the runtime counters of .NET processes.
+type DotNet struct {
+	ProcessNames         []string        `toml:"process_names"`
+	FrameworkCounters    bool            `toml:"framework_counters"`
+	EventCounters        bool            `toml:"event_counters"`
+	EventCounterInterval config.Duration `toml:"event_counter_interval"`
+	Timeout              config.Duration `toml:"timeout"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter    filter.Filter
+	processes func() (map[int32]string, error)
+	framework frameworkFunc
+	dial      func(pid int32, timeout time.Duration) (net.Conn, error)
+}
+
+func (d *DotNet) Description() string {
+	return "Collect the runtime counters of .NET processes"
+}
+
+func (d *DotNet) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DotNet) Init() error {
+	if d.EventCounters && d.EventCounterInterval < config.Duration(time.Second) {
+		return errors.New("event_counter_interval must be at least one second")
+	}
+	if d.EventCounters && d.Timeout <= d.EventCounterInterval {
+		return errors.New("timeout must be longer than event_counter_interval")
+	}
+
+	names := make([]string, 0, len(d.ProcessNames))
+	for _, name := range d.ProcessNames {
+		names = append(names, strings.ToLower(name))
+	}
+	f, err := filter.Compile(names)
+	if err != nil {
+		return fmt.Errorf("compiling process_names failed: %w", err)
+	}
+	d.filter = f
+	return nil
+}
+
+func (d *DotNet) Gather(acc telegraf.Accumulator) error {
+	all, err := d.processes()
+	if err != nil {
+		return fmt.Errorf("listing processes failed: %w", err)
+	}
+	processes := make(map[int32]string)
+	for pid, name := range all {
+		if d.filter == nil || d.filter.Match(strings.ToLower(name)) {
+			processes[pid] = name
+		}
+	}
+
+	// Processes with .NET Framework counters are not queried for
+	// EventCounters.
+	collected := make(map[int32]bool)
+	if d.FrameworkCounters && d.framework != nil {
+		counters, err := d.framework(processes)
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying .NET Framework counters failed: %w", err))
+		}
+		for pid, fields := range counters {
+			acc.AddFields("dotnet", fields, processTags(pid, processes[pid], "framework"))
+			collected[pid] = true
+		}
+	}
+
+	if d.EventCounters {
+		var wg sync.WaitGroup
+		for pid, name := range processes {
+			if collected[pid] {
+				continue
+			}
+			wg.Add(1)
+			go func(pid int32, name string) {
+				defer wg.Done()
+				fields, err := d.gatherEventCounters(pid)
+				switch {
+				case errors.Is(err, errNoDiagnosticsPort):
+				case err != nil:
+					acc.AddError(fmt.Errorf("collecting EventCounters of process %d failed: %w", pid, err))
+				default:
+					acc.AddFields("dotnet", fields, processTags(pid, name, "core"))
+				}
+			}(pid, name)
+		}
+		wg.Wait()
+	}
+	return nil
+}
+
+// gatherEventCounters returns the fields of the runtime counters of the
+// process collected using its diagnostics port.
+func (d *DotNet) gatherEventCounters(pid int32) (map[string]interface{}, error) {
+	timeout := time.Duration(d.Timeout)
+	conn, err := d.dial(pid, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	counters, err := collectEventCounters(conn, time.Duration(d.EventCounterInterval))
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	for name, payload := range counters {
+		counter, ok := eventCounters[name]
+		if !ok {
+			continue
+		}
+		if counter.scale == 0 {
+			// Incrementing counters report the increment of the interval.
+			increment, ok := payload["Increment"].(float64)
+			if !ok {
+				continue
+			}
+			if seconds, ok := payload["IntervalSec"].(float64); ok && seconds > 0 {
+				fields[counter.field] = increment / seconds
+			}
+			continue
+		}
+		mean, ok := payload["Mean"].(float64)
+		if !ok {
+			continue
+		}
+		if counter.integer {
+			if mean < 0 {
+				mean = 0
+			}
+			fields[counter.field] = uint64(mean * counter.scale)
+		} else {
+			fields[counter.field] = mean * counter.scale
+		}
+	}
+	return fields, nil
+}
+
+// processTags returns the tags of the process.
+func processTags(pid int32, name, runtime string) map[string]string {
+	return map[string]string{
+		"process_name": name,
+		"pid":          strconv.Itoa(int(pid)),
+		"runtime":      runtime,
+	}
+}
+
+// localProcesses returns the names of the running processes by process ID,
+// without the '.exe' extension.
+func localProcesses() (map[int32]string, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int32]string, len(procs))
+	for _, p := range procs {
+		name, err := p.Name()
+		if err != nil {
+			// The process exited or cannot be accessed
+			continue
+		}
+		if strings.HasSuffix(strings.ToLower(name), ".exe") {
+			name = name[:len(name)-4]
+		}
+		names[p.Pid] = name
+	}
+	return names, nil
+}
+
+func init() {
+	inputs.Add("dotnet", func() telegraf.Input {
+		return &DotNet{
+			FrameworkCounters:    true,
+			EventCounters:        true,
+			EventCounterInterval: config.Duration(time.Second),
+			Timeout:              config.Duration(5 * time.Second),
+			processes:            localProcesses,
+			framework:            newFramework(),
+			dial:                 dialDiagnostics,
+		}
+	})
+}
@@ -0,0 +1,348 @@
+package dotnet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// counterFields are the fields of the counter payloads, the mean counters use
+// Mean and the incrementing counters Increment.
+var counterFields = []field{
+	{name: "Name", typeCode: typeString},
+	{name: "Mean", typeCode: typeDouble},
+	{name: "Increment", typeCode: typeDouble},
+	{name: "IntervalSec", typeCode: typeSingle},
+	{name: "CounterType", typeCode: typeString},
+}
+
+// counter is the payload of an EventCounters event.
+type counter struct {
+	name      string
+	mean      float64
+	increment float64
+	interval  float32
+}
+
+// traceWriter writes a trace in the nettrace format.
+type traceWriter struct {
+	bytes.Buffer
+}
+
+func newTraceWriter() *traceWriter {
+	w := &traceWriter{}
+	w.WriteString("Nettrace")
+	w.Write(appendUint32(nil, 20))
+	w.WriteString("!FastSerialization.1")
+	w.beginObject("Trace")
+	w.Write(make([]byte, traceObjectSize))
+	w.WriteByte(tagEndObject)
+	return w
+}
+
+func (w *traceWriter) beginObject(name string) {
+	w.WriteByte(tagBeginPrivateObject)
+	w.WriteByte(tagBeginPrivateObject)
+	w.WriteByte(tagNullReference)
+	w.Write(appendUint32(appendUint32(nil, 4), 4))
+	w.Write(appendUint32(nil, uint32(len(name))))
+	w.WriteString(name)
+	w.WriteByte(tagEndObject)
+}
+
+// block writes a block with the events aligned from the start of the trace.
+func (w *traceWriter) block(name string, content []byte) {
+	w.beginObject(name)
+	w.Write(appendUint32(nil, uint32(len(content))))
+	for w.Len()%4 != 0 {
+		w.WriteByte(0)
+	}
+	w.Write(content)
+	w.WriteByte(tagEndObject)
+}
+
+func (w *traceWriter) end() []byte {
+	w.WriteByte(tagNullReference)
+	return w.Bytes()
+}
+
+// compressedBlock returns a block of the payloads with compressed headers.
+func compressedBlock(metadataID uint32, payloads ...[]byte) []byte {
+	b := appendUint16(appendUint16(nil, 20), blockCompressed)
+	b = append(b, make([]byte, 16)...)
+	for _, payload := range payloads {
+		b = append(b, headerMetadataID|headerDataLength)
+		b = appendVarUint(b, metadataID)
+		b = appendVarUint(b, 1000) // timestamp delta
+		b = appendVarUint(b, uint32(len(payload)))
+		b = append(b, payload...)
+	}
+	return b
+}
+
+// uncompressedBlock returns a block of the payloads with uncompressed
+// headers.
+func uncompressedBlock(metadataID uint32, payloads ...[]byte) []byte {
+	b := appendUint16(appendUint16(nil, 20), 0)
+	b = append(b, make([]byte, 16)...)
+	for _, payload := range payloads {
+		b = appendUint32(b, uint32(76+len(payload)))
+		b = appendUint32(b, metadataID|1<<31)
+		b = append(b, make([]byte, 4+8+8+4+4+8+16+16)...)
+		b = appendUint32(b, uint32(len(payload)))
+		b = append(b, payload...)
+		for len(b)%4 != 0 {
+			b = append(b, 0)
+		}
+	}
+	return b
+}
+
+func appendVarUint(b []byte, v uint32) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// appendUTF16 appends the null-terminated UTF-16 string without its length.
+func appendUTF16(b []byte, s string) []byte {
+	return append(b, appendString(nil, s)[4:]...)
+}
+
+// metadataPayload returns the metadata of the event with the fields nested in
+// the object field 'Payload'.
+func metadataPayload(id uint32, provider, name string, fields []field) []byte {
+	b := appendUint32(nil, id)
+	b = appendUTF16(b, provider)
+	b = appendUint32(b, 0)
+	b = appendUTF16(b, name)
+	b = appendUint64(b, 0)
+	b = appendUint32(b, 0)
+	b = appendUint32(b, 4)
+	b = appendUint32(b, 1)
+	b = appendUint32(b, typeObject)
+	b = appendUint32(b, uint32(len(fields)))
+	for _, f := range fields {
+		b = appendUint32(b, uint32(f.typeCode))
+		b = appendUTF16(b, f.name)
+	}
+	return appendUTF16(b, "Payload")
+}
+
+func counterPayload(c counter) []byte {
+	b := appendUTF16(nil, c.name)
+	b = appendUint64(b, math.Float64bits(c.mean))
+	b = appendUint64(b, math.Float64bits(c.increment))
+	b = appendUint32(b, math.Float32bits(c.interval))
+	return appendUTF16(b, "Mean")
+}
+
+// counterTrace returns a trace of the counters written in one block per
+// interval.
+func counterTrace(intervals ...[]counter) []byte {
+	w := newTraceWriter()
+	w.block("MetadataBlock", compressedBlock(0, metadataPayload(1, runtimeProvider, "EventCounters", counterFields)))
+	for _, counters := range intervals {
+		payloads := make([][]byte, 0, len(counters))
+		for _, c := range counters {
+			payloads = append(payloads, counterPayload(c))
+		}
+		w.block("EventBlock", compressedBlock(1, payloads...))
+	}
+	return w.end()
+}
+
+// fakeDiagnostics returns a dial function serving the response and trace on
+// the diagnostics port of the processes.
+func fakeDiagnostics(t *testing.T, pids map[int32]bool, response, trace []byte) func(int32, time.Duration) (net.Conn, error) {
+	return func(pid int32, _ time.Duration) (net.Conn, error) {
+		if !pids[pid] {
+			return nil, errNoDiagnosticsPort
+		}
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			header := make([]byte, ipcHeaderSize)
+			if _, err := io.ReadFull(server, header); err != nil {
+				return
+			}
+			request := make([]byte, int(binary.LittleEndian.Uint16(header[14:]))-ipcHeaderSize)
+			if _, err := io.ReadFull(server, request); err != nil {
+				return
+			}
+			if header[16] != commandSetEventPipe || header[17] != commandCollectTracing2 ||
+				!bytes.Contains(request, appendUTF16(nil, "EventCounterIntervalSec=1")) {
+				t.Errorf("invalid request %v", append(header, request...))
+				return
+			}
+			// The client closes the connection once the counters are read.
+			_, _ = server.Write(append(response, trace...))
+		}()
+		return client, nil
+	}
+}
+
+func okResponse() []byte {
+	b := append([]byte(ipcMagic), appendUint16(nil, ipcHeaderSize+8)...)
+	b = append(b, commandSetServer, commandOK, 0, 0)
+	return appendUint64(b, 1)
+}
+
+func TestGather(t *testing.T) {
+	trace := counterTrace(
+		[]counter{
+			{name: "gen-0-size", mean: 1024, interval: 1},
+			{name: "gc-heap-size", mean: 12.5, interval: 1},
+			{name: "time-in-gc", mean: 3.5, interval: 1},
+			{name: "exception-count", increment: 10, interval: 2},
+			{name: "threadpool-thread-count", mean: 4, interval: 1},
+			{name: "cpu-usage", mean: 10, interval: 1},
+		},
+		[]counter{{name: "gen-0-size", mean: 2048, interval: 1}},
+	)
+	d := &DotNet{
+		ProcessNames:         []string{"dotnet", "W3WP"},
+		FrameworkCounters:    true,
+		EventCounters:        true,
+		EventCounterInterval: config.Duration(time.Second),
+		Timeout:              config.Duration(5 * time.Second),
+		processes: func() (map[int32]string, error) {
+			return map[int32]string{100: "dotnet", 200: "w3wp", 300: "notepad", 400: "dotnet"}, nil
+		},
+		framework: func(processes map[int32]string) (map[int32]map[string]interface{}, error) {
+			require.Len(t, processes, 3)
+			return map[int32]map[string]interface{}{
+				200: {"gen0_size_bytes": uint64(4096), "exceptions_per_sec": float64(2)},
+			}, nil
+		},
+		dial: fakeDiagnostics(t, map[int32]bool{100: true, 200: true, 300: true}, okResponse(), trace),
+	}
+	require.NoError(t, d.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, d.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("dotnet",
+			map[string]string{"process_name": "w3wp", "pid": "200", "runtime": "framework"},
+			map[string]interface{}{
+				"gen0_size_bytes":    uint64(4096),
+				"exceptions_per_sec": float64(2),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("dotnet",
+			map[string]string{"process_name": "dotnet", "pid": "100", "runtime": "core"},
+			map[string]interface{}{
+				"gen0_size_bytes":    uint64(1024),
+				"heap_size_bytes":    uint64(13107200),
+				"time_in_gc_percent": float64(3.5),
+				"exceptions_per_sec": float64(5),
+				"threadpool_threads": uint64(4),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherIncompleteInterval(t *testing.T) {
+	// The counters read until the end of the trace or the timeout are used
+	// if the next interval is not received.
+	trace := counterTrace([]counter{{name: "time-in-gc", mean: 1.5, interval: 1}})
+	d := &DotNet{
+		EventCounters:        true,
+		EventCounterInterval: config.Duration(time.Second),
+		Timeout:              config.Duration(5 * time.Second),
+		processes:            func() (map[int32]string, error) { return map[int32]string{100: "app"}, nil },
+		dial:                 fakeDiagnostics(t, map[int32]bool{100: true}, okResponse(), trace[:len(trace)-1]),
+	}
+	require.NoError(t, d.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, d.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Equal(t, map[string]interface{}{"time_in_gc_percent": 1.5}, acc.GetTelegrafMetrics()[0].Fields())
+}
+
+func TestGatherCommandError(t *testing.T) {
+	response := append([]byte(ipcMagic), appendUint16(nil, ipcHeaderSize+4)...)
+	response = append(response, commandSetServer, commandError, 0, 0)
+	response = appendUint32(response, 0x80131384)
+	d := &DotNet{
+		EventCounters:        true,
+		EventCounterInterval: config.Duration(time.Second),
+		Timeout:              config.Duration(5 * time.Second),
+		processes:            func() (map[int32]string, error) { return map[int32]string{100: "app", 200: "other"}, nil },
+		dial:                 fakeDiagnostics(t, map[int32]bool{100: true}, response, nil),
+	}
+	require.NoError(t, d.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, d.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Contains(t, acc.Errors[0].Error(), "0x80131384")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestInitInvalidTimeout(t *testing.T) {
+	d := &DotNet{
+		EventCounters:        true,
+		EventCounterInterval: config.Duration(5 * time.Second),
+		Timeout:              config.Duration(5 * time.Second),
+	}
+	require.Error(t, d.Init())
+}
+
+func TestNettraceUncompressed(t *testing.T) {
+	fields := []field{
+		{name: "Name", typeCode: typeString},
+		{name: "Count", typeCode: typeInt32},
+		{name: "Enabled", typeCode: typeBoolean},
+	}
+	payload := appendUTF16(nil, "requests")
+	payload = appendUint32(payload, 42)
+	payload = appendUint32(payload, 1)
+
+	w := newTraceWriter()
+	w.block("MetadataBlock", uncompressedBlock(0, metadataPayload(7, "App", "Counters", fields)))
+	w.block("StackBlock", []byte{0, 0, 0, 0})
+	w.block("EventBlock", uncompressedBlock(7, payload, payload))
+	w.block("EventBlock", uncompressedBlock(8, payload))
+
+	r := newNettraceReader(bytes.NewReader(w.end()))
+	require.NoError(t, r.readHeader())
+	expected := traceEvent{
+		provider: "App",
+		name:     "Counters",
+		payload: map[string]interface{}{
+			"Payload": map[string]interface{}{"Name": "requests", "Count": int64(42), "Enabled": true},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		e, err := r.next()
+		require.NoError(t, err)
+		require.Equal(t, expected, e)
+	}
+	// Events without metadata are skipped.
+	_, err := r.next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestNettraceInvalid(t *testing.T) {
+	r := newNettraceReader(strings.NewReader("Nettrace\x14\x00\x00\x00!FastSerialization.2"))
+	require.Error(t, r.readHeader())
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+// +build !windows
+
+package dotnet
+
+// newFramework returns nil, the .NET Framework only runs on Windows.
+func newFramework() frameworkFunc {
+	return nil
+}
@@ -0,0 +1,121 @@
+//go:build windows
+// +build windows
+
+package dotnet
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+)
+
+const namespace = `root\cimv2`
+
+// newFramework returns the function querying the .NET Framework performance
+// counters using WMI.
+func newFramework() frameworkFunc {
+	return frameworkCounters((&wmi.Connection{}).Query)
+}
+
+// frameworkCounters returns the function querying the .NET CLR performance
+// counter classes. The instances are named by the process, only the memory
+// counters contain the process ID.
+func frameworkCounters(query wmi.QueryFunc) frameworkFunc {
+	return func(processes map[int32]string) (map[int32]map[string]interface{}, error) {
+		type instance struct {
+			pid    int32
+			fields map[string]interface{}
+		}
+		instances := make(map[string]*instance)
+		err := query(namespace,
+			"SELECT Name, ProcessID, Gen0heapsize, Gen1heapsize, Gen2heapsize, LargeObjectHeapsize, NumberBytesinallHeaps, PercentTimeinGC, NumberGen0Collections, NumberGen1Collections, NumberGen2Collections, AllocatedBytesPersec FROM Win32_PerfFormattedData_NETFramework_NETCLRMemory",
+			func(p map[string]interface{}) error {
+				pid, err := wmi.Uint64(p["ProcessID"])
+				if err != nil {
+					return nil
+				}
+				// The totals of all processes are named '_Global_'.
+				if _, ok := processes[int32(pid)]; !ok {
+					return nil
+				}
+				fields := make(map[string]interface{})
+				wmi.AddUintFields(fields, p, map[string]string{
+					"Gen0heapsize":          "gen0_size_bytes",
+					"Gen1heapsize":          "gen1_size_bytes",
+					"Gen2heapsize":          "gen2_size_bytes",
+					"LargeObjectHeapsize":   "loh_size_bytes",
+					"NumberBytesinallHeaps": "heap_size_bytes",
+					"NumberGen0Collections": "gen0_collections",
+					"NumberGen1Collections": "gen1_collections",
+					"NumberGen2Collections": "gen2_collections",
+				})
+				addFloatFields(fields, p, map[string]string{
+					"PercentTimeinGC":      "time_in_gc_percent",
+					"AllocatedBytesPersec": "allocated_bytes_per_sec",
+				})
+				instances[fmt.Sprint(p["Name"])] = &instance{pid: int32(pid), fields: fields}
+				return nil
+			})
+		if err != nil {
+			return nil, err
+		}
+		if len(instances) == 0 {
+			return nil, nil
+		}
+
+		err = query(namespace,
+			"SELECT Name, NumberofExcepsThrown, NumberofExcepsThrownPersec FROM Win32_PerfFormattedData_NETFramework_NETCLRExceptions",
+			func(p map[string]interface{}) error {
+				i, ok := instances[fmt.Sprint(p["Name"])]
+				if !ok {
+					return nil
+				}
+				wmi.AddUintFields(i.fields, p, map[string]string{"NumberofExcepsThrown": "exceptions"})
+				addFloatFields(i.fields, p, map[string]string{"NumberofExcepsThrownPersec": "exceptions_per_sec"})
+				return nil
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		err = query(namespace,
+			"SELECT Name, TotalNumberofContentions, ContentionRatePersec, CurrentQueueLength, NumberofcurrentlogicalThreads, NumberofcurrentphysicalThreads FROM Win32_PerfFormattedData_NETFramework_NETCLRLocksAndThreads",
+			func(p map[string]interface{}) error {
+				i, ok := instances[fmt.Sprint(p["Name"])]
+				if !ok {
+					return nil
+				}
+				wmi.AddUintFields(i.fields, p, map[string]string{
+					"TotalNumberofContentions":       "lock_contentions",
+					"CurrentQueueLength":             "lock_queue_length",
+					"NumberofcurrentlogicalThreads":  "logical_threads",
+					"NumberofcurrentphysicalThreads": "physical_threads",
+				})
+				addFloatFields(i.fields, p, map[string]string{"ContentionRatePersec": "lock_contentions_per_sec"})
+				return nil
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		counters := make(map[int32]map[string]interface{}, len(instances))
+		for _, i := range instances {
+			counters[i.pid] = i.fields
+		}
+		return counters, nil
+	}
+}
+
+// addFloatFields adds the properties as float fields with the given names,
+// skipping missing properties.
+func addFloatFields(fields map[string]interface{}, properties map[string]interface{}, names map[string]string) {
+	for property, field := range names {
+		value, ok := properties[property]
+		if !ok {
+			continue
+		}
+		if v, err := wmi.Float64(value); err == nil {
+			fields[field] = v
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//go:build windows
+// +build windows
+
+package dotnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+)
+
+func TestFrameworkCounters(t *testing.T) {
+	framework := frameworkCounters(wmi.FakeQuery(map[string][]map[string]interface{}{
+		"Win32_PerfFormattedData_NETFramework_NETCLRMemory": {
+			{
+				"Name": "w3wp", "ProcessID": int32(200), "Gen0heapsize": "4194304", "Gen1heapsize": "1048576", "Gen2heapsize": "8388608",
+				"LargeObjectHeapsize": "2097152", "NumberBytesinallHeaps": "11534336", "PercentTimeinGC": int32(3),
+				"NumberGen0Collections": int32(120), "NumberGen1Collections": int32(30), "NumberGen2Collections": int32(4),
+				"AllocatedBytesPersec": int32(65536),
+			},
+			{"Name": "w3wp#1", "ProcessID": int32(300), "Gen0heapsize": "4194304"},
+			{"Name": "_Global_", "ProcessID": int32(0), "Gen0heapsize": "8388608"},
+		},
+		"Win32_PerfFormattedData_NETFramework_NETCLRExceptions": {
+			{"Name": "w3wp", "NumberofExcepsThrown": int32(42), "NumberofExcepsThrownPersec": int32(2)},
+			{"Name": "_Global_", "NumberofExcepsThrown": int32(50), "NumberofExcepsThrownPersec": int32(3)},
+		},
+		"Win32_PerfFormattedData_NETFramework_NETCLRLocksAndThreads": {
+			{
+				"Name": "w3wp", "TotalNumberofContentions": int32(17), "ContentionRatePersec": int32(1), "CurrentQueueLength": int32(0),
+				"NumberofcurrentlogicalThreads": int32(35), "NumberofcurrentphysicalThreads": int32(33),
+			},
+		},
+	}))
+
+	counters, err := framework(map[int32]string{200: "w3wp", 400: "app"})
+	require.NoError(t, err)
+	require.Equal(t, map[int32]map[string]interface{}{
+		200: {
+			"gen0_size_bytes":          uint64(4194304),
+			"gen1_size_bytes":          uint64(1048576),
+			"gen2_size_bytes":          uint64(8388608),
+			"loh_size_bytes":           uint64(2097152),
+			"heap_size_bytes":          uint64(11534336),
+			"time_in_gc_percent":       float64(3),
+			"gen0_collections":         uint64(120),
+			"gen1_collections":         uint64(30),
+			"gen2_collections":         uint64(4),
+			"allocated_bytes_per_sec":  float64(65536),
+			"exceptions":               uint64(42),
+			"exceptions_per_sec":       float64(2),
+			"lock_contentions":         uint64(17),
+			"lock_contentions_per_sec": float64(1),
+			"lock_queue_length":        uint64(0),
+			"logical_threads":          uint64(35),
+			"physical_threads":         uint64(33),
+		},
+	}, counters)
+}
+
+func TestFrameworkCountersNoProcesses(t *testing.T) {
+	// The other classes are not queried without matching processes.
+	framework := frameworkCounters(wmi.FakeQuery(map[string][]map[string]interface{}{
+		"Win32_PerfFormattedData_NETFramework_NETCLRMemory": {{"Name": "_Global_", "ProcessID": int32(0)}},
+	}))
+	counters, err := framework(map[int32]string{200: "w3wp"})
+	require.NoError(t, err)
+	require.Empty(t, counters)
+}
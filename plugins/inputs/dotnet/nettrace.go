@@ -0,0 +1,539 @@
+package dotnet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf16"
+)
+
+// Tags of the FastSerialization objects of the trace.
+const (
+	tagNullReference      = 1
+	tagBeginPrivateObject = 5
+	tagEndObject          = 6
+)
+
+// Type codes of the event fields.
+const (
+	typeObject   = 1
+	typeBoolean  = 3
+	typeChar     = 4
+	typeSByte    = 5
+	typeByte     = 6
+	typeInt16    = 7
+	typeUInt16   = 8
+	typeInt32    = 9
+	typeUInt32   = 10
+	typeInt64    = 11
+	typeUInt64   = 12
+	typeSingle   = 13
+	typeDouble   = 14
+	typeDecimal  = 15
+	typeDateTime = 16
+	typeGUID     = 17
+	typeString   = 18
+)
+
+// Flags of the compressed event headers.
+const (
+	headerMetadataID               = 1 << 0
+	headerCaptureThreadAndSequence = 1 << 1
+	headerThreadID                 = 1 << 2
+	headerStackID                  = 1 << 3
+	headerActivityID               = 1 << 4
+	headerRelatedActivityID        = 1 << 5
+	headerDataLength               = 1 << 7
+)
+
+// blockCompressed is the flag of blocks with compressed event headers.
+const blockCompressed = 1
+
+// traceObjectSize is the size of the trace object, i.e. the start time, the
+// timestamp frequency and the process information.
+const traceObjectSize = 48
+
+// field is the description of an event field, objects have nested fields.
+type field struct {
+	name     string
+	typeCode int32
+	fields   []field
+}
+
+// eventMetadata is the description of the events with a metadata ID.
+type eventMetadata struct {
+	provider string
+	name     string
+	fields   []field
+}
+
+// traceEvent is a decoded event of the trace.
+type traceEvent struct {
+	provider string
+	name     string
+	payload  map[string]interface{}
+}
+
+// eventHeader is the header of an event, the compressed headers only contain
+// the changes to the header of the previous event of the block.
+type eventHeader struct {
+	metadataID  int32
+	payloadSize int32
+}
+
+// nettraceReader reads the events of a trace in the nettrace format, the
+// format of the EventPipe sessions streamed by the diagnostics port.
+type nettraceReader struct {
+	r        *bufio.Reader
+	offset   int64
+	metadata map[int32]*eventMetadata
+	pending  []traceEvent
+}
+
+func newNettraceReader(r io.Reader) *nettraceReader {
+	return &nettraceReader{
+		r:        bufio.NewReader(r),
+		metadata: make(map[int32]*eventMetadata),
+	}
+}
+
+// readHeader reads and verifies the header of the trace.
+func (n *nettraceReader) readHeader() error {
+	magic, err := n.read(8)
+	if err != nil {
+		return err
+	}
+	if string(magic) != "Nettrace" {
+		return errors.New("invalid trace format")
+	}
+	length, err := n.readInt32()
+	if err != nil {
+		return err
+	}
+	if length != 20 {
+		return errors.New("invalid serialization header")
+	}
+	signature, err := n.read(int(length))
+	if err != nil {
+		return err
+	}
+	if string(signature) != "!FastSerialization.1" {
+		return errors.New("invalid serialization header")
+	}
+	return nil
+}
+
+// next returns the next event of the trace, io.EOF at the end of the trace.
+func (n *nettraceReader) next() (traceEvent, error) {
+	for len(n.pending) == 0 {
+		if err := n.readObject(); err != nil {
+			return traceEvent{}, err
+		}
+	}
+	e := n.pending[0]
+	n.pending = n.pending[1:]
+	return e, nil
+}
+
+// readObject reads the next object of the trace and decodes the events of
+// event and metadata blocks.
+func (n *nettraceReader) readObject() error {
+	tag, err := n.readByte()
+	if err != nil {
+		return err
+	}
+	if tag == tagNullReference {
+		return io.EOF
+	}
+	if tag != tagBeginPrivateObject {
+		return fmt.Errorf("invalid object tag %d", tag)
+	}
+	name, err := n.readType()
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "Trace":
+		if _, err := n.read(traceObjectSize); err != nil {
+			return err
+		}
+	case "EventBlock", "MetadataBlock", "StackBlock", "SPBlock":
+		size, err := n.readInt32()
+		if err != nil {
+			return err
+		}
+		// The blocks are aligned to four bytes from the start of the trace.
+		if _, err := n.read(int((4 - n.offset%4) % 4)); err != nil {
+			return err
+		}
+		block, err := n.read(int(size))
+		if err != nil {
+			return err
+		}
+		switch name {
+		case "EventBlock":
+			err = n.parseBlock(block, false)
+		case "MetadataBlock":
+			err = n.parseBlock(block, true)
+		}
+		if err != nil {
+			return fmt.Errorf("parsing %s failed: %w", name, err)
+		}
+	default:
+		return fmt.Errorf("unsupported object %q", name)
+	}
+
+	tag, err = n.readByte()
+	if err != nil {
+		return err
+	}
+	if tag != tagEndObject {
+		return fmt.Errorf("invalid end tag %d of object %q", tag, name)
+	}
+	return nil
+}
+
+// readType reads the type of an object and returns its name.
+func (n *nettraceReader) readType() (string, error) {
+	tags, err := n.read(2)
+	if err != nil {
+		return "", err
+	}
+	if tags[0] != tagBeginPrivateObject || tags[1] != tagNullReference {
+		return "", errors.New("invalid type tags")
+	}
+	// The version and minimum reader version are followed by the name.
+	if _, err := n.read(8); err != nil {
+		return "", err
+	}
+	length, err := n.readInt32()
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || length > 256 {
+		return "", fmt.Errorf("invalid type name length %d", length)
+	}
+	name, err := n.read(int(length))
+	if err != nil {
+		return "", err
+	}
+	tag, err := n.readByte()
+	if err != nil {
+		return "", err
+	}
+	if tag != tagEndObject {
+		return "", fmt.Errorf("invalid end tag %d of type %q", tag, name)
+	}
+	return string(name), nil
+}
+
+// parseBlock parses the events of an event or metadata block.
+func (n *nettraceReader) parseBlock(block []byte, metadata bool) error {
+	c := &cursor{b: block}
+	headerSize := c.uint16()
+	flags := c.uint16()
+	c.skip(int(headerSize) - 4)
+	compressed := flags&blockCompressed != 0
+
+	var h eventHeader
+	for c.err == nil && c.len() > 0 {
+		if compressed {
+			readCompressedHeader(c, &h)
+		} else {
+			readHeader(c, &h)
+		}
+		payload := c.bytes(int(h.payloadSize))
+		if !compressed {
+			c.align(4)
+		}
+		if c.err != nil {
+			break
+		}
+
+		if metadata {
+			if err := n.addMetadata(payload); err != nil {
+				return err
+			}
+			continue
+		}
+		m, ok := n.metadata[h.metadataID]
+		if !ok {
+			continue
+		}
+		pc := &cursor{b: payload}
+		values := decodeFields(pc, m.fields)
+		if pc.err != nil {
+			continue
+		}
+		n.pending = append(n.pending, traceEvent{provider: m.provider, name: m.name, payload: values})
+	}
+	return c.err
+}
+
+// readHeader reads an uncompressed event header.
+func readHeader(c *cursor, h *eventHeader) {
+	// The event size is followed by the metadata ID with the sorted flag.
+	c.skip(4)
+	h.metadataID = c.int32() & math.MaxInt32
+	// The sequence number, thread IDs, processor number, stack ID,
+	// timestamp and activity IDs are followed by the payload size.
+	c.skip(4 + 8 + 8 + 4 + 4 + 8 + 16 + 16)
+	h.payloadSize = c.int32()
+}
+
+// readCompressedHeader reads a compressed event header and updates the header
+// of the previous event.
+func readCompressedHeader(c *cursor, h *eventHeader) {
+	flags := c.uint8()
+	if flags&headerMetadataID != 0 {
+		h.metadataID = int32(c.varUint32())
+	}
+	if flags&headerCaptureThreadAndSequence != 0 {
+		c.varUint32()
+		c.varUint64()
+		c.varUint32()
+	}
+	if flags&headerThreadID != 0 {
+		c.varUint64()
+	}
+	if flags&headerStackID != 0 {
+		c.varUint32()
+	}
+	// The timestamp delta is always present.
+	c.varUint64()
+	if flags&headerActivityID != 0 {
+		c.skip(16)
+	}
+	if flags&headerRelatedActivityID != 0 {
+		c.skip(16)
+	}
+	if flags&headerDataLength != 0 {
+		h.payloadSize = int32(c.varUint32())
+	}
+}
+
+// addMetadata parses the payload of a metadata event and adds the metadata.
+func (n *nettraceReader) addMetadata(payload []byte) error {
+	c := &cursor{b: payload}
+	id := c.int32()
+	m := &eventMetadata{provider: c.utf16()}
+	// The event ID is followed by the name, keywords, version and level.
+	c.skip(4)
+	m.name = c.utf16()
+	c.skip(8 + 4 + 4)
+	if c.err == nil && c.len() > 0 {
+		m.fields = parseFields(c)
+	}
+	if c.err != nil {
+		return fmt.Errorf("invalid metadata: %w", c.err)
+	}
+	n.metadata[id] = m
+	return nil
+}
+
+// parseFields parses the field descriptions of the metadata.
+func parseFields(c *cursor) []field {
+	count := c.int32()
+	if count < 0 || int(count) > c.len() {
+		c.fail(fmt.Errorf("invalid field count %d", count))
+		return nil
+	}
+	fields := make([]field, 0, count)
+	for i := int32(0); i < count && c.err == nil; i++ {
+		f := field{typeCode: c.int32()}
+		if f.typeCode == typeObject {
+			f.fields = parseFields(c)
+		}
+		f.name = c.utf16()
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// decodeFields decodes the payload of the fields.
+func decodeFields(c *cursor, fields []field) map[string]interface{} {
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		switch f.typeCode {
+		case typeObject:
+			values[f.name] = decodeFields(c, f.fields)
+		case typeBoolean:
+			values[f.name] = c.uint32() != 0
+		case typeChar:
+			values[f.name] = string(rune(c.uint16()))
+		case typeSByte:
+			values[f.name] = int64(int8(c.uint8()))
+		case typeByte:
+			values[f.name] = uint64(c.uint8())
+		case typeInt16:
+			values[f.name] = int64(int16(c.uint16()))
+		case typeUInt16:
+			values[f.name] = uint64(c.uint16())
+		case typeInt32:
+			values[f.name] = int64(c.int32())
+		case typeUInt32:
+			values[f.name] = uint64(c.uint32())
+		case typeInt64, typeDateTime:
+			values[f.name] = c.int64()
+		case typeUInt64:
+			values[f.name] = uint64(c.int64())
+		case typeSingle:
+			values[f.name] = float64(math.Float32frombits(c.uint32()))
+		case typeDouble:
+			values[f.name] = math.Float64frombits(uint64(c.int64()))
+		case typeDecimal, typeGUID:
+			// Not supported, but skipped to decode the following fields
+			c.skip(16)
+		case typeString:
+			values[f.name] = c.utf16()
+		default:
+			c.fail(fmt.Errorf("unsupported type %d of field %q", f.typeCode, f.name))
+		}
+		if c.err != nil {
+			break
+		}
+	}
+	return values
+}
+
+func (n *nettraceReader) read(size int) ([]byte, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(n.r, buf); err != nil {
+		return nil, err
+	}
+	n.offset += int64(size)
+	return buf, nil
+}
+
+func (n *nettraceReader) readByte() (byte, error) {
+	buf, err := n.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+func (n *nettraceReader) readInt32() (int32, error) {
+	buf, err := n.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(buf)), nil
+}
+
+// cursor reads little-endian values from a buffer, the first error is kept
+// and all following reads return zero values.
+type cursor struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (c *cursor) len() int {
+	return len(c.b) - c.pos
+}
+
+func (c *cursor) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+func (c *cursor) bytes(size int) []byte {
+	if c.err != nil {
+		return nil
+	}
+	if size < 0 || size > c.len() {
+		c.fail(io.ErrUnexpectedEOF)
+		return nil
+	}
+	buf := c.b[c.pos : c.pos+size]
+	c.pos += size
+	return buf
+}
+
+func (c *cursor) skip(size int) {
+	c.bytes(size)
+}
+
+// align skips the padding to the alignment from the start of the buffer.
+func (c *cursor) align(alignment int) {
+	if pad := c.pos % alignment; pad != 0 && c.len() > 0 {
+		c.skip(alignment - pad)
+	}
+}
+
+func (c *cursor) uint8() uint8 {
+	if buf := c.bytes(1); buf != nil {
+		return buf[0]
+	}
+	return 0
+}
+
+func (c *cursor) uint16() uint16 {
+	if buf := c.bytes(2); buf != nil {
+		return binary.LittleEndian.Uint16(buf)
+	}
+	return 0
+}
+
+func (c *cursor) uint32() uint32 {
+	if buf := c.bytes(4); buf != nil {
+		return binary.LittleEndian.Uint32(buf)
+	}
+	return 0
+}
+
+func (c *cursor) int32() int32 {
+	return int32(c.uint32())
+}
+
+func (c *cursor) int64() int64 {
+	if buf := c.bytes(8); buf != nil {
+		return int64(binary.LittleEndian.Uint64(buf))
+	}
+	return 0
+}
+
+// varUint64 reads an unsigned LEB128 encoded integer.
+func (c *cursor) varUint64() uint64 {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b := c.uint8()
+		if c.err != nil {
+			return 0
+		}
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+	c.fail(errors.New("invalid variable length integer"))
+	return 0
+}
+
+func (c *cursor) varUint32() uint32 {
+	return uint32(c.varUint64())
+}
+
+// utf16 reads a null-terminated UTF-16 string.
+func (c *cursor) utf16() string {
+	var s []uint16
+	for {
+		ch := c.uint16()
+		if c.err != nil {
+			return ""
+		}
+		if ch == 0 {
+			return string(utf16.Decode(s))
+		}
+		s = append(s, ch)
+	}
+}
@@ -0,0 +1,323 @@
+//go:build windows
+// +build windows
+
+package etw
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modtdh = windows.NewLazySystemDLL("tdh.dll")
+
+	procTdhEnumerateProviders  = modtdh.NewProc("TdhEnumerateProviders")
+	procTdhGetEventInformation = modtdh.NewProc("TdhGetEventInformation")
+	procTdhGetPropertySize     = modtdh.NewProc("TdhGetPropertySize")
+	procTdhGetProperty         = modtdh.NewProc("TdhGetProperty")
+)
+
+// Flags of the properties.
+const (
+	propertyStruct     = 0x1
+	propertyParamCount = 0x2
+)
+
+// In types of the properties.
+const (
+	tdhInTypeUnicodeString = 1
+	tdhInTypeAnsiString    = 2
+	tdhInTypeInt8          = 3
+	tdhInTypeUint8         = 4
+	tdhInTypeInt16         = 5
+	tdhInTypeUint16        = 6
+	tdhInTypeInt32         = 7
+	tdhInTypeUint32        = 8
+	tdhInTypeInt64         = 9
+	tdhInTypeUint64        = 10
+	tdhInTypeFloat         = 11
+	tdhInTypeDouble        = 12
+	tdhInTypeBoolean       = 13
+	tdhInTypeGUID          = 15
+	tdhInTypePointer       = 16
+	tdhInTypeSID           = 19
+	tdhInTypeHexInt32      = 20
+	tdhInTypeHexInt64      = 21
+)
+
+// traceProviderInfo is TRACE_PROVIDER_INFO.
+type traceProviderInfo struct {
+	ProviderGUID       windows.GUID
+	SchemaSource       uint32
+	ProviderNameOffset uint32
+}
+
+// providerEnumerationInfo is PROVIDER_ENUMERATION_INFO.
+type providerEnumerationInfo struct {
+	NumberOfProviders      uint32
+	Reserved               uint32
+	TraceProviderInfoArray [1]traceProviderInfo
+}
+
+// traceEventInfo is TRACE_EVENT_INFO.
+type traceEventInfo struct {
+	ProviderGUID          windows.GUID
+	EventGUID             windows.GUID
+	EventDescriptor       eventDescriptor
+	DecodingSource        uint32
+	ProviderNameOffset    uint32
+	LevelNameOffset       uint32
+	ChannelNameOffset     uint32
+	KeywordsNameOffset    uint32
+	TaskNameOffset        uint32
+	OpcodeNameOffset      uint32
+	EventMessageOffset    uint32
+	ProviderMessageOffset uint32
+	BinaryXMLOffset       uint32
+	BinaryXMLSize         uint32
+	EventNameOffset       uint32
+	EventAttributesOffset uint32
+	PropertyCount         uint32
+	TopLevelPropertyCount uint32
+	Flags                 uint32
+	EventPropertyInfo     [1]eventPropertyInfo
+}
+
+// eventPropertyInfo is EVENT_PROPERTY_INFO.
+type eventPropertyInfo struct {
+	Flags         uint32
+	NameOffset    uint32
+	InType        uint16
+	OutType       uint16
+	MapNameOffset uint32
+	Count         uint16
+	Length        uint16
+	Reserved      uint32
+}
+
+// propertyDataDescriptor is PROPERTY_DATA_DESCRIPTOR.
+type propertyDataDescriptor struct {
+	PropertyName uint64
+	ArrayIndex   uint32
+	Reserved     uint32
+}
+
+// registeredProviders returns the GUIDs of the registered providers by name.
+func registeredProviders() (map[string]windows.GUID, error) {
+	var size uint32
+	var buf []byte
+	for {
+		var p uintptr
+		if len(buf) > 0 {
+			p = uintptr(unsafe.Pointer(&buf[0]))
+		}
+		r, _, _ := procTdhEnumerateProviders.Call(p, uintptr(unsafe.Pointer(&size)))
+		errno := windows.Errno(r)
+		if errno == windows.ERROR_INSUFFICIENT_BUFFER {
+			buf = make([]byte, size)
+			continue
+		}
+		if errno != 0 {
+			return nil, errno
+		}
+		break
+	}
+
+	info := (*providerEnumerationInfo)(unsafe.Pointer(&buf[0]))
+	entries := unsafe.Slice(&info.TraceProviderInfoArray[0], info.NumberOfProviders)
+	providers := make(map[string]windows.GUID, len(entries))
+	for _, entry := range entries {
+		providers[utf16At(buf, entry.ProviderNameOffset)] = entry.ProviderGUID
+	}
+	return providers, nil
+}
+
+// decodeEvent returns the event of the record. Properties are decoded if the
+// schema of the event is available, only top level scalar properties are
+// decoded.
+func decodeEvent(record *eventRecord) *event {
+	h := &record.EventHeader
+	// The time stamp is a FILETIME, i.e. 100ns intervals since 1601.
+	timestamp := windows.Filetime{
+		LowDateTime:  uint32(h.TimeStamp),
+		HighDateTime: uint32(h.TimeStamp >> 32),
+	}
+	e := &event{
+		providerID: guidString(h.ProviderID),
+		id:         h.EventDescriptor.ID,
+		version:    h.EventDescriptor.Version,
+		level:      h.EventDescriptor.Level,
+		processID:  h.ProcessID,
+		threadID:   h.ThreadID,
+		timestamp:  time.Unix(0, timestamp.Nanoseconds()),
+		properties: make(map[string]interface{}),
+	}
+
+	buf, err := eventInformation(record)
+	if err != nil {
+		return e
+	}
+	info := (*traceEventInfo)(unsafe.Pointer(&buf[0]))
+	e.providerName = utf16At(buf, info.ProviderNameOffset)
+	e.task = utf16At(buf, info.TaskNameOffset)
+	e.opcode = utf16At(buf, info.OpcodeNameOffset)
+
+	properties := unsafe.Slice(&info.EventPropertyInfo[0], info.PropertyCount)
+	for _, property := range properties[:info.TopLevelPropertyCount] {
+		if property.Flags&(propertyStruct|propertyParamCount) != 0 || property.Count > 1 {
+			// Structures and arrays are not supported
+			continue
+		}
+		data, err := propertyData(record, &buf[property.NameOffset])
+		if err != nil {
+			continue
+		}
+		if value := decodeValue(property.InType, data); value != nil {
+			e.properties[utf16At(buf, property.NameOffset)] = value
+		}
+	}
+	return e
+}
+
+// eventInformation returns the TRACE_EVENT_INFO of the record.
+func eventInformation(record *eventRecord) ([]byte, error) {
+	var size uint32
+	r, _, _ := procTdhGetEventInformation.Call(uintptr(unsafe.Pointer(record)), 0, 0, 0, uintptr(unsafe.Pointer(&size)))
+	if errno := windows.Errno(r); errno != windows.ERROR_INSUFFICIENT_BUFFER {
+		return nil, errno
+	}
+	buf := make([]byte, size)
+	r, _, _ = procTdhGetEventInformation.Call(uintptr(unsafe.Pointer(record)), 0, 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if errno := windows.Errno(r); errno != 0 {
+		return nil, errno
+	}
+	return buf, nil
+}
+
+// propertyData returns the data of the top level property with the name.
+func propertyData(record *eventRecord, name *byte) ([]byte, error) {
+	desc := propertyDataDescriptor{
+		PropertyName: uint64(uintptr(unsafe.Pointer(name))),
+		ArrayIndex:   math.MaxUint32,
+	}
+	var size uint32
+	r, _, _ := procTdhGetPropertySize.Call(uintptr(unsafe.Pointer(record)), 0, 0, 1, uintptr(unsafe.Pointer(&desc)), uintptr(unsafe.Pointer(&size)))
+	if errno := windows.Errno(r); errno != 0 {
+		return nil, errno
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	data := make([]byte, size)
+	r, _, _ = procTdhGetProperty.Call(uintptr(unsafe.Pointer(record)), 0, 0, 1, uintptr(unsafe.Pointer(&desc)), uintptr(size), uintptr(unsafe.Pointer(&data[0])))
+	if errno := windows.Errno(r); errno != 0 {
+		return nil, errno
+	}
+	return data, nil
+}
+
+// decodeValue decodes the property data of the in type, nil is returned for
+// unsupported types.
+func decodeValue(inType uint16, data []byte) interface{} {
+	switch inType {
+	case tdhInTypeUnicodeString:
+		if len(data) < 2 {
+			return ""
+		}
+		return windows.UTF16ToString(unsafe.Slice((*uint16)(unsafe.Pointer(&data[0])), len(data)/2))
+	case tdhInTypeAnsiString:
+		for i, c := range data {
+			if c == 0 {
+				return string(data[:i])
+			}
+		}
+		return string(data)
+	case tdhInTypeInt8:
+		if len(data) == 1 {
+			return int64(int8(data[0]))
+		}
+	case tdhInTypeUint8:
+		if len(data) == 1 {
+			return uint64(data[0])
+		}
+	case tdhInTypeInt16:
+		if len(data) == 2 {
+			return int64(int16(binary.LittleEndian.Uint16(data)))
+		}
+	case tdhInTypeUint16:
+		if len(data) == 2 {
+			return uint64(binary.LittleEndian.Uint16(data))
+		}
+	case tdhInTypeInt32:
+		if len(data) == 4 {
+			return int64(int32(binary.LittleEndian.Uint32(data)))
+		}
+	case tdhInTypeUint32, tdhInTypeHexInt32:
+		if len(data) == 4 {
+			return uint64(binary.LittleEndian.Uint32(data))
+		}
+	case tdhInTypeInt64:
+		if len(data) == 8 {
+			return int64(binary.LittleEndian.Uint64(data))
+		}
+	case tdhInTypeUint64, tdhInTypeHexInt64:
+		if len(data) == 8 {
+			return binary.LittleEndian.Uint64(data)
+		}
+	case tdhInTypeFloat:
+		if len(data) == 4 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+		}
+	case tdhInTypeDouble:
+		if len(data) == 8 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(data))
+		}
+	case tdhInTypeBoolean:
+		if len(data) == 4 {
+			return binary.LittleEndian.Uint32(data) != 0
+		}
+	case tdhInTypePointer:
+		// The size of pointers depends on the provider
+		switch len(data) {
+		case 4:
+			return uint64(binary.LittleEndian.Uint32(data))
+		case 8:
+			return binary.LittleEndian.Uint64(data)
+		}
+	case tdhInTypeGUID:
+		if len(data) == 16 {
+			return guidString(*(*windows.GUID)(unsafe.Pointer(&data[0])))
+		}
+	case tdhInTypeSID:
+		// The SID has at least the revision, count and authority
+		if len(data) >= 8 {
+			sid := (*windows.SID)(unsafe.Pointer(&data[0]))
+			if sid.IsValid() {
+				return sid.String()
+			}
+		}
+	}
+	return nil
+}
+
+// guidString returns the GUID in upper case without braces.
+func guidString(g windows.GUID) string {
+	return fmt.Sprintf("%08X-%04X-%04X-%02X%02X-%02X%02X%02X%02X%02X%02X",
+		g.Data1, g.Data2, g.Data3, g.Data4[0], g.Data4[1],
+		g.Data4[2], g.Data4[3], g.Data4[4], g.Data4[5], g.Data4[6], g.Data4[7])
+}
+
+// utf16At returns the null-terminated UTF-16 string at the offset of the
+// buffer, an empty string for a zero offset.
+func utf16At(buf []byte, offset uint32) string {
+	if offset == 0 || int(offset) >= len(buf) {
+		return ""
+	}
+	s := unsafe.Slice((*uint16)(unsafe.Pointer(&buf[offset])), (len(buf)-int(offset))/2)
+	return windows.UTF16ToString(s)
+}
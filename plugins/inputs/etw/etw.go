@@ -0,0 +1,236 @@
+//go:build windows
+// +build windows
+
+package etw
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Name of the trace session. The session is created and stopped with
+  ## Telegraf, unless a session with the name exists already, which is
+  ## attached to and left running.
+  # session_name = "Telegraf"
+
+  ## Properties of the events added as tags, all other properties are added
+  ## as fields.
+  # tag_properties = []
+
+  [[inputs.etw.provider]]
+    ## Name or GUID of the provider, see 'logman query providers'
+    name = "Microsoft-Windows-DNS-Client"
+
+    ## Level of the events: 1 (critical), 2 (error), 3 (warning),
+    ## 4 (information) or 5 (verbose)
+    # level = 4
+
+    ## Keywords of the events, see 'logman query providers <name>'. All events
+    ## are enabled if zero.
+    # match_any_keyword = 0
+    # match_all_keyword = 0
+
+    ## IDs of the events converted to metrics, all if empty
+    # event_ids = []
+`
+
+// event is a decoded event.
+type event struct {
+	providerID   string
+	providerName string
+	id           uint16
+	version      uint8
+	level        uint8
+	task         string
+	opcode       string
+	processID    uint32
+	threadID     uint32
+	timestamp    time.Time
+	properties   map[string]interface{}
+}
+
+// sessionStats are the statistics of the trace session.
+type sessionStats struct {
+	eventsLost          uint32
+	buffersLost         uint32
+	realTimeBuffersLost uint32
+}
+
+// traceSession is a real-time trace session consuming the events of the
+// providers.
+type traceSession interface {
+	// start starts consuming the events and calls the function with every
+	// event.
+	start(fn func(e *event)) error
+	stats() (*sessionStats, error)
+	stop() error
+}
+
+// ETW converts the events of Event Tracing for Windows providers to metrics.
+type ETW struct {
+	SessionName   string     `toml:"session_name"`
+	TagProperties []string   `toml:"tag_properties"`
+	Providers     []Provider `toml:"provider"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	newSession func(name string, providers []Provider) traceSession
+	session    traceSession
+	tags       map[string]bool
+
+	sync.Mutex
+	received uint64
+}
+
+// Provider is an event provider enabled in the session.
+type Provider struct {
+	Name            string   `toml:"name"`
+	Level           uint8    `toml:"level"`
+	MatchAnyKeyword uint64   `toml:"match_any_keyword"`
+	MatchAllKeyword uint64   `toml:"match_all_keyword"`
+	EventIDs        []uint16 `toml:"event_ids"`
+
+	eventIDs map[uint16]bool
+}
+
+func (e *ETW) Description() string {
+	return "Convert events of Event Tracing for Windows providers to metrics"
+}
+
+func (e *ETW) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *ETW) Init() error {
+	if e.SessionName == "" {
+		return errors.New("session_name must not be empty")
+	}
+	for i := range e.Providers {
+		p := &e.Providers[i]
+		if p.Name == "" {
+			return errors.New("provider name must not be empty")
+		}
+		if p.Level == 0 {
+			p.Level = 4
+		}
+		if len(p.EventIDs) > 0 {
+			p.eventIDs = make(map[uint16]bool, len(p.EventIDs))
+			for _, id := range p.EventIDs {
+				p.eventIDs[id] = true
+			}
+		}
+	}
+	e.tags = make(map[string]bool, len(e.TagProperties))
+	for _, property := range e.TagProperties {
+		e.tags[property] = true
+	}
+	return nil
+}
+
+func (e *ETW) Start(acc telegraf.Accumulator) error {
+	e.session = e.newSession(e.SessionName, e.Providers)
+	return e.session.start(func(ev *event) {
+		e.Lock()
+		e.received++
+		e.Unlock()
+
+		if !e.accept(ev) {
+			return
+		}
+		tags, fields := e.convert(ev)
+		acc.AddFields("etw", fields, tags, ev.timestamp)
+	})
+}
+
+// Gather adds the statistics of the session.
+func (e *ETW) Gather(acc telegraf.Accumulator) error {
+	stats, err := e.session.stats()
+	if err != nil {
+		return fmt.Errorf("querying session failed: %w", err)
+	}
+
+	e.Lock()
+	received := e.received
+	e.Unlock()
+
+	fields := map[string]interface{}{
+		"events_received":       received,
+		"events_lost":           stats.eventsLost,
+		"buffers_lost":          stats.buffersLost,
+		"realtime_buffers_lost": stats.realTimeBuffersLost,
+	}
+	acc.AddFields("etw_session", fields, map[string]string{"session": e.SessionName})
+	return nil
+}
+
+func (e *ETW) Stop() {
+	if e.session == nil {
+		return
+	}
+	if err := e.session.stop(); err != nil {
+		e.Log.Errorf("Stopping session failed: %v", err)
+	}
+}
+
+// accept returns true if the event is not filtered by the event IDs of its
+// provider. Events of providers enabled outside of the plugin are accepted.
+func (e *ETW) accept(ev *event) bool {
+	for i := range e.Providers {
+		p := &e.Providers[i]
+		if !strings.EqualFold(p.Name, ev.providerName) && !strings.EqualFold(strings.Trim(p.Name, "{}"), ev.providerID) {
+			continue
+		}
+		return p.eventIDs == nil || p.eventIDs[ev.id]
+	}
+	return true
+}
+
+// convert returns the tags and fields of the event.
+func (e *ETW) convert(ev *event) (map[string]string, map[string]interface{}) {
+	provider := ev.providerName
+	if provider == "" {
+		provider = ev.providerID
+	}
+	tags := map[string]string{
+		"provider": provider,
+		"event_id": strconv.Itoa(int(ev.id)),
+		"level":    strconv.Itoa(int(ev.level)),
+	}
+	if ev.task != "" {
+		tags["task"] = ev.task
+	}
+	if ev.opcode != "" {
+		tags["opcode"] = ev.opcode
+	}
+
+	fields := map[string]interface{}{
+		"process_id": ev.processID,
+		"thread_id":  ev.threadID,
+		"version":    ev.version,
+	}
+	for name, value := range ev.properties {
+		if e.tags[name] {
+			tags[name] = fmt.Sprint(value)
+			continue
+		}
+		fields[name] = value
+	}
+	return tags, fields
+}
+
+func init() {
+	inputs.Add("etw", func() telegraf.Input {
+		return &ETW{
+			SessionName: "Telegraf",
+			newSession:  newRealTimeSession,
+		}
+	})
+}
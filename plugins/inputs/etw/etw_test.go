@@ -0,0 +1,188 @@
+//go:build windows
+// +build windows
+
+package etw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeSession replays the events when started.
+type fakeSession struct {
+	events  []*event
+	stopped bool
+}
+
+func (s *fakeSession) start(fn func(e *event)) error {
+	for _, e := range s.events {
+		fn(e)
+	}
+	return nil
+}
+
+func (s *fakeSession) stats() (*sessionStats, error) {
+	return &sessionStats{eventsLost: 3, buffersLost: 1}, nil
+}
+
+func (s *fakeSession) stop() error {
+	s.stopped = true
+	return nil
+}
+
+func TestInit(t *testing.T) {
+	e := &ETW{SessionName: "Telegraf", Providers: []Provider{{Name: "Microsoft-Windows-DNS-Client", EventIDs: []uint16{3008}}}}
+	require.NoError(t, e.Init())
+	require.Equal(t, uint8(4), e.Providers[0].Level)
+	require.True(t, e.Providers[0].eventIDs[3008])
+
+	e = &ETW{}
+	require.Error(t, e.Init())
+
+	e = &ETW{SessionName: "Telegraf", Providers: []Provider{{}}}
+	require.Error(t, e.Init())
+}
+
+func TestEvents(t *testing.T) {
+	ts := time.Unix(1634201221, 0)
+	session := &fakeSession{
+		events: []*event{
+			{
+				providerID:   "1C95126E-7EEA-49A9-A3FE-A378B03DDB4D",
+				providerName: "Microsoft-Windows-DNS-Client",
+				id:           3008,
+				level:        4,
+				processID:    1234,
+				threadID:     5678,
+				timestamp:    ts,
+				properties: map[string]interface{}{
+					"QueryName":    "example.com",
+					"QueryType":    uint64(1),
+					"QueryStatus":  uint64(0),
+					"QueryResults": "93.184.216.34;",
+				},
+			},
+			{
+				providerID:   "1C95126E-7EEA-49A9-A3FE-A378B03DDB4D",
+				providerName: "Microsoft-Windows-DNS-Client",
+				id:           3006,
+				level:        4,
+				timestamp:    ts,
+				properties:   map[string]interface{}{"QueryName": "example.com"},
+			},
+			{
+				providerID: "22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716",
+				id:         1,
+				version:    3,
+				level:      4,
+				task:       "ProcessStart",
+				opcode:     "Start",
+				processID:  4,
+				threadID:   8,
+				timestamp:  ts,
+				properties: map[string]interface{}{"ProcessID": uint64(4321)},
+			},
+		},
+	}
+
+	e := &ETW{
+		SessionName:   "Telegraf",
+		TagProperties: []string{"QueryName"},
+		Providers: []Provider{
+			{Name: "Microsoft-Windows-DNS-Client", EventIDs: []uint16{3008}},
+			{Name: "{22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716}"},
+		},
+		Log: testutil.Logger{},
+		newSession: func(name string, providers []Provider) traceSession {
+			require.Equal(t, "Telegraf", name)
+			require.Len(t, providers, 2)
+			return session
+		},
+	}
+	require.NoError(t, e.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, e.Start(&acc))
+	require.NoError(t, e.Gather(&acc))
+	e.Stop()
+	require.True(t, session.stopped)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"etw",
+			map[string]string{
+				"provider":  "Microsoft-Windows-DNS-Client",
+				"event_id":  "3008",
+				"level":     "4",
+				"QueryName": "example.com",
+			},
+			map[string]interface{}{
+				"process_id":   uint32(1234),
+				"thread_id":    uint32(5678),
+				"version":      uint8(0),
+				"QueryType":    uint64(1),
+				"QueryStatus":  uint64(0),
+				"QueryResults": "93.184.216.34;",
+			},
+			ts,
+		),
+		testutil.MustMetric(
+			"etw",
+			map[string]string{
+				"provider": "22FB2CD6-0E7B-422B-A0C7-2FAD1FD0E716",
+				"event_id": "1",
+				"level":    "4",
+				"task":     "ProcessStart",
+				"opcode":   "Start",
+			},
+			map[string]interface{}{
+				"process_id": uint32(4),
+				"thread_id":  uint32(8),
+				"version":    uint8(3),
+				"ProcessID":  uint64(4321),
+			},
+			ts,
+		),
+		testutil.MustMetric(
+			"etw_session",
+			map[string]string{"session": "Telegraf"},
+			map[string]interface{}{
+				"events_received":       uint64(3),
+				"events_lost":           uint32(3),
+				"buffers_lost":          uint32(1),
+				"realtime_buffers_lost": uint32(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestDecodeValue(t *testing.T) {
+	tests := []struct {
+		inType   uint16
+		data     []byte
+		expected interface{}
+	}{
+		{tdhInTypeUnicodeString, []byte{'a', 0, 'b', 0, 0, 0}, "ab"},
+		{tdhInTypeAnsiString, []byte{'a', 'b', 0}, "ab"},
+		{tdhInTypeInt8, []byte{0xff}, int64(-1)},
+		{tdhInTypeUint16, []byte{0x01, 0x02}, uint64(0x0201)},
+		{tdhInTypeInt32, []byte{0xfe, 0xff, 0xff, 0xff}, int64(-2)},
+		{tdhInTypeUint64, []byte{1, 0, 0, 0, 0, 0, 0, 0}, uint64(1)},
+		{tdhInTypeDouble, []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f}, float64(1.5)},
+		{tdhInTypeBoolean, []byte{1, 0, 0, 0}, true},
+		{tdhInTypePointer, []byte{0x10, 0, 0, 0}, uint64(0x10)},
+		{tdhInTypeGUID, []byte{0x6e, 0x12, 0x95, 0x1c, 0xea, 0x7e, 0xa9, 0x49, 0xa3, 0xfe, 0xa3, 0x78, 0xb0, 0x3d, 0xdb, 0x4d}, "1C95126E-7EEA-49A9-A3FE-A378B03DDB4D"},
+		{tdhInTypeUint32, []byte{1, 0}, nil},
+		{14, []byte{1, 2, 3}, nil},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, decodeValue(tt.inType, tt.data))
+	}
+}
@@ -0,0 +1,407 @@
+//go:build windows
+// +build windows
+
+package etw
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modadvapi32 = windows.NewLazySystemDLL("advapi32.dll")
+
+	procStartTraceW    = modadvapi32.NewProc("StartTraceW")
+	procControlTraceW  = modadvapi32.NewProc("ControlTraceW")
+	procEnableTraceEx2 = modadvapi32.NewProc("EnableTraceEx2")
+	procOpenTraceW     = modadvapi32.NewProc("OpenTraceW")
+	procProcessTrace   = modadvapi32.NewProc("ProcessTrace")
+	procCloseTrace     = modadvapi32.NewProc("CloseTrace")
+)
+
+// ptrSize is the size of a pointer, the layout of some structures and the
+// passing of 64 bit arguments depend on it.
+const ptrSize = unsafe.Sizeof(uintptr(0))
+
+const (
+	wnodeFlagTracedGUID          = 0x00020000
+	eventTraceRealTimeMode       = 0x00000100
+	eventTraceControlQuery       = 0
+	eventTraceControlStop        = 1
+	processTraceModeRealTime     = 0x00000100
+	processTraceModeEventRecord  = 0x10000000
+	eventControlCodeDisable      = 0
+	eventControlCodeEnable       = 1
+	eventTraceClientContextQPC   = 1
+	maxSessionNameLength         = 1024
+	traceEventSize               = 88
+	traceLogfileHeaderSize       = 264 + 2*ptrSize
+	invalidProcessTraceHandle64  = 0xffffffffffffffff
+	invalidProcessTraceHandle32  = 0x00000000ffffffff
+	eventTracePropertiesNameSize = 2 * maxSessionNameLength
+)
+
+// wnodeHeader is WNODE_HEADER.
+type wnodeHeader struct {
+	BufferSize        uint32
+	ProviderID        uint32
+	HistoricalContext uint64
+	TimeStamp         int64
+	GUID              windows.GUID
+	ClientContext     uint32
+	Flags             uint32
+}
+
+// eventTraceProperties is EVENT_TRACE_PROPERTIES.
+type eventTraceProperties struct {
+	Wnode               wnodeHeader
+	BufferSize          uint32
+	MinimumBuffers      uint32
+	MaximumBuffers      uint32
+	MaximumFileSize     uint32
+	LogFileMode         uint32
+	FlushTimer          uint32
+	EnableFlags         uint32
+	AgeLimit            int32
+	NumberOfBuffers     uint32
+	FreeBuffers         uint32
+	EventsLost          uint32
+	BuffersWritten      uint32
+	LogBuffersLost      uint32
+	RealTimeBuffersLost uint32
+	LoggerThreadID      uintptr
+	LogFileNameOffset   uint32
+	LoggerNameOffset    uint32
+}
+
+// eventTracePropertiesBuffer is the properties followed by the space for the
+// session and log file names.
+type eventTracePropertiesBuffer struct {
+	eventTraceProperties
+	_          uint64 // the structure is 8 byte aligned on 32 bit platforms
+	loggerName [eventTracePropertiesNameSize]byte
+	logFile    [eventTracePropertiesNameSize]byte
+}
+
+func newEventTracePropertiesBuffer() *eventTracePropertiesBuffer {
+	p := &eventTracePropertiesBuffer{}
+	p.Wnode.BufferSize = uint32(unsafe.Sizeof(*p))
+	p.LoggerNameOffset = uint32(unsafe.Offsetof(p.loggerName))
+	p.LogFileNameOffset = uint32(unsafe.Offsetof(p.logFile))
+	return p
+}
+
+// eventTraceLogfile is EVENT_TRACE_LOGFILEW, the current event and header
+// are not used.
+type eventTraceLogfile struct {
+	LogFileName         *uint16
+	LoggerName          *uint16
+	CurrentTime         int64
+	BuffersRead         uint32
+	ProcessTraceMode    uint32
+	CurrentEvent        [traceEventSize]byte
+	LogfileHeader       [traceLogfileHeaderSize]byte
+	BufferCallback      uintptr
+	BufferSize          uint32
+	Filled              uint32
+	EventsLost          uint32
+	EventRecordCallback uintptr
+	IsKernelTrace       uint32
+	Context             uintptr
+	_                   uint64 // the structure is 8 byte aligned on 32 bit platforms
+}
+
+// eventDescriptor is EVENT_DESCRIPTOR.
+type eventDescriptor struct {
+	ID      uint16
+	Version uint8
+	Channel uint8
+	Level   uint8
+	Opcode  uint8
+	Task    uint16
+	Keyword uint64
+}
+
+// eventHeader is EVENT_HEADER.
+type eventHeader struct {
+	Size            uint16
+	HeaderType      uint16
+	Flags           uint16
+	EventProperty   uint16
+	ThreadID        uint32
+	ProcessID       uint32
+	TimeStamp       int64
+	ProviderID      windows.GUID
+	EventDescriptor eventDescriptor
+	ProcessorTime   uint64
+	ActivityID      windows.GUID
+}
+
+// eventRecord is EVENT_RECORD.
+type eventRecord struct {
+	EventHeader       eventHeader
+	BufferContext     uint32
+	ExtendedDataCount uint16
+	UserDataLength    uint16
+	ExtendedData      uintptr
+	UserData          uintptr
+	UserContext       uintptr
+}
+
+// Callbacks cannot be released, so a single callback dispatches the events
+// of all sessions by the ID in the context of the record.
+var (
+	callbackOnce sync.Once
+	callback     uintptr
+
+	consumersMu sync.Mutex
+	consumers   = make(map[uintptr]func(record *eventRecord))
+	consumerID  uintptr
+)
+
+func eventRecordCallback(record *eventRecord) uintptr {
+	consumersMu.Lock()
+	fn := consumers[record.UserContext]
+	consumersMu.Unlock()
+
+	if fn != nil {
+		fn(record)
+	}
+	return 0
+}
+
+// realTimeSession is a real-time trace session.
+type realTimeSession struct {
+	name      string
+	providers []Provider
+
+	// handle is the handle of the session if created or attached to.
+	handle  uint64
+	created bool
+	// enabled are the providers enabled in the session.
+	enabled []windows.GUID
+
+	trace uint64
+	id    uintptr
+	done  chan struct{}
+}
+
+func newRealTimeSession(name string, providers []Provider) traceSession {
+	return &realTimeSession{name: name, providers: providers}
+}
+
+func (s *realTimeSession) start(fn func(e *event)) error {
+	if err := s.open(); err != nil {
+		return err
+	}
+	for _, p := range s.providers {
+		if err := s.enable(p); err != nil {
+			s.close()
+			return fmt.Errorf("enabling provider %q failed: %w", p.Name, err)
+		}
+	}
+
+	callbackOnce.Do(func() {
+		callback = windows.NewCallback(eventRecordCallback)
+	})
+	consumersMu.Lock()
+	consumerID++
+	s.id = consumerID
+	consumersMu.Unlock()
+
+	name, err := windows.UTF16PtrFromString(s.name)
+	if err != nil {
+		s.close()
+		return err
+	}
+	logfile := eventTraceLogfile{
+		LoggerName:          name,
+		ProcessTraceMode:    processTraceModeRealTime | processTraceModeEventRecord,
+		EventRecordCallback: callback,
+		Context:             s.id,
+	}
+	r1, r2, err := procOpenTraceW.Call(uintptr(unsafe.Pointer(&logfile)))
+	trace := uint64(r1)
+	if ptrSize == 4 {
+		trace |= uint64(r2) << 32
+	}
+	if trace == invalidProcessTraceHandle64 || trace == invalidProcessTraceHandle32 {
+		s.close()
+		return fmt.Errorf("opening trace failed: %w", err)
+	}
+	s.trace = trace
+
+	// The consumer is registered once the trace is open, as events are only
+	// delivered by ProcessTrace, so failed starts do not leave it behind.
+	consumersMu.Lock()
+	consumers[s.id] = func(record *eventRecord) {
+		fn(decodeEvent(record))
+	}
+	consumersMu.Unlock()
+
+	// ProcessTrace blocks until the trace is closed.
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		procProcessTrace.Call(uintptr(unsafe.Pointer(&trace)), 1, 0, 0) //nolint:errcheck,revive // returns when closed
+	}()
+	return nil
+}
+
+func (s *realTimeSession) stats() (*sessionStats, error) {
+	props := newEventTracePropertiesBuffer()
+	if err := controlTrace(s.name, props, eventTraceControlQuery); err != nil {
+		return nil, err
+	}
+	return &sessionStats{
+		eventsLost:          props.EventsLost,
+		buffersLost:         props.LogBuffersLost,
+		realTimeBuffersLost: props.RealTimeBuffersLost,
+	}, nil
+}
+
+func (s *realTimeSession) stop() error {
+	if s.trace != 0 {
+		r, _, _ := procCloseTrace.Call(uint64Args(s.trace)...)
+		if errno := windows.Errno(r); errno != 0 && errno != windows.ERROR_CTX_CLOSE_PENDING {
+			return fmt.Errorf("closing trace failed: %w", errno)
+		}
+		<-s.done
+		s.trace = 0
+	}
+
+	consumersMu.Lock()
+	delete(consumers, s.id)
+	consumersMu.Unlock()
+
+	return s.close()
+}
+
+// open creates the session or attaches to the existing session with the
+// name.
+func (s *realTimeSession) open() error {
+	name, err := windows.UTF16PtrFromString(s.name)
+	if err != nil {
+		return err
+	}
+	if len(s.name) >= maxSessionNameLength {
+		return errors.New("session name too long")
+	}
+
+	props := newEventTracePropertiesBuffer()
+	props.Wnode.Flags = wnodeFlagTracedGUID
+	props.Wnode.ClientContext = eventTraceClientContextQPC
+	props.LogFileMode = eventTraceRealTimeMode
+	var handle uint64
+	r, _, _ := procStartTraceW.Call(
+		uintptr(unsafe.Pointer(&handle)),
+		uintptr(unsafe.Pointer(name)),
+		uintptr(unsafe.Pointer(props)),
+	)
+	switch errno := windows.Errno(r); errno {
+	case 0:
+		s.handle, s.created = handle, true
+		return nil
+	case windows.ERROR_ALREADY_EXISTS:
+		// The handle of the existing session is returned by a query.
+		props = newEventTracePropertiesBuffer()
+		if err := controlTrace(s.name, props, eventTraceControlQuery); err != nil {
+			return err
+		}
+		s.handle = props.Wnode.HistoricalContext
+		return nil
+	default:
+		return fmt.Errorf("starting session failed: %w", errno)
+	}
+}
+
+// enable enables the provider in the session.
+func (s *realTimeSession) enable(p Provider) error {
+	guid, err := providerGUID(p.Name)
+	if err != nil {
+		return err
+	}
+
+	args := uint64Args(s.handle)
+	args = append(args, uintptr(unsafe.Pointer(&guid)), eventControlCodeEnable, uintptr(p.Level))
+	args = append(args, uint64Args(p.MatchAnyKeyword)...)
+	args = append(args, uint64Args(p.MatchAllKeyword)...)
+	args = append(args, 0, 0)
+	r, _, _ := procEnableTraceEx2.Call(args...)
+	if errno := windows.Errno(r); errno != 0 {
+		return errno
+	}
+	s.enabled = append(s.enabled, guid)
+	return nil
+}
+
+// close stops the session if it was created, otherwise the providers enabled
+// in the existing session are disabled.
+func (s *realTimeSession) close() error {
+	if s.created {
+		s.created = false
+		props := newEventTracePropertiesBuffer()
+		if err := controlTrace(s.name, props, eventTraceControlStop); err != nil {
+			return fmt.Errorf("stopping session failed: %w", err)
+		}
+		return nil
+	}
+
+	for _, guid := range s.enabled {
+		guid := guid
+		args := uint64Args(s.handle)
+		args = append(args, uintptr(unsafe.Pointer(&guid)), eventControlCodeDisable, 0)
+		args = append(args, uint64Args(0)...)
+		args = append(args, uint64Args(0)...)
+		args = append(args, 0, 0)
+		procEnableTraceEx2.Call(args...) //nolint:errcheck,revive // nothing to do on error
+	}
+	s.enabled = nil
+	return nil
+}
+
+// controlTrace runs the control code on the session with the name.
+func controlTrace(name string, props *eventTracePropertiesBuffer, code uint32) error {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	args := uint64Args(0)
+	args = append(args, uintptr(unsafe.Pointer(namePtr)), uintptr(unsafe.Pointer(props)), uintptr(code))
+	r, _, _ := procControlTraceW.Call(args...)
+	if errno := windows.Errno(r); errno != 0 && errno != windows.ERROR_MORE_DATA {
+		return errno
+	}
+	return nil
+}
+
+// providerGUID returns the GUID of the provider given by name or GUID.
+func providerGUID(name string) (windows.GUID, error) {
+	if strings.HasPrefix(name, "{") {
+		return windows.GUIDFromString(name)
+	}
+	providers, err := registeredProviders()
+	if err != nil {
+		return windows.GUID{}, fmt.Errorf("listing providers failed: %w", err)
+	}
+	for providerName, guid := range providers {
+		if strings.EqualFold(providerName, name) {
+			return guid, nil
+		}
+	}
+	return windows.GUID{}, errors.New("unknown provider")
+}
+
+// uint64Args returns the arguments of a 64 bit value passed by value, which
+// takes two arguments on 32 bit platforms.
+func uint64Args(v uint64) []uintptr {
+	if ptrSize == 8 {
+		return []uintptr{uintptr(v)}
+	}
+	return []uintptr{uintptr(v), uintptr(v >> 32)}
+}
@@ -0,0 +1,253 @@
+//go:build windows
+// +build windows
+
+package exchange
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Collect the RPC Client Access and database cache performance counters.
+  # performance_counters = true
+
+  ## Collect the mail queues and the Managed Availability health sets of the
+  ## server using the Exchange Management Shell. Requires a role with the
+  ## Get-Queue and Get-HealthReport cmdlets, e.g. View-Only Organization
+  ## Management.
+  # management_shell = true
+
+  ## Names of the health sets to collect, all if empty. Globs accepted.
+  # health_sets = []
+
+  ## Timeout of the Exchange Management Shell, loading the shell takes several
+  ## seconds.
+  # timeout = "60s"
+`
+
+const namespace = `root\cimv2`
+
+// shellScript returns the queues and health sets of the local server as JSON.
+// Enumerations are converted to strings, ConvertTo-Json writes them as
+// numbers.
+const shellScript = `
+$ErrorActionPreference = 'Stop'
+$WarningPreference = 'SilentlyContinue'
+$ProgressPreference = 'SilentlyContinue'
+try {
+  Add-PSSnapin Microsoft.Exchange.Management.PowerShell.SnapIn
+  $queues = Get-Queue -Server $env:COMPUTERNAME | ForEach-Object {
+    [pscustomobject]@{
+      Identity = "$($_.Identity)"; DeliveryType = "$($_.DeliveryType)"; Status = "$($_.Status)"
+      NextHopDomain = "$($_.NextHopDomain)"; MessageCount = [int64]$_.MessageCount
+    }
+  }
+  $healthSets = Get-HealthReport -Identity $env:COMPUTERNAME | ForEach-Object {
+    [pscustomobject]@{ HealthSet = "$($_.HealthSet)"; AlertValue = "$($_.AlertValue)"; MonitorCount = [int64]$_.MonitorCount }
+  }
+  [pscustomobject]@{ Queues = @($queues); HealthSets = @($healthSets) } | ConvertTo-Json -Compress -Depth 3
+} catch {
+  Write-Output $_.Exception.Message
+  exit 1
+}
+`
+
+// healthStates are the codes of the alert values of the health sets.
+var healthStates = map[string]int64{
+	"healthy":   0,
+	"degraded":  1,
+	"unhealthy": 2,
+	"repairing": 3,
+	"disabled":  4,
+	"unknown":   5,
+}
+
+// Exchange collects the health of a Microsoft Exchange server.
+type Exchange struct {
+	PerformanceCounters bool            `toml:"performance_counters"`
+	ManagementShell     bool            `toml:"management_shell"`
+	HealthSets          []string        `toml:"health_sets"`
+	Timeout             config.Duration `toml:"timeout"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	query  wmi.QueryFunc
+	run    func(timeout time.Duration) ([]byte, error)
+}
+
+// shellOutput is the output of the management shell script.
+type shellOutput struct {
+	Queues []struct {
+		Identity      string
+		DeliveryType  string
+		Status        string
+		NextHopDomain string
+		MessageCount  int64
+	}
+	HealthSets []struct {
+		HealthSet    string
+		AlertValue   string
+		MonitorCount int64
+	}
+}
+
+func (e *Exchange) Description() string {
+	return "Collect the health of a Microsoft Exchange server"
+}
+
+func (e *Exchange) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Exchange) Init() error {
+	f, err := filter.Compile(e.HealthSets)
+	if err != nil {
+		return fmt.Errorf("compiling health_sets failed: %w", err)
+	}
+	e.filter = f
+	return nil
+}
+
+func (e *Exchange) Gather(acc telegraf.Accumulator) error {
+	if e.PerformanceCounters {
+		if err := e.gatherRPC(acc); err != nil {
+			acc.AddError(fmt.Errorf("querying RPC Client Access counters failed: %w", err))
+		}
+		if err := e.gatherDatabases(acc); err != nil {
+			acc.AddError(fmt.Errorf("querying database counters failed: %w", err))
+		}
+	}
+	if e.ManagementShell {
+		if err := e.gatherShell(acc); err != nil {
+			acc.AddError(fmt.Errorf("running management shell failed: %w", err))
+		}
+	}
+	return nil
+}
+
+func (e *Exchange) gatherRPC(acc telegraf.Accumulator) error {
+	return e.query(namespace,
+		"SELECT RPCAveragedLatency, RPCRequests, RPCOperationsPersec, ActiveUserCount, UserCount, ConnectionCount FROM Win32_PerfFormattedData_MSExchangeRpcClientAccess_MSExchangeRpcClientAccess",
+		func(p map[string]interface{}) error {
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"RPCAveragedLatency":  "rpc_average_latency_ms",
+				"RPCRequests":         "rpc_requests",
+				"RPCOperationsPersec": "rpc_operations_per_sec",
+				"ActiveUserCount":     "active_users",
+				"UserCount":           "users",
+				"ConnectionCount":     "connections",
+			})
+			acc.AddFields("exchange_rpc", fields, nil)
+			return nil
+		})
+}
+
+// gatherDatabases adds the cache hit ratio of the mailbox databases, the
+// instances named 'Information Store - <database>'. The instances of the
+// transport and the totals are skipped.
+func (e *Exchange) gatherDatabases(acc telegraf.Accumulator) error {
+	return e.query(namespace,
+		"SELECT Name, DatabaseCachePercentHit FROM Win32_PerfFormattedData_ESE_MSExchangeDatabaseInstances",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["Name"])
+			if !strings.HasPrefix(name, "Information Store - ") {
+				return nil
+			}
+			database := strings.TrimPrefix(name, "Information Store - ")
+			if i := strings.Index(database, "/"); i >= 0 {
+				database = database[:i]
+			}
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{"DatabaseCachePercentHit": "cache_hit_percent"})
+			acc.AddFields("exchange_database", fields, map[string]string{"database": database})
+			return nil
+		})
+}
+
+func (e *Exchange) gatherShell(acc telegraf.Accumulator) error {
+	out, err := e.run(time.Duration(e.Timeout))
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	var result shellOutput
+	if err := json.Unmarshal(out, &result); err != nil {
+		return fmt.Errorf("parsing output failed: %w", err)
+	}
+
+	for _, q := range result.Queues {
+		tags := map[string]string{
+			"queue":         q.Identity,
+			"delivery_type": q.DeliveryType,
+		}
+		if q.NextHopDomain != "" {
+			tags["next_hop"] = q.NextHopDomain
+		}
+		fields := map[string]interface{}{
+			"messages": q.MessageCount,
+			"status":   strings.ToLower(q.Status),
+		}
+		acc.AddFields("exchange_queue", fields, tags)
+	}
+
+	for _, h := range result.HealthSets {
+		if e.filter != nil && !e.filter.Match(h.HealthSet) {
+			continue
+		}
+		state := strings.ToLower(h.AlertValue)
+		code, ok := healthStates[state]
+		if !ok {
+			state = "unknown"
+			code = healthStates[state]
+		}
+		fields := map[string]interface{}{
+			"state":      state,
+			"state_code": code,
+			"monitors":   h.MonitorCount,
+		}
+		acc.AddFields("exchange_health_set", fields, map[string]string{"health_set": h.HealthSet})
+	}
+	return nil
+}
+
+// runShell runs the script in the Exchange Management Shell. The script is
+// passed encoded to avoid quoting the command line.
+func runShell(timeout time.Duration) ([]byte, error) {
+	script := utf16.Encode([]rune(shellScript))
+	encoded := make([]byte, 0, 2*len(script))
+	for _, ch := range script {
+		encoded = append(encoded, byte(ch), byte(ch>>8))
+	}
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-EncodedCommand",
+		base64.StdEncoding.EncodeToString(encoded))
+	return internal.CombinedOutputTimeout(cmd, timeout)
+}
+
+func init() {
+	inputs.Add("exchange", func() telegraf.Input {
+		return &Exchange{
+			PerformanceCounters: true,
+			ManagementShell:     true,
+			Timeout:             config.Duration(60 * time.Second),
+			query:               (&wmi.Connection{}).Query,
+			run:                 runShell,
+		}
+	})
+}
@@ -0,0 +1,125 @@
+//go:build windows
+// +build windows
+
+package exchange
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const shellJSON = `{"Queues":[` +
+	`{"Identity":"EX01\\Submission","DeliveryType":"Undefined","Status":"Ready","NextHopDomain":"Submission","MessageCount":0},` +
+	`{"Identity":"EX01\\3","DeliveryType":"SmtpDeliveryToMailbox","Status":"Retry","NextHopDomain":"mailbox database 01","MessageCount":12},` +
+	`{"Identity":"EX01\\Poison","DeliveryType":"Undefined","Status":"Ready","NextHopDomain":"","MessageCount":1}],` +
+	`"HealthSets":[` +
+	`{"HealthSet":"Transport","AlertValue":"Healthy","MonitorCount":42},` +
+	`{"HealthSet":"OWA.Protocol","AlertValue":"Unhealthy","MonitorCount":8},` +
+	`{"HealthSet":"Store","AlertValue":"NotApplicable","MonitorCount":3}]}`
+
+func TestGather(t *testing.T) {
+	e := &Exchange{
+		PerformanceCounters: true,
+		ManagementShell:     true,
+		HealthSets:          []string{"Transport", "OWA.*", "Store"},
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Win32_PerfFormattedData_MSExchangeRpcClientAccess_MSExchangeRpcClientAccess": {
+				{"RPCAveragedLatency": "12", "RPCRequests": int32(3), "RPCOperationsPersec": int32(150), "ActiveUserCount": int32(80), "UserCount": int32(95), "ConnectionCount": int32(210)},
+			},
+			"Win32_PerfFormattedData_ESE_MSExchangeDatabaseInstances": {
+				{"Name": "Information Store - DB01/_Total", "DatabaseCachePercentHit": "99"},
+				{"Name": "Information Store - DB02", "DatabaseCachePercentHit": "97"},
+				{"Name": "Information Store/_Total", "DatabaseCachePercentHit": "98"},
+				{"Name": "edgetransport/Transport Mail Database", "DatabaseCachePercentHit": "100"},
+			},
+		}),
+		run: func(time.Duration) ([]byte, error) { return []byte(shellJSON), nil },
+	}
+	require.NoError(t, e.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, e.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("exchange_rpc",
+			map[string]string{},
+			map[string]interface{}{
+				"rpc_average_latency_ms": uint64(12),
+				"rpc_requests":           uint64(3),
+				"rpc_operations_per_sec": uint64(150),
+				"active_users":           uint64(80),
+				"users":                  uint64(95),
+				"connections":            uint64(210),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("exchange_database",
+			map[string]string{"database": "DB01"},
+			map[string]interface{}{"cache_hit_percent": uint64(99)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("exchange_database",
+			map[string]string{"database": "DB02"},
+			map[string]interface{}{"cache_hit_percent": uint64(97)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("exchange_queue",
+			map[string]string{"queue": `EX01\Submission`, "delivery_type": "Undefined", "next_hop": "Submission"},
+			map[string]interface{}{"messages": int64(0), "status": "ready"},
+			time.Unix(0, 0)),
+		testutil.MustMetric("exchange_queue",
+			map[string]string{"queue": `EX01\3`, "delivery_type": "SmtpDeliveryToMailbox", "next_hop": "mailbox database 01"},
+			map[string]interface{}{"messages": int64(12), "status": "retry"},
+			time.Unix(0, 0)),
+		testutil.MustMetric("exchange_queue",
+			map[string]string{"queue": `EX01\Poison`, "delivery_type": "Undefined"},
+			map[string]interface{}{"messages": int64(1), "status": "ready"},
+			time.Unix(0, 0)),
+		testutil.MustMetric("exchange_health_set",
+			map[string]string{"health_set": "Transport"},
+			map[string]interface{}{"state": "healthy", "state_code": int64(0), "monitors": int64(42)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("exchange_health_set",
+			map[string]string{"health_set": "OWA.Protocol"},
+			map[string]interface{}{"state": "unhealthy", "state_code": int64(2), "monitors": int64(8)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("exchange_health_set",
+			map[string]string{"health_set": "Store"},
+			map[string]interface{}{"state": "unknown", "state_code": int64(5), "monitors": int64(3)},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherShellError(t *testing.T) {
+	e := &Exchange{
+		ManagementShell: true,
+		run: func(time.Duration) ([]byte, error) {
+			return []byte("No snap-ins have been registered for Windows PowerShell version 5.\r\n"), errors.New("exit status 1")
+		},
+	}
+	require.NoError(t, e.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, e.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.EqualError(t, acc.Errors[0], "running management shell failed: exit status 1: No snap-ins have been registered for Windows PowerShell version 5.")
+}
+
+func TestGatherEmpty(t *testing.T) {
+	e := &Exchange{
+		ManagementShell: true,
+		run:             func(time.Duration) ([]byte, error) { return []byte(`{"Queues":[],"HealthSets":[]}`), nil },
+	}
+	require.NoError(t, e.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, e.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -124,7 +125,7 @@ func (f *Fibaro) Gather(acc telegraf.Accumulator) error {
 	if f.client == nil {
 		f.client = &http.Client{
 			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
+				Proxy: internal.Proxy,
 			},
 			Timeout: time.Duration(f.Timeout),
 		}
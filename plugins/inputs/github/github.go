@@ -13,6 +13,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/selfstat"
 )
@@ -72,7 +73,7 @@ func (g *GitHub) Description() string {
 func (g *GitHub) createGitHubClient(ctx context.Context) (*githubLib.Client, error) {
 	httpClient := &http.Client{
 		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
+			Proxy: internal.Proxy,
 		},
 		Timeout: time.Duration(g.HTTPTimeout),
 	}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -148,7 +149,7 @@ var ErrRedirectAttempted = errors.New("redirect")
 // Set the proxy. A configured proxy overwrites the system wide proxy.
 func getProxyFunc(httpProxy string) func(*http.Request) (*url.URL, error) {
 	if httpProxy == "" {
-		return http.ProxyFromEnvironment
+		return internal.Proxy
 	}
 	proxyURL, err := url.Parse(httpProxy)
 	if err != nil {
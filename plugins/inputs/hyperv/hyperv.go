@@ -0,0 +1,238 @@
+//go:build windows
+// +build windows
+
+package hyperv
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the virtual machines to collect, all if empty. Globs accepted.
+  # vm_names = []
+`
+
+// Namespaces of the queried classes.
+const (
+	virtualizationNamespace = `root\virtualization\v2`
+	cimv2Namespace          = `root\cimv2`
+)
+
+// HyperV collects metrics of the virtual machines of a Hyper-V host.
+type HyperV struct {
+	VMNames []string `toml:"vm_names"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	query  wmi.QueryFunc
+}
+
+// vm is a virtual machine and its metrics.
+type vm struct {
+	id     string
+	name   string
+	fields map[string]interface{}
+	// cpuTotal is the sum of the run time of the virtual processors.
+	cpuTotal float64
+	cpus     int
+}
+
+func (h *HyperV) Description() string {
+	return "Collect metrics of the virtual machines of a Hyper-V host"
+}
+
+func (h *HyperV) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HyperV) Init() error {
+	f, err := filter.Compile(h.VMNames)
+	if err != nil {
+		return fmt.Errorf("compiling vm_names failed: %w", err)
+	}
+	h.filter = f
+	return nil
+}
+
+func (h *HyperV) Gather(acc telegraf.Accumulator) error {
+	vms, err := h.gatherVMs()
+	if err != nil {
+		return fmt.Errorf("querying virtual machines failed: %w", err)
+	}
+
+	if err := h.gatherCPU(vms); err != nil {
+		acc.AddError(fmt.Errorf("querying virtual processors failed: %w", err))
+	}
+	if err := h.gatherMemory(vms); err != nil {
+		acc.AddError(fmt.Errorf("querying dynamic memory failed: %w", err))
+	}
+	for _, v := range vms {
+		if v.cpus > 0 {
+			v.fields["virtual_processors"] = v.cpus
+			v.fields["cpu_usage_percent"] = v.cpuTotal / float64(v.cpus)
+		}
+		acc.AddFields("hyperv_vm", v.fields, map[string]string{"vm_name": v.name, "vm_id": v.id})
+	}
+
+	if err := h.gatherDisks(acc, vms); err != nil {
+		acc.AddError(fmt.Errorf("querying virtual disks failed: %w", err))
+	}
+	if err := h.gatherSwitches(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying virtual switches failed: %w", err))
+	}
+	return nil
+}
+
+// gatherVMs returns the virtual machines matching the filter by name.
+func (h *HyperV) gatherVMs() (map[string]*vm, error) {
+	vms := make(map[string]*vm)
+	err := h.query(virtualizationNamespace,
+		"SELECT Name, ElementName, EnabledState, OnTimeInMilliseconds FROM Msvm_ComputerSystem WHERE Caption = 'Virtual Machine'",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["ElementName"])
+			if h.filter != nil && !h.filter.Match(name) {
+				return nil
+			}
+
+			fields := make(map[string]interface{})
+			if state, err := wmi.Uint64(p["EnabledState"]); err == nil {
+				fields["state"] = state
+			}
+			if uptime, err := wmi.Uint64(p["OnTimeInMilliseconds"]); err == nil {
+				fields["uptime"] = uptime / 1000
+			}
+			vms[name] = &vm{id: fmt.Sprint(p["Name"]), name: name, fields: fields}
+			return nil
+		})
+	return vms, err
+}
+
+// gatherCPU adds the run time of the virtual processors, named
+// '<vm>:Hv VP <index>', to the virtual machines.
+func (h *HyperV) gatherCPU(vms map[string]*vm) error {
+	return h.query(cimv2Namespace,
+		"SELECT Name, PercentTotalRunTime FROM Win32_PerfFormattedData_HvStats_HyperVHypervisorVirtualProcessor",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["Name"])
+			i := strings.LastIndex(name, ":")
+			if i < 0 {
+				return nil
+			}
+			v, ok := vms[name[:i]]
+			if !ok {
+				return nil
+			}
+			if runTime, err := wmi.Float64(p["PercentTotalRunTime"]); err == nil {
+				v.cpuTotal += runTime
+				v.cpus++
+			}
+			return nil
+		})
+}
+
+// gatherMemory adds the dynamic memory metrics to the virtual machines.
+func (h *HyperV) gatherMemory(vms map[string]*vm) error {
+	return h.query(cimv2Namespace,
+		"SELECT Name, CurrentPressure, AveragePressure, PhysicalMemory, GuestVisiblePhysicalMemory FROM Win32_PerfFormattedData_BalancerStats_HyperVDynamicMemoryVM",
+		func(p map[string]interface{}) error {
+			v, ok := vms[fmt.Sprint(p["Name"])]
+			if !ok {
+				return nil
+			}
+			wmi.AddUintFields(v.fields, p, map[string]string{
+				"CurrentPressure":            "memory_pressure",
+				"AveragePressure":            "memory_average_pressure",
+				"PhysicalMemory":             "memory_physical_mb",
+				"GuestVisiblePhysicalMemory": "memory_guest_visible_mb",
+			})
+			return nil
+		})
+}
+
+// gatherDisks adds the metrics of the virtual disks of the virtual machines.
+// The instances of the storage devices are named by the path of the disk
+// file with the backslashes replaced by dashes.
+func (h *HyperV) gatherDisks(acc telegraf.Accumulator, vms map[string]*vm) error {
+	ids := make(map[string]*vm, len(vms))
+	for _, v := range vms {
+		ids[strings.ToUpper(v.id)] = v
+	}
+
+	type disk struct {
+		path string
+		vm   *vm
+	}
+	disks := make(map[string]disk)
+	err := h.query(virtualizationNamespace,
+		"SELECT InstanceID, HostResource FROM Msvm_StorageAllocationSettingData",
+		func(p map[string]interface{}) error {
+			// The instance ID is 'Microsoft:<vm id>\<device>'.
+			id := strings.TrimPrefix(fmt.Sprint(p["InstanceID"]), "Microsoft:")
+			if i := strings.Index(id, `\`); i >= 0 {
+				id = id[:i]
+			}
+			v, ok := ids[strings.ToUpper(id)]
+			if !ok {
+				return nil
+			}
+			resources, _ := p["HostResource"].([]interface{})
+			for _, resource := range resources {
+				path := fmt.Sprint(resource)
+				disks[strings.ToUpper(strings.ReplaceAll(path, `\`, "-"))] = disk{path: path, vm: v}
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	return h.query(cimv2Namespace,
+		"SELECT Name, ReadOperationsPerSec, WriteOperationsPerSec, ReadBytesPerSec, WriteBytesPerSec, Latency FROM Win32_PerfFormattedData_Counters_HyperVVirtualStorageDevice",
+		func(p map[string]interface{}) error {
+			d, ok := disks[strings.ToUpper(fmt.Sprint(p["Name"]))]
+			if !ok {
+				return nil
+			}
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"ReadOperationsPerSec":  "read_ops_per_sec",
+				"WriteOperationsPerSec": "write_ops_per_sec",
+				"ReadBytesPerSec":       "read_bytes_per_sec",
+				"WriteBytesPerSec":      "write_bytes_per_sec",
+				"Latency":               "latency_ms",
+			})
+			tags := map[string]string{"vm_name": d.vm.name, "vm_id": d.vm.id, "disk": d.path}
+			acc.AddFields("hyperv_vm_disk", fields, tags)
+			return nil
+		})
+}
+
+// gatherSwitches adds the throughput of the virtual switches.
+func (h *HyperV) gatherSwitches(acc telegraf.Accumulator) error {
+	return h.query(cimv2Namespace,
+		"SELECT Name, BytesReceivedPersec, BytesSentPersec, PacketsReceivedPersec, PacketsSentPersec FROM Win32_PerfFormattedData_NvspSwitchStats_HyperVVirtualSwitch",
+		func(p map[string]interface{}) error {
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"BytesReceivedPersec":   "bytes_received_per_sec",
+				"BytesSentPersec":       "bytes_sent_per_sec",
+				"PacketsReceivedPersec": "packets_received_per_sec",
+				"PacketsSentPersec":     "packets_sent_per_sec",
+			})
+			acc.AddFields("hyperv_vswitch", fields, map[string]string{"switch": fmt.Sprint(p["Name"])})
+			return nil
+		})
+}
+
+func init() {
+	inputs.Add("hyperv", func() telegraf.Input {
+		return &HyperV{query: (&wmi.Connection{}).Query}
+	})
+}
@@ -0,0 +1,100 @@
+//go:build windows
+// +build windows
+
+package hyperv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	h := &HyperV{
+		VMNames: []string{"web*"},
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Msvm_ComputerSystem": {
+				{"Name": "1A2B", "ElementName": "web1", "EnabledState": int32(2), "OnTimeInMilliseconds": "3600000"},
+				{"Name": "3C4D", "ElementName": "db1", "EnabledState": int32(2), "OnTimeInMilliseconds": "7200000"},
+			},
+			"Win32_PerfFormattedData_HvStats_HyperVHypervisorVirtualProcessor": {
+				{"Name": "web1:Hv VP 0", "PercentTotalRunTime": "10"},
+				{"Name": "web1:Hv VP 1", "PercentTotalRunTime": "30"},
+				{"Name": "db1:Hv VP 0", "PercentTotalRunTime": "50"},
+				{"Name": "_Total", "PercentTotalRunTime": "30"},
+			},
+			"Win32_PerfFormattedData_BalancerStats_HyperVDynamicMemoryVM": {
+				{"Name": "web1", "CurrentPressure": int32(80), "AveragePressure": int32(75), "PhysicalMemory": "2048", "GuestVisiblePhysicalMemory": "4096"},
+			},
+			"Msvm_StorageAllocationSettingData": {
+				{"InstanceID": `Microsoft:1a2b\83F8638B-8DCA-4152-9EDA-2CA8B33039B4\0\0\L`, "HostResource": []interface{}{`C:\VMs\web1.vhdx`}},
+				{"InstanceID": `Microsoft:3C4D\83F8638B-8DCA-4152-9EDA-2CA8B33039B4\0\0\L`, "HostResource": []interface{}{`C:\VMs\db1.vhdx`}},
+			},
+			"Win32_PerfFormattedData_Counters_HyperVVirtualStorageDevice": {
+				{"Name": "C:-VMs-web1.vhdx", "ReadOperationsPerSec": int32(5), "WriteOperationsPerSec": int32(7), "ReadBytesPerSec": "1024", "WriteBytesPerSec": "2048", "Latency": int32(3)},
+				{"Name": "C:-VMs-db1.vhdx", "ReadOperationsPerSec": int32(1), "WriteOperationsPerSec": int32(1), "ReadBytesPerSec": "1", "WriteBytesPerSec": "1", "Latency": int32(1)},
+			},
+			"Win32_PerfFormattedData_NvspSwitchStats_HyperVVirtualSwitch": {
+				{"Name": "External", "BytesReceivedPersec": "100", "BytesSentPersec": "200", "PacketsReceivedPersec": int32(1), "PacketsSentPersec": int32(2)},
+			},
+		}),
+	}
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("hyperv_vm",
+			map[string]string{"vm_name": "web1", "vm_id": "1A2B"},
+			map[string]interface{}{
+				"state":                   uint64(2),
+				"uptime":                  uint64(3600),
+				"virtual_processors":      2,
+				"cpu_usage_percent":       float64(20),
+				"memory_pressure":         uint64(80),
+				"memory_average_pressure": uint64(75),
+				"memory_physical_mb":      uint64(2048),
+				"memory_guest_visible_mb": uint64(4096),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("hyperv_vm_disk",
+			map[string]string{"vm_name": "web1", "vm_id": "1A2B", "disk": `C:\VMs\web1.vhdx`},
+			map[string]interface{}{
+				"read_ops_per_sec":    uint64(5),
+				"write_ops_per_sec":   uint64(7),
+				"read_bytes_per_sec":  uint64(1024),
+				"write_bytes_per_sec": uint64(2048),
+				"latency_ms":          uint64(3),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("hyperv_vswitch",
+			map[string]string{"switch": "External"},
+			map[string]interface{}{
+				"bytes_received_per_sec":   uint64(100),
+				"bytes_sent_per_sec":       uint64(200),
+				"packets_received_per_sec": uint64(1),
+				"packets_sent_per_sec":     uint64(2),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherQueryError(t *testing.T) {
+	h := &HyperV{query: wmi.FakeQuery(map[string][]map[string]interface{}{
+		"Msvm_ComputerSystem": {{"Name": "1A2B", "ElementName": "web1", "EnabledState": int32(3)}},
+	})}
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Len(t, acc.Errors, 4)
+	require.True(t, acc.HasMeasurement("hyperv_vm"))
+}
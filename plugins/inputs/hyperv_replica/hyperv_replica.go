@@ -0,0 +1,205 @@
+//go:build windows
+// +build windows
+
+package hyperv_replica
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the virtual machines to collect, all if empty. Globs accepted.
+  # vm_names = []
+`
+
+// Namespaces of the queried classes.
+const (
+	virtualizationNamespace = `root\virtualization\v2`
+	cimv2Namespace          = `root\cimv2`
+)
+
+// Names of the replication modes, states, health states and types of the
+// virtual machines.
+var (
+	replicationModes = map[int64]string{
+		1: "primary",
+		2: "replica",
+		3: "test_replica",
+		4: "extended_replica",
+	}
+	replicationStates = map[int64]string{
+		0:  "disabled",
+		1:  "ready_for_initial_replication",
+		2:  "waiting_to_complete_initial_replication",
+		3:  "replicating",
+		4:  "synced_replication_complete",
+		5:  "recovered",
+		6:  "committed",
+		7:  "suspended",
+		8:  "critical",
+		9:  "waiting_to_start_resynchronization",
+		10: "resynchronizing",
+		11: "resynchronization_suspended",
+		12: "failover_in_progress",
+		13: "failback_in_progress",
+		14: "failback_complete",
+	}
+	replicationHealthStates = map[int64]string{
+		0: "not_applicable",
+		1: "ok",
+		2: "warning",
+		3: "critical",
+	}
+	replicationTypes = map[int64]string{
+		0: "none",
+		1: "regular",
+		2: "application_consistent",
+		3: "planned",
+	}
+)
+
+// HyperVReplica collects the replication health of the replicated virtual
+// machines of a Hyper-V host.
+type HyperVReplica struct {
+	VMNames []string `toml:"vm_names"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	query  wmi.QueryFunc
+	now    func() time.Time
+}
+
+// vm is a replicated virtual machine and its metrics.
+type vm struct {
+	fields map[string]interface{}
+	tags   map[string]string
+}
+
+func (h *HyperVReplica) Description() string {
+	return "Collect the replication health of Hyper-V Replica virtual machines"
+}
+
+func (h *HyperVReplica) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HyperVReplica) Init() error {
+	f, err := filter.Compile(h.VMNames)
+	if err != nil {
+		return fmt.Errorf("compiling vm_names failed: %w", err)
+	}
+	h.filter = f
+	return nil
+}
+
+func (h *HyperVReplica) Gather(acc telegraf.Accumulator) error {
+	vms, err := h.gatherVMs()
+	if err != nil {
+		return fmt.Errorf("querying virtual machines failed: %w", err)
+	}
+	if len(vms) == 0 {
+		return nil
+	}
+
+	if err := h.gatherStatistics(vms); err != nil {
+		acc.AddError(fmt.Errorf("querying replication statistics failed: %w", err))
+	}
+	for _, v := range vms {
+		acc.AddFields("hyperv_replica", v.fields, v.tags)
+	}
+	return nil
+}
+
+// gatherVMs returns the replicated virtual machines matching the filter by
+// name, virtual machines not enabled for replication are skipped.
+func (h *HyperVReplica) gatherVMs() (map[string]*vm, error) {
+	vms := make(map[string]*vm)
+	err := h.query(virtualizationNamespace,
+		"SELECT Name, ElementName, ReplicationMode, ReplicationState, ReplicationHealth, LastReplicationTime, LastReplicationType FROM Msvm_ComputerSystem WHERE Caption = 'Virtual Machine'",
+		func(p map[string]interface{}) error {
+			mode, err := strconv.ParseInt(fmt.Sprint(p["ReplicationMode"]), 10, 64)
+			if err != nil || mode == 0 {
+				return nil
+			}
+			name := fmt.Sprint(p["ElementName"])
+			if h.filter != nil && !h.filter.Match(name) {
+				return nil
+			}
+
+			tags := map[string]string{
+				"vm_name": name,
+				"vm_id":   fmt.Sprint(p["Name"]),
+				"mode":    lookup(replicationModes, mode),
+			}
+			fields := make(map[string]interface{})
+			addStateFields(fields, "state", replicationStates, p["ReplicationState"])
+			addStateFields(fields, "health", replicationHealthStates, p["ReplicationHealth"])
+			addStateFields(fields, "last_replication_type", replicationTypes, p["LastReplicationType"])
+			// The time is zero, i.e. '16010101000000.000000-000', if the
+			// virtual machine was never replicated.
+			if last, err := wmi.Time(p["LastReplicationTime"]); err == nil && last.Year() > 1601 {
+				fields["last_replication_age_seconds"] = int64(h.now().Sub(last).Seconds())
+			}
+			vms[name] = &vm{fields: fields, tags: tags}
+			return nil
+		})
+	return vms, err
+}
+
+// gatherStatistics adds the replication counters, named by the virtual
+// machine, to the virtual machines.
+func (h *HyperVReplica) gatherStatistics(vms map[string]*vm) error {
+	return h.query(cimv2Namespace,
+		"SELECT * FROM Win32_PerfFormattedData_Counters_HyperVReplicaVM",
+		func(p map[string]interface{}) error {
+			v, ok := vms[fmt.Sprint(p["Name"])]
+			if !ok {
+				return nil
+			}
+			wmi.AddUintFields(v.fields, p, map[string]string{
+				"AverageReplicationLatency": "average_latency_seconds",
+				"ReplicationLatency":        "latency_seconds",
+				"ReplicationCount":          "replications",
+				"AverageReplicationSize":    "average_size_bytes",
+				"LastReplicationSize":       "last_size_bytes",
+				"NetworkBytesSent":          "network_sent_bytes",
+				"NetworkBytesRecv":          "network_received_bytes",
+			})
+			return nil
+		})
+}
+
+// addStateFields adds the state and its code as fields with the prefix.
+func addStateFields(fields map[string]interface{}, prefix string, names map[int64]string, value interface{}) {
+	code, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil {
+		return
+	}
+	fields[prefix] = lookup(names, code)
+	fields[prefix+"_code"] = code
+}
+
+// lookup returns the name of the code, "unknown" if not found.
+func lookup(names map[int64]string, code int64) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func init() {
+	inputs.Add("hyperv_replica", func() telegraf.Input {
+		return &HyperVReplica{
+			query: (&wmi.Connection{}).Query,
+			now:   time.Now,
+		}
+	})
+}
@@ -0,0 +1,109 @@
+//go:build windows
+// +build windows
+
+package hyperv_replica
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	h := &HyperVReplica{
+		VMNames: []string{"web*", "db*"},
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Msvm_ComputerSystem": {
+				{
+					"Name": "1A2B", "ElementName": "web1", "ReplicationMode": int32(1), "ReplicationState": int32(3),
+					"ReplicationHealth": int32(1), "LastReplicationTime": "20211014115500.000000+000", "LastReplicationType": int32(1),
+				},
+				{
+					"Name": "3C4D", "ElementName": "db1", "ReplicationMode": int32(2), "ReplicationState": int32(1),
+					"ReplicationHealth": int32(3), "LastReplicationTime": "16010101000000.000000-000", "LastReplicationType": int32(0),
+				},
+				{"Name": "5E6F", "ElementName": "web2", "ReplicationMode": int32(0)},
+				{"Name": "7A8B", "ElementName": "mail1", "ReplicationMode": int32(1), "ReplicationState": int32(3)},
+			},
+			"Win32_PerfFormattedData_Counters_HyperVReplicaVM": {
+				{
+					"Name": "web1", "AverageReplicationLatency": int32(12), "ReplicationLatency": int32(10), "ReplicationCount": int32(40),
+					"AverageReplicationSize": "1048576", "LastReplicationSize": "524288", "NetworkBytesSent": "41943040", "NetworkBytesRecv": "4096",
+				},
+				{"Name": "mail1", "AverageReplicationLatency": int32(5)},
+			},
+		}),
+		now: func() time.Time { return time.Date(2021, 10, 14, 12, 0, 0, 0, time.UTC) },
+	}
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("hyperv_replica",
+			map[string]string{"vm_name": "web1", "vm_id": "1A2B", "mode": "primary"},
+			map[string]interface{}{
+				"state":                        "replicating",
+				"state_code":                   int64(3),
+				"health":                       "ok",
+				"health_code":                  int64(1),
+				"last_replication_type":        "regular",
+				"last_replication_type_code":   int64(1),
+				"last_replication_age_seconds": int64(300),
+				"average_latency_seconds":      uint64(12),
+				"latency_seconds":              uint64(10),
+				"replications":                 uint64(40),
+				"average_size_bytes":           uint64(1048576),
+				"last_size_bytes":              uint64(524288),
+				"network_sent_bytes":           uint64(41943040),
+				"network_received_bytes":       uint64(4096),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("hyperv_replica",
+			map[string]string{"vm_name": "db1", "vm_id": "3C4D", "mode": "replica"},
+			map[string]interface{}{
+				"state":                      "ready_for_initial_replication",
+				"state_code":                 int64(1),
+				"health":                     "critical",
+				"health_code":                int64(3),
+				"last_replication_type":      "none",
+				"last_replication_type_code": int64(0),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherNoReplicatedVMs(t *testing.T) {
+	h := &HyperVReplica{query: wmi.FakeQuery(map[string][]map[string]interface{}{
+		"Msvm_ComputerSystem": {{"Name": "1A2B", "ElementName": "web1", "ReplicationMode": int32(0)}},
+	})}
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestGatherStatisticsError(t *testing.T) {
+	h := &HyperVReplica{
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Msvm_ComputerSystem": {{"Name": "1A2B", "ElementName": "web1", "ReplicationMode": int32(1), "ReplicationState": int32(7)}},
+		}),
+		now: time.Now,
+	}
+	require.NoError(t, h.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.True(t, acc.HasMeasurement("hyperv_replica"))
+}
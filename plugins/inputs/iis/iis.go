@@ -0,0 +1,179 @@
+//go:build windows
+// +build windows
+
+package iis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the sites to collect, all if empty. Globs accepted.
+  # site_names = []
+
+  ## Names of the application pools to collect, all if empty. Globs accepted.
+  # app_pool_names = []
+`
+
+const namespace = `root\cimv2`
+
+// IIS collects metrics of the sites and application pools of the Internet
+// Information Services.
+type IIS struct {
+	SiteNames    []string `toml:"site_names"`
+	AppPoolNames []string `toml:"app_pool_names"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	siteFilter    filter.Filter
+	appPoolFilter filter.Filter
+	query         wmi.QueryFunc
+}
+
+func (i *IIS) Description() string {
+	return "Collect metrics of the sites and application pools of IIS"
+}
+
+func (i *IIS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (i *IIS) Init() error {
+	var err error
+	if i.siteFilter, err = filter.Compile(i.SiteNames); err != nil {
+		return fmt.Errorf("compiling site_names failed: %w", err)
+	}
+	if i.appPoolFilter, err = filter.Compile(i.AppPoolNames); err != nil {
+		return fmt.Errorf("compiling app_pool_names failed: %w", err)
+	}
+	return nil
+}
+
+func (i *IIS) Gather(acc telegraf.Accumulator) error {
+	if err := i.gatherSites(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying sites failed: %w", err))
+	}
+	if err := i.gatherAppPools(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying application pools failed: %w", err))
+	}
+	return nil
+}
+
+func (i *IIS) gatherSites(acc telegraf.Accumulator) error {
+	return i.query(namespace,
+		"SELECT Name, TotalMethodRequestsPerSec, BytesReceivedPersec, BytesSentPersec, CurrentConnections, NotFoundErrorsPerSec, ServiceUptime FROM Win32_PerfFormattedData_W3SVC_WebService",
+		func(p map[string]interface{}) error {
+			site := fmt.Sprint(p["Name"])
+			if site == "_Total" || (i.siteFilter != nil && !i.siteFilter.Match(site)) {
+				return nil
+			}
+
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"TotalMethodRequestsPerSec": "requests_per_sec",
+				"BytesReceivedPersec":       "bytes_received_per_sec",
+				"BytesSentPersec":           "bytes_sent_per_sec",
+				"CurrentConnections":        "current_connections",
+				"NotFoundErrorsPerSec":      "not_found_errors_per_sec",
+				"ServiceUptime":             "uptime",
+			})
+			acc.AddFields("iis_site", fields, map[string]string{"site": site})
+			return nil
+		})
+}
+
+// workerProcess is the resource usage of the worker processes of a pool.
+type workerProcess struct {
+	cpu          float64
+	privateBytes uint64
+	workingSet   uint64
+}
+
+func (i *IIS) gatherAppPools(acc telegraf.Accumulator) error {
+	workers, err := i.gatherWorkerProcesses()
+	if err != nil {
+		acc.AddError(fmt.Errorf("querying worker processes failed: %w", err))
+	}
+
+	return i.query(namespace,
+		"SELECT Name, CurrentApplicationPoolState, CurrentApplicationPoolUptime, CurrentWorkerProcesses, TotalApplicationPoolRecycles, TotalWorkerProcessFailures FROM Win32_PerfFormattedData_APPPOOLCountersProvider_APPPOOLWAS",
+		func(p map[string]interface{}) error {
+			pool := fmt.Sprint(p["Name"])
+			if pool == "_Total" || (i.appPoolFilter != nil && !i.appPoolFilter.Match(pool)) {
+				return nil
+			}
+
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"CurrentApplicationPoolState":  "state",
+				"CurrentApplicationPoolUptime": "uptime",
+				"CurrentWorkerProcesses":       "worker_processes",
+				"TotalApplicationPoolRecycles": "recycles",
+				"TotalWorkerProcessFailures":   "worker_process_failures",
+			})
+			if w, ok := workers[pool]; ok {
+				fields["cpu_usage_percent"] = w.cpu
+				fields["memory_private_bytes"] = w.privateBytes
+				fields["memory_working_set_bytes"] = w.workingSet
+			}
+			acc.AddFields("iis_app_pool", fields, map[string]string{"app_pool": pool})
+			return nil
+		})
+}
+
+// gatherWorkerProcesses returns the resource usage of the worker processes
+// by application pool. The worker process instances are named
+// '<pid>_<pool>'.
+func (i *IIS) gatherWorkerProcesses() (map[string]*workerProcess, error) {
+	pools := make(map[string]string)
+	err := i.query(namespace,
+		"SELECT Name FROM Win32_PerfFormattedData_W3SVCW3WPCounterProvider_W3SVCW3WP",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["Name"])
+			if n := strings.Index(name, "_"); n > 0 {
+				pools[name[:n]] = name[n+1:]
+			}
+			return nil
+		})
+	if err != nil || len(pools) == 0 {
+		return nil, err
+	}
+
+	workers := make(map[string]*workerProcess)
+	err = i.query(namespace,
+		"SELECT IDProcess, PercentProcessorTime, PrivateBytes, WorkingSet FROM Win32_PerfFormattedData_PerfProc_Process WHERE Name LIKE 'w3wp%'",
+		func(p map[string]interface{}) error {
+			pool, ok := pools[fmt.Sprint(p["IDProcess"])]
+			if !ok {
+				return nil
+			}
+			w, ok := workers[pool]
+			if !ok {
+				w = &workerProcess{}
+				workers[pool] = w
+			}
+			if v, err := wmi.Float64(p["PercentProcessorTime"]); err == nil {
+				w.cpu += v
+			}
+			if v, err := wmi.Uint64(p["PrivateBytes"]); err == nil {
+				w.privateBytes += v
+			}
+			if v, err := wmi.Uint64(p["WorkingSet"]); err == nil {
+				w.workingSet += v
+			}
+			return nil
+		})
+	return workers, err
+}
+
+func init() {
+	inputs.Add("iis", func() telegraf.Input {
+		return &IIS{query: (&wmi.Connection{}).Query}
+	})
+}
@@ -0,0 +1,76 @@
+//go:build windows
+// +build windows
+
+package iis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	i := &IIS{
+		AppPoolNames: []string{"Default*"},
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Win32_PerfFormattedData_W3SVC_WebService": {
+				{"Name": "_Total", "TotalMethodRequestsPerSec": int32(30)},
+				{"Name": "Default Web Site", "TotalMethodRequestsPerSec": int32(30), "BytesReceivedPersec": "1024", "BytesSentPersec": "4096",
+					"CurrentConnections": int32(5), "NotFoundErrorsPerSec": int32(1), "ServiceUptime": "3600"},
+			},
+			"Win32_PerfFormattedData_APPPOOLCountersProvider_APPPOOLWAS": {
+				{"Name": "_Total", "CurrentApplicationPoolState": int32(3)},
+				{"Name": "DefaultAppPool", "CurrentApplicationPoolState": int32(3), "CurrentApplicationPoolUptime": "3600",
+					"CurrentWorkerProcesses": int32(2), "TotalApplicationPoolRecycles": int32(1), "TotalWorkerProcessFailures": int32(0)},
+				{"Name": "Other", "CurrentApplicationPoolState": int32(3)},
+			},
+			"Win32_PerfFormattedData_W3SVCW3WPCounterProvider_W3SVCW3WP": {
+				{"Name": "_Total"},
+				{"Name": "1234_DefaultAppPool"},
+				{"Name": "5678_DefaultAppPool"},
+			},
+			"Win32_PerfFormattedData_PerfProc_Process": {
+				{"IDProcess": int32(1234), "PercentProcessorTime": "10", "PrivateBytes": "1000", "WorkingSet": "2000"},
+				{"IDProcess": int32(5678), "PercentProcessorTime": "5", "PrivateBytes": "3000", "WorkingSet": "4000"},
+			},
+		}),
+	}
+	require.NoError(t, i.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, i.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("iis_site",
+			map[string]string{"site": "Default Web Site"},
+			map[string]interface{}{
+				"requests_per_sec":         uint64(30),
+				"bytes_received_per_sec":   uint64(1024),
+				"bytes_sent_per_sec":       uint64(4096),
+				"current_connections":      uint64(5),
+				"not_found_errors_per_sec": uint64(1),
+				"uptime":                   uint64(3600),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("iis_app_pool",
+			map[string]string{"app_pool": "DefaultAppPool"},
+			map[string]interface{}{
+				"state":                    uint64(3),
+				"uptime":                   uint64(3600),
+				"worker_processes":         uint64(2),
+				"recycles":                 uint64(1),
+				"worker_process_failures":  uint64(0),
+				"cpu_usage_percent":        float64(15),
+				"memory_private_bytes":     uint64(4000),
+				"memory_working_set_bytes": uint64(6000),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
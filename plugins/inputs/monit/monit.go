@@ -8,6 +8,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"golang.org/x/net/html/charset"
@@ -222,7 +223,7 @@ func (m *Monit) Init() error {
 	m.client = http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: tlsCfg,
-			Proxy:           http.ProxyFromEnvironment,
+			Proxy:           internal.Proxy,
 		},
 		Timeout: time.Duration(m.Timeout),
 	}
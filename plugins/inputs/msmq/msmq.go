@@ -0,0 +1,218 @@
+//go:build windows
+// +build windows
+
+package msmq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the queues to collect, all if empty. Globs accepted. The names
+  ## are the path names without the host, e.g. 'private$\orders' for private
+  ## and 'orders' for public queues, and are case-insensitive.
+  # queue_names = []
+
+  ## Peek the first message of the queues for the age of the oldest message.
+  ## Requires the permission to peek messages of the queues.
+  # oldest_message_age = true
+`
+
+const namespace = `root\cimv2`
+
+// Access and share modes of MSMQQueueInfo.Open.
+const (
+	mqPeekAccess = 32
+	mqDenyNone   = 0
+)
+
+// peekFunc returns the arrival time of the first message of the queue with
+// the path name, false if the queue is empty.
+type peekFunc func(pathName string) (time.Time, bool, error)
+
+// MSMQ collects the depth of the Message Queuing queues.
+type MSMQ struct {
+	QueueNames       []string `toml:"queue_names"`
+	OldestMessageAge bool     `toml:"oldest_message_age"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	query  wmi.QueryFunc
+	peek   peekFunc
+	now    func() time.Time
+}
+
+func (m *MSMQ) Description() string {
+	return "Collect the depth of the Message Queuing queues"
+}
+
+func (m *MSMQ) SampleConfig() string {
+	return sampleConfig
+}
+
+func (m *MSMQ) Init() error {
+	// Backslashes are escape characters in globs, so the names are matched
+	// with slashes as separators.
+	names := make([]string, 0, len(m.QueueNames))
+	for _, name := range m.QueueNames {
+		names = append(names, strings.ReplaceAll(strings.ToLower(name), `\`, "/"))
+	}
+	f, err := filter.Compile(names)
+	if err != nil {
+		return fmt.Errorf("compiling queue_names failed: %w", err)
+	}
+	m.filter = f
+	return nil
+}
+
+func (m *MSMQ) Gather(acc telegraf.Accumulator) error {
+	type queue struct {
+		name   string
+		fields map[string]interface{}
+	}
+	var queues []queue
+	err := m.query(namespace,
+		"SELECT Name, MessagesinQueue, BytesinQueue, MessagesinJournalQueue, BytesinJournalQueue FROM Win32_PerfRawData_MSMQ_MSMQQueue",
+		func(p map[string]interface{}) error {
+			// The instances are named '<host>\<queue>', the totals of the
+			// host 'Computer Queues'.
+			name := strings.ToLower(fmt.Sprint(p["Name"]))
+			i := strings.Index(name, `\`)
+			if i < 0 {
+				return nil
+			}
+			name = name[i+1:]
+			if m.filter != nil && !m.filter.Match(strings.ReplaceAll(name, `\`, "/")) {
+				return nil
+			}
+
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"MessagesinQueue":        "messages",
+				"BytesinQueue":           "bytes",
+				"MessagesinJournalQueue": "journal_messages",
+				"BytesinJournalQueue":    "journal_bytes",
+			})
+			queues = append(queues, queue{name: name, fields: fields})
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("querying queues failed: %w", err)
+	}
+
+	for _, q := range queues {
+		if m.OldestMessageAge {
+			// The local queue is addressed by the path name with '.' as
+			// host.
+			arrived, ok, err := m.peek(`.\` + q.name)
+			switch {
+			case err != nil:
+				acc.AddError(fmt.Errorf("peeking queue %q failed: %w", q.name, err))
+			case ok:
+				q.fields["oldest_message_age"] = int64(m.now().Sub(arrived).Seconds())
+			default:
+				q.fields["oldest_message_age"] = int64(0)
+			}
+		}
+
+		typ := "public"
+		if strings.HasPrefix(q.name, `private$\`) {
+			typ = "private"
+		}
+		acc.AddFields("msmq_queue", q.fields, map[string]string{"queue": q.name, "type": typ})
+	}
+	return nil
+}
+
+// peekQueue returns the arrival time of the first message of the queue using
+// the MSMQ COM API.
+func peekQueue(pathName string) (arrived time.Time, found bool, err error) {
+	err = wmi.WithCOM(func() error {
+		arrived, found, err = peekQueueCOM(pathName)
+		return err
+	})
+	return arrived, found, err
+}
+
+func peekQueueCOM(pathName string) (time.Time, bool, error) {
+	info, err := createObject("MSMQ.MSMQQueueInfo")
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer info.Release()
+
+	if _, err := oleutil.PutProperty(info, "PathName", pathName); err != nil {
+		return time.Time{}, false, err
+	}
+	queueRaw, err := oleutil.CallMethod(info, "Open", mqPeekAccess, mqDenyNone)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("opening queue failed: %w", err)
+	}
+	defer queueRaw.Clear() //nolint:errcheck // nothing to do on error
+	queue := queueRaw.ToIDispatch()
+	defer oleutil.CallMethod(queue, "Close") //nolint:errcheck // nothing to do on error
+
+	// Peek(WantDestinationQueue, WantBody, ReceiveTimeout) returns Nothing
+	// if the queue is empty.
+	messageRaw, err := oleutil.CallMethod(queue, "Peek", false, false, 0)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer messageRaw.Clear() //nolint:errcheck // nothing to do on error
+	if messageRaw.VT != ole.VT_DISPATCH || messageRaw.Val == 0 {
+		return time.Time{}, false, nil
+	}
+
+	arrivedRaw, err := oleutil.GetProperty(messageRaw.ToIDispatch(), "ArrivedTime")
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer arrivedRaw.Clear() //nolint:errcheck // nothing to do on error
+	arrived, ok := arrivedRaw.Value().(time.Time)
+	if !ok {
+		return time.Time{}, false, errors.New("invalid arrival time")
+	}
+
+	// The arrival time is in local time, but converted as UTC.
+	arrived = time.Date(arrived.Year(), arrived.Month(), arrived.Day(),
+		arrived.Hour(), arrived.Minute(), arrived.Second(), arrived.Nanosecond(), time.Local)
+	return arrived, true, nil
+}
+
+// createObject creates the COM object with the given program ID.
+func createObject(programID string) (*ole.IDispatch, error) {
+	unknown, err := oleutil.CreateObject(programID)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s failed: %w", programID, err)
+	}
+	defer unknown.Release()
+
+	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s failed: %w", programID, err)
+	}
+	return disp, nil
+}
+
+func init() {
+	inputs.Add("msmq", func() telegraf.Input {
+		return &MSMQ{
+			OldestMessageAge: true,
+			query:            (&wmi.Connection{}).Query,
+			peek:             peekQueue,
+			now:              time.Now,
+		}
+	})
+}
@@ -0,0 +1,176 @@
+//go:build windows
+// +build windows
+
+package msmq
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeQuery returns the objects for every query.
+func fakeQuery(objects []map[string]interface{}) wmi.QueryFunc {
+	return func(_, _ string, fn func(properties map[string]interface{}) error) error {
+		for _, o := range objects {
+			if err := fn(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+var queues = []map[string]interface{}{
+	{
+		"Name":                   "Computer Queues",
+		"MessagesinQueue":        "1510",
+		"BytesinQueue":           "1548288",
+		"MessagesinJournalQueue": "0",
+		"BytesinJournalQueue":    "0",
+	},
+	{
+		"Name":                   `WIN01\private$\Orders`,
+		"MessagesinQueue":        "1500",
+		"BytesinQueue":           "1536000",
+		"MessagesinJournalQueue": "0",
+		"BytesinJournalQueue":    "0",
+	},
+	{
+		"Name":                   `win01\invoices`,
+		"MessagesinQueue":        "10",
+		"BytesinQueue":           "12288",
+		"MessagesinJournalQueue": "25",
+		"BytesinJournalQueue":    "30720",
+	},
+	{
+		"Name":                   `win01\private$\empty`,
+		"MessagesinQueue":        "0",
+		"BytesinQueue":           "0",
+		"MessagesinJournalQueue": "0",
+		"BytesinJournalQueue":    "0",
+	},
+}
+
+func TestGather(t *testing.T) {
+	now := time.Date(2021, 10, 14, 12, 0, 0, 0, time.UTC)
+	var peeked []string
+	m := &MSMQ{
+		OldestMessageAge: true,
+		Log:              testutil.Logger{},
+		query:            fakeQuery(queues),
+		peek: func(pathName string) (time.Time, bool, error) {
+			peeked = append(peeked, pathName)
+			switch pathName {
+			case `.\private$\orders`:
+				return now.Add(-90 * time.Second), true, nil
+			case `.\invoices`:
+				return now.Add(-5 * time.Second), true, nil
+			}
+			return time.Time{}, false, nil
+		},
+		now: func() time.Time { return now },
+	}
+	require.NoError(t, m.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, []string{`.\private$\orders`, `.\invoices`, `.\private$\empty`}, peeked)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"msmq_queue",
+			map[string]string{"queue": `private$\orders`, "type": "private"},
+			map[string]interface{}{
+				"messages":           uint64(1500),
+				"bytes":              uint64(1536000),
+				"journal_messages":   uint64(0),
+				"journal_bytes":      uint64(0),
+				"oldest_message_age": int64(90),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"msmq_queue",
+			map[string]string{"queue": "invoices", "type": "public"},
+			map[string]interface{}{
+				"messages":           uint64(10),
+				"bytes":              uint64(12288),
+				"journal_messages":   uint64(25),
+				"journal_bytes":      uint64(30720),
+				"oldest_message_age": int64(5),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"msmq_queue",
+			map[string]string{"queue": `private$\empty`, "type": "private"},
+			map[string]interface{}{
+				"messages":           uint64(0),
+				"bytes":              uint64(0),
+				"journal_messages":   uint64(0),
+				"journal_bytes":      uint64(0),
+				"oldest_message_age": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherQueueNames(t *testing.T) {
+	m := &MSMQ{
+		QueueNames: []string{`Private$\Ord*`},
+		Log:        testutil.Logger{},
+		query:      fakeQuery(queues),
+	}
+	require.NoError(t, m.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"msmq_queue",
+			map[string]string{"queue": `private$\orders`, "type": "private"},
+			map[string]interface{}{
+				"messages":         uint64(1500),
+				"bytes":            uint64(1536000),
+				"journal_messages": uint64(0),
+				"journal_bytes":    uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherPeekError(t *testing.T) {
+	m := &MSMQ{
+		OldestMessageAge: true,
+		QueueNames:       []string{"invoices"},
+		Log:              testutil.Logger{},
+		query:            fakeQuery(queues),
+		peek: func(string) (time.Time, bool, error) {
+			return time.Time{}, false, errors.New("access denied")
+		},
+		now: time.Now,
+	}
+	require.NoError(t, m.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, m.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+
+	// The depth is added without the age of the oldest message
+	require.Len(t, acc.Metrics, 1)
+	require.False(t, acc.HasField("msmq_queue", "oldest_message_age"))
+	require.True(t, acc.HasUIntField("msmq_queue", "messages"))
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	gnatsd "github.com/nats-io/nats-server/v2/server"
 )
@@ -92,7 +93,7 @@ func (n *Nats) Gather(acc telegraf.Accumulator) error {
 
 func (n *Nats) createHTTPClient() *http.Client {
 	transport := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: internal.Proxy,
 	}
 	timeout := time.Duration(n.ResponseTimeout)
 	if timeout == time.Duration(0) {
@@ -2,6 +2,9 @@ package procstat
 
 import (
 	"regexp"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
 // Pattern matches on the process name
@@ -11,20 +14,23 @@ func (pg *NativeFinder) Pattern(pattern string) ([]PID, error) {
 	if err != nil {
 		return pids, err
 	}
-	procs, err := pg.FastProcessList()
+
+	// A single snapshot of all processes is much cheaper than opening every
+	// process for its name and includes protected processes.
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
 	if err != nil {
 		return pids, err
 	}
-	for _, p := range procs {
-		name, err := p.Name()
-		if err != nil {
-			//skip, this can be caused by the pid no longer existing
-			//or you having no permissions to access it
-			continue
-		}
-		if regxPattern.MatchString(name) {
-			pids = append(pids, PID(p.Pid))
+	defer windows.CloseHandle(snapshot) //nolint:errcheck // nothing to do on error
+
+	entry := windows.ProcessEntry32{Size: uint32(unsafe.Sizeof(windows.ProcessEntry32{}))}
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if regxPattern.MatchString(windows.UTF16ToString(entry.ExeFile[:])) {
+			pids = append(pids, PID(entry.ProcessID))
 		}
 	}
-	return pids, err
+	if err != windows.ERROR_NO_MORE_FILES {
+		return pids, err
+	}
+	return pids, nil
 }
@@ -27,6 +27,18 @@ type Process interface {
 	Username() (string, error)
 	CreateTime() (int64, error)
 	Ppid() (int32, error)
+	WinResources() (*WinResourcesStat, error)
+}
+
+// WinResourcesStat are the resources of a Windows process not reported by
+// gopsutil.
+type WinResourcesStat struct {
+	Handles     uint64
+	GDIObjects  uint64
+	USERObjects uint64
+	PageFaults  uint64
+	OtherCount  uint64
+	OtherBytes  uint64
 }
 
 type PIDFinder interface {
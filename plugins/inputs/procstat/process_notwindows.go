@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package procstat
+
+import (
+	"fmt"
+)
+
+func (p *Proc) WinResources() (*WinResourcesStat, error) {
+	return nil, fmt.Errorf("os not support windows resources")
+}
@@ -63,7 +63,7 @@ var sampleConfig = `
   ## CGroup name or path, supports globs
   # cgroup = "systemd/system.slice/nginx.service"
 
-  ## Windows service name
+  ## Windows service name, supports globs matching all running services
   # win_service = ""
 
   ## override for process_name
@@ -333,6 +333,16 @@ func (p *Procstat) addMetric(proc Process, acc telegraf.Accumulator, t time.Time
 		fields[prefix+"ppid"] = ppid
 	}
 
+	winRes, err := proc.WinResources()
+	if err == nil {
+		fields[prefix+"handle_count"] = winRes.Handles
+		fields[prefix+"gdi_objects"] = winRes.GDIObjects
+		fields[prefix+"user_objects"] = winRes.USERObjects
+		fields[prefix+"page_faults"] = winRes.PageFaults
+		fields[prefix+"other_count"] = winRes.OtherCount
+		fields[prefix+"other_bytes"] = winRes.OtherBytes
+	}
+
 	acc.AddFields("procstat", fields, proc.Tags(), t)
 }
 
@@ -397,6 +407,9 @@ func (p *Procstat) findPids() []PidsTags {
 	} else if p.CGroup != "" {
 		groups := p.cgroupPIDs()
 		return groups
+	} else if p.WinService != "" && strings.ContainsAny(p.WinService, "*?[") {
+		groups := p.winServicePatternPIDs()
+		return groups
 	} else {
 		f, err := p.getPIDFinder()
 		if err != nil {
@@ -441,6 +454,9 @@ func (p *Procstat) SimpleFindPids(f PIDFinder) ([]PID, map[string]string, error)
 // execCommand is so tests can mock out exec.Command usage.
 var execCommand = exec.Command
 
+// queryWinServicePattern is so tests can mock out the service control manager.
+var queryWinServicePattern = queryPidsWithWinServicePattern
+
 func (p *Procstat) systemdUnitPIDs() []PidsTags {
 	if p.IncludeSystemdChildren {
 		p.CGroup = fmt.Sprintf("systemd/system.slice/%s", p.SystemdUnit)
@@ -555,6 +571,22 @@ func (p *Procstat) winServicePIDs() ([]PID, error) {
 	return pids, nil
 }
 
+func (p *Procstat) winServicePatternPIDs() []PidsTags {
+	var pidTags []PidsTags
+
+	services, err := queryWinServicePattern(p.WinService)
+	if err != nil {
+		pidTags = append(pidTags, PidsTags{nil, nil, err})
+		return pidTags
+	}
+	for name, pid := range services {
+		tags := map[string]string{"win_service": name}
+		pidTags = append(pidTags, PidsTags{[]PID{PID(pid)}, tags, nil})
+	}
+
+	return pidTags
+}
+
 func (p *Procstat) Init() error {
 	if strings.ToLower(p.Mode) == "solaris" {
 		p.solarisMode = true
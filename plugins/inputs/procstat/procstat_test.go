@@ -169,6 +169,10 @@ func (p *testProc) Ppid() (int32, error) {
 	return 0, nil
 }
 
+func (p *testProc) WinResources() (*WinResourcesStat, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
 var pid = PID(42)
 var exe = "foo"
 
@@ -379,6 +383,24 @@ func TestGather_systemdUnitPIDs(t *testing.T) {
 	}
 }
 
+func TestGather_winServicePatternPIDs(t *testing.T) {
+	queryWinServicePattern = func(pattern string) (map[string]uint32, error) {
+		require.Equal(t, "sql*", pattern)
+		return map[string]uint32{"sqlserver": 1234}, nil
+	}
+	defer func() { queryWinServicePattern = queryPidsWithWinServicePattern }()
+
+	p := Procstat{
+		createPIDFinder: pidFinder([]PID{}),
+		WinService:      "sql*",
+	}
+	pidsTags := p.findPids()
+	require.Len(t, pidsTags, 1)
+	require.NoError(t, pidsTags[0].Err)
+	assert.Equal(t, []PID{1234}, pidsTags[0].PIDS)
+	assert.Equal(t, "sqlserver", pidsTags[0].Tags["win_service"])
+}
+
 func TestGather_cgroupPIDs(t *testing.T) {
 	//no cgroups in windows
 	if runtime.GOOS == "windows" {
@@ -432,3 +454,30 @@ func TestGather_SameTimestamps(t *testing.T) {
 
 	require.Equal(t, procstat.Time, procstatLookup.Time)
 }
+
+type testWinProc struct {
+	*testProc
+}
+
+func (p *testWinProc) WinResources() (*WinResourcesStat, error) {
+	return &WinResourcesStat{Handles: 120, GDIObjects: 4, USERObjects: 2, PageFaults: 1000}, nil
+}
+
+func TestGather_WinResources(t *testing.T) {
+	var acc testutil.Accumulator
+
+	p := Procstat{
+		Exe:             exe,
+		createPIDFinder: pidFinder([]PID{pid}),
+		createProcess: func(pid PID) (Process, error) {
+			proc, err := newTestProc(pid)
+			return &testWinProc{proc.(*testProc)}, err
+		},
+	}
+	require.NoError(t, acc.GatherError(p.Gather))
+
+	assert.True(t, acc.HasUIntField("procstat", "handle_count"))
+	assert.True(t, acc.HasUIntField("procstat", "gdi_objects"))
+	assert.True(t, acc.HasUIntField("procstat", "user_objects"))
+	assert.True(t, acc.HasUIntField("procstat", "page_faults"))
+}
@@ -10,3 +10,7 @@ import (
 func queryPidWithWinServiceName(_ string) (uint32, error) {
 	return 0, fmt.Errorf("os not support win_service option")
 }
+
+func queryPidsWithWinServicePattern(_ string) (map[string]uint32, error) {
+	return nil, fmt.Errorf("os not support win_service option")
+}
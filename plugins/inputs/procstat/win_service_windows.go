@@ -4,10 +4,13 @@
 package procstat
 
 import (
+	"strings"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/influxdata/telegraf/filter"
 )
 
 func getService(name string) (*mgr.Service, error) {
@@ -47,3 +50,43 @@ func queryPidWithWinServiceName(winServiceName string) (uint32, error) {
 
 	return p.ProcessId, nil
 }
+
+// queryPidsWithWinServicePattern returns the process IDs of the running
+// services matching the glob pattern by service name. Service names are
+// case-insensitive.
+func queryPidsWithWinServicePattern(pattern string) (map[string]uint32, error) {
+	f, err := filter.Compile([]string{strings.ToLower(pattern)})
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer m.Disconnect()
+
+	names, err := m.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	pids := make(map[string]uint32)
+	for _, name := range names {
+		if !f.Match(strings.ToLower(name)) {
+			continue
+		}
+		srv, err := m.OpenService(name)
+		if err != nil {
+			// The service may have been deleted after listing
+			continue
+		}
+		status, err := srv.Query()
+		srv.Close()
+		if err != nil || status.ProcessId == 0 {
+			continue
+		}
+		pids[name] = status.ProcessId
+	}
+	return pids, nil
+}
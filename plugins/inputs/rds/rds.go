@@ -0,0 +1,173 @@
+//go:build windows
+// +build windows
+
+package rds
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"golang.org/x/sys/windows"
+)
+
+const sampleConfig = `
+  ## Collect the RemoteFX network counters of the sessions, e.g. the round
+  ## trip time and bandwidth of the TCP and UDP transports.
+  # remotefx = true
+`
+
+const namespace = `root\cimv2`
+
+// Names of the session states.
+var sessionStates = map[uint32]string{
+	windows.WTSActive:       "active",
+	windows.WTSConnected:    "connected",
+	windows.WTSConnectQuery: "connect_query",
+	windows.WTSShadow:       "shadow",
+	windows.WTSDisconnected: "disconnected",
+	windows.WTSIdle:         "idle",
+	windows.WTSListen:       "listen",
+	windows.WTSReset:        "reset",
+	windows.WTSDown:         "down",
+	windows.WTSInit:         "init",
+}
+
+// session is a Remote Desktop Services session.
+type session struct {
+	id        uint32
+	station   string
+	state     uint32
+	user      string
+	domain    string
+	lastInput time.Time
+	logon     time.Time
+	current   time.Time
+}
+
+// RDS collects the sessions of the Remote Desktop Services.
+type RDS struct {
+	RemoteFX bool `toml:"remotefx"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	sessions func() ([]session, error)
+	query    wmi.QueryFunc
+}
+
+func (r *RDS) Description() string {
+	return "Collect the sessions of the Remote Desktop Services"
+}
+
+func (r *RDS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *RDS) Gather(acc telegraf.Accumulator) error {
+	sessions, err := r.sessions()
+	if err != nil {
+		return fmt.Errorf("enumerating sessions failed: %w", err)
+	}
+
+	var network map[string]map[string]interface{}
+	if r.RemoteFX {
+		if network, err = r.remoteFXNetwork(); err != nil {
+			acc.AddError(fmt.Errorf("querying RemoteFX counters failed: %w", err))
+		}
+	}
+
+	var active, disconnected, total uint64
+	for _, s := range sessions {
+		// The services session and the listeners of the protocols are not
+		// user sessions.
+		if s.id == 0 || s.state == windows.WTSListen {
+			continue
+		}
+		total++
+		switch s.state {
+		case windows.WTSActive:
+			active++
+		case windows.WTSDisconnected:
+			disconnected++
+		}
+		if s.user == "" {
+			continue
+		}
+
+		state, ok := sessionStates[s.state]
+		if !ok {
+			state = "unknown"
+		}
+		tags := map[string]string{
+			"session_id": strconv.FormatUint(uint64(s.id), 10),
+			"user":       s.user,
+			"state":      state,
+		}
+		if s.station != "" {
+			tags["station"] = s.station
+		}
+		if s.domain != "" {
+			tags["domain"] = s.domain
+		}
+		fields := make(map[string]interface{})
+		if !s.lastInput.IsZero() && !s.current.IsZero() {
+			fields["idle_seconds"] = int64(s.current.Sub(s.lastInput).Seconds())
+		}
+		if !s.logon.IsZero() && !s.current.IsZero() {
+			fields["logon_seconds"] = int64(s.current.Sub(s.logon).Seconds())
+		}
+		// The counter instances are named by the window station with '#'
+		// replaced by a space.
+		for k, v := range network[strings.ReplaceAll(s.station, "#", " ")] {
+			fields[k] = v
+		}
+		if len(fields) > 0 {
+			acc.AddFields("rds_session", fields, tags)
+		}
+	}
+
+	fields := map[string]interface{}{
+		"sessions":              total,
+		"sessions_active":       active,
+		"sessions_disconnected": disconnected,
+	}
+	acc.AddFields("rds", fields, nil)
+	return nil
+}
+
+// remoteFXNetwork returns the RemoteFX network fields of the sessions by
+// counter instance.
+func (r *RDS) remoteFXNetwork() (map[string]map[string]interface{}, error) {
+	network := make(map[string]map[string]interface{})
+	// The counters differ between the versions of Windows, so all properties
+	// are selected and missing ones skipped.
+	err := r.query(namespace, "SELECT * FROM Win32_PerfFormattedData_Counters_RemoteFXNetwork",
+		func(p map[string]interface{}) error {
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"CurrentTCPRTT":       "tcp_rtt_ms",
+				"CurrentUDPRTT":       "udp_rtt_ms",
+				"CurrentTCPBandwidth": "tcp_bandwidth_kbps",
+				"CurrentUDPBandwidth": "udp_bandwidth_kbps",
+				"LossRate":            "loss_rate_percent",
+				"RetransmissionRate":  "retransmission_rate_percent",
+			})
+			network[fmt.Sprint(p["Name"])] = fields
+			return nil
+		})
+	return network, err
+}
+
+func init() {
+	inputs.Add("rds", func() telegraf.Input {
+		return &RDS{
+			RemoteFX: true,
+			sessions: wtsSessions,
+			query:    (&wmi.Connection{}).Query,
+		}
+	})
+}
@@ -0,0 +1,112 @@
+//go:build windows
+// +build windows
+
+package rds
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	now := time.Date(2021, 10, 14, 12, 0, 0, 0, time.UTC)
+	r := &RDS{
+		RemoteFX: true,
+		Log:      testutil.Logger{},
+		sessions: func() ([]session, error) {
+			return []session{
+				{id: 0, station: "Services", state: windows.WTSDisconnected},
+				{id: 1, station: "Console", state: windows.WTSConnected},
+				{
+					id:        2,
+					station:   "RDP-Tcp#3",
+					state:     windows.WTSActive,
+					user:      "alice",
+					domain:    "CORP",
+					lastInput: now.Add(-2 * time.Minute),
+					logon:     now.Add(-time.Hour),
+					current:   now,
+				},
+				{
+					id:      3,
+					state:   windows.WTSDisconnected,
+					user:    "bob",
+					domain:  "CORP",
+					logon:   now.Add(-24 * time.Hour),
+					current: now,
+				},
+				{id: 65536, station: "RDP-Tcp", state: windows.WTSListen},
+			}, nil
+		},
+		query: func(_, _ string, fn func(properties map[string]interface{}) error) error {
+			return fn(map[string]interface{}{
+				"Name":                "RDP-Tcp 3",
+				"CurrentTCPRTT":       uint32(25),
+				"CurrentUDPRTT":       uint32(12),
+				"CurrentTCPBandwidth": uint32(0),
+				"CurrentUDPBandwidth": uint32(95000),
+				"LossRate":            uint32(1),
+				"RetransmissionRate":  uint32(0),
+				"FECRate":             uint32(3),
+			})
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"rds_session",
+			map[string]string{
+				"session_id": "2",
+				"station":    "RDP-Tcp#3",
+				"user":       "alice",
+				"domain":     "CORP",
+				"state":      "active",
+			},
+			map[string]interface{}{
+				"idle_seconds":                int64(120),
+				"logon_seconds":               int64(3600),
+				"tcp_rtt_ms":                  uint64(25),
+				"udp_rtt_ms":                  uint64(12),
+				"tcp_bandwidth_kbps":          uint64(0),
+				"udp_bandwidth_kbps":          uint64(95000),
+				"loss_rate_percent":           uint64(1),
+				"retransmission_rate_percent": uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"rds_session",
+			map[string]string{
+				"session_id": "3",
+				"user":       "bob",
+				"domain":     "CORP",
+				"state":      "disconnected",
+			},
+			map[string]interface{}{
+				"logon_seconds": int64(86400),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"rds",
+			map[string]string{},
+			map[string]interface{}{
+				"sessions":              uint64(3),
+				"sessions_active":       uint64(1),
+				"sessions_disconnected": uint64(1),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
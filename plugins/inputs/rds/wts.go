@@ -0,0 +1,110 @@
+//go:build windows
+// +build windows
+
+package rds
+
+import (
+	"errors"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modwtsapi32 = windows.NewLazySystemDLL("wtsapi32.dll")
+
+	procWTSQuerySessionInformationW = modwtsapi32.NewProc("WTSQuerySessionInformationW")
+)
+
+const (
+	wtsCurrentServerHandle = 0
+	wtsSessionInfo         = 24
+)
+
+// wtsInfo is WTSINFOW.
+type wtsInfo struct {
+	State                   uint32
+	SessionID               uint32
+	IncomingBytes           uint32
+	OutgoingBytes           uint32
+	IncomingFrames          uint32
+	OutgoingFrames          uint32
+	IncomingCompressedBytes uint32
+	OutgoingCompressedBytes uint32
+	WinStationName          [32]uint16
+	Domain                  [17]uint16
+	UserName                [21]uint16
+	// The times are aligned to 8 bytes on all architectures
+	_              [2]uint16
+	ConnectTime    int64
+	DisconnectTime int64
+	LastInputTime  int64
+	LogonTime      int64
+	CurrentTime    int64
+}
+
+// wtsSessions returns the sessions of the local server.
+func wtsSessions() ([]session, error) {
+	var infos *windows.WTS_SESSION_INFO
+	var count uint32
+	if err := windows.WTSEnumerateSessions(wtsCurrentServerHandle, 0, 1, &infos, &count); err != nil {
+		return nil, err
+	}
+	defer windows.WTSFreeMemory(uintptr(unsafe.Pointer(infos)))
+
+	sessions := make([]session, 0, count)
+	for _, info := range unsafe.Slice(infos, count) {
+		s := session{
+			id:      info.SessionID,
+			station: windows.UTF16PtrToString(info.WindowStationName),
+			state:   info.State,
+		}
+		// The details are not available for all sessions, e.g. listeners
+		if details, err := sessionDetails(info.SessionID); err == nil {
+			s.user = windows.UTF16ToString(details.UserName[:])
+			s.domain = windows.UTF16ToString(details.Domain[:])
+			s.lastInput = filetime(details.LastInputTime)
+			s.logon = filetime(details.LogonTime)
+			s.current = filetime(details.CurrentTime)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// sessionDetails returns the WTSINFOW of the session.
+func sessionDetails(id uint32) (*wtsInfo, error) {
+	var buf *byte
+	var size uint32
+	r, _, err := procWTSQuerySessionInformationW.Call(
+		wtsCurrentServerHandle,
+		uintptr(id),
+		wtsSessionInfo,
+		uintptr(unsafe.Pointer(&buf)),
+		uintptr(unsafe.Pointer(&size)),
+	)
+	if r == 0 {
+		return nil, err
+	}
+	defer windows.WTSFreeMemory(uintptr(unsafe.Pointer(buf)))
+	if uintptr(size) < unsafe.Sizeof(wtsInfo{}) {
+		return nil, errors.New("invalid session information")
+	}
+
+	info := *(*wtsInfo)(unsafe.Pointer(buf))
+	return &info, nil
+}
+
+// filetime returns the time of the 100ns intervals since 1601, the zero time
+// if not set.
+func filetime(t int64) time.Time {
+	if t <= 0 {
+		return time.Time{}
+	}
+	ft := windows.Filetime{
+		LowDateTime:  uint32(t),
+		HighDateTime: uint32(t >> 32),
+	}
+	return time.Unix(0, ft.Nanoseconds())
+}
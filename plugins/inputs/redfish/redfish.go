@@ -12,6 +12,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -168,7 +169,7 @@ func (r *Redfish) Init() error {
 	r.client = http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: tlsCfg,
-			Proxy:           http.ProxyFromEnvironment,
+			Proxy:           internal.Proxy,
 		},
 		Timeout: time.Duration(r.Timeout),
 	}
@@ -0,0 +1,161 @@
+//go:build windows
+// +build windows
+
+package smb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the shares to collect, all if empty. Globs accepted.
+  # share_names = []
+
+  ## Collect the administrative and other special shares, e.g. C$ and IPC$.
+  # special_shares = false
+`
+
+// Namespaces of the queried classes.
+const (
+	cimv2Namespace = `root\cimv2`
+	smbNamespace   = `root\Microsoft\Windows\SMB`
+)
+
+// SMB collects the metrics of the shares of the SMB server.
+type SMB struct {
+	ShareNames    []string `toml:"share_names"`
+	SpecialShares bool     `toml:"special_shares"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	query  wmi.QueryFunc
+}
+
+// share is a share of the server and its metrics.
+type share struct {
+	name   string
+	path   string
+	fields map[string]interface{}
+}
+
+func (s *SMB) Description() string {
+	return "Collect the sessions, open files and throughput of the SMB server shares"
+}
+
+func (s *SMB) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SMB) Init() error {
+	f, err := filter.Compile(s.ShareNames)
+	if err != nil {
+		return fmt.Errorf("compiling share_names failed: %w", err)
+	}
+	s.filter = f
+	return nil
+}
+
+func (s *SMB) Gather(acc telegraf.Accumulator) error {
+	if err := s.gatherServer(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying server counters failed: %w", err))
+	}
+
+	shares, err := s.gatherShares()
+	if err != nil {
+		return fmt.Errorf("querying shares failed: %w", err)
+	}
+	if err := s.gatherShareCounters(shares); err != nil {
+		acc.AddError(fmt.Errorf("querying share counters failed: %w", err))
+	}
+	for _, sh := range shares {
+		acc.AddFields("smb_share", sh.fields, map[string]string{"share": sh.name, "path": sh.path})
+	}
+	return nil
+}
+
+// gatherServer adds the session and error counters of the server.
+func (s *SMB) gatherServer(acc telegraf.Accumulator) error {
+	return s.query(cimv2Namespace,
+		"SELECT ServerSessions, SessionsErroredOut, SessionsTimedOut, ErrorsAccessPermissions, ErrorsGrantedAccess, ErrorsLogon FROM Win32_PerfRawData_PerfNet_Server",
+		func(p map[string]interface{}) error {
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"ServerSessions":          "sessions",
+				"SessionsErroredOut":      "sessions_errored_out",
+				"SessionsTimedOut":        "sessions_timed_out",
+				"ErrorsAccessPermissions": "access_denied_errors",
+				"ErrorsGrantedAccess":     "granted_access_errors",
+				"ErrorsLogon":             "logon_errors",
+			})
+			acc.AddFields("smb_server", fields, nil)
+			return nil
+		})
+}
+
+// gatherShares returns the shares matching the filter by upper case name.
+func (s *SMB) gatherShares() (map[string]*share, error) {
+	shares := make(map[string]*share)
+	err := s.query(smbNamespace,
+		"SELECT Name, Path, CurrentUsers, ShareState, Special FROM MSFT_SmbShare",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["Name"])
+			if special, _ := p["Special"].(bool); special && !s.SpecialShares {
+				return nil
+			}
+			if s.filter != nil && !s.filter.Match(name) {
+				return nil
+			}
+
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"CurrentUsers": "current_users",
+				"ShareState":   "state",
+			})
+			path, _ := p["Path"].(string)
+			shares[strings.ToUpper(name)] = &share{name: name, path: path, fields: fields}
+			return nil
+		})
+	return shares, err
+}
+
+// gatherShareCounters adds the performance counters to the shares. The
+// counter instances are named '\\<server>\<share>'.
+func (s *SMB) gatherShareCounters(shares map[string]*share) error {
+	return s.query(cimv2Namespace,
+		"SELECT Name, CurrentOpenFileCount, CurrentDurableOpenFileCount, TreeConnectCount, FilesOpenedPersec, ReceivedBytesPersec, SentBytesPersec, ReadBytesPersec, WriteBytesPersec, RequestsPersec FROM Win32_PerfFormattedData_SMBServerShares_SMBServerShares",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["Name"])
+			if i := strings.LastIndex(name, `\`); i >= 0 {
+				name = name[i+1:]
+			}
+			sh, ok := shares[strings.ToUpper(name)]
+			if !ok {
+				return nil
+			}
+			wmi.AddUintFields(sh.fields, p, map[string]string{
+				"CurrentOpenFileCount":        "open_files",
+				"CurrentDurableOpenFileCount": "durable_open_files",
+				"TreeConnectCount":            "tree_connects",
+				"FilesOpenedPersec":           "files_opened_per_sec",
+				"ReceivedBytesPersec":         "bytes_received_per_sec",
+				"SentBytesPersec":             "bytes_sent_per_sec",
+				"ReadBytesPersec":             "read_bytes_per_sec",
+				"WriteBytesPersec":            "write_bytes_per_sec",
+				"RequestsPersec":              "requests_per_sec",
+			})
+			return nil
+		})
+}
+
+func init() {
+	inputs.Add("smb", func() telegraf.Input {
+		return &SMB{query: (&wmi.Connection{}).Query}
+	})
+}
@@ -0,0 +1,122 @@
+//go:build windows
+// +build windows
+
+package smb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	s := &SMB{
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Win32_PerfRawData_PerfNet_Server": {
+				{
+					"ServerSessions":          int32(12),
+					"SessionsErroredOut":      int32(1),
+					"SessionsTimedOut":        int32(2),
+					"ErrorsAccessPermissions": int32(3),
+					"ErrorsGrantedAccess":     int32(4),
+					"ErrorsLogon":             int32(5),
+				},
+			},
+			"MSFT_SmbShare": {
+				{"Name": "Data", "Path": `D:\Data`, "CurrentUsers": int32(10), "ShareState": int32(1), "Special": false},
+				{"Name": "Empty", "Path": `D:\Empty`, "CurrentUsers": int32(0), "ShareState": int32(1), "Special": false},
+				{"Name": "C$", "Path": `C:\`, "CurrentUsers": int32(1), "ShareState": int32(1), "Special": true},
+			},
+			"Win32_PerfFormattedData_SMBServerShares_SMBServerShares": {
+				{
+					"Name":                        `\\*\DATA`,
+					"CurrentOpenFileCount":        int32(25),
+					"CurrentDurableOpenFileCount": int32(20),
+					"TreeConnectCount":            int32(11),
+					"FilesOpenedPersec":           int32(3),
+					"ReceivedBytesPersec":         "1024",
+					"SentBytesPersec":             "4096",
+					"ReadBytesPersec":             "4000",
+					"WriteBytesPersec":            "1000",
+					"RequestsPersec":              int32(50),
+				},
+				{"Name": `\\*\C$`, "CurrentOpenFileCount": int32(1)},
+				{"Name": "_Total", "CurrentOpenFileCount": int32(26)},
+			},
+		}),
+	}
+	require.NoError(t, s.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("smb_server",
+			map[string]string{},
+			map[string]interface{}{
+				"sessions":              uint64(12),
+				"sessions_errored_out":  uint64(1),
+				"sessions_timed_out":    uint64(2),
+				"access_denied_errors":  uint64(3),
+				"granted_access_errors": uint64(4),
+				"logon_errors":          uint64(5),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("smb_share",
+			map[string]string{"share": "Data", "path": `D:\Data`},
+			map[string]interface{}{
+				"current_users":          uint64(10),
+				"state":                  uint64(1),
+				"open_files":             uint64(25),
+				"durable_open_files":     uint64(20),
+				"tree_connects":          uint64(11),
+				"files_opened_per_sec":   uint64(3),
+				"bytes_received_per_sec": uint64(1024),
+				"bytes_sent_per_sec":     uint64(4096),
+				"read_bytes_per_sec":     uint64(4000),
+				"write_bytes_per_sec":    uint64(1000),
+				"requests_per_sec":       uint64(50),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("smb_share",
+			map[string]string{"share": "Empty", "path": `D:\Empty`},
+			map[string]interface{}{
+				"current_users": uint64(0),
+				"state":         uint64(1),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherFilter(t *testing.T) {
+	s := &SMB{
+		ShareNames:    []string{"C$", "Data"},
+		SpecialShares: true,
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Win32_PerfRawData_PerfNet_Server": {},
+			"MSFT_SmbShare": {
+				{"Name": "Data", "Path": `D:\Data`, "CurrentUsers": int32(1), "Special": false},
+				{"Name": "Other", "Path": `D:\Other`, "CurrentUsers": int32(1), "Special": false},
+				{"Name": "C$", "Path": `C:\`, "CurrentUsers": int32(1), "Special": true},
+			},
+			"Win32_PerfFormattedData_SMBServerShares_SMBServerShares": {},
+		}),
+	}
+	require.NoError(t, s.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, s.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	for _, m := range acc.GetTelegrafMetrics() {
+		share, _ := m.GetTag("share")
+		require.Contains(t, []string{"C$", "Data"}, share)
+	}
+}
@@ -98,3 +98,28 @@ func parseConnectionStringURL(connectionString string) (sqlInstance string, data
 
 	return sqlInstance, databaseName
 }
+
+// hasCredentials returns true if the connection string contains a user or
+// password, in which case SQL Server or NTLM authentication is used instead of
+// integrated Windows authentication.
+func hasCredentials(connectionString string) bool {
+	trimmedConnectionString := strings.TrimSpace(connectionString)
+
+	if strings.HasPrefix(trimmedConnectionString, "sqlserver://") {
+		u, err := url.Parse(trimmedConnectionString)
+		return err == nil && u.User != nil && u.User.Username() != ""
+	}
+
+	keyValuePairs := strings.Split(strings.TrimPrefix(trimmedConnectionString, "odbc:"), ";")
+	for _, keyValuePair := range keyValuePairs {
+		keyAndValue := strings.SplitN(keyValuePair, "=", 2)
+		if len(keyAndValue) < 2 || strings.TrimSpace(keyAndValue[1]) == "" {
+			continue
+		}
+		switch strings.TrimSpace(strings.ToLower(keyAndValue[0])) {
+		case "user id", "password":
+			return true
+		}
+	}
+	return false
+}
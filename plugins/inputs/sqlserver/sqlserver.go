@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -82,7 +83,10 @@ servers = [
 ]
 
 ## Authentication method
-## valid methods: "connection_string", "AAD"
+## valid methods: "connection_string", "windows", "AAD"
+## "windows" uses integrated Windows authentication with the account Telegraf
+## runs as, e.g. a group managed service account, and requires connection
+## strings without user id and password.
 # auth_method = "connection_string"
 
 ## "database_type" enables a specific set of queries depending on the database type. If specified, it replaces azuredb = true/false and query_version = 2
@@ -300,6 +304,25 @@ func (s *SQLServer) Start(acc telegraf.Accumulator) error {
 			var err error
 			pool, err = sql.Open("mssql", serv)
 
+			if err != nil {
+				acc.AddError(err)
+				continue
+			}
+		case "windows":
+			// Integrated Windows authentication with the account
+			// Telegraf runs as, e.g. a group managed service account,
+			// so no SQL logins are needed.
+			if runtime.GOOS != "windows" {
+				return errors.New("auth method windows is only supported on Windows")
+			}
+			if hasCredentials(serv) {
+				sqlInstance, _ := getConnectionIdentifiers(serv)
+				err := fmt.Errorf("connection string of %q must not contain a user id or password for auth method windows", sqlInstance)
+				acc.AddError(err)
+				continue
+			}
+			var err error
+			pool, err = sql.Open("mssql", serv)
 			if err != nil {
 				acc.AddError(err)
 				continue
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/parsers"
 )
 
 // Indicates relation to the multiline event: previous or next
@@ -17,6 +18,12 @@ type Multiline struct {
 	config        *MultilineConfig
 	enabled       bool
 	patternRegexp *regexp.Regexp
+
+	// parser is set if the joining of lines is driven by the parser instead
+	// of the multiline configuration of the plugin.
+	parser   parsers.MultilineParser
+	maxLines int
+	lines    int
 }
 
 type MultilineConfig struct {
@@ -55,24 +62,41 @@ func (m *MultilineConfig) NewMultiline() (*Multiline, error) {
 		patternRegexp: r}, nil
 }
 
+// newParserMultiline returns a multiline handler where the parser decides
+// which lines start a new log entry. Lines are joined using a newline so the
+// parser can split them the same way when parsing the entry.
+func newParserMultiline(parser parsers.MultilineParser) *Multiline {
+	timeout := config.Duration(parser.MultilineFlushTimeout())
+	if timeout == 0 {
+		timeout = config.Duration(5 * time.Second)
+	}
+
+	return &Multiline{
+		config: &MultilineConfig{
+			MatchWhichLine: Previous,
+			Timeout:        &timeout,
+		},
+		enabled:  true,
+		parser:   parser,
+		maxLines: parser.MultilineLineLimit(),
+	}
+}
+
 func (m *Multiline) IsEnabled() bool {
 	return m.enabled
 }
 
 func (m *Multiline) ProcessLine(text string, buffer *bytes.Buffer) string {
-	if m.matchString(text) {
-		// Ignore the returned error as we cannot do anything about it anyway
-		//nolint:errcheck,revive
-		buffer.WriteString(text)
+	if m.matchString(text) && !m.isFull() {
+		m.appendLine(text, buffer)
 		return ""
 	}
 
 	if m.config.MatchWhichLine == Previous {
 		previousText := buffer.String()
 		buffer.Reset()
-		if _, err := buffer.WriteString(text); err != nil {
-			return ""
-		}
+		m.lines = 0
+		m.appendLine(text, buffer)
 		text = previousText
 	} else {
 		// Next
@@ -82,6 +106,7 @@ func (m *Multiline) ProcessLine(text string, buffer *bytes.Buffer) string {
 			}
 			text = buffer.String()
 			buffer.Reset()
+			m.lines = 0
 		}
 	}
 
@@ -89,6 +114,7 @@ func (m *Multiline) ProcessLine(text string, buffer *bytes.Buffer) string {
 }
 
 func (m *Multiline) Flush(buffer *bytes.Buffer) string {
+	m.lines = 0
 	if buffer.Len() == 0 {
 		return ""
 	}
@@ -97,7 +123,26 @@ func (m *Multiline) Flush(buffer *bytes.Buffer) string {
 	return text
 }
 
+func (m *Multiline) appendLine(text string, buffer *bytes.Buffer) {
+	if m.parser != nil && buffer.Len() > 0 {
+		// Ignore the returned error as we cannot do anything about it anyway
+		//nolint:errcheck,revive
+		buffer.WriteString("\n")
+	}
+	// Ignore the returned error as we cannot do anything about it anyway
+	//nolint:errcheck,revive
+	buffer.WriteString(text)
+	m.lines++
+}
+
+func (m *Multiline) isFull() bool {
+	return m.maxLines > 0 && m.lines >= m.maxLines
+}
+
 func (m *Multiline) matchString(text string) bool {
+	if m.parser != nil {
+		return !m.parser.IsNewLogLine(text)
+	}
 	return m.patternRegexp.MatchString(text) != m.config.InvertMatch
 }
 
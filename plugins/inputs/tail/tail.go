@@ -6,8 +6,10 @@ package tail
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -20,10 +22,15 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/plugins/parsers/xsv"
 )
 
 const (
 	defaultWatchMethod = "inotify"
+
+	// maxBatchLines is the maximum number of lines already available passed
+	// at once to parsers supporting batches.
+	maxBatchLines = 100
 )
 
 var (
@@ -43,7 +50,10 @@ type Tail struct {
 	CharacterEncoding   string   `toml:"character_encoding"`
 	PathTag             string   `toml:"path_tag"`
 
-	Log        telegraf.Logger `toml:"-"`
+	Log telegraf.Logger `toml:"-"`
+
+	// mu protects the tailers and offsets, read by GetState while running.
+	mu         sync.Mutex
 	tailers    map[string]*tail.Tail
 	offsets    map[string]int64
 	parserFunc parsers.ParserFunc
@@ -191,12 +201,16 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 		return err
 	}
 
+	t.mu.Lock()
 	t.tailers = make(map[string]*tail.Tail)
+	t.mu.Unlock()
 
 	err = t.tailNewFiles(t.FromBeginning)
 
 	// clear offsets
+	t.mu.Lock()
 	t.offsets = make(map[string]int64)
+	t.mu.Unlock()
 	// assumption that once Start is called, all parallel plugins have already been initialized
 	offsetsMutex.Lock()
 	offsets = make(map[string]int64)
@@ -206,6 +220,9 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 }
 
 func (t *Tail) tailNewFiles(fromBeginning bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	var poll bool
 	if t.WatchMethod == "poll" {
 		poll = true
@@ -226,6 +243,11 @@ func (t *Tail) tailNewFiles(fromBeginning bool) error {
 			var seek *tail.SeekInfo
 			if !t.Pipe && !fromBeginning {
 				if offset, ok := t.offsets[file]; ok {
+					// A file smaller than the offset was rotated or truncated
+					// since, it is read from the start.
+					if fileSmallerThan(file, offset) {
+						offset = 0
+					}
 					t.Log.Debugf("Using offset %d for %q", offset, file)
 					seek = &tail.SeekInfo{
 						Whence: 0,
@@ -289,7 +311,7 @@ func (t *Tail) tailNewFiles(fromBeginning bool) error {
 
 // ParseLine parses a line of text.
 func parseLine(parser parsers.Parser, line string, firstLine bool) ([]telegraf.Metric, error) {
-	switch parser.(type) {
+	switch parsers.Unwrap(parser).(type) {
 	case *csv.Parser:
 		// The csv parser parses headers in Parse and skips them in ParseLine.
 		// As a temporary solution call Parse only when getting the first
@@ -303,6 +325,18 @@ func parseLine(parser parsers.Parser, line string, firstLine bool) ([]telegraf.M
 			return nil, err
 		}
 
+		if m != nil {
+			return []telegraf.Metric{m}, nil
+		}
+		return []telegraf.Metric{}, nil
+	case *xsv.Parser:
+		// The xsv parser detects the format and consumes the header from the
+		// first line passed to ParseLine and keeps it for the following ones.
+		m, err := parser.ParseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
 		if m != nil {
 			return []telegraf.Metric{m}, nil
 		}
@@ -323,13 +357,28 @@ func (t *Tail) receiver(parser parsers.Parser, tailer *tail.Tail) {
 	var timer *time.Timer
 	var timeout <-chan time.Time
 
+	// Each receiver keeps its own multiline state. If the plugin has no
+	// multiline configuration, the parser may take over joining the lines.
+	ml := *t.multiline
+	multiline := &ml
+	if !multiline.IsEnabled() {
+		if mp, ok := parser.(parsers.MultilineParser); ok && mp.IsMultiline() {
+			multiline = newParserMultiline(mp)
+		}
+	}
+
 	// The multiline mode requires a timer in order to flush the multiline buffer
 	// if no new lines are incoming.
-	if t.multiline.IsEnabled() {
-		timer = time.NewTimer(time.Duration(*t.MultilineConfig.Timeout))
+	if multiline.IsEnabled() {
+		timer = time.NewTimer(time.Duration(*multiline.config.Timeout))
 		timeout = timer.C
 	}
 
+	// Parsers supporting batches get all lines already available at once,
+	// unless the lines are joined to multiline entries.
+	_, batched := parsers.Unwrap(parser).(parsers.LinesParser)
+	batched = batched && !multiline.IsEnabled()
+
 	channelOpen := true
 	tailerOpen := true
 	var line *tail.Line
@@ -338,7 +387,7 @@ func (t *Tail) receiver(parser parsers.Parser, tailer *tail.Tail) {
 		line = nil
 
 		if timer != nil {
-			timer.Reset(time.Duration(*t.MultilineConfig.Timeout))
+			timer.Reset(time.Duration(*multiline.config.Timeout))
 		}
 
 		select {
@@ -357,14 +406,14 @@ func (t *Tail) receiver(parser parsers.Parser, tailer *tail.Tail) {
 			// Fix up files with Windows line endings.
 			text = strings.TrimRight(line.Text, "\r")
 
-			if t.multiline.IsEnabled() {
-				if text = t.multiline.ProcessLine(text, &buffer); text == "" {
+			if multiline.IsEnabled() {
+				if text = multiline.ProcessLine(text, &buffer); text == "" {
 					continue
 				}
 			}
 		}
 		if line == nil || !channelOpen || !tailerOpen {
-			if text += t.multiline.Flush(&buffer); text == "" {
+			if text += multiline.Flush(&buffer); text == "" {
 				if !channelOpen {
 					return
 				}
@@ -378,11 +427,17 @@ func (t *Tail) receiver(parser parsers.Parser, tailer *tail.Tail) {
 			continue
 		}
 
-		metrics, err := parseLine(parser, text, firstLine)
-		if err != nil {
-			t.Log.Errorf("Malformed log line in %q: [%q]: %s",
-				tailer.Filename, text, err.Error())
-			continue
+		var metrics []telegraf.Metric
+		if batched && line != nil {
+			metrics = t.parseBatch(parser, tailer.Filename, t.readBatch(tailer, text))
+		} else {
+			var err error
+			metrics, err = parseLine(parser, text, firstLine)
+			if err != nil {
+				t.Log.Errorf("Malformed log line in %q: [%q]: %s",
+					tailer.Filename, text, err.Error())
+				continue
+			}
 		}
 		firstLine = false
 
@@ -414,13 +469,60 @@ func (t *Tail) receiver(parser parsers.Parser, tailer *tail.Tail) {
 	}
 }
 
+// readBatch returns the given line followed by the lines already available
+// from the tailer, up to maxBatchLines, without waiting for new lines.
+func (t *Tail) readBatch(tailer *tail.Tail, first string) []string {
+	lines := []string{first}
+	for len(lines) < maxBatchLines {
+		select {
+		case line, ok := <-tailer.Lines:
+			if !ok {
+				return lines
+			}
+			if line.Err != nil {
+				t.Log.Errorf("Tailing %q: %s", tailer.Filename, line.Err.Error())
+				continue
+			}
+			// Fix up files with Windows line endings.
+			lines = append(lines, strings.TrimRight(line.Text, "\r"))
+		default:
+			return lines
+		}
+	}
+	return lines
+}
+
+// parseBatch parses the lines at once. If that fails, the lines are parsed
+// one by one, so only the malformed lines are dropped.
+func (t *Tail) parseBatch(parser parsers.Parser, filename string, lines []string) []telegraf.Metric {
+	metrics, err := parsers.ParseLines(parser, lines)
+	if err == nil {
+		return metrics
+	}
+
+	metrics = make([]telegraf.Metric, 0, len(lines))
+	for _, line := range lines {
+		m, err := parser.ParseLine(line)
+		if err != nil {
+			t.Log.Errorf("Malformed log line in %q: [%q]: %s", filename, line, err.Error())
+			continue
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
+
 func (t *Tail) Stop() {
+	t.mu.Lock()
 	for _, tailer := range t.tailers {
 		if !t.Pipe && !t.FromBeginning {
 			// store offset for resume
 			offset, err := tailer.Tell()
 			if err == nil {
 				t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
+				t.offsets[tailer.Filename] = offset
 			} else {
 				t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
 			}
@@ -430,16 +532,61 @@ func (t *Tail) Stop() {
 			t.Log.Errorf("Stopping tail on %q: %s", tailer.Filename, err.Error())
 		}
 	}
+	t.tailers = make(map[string]*tail.Tail)
+	t.mu.Unlock()
 
 	t.cancel()
 	t.wg.Wait()
 
 	// persist offsets
+	t.mu.Lock()
 	offsetsMutex.Lock()
 	for k, v := range t.offsets {
 		offsets[k] = v
 	}
 	offsetsMutex.Unlock()
+	t.mu.Unlock()
+}
+
+// GetState returns the offsets of the tailed files, and of the files recorded
+// on Stop, to resume tailing after restarts.
+func (t *Tail) GetState() ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[string]int64, len(t.offsets)+len(t.tailers))
+	for file, offset := range t.offsets {
+		current[file] = offset
+	}
+	if !t.Pipe && !t.FromBeginning {
+		for _, tailer := range t.tailers {
+			if offset, err := tailer.Tell(); err == nil {
+				current[tailer.Filename] = offset
+			}
+		}
+	}
+	return json.Marshal(current)
+}
+
+// SetState restores the offsets of the files.
+func (t *Tail) SetState(state []byte) error {
+	restored := make(map[string]int64)
+	if err := json.Unmarshal(state, &restored); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for file, offset := range restored {
+		t.offsets[file] = offset
+	}
+	return nil
+}
+
+// fileSmallerThan returns true if the file exists and is smaller than size.
+func fileSmallerThan(file string, size int64) bool {
+	info, err := os.Stat(file)
+	return err == nil && info.Size() < size
 }
 
 func (t *Tail) SetParserFunc(fn parsers.ParserFunc) {
@@ -2,10 +2,12 @@ package tail
 
 import (
 	"bytes"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -171,6 +173,44 @@ func TestGrokParseLogFilesWithMultiline(t *testing.T) {
 	assert.Equal(t, uint64(3), acc.NMetrics())
 }
 
+func TestGrokParseLogFilesWithParserMultiline(t *testing.T) {
+	tt := NewTail()
+	tt.Log = testutil.Logger{}
+	tt.FromBeginning = true
+	tt.Files = []string{filepath.Join(testdataDir, "test_multiline.log")}
+	tt.SetParserFunc(func() (parsers.Parser, error) {
+		return parsers.NewParser(&parsers.Config{
+			MetricName:             "tail_grok",
+			GrokPatterns:           []string{"(?s)%{TEST_LOG_MULTILINE}"},
+			GrokCustomPatternFiles: []string{filepath.Join(testdataDir, "test-patterns")},
+			GrokMultilinePattern:   `^\[`,
+			GrokMultilineNegate:    true,
+			GrokMultilineTimeout:   100 * time.Second,
+			DataFormat:             "grok",
+		})
+	})
+
+	err := tt.Init()
+	require.NoError(t, err)
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	defer tt.Stop()
+
+	acc.Wait(3)
+
+	expectedPath := filepath.Join(testdataDir, "test_multiline.log")
+	acc.AssertContainsTaggedFields(t, "tail_grok",
+		map[string]interface{}{
+			"message": "HelloExample: Sorry, something wrong! \njava.lang.ArithmeticException: / by zero\n\tat com.foo.HelloExample2.divide(HelloExample2.java:24)\n\tat com.foo.HelloExample2.main(HelloExample2.java:14)",
+		},
+		map[string]string{
+			"path":     expectedPath,
+			"loglevel": "ERROR",
+		})
+	require.Equal(t, uint64(3), acc.NMetrics())
+}
+
 func TestGrokParseLogFilesWithMultilineTimeout(t *testing.T) {
 	tmpfile, err := os.CreateTemp("", "")
 	require.NoError(t, err)
@@ -342,6 +382,111 @@ cpu,42
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
 }
 
+// The header must be parsed once if the csv parser is wrapped to apply
+// options common to all data formats.
+func TestCSVHeadersParsedOnceWrapped(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.WriteString("measurement,time_idle,time\ncpu,42,1000\ncpu,43,2000\n")
+	require.NoError(t, err)
+	require.NoError(t, tmpfile.Close())
+
+	plugin := NewTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.FromBeginning = true
+	plugin.Files = []string{tmpfile.Name()}
+	plugin.SetParserFunc(func() (parsers.Parser, error) {
+		return parsers.NewParser(&parsers.Config{
+			DataFormat:             "csv",
+			CSVHeaderRowCount:      1,
+			CSVMeasurementColumn:   "measurement",
+			CSVTimestampColumn:     "time",
+			CSVTimestampFormat:     "unix",
+			ParseErrorsMeasurement: "parse_error",
+		})
+	})
+	require.NoError(t, plugin.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	acc.Wait(2)
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{
+				"path": tmpfile.Name(),
+			},
+			map[string]interface{}{
+				"time_idle": 42,
+			},
+			time.Unix(1000, 0)),
+		testutil.MustMetric("cpu",
+			map[string]string{
+				"path": tmpfile.Name(),
+			},
+			map[string]interface{}{
+				"time_idle": 43,
+			},
+			time.Unix(2000, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestXSVHeaderDetectedFromFirstLine(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.WriteString("measurement;time_idle;time\ncpu;42;1000\ncpu;43;2000\n")
+	require.NoError(t, err)
+	require.NoError(t, tmpfile.Close())
+
+	plugin := NewTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.FromBeginning = true
+	plugin.Files = []string{tmpfile.Name()}
+	plugin.SetParserFunc(func() (parsers.Parser, error) {
+		return parsers.NewParser(&parsers.Config{
+			DataFormat:             "xsv",
+			CSVMeasurementColumn:   "measurement",
+			CSVTimestampColumn:     "time",
+			CSVTimestampFormat:     "unix",
+			ParseErrorsMeasurement: "parse_error",
+		})
+	})
+	require.NoError(t, plugin.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	acc.Wait(2)
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{
+				"path": tmpfile.Name(),
+			},
+			map[string]interface{}{
+				"time_idle": 42,
+			},
+			time.Unix(1000, 0)),
+		testutil.MustMetric("cpu",
+			map[string]string{
+				"path": tmpfile.Name(),
+			},
+			map[string]interface{}{
+				"time_idle": 43,
+			},
+			time.Unix(2000, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
 // Ensure that the first line can produce multiple metrics (#6138)
 func TestMultipleMetricsOnFirstLine(t *testing.T) {
 	tmpfile, err := os.CreateTemp("", "")
@@ -584,6 +729,114 @@ func TestTailEOF(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// batchParser counts the lines passed to the parser in batches.
+type batchParser struct {
+	parsers.Parser
+	lines int64
+}
+
+func (p *batchParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	atomic.AddInt64(&p.lines, int64(len(lines)))
+	return parsers.ParseLines(p.Parser, lines)
+}
+
+func TestBatchParsing(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString("cpu value=1 1\ncpu value= 2\ncpu value=3 3\r\n")
+	require.NoError(t, err)
+	require.NoError(t, tmpfile.Close())
+
+	inner, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+	parser := &batchParser{Parser: inner}
+
+	plugin := NewTestTail()
+	plugin.Log = testutil.Logger{}
+	plugin.FromBeginning = true
+	plugin.PathTag = ""
+	plugin.Files = []string{tmpfile.Name()}
+	plugin.SetParserFunc(func() (parsers.Parser, error) { return parser, nil })
+	require.NoError(t, plugin.Init())
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	acc.Wait(2)
+	plugin.Stop()
+
+	// The malformed line is dropped after parsing the batch failed
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 1)),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 3.0}, time.Unix(0, 3)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Equal(t, int64(3), atomic.LoadInt64(&parser.lines))
+}
+
+func TestTailState(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString("cpu usage_idle=100\ncpu2 usage_idle=200\n")
+	require.NoError(t, err)
+	require.NoError(t, tmpfile.Close())
+
+	tt := NewTestTail()
+	tt.Log = testutil.Logger{}
+	tt.Files = []string{tmpfile.Name()}
+	tt.SetParserFunc(parsers.NewInfluxParser)
+	require.NoError(t, tt.Init())
+
+	// Resume after the first line
+	require.NoError(t, tt.SetState([]byte(fmt.Sprintf(`{%q: 19}`, tmpfile.Name()))))
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	acc.Wait(1)
+
+	// The state reflects the current offset while running
+	expected := fmt.Sprintf(`{%q:39}`, tmpfile.Name())
+	require.Eventually(t, func() bool {
+		state, err := tt.GetState()
+		return err == nil && string(state) == expected
+	}, time.Second, 10*time.Millisecond)
+	tt.Stop()
+
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, "cpu2", acc.Metrics[0].Measurement)
+
+	state, err := tt.GetState()
+	require.NoError(t, err)
+	require.JSONEq(t, fmt.Sprintf(`{%q: 39}`, tmpfile.Name()), string(state))
+}
+
+func TestTailStateRotated(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString("cpu usage_idle=100\n")
+	require.NoError(t, err)
+	require.NoError(t, tmpfile.Close())
+
+	tt := NewTestTail()
+	tt.Log = testutil.Logger{}
+	tt.Files = []string{tmpfile.Name()}
+	tt.SetParserFunc(parsers.NewInfluxParser)
+	require.NoError(t, tt.Init())
+
+	// The file is smaller than the offset, so it was rotated and is read
+	// from the start.
+	require.NoError(t, tt.SetState([]byte(fmt.Sprintf(`{%q: 1000}`, tmpfile.Name()))))
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	defer tt.Stop()
+	acc.Wait(1)
+	require.Equal(t, "cpu", acc.Metrics[0].Measurement)
+}
+
 func getTestdataDir() string {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -0,0 +1,257 @@
+//go:build windows
+// +build windows
+
+package vss
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Collect the states of the VSS writers using 'vssadmin list writers'.
+  # writers = true
+
+  ## Timeout of the vssadmin command.
+  # timeout = "30s"
+`
+
+const namespace = `root\cimv2`
+
+// VSS collects the shadow copies and shadow storage of the volumes and the
+// states of the VSS writers.
+type VSS struct {
+	Writers bool            `toml:"writers"`
+	Timeout config.Duration `toml:"timeout"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	query wmi.QueryFunc
+	run   func(timeout time.Duration) ([]byte, error)
+	now   func() time.Time
+}
+
+// volume are the shadow copies and shadow storage of a volume.
+type volume struct {
+	fields map[string]interface{}
+	oldest time.Time
+	newest time.Time
+}
+
+func (v *VSS) Description() string {
+	return "Collect the shadow copies of the volumes and the states of the VSS writers"
+}
+
+func (v *VSS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (v *VSS) Gather(acc telegraf.Accumulator) error {
+	if err := v.gatherVolumes(acc); err != nil {
+		acc.AddError(err)
+	}
+	if v.Writers {
+		if err := v.gatherWriters(acc); err != nil {
+			acc.AddError(err)
+		}
+	}
+	return nil
+}
+
+func (v *VSS) gatherVolumes(acc telegraf.Accumulator) error {
+	var ids []string
+	volumes := make(map[string]*volume)
+	get := func(id string) *volume {
+		vol, ok := volumes[id]
+		if !ok {
+			vol = &volume{fields: map[string]interface{}{"shadow_copies": uint64(0)}}
+			volumes[id] = vol
+			ids = append(ids, id)
+		}
+		return vol
+	}
+
+	err := v.query(namespace, "SELECT VolumeName, InstallDate FROM Win32_ShadowCopy",
+		func(p map[string]interface{}) error {
+			vol := get(fmt.Sprint(p["VolumeName"]))
+			vol.fields["shadow_copies"] = vol.fields["shadow_copies"].(uint64) + 1
+			created, err := wmi.Time(p["InstallDate"])
+			if err != nil {
+				return nil
+			}
+			if vol.oldest.IsZero() || created.Before(vol.oldest) {
+				vol.oldest = created
+			}
+			if created.After(vol.newest) {
+				vol.newest = created
+			}
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("querying shadow copies failed: %w", err)
+	}
+
+	err = v.query(namespace, "SELECT Volume, AllocatedSpace, UsedSpace, MaxSpace FROM Win32_ShadowStorage",
+		func(p map[string]interface{}) error {
+			id, ok := referenceDeviceID(fmt.Sprint(p["Volume"]))
+			if !ok {
+				return nil
+			}
+			vol := get(id)
+			for property, field := range map[string]string{
+				"AllocatedSpace": "diff_area_allocated_bytes",
+				"UsedSpace":      "diff_area_used_bytes",
+				"MaxSpace":       "diff_area_max_bytes",
+			} {
+				// A volume may have several diff areas on other volumes
+				value, err := wmi.Uint64(p[property])
+				if err != nil {
+					continue
+				}
+				// The maximum is the largest integer if unbounded
+				sum, _ := vol.fields[field].(uint64)
+				if sum > math.MaxUint64-value {
+					sum = math.MaxUint64
+				} else {
+					sum += value
+				}
+				vol.fields[field] = sum
+			}
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("querying shadow storage failed: %w", err)
+	}
+
+	paths := make(map[string]string)
+	err = v.query(namespace, "SELECT DeviceID, Name FROM Win32_Volume", func(p map[string]interface{}) error {
+		paths[fmt.Sprint(p["DeviceID"])] = fmt.Sprint(p["Name"])
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("querying volumes failed: %w", err)
+	}
+
+	now := v.now()
+	for _, id := range ids {
+		vol := volumes[id]
+		if !vol.oldest.IsZero() {
+			vol.fields["oldest_age_seconds"] = int64(now.Sub(vol.oldest).Seconds())
+			vol.fields["newest_age_seconds"] = int64(now.Sub(vol.newest).Seconds())
+		}
+		tags := map[string]string{"volume": id}
+		if path, ok := paths[id]; ok && path != id {
+			tags["path"] = path
+		}
+		acc.AddFields("vss", vol.fields, tags)
+	}
+	return nil
+}
+
+func (v *VSS) gatherWriters(acc telegraf.Accumulator) error {
+	out, err := v.run(time.Duration(v.Timeout))
+	if err != nil {
+		return fmt.Errorf("running vssadmin failed: %w - %s", err, strings.TrimSpace(string(out)))
+	}
+	writers := parseWriters(string(out))
+	if len(writers) == 0 {
+		return errors.New("no writers found in the output of vssadmin, only the English output is supported")
+	}
+
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		acc.AddFields("vss_writer", writers[name], map[string]string{"writer": name})
+	}
+	return nil
+}
+
+// runVssadmin returns the output of 'vssadmin list writers'.
+func runVssadmin(timeout time.Duration) ([]byte, error) {
+	return internal.CombinedOutputTimeout(exec.Command("vssadmin", "list", "writers"), timeout)
+}
+
+// parseWriters parses the output of 'vssadmin list writers', like:
+//
+//	Writer name: 'Task Scheduler Writer'
+//	   Writer Id: {d61d61c8-d73a-4eee-8cdd-f6f9786b7124}
+//	   Writer Instance Id: {1bddd48e-5052-49db-9b07-b96f96727e6b}
+//	   State: [1] Stable
+//	   Last error: No error
+//
+// and returns the fields of the writers by name. A writer may have several
+// instances, the last one is returned.
+func parseWriters(out string) map[string]map[string]interface{} {
+	writers := make(map[string]map[string]interface{})
+	var fields map[string]interface{}
+	for _, line := range strings.Split(out, "\n") {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "Writer name":
+			fields = make(map[string]interface{})
+			writers[strings.Trim(value, "'")] = fields
+		case "State":
+			// The state is the code in brackets followed by its name
+			end := strings.Index(value, "]")
+			if fields == nil || !strings.HasPrefix(value, "[") || end < 0 {
+				continue
+			}
+			code, err := strconv.ParseInt(value[1:end], 10, 64)
+			if err != nil {
+				continue
+			}
+			fields["state"] = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value[end+1:])), " ", "_")
+			fields["state_code"] = code
+		case "Last error":
+			if fields != nil {
+				fields["last_error"] = value
+			}
+		}
+	}
+	return writers
+}
+
+// referenceDeviceID returns the device ID of the volume reference, e.g.
+// 'Win32_Volume.DeviceID="\\\\?\\Volume{...}\\"'.
+func referenceDeviceID(ref string) (string, bool) {
+	const prefix = `DeviceID="`
+	start := strings.Index(ref, prefix)
+	if start < 0 || !strings.HasSuffix(ref, `"`) {
+		return "", false
+	}
+	id := ref[start+len(prefix) : len(ref)-1]
+	return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(id), true
+}
+
+func init() {
+	inputs.Add("vss", func() telegraf.Input {
+		return &VSS{
+			Writers: true,
+			Timeout: config.Duration(30 * time.Second),
+			query:   (&wmi.Connection{}).Query,
+			run:     runVssadmin,
+			now:     time.Now,
+		}
+	})
+}
@@ -0,0 +1,164 @@
+//go:build windows
+// +build windows
+
+package vss
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const writers = `vssadmin 1.1 - Volume Shadow Copy Service administrative command-line tool
+(C) Copyright 2001-2013 Microsoft Corp.
+
+Writer name: 'Task Scheduler Writer'
+   Writer Id: {d61d61c8-d73a-4eee-8cdd-f6f9786b7124}
+   Writer Instance Id: {1bddd48e-5052-49db-9b07-b96f96727e6b}
+   State: [1] Stable
+   Last error: No error
+
+Writer name: 'SqlServerWriter'
+   Writer Id: {a65faa63-5ea8-4ebc-9dbd-a0c4db26912a}
+   Writer Instance Id: {9075f235-f3e7-4c5a-a3a8-e3e8e2e4e4c5}
+   State: [8] Failed
+   Last error: Retryable error
+
+Writer name: 'System Writer'
+   Writer Id: {e8132975-6f93-4464-a53e-1050253ae220}
+   Writer Instance Id: {3a8e2c4b-7f6d-4e5c-9b1a-2d3c4e5f6a7b}
+   State: [5] Waiting for completion
+   Last error: No error
+`
+
+var objects = map[string][]map[string]interface{}{
+	"Win32_ShadowCopy": {
+		{"VolumeName": `\\?\Volume{11111111-0000-0000-0000-100000000000}\`, "InstallDate": "20211012120000.000000+000"},
+		{"VolumeName": `\\?\Volume{11111111-0000-0000-0000-100000000000}\`, "InstallDate": "20211014060000.000000+000"},
+		{"VolumeName": `\\?\Volume{22222222-0000-0000-0000-100000000000}\`, "InstallDate": "20211014110000.000000+060"},
+	},
+	"Win32_ShadowStorage": {
+		{
+			"Volume":         `Win32_Volume.DeviceID="\\\\?\\Volume{11111111-0000-0000-0000-100000000000}\\"`,
+			"AllocatedSpace": "2147483648",
+			"UsedSpace":      "1073741824",
+			"MaxSpace":       "10737418240",
+		},
+		{
+			"Volume":         `Win32_Volume.DeviceID="\\\\?\\Volume{22222222-0000-0000-0000-100000000000}\\"`,
+			"AllocatedSpace": "1048576",
+			"UsedSpace":      "524288",
+			"MaxSpace":       "18446744073709551615",
+		},
+		{
+			"Volume":         `Win32_Volume.DeviceID="\\\\?\\Volume{22222222-0000-0000-0000-100000000000}\\"`,
+			"AllocatedSpace": "1048576",
+			"UsedSpace":      "524288",
+			"MaxSpace":       "1073741824",
+		},
+		{
+			"Volume":         `Win32_Volume.DeviceID="\\\\?\\Volume{33333333-0000-0000-0000-100000000000}\\"`,
+			"AllocatedSpace": "0",
+			"UsedSpace":      "0",
+			"MaxSpace":       "1073741824",
+		},
+	},
+	"Win32_Volume": {
+		{"DeviceID": `\\?\Volume{11111111-0000-0000-0000-100000000000}\`, "Name": `C:\`},
+		{"DeviceID": `\\?\Volume{22222222-0000-0000-0000-100000000000}\`, "Name": `D:\Mounts\Data\`},
+		{"DeviceID": `\\?\Volume{33333333-0000-0000-0000-100000000000}\`, "Name": `\\?\Volume{33333333-0000-0000-0000-100000000000}\`},
+	},
+}
+
+func TestGather(t *testing.T) {
+	v := &VSS{
+		Writers: true,
+		Log:     testutil.Logger{},
+		query: func(_, query string, fn func(properties map[string]interface{}) error) error {
+			for class, list := range objects {
+				if !strings.HasSuffix(query, "FROM "+class) {
+					continue
+				}
+				for _, o := range list {
+					if err := fn(o); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		run: func(time.Duration) ([]byte, error) {
+			return []byte(strings.ReplaceAll(writers, "\n", "\r\n")), nil
+		},
+		now: func() time.Time { return time.Date(2021, 10, 14, 12, 0, 0, 0, time.UTC) },
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, v.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"vss",
+			map[string]string{"volume": `\\?\Volume{11111111-0000-0000-0000-100000000000}\`, "path": `C:\`},
+			map[string]interface{}{
+				"shadow_copies":             uint64(2),
+				"oldest_age_seconds":        int64(172800),
+				"newest_age_seconds":        int64(21600),
+				"diff_area_allocated_bytes": uint64(2147483648),
+				"diff_area_used_bytes":      uint64(1073741824),
+				"diff_area_max_bytes":       uint64(10737418240),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"vss",
+			map[string]string{"volume": `\\?\Volume{22222222-0000-0000-0000-100000000000}\`, "path": `D:\Mounts\Data\`},
+			map[string]interface{}{
+				"shadow_copies":             uint64(1),
+				"oldest_age_seconds":        int64(7200),
+				"newest_age_seconds":        int64(7200),
+				"diff_area_allocated_bytes": uint64(2097152),
+				"diff_area_used_bytes":      uint64(1048576),
+				"diff_area_max_bytes":       uint64(math.MaxUint64),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"vss",
+			map[string]string{"volume": `\\?\Volume{33333333-0000-0000-0000-100000000000}\`},
+			map[string]interface{}{
+				"shadow_copies":             uint64(0),
+				"diff_area_allocated_bytes": uint64(0),
+				"diff_area_used_bytes":      uint64(0),
+				"diff_area_max_bytes":       uint64(1073741824),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"vss_writer",
+			map[string]string{"writer": "SqlServerWriter"},
+			map[string]interface{}{"state": "failed", "state_code": int64(8), "last_error": "Retryable error"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"vss_writer",
+			map[string]string{"writer": "System Writer"},
+			map[string]interface{}{"state": "waiting_for_completion", "state_code": int64(5), "last_error": "No error"},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"vss_writer",
+			map[string]string{"writer": "Task Scheduler Writer"},
+			map[string]interface{}{"state": "stable", "state_code": int64(1), "last_error": "No error"},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
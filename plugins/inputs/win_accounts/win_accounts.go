@@ -0,0 +1,122 @@
+//go:build windows
+// +build windows
+
+package win_accounts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Count the members of the local Administrators group.
+  # admin_members = true
+`
+
+const namespace = `root\cimv2`
+
+// administratorsSID is the well-known SID of the local Administrators group,
+// whose name is localized.
+const administratorsSID = "S-1-5-32-544"
+
+// WinAccounts collects the counts of the local user accounts and the members
+// of the local Administrators group.
+type WinAccounts struct {
+	AdminMembers bool `toml:"admin_members"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	query wmi.QueryFunc
+}
+
+func (w *WinAccounts) Description() string {
+	return "Collect the counts of the local user accounts and administrators"
+}
+
+func (w *WinAccounts) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinAccounts) Gather(acc telegraf.Accumulator) error {
+	var users, disabled, lockedOut, neverExpires uint64
+	err := w.query(namespace, "SELECT Name, Disabled, Lockout, PasswordExpires FROM Win32_UserAccount WHERE LocalAccount = TRUE",
+		func(p map[string]interface{}) error {
+			users++
+			if v, ok := p["Disabled"].(bool); ok && v {
+				disabled++
+				return nil
+			}
+			if v, ok := p["Lockout"].(bool); ok && v {
+				lockedOut++
+			}
+			if v, ok := p["PasswordExpires"].(bool); ok && !v {
+				neverExpires++
+			}
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("querying user accounts failed: %w", err)
+	}
+
+	fields := map[string]interface{}{
+		"users":                        users,
+		"users_enabled":                users - disabled,
+		"users_disabled":               disabled,
+		"users_locked_out":             lockedOut,
+		"users_password_never_expires": neverExpires,
+	}
+	if w.AdminMembers {
+		members, err := w.adminMembers()
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying administrators failed: %w", err))
+		} else {
+			fields["admin_members"] = members
+		}
+	}
+	acc.AddFields("win_accounts", fields, nil)
+	return nil
+}
+
+// adminMembers returns the number of direct members of the local
+// Administrators group, including domain accounts and groups.
+func (w *WinAccounts) adminMembers() (uint64, error) {
+	var domain, name string
+	err := w.query(namespace,
+		fmt.Sprintf("SELECT Domain, Name FROM Win32_Group WHERE LocalAccount = TRUE AND SID = '%s'", administratorsSID),
+		func(p map[string]interface{}) error {
+			domain = fmt.Sprint(p["Domain"])
+			name = fmt.Sprint(p["Name"])
+			return nil
+		})
+	if err != nil {
+		return 0, err
+	}
+	if name == "" {
+		return 0, fmt.Errorf("group %s not found", administratorsSID)
+	}
+
+	// The references are used instead of the associators, which would
+	// resolve the domain members at the domain controllers.
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	query := fmt.Sprintf(`REFERENCES OF {Win32_Group.Domain="%s",Name="%s"} WHERE ResultClass = Win32_GroupUser`,
+		escape.Replace(domain), escape.Replace(name))
+	var members uint64
+	err = w.query(namespace, query, func(map[string]interface{}) error {
+		members++
+		return nil
+	})
+	return members, err
+}
+
+func init() {
+	inputs.Add("win_accounts", func() telegraf.Input {
+		return &WinAccounts{
+			AdminMembers: true,
+			query:        (&wmi.Connection{}).Query,
+		}
+	})
+}
@@ -0,0 +1,72 @@
+//go:build windows
+// +build windows
+
+package win_accounts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	var queries []string
+	w := &WinAccounts{
+		AdminMembers: true,
+		Log:          testutil.Logger{},
+		query: func(_, query string, fn func(properties map[string]interface{}) error) error {
+			queries = append(queries, query)
+			var objects []map[string]interface{}
+			switch {
+			case strings.Contains(query, "FROM Win32_UserAccount"):
+				objects = []map[string]interface{}{
+					{"Name": "Administrator", "Disabled": true, "Lockout": false, "PasswordExpires": false},
+					{"Name": "Guest", "Disabled": true, "Lockout": false, "PasswordExpires": false},
+					{"Name": "svc_backup", "Disabled": false, "Lockout": false, "PasswordExpires": false},
+					{"Name": "alice", "Disabled": false, "Lockout": true, "PasswordExpires": true},
+					{"Name": "bob", "Disabled": false, "Lockout": false, "PasswordExpires": true},
+				}
+			case strings.Contains(query, "FROM Win32_Group"):
+				objects = []map[string]interface{}{{"Domain": "WEB01", "Name": "Administratoren"}}
+			case strings.HasPrefix(query, "REFERENCES OF"):
+				objects = []map[string]interface{}{
+					{"PartComponent": `\\WEB01\root\cimv2:Win32_UserAccount.Domain="WEB01",Name="Administrator"`},
+					{"PartComponent": `\\WEB01\root\cimv2:Win32_Group.Domain="CORP",Name="Domain Admins"`},
+				}
+			}
+			for _, o := range objects {
+				if err := fn(o); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Contains(t, queries, `REFERENCES OF {Win32_Group.Domain="WEB01",Name="Administratoren"} WHERE ResultClass = Win32_GroupUser`)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_accounts",
+			map[string]string{},
+			map[string]interface{}{
+				"users":                        uint64(5),
+				"users_enabled":                uint64(3),
+				"users_disabled":               uint64(2),
+				"users_locked_out":             uint64(1),
+				"users_password_never_expires": uint64(1),
+				"admin_members":                uint64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
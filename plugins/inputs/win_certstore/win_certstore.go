@@ -0,0 +1,204 @@
+//go:build windows
+// +build windows
+
+package win_certstore
+
+import (
+	"crypto/dsa" //nolint:staticcheck // DSA keys still need to be reported
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // the thumbprint is defined as SHA-1 hash
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Certificate stores to scan as '<location>\<store>'. The location is
+  ## "LocalMachine" or "CurrentUser", the store the name of the store, e.g.
+  ## "My" for the personal store.
+  # stores = ["LocalMachine\\My", "LocalMachine\\WebHosting"]
+
+  ## Common names of the subjects and issuers of the certificates to report,
+  ## all if empty. Globs accepted.
+  # subject_names = []
+  # issuer_names = []
+`
+
+// storeLocations are the flags of the supported store locations.
+var storeLocations = map[string]uint32{
+	"localmachine": windows.CERT_SYSTEM_STORE_LOCAL_MACHINE,
+	"currentuser":  windows.CERT_SYSTEM_STORE_CURRENT_USER,
+}
+
+// loadFunc returns the certificates of the system store in the location.
+type loadFunc func(location uint32, store string) ([]*x509.Certificate, error)
+
+// store is a parsed certificate store of the configuration.
+type store struct {
+	name     string
+	location uint32
+	store    string
+}
+
+// WinCertstore reports the expiry of the certificates in the Windows
+// certificate stores.
+type WinCertstore struct {
+	Stores       []string `toml:"stores"`
+	SubjectNames []string `toml:"subject_names"`
+	IssuerNames  []string `toml:"issuer_names"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	stores        []store
+	subjectFilter filter.Filter
+	issuerFilter  filter.Filter
+	load          loadFunc
+	now           func() time.Time
+}
+
+func (w *WinCertstore) Description() string {
+	return "Report the expiry of the certificates in the Windows certificate stores"
+}
+
+func (w *WinCertstore) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinCertstore) Init() error {
+	if len(w.Stores) == 0 {
+		return errors.New("no stores configured")
+	}
+	w.stores = w.stores[:0]
+	for _, name := range w.Stores {
+		parts := strings.SplitN(name, `\`, 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return fmt.Errorf("invalid store %q, expected '<location>\\<store>'", name)
+		}
+		location, ok := storeLocations[strings.ToLower(parts[0])]
+		if !ok {
+			return fmt.Errorf("invalid location %q of store %q", parts[0], name)
+		}
+		w.stores = append(w.stores, store{name: name, location: location, store: parts[1]})
+	}
+
+	var err error
+	if w.subjectFilter, err = filter.Compile(w.SubjectNames); err != nil {
+		return fmt.Errorf("compiling subject_names failed: %w", err)
+	}
+	if w.issuerFilter, err = filter.Compile(w.IssuerNames); err != nil {
+		return fmt.Errorf("compiling issuer_names failed: %w", err)
+	}
+	return nil
+}
+
+func (w *WinCertstore) Gather(acc telegraf.Accumulator) error {
+	now := w.now()
+	for _, s := range w.stores {
+		certs, err := w.load(s.location, s.store)
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading store %q failed: %w", s.name, err))
+			continue
+		}
+		for _, cert := range certs {
+			if w.subjectFilter != nil && !w.subjectFilter.Match(cert.Subject.CommonName) {
+				continue
+			}
+			if w.issuerFilter != nil && !w.issuerFilter.Match(cert.Issuer.CommonName) {
+				continue
+			}
+
+			expiry := cert.NotAfter.Sub(now)
+			fields := map[string]interface{}{
+				"expiry":            int64(expiry.Seconds()),
+				"days_until_expiry": int64(expiry.Hours() / 24),
+				"startdate":         cert.NotBefore.Unix(),
+				"enddate":           cert.NotAfter.Unix(),
+			}
+			if length := keyLength(cert); length > 0 {
+				fields["key_length"] = length
+			}
+			tags := map[string]string{
+				"store":                s.name,
+				"common_name":          cert.Subject.CommonName,
+				"issuer_common_name":   cert.Issuer.CommonName,
+				"serial_number":        cert.SerialNumber.Text(16),
+				"thumbprint":           fmt.Sprintf("%X", sha1.Sum(cert.Raw)), //nolint:gosec // the thumbprint is defined as SHA-1 hash
+				"public_key_algorithm": cert.PublicKeyAlgorithm.String(),
+			}
+			acc.AddFields("win_certstore", fields, tags)
+		}
+	}
+	return nil
+}
+
+// keyLength returns the length of the public key in bits, 0 if unknown.
+func keyLength(cert *x509.Certificate) int64 {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return int64(key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return int64(key.Curve.Params().BitSize)
+	case ed25519.PublicKey:
+		return 256
+	case *dsa.PublicKey:
+		return int64(key.P.BitLen())
+	}
+	return 0
+}
+
+// loadSystemStore returns the certificates of the system store. Certificates
+// that cannot be parsed are skipped.
+func loadSystemStore(location uint32, name string) ([]*x509.Certificate, error) {
+	storeName, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	flags := location | windows.CERT_STORE_READONLY_FLAG | windows.CERT_STORE_OPEN_EXISTING_FLAG
+	h, err := windows.CertOpenStore(windows.CERT_STORE_PROV_SYSTEM, 0, 0, flags, uintptr(unsafe.Pointer(storeName)))
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CertCloseStore(h, 0) //nolint:errcheck // nothing to do on error
+
+	var certs []*x509.Certificate
+	var ctx *windows.CertContext
+	for {
+		ctx, err = windows.CertEnumCertificatesInStore(h, ctx)
+		if ctx == nil {
+			break
+		}
+		// The encoded certificate is owned by the context, so copy it before
+		// the next iteration frees it.
+		encoded := make([]byte, ctx.Length)
+		copy(encoded, unsafe.Slice(ctx.EncodedCert, ctx.Length))
+		if cert, err := x509.ParseCertificate(encoded); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	var errno windows.Errno
+	if err != nil && (!errors.As(err, &errno) || errno != windows.Errno(windows.CRYPT_E_NOT_FOUND)) {
+		return certs, err
+	}
+	return certs, nil
+}
+
+func init() {
+	inputs.Add("win_certstore", func() telegraf.Input {
+		return &WinCertstore{
+			Stores: []string{`LocalMachine\My`, `LocalMachine\WebHosting`},
+			load:   loadSystemStore,
+			now:    time.Now,
+		}
+	})
+}
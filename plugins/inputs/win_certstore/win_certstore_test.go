@@ -0,0 +1,125 @@
+//go:build windows
+// +build windows
+
+package win_certstore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // the thumbprint is defined as SHA-1 hash
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// newCertificate returns a self-signed certificate for the common name.
+func newCertificate(t *testing.T, commonName string, serial int64, key interface{}, notAfter time.Time) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     notAfter,
+	}
+	var public interface{}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		public = &k.PublicKey
+	case *ecdsa.PrivateKey:
+		public = &k.PublicKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, public, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestInit(t *testing.T) {
+	w := &WinCertstore{Stores: []string{`LocalMachine\My`, `currentuser\Root`}}
+	require.NoError(t, w.Init())
+	require.Equal(t, []store{
+		{name: `LocalMachine\My`, location: windows.CERT_SYSTEM_STORE_LOCAL_MACHINE, store: "My"},
+		{name: `currentuser\Root`, location: windows.CERT_SYSTEM_STORE_CURRENT_USER, store: "Root"},
+	}, w.stores)
+
+	require.Error(t, (&WinCertstore{}).Init())
+	require.Error(t, (&WinCertstore{Stores: []string{"My"}}).Init())
+	require.Error(t, (&WinCertstore{Stores: []string{`Services\My`}}).Init())
+}
+
+func TestGather(t *testing.T) {
+	now := time.Date(2021, 10, 14, 0, 0, 0, 0, time.UTC)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	web := newCertificate(t, "www.example.com", 1, rsaKey, now.Add(30*24*time.Hour+time.Hour))
+	internal := newCertificate(t, "internal.example.com", 2, ecKey, now.Add(-2*24*time.Hour))
+	other := newCertificate(t, "other.org", 3, ecKey, now.Add(time.Hour))
+
+	w := &WinCertstore{
+		Stores:       []string{`LocalMachine\My`},
+		SubjectNames: []string{"*.example.com"},
+		load: func(location uint32, store string) ([]*x509.Certificate, error) {
+			require.Equal(t, uint32(windows.CERT_SYSTEM_STORE_LOCAL_MACHINE), location)
+			require.Equal(t, "My", store)
+			return []*x509.Certificate{web, internal, other}, nil
+		},
+		now: func() time.Time { return now },
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("win_certstore",
+			map[string]string{
+				"store":                `LocalMachine\My`,
+				"common_name":          "www.example.com",
+				"issuer_common_name":   "www.example.com",
+				"serial_number":        "1",
+				"thumbprint":           fmt.Sprintf("%X", sha1.Sum(web.Raw)), //nolint:gosec // the thumbprint is defined as SHA-1 hash
+				"public_key_algorithm": "RSA",
+			},
+			map[string]interface{}{
+				"expiry":            int64(30*24*3600 + 3600),
+				"days_until_expiry": int64(30),
+				"startdate":         int64(1609459200),
+				"enddate":           web.NotAfter.Unix(),
+				"key_length":        int64(1024),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_certstore",
+			map[string]string{
+				"store":                `LocalMachine\My`,
+				"common_name":          "internal.example.com",
+				"issuer_common_name":   "internal.example.com",
+				"serial_number":        "2",
+				"thumbprint":           fmt.Sprintf("%X", sha1.Sum(internal.Raw)), //nolint:gosec // the thumbprint is defined as SHA-1 hash
+				"public_key_algorithm": "ECDSA",
+			},
+			map[string]interface{}{
+				"expiry":            int64(-2 * 24 * 3600),
+				"days_until_expiry": int64(-2),
+				"startdate":         int64(1609459200),
+				"enddate":           internal.NotAfter.Unix(),
+				"key_length":        int64(256),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
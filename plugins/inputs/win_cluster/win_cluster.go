@@ -0,0 +1,195 @@
+//go:build windows
+// +build windows
+
+package win_cluster
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the cluster groups to collect, all if empty. Globs accepted.
+  # group_names = []
+
+  ## Collect the state of the resources of the groups.
+  # resources = true
+`
+
+const namespace = `root\MSCluster`
+
+// Names of the states of the cluster objects.
+var (
+	nodeStates = map[int64]string{
+		-1: "unknown",
+		0:  "up",
+		1:  "down",
+		2:  "paused",
+		3:  "joining",
+	}
+	groupStates = map[int64]string{
+		-1: "unknown",
+		0:  "online",
+		1:  "offline",
+		2:  "failed",
+		3:  "partial_online",
+		4:  "pending",
+	}
+	resourceStates = map[int64]string{
+		-1:  "unknown",
+		0:   "inherited",
+		1:   "initializing",
+		2:   "online",
+		3:   "offline",
+		4:   "failed",
+		128: "pending",
+		129: "online_pending",
+		130: "offline_pending",
+	}
+)
+
+// WinCluster collects the state of the nodes, groups and resources of a
+// Windows Server Failover Cluster.
+type WinCluster struct {
+	GroupNames []string `toml:"group_names"`
+	Resources  bool     `toml:"resources"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	groupFilter filter.Filter
+	query       wmi.QueryFunc
+
+	// owners are the owner nodes of the groups seen in the last gather,
+	// failovers the number of owner changes since the start by group.
+	owners    map[string]string
+	failovers map[string]int64
+}
+
+func (w *WinCluster) Description() string {
+	return "Collect the state of the nodes, groups and resources of a Windows failover cluster"
+}
+
+func (w *WinCluster) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinCluster) Init() error {
+	f, err := filter.Compile(w.GroupNames)
+	if err != nil {
+		return fmt.Errorf("compiling group_names failed: %w", err)
+	}
+	w.groupFilter = f
+	w.owners = make(map[string]string)
+	w.failovers = make(map[string]int64)
+	return nil
+}
+
+func (w *WinCluster) Gather(acc telegraf.Accumulator) error {
+	var cluster string
+	err := w.query(namespace, "SELECT Name FROM MSCluster_Cluster", func(p map[string]interface{}) error {
+		cluster = fmt.Sprint(p["Name"])
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("querying cluster failed: %w", err)
+	}
+
+	if err := w.gatherNodes(acc, cluster); err != nil {
+		acc.AddError(fmt.Errorf("querying nodes failed: %w", err))
+	}
+	if err := w.gatherGroups(acc, cluster); err != nil {
+		acc.AddError(fmt.Errorf("querying groups failed: %w", err))
+	}
+	if w.Resources {
+		if err := w.gatherResources(acc, cluster); err != nil {
+			acc.AddError(fmt.Errorf("querying resources failed: %w", err))
+		}
+	}
+	return nil
+}
+
+func (w *WinCluster) gatherNodes(acc telegraf.Accumulator, cluster string) error {
+	return w.query(namespace, "SELECT Name, State, DynamicWeight FROM MSCluster_Node",
+		func(p map[string]interface{}) error {
+			fields := stateFields(p["State"], nodeStates)
+			if weight, err := wmi.Uint64(p["DynamicWeight"]); err == nil {
+				fields["vote"] = weight
+			}
+			tags := map[string]string{"cluster": cluster, "node": fmt.Sprint(p["Name"])}
+			acc.AddFields("win_cluster_node", fields, tags)
+			return nil
+		})
+}
+
+func (w *WinCluster) gatherGroups(acc telegraf.Accumulator, cluster string) error {
+	return w.query(namespace, "SELECT Name, State, OwnerNode FROM MSCluster_ResourceGroup",
+		func(p map[string]interface{}) error {
+			group := fmt.Sprint(p["Name"])
+			if w.groupFilter != nil && !w.groupFilter.Match(group) {
+				return nil
+			}
+
+			// A change of the owner between two gathers is a failover or a
+			// move of the group.
+			owner := fmt.Sprint(p["OwnerNode"])
+			if previous, ok := w.owners[group]; ok && previous != owner {
+				w.failovers[group]++
+			}
+			w.owners[group] = owner
+
+			fields := stateFields(p["State"], groupStates)
+			fields["failovers"] = w.failovers[group]
+			tags := map[string]string{"cluster": cluster, "group": group, "owner_node": owner}
+			acc.AddFields("win_cluster_group", fields, tags)
+			return nil
+		})
+}
+
+func (w *WinCluster) gatherResources(acc telegraf.Accumulator, cluster string) error {
+	return w.query(namespace, "SELECT Name, State, OwnerGroup, OwnerNode, Type FROM MSCluster_Resource",
+		func(p map[string]interface{}) error {
+			group := fmt.Sprint(p["OwnerGroup"])
+			if w.groupFilter != nil && !w.groupFilter.Match(group) {
+				return nil
+			}
+			tags := map[string]string{
+				"cluster":    cluster,
+				"resource":   fmt.Sprint(p["Name"]),
+				"group":      group,
+				"owner_node": fmt.Sprint(p["OwnerNode"]),
+				"type":       fmt.Sprint(p["Type"]),
+			}
+			acc.AddFields("win_cluster_resource", stateFields(p["State"], resourceStates), tags)
+			return nil
+		})
+}
+
+// stateFields returns the state code and its name.
+func stateFields(value interface{}, names map[int64]string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	code, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil {
+		return fields
+	}
+	state, ok := names[code]
+	if !ok {
+		state = "unknown"
+	}
+	fields["state"] = state
+	fields["state_code"] = code
+	return fields
+}
+
+func init() {
+	inputs.Add("win_cluster", func() telegraf.Input {
+		return &WinCluster{
+			Resources: true,
+			query:     (&wmi.Connection{}).Query,
+		}
+	})
+}
@@ -0,0 +1,94 @@
+//go:build windows
+// +build windows
+
+package win_cluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	w := &WinCluster{
+		Resources: true,
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"MSCluster_Cluster": {{"Name": "CL1"}},
+			"MSCluster_Node": {
+				{"Name": "NODE1", "State": int32(0), "DynamicWeight": int32(1)},
+				{"Name": "NODE2", "State": int32(1), "DynamicWeight": int32(0)},
+			},
+			"MSCluster_ResourceGroup": {
+				{"Name": "SQL", "State": int32(0), "OwnerNode": "NODE1"},
+			},
+			"MSCluster_Resource": {
+				{"Name": "SQL Server", "State": int32(2), "OwnerGroup": "SQL", "OwnerNode": "NODE1", "Type": "SQL Server"},
+			},
+		}),
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("win_cluster_node",
+			map[string]string{"cluster": "CL1", "node": "NODE1"},
+			map[string]interface{}{"state": "up", "state_code": int64(0), "vote": uint64(1)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_cluster_node",
+			map[string]string{"cluster": "CL1", "node": "NODE2"},
+			map[string]interface{}{"state": "down", "state_code": int64(1), "vote": uint64(0)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_cluster_group",
+			map[string]string{"cluster": "CL1", "group": "SQL", "owner_node": "NODE1"},
+			map[string]interface{}{"state": "online", "state_code": int64(0), "failovers": int64(0)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_cluster_resource",
+			map[string]string{"cluster": "CL1", "resource": "SQL Server", "group": "SQL", "owner_node": "NODE1", "type": "SQL Server"},
+			map[string]interface{}{"state": "online", "state_code": int64(2)},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherFailovers(t *testing.T) {
+	owner := "NODE1"
+	w := &WinCluster{
+		GroupNames: []string{"SQL"},
+		query: func(_, query string, fn func(map[string]interface{}) error) error {
+			switch {
+			case strings.HasSuffix(query, "FROM MSCluster_Cluster"):
+				return fn(map[string]interface{}{"Name": "CL1"})
+			case strings.HasSuffix(query, "FROM MSCluster_ResourceGroup"):
+				if err := fn(map[string]interface{}{"Name": "SQL", "State": int32(0), "OwnerNode": owner}); err != nil {
+					return err
+				}
+				return fn(map[string]interface{}{"Name": "Available Storage", "State": int32(1), "OwnerNode": owner})
+			}
+			return nil
+		},
+	}
+	require.NoError(t, w.Init())
+
+	for _, node := range []string{"NODE1", "NODE2", "NODE2", "NODE1"} {
+		owner = node
+		var acc testutil.Accumulator
+		require.NoError(t, w.Gather(&acc))
+		require.Len(t, acc.Metrics, 1)
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	failovers, ok := acc.Get("win_cluster_group")
+	require.True(t, ok)
+	require.Equal(t, int64(2), failovers.Fields["failovers"])
+	require.Equal(t, "NODE1", failovers.Tags["owner_node"])
+}
@@ -0,0 +1,124 @@
+//go:build windows
+// +build windows
+
+package win_containers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modvmcompute = windows.NewLazySystemDLL("vmcompute.dll")
+
+	procHcsEnumerateComputeSystems    = modvmcompute.NewProc("HcsEnumerateComputeSystems")
+	procHcsOpenComputeSystem          = modvmcompute.NewProc("HcsOpenComputeSystem")
+	procHcsGetComputeSystemProperties = modvmcompute.NewProc("HcsGetComputeSystemProperties")
+	procHcsCloseComputeSystem         = modvmcompute.NewProc("HcsCloseComputeSystem")
+)
+
+// hcsClient queries the Host Compute Service using the version 1 API of
+// vmcompute.dll, which is also used by Docker.
+type hcsClient struct{}
+
+func (hcsClient) containers() ([]container, error) {
+	// vmcompute.dll is only available with the Containers feature installed
+	if err := procHcsEnumerateComputeSystems.Find(); err != nil {
+		return nil, fmt.Errorf("Host Compute Service not available: %w", err)
+	}
+
+	query, err := windows.UTF16PtrFromString(`{"Types":["Container"]}`)
+	if err != nil {
+		return nil, err
+	}
+	var systems, result *uint16
+	r, _, _ := procHcsEnumerateComputeSystems.Call(
+		uintptr(unsafe.Pointer(query)),
+		uintptr(unsafe.Pointer(&systems)),
+		uintptr(unsafe.Pointer(&result)),
+	)
+	if err := hcsError(r, result); err != nil {
+		freeString(systems)
+		return nil, fmt.Errorf("enumerating compute systems failed: %w", err)
+	}
+
+	var containers []container
+	if err := json.Unmarshal([]byte(freeString(systems)), &containers); err != nil {
+		return nil, fmt.Errorf("decoding compute systems failed: %w", err)
+	}
+	return containers, nil
+}
+
+func (hcsClient) statistics(id string) (*statistics, error) {
+	idPtr, err := windows.UTF16PtrFromString(id)
+	if err != nil {
+		return nil, err
+	}
+	var handle windows.Handle
+	var result *uint16
+	r, _, _ := procHcsOpenComputeSystem.Call(
+		uintptr(unsafe.Pointer(idPtr)),
+		uintptr(unsafe.Pointer(&handle)),
+		uintptr(unsafe.Pointer(&result)),
+	)
+	if err := hcsError(r, result); err != nil {
+		return nil, fmt.Errorf("opening compute system failed: %w", err)
+	}
+	defer procHcsCloseComputeSystem.Call(uintptr(handle)) //nolint:errcheck // nothing to do on error
+
+	query, err := windows.UTF16PtrFromString(`{"PropertyTypes":["Statistics"]}`)
+	if err != nil {
+		return nil, err
+	}
+	var properties *uint16
+	r, _, _ = procHcsGetComputeSystemProperties.Call(
+		uintptr(handle),
+		uintptr(unsafe.Pointer(query)),
+		uintptr(unsafe.Pointer(&properties)),
+		uintptr(unsafe.Pointer(&result)),
+	)
+	if err := hcsError(r, result); err != nil {
+		freeString(properties)
+		return nil, fmt.Errorf("querying properties failed: %w", err)
+	}
+
+	var p struct {
+		Statistics *statistics `json:"Statistics"`
+	}
+	if err := json.Unmarshal([]byte(freeString(properties)), &p); err != nil {
+		return nil, fmt.Errorf("decoding properties failed: %w", err)
+	}
+	if p.Statistics == nil {
+		return nil, errors.New("no statistics returned")
+	}
+	return p.Statistics, nil
+}
+
+// hcsError returns the error of the HRESULT of a call, with the message of
+// the result document if any. The result document is freed.
+func hcsError(r uintptr, result *uint16) error {
+	doc := freeString(result)
+	if int32(r) >= 0 {
+		return nil
+	}
+	var details struct {
+		ErrorMessage string `json:"ErrorMessage"`
+	}
+	if json.Unmarshal([]byte(doc), &details) == nil && details.ErrorMessage != "" {
+		return fmt.Errorf("%s (HRESULT 0x%08x)", details.ErrorMessage, uint32(r))
+	}
+	return windows.Errno(r)
+}
+
+// freeString returns the string allocated by the service and frees it.
+func freeString(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	defer windows.CoTaskMemFree(unsafe.Pointer(p))
+	return windows.UTF16PtrToString(p)
+}
@@ -0,0 +1,148 @@
+//go:build windows
+// +build windows
+
+package win_containers
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## IDs or names of the containers to include and exclude. Globs accepted.
+  ## All containers are included if both are empty.
+  # container_include = []
+  # container_exclude = []
+`
+
+// zeroGUID is the runtime ID of process-isolated containers.
+const zeroGUID = "00000000-0000-0000-0000-000000000000"
+
+// container is a compute system of the Host Compute Service.
+type container struct {
+	ID        string `json:"Id"`
+	Name      string `json:"Name"`
+	Owner     string `json:"Owner"`
+	RuntimeID string `json:"RuntimeId"`
+	Stopped   bool   `json:"Stopped"`
+}
+
+// statistics are the statistics of a compute system.
+type statistics struct {
+	Uptime100ns uint64 `json:"Uptime100ns"`
+	Processor   struct {
+		TotalRuntime100ns  uint64 `json:"TotalRuntime100ns"`
+		RuntimeUser100ns   uint64 `json:"RuntimeUser100ns"`
+		RuntimeKernel100ns uint64 `json:"RuntimeKernel100ns"`
+	} `json:"Processor"`
+	Memory struct {
+		UsageCommitBytes            uint64 `json:"UsageCommitBytes"`
+		UsageCommitPeakBytes        uint64 `json:"UsageCommitPeakBytes"`
+		UsagePrivateWorkingSetBytes uint64 `json:"UsagePrivateWorkingSetBytes"`
+	} `json:"Memory"`
+	Storage struct {
+		ReadCountNormalized  uint64 `json:"ReadCountNormalized"`
+		ReadSizeBytes        uint64 `json:"ReadSizeBytes"`
+		WriteCountNormalized uint64 `json:"WriteCountNormalized"`
+		WriteSizeBytes       uint64 `json:"WriteSizeBytes"`
+	} `json:"Storage"`
+}
+
+// client queries the containers and their statistics.
+type client interface {
+	containers() ([]container, error)
+	statistics(id string) (*statistics, error)
+}
+
+// WinContainers collects the resource usage of the Windows containers from
+// the Host Compute Service.
+type WinContainers struct {
+	ContainerInclude []string `toml:"container_include"`
+	ContainerExclude []string `toml:"container_exclude"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	client client
+	filter filter.Filter
+}
+
+func (w *WinContainers) Description() string {
+	return "Collect the resource usage of Windows containers from the Host Compute Service"
+}
+
+func (w *WinContainers) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinContainers) Init() error {
+	f, err := filter.NewIncludeExcludeFilter(w.ContainerInclude, w.ContainerExclude)
+	if err != nil {
+		return fmt.Errorf("compiling container filter failed: %w", err)
+	}
+	w.filter = f
+	return nil
+}
+
+func (w *WinContainers) Gather(acc telegraf.Accumulator) error {
+	containers, err := w.client.containers()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range containers {
+		if c.Stopped || !w.filter.Match(c.ID) && (c.Name == "" || !w.filter.Match(c.Name)) {
+			continue
+		}
+		stats, err := w.client.statistics(c.ID)
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying statistics of container %q failed: %w", c.ID, err))
+			continue
+		}
+		w.addContainer(acc, c, stats)
+	}
+	return nil
+}
+
+func (w *WinContainers) addContainer(acc telegraf.Accumulator, c container, stats *statistics) {
+	// Hyper-V isolated containers run in a utility VM referenced by the
+	// runtime ID.
+	isolation := "process"
+	if c.RuntimeID != "" && c.RuntimeID != zeroGUID {
+		isolation = "hyperv"
+	}
+	tags := map[string]string{
+		"container_id": c.ID,
+		"isolation":    isolation,
+	}
+	if c.Name != "" && c.Name != c.ID {
+		tags["container_name"] = c.Name
+	}
+	if c.Owner != "" {
+		tags["owner"] = c.Owner
+	}
+
+	// The times are reported in 100ns intervals.
+	fields := map[string]interface{}{
+		"uptime_ns":                        stats.Uptime100ns * 100,
+		"cpu_usage_total_ns":               stats.Processor.TotalRuntime100ns * 100,
+		"cpu_usage_user_ns":                stats.Processor.RuntimeUser100ns * 100,
+		"cpu_usage_kernel_ns":              stats.Processor.RuntimeKernel100ns * 100,
+		"memory_commit_bytes":              stats.Memory.UsageCommitBytes,
+		"memory_commit_peak_bytes":         stats.Memory.UsageCommitPeakBytes,
+		"memory_private_working_set_bytes": stats.Memory.UsagePrivateWorkingSetBytes,
+		"storage_read_count":               stats.Storage.ReadCountNormalized,
+		"storage_read_bytes":               stats.Storage.ReadSizeBytes,
+		"storage_write_count":              stats.Storage.WriteCountNormalized,
+		"storage_write_bytes":              stats.Storage.WriteSizeBytes,
+	}
+	acc.AddFields("win_containers", fields, tags)
+}
+
+func init() {
+	inputs.Add("win_containers", func() telegraf.Input {
+		return &WinContainers{client: hcsClient{}}
+	})
+}
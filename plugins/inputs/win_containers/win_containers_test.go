@@ -0,0 +1,141 @@
+//go:build windows
+// +build windows
+
+package win_containers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type fakeClient struct {
+	list  []container
+	stats map[string]*statistics
+}
+
+func (c *fakeClient) containers() ([]container, error) {
+	return c.list, nil
+}
+
+func (c *fakeClient) statistics(id string) (*statistics, error) {
+	s, ok := c.stats[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return s, nil
+}
+
+func newStatistics(cpu, memory, read uint64) *statistics {
+	s := &statistics{Uptime100ns: 36000000000}
+	s.Processor.TotalRuntime100ns = cpu
+	s.Processor.RuntimeUser100ns = cpu / 4 * 3
+	s.Processor.RuntimeKernel100ns = cpu / 4
+	s.Memory.UsageCommitBytes = memory
+	s.Memory.UsageCommitPeakBytes = memory * 2
+	s.Memory.UsagePrivateWorkingSetBytes = memory / 2
+	s.Storage.ReadCountNormalized = read
+	s.Storage.ReadSizeBytes = read * 4096
+	s.Storage.WriteCountNormalized = 10
+	s.Storage.WriteSizeBytes = 40960
+	return s
+}
+
+func TestGather(t *testing.T) {
+	w := &WinContainers{
+		Log: testutil.Logger{},
+		client: &fakeClient{
+			list: []container{
+				{ID: "3f0c", Name: "3f0c", Owner: "docker", RuntimeID: zeroGUID},
+				{ID: "9a1b", Name: "web", Owner: "containerd-shim-runhcs-v1.exe", RuntimeID: "5d2a3c8e-1b7f-4c1a-9f1e-0a2b3c4d5e6f"},
+				{ID: "77aa", Owner: "docker", Stopped: true},
+			},
+			stats: map[string]*statistics{
+				"3f0c": newStatistics(1000, 104857600, 100),
+				"9a1b": newStatistics(4000, 209715200, 0),
+			},
+		},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_containers",
+			map[string]string{"container_id": "3f0c", "isolation": "process", "owner": "docker"},
+			map[string]interface{}{
+				"uptime_ns":                        uint64(3600000000000),
+				"cpu_usage_total_ns":               uint64(100000),
+				"cpu_usage_user_ns":                uint64(75000),
+				"cpu_usage_kernel_ns":              uint64(25000),
+				"memory_commit_bytes":              uint64(104857600),
+				"memory_commit_peak_bytes":         uint64(209715200),
+				"memory_private_working_set_bytes": uint64(52428800),
+				"storage_read_count":               uint64(100),
+				"storage_read_bytes":               uint64(409600),
+				"storage_write_count":              uint64(10),
+				"storage_write_bytes":              uint64(40960),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_containers",
+			map[string]string{
+				"container_id":   "9a1b",
+				"container_name": "web",
+				"isolation":      "hyperv",
+				"owner":          "containerd-shim-runhcs-v1.exe",
+			},
+			map[string]interface{}{
+				"uptime_ns":                        uint64(3600000000000),
+				"cpu_usage_total_ns":               uint64(400000),
+				"cpu_usage_user_ns":                uint64(300000),
+				"cpu_usage_kernel_ns":              uint64(100000),
+				"memory_commit_bytes":              uint64(209715200),
+				"memory_commit_peak_bytes":         uint64(419430400),
+				"memory_private_working_set_bytes": uint64(104857600),
+				"storage_read_count":               uint64(0),
+				"storage_read_bytes":               uint64(0),
+				"storage_write_count":              uint64(10),
+				"storage_write_bytes":              uint64(40960),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherFilter(t *testing.T) {
+	w := &WinContainers{
+		ContainerInclude: []string{"we*", "77*"},
+		Log:              testutil.Logger{},
+		client: &fakeClient{
+			list: []container{
+				{ID: "3f0c", Name: "3f0c"},
+				{ID: "9a1b", Name: "web"},
+				{ID: "77aa"},
+			},
+			stats: map[string]*statistics{
+				"3f0c": newStatistics(1000, 1024, 1),
+				"9a1b": newStatistics(1000, 1024, 1),
+			},
+		},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	// The statistics of 77aa are missing
+	require.Len(t, acc.Errors, 1)
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, "9a1b", acc.Metrics[0].Tags["container_id"])
+}
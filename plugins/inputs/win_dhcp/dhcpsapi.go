@@ -0,0 +1,205 @@
+//go:build windows
+// +build windows
+
+package win_dhcp
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Errors of the failover functions if the scope is in no relationship.
+const (
+	errorDhcpFoRelationshipDoesNotExist = 20115
+	errorDhcpFoScopeNotInRelationship   = 20116
+)
+
+var (
+	moddhcpsapi                            = windows.NewLazySystemDLL("dhcpsapi.dll")
+	procDhcpGetMibInfo                     = moddhcpsapi.NewProc("DhcpGetMibInfo")
+	procDhcpGetSubnetInfo                  = moddhcpsapi.NewProc("DhcpGetSubnetInfo")
+	procDhcpV4FailoverGetScopeRelationship = moddhcpsapi.NewProc("DhcpV4FailoverGetScopeRelationship")
+	procDhcpRpcFreeMemory                  = moddhcpsapi.NewProc("DhcpRpcFreeMemory")
+)
+
+// dhcpMibInfo is the DHCP_MIB_INFO structure.
+type dhcpMibInfo struct {
+	discovers       uint32
+	offers          uint32
+	requests        uint32
+	acks            uint32
+	naks            uint32
+	declines        uint32
+	releases        uint32
+	serverStartTime windows.Filetime
+	scopes          uint32
+	scopeInfo       *scopeMibInfo
+}
+
+// scopeMibInfo is the SCOPE_MIB_INFO structure.
+type scopeMibInfo struct {
+	subnet            uint32
+	numAddressesInuse uint32
+	numAddressesFree  uint32
+	numPendingOffers  uint32
+}
+
+// dhcpHostInfo is the DHCP_HOST_INFO structure.
+type dhcpHostInfo struct {
+	ipAddress   uint32
+	netBiosName *uint16
+	hostName    *uint16
+}
+
+// dhcpSubnetInfo is the DHCP_SUBNET_INFO structure.
+type dhcpSubnetInfo struct {
+	subnetAddress uint32
+	subnetMask    uint32
+	subnetName    *uint16
+	subnetComment *uint16
+	primaryHost   dhcpHostInfo
+	subnetState   int32
+}
+
+// dhcpFailoverRelationship is the DHCP_FAILOVER_RELATIONSHIP structure.
+type dhcpFailoverRelationship struct {
+	relationshipName    *uint16
+	primaryServer       uint32
+	secondaryServer     uint32
+	mode                int32
+	serverType          int32
+	state               int32
+	prevState           int32
+	mclt                uint32
+	safePeriod          uint32
+	sharedSecret        *uint16
+	percentage          uint32
+	scopes              *dhcpIPArray
+	primaryServerName   *uint16
+	secondaryServerName *uint16
+}
+
+// dhcpIPArray is the DHCP_IP_ARRAY structure.
+type dhcpIPArray struct {
+	numElements uint32
+	elements    *uint32
+}
+
+// apiServer queries a DHCP server using the DHCP server management API.
+type apiServer struct {
+	// address is the address of the server, the local server if empty.
+	address string
+}
+
+func (s *apiServer) serverAddress() (*uint16, error) {
+	if s.address == "" {
+		return nil, nil
+	}
+	return windows.UTF16PtrFromString(s.address)
+}
+
+func (s *apiServer) statistics() (*serverStatistics, error) {
+	server, err := s.serverAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	var info *dhcpMibInfo
+	r, _, _ := procDhcpGetMibInfo.Call(uintptr(unsafe.Pointer(server)), uintptr(unsafe.Pointer(&info)))
+	if r != 0 {
+		return nil, fmt.Errorf("DhcpGetMibInfo failed: %w", syscall.Errno(r))
+	}
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info))
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.scopeInfo))
+
+	stats := &serverStatistics{
+		discovers: info.discovers,
+		offers:    info.offers,
+		requests:  info.requests,
+		acks:      info.acks,
+		naks:      info.naks,
+		declines:  info.declines,
+		releases:  info.releases,
+	}
+	if info.scopes > 0 && info.scopeInfo != nil {
+		for _, scope := range unsafe.Slice(info.scopeInfo, info.scopes) {
+			stats.scopes = append(stats.scopes, scopeStatistics{
+				subnet:        scope.subnet,
+				inUse:         scope.numAddressesInuse,
+				free:          scope.numAddressesFree,
+				pendingOffers: scope.numPendingOffers,
+			})
+		}
+	}
+	return stats, nil
+}
+
+func (s *apiServer) scopeName(subnet uint32) (string, error) {
+	server, err := s.serverAddress()
+	if err != nil {
+		return "", err
+	}
+
+	var info *dhcpSubnetInfo
+	r, _, _ := procDhcpGetSubnetInfo.Call(uintptr(unsafe.Pointer(server)), uintptr(subnet), uintptr(unsafe.Pointer(&info)))
+	if r != 0 {
+		return "", fmt.Errorf("DhcpGetSubnetInfo failed: %w", syscall.Errno(r))
+	}
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info))
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.subnetName))
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.subnetComment))
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.primaryHost.netBiosName))
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.primaryHost.hostName))
+
+	return windows.UTF16PtrToString(info.subnetName), nil
+}
+
+func (s *apiServer) failover(subnet uint32) (*failoverRelationship, error) {
+	if err := procDhcpV4FailoverGetScopeRelationship.Find(); err != nil {
+		// Failover is supported since Windows Server 2012.
+		return nil, nil
+	}
+
+	server, err := s.serverAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	var info *dhcpFailoverRelationship
+	r, _, _ := procDhcpV4FailoverGetScopeRelationship.Call(uintptr(unsafe.Pointer(server)), uintptr(subnet), uintptr(unsafe.Pointer(&info)))
+	switch r {
+	case 0:
+	case errorDhcpFoScopeNotInRelationship, errorDhcpFoRelationshipDoesNotExist:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("DhcpV4FailoverGetScopeRelationship failed: %w", syscall.Errno(r))
+	}
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info))
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.relationshipName))
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.sharedSecret))
+	if info.scopes != nil {
+		defer dhcpRpcFreeMemory(unsafe.Pointer(info.scopes))
+		defer dhcpRpcFreeMemory(unsafe.Pointer(info.scopes.elements))
+	}
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.primaryServerName))
+	defer dhcpRpcFreeMemory(unsafe.Pointer(info.secondaryServerName))
+
+	return &failoverRelationship{
+		name:       windows.UTF16PtrToString(info.relationshipName),
+		mode:       info.mode,
+		serverType: info.serverType,
+		state:      info.state,
+		mclt:       info.mclt,
+		percentage: info.percentage,
+	}, nil
+}
+
+// dhcpRpcFreeMemory frees the memory allocated by the DHCP server API.
+func dhcpRpcFreeMemory(p unsafe.Pointer) {
+	if p != nil {
+		procDhcpRpcFreeMemory.Call(uintptr(p)) //nolint:errcheck // nothing to do on error
+	}
+}
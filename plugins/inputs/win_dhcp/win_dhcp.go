@@ -0,0 +1,216 @@
+//go:build windows
+// +build windows
+
+package win_dhcp
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Addresses or names of the DHCP servers to query, the local server if
+  ## empty. Querying remote servers requires Telegraf to run with an account
+  ## in the "DHCP Users" group of the servers.
+  # servers = []
+`
+
+// failoverStates are the names of the FSM_STATE values of a failover
+// relationship.
+var failoverStates = []string{
+	"no_state",
+	"init",
+	"startup",
+	"normal",
+	"communication_interrupted",
+	"partner_down",
+	"potential_conflict",
+	"conflict_done",
+	"resolution_interrupted",
+	"recover",
+	"recover_wait",
+	"recover_done",
+	"paused",
+	"shutdown",
+}
+
+// serverStatistics are the statistics of a DHCP server and its scopes.
+type serverStatistics struct {
+	discovers uint32
+	offers    uint32
+	requests  uint32
+	acks      uint32
+	naks      uint32
+	declines  uint32
+	releases  uint32
+	scopes    []scopeStatistics
+}
+
+// scopeStatistics are the address statistics of an IPv4 scope.
+type scopeStatistics struct {
+	subnet        uint32
+	inUse         uint32
+	free          uint32
+	pendingOffers uint32
+}
+
+// failoverRelationship is the failover relationship of a scope.
+type failoverRelationship struct {
+	name       string
+	mode       int32
+	serverType int32
+	state      int32
+	mclt       uint32
+	percentage uint32
+}
+
+// dhcpServer returns the statistics and configuration of a DHCP server.
+// Subnets are IPv4 addresses in host byte order.
+type dhcpServer interface {
+	statistics() (*serverStatistics, error)
+	scopeName(subnet uint32) (string, error)
+	// failover returns nil if the scope is not in a failover relationship.
+	failover(subnet uint32) (*failoverRelationship, error)
+}
+
+// WinDHCP collects the scope statistics of Windows DHCP servers.
+type WinDHCP struct {
+	Servers []string `toml:"servers"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	newServer func(address string) dhcpServer
+}
+
+func (w *WinDHCP) Description() string {
+	return "Collect the scope utilization and failover state of Windows DHCP servers"
+}
+
+func (w *WinDHCP) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinDHCP) Gather(acc telegraf.Accumulator) error {
+	servers := w.Servers
+	if len(servers) == 0 {
+		servers = []string{""}
+	}
+	for _, address := range servers {
+		if err := w.gatherServer(acc, address); err != nil {
+			if address != "" {
+				err = fmt.Errorf("server %q: %w", address, err)
+			}
+			acc.AddError(err)
+		}
+	}
+	return nil
+}
+
+func (w *WinDHCP) gatherServer(acc telegraf.Accumulator, address string) error {
+	server := w.newServer(address)
+	stats, err := server.statistics()
+	if err != nil {
+		return err
+	}
+
+	serverTags := make(map[string]string)
+	if address != "" {
+		serverTags["source"] = address
+	}
+	acc.AddFields("win_dhcp", map[string]interface{}{
+		"discovers": uint64(stats.discovers),
+		"offers":    uint64(stats.offers),
+		"requests":  uint64(stats.requests),
+		"acks":      uint64(stats.acks),
+		"naks":      uint64(stats.naks),
+		"declines":  uint64(stats.declines),
+		"releases":  uint64(stats.releases),
+		"scopes":    uint64(len(stats.scopes)),
+	}, serverTags)
+
+	relationships := make(map[string]bool)
+	for _, scope := range stats.scopes {
+		tags := map[string]string{"scope": ipString(scope.subnet)}
+		if address != "" {
+			tags["source"] = address
+		}
+		name, err := server.scopeName(scope.subnet)
+		if err != nil {
+			w.Log.Debugf("Querying name of scope %s failed: %v", tags["scope"], err)
+		} else if name != "" {
+			tags["scope_name"] = name
+		}
+
+		relationship, err := server.failover(scope.subnet)
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying failover relationship of scope %s failed: %w", tags["scope"], err))
+		} else if relationship != nil {
+			tags["failover_relationship"] = relationship.name
+			if !relationships[relationship.name] {
+				relationships[relationship.name] = true
+				addFailover(acc, relationship, serverTags)
+			}
+		}
+
+		fields := map[string]interface{}{
+			"addresses_in_use": uint64(scope.inUse),
+			"addresses_free":   uint64(scope.free),
+			"pending_offers":   uint64(scope.pendingOffers),
+		}
+		if total := scope.inUse + scope.free; total > 0 {
+			fields["utilization_percent"] = float64(scope.inUse) / float64(total) * 100
+		}
+		acc.AddFields("win_dhcp_scope", fields, tags)
+	}
+	return nil
+}
+
+// addFailover adds the state of the failover relationship.
+func addFailover(acc telegraf.Accumulator, relationship *failoverRelationship, serverTags map[string]string) {
+	tags := map[string]string{"relationship": relationship.name}
+	for k, v := range serverTags {
+		tags[k] = v
+	}
+	switch relationship.mode {
+	case 0:
+		tags["mode"] = "load_balance"
+	case 1:
+		tags["mode"] = "hot_standby"
+	}
+	switch relationship.serverType {
+	case 0:
+		tags["server_type"] = "primary"
+	case 1:
+		tags["server_type"] = "secondary"
+	}
+
+	state := "unknown"
+	if relationship.state >= 0 && int(relationship.state) < len(failoverStates) {
+		state = failoverStates[relationship.state]
+	}
+	fields := map[string]interface{}{
+		"state":      state,
+		"state_code": int64(relationship.state),
+		"mclt":       uint64(relationship.mclt),
+		"percentage": uint64(relationship.percentage),
+	}
+	acc.AddFields("win_dhcp_failover", fields, tags)
+}
+
+// ipString returns the IPv4 address, in host byte order, as string.
+func ipString(ip uint32) string {
+	return net.IPv4(byte(ip>>24), byte(ip>>16), byte(ip>>8), byte(ip)).String()
+}
+
+func init() {
+	inputs.Add("win_dhcp", func() telegraf.Input {
+		return &WinDHCP{
+			newServer: func(address string) dhcpServer {
+				return &apiServer{address: address}
+			},
+		}
+	})
+}
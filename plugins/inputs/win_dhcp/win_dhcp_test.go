@@ -0,0 +1,131 @@
+//go:build windows
+// +build windows
+
+package win_dhcp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeServer returns the given statistics and scope configuration.
+type fakeServer struct {
+	stats         *serverStatistics
+	err           error
+	names         map[uint32]string
+	relationships map[uint32]*failoverRelationship
+}
+
+func (f *fakeServer) statistics() (*serverStatistics, error) {
+	return f.stats, f.err
+}
+
+func (f *fakeServer) scopeName(subnet uint32) (string, error) {
+	return f.names[subnet], nil
+}
+
+func (f *fakeServer) failover(subnet uint32) (*failoverRelationship, error) {
+	return f.relationships[subnet], nil
+}
+
+func TestGather(t *testing.T) {
+	relationship := &failoverRelationship{name: "dhcp1-dhcp2", mode: 0, serverType: 0, state: 3, mclt: 3600, percentage: 50}
+	server := &fakeServer{
+		stats: &serverStatistics{
+			discovers: 100, offers: 90, requests: 80, acks: 70, naks: 1, declines: 2, releases: 3,
+			scopes: []scopeStatistics{
+				{subnet: 0xC0A80100, inUse: 150, free: 50, pendingOffers: 2},
+				{subnet: 0xC0A80200, inUse: 10, free: 90},
+				{subnet: 0x0A000000, inUse: 0, free: 0},
+			},
+		},
+		names: map[uint32]string{0xC0A80100: "Office", 0xC0A80200: "Guests"},
+		relationships: map[uint32]*failoverRelationship{
+			0xC0A80100: relationship,
+			0xC0A80200: relationship,
+		},
+	}
+	w := &WinDHCP{
+		Servers: []string{"dhcp1"},
+		Log:     testutil.Logger{},
+		newServer: func(address string) dhcpServer {
+			require.Equal(t, "dhcp1", address)
+			return server
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("win_dhcp",
+			map[string]string{"source": "dhcp1"},
+			map[string]interface{}{
+				"discovers": uint64(100),
+				"offers":    uint64(90),
+				"requests":  uint64(80),
+				"acks":      uint64(70),
+				"naks":      uint64(1),
+				"declines":  uint64(2),
+				"releases":  uint64(3),
+				"scopes":    uint64(3),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_dhcp_failover",
+			map[string]string{"source": "dhcp1", "relationship": "dhcp1-dhcp2", "mode": "load_balance", "server_type": "primary"},
+			map[string]interface{}{
+				"state":      "normal",
+				"state_code": int64(3),
+				"mclt":       uint64(3600),
+				"percentage": uint64(50),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_dhcp_scope",
+			map[string]string{"source": "dhcp1", "scope": "192.168.1.0", "scope_name": "Office", "failover_relationship": "dhcp1-dhcp2"},
+			map[string]interface{}{
+				"addresses_in_use":    uint64(150),
+				"addresses_free":      uint64(50),
+				"pending_offers":      uint64(2),
+				"utilization_percent": float64(75),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_dhcp_scope",
+			map[string]string{"source": "dhcp1", "scope": "192.168.2.0", "scope_name": "Guests", "failover_relationship": "dhcp1-dhcp2"},
+			map[string]interface{}{
+				"addresses_in_use":    uint64(10),
+				"addresses_free":      uint64(90),
+				"pending_offers":      uint64(0),
+				"utilization_percent": float64(10),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_dhcp_scope",
+			map[string]string{"source": "dhcp1", "scope": "10.0.0.0"},
+			map[string]interface{}{
+				"addresses_in_use": uint64(0),
+				"addresses_free":   uint64(0),
+				"pending_offers":   uint64(0),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherError(t *testing.T) {
+	w := &WinDHCP{
+		newServer: func(string) dhcpServer {
+			return &fakeServer{err: errors.New("access denied")}
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,292 @@
+//go:build windows
+// +build windows
+
+package win_disk_health
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Collect the reliability counters of the physical disks, e.g. the
+  ## temperature, wear and read and write errors.
+  # reliability_counters = true
+
+  ## Collect the health of the Storage Spaces pools and virtual disks.
+  # storage_spaces = true
+`
+
+const namespace = `root\Microsoft\Windows\Storage`
+
+// Names of the health states, media and bus types and operational states of
+// the storage objects.
+var (
+	healthStates = map[int64]string{
+		0: "healthy",
+		1: "warning",
+		2: "unhealthy",
+		5: "unknown",
+	}
+	mediaTypes = map[string]string{
+		"0": "unspecified",
+		"3": "hdd",
+		"4": "ssd",
+		"5": "scm",
+	}
+	busTypes = map[string]string{
+		"0":  "unknown",
+		"1":  "scsi",
+		"2":  "atapi",
+		"3":  "ata",
+		"4":  "1394",
+		"5":  "ssa",
+		"6":  "fibre_channel",
+		"7":  "usb",
+		"8":  "raid",
+		"9":  "iscsi",
+		"10": "sas",
+		"11": "sata",
+		"12": "sd",
+		"13": "mmc",
+		"14": "virtual",
+		"15": "file_backed_virtual",
+		"16": "storage_spaces",
+		"17": "nvme",
+		"18": "scm",
+		"19": "ufs",
+	}
+	operationalStates = map[int64]string{
+		0:  "unknown",
+		1:  "other",
+		2:  "ok",
+		3:  "degraded",
+		4:  "stressed",
+		5:  "predictive_failure",
+		6:  "error",
+		7:  "non_recoverable_error",
+		8:  "starting",
+		9:  "stopping",
+		10: "stopped",
+		11: "in_service",
+		12: "no_contact",
+		13: "lost_communication",
+		14: "aborted",
+		15: "dormant",
+		16: "supporting_entity_in_error",
+		17: "completed",
+		18: "power_mode",
+	}
+)
+
+// WinDiskHealth collects the health and reliability counters of the physical
+// disks and the health of the Storage Spaces pools and virtual disks.
+type WinDiskHealth struct {
+	ReliabilityCounters bool `toml:"reliability_counters"`
+	StorageSpaces       bool `toml:"storage_spaces"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	query wmi.QueryFunc
+}
+
+// pool is a Storage Spaces pool.
+type pool struct {
+	objectID string
+	name     string
+}
+
+func (w *WinDiskHealth) Description() string {
+	return "Collect the health of physical disks and Storage Spaces"
+}
+
+func (w *WinDiskHealth) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinDiskHealth) Gather(acc telegraf.Accumulator) error {
+	var pools []pool
+	if w.StorageSpaces {
+		var err error
+		if pools, err = w.gatherPools(acc); err != nil {
+			acc.AddError(fmt.Errorf("querying storage pools failed: %w", err))
+		}
+	}
+
+	// The physical disks are tagged by the pool they are a member of.
+	members := make(map[string]string)
+	for _, p := range pools {
+		err := w.query(namespace, associatorsQuery("MSFT_StoragePool", p.objectID, "MSFT_PhysicalDisk"),
+			func(properties map[string]interface{}) error {
+				members[fmt.Sprint(properties["ObjectId"])] = p.name
+				return nil
+			})
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying disks of pool %q failed: %w", p.name, err))
+		}
+		if err := w.gatherVirtualDisks(acc, p); err != nil {
+			acc.AddError(fmt.Errorf("querying virtual disks of pool %q failed: %w", p.name, err))
+		}
+	}
+
+	if err := w.gatherPhysicalDisks(acc, members); err != nil {
+		return fmt.Errorf("querying physical disks failed: %w", err)
+	}
+	return nil
+}
+
+func (w *WinDiskHealth) gatherPhysicalDisks(acc telegraf.Accumulator, members map[string]string) error {
+	type disk struct {
+		objectID string
+		fields   map[string]interface{}
+		tags     map[string]string
+	}
+	var disks []disk
+	err := w.query(namespace,
+		"SELECT ObjectId, DeviceId, FriendlyName, SerialNumber, MediaType, BusType, HealthStatus, OperationalStatus, Size FROM MSFT_PhysicalDisk",
+		func(p map[string]interface{}) error {
+			objectID := fmt.Sprint(p["ObjectId"])
+			tags := map[string]string{
+				"device_id":     fmt.Sprint(p["DeviceId"]),
+				"friendly_name": fmt.Sprint(p["FriendlyName"]),
+				"serial_number": strings.TrimSpace(fmt.Sprint(p["SerialNumber"])),
+				"media_type":    lookup(mediaTypes, p["MediaType"]),
+				"bus_type":      lookup(busTypes, p["BusType"]),
+			}
+			if name, ok := members[objectID]; ok {
+				tags["pool"] = name
+			}
+			fields := healthFields(p)
+			wmi.AddUintFields(fields, p, map[string]string{"Size": "size"})
+			disks = append(disks, disk{objectID: objectID, fields: fields, tags: tags})
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range disks {
+		if w.ReliabilityCounters {
+			query := associatorsQuery("MSFT_PhysicalDisk", d.objectID, "MSFT_StorageReliabilityCounter")
+			err := w.query(namespace, query, func(p map[string]interface{}) error {
+				wmi.AddUintFields(d.fields, p, map[string]string{
+					"Temperature":            "temperature_celsius",
+					"TemperatureMax":         "temperature_max_celsius",
+					"Wear":                   "wear_percent",
+					"PowerOnHours":           "power_on_hours",
+					"StartStopCycleCount":    "start_stop_cycles",
+					"ReadErrorsTotal":        "read_errors_total",
+					"ReadErrorsCorrected":    "read_errors_corrected",
+					"ReadErrorsUncorrected":  "read_errors_uncorrected",
+					"WriteErrorsTotal":       "write_errors_total",
+					"WriteErrorsCorrected":   "write_errors_corrected",
+					"WriteErrorsUncorrected": "write_errors_uncorrected",
+					"ReadLatencyMax":         "read_latency_max_ms",
+					"WriteLatencyMax":        "write_latency_max_ms",
+				})
+				return nil
+			})
+			if err != nil {
+				acc.AddError(fmt.Errorf("querying reliability counters of disk %q failed: %w", d.tags["serial_number"], err))
+			}
+		}
+		acc.AddFields("win_disk_health", d.fields, d.tags)
+	}
+	return nil
+}
+
+// gatherPools adds the health of the pools and returns them, the primordial
+// pools of the unpooled disks are skipped.
+func (w *WinDiskHealth) gatherPools(acc telegraf.Accumulator) ([]pool, error) {
+	var pools []pool
+	err := w.query(namespace,
+		"SELECT ObjectId, FriendlyName, HealthStatus, OperationalStatus, IsReadOnly, Size, AllocatedSize FROM MSFT_StoragePool WHERE IsPrimordial = FALSE",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["FriendlyName"])
+			fields := healthFields(p)
+			wmi.AddUintFields(fields, p, map[string]string{
+				"Size":          "size",
+				"AllocatedSize": "allocated_size",
+			})
+			if readOnly, ok := p["IsReadOnly"].(bool); ok {
+				fields["read_only"] = readOnly
+			}
+			acc.AddFields("win_disk_health_pool", fields, map[string]string{"pool": name})
+			pools = append(pools, pool{objectID: fmt.Sprint(p["ObjectId"]), name: name})
+			return nil
+		})
+	return pools, err
+}
+
+func (w *WinDiskHealth) gatherVirtualDisks(acc telegraf.Accumulator, p pool) error {
+	return w.query(namespace, associatorsQuery("MSFT_StoragePool", p.objectID, "MSFT_VirtualDisk"),
+		func(properties map[string]interface{}) error {
+			fields := healthFields(properties)
+			wmi.AddUintFields(fields, properties, map[string]string{
+				"Size":          "size",
+				"AllocatedSize": "allocated_size",
+			})
+			tags := map[string]string{
+				"pool":         p.name,
+				"virtual_disk": fmt.Sprint(properties["FriendlyName"]),
+				"resiliency":   fmt.Sprint(properties["ResiliencySettingName"]),
+			}
+			acc.AddFields("win_disk_health_virtual_disk", fields, tags)
+			return nil
+		})
+}
+
+// associatorsQuery returns the query of the objects of the result class
+// associated with the object of the class with the object ID.
+func associatorsQuery(class, objectID, resultClass string) string {
+	id := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(objectID)
+	return fmt.Sprintf(`ASSOCIATORS OF {%s.ObjectId="%s"} WHERE ResultClass = %s`, class, id, resultClass)
+}
+
+// healthFields returns the health status and the first operational status of
+// the storage object and their names.
+func healthFields(p map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if code, err := strconv.ParseInt(fmt.Sprint(p["HealthStatus"]), 10, 64); err == nil {
+		health, ok := healthStates[code]
+		if !ok {
+			health = "unknown"
+		}
+		fields["health"] = health
+		fields["health_code"] = code
+	}
+	if states, ok := p["OperationalStatus"].([]interface{}); ok && len(states) > 0 {
+		if code, err := strconv.ParseInt(fmt.Sprint(states[0]), 10, 64); err == nil {
+			state, ok := operationalStates[code]
+			if !ok {
+				state = "unknown"
+			}
+			fields["operational_status"] = state
+			fields["operational_status_code"] = code
+		}
+	}
+	return fields
+}
+
+// lookup returns the name of the property value, "unknown" if not found.
+func lookup(names map[string]string, value interface{}) string {
+	if name, ok := names[fmt.Sprint(value)]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func init() {
+	inputs.Add("win_disk_health", func() telegraf.Input {
+		return &WinDiskHealth{
+			ReliabilityCounters: true,
+			StorageSpaces:       true,
+			query:               (&wmi.Connection{}).Query,
+		}
+	})
+}
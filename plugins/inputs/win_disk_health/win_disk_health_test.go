@@ -0,0 +1,191 @@
+//go:build windows
+// +build windows
+
+package win_disk_health
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeQuery returns the objects of the first query containing the key.
+func fakeQuery(keys []string, objects map[string][]map[string]interface{}) wmi.QueryFunc {
+	return func(_, query string, fn func(properties map[string]interface{}) error) error {
+		for _, key := range keys {
+			if !strings.Contains(query, key) {
+				continue
+			}
+			for _, o := range objects[key] {
+				if err := fn(o); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return nil
+	}
+}
+
+func TestAssociatorsQuery(t *testing.T) {
+	require.Equal(t,
+		`ASSOCIATORS OF {MSFT_StoragePool.ObjectId="{1}\\\\HOST\\root/Microsoft/Windows/Storage/Providers_v2\\SPACES_StoragePool.ObjectId=\"{a}\""} WHERE ResultClass = MSFT_VirtualDisk`,
+		associatorsQuery("MSFT_StoragePool", `{1}\\HOST\root/Microsoft/Windows/Storage/Providers_v2\SPACES_StoragePool.ObjectId="{a}"`, "MSFT_VirtualDisk"),
+	)
+}
+
+func TestGather(t *testing.T) {
+	keys := []string{
+		`MSFT_StoragePool.ObjectId="pool1"} WHERE ResultClass = MSFT_PhysicalDisk`,
+		`MSFT_StoragePool.ObjectId="pool1"} WHERE ResultClass = MSFT_VirtualDisk`,
+		`MSFT_PhysicalDisk.ObjectId="disk1"}`,
+		`MSFT_PhysicalDisk.ObjectId="disk2"}`,
+		"FROM MSFT_StoragePool",
+		"FROM MSFT_PhysicalDisk",
+	}
+	w := &WinDiskHealth{
+		ReliabilityCounters: true,
+		StorageSpaces:       true,
+		Log:                 testutil.Logger{},
+		query: fakeQuery(keys, map[string][]map[string]interface{}{
+			"FROM MSFT_StoragePool": {
+				{
+					"ObjectId":          "pool1",
+					"FriendlyName":      "Pool",
+					"HealthStatus":      uint16(1),
+					"OperationalStatus": []interface{}{uint16(3)},
+					"IsReadOnly":        false,
+					"Size":              "4000787030016",
+					"AllocatedSize":     "1073741824000",
+				},
+			},
+			`MSFT_StoragePool.ObjectId="pool1"} WHERE ResultClass = MSFT_PhysicalDisk`: {
+				{"ObjectId": "disk1"},
+			},
+			`MSFT_StoragePool.ObjectId="pool1"} WHERE ResultClass = MSFT_VirtualDisk`: {
+				{
+					"FriendlyName":          "Data",
+					"ResiliencySettingName": "Mirror",
+					"HealthStatus":          uint16(0),
+					"OperationalStatus":     []interface{}{uint16(2)},
+					"Size":                  "1073741824000",
+					"AllocatedSize":         "1073741824000",
+				},
+			},
+			"FROM MSFT_PhysicalDisk": {
+				{
+					"ObjectId":          "disk1",
+					"DeviceId":          "1",
+					"FriendlyName":      "SAMSUNG MZ7LH960",
+					"SerialNumber":      " S45NNE0M ",
+					"MediaType":         uint16(4),
+					"BusType":           uint16(11),
+					"HealthStatus":      uint16(1),
+					"OperationalStatus": []interface{}{uint16(5)},
+					"Size":              "960197124096",
+				},
+				{
+					"ObjectId":          "disk2",
+					"DeviceId":          "0",
+					"FriendlyName":      "NVMe Disk",
+					"SerialNumber":      "0025_3852",
+					"MediaType":         uint16(4),
+					"BusType":           uint16(17),
+					"HealthStatus":      uint16(0),
+					"OperationalStatus": []interface{}{uint16(2)},
+					"Size":              "512110190592",
+				},
+			},
+			`MSFT_PhysicalDisk.ObjectId="disk1"}`: {
+				{
+					"Temperature":           uint8(38),
+					"Wear":                  uint8(12),
+					"PowerOnHours":          uint32(20000),
+					"ReadErrorsUncorrected": "3",
+				},
+			},
+		}),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_disk_health_pool",
+			map[string]string{"pool": "Pool"},
+			map[string]interface{}{
+				"health":                  "warning",
+				"health_code":             int64(1),
+				"operational_status":      "degraded",
+				"operational_status_code": int64(3),
+				"read_only":               false,
+				"size":                    uint64(4000787030016),
+				"allocated_size":          uint64(1073741824000),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_disk_health_virtual_disk",
+			map[string]string{"pool": "Pool", "virtual_disk": "Data", "resiliency": "Mirror"},
+			map[string]interface{}{
+				"health":                  "healthy",
+				"health_code":             int64(0),
+				"operational_status":      "ok",
+				"operational_status_code": int64(2),
+				"size":                    uint64(1073741824000),
+				"allocated_size":          uint64(1073741824000),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_disk_health",
+			map[string]string{
+				"device_id":     "1",
+				"friendly_name": "SAMSUNG MZ7LH960",
+				"serial_number": "S45NNE0M",
+				"media_type":    "ssd",
+				"bus_type":      "sata",
+				"pool":          "Pool",
+			},
+			map[string]interface{}{
+				"health":                  "warning",
+				"health_code":             int64(1),
+				"operational_status":      "predictive_failure",
+				"operational_status_code": int64(5),
+				"size":                    uint64(960197124096),
+				"temperature_celsius":     uint64(38),
+				"wear_percent":            uint64(12),
+				"power_on_hours":          uint64(20000),
+				"read_errors_uncorrected": uint64(3),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_disk_health",
+			map[string]string{
+				"device_id":     "0",
+				"friendly_name": "NVMe Disk",
+				"serial_number": "0025_3852",
+				"media_type":    "ssd",
+				"bus_type":      "nvme",
+			},
+			map[string]interface{}{
+				"health":                  "healthy",
+				"health_code":             int64(0),
+				"operational_status":      "ok",
+				"operational_status_code": int64(2),
+				"size":                    uint64(512110190592),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,164 @@
+//go:build windows
+// +build windows
+
+package win_dns
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the zones to collect, all if empty. Globs accepted.
+  # zone_names = []
+`
+
+// Namespaces of the queried classes.
+const (
+	cimv2Namespace = `root\cimv2`
+	dnsNamespace   = `root\MicrosoftDNS`
+)
+
+// WinDNS collects the statistics of a Windows DNS server.
+type WinDNS struct {
+	ZoneNames []string `toml:"zone_names"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	zoneFilter filter.Filter
+	query      wmi.QueryFunc
+}
+
+func (w *WinDNS) Description() string {
+	return "Collect query, recursion, cache and zone statistics of a Windows DNS server"
+}
+
+func (w *WinDNS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinDNS) Init() error {
+	f, err := filter.Compile(w.ZoneNames)
+	if err != nil {
+		return fmt.Errorf("compiling zone_names failed: %w", err)
+	}
+	w.zoneFilter = f
+	return nil
+}
+
+func (w *WinDNS) Gather(acc telegraf.Accumulator) error {
+	if err := w.gatherServer(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying server counters failed: %w", err))
+	}
+	if err := w.gatherQueryTypes(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying query statistics failed: %w", err))
+	}
+	if err := w.gatherZones(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying zones failed: %w", err))
+	}
+	return nil
+}
+
+// gatherServer adds the counters of the DNS server.
+func (w *WinDNS) gatherServer(acc telegraf.Accumulator) error {
+	return w.query(cimv2Namespace,
+		"SELECT TotalQueryReceivedPersec, TotalResponseSentPersec, UDPQueryReceivedPersec, TCPQueryReceivedPersec, RecursiveQueriesPersec, RecursiveQueryFailurePersec, RecursiveTimeOutPersec, CachingMemory, DynamicUpdateReceivedPersec, ZoneTransferRequestReceived, ZoneTransferSuccess, ZoneTransferFailure, ZoneTransferSOARequestSent FROM Win32_PerfFormattedData_DNS_DNS",
+		func(p map[string]interface{}) error {
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"TotalQueryReceivedPersec":    "queries_per_sec",
+				"TotalResponseSentPersec":     "responses_per_sec",
+				"UDPQueryReceivedPersec":      "udp_queries_per_sec",
+				"TCPQueryReceivedPersec":      "tcp_queries_per_sec",
+				"RecursiveQueriesPersec":      "recursive_queries_per_sec",
+				"RecursiveQueryFailurePersec": "recursive_failures_per_sec",
+				"RecursiveTimeOutPersec":      "recursive_timeouts_per_sec",
+				"CachingMemory":               "cache_memory_bytes",
+				"DynamicUpdateReceivedPersec": "dynamic_updates_per_sec",
+				"ZoneTransferRequestReceived": "zone_transfer_requests_received",
+				"ZoneTransferSuccess":         "zone_transfer_success",
+				"ZoneTransferFailure":         "zone_transfer_failure",
+				"ZoneTransferSOARequestSent":  "zone_transfer_soa_requests_sent",
+			})
+			acc.AddFields("win_dns", fields, nil)
+			return nil
+		})
+}
+
+// gatherQueryTypes adds the number of queries received by record type. The
+// statistics of the record types are named 'Type<type>', e.g. 'TypeA'.
+func (w *WinDNS) gatherQueryTypes(acc telegraf.Accumulator) error {
+	return w.query(dnsNamespace,
+		"SELECT Name, Value FROM MicrosoftDNS_Statistic WHERE CollectionName = 'Query2'",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["Name"])
+			if !strings.HasPrefix(name, "Type") || len(name) == len("Type") {
+				return nil
+			}
+			queries, err := wmi.Uint64(p["Value"])
+			if err != nil {
+				return nil
+			}
+			tags := map[string]string{"type": strings.ToUpper(strings.TrimPrefix(name, "Type"))}
+			acc.AddFields("win_dns_query", map[string]interface{}{"queries": queries}, tags)
+			return nil
+		})
+}
+
+// gatherZones adds the state and SOA serial number of the zones.
+func (w *WinDNS) gatherZones(acc telegraf.Accumulator) error {
+	serials := make(map[string]uint64)
+	err := w.query(dnsNamespace,
+		"SELECT ContainerName, OwnerName, SerialNumber FROM MicrosoftDNS_SOAType",
+		func(p map[string]interface{}) error {
+			zone := fmt.Sprint(p["ContainerName"])
+			if !strings.EqualFold(zone, fmt.Sprint(p["OwnerName"])) {
+				return nil
+			}
+			if serial, err := wmi.Uint64(p["SerialNumber"]); err == nil {
+				serials[strings.ToLower(zone)] = serial
+			}
+			return nil
+		})
+	if err != nil {
+		acc.AddError(fmt.Errorf("querying SOA records failed: %w", err))
+	}
+
+	return w.query(dnsNamespace,
+		"SELECT Name, ZoneType, Paused, Shutdown, DsIntegrated, Reverse FROM MicrosoftDNS_Zone",
+		func(p map[string]interface{}) error {
+			zone := fmt.Sprint(p["Name"])
+			if zone == "TrustAnchors" || (w.zoneFilter != nil && !w.zoneFilter.Match(zone)) {
+				return nil
+			}
+
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{"ZoneType": "zone_type"})
+			for property, field := range map[string]string{
+				"Paused":       "paused",
+				"Shutdown":     "shutdown",
+				"DsIntegrated": "ds_integrated",
+				"Reverse":      "reverse",
+			} {
+				if v, ok := p[property].(bool); ok {
+					fields[field] = v
+				}
+			}
+			if serial, ok := serials[strings.ToLower(zone)]; ok {
+				fields["soa_serial"] = serial
+			}
+			acc.AddFields("win_dns_zone", fields, map[string]string{"zone": zone})
+			return nil
+		})
+}
+
+func init() {
+	inputs.Add("win_dns", func() telegraf.Input {
+		return &WinDNS{query: (&wmi.Connection{}).Query}
+	})
+}
@@ -0,0 +1,112 @@
+//go:build windows
+// +build windows
+
+package win_dns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	w := &WinDNS{
+		ZoneNames: []string{"example.com", "*.in-addr.arpa"},
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"Win32_PerfFormattedData_DNS_DNS": {
+				{
+					"TotalQueryReceivedPersec":    int32(120),
+					"TotalResponseSentPersec":     int32(118),
+					"UDPQueryReceivedPersec":      int32(115),
+					"TCPQueryReceivedPersec":      int32(5),
+					"RecursiveQueriesPersec":      int32(40),
+					"RecursiveQueryFailurePersec": int32(1),
+					"RecursiveTimeOutPersec":      int32(2),
+					"CachingMemory":               int32(1048576),
+					"DynamicUpdateReceivedPersec": int32(3),
+					"ZoneTransferRequestReceived": int32(10),
+					"ZoneTransferSuccess":         int32(9),
+					"ZoneTransferFailure":         int32(1),
+					"ZoneTransferSOARequestSent":  int32(20),
+				},
+			},
+			"MicrosoftDNS_Statistic": {
+				{"Name": "TotalQueries", "Value": int32(1000)},
+				{"Name": "TypeA", "Value": int32(600)},
+				{"Name": "TypeAaaa", "Value": int32(300)},
+				{"Name": "Type", "Value": int32(1)},
+			},
+			"MicrosoftDNS_SOAType": {
+				{"ContainerName": "example.com", "OwnerName": "example.com", "SerialNumber": int32(2021101401)},
+				{"ContainerName": "example.com", "OwnerName": "sub.example.com", "SerialNumber": int32(1)},
+			},
+			"MicrosoftDNS_Zone": {
+				{"Name": "example.com", "ZoneType": int32(1), "Paused": false, "Shutdown": false, "DsIntegrated": true, "Reverse": false},
+				{"Name": "1.168.192.in-addr.arpa", "ZoneType": int32(2), "Paused": false, "Shutdown": true, "DsIntegrated": false, "Reverse": true},
+				{"Name": "other.com", "ZoneType": int32(1), "Paused": false, "Shutdown": false, "DsIntegrated": true, "Reverse": false},
+				{"Name": "TrustAnchors", "ZoneType": int32(1), "Paused": false, "Shutdown": false, "DsIntegrated": true, "Reverse": false},
+			},
+		}),
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("win_dns",
+			map[string]string{},
+			map[string]interface{}{
+				"queries_per_sec":                 uint64(120),
+				"responses_per_sec":               uint64(118),
+				"udp_queries_per_sec":             uint64(115),
+				"tcp_queries_per_sec":             uint64(5),
+				"recursive_queries_per_sec":       uint64(40),
+				"recursive_failures_per_sec":      uint64(1),
+				"recursive_timeouts_per_sec":      uint64(2),
+				"cache_memory_bytes":              uint64(1048576),
+				"dynamic_updates_per_sec":         uint64(3),
+				"zone_transfer_requests_received": uint64(10),
+				"zone_transfer_success":           uint64(9),
+				"zone_transfer_failure":           uint64(1),
+				"zone_transfer_soa_requests_sent": uint64(20),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_dns_query",
+			map[string]string{"type": "A"},
+			map[string]interface{}{"queries": uint64(600)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_dns_query",
+			map[string]string{"type": "AAAA"},
+			map[string]interface{}{"queries": uint64(300)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_dns_zone",
+			map[string]string{"zone": "example.com"},
+			map[string]interface{}{
+				"zone_type":     uint64(1),
+				"paused":        false,
+				"shutdown":      false,
+				"ds_integrated": true,
+				"reverse":       false,
+				"soa_serial":    uint64(2021101401),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_dns_zone",
+			map[string]string{"zone": "1.168.192.in-addr.arpa"},
+			map[string]interface{}{
+				"zone_type":     uint64(2),
+				"paused":        false,
+				"shutdown":      true,
+				"ds_integrated": false,
+				"reverse":       true,
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -9,6 +9,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"reflect"
@@ -138,6 +139,10 @@ func (w *WinEventLog) Gather(acc telegraf.Accumulator) error {
 	var err error
 	if w.subscription == 0 {
 		w.subscription, err = w.evtSubscribe(w.EventlogName, w.Query)
+		if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+			return fmt.Errorf("Windows Event Log subscription error: access to %q denied, "+
+				"reading it requires administrator rights or membership in the \"Event Log Readers\" group", w.EventlogName)
+		}
 		if err != nil {
 			return fmt.Errorf("Windows Event Log subscription error: %v", err.Error())
 		}
@@ -0,0 +1,239 @@
+//go:build windows
+// +build windows
+
+package win_gpu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/process"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Collect the engine utilization and memory usage per process in addition
+  ## to the totals of the adapters. Adds a series per process using the GPU.
+  # per_process = false
+`
+
+const namespace = `root\cimv2`
+
+// instance is the parsed name of a GPU counter instance, e.g.
+// 'pid_1234_luid_0x00000000_0x0000D1F0_phys_0_eng_0_engtype_3D'.
+type instance struct {
+	pid        string
+	adapter    string
+	phys       string
+	engine     string
+	engineType string
+}
+
+// WinGPU collects the engine utilization and memory usage of the GPUs from
+// the GPU performance counters.
+type WinGPU struct {
+	PerProcess bool `toml:"per_process"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	query       wmi.QueryFunc
+	processName func(pid string) string
+}
+
+func (w *WinGPU) Description() string {
+	return "Collect the engine utilization and memory usage of the GPUs"
+}
+
+func (w *WinGPU) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinGPU) Gather(acc telegraf.Accumulator) error {
+	// The process names are looked up once per process and interval
+	names := make(map[string]string)
+	processTags := func(i instance) map[string]string {
+		name, ok := names[i.pid]
+		if !ok {
+			name = w.processName(i.pid)
+			names[i.pid] = name
+		}
+		tags := map[string]string{"pid": i.pid, "adapter": i.adapter, "phys": i.phys}
+		if name != "" {
+			tags["process_name"] = name
+		}
+		return tags
+	}
+
+	if err := w.gatherEngines(acc, processTags); err != nil {
+		acc.AddError(fmt.Errorf("querying GPU engines failed: %w", err))
+	}
+	if err := w.gatherAdapterMemory(acc); err != nil {
+		acc.AddError(fmt.Errorf("querying GPU adapter memory failed: %w", err))
+	}
+	if w.PerProcess {
+		if err := w.gatherProcessMemory(acc, processTags); err != nil {
+			acc.AddError(fmt.Errorf("querying GPU process memory failed: %w", err))
+		}
+	}
+	return nil
+}
+
+// gatherEngines adds the utilization of the engines summed over the
+// processes, and per process and engine type if enabled.
+func (w *WinGPU) gatherEngines(acc telegraf.Accumulator, processTags func(instance) map[string]string) error {
+	type engineKey struct {
+		adapter, phys, engine, engineType string
+	}
+	type processKey struct {
+		pid, adapter, phys, engineType string
+	}
+	var engines []engineKey
+	var processes []processKey
+	engineUsage := make(map[engineKey]float64)
+	processUsage := make(map[processKey]float64)
+	err := w.query(namespace, "SELECT Name, UtilizationPercentage FROM Win32_PerfFormattedData_GPUPerformanceCounters_GPUEngine",
+		func(p map[string]interface{}) error {
+			i, ok := parseInstance(fmt.Sprint(p["Name"]))
+			if !ok {
+				return nil
+			}
+			usage, err := wmi.Float64(p["UtilizationPercentage"])
+			if err != nil {
+				return nil
+			}
+
+			ek := engineKey{i.adapter, i.phys, i.engine, i.engineType}
+			if _, ok := engineUsage[ek]; !ok {
+				engines = append(engines, ek)
+			}
+			engineUsage[ek] += usage
+
+			if w.PerProcess && i.pid != "" {
+				pk := processKey{i.pid, i.adapter, i.phys, i.engineType}
+				if _, ok := processUsage[pk]; !ok {
+					processes = append(processes, pk)
+				}
+				processUsage[pk] += usage
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range engines {
+		tags := map[string]string{
+			"adapter":     k.adapter,
+			"phys":        k.phys,
+			"engine":      k.engine,
+			"engine_type": k.engineType,
+		}
+		acc.AddFields("win_gpu_engine", map[string]interface{}{"utilization_percent": engineUsage[k]}, tags)
+	}
+	for _, k := range processes {
+		tags := processTags(instance{pid: k.pid, adapter: k.adapter, phys: k.phys})
+		tags["engine_type"] = k.engineType
+		acc.AddFields("win_gpu_process", map[string]interface{}{"utilization_percent": processUsage[k]}, tags)
+	}
+	return nil
+}
+
+func (w *WinGPU) gatherAdapterMemory(acc telegraf.Accumulator) error {
+	return w.query(namespace, "SELECT Name, DedicatedUsage, SharedUsage, TotalCommitted FROM Win32_PerfFormattedData_GPUPerformanceCounters_GPUAdapterMemory",
+		func(p map[string]interface{}) error {
+			i, ok := parseInstance(fmt.Sprint(p["Name"]))
+			if !ok {
+				return nil
+			}
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"DedicatedUsage": "dedicated_usage_bytes",
+				"SharedUsage":    "shared_usage_bytes",
+				"TotalCommitted": "total_committed_bytes",
+			})
+			acc.AddFields("win_gpu_adapter_memory", fields, map[string]string{"adapter": i.adapter, "phys": i.phys})
+			return nil
+		})
+}
+
+func (w *WinGPU) gatherProcessMemory(acc telegraf.Accumulator, processTags func(instance) map[string]string) error {
+	return w.query(namespace, "SELECT Name, DedicatedUsage, SharedUsage, LocalUsage, NonLocalUsage, TotalCommitted FROM Win32_PerfFormattedData_GPUPerformanceCounters_GPUProcessMemory",
+		func(p map[string]interface{}) error {
+			i, ok := parseInstance(fmt.Sprint(p["Name"]))
+			if !ok || i.pid == "" {
+				return nil
+			}
+			fields := make(map[string]interface{})
+			wmi.AddUintFields(fields, p, map[string]string{
+				"DedicatedUsage": "dedicated_usage_bytes",
+				"SharedUsage":    "shared_usage_bytes",
+				"LocalUsage":     "local_usage_bytes",
+				"NonLocalUsage":  "non_local_usage_bytes",
+				"TotalCommitted": "total_committed_bytes",
+			})
+			acc.AddFields("win_gpu_process_memory", fields, processTags(i))
+			return nil
+		})
+}
+
+// parseInstance parses the name of the counter instance, false is returned
+// if the name has no adapter.
+func parseInstance(name string) (instance, bool) {
+	var i instance
+	parts := strings.Split(name, "_")
+	for n := 0; n < len(parts)-1; n++ {
+		switch parts[n] {
+		case "pid":
+			n++
+			i.pid = parts[n]
+		case "luid":
+			// The LUID consists of its high and low part
+			if n+2 >= len(parts) {
+				return i, false
+			}
+			i.adapter = parts[n+1] + "_" + parts[n+2]
+			n += 2
+		case "phys":
+			n++
+			i.phys = parts[n]
+		case "eng":
+			n++
+			i.engine = parts[n]
+		case "engtype":
+			// The engine type is last and may contain underscores
+			i.engineType = strings.Join(parts[n+1:], "_")
+			n = len(parts)
+		}
+	}
+	return i, i.adapter != ""
+}
+
+// localProcessName returns the name of the process, empty if not found.
+func localProcessName(pid string) string {
+	id, err := strconv.ParseInt(pid, 10, 32)
+	if err != nil {
+		return ""
+	}
+	p, err := process.NewProcess(int32(id))
+	if err != nil {
+		return ""
+	}
+	name, err := p.Name()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+func init() {
+	inputs.Add("win_gpu", func() telegraf.Input {
+		return &WinGPU{
+			query:       (&wmi.Connection{}).Query,
+			processName: localProcessName,
+		}
+	})
+}
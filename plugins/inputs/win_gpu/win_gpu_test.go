@@ -0,0 +1,175 @@
+//go:build windows
+// +build windows
+
+package win_gpu
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeQuery returns the objects of the class of the query.
+func fakeQuery(objects map[string][]map[string]interface{}) wmi.QueryFunc {
+	return func(_, query string, fn func(properties map[string]interface{}) error) error {
+		for class, list := range objects {
+			if !strings.HasSuffix(query, "_"+class) {
+				continue
+			}
+			for _, o := range list {
+				if err := fn(o); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func TestParseInstance(t *testing.T) {
+	i, ok := parseInstance("pid_1234_luid_0x00000000_0x0000D1F0_phys_0_eng_5_engtype_Compute_1")
+	require.True(t, ok)
+	require.Equal(t, instance{
+		pid:        "1234",
+		adapter:    "0x00000000_0x0000D1F0",
+		phys:       "0",
+		engine:     "5",
+		engineType: "Compute_1",
+	}, i)
+
+	i, ok = parseInstance("luid_0x00000000_0x0000D1F0_phys_0")
+	require.True(t, ok)
+	require.Equal(t, instance{adapter: "0x00000000_0x0000D1F0", phys: "0"}, i)
+
+	_, ok = parseInstance("_Total")
+	require.False(t, ok)
+}
+
+var objects = map[string][]map[string]interface{}{
+	"GPUEngine": {
+		{"Name": "pid_1234_luid_0x00000000_0x0000D1F0_phys_0_eng_0_engtype_3D", "UtilizationPercentage": "40"},
+		{"Name": "pid_5678_luid_0x00000000_0x0000D1F0_phys_0_eng_0_engtype_3D", "UtilizationPercentage": "15"},
+		{"Name": "pid_1234_luid_0x00000000_0x0000D1F0_phys_0_eng_3_engtype_VideoDecode", "UtilizationPercentage": "7"},
+	},
+	"GPUAdapterMemory": {
+		{"Name": "luid_0x00000000_0x0000D1F0_phys_0", "DedicatedUsage": "1073741824", "SharedUsage": "134217728", "TotalCommitted": "1342177280"},
+	},
+	"GPUProcessMemory": {
+		{
+			"Name":           "pid_1234_luid_0x00000000_0x0000D1F0_phys_0",
+			"DedicatedUsage": "805306368",
+			"SharedUsage":    "67108864",
+			"LocalUsage":     "805306368",
+			"NonLocalUsage":  "67108864",
+			"TotalCommitted": "939524096",
+		},
+	},
+}
+
+func TestGather(t *testing.T) {
+	w := &WinGPU{
+		Log:   testutil.Logger{},
+		query: fakeQuery(objects),
+		processName: func(string) string {
+			require.FailNow(t, "process name looked up")
+			return ""
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_gpu_engine",
+			map[string]string{"adapter": "0x00000000_0x0000D1F0", "phys": "0", "engine": "0", "engine_type": "3D"},
+			map[string]interface{}{"utilization_percent": float64(55)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_gpu_engine",
+			map[string]string{"adapter": "0x00000000_0x0000D1F0", "phys": "0", "engine": "3", "engine_type": "VideoDecode"},
+			map[string]interface{}{"utilization_percent": float64(7)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_gpu_adapter_memory",
+			map[string]string{"adapter": "0x00000000_0x0000D1F0", "phys": "0"},
+			map[string]interface{}{
+				"dedicated_usage_bytes": uint64(1073741824),
+				"shared_usage_bytes":    uint64(134217728),
+				"total_committed_bytes": uint64(1342177280),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherPerProcess(t *testing.T) {
+	lookups := 0
+	w := &WinGPU{
+		PerProcess: true,
+		Log:        testutil.Logger{},
+		query:      fakeQuery(objects),
+		processName: func(pid string) string {
+			lookups++
+			if pid == "1234" {
+				return "game.exe"
+			}
+			return ""
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Equal(t, 2, lookups)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_gpu_process",
+			map[string]string{"pid": "1234", "process_name": "game.exe", "adapter": "0x00000000_0x0000D1F0", "phys": "0", "engine_type": "3D"},
+			map[string]interface{}{"utilization_percent": float64(40)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_gpu_process",
+			map[string]string{"pid": "5678", "adapter": "0x00000000_0x0000D1F0", "phys": "0", "engine_type": "3D"},
+			map[string]interface{}{"utilization_percent": float64(15)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_gpu_process",
+			map[string]string{"pid": "1234", "process_name": "game.exe", "adapter": "0x00000000_0x0000D1F0", "phys": "0", "engine_type": "VideoDecode"},
+			map[string]interface{}{"utilization_percent": float64(7)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_gpu_process_memory",
+			map[string]string{"pid": "1234", "process_name": "game.exe", "adapter": "0x00000000_0x0000D1F0", "phys": "0"},
+			map[string]interface{}{
+				"dedicated_usage_bytes": uint64(805306368),
+				"shared_usage_bytes":    uint64(67108864),
+				"local_usage_bytes":     uint64(805306368),
+				"non_local_usage_bytes": uint64(67108864),
+				"total_committed_bytes": uint64(939524096),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	var metrics []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if strings.HasPrefix(m.Name(), "win_gpu_process") {
+			metrics = append(metrics, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime())
+}
@@ -0,0 +1,183 @@
+//go:build windows
+// +build windows
+
+package win_netstat
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Classes of the tables of GetExtendedTcpTable and GetExtendedUdpTable.
+const (
+	tcpTableOwnerPIDAll = 5
+	udpTableOwnerPID    = 1
+)
+
+var (
+	modiphlpapi             = windows.NewLazySystemDLL("iphlpapi.dll")
+	procGetExtendedTcpTable = modiphlpapi.NewProc("GetExtendedTcpTable")
+	procGetExtendedUdpTable = modiphlpapi.NewProc("GetExtendedUdpTable")
+)
+
+// mibTCPRowOwnerPID is the MIB_TCPROW_OWNER_PID structure.
+type mibTCPRowOwnerPID struct {
+	state      uint32
+	localAddr  [4]byte
+	localPort  uint32
+	remoteAddr [4]byte
+	remotePort uint32
+	owningPID  uint32
+}
+
+// mibTCP6RowOwnerPID is the MIB_TCP6ROW_OWNER_PID structure.
+type mibTCP6RowOwnerPID struct {
+	localAddr     [16]byte
+	localScopeID  uint32
+	localPort     uint32
+	remoteAddr    [16]byte
+	remoteScopeID uint32
+	remotePort    uint32
+	state         uint32
+	owningPID     uint32
+}
+
+// mibUDPRowOwnerPID is the MIB_UDPROW_OWNER_PID structure.
+type mibUDPRowOwnerPID struct {
+	localAddr [4]byte
+	localPort uint32
+	owningPID uint32
+}
+
+// mibUDP6RowOwnerPID is the MIB_UDP6ROW_OWNER_PID structure.
+type mibUDP6RowOwnerPID struct {
+	localAddr    [16]byte
+	localScopeID uint32
+	localPort    uint32
+	owningPID    uint32
+}
+
+// systemSockets returns the TCP and UDP sockets of all processes.
+func systemSockets() ([]socket, error) {
+	var sockets []socket
+
+	buf, err := extendedTable(procGetExtendedTcpTable, windows.AF_INET, tcpTableOwnerPIDAll)
+	if err != nil {
+		return nil, fmt.Errorf("GetExtendedTcpTable failed: %w", err)
+	}
+	first, n := tableRows(buf, unsafe.Sizeof(mibTCPRowOwnerPID{}))
+	for _, row := range unsafe.Slice((*mibTCPRowOwnerPID)(first), n) {
+		sockets = append(sockets, socket{
+			protocol: "tcp", state: row.state, pid: row.owningPID,
+			localAddr: copyIP(row.localAddr[:]), localPort: port(row.localPort),
+		})
+	}
+
+	buf, err = extendedTable(procGetExtendedTcpTable, windows.AF_INET6, tcpTableOwnerPIDAll)
+	if err != nil {
+		return nil, fmt.Errorf("GetExtendedTcpTable failed: %w", err)
+	}
+	first, n = tableRows(buf, unsafe.Sizeof(mibTCP6RowOwnerPID{}))
+	for _, row := range unsafe.Slice((*mibTCP6RowOwnerPID)(first), n) {
+		sockets = append(sockets, socket{
+			protocol: "tcp6", state: row.state, pid: row.owningPID,
+			localAddr: copyIP(row.localAddr[:]), localPort: port(row.localPort),
+		})
+	}
+
+	buf, err = extendedTable(procGetExtendedUdpTable, windows.AF_INET, udpTableOwnerPID)
+	if err != nil {
+		return nil, fmt.Errorf("GetExtendedUdpTable failed: %w", err)
+	}
+	first, n = tableRows(buf, unsafe.Sizeof(mibUDPRowOwnerPID{}))
+	for _, row := range unsafe.Slice((*mibUDPRowOwnerPID)(first), n) {
+		sockets = append(sockets, socket{
+			protocol: "udp", pid: row.owningPID,
+			localAddr: copyIP(row.localAddr[:]), localPort: port(row.localPort),
+		})
+	}
+
+	buf, err = extendedTable(procGetExtendedUdpTable, windows.AF_INET6, udpTableOwnerPID)
+	if err != nil {
+		return nil, fmt.Errorf("GetExtendedUdpTable failed: %w", err)
+	}
+	first, n = tableRows(buf, unsafe.Sizeof(mibUDP6RowOwnerPID{}))
+	for _, row := range unsafe.Slice((*mibUDP6RowOwnerPID)(first), n) {
+		sockets = append(sockets, socket{
+			protocol: "udp6", pid: row.owningPID,
+			localAddr: copyIP(row.localAddr[:]), localPort: port(row.localPort),
+		})
+	}
+	return sockets, nil
+}
+
+// extendedTable returns the table of the address family and class, starting
+// with the number of rows.
+func extendedTable(proc *windows.LazyProc, family uint32, class uint32) ([]byte, error) {
+	var size uint32
+	buf := make([]byte, 4096)
+	for {
+		size = uint32(len(buf))
+		r, _, _ := proc.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)), 0, uintptr(family), uintptr(class), 0)
+		switch syscall.Errno(r) {
+		case 0:
+			return buf[:size], nil
+		case windows.ERROR_INSUFFICIENT_BUFFER:
+			// The table may have grown since the size was returned.
+			buf = make([]byte, size+size/4)
+		default:
+			return nil, syscall.Errno(r)
+		}
+	}
+}
+
+// tableRows returns the first row and the number of rows of the table. The
+// rows start after the number of rows, all row structures are aligned to 4
+// bytes.
+func tableRows(buf []byte, rowSize uintptr) (unsafe.Pointer, int) {
+	if len(buf) < 4 {
+		return nil, 0
+	}
+	n := int(*(*uint32)(unsafe.Pointer(&buf[0])))
+	if max := (len(buf) - 4) / int(rowSize); n > max {
+		n = max
+	}
+	if n == 0 {
+		return nil, 0
+	}
+	return unsafe.Pointer(&buf[4]), n
+}
+
+// copyIP returns a copy of the address of the row.
+func copyIP(addr []byte) net.IP {
+	return append(net.IP(nil), addr...)
+}
+
+// port returns the port, stored in network byte order in the lower 16 bits.
+func port(p uint32) uint16 {
+	return uint16(p&0xff)<<8 | uint16(p>>8&0xff)
+}
+
+// processNames returns the executable names of the running processes by
+// process ID.
+func processNames() (map[uint32]string, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snapshot) //nolint:errcheck // nothing to do on error
+
+	names := make(map[uint32]string)
+	entry := windows.ProcessEntry32{Size: uint32(unsafe.Sizeof(windows.ProcessEntry32{}))}
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		names[entry.ProcessID] = windows.UTF16ToString(entry.ExeFile[:])
+	}
+	if err != windows.ERROR_NO_MORE_FILES {
+		return nil, err
+	}
+	return names, nil
+}
@@ -0,0 +1,188 @@
+//go:build windows
+// +build windows
+
+package win_netstat
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the processes to report, all if empty. Globs accepted.
+  # process_names = []
+
+  ## Report the connections by process ID instead of summing them up by
+  ## process name, adding the "pid" tag.
+  # pid_tag = false
+
+  ## Report the listening ports of the processes.
+  # listen_ports = true
+`
+
+// tcpListen is the MIB_TCP_STATE of listening sockets.
+const tcpListen = 2
+
+// tcpStates are the fields of the MIB_TCP_STATE values, other states are
+// counted as "tcp_none".
+var tcpStates = map[uint32]string{
+	1:  "tcp_close",
+	2:  "tcp_listen",
+	3:  "tcp_syn_sent",
+	4:  "tcp_syn_recv",
+	5:  "tcp_established",
+	6:  "tcp_fin_wait1",
+	7:  "tcp_fin_wait2",
+	8:  "tcp_close_wait",
+	9:  "tcp_closing",
+	10: "tcp_last_ack",
+	11: "tcp_time_wait",
+}
+
+// socket is a TCP or UDP socket and its owning process.
+type socket struct {
+	// protocol is "tcp", "tcp6", "udp" or "udp6".
+	protocol  string
+	state     uint32
+	localAddr net.IP
+	localPort uint16
+	pid       uint32
+}
+
+// WinNetstat reports the network connections by owning process.
+type WinNetstat struct {
+	ProcessNames []string `toml:"process_names"`
+	PidTag       bool     `toml:"pid_tag"`
+	ListenPorts  bool     `toml:"listen_ports"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter       filter.Filter
+	sockets      func() ([]socket, error)
+	processNames func() (map[uint32]string, error)
+}
+
+// process identifies the owner of the connections, the pid is zero if the
+// connections are summed up by name.
+type process struct {
+	name string
+	pid  uint32
+}
+
+func (w *WinNetstat) Description() string {
+	return "Report the network connections and listening ports by process"
+}
+
+func (w *WinNetstat) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinNetstat) Init() error {
+	f, err := filter.Compile(w.ProcessNames)
+	if err != nil {
+		return fmt.Errorf("compiling process_names failed: %w", err)
+	}
+	w.filter = f
+	return nil
+}
+
+func (w *WinNetstat) Gather(acc telegraf.Accumulator) error {
+	sockets, err := w.sockets()
+	if err != nil {
+		return fmt.Errorf("querying sockets failed: %w", err)
+	}
+	names, err := w.processNames()
+	if err != nil {
+		return fmt.Errorf("querying processes failed: %w", err)
+	}
+
+	counts := make(map[process]map[string]interface{})
+	for _, s := range sockets {
+		name, ok := names[s.pid]
+		if !ok {
+			// The process exited after the sockets were queried.
+			name = "unknown"
+		}
+		if w.filter != nil && !w.filter.Match(name) {
+			continue
+		}
+
+		p := process{name: name}
+		if w.PidTag {
+			p.pid = s.pid
+		}
+		fields, ok := counts[p]
+		if !ok {
+			fields = newFields()
+			counts[p] = fields
+		}
+
+		switch s.protocol {
+		case "tcp", "tcp6":
+			field, ok := tcpStates[s.state]
+			if !ok {
+				field = "tcp_none"
+			}
+			fields[field] = fields[field].(int64) + 1
+			if s.state == tcpListen {
+				w.addListen(acc, p, s)
+			}
+		case "udp", "udp6":
+			fields["udp_socket"] = fields["udp_socket"].(int64) + 1
+			w.addListen(acc, p, s)
+		}
+	}
+
+	for p, fields := range counts {
+		acc.AddFields("win_netstat", fields, processTags(p))
+	}
+	return nil
+}
+
+// addListen adds the listening port of the socket.
+func (w *WinNetstat) addListen(acc telegraf.Accumulator, p process, s socket) {
+	if !w.ListenPorts {
+		return
+	}
+	tags := processTags(p)
+	tags["protocol"] = s.protocol
+	tags["address"] = s.localAddr.String()
+	tags["port"] = strconv.Itoa(int(s.localPort))
+	acc.AddFields("win_netstat_listen", map[string]interface{}{"pid": int64(s.pid)}, tags)
+}
+
+// newFields returns the connection counts of a process, all zero.
+func newFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"tcp_none":   int64(0),
+		"udp_socket": int64(0),
+	}
+	for _, field := range tcpStates {
+		fields[field] = int64(0)
+	}
+	return fields
+}
+
+// processTags returns the tags of the process.
+func processTags(p process) map[string]string {
+	tags := map[string]string{"process_name": p.name}
+	if p.pid != 0 {
+		tags["pid"] = strconv.FormatUint(uint64(p.pid), 10)
+	}
+	return tags
+}
+
+func init() {
+	inputs.Add("win_netstat", func() telegraf.Input {
+		return &WinNetstat{
+			ListenPorts:  true,
+			sockets:      systemSockets,
+			processNames: processNames,
+		}
+	})
+}
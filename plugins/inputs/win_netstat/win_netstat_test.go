@@ -0,0 +1,105 @@
+//go:build windows
+// +build windows
+
+package win_netstat
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func fakeSockets() ([]socket, error) {
+	return []socket{
+		{protocol: "tcp", state: 2, localAddr: net.IPv4zero, localPort: 443, pid: 100},
+		{protocol: "tcp", state: 5, localAddr: net.IPv4(10, 0, 0, 1), localPort: 443, pid: 100},
+		{protocol: "tcp6", state: 5, localAddr: net.IPv6loopback, localPort: 443, pid: 100},
+		{protocol: "tcp", state: 11, localAddr: net.IPv4(10, 0, 0, 1), localPort: 443, pid: 101},
+		{protocol: "udp", localAddr: net.IPv4(127, 0, 0, 1), localPort: 53, pid: 200},
+		{protocol: "tcp", state: 12, localAddr: net.IPv4(10, 0, 0, 1), localPort: 5000, pid: 300},
+	}, nil
+}
+
+func fakeProcessNames() (map[uint32]string, error) {
+	return map[uint32]string{100: "nginx.exe", 101: "nginx.exe", 200: "dns.exe"}, nil
+}
+
+// counts returns the connection counts with the given non-zero counts.
+func counts(nonZero map[string]int64) map[string]interface{} {
+	fields := newFields()
+	for field, count := range nonZero {
+		fields[field] = count
+	}
+	return fields
+}
+
+func TestGather(t *testing.T) {
+	w := &WinNetstat{
+		ListenPorts:  true,
+		sockets:      fakeSockets,
+		processNames: fakeProcessNames,
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("win_netstat_listen",
+			map[string]string{"process_name": "nginx.exe", "protocol": "tcp", "address": "0.0.0.0", "port": "443"},
+			map[string]interface{}{"pid": int64(100)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_netstat_listen",
+			map[string]string{"process_name": "dns.exe", "protocol": "udp", "address": "127.0.0.1", "port": "53"},
+			map[string]interface{}{"pid": int64(200)},
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_netstat",
+			map[string]string{"process_name": "nginx.exe"},
+			counts(map[string]int64{"tcp_listen": 1, "tcp_established": 2, "tcp_time_wait": 1}),
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_netstat",
+			map[string]string{"process_name": "dns.exe"},
+			counts(map[string]int64{"udp_socket": 1}),
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_netstat",
+			map[string]string{"process_name": "unknown"},
+			counts(map[string]int64{"tcp_none": 1}),
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherPidTag(t *testing.T) {
+	w := &WinNetstat{
+		ProcessNames: []string{"nginx*"},
+		PidTag:       true,
+		sockets:      fakeSockets,
+		processNames: fakeProcessNames,
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("win_netstat",
+			map[string]string{"process_name": "nginx.exe", "pid": "100"},
+			counts(map[string]int64{"tcp_listen": 1, "tcp_established": 2}),
+			time.Unix(0, 0)),
+		testutil.MustMetric("win_netstat",
+			map[string]string{"process_name": "nginx.exe", "pid": "101"},
+			counts(map[string]int64{"tcp_time_wait": 1}),
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestPort(t *testing.T) {
+	require.Equal(t, uint16(443), port(0xbb01))
+	require.Equal(t, uint16(53), port(0x3500))
+}
@@ -0,0 +1,66 @@
+//go:build windows
+// +build windows
+
+package win_perf_counters
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// englishNamesKey is the registry key holding the indexes and English names
+// of the objects and counters.
+const englishNamesKey = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\Perflib\009`
+
+var (
+	englishIndexesOnce sync.Once
+	englishIndexes     map[string]uint32
+	englishIndexesErr  error
+)
+
+// englishNameIndexes returns the indexes of the English object and counter
+// names. Names used by several indexes, e.g. for different objects, map to the
+// lowest index. The names are read once as they only change on installing
+// new counters.
+func englishNameIndexes() (map[string]uint32, error) {
+	englishIndexesOnce.Do(func() {
+		englishIndexes, englishIndexesErr = readEnglishNameIndexes()
+	})
+	return englishIndexes, englishIndexesErr
+}
+
+func readEnglishNameIndexes() (map[string]uint32, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, englishNamesKey, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, fmt.Errorf("opening registry key of English counter names failed: %w", err)
+	}
+	defer key.Close()
+
+	// The value alternately holds the index and the name.
+	values, _, err := key.GetStringsValue("Counter")
+	if err != nil {
+		return nil, fmt.Errorf("reading English counter names failed: %w", err)
+	}
+	return parseNameIndexes(values), nil
+}
+
+// parseNameIndexes parses the alternating indexes and names, skipping invalid
+// indexes.
+func parseNameIndexes(values []string) map[string]uint32 {
+	indexes := make(map[string]uint32, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		index, err := strconv.ParseUint(values[i], 10, 32)
+		if err != nil {
+			continue
+		}
+		name := values[i+1]
+		if current, ok := indexes[name]; ok && current <= uint32(index) {
+			continue
+		}
+		indexes[name] = uint32(index)
+	}
+	return indexes
+}
@@ -0,0 +1,21 @@
+//go:build windows
+// +build windows
+
+package win_perf_counters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNameIndexes(t *testing.T) {
+	values := []string{"1", "1847", "2", "System", "4", "Memory", "6", "% Processor Time", "x", "Invalid", "1500", "% Processor Time", "7"}
+	expected := map[string]uint32{
+		"1847":             1,
+		"System":           2,
+		"Memory":           4,
+		"% Processor Time": 6,
+	}
+	require.Equal(t, expected, parseNameIndexes(values))
+}
@@ -160,6 +160,35 @@ const (
 	PERF_DETAIL_STANDARD = 0x0000FFFF
 )
 
+// Counter types, taken from winperf.h. The types determine how the formatted value is computed from the raw
+// values of the counter.
+const (
+	PERF_SIZE_LARGE = 0x00000100 // Flag of the counter types with 64-bit values.
+
+	PERF_COUNTER_RAWCOUNT_HEX        = 0x00000000
+	PERF_COUNTER_LARGE_RAWCOUNT_HEX  = 0x00000100
+	PERF_COUNTER_RAWCOUNT            = 0x00010000
+	PERF_COUNTER_LARGE_RAWCOUNT      = 0x00010100
+	PERF_COUNTER_DELTA               = 0x00400400
+	PERF_COUNTER_LARGE_DELTA         = 0x00400500
+	PERF_COUNTER_COUNTER             = 0x10410400
+	PERF_COUNTER_BULK_COUNT          = 0x10410500
+	PERF_COUNTER_TIMER               = 0x20410500
+	PERF_COUNTER_TIMER_INV           = 0x21410500
+	PERF_100NSEC_TIMER               = 0x20510500
+	PERF_100NSEC_TIMER_INV           = 0x21510500
+	PERF_RAW_FRACTION                = 0x20020400
+	PERF_LARGE_RAW_FRACTION          = 0x20020500
+	PERF_SAMPLE_FRACTION             = 0x20C20400
+	PERF_AVERAGE_BULK                = 0x40020500
+	PERF_COUNTER_QUEUELEN_TYPE       = 0x00450400
+	PERF_COUNTER_LARGE_QUEUELEN_TYPE = 0x00450500
+	PERF_COUNTER_100NS_QUEUELEN_TYPE = 0x00550500
+)
+
+// PDH_MAX_COUNTER_NAME is the maximum length of an object or counter name in characters.
+const PDH_MAX_COUNTER_NAME = 1024
+
 type (
 	PDH_HQUERY   HANDLE // query handle
 	PDH_HCOUNTER HANDLE // counter handle
@@ -177,10 +206,14 @@ var (
 	pdh_CollectQueryDataWithTime  *syscall.Proc
 	pdh_GetFormattedCounterValue  *syscall.Proc
 	pdh_GetFormattedCounterArrayW *syscall.Proc
+	pdh_GetRawCounterValue        *syscall.Proc
+	pdh_GetRawCounterArrayW       *syscall.Proc
 	pdh_OpenQuery                 *syscall.Proc
 	pdh_ValidatePathW             *syscall.Proc
 	pdh_ExpandWildCardPathW       *syscall.Proc
 	pdh_GetCounterInfoW           *syscall.Proc
+	pdh_RemoveCounter             *syscall.Proc
+	pdh_LookupPerfNameByIndexW    *syscall.Proc
 )
 
 func init() {
@@ -195,10 +228,14 @@ func init() {
 	pdh_CollectQueryDataWithTime, _ = libpdhDll.FindProc("PdhCollectQueryDataWithTime")
 	pdh_GetFormattedCounterValue = libpdhDll.MustFindProc("PdhGetFormattedCounterValue")
 	pdh_GetFormattedCounterArrayW = libpdhDll.MustFindProc("PdhGetFormattedCounterArrayW")
+	pdh_GetRawCounterValue = libpdhDll.MustFindProc("PdhGetRawCounterValue")
+	pdh_GetRawCounterArrayW = libpdhDll.MustFindProc("PdhGetRawCounterArrayW")
 	pdh_OpenQuery = libpdhDll.MustFindProc("PdhOpenQuery")
 	pdh_ValidatePathW = libpdhDll.MustFindProc("PdhValidatePathW")
 	pdh_ExpandWildCardPathW = libpdhDll.MustFindProc("PdhExpandWildCardPathW")
 	pdh_GetCounterInfoW = libpdhDll.MustFindProc("PdhGetCounterInfoW")
+	pdh_RemoveCounter = libpdhDll.MustFindProc("PdhRemoveCounter")
+	pdh_LookupPerfNameByIndexW = libpdhDll.MustFindProc("PdhLookupPerfNameByIndexW")
 }
 
 // PdhAddCounter adds the specified counter to the query. This is the internationalized version. Preferably, use the
@@ -274,6 +311,13 @@ func PdhAddEnglishCounter(hQuery PDH_HQUERY, szFullCounterPath string, dwUserDat
 	return uint32(ret)
 }
 
+// PdhRemoveCounter removes a counter from a query and closes the counter handle.
+func PdhRemoveCounter(hCounter PDH_HCOUNTER) uint32 {
+	ret, _, _ := pdh_RemoveCounter.Call(uintptr(hCounter))
+
+	return uint32(ret)
+}
+
 // PdhCloseQuery closes all counters contained in the specified query, closes all handles related to the query,
 // and frees all memory associated with the query.
 func PdhCloseQuery(hQuery PDH_HQUERY) uint32 {
@@ -317,27 +361,35 @@ func PdhCollectQueryDataWithTime(hQuery PDH_HQUERY) (uint32, time.Time) {
 	ret, _, _ := pdh_CollectQueryDataWithTime.Call(uintptr(hQuery), uintptr(unsafe.Pointer(&localFileTime)))
 
 	if ret == ERROR_SUCCESS {
-		var utcFileTime FILETIME
-		ret, _, _ := krn_LocalFileTimeToFileTime.Call(
-			uintptr(unsafe.Pointer(&localFileTime)),
-			uintptr(unsafe.Pointer(&utcFileTime)))
-
-		if ret == 0 {
+		retTime, ok := LocalFileTimeToTime(localFileTime)
+		if !ok {
 			return uint32(ERROR_FAILURE), time.Now()
 		}
+		return uint32(ERROR_SUCCESS), retTime
+	}
 
-		// First convert 100-ns intervals to microseconds, then adjust for the
-		// epoch difference
-		var totalMicroSeconds int64
-		totalMicroSeconds = ((int64(utcFileTime.dwHighDateTime) << 32) | int64(utcFileTime.dwLowDateTime)) / 10
-		totalMicroSeconds -= EPOCH_DIFFERENCE_MICROS
+	return uint32(ret), time.Now()
+}
 
-		retTime := time.Unix(0, totalMicroSeconds*1000)
+// LocalFileTimeToTime converts the filetime structure in local time, as returned by the Pdh* functions, to a GO time.
+// It returns false if the conversion to UTC fails.
+func LocalFileTimeToTime(localFileTime FILETIME) (time.Time, bool) {
+	var utcFileTime FILETIME
+	ret, _, _ := krn_LocalFileTimeToFileTime.Call(
+		uintptr(unsafe.Pointer(&localFileTime)),
+		uintptr(unsafe.Pointer(&utcFileTime)))
 
-		return uint32(ERROR_SUCCESS), retTime
+	if ret == 0 {
+		return time.Time{}, false
 	}
 
-	return uint32(ret), time.Now()
+	// First convert 100-ns intervals to microseconds, then adjust for the
+	// epoch difference
+	var totalMicroSeconds int64
+	totalMicroSeconds = ((int64(utcFileTime.dwHighDateTime) << 32) | int64(utcFileTime.dwLowDateTime)) / 10
+	totalMicroSeconds -= EPOCH_DIFFERENCE_MICROS
+
+	return time.Unix(0, totalMicroSeconds*1000), true
 }
 
 // PdhGetFormattedCounterValueDouble formats the given hCounter using a 'double'. The result is set into the specialized union struct pValue.
@@ -400,6 +452,31 @@ func PdhGetFormattedCounterArrayDouble(hCounter PDH_HCOUNTER, lpdwBufferSize *ui
 	return uint32(ret)
 }
 
+// PdhGetRawCounterValue returns the current raw value of the counter. The raw value of a counter is not computed
+// from the previous sample, rates and averages have to be computed from the raw values of two samples. lpdwType
+// receives the counter type, see the PERF_* constants.
+func PdhGetRawCounterValue(hCounter PDH_HCOUNTER, lpdwType *uint32, pValue *PDH_RAW_COUNTER) uint32 {
+	ret, _, _ := pdh_GetRawCounterValue.Call(
+		uintptr(hCounter),
+		uintptr(unsafe.Pointer(lpdwType)),
+		uintptr(unsafe.Pointer(pValue)))
+
+	return uint32(ret)
+}
+
+// PdhGetRawCounterArray returns the raw values of the instances of a counter that contains a wildcard character for
+// the instance name. The itemBuffer receives PDH_RAW_COUNTER_ITEM structures and the strings of the instance names.
+// If lpdwBufferSize is zero on input, the function returns PDH_MORE_DATA and sets it to the required buffer size.
+func PdhGetRawCounterArray(hCounter PDH_HCOUNTER, lpdwBufferSize *uint32, lpdwItemCount *uint32, itemBuffer *byte) uint32 {
+	ret, _, _ := pdh_GetRawCounterArrayW.Call(
+		uintptr(hCounter),
+		uintptr(unsafe.Pointer(lpdwBufferSize)),
+		uintptr(unsafe.Pointer(lpdwItemCount)),
+		uintptr(unsafe.Pointer(itemBuffer)))
+
+	return uint32(ret)
+}
+
 // PdhOpenQuery creates a new query that is used to manage the collection of performance data.
 // szDataSource is a null terminated string that specifies the name of the log file from which to
 // retrieve the performance data. If 0, performance data is collected from a real-time data source.
@@ -500,3 +577,15 @@ func PdhGetCounterInfo(hCounter PDH_HCOUNTER, bRetrieveExplainText int, pdwBuffe
 
 	return uint32(ret)
 }
+
+// PdhLookupPerfNameByIndex returns the name of the object or counter with the given index in the language of the
+// local computer. pcchNameBufferSize is the size of szNameBuffer in characters, at least PDH_MAX_COUNTER_NAME.
+func PdhLookupPerfNameByIndex(dwNameIndex uint32, szNameBuffer *uint16, pcchNameBufferSize *uint32) uint32 {
+	ret, _, _ := pdh_LookupPerfNameByIndexW.Call(
+		uintptr(unsafe.Pointer(nil)), // look up names on local computer
+		uintptr(dwNameIndex),
+		uintptr(unsafe.Pointer(szNameBuffer)),
+		uintptr(unsafe.Pointer(pcchNameBufferSize)))
+
+	return uint32(ret)
+}
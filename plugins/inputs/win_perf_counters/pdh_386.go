@@ -74,6 +74,24 @@ type PDH_FMT_COUNTERVALUE_ITEM_LONG struct {
 	FmtValue PDH_FMT_COUNTERVALUE_LONG
 }
 
+// PDH_RAW_COUNTER is the raw value of a counter, used by PdhGetRawCounterValue()
+type PDH_RAW_COUNTER struct {
+	CStatus     uint32
+	TimeStamp   FILETIME // local time the data was collected
+	padding1    [4]byte
+	FirstValue  int64
+	SecondValue int64
+	MultiCount  uint32
+	padding2    [4]byte
+}
+
+// PDH_RAW_COUNTER_ITEM is the raw value of an instance of a counter, used by PdhGetRawCounterArray()
+type PDH_RAW_COUNTER_ITEM struct {
+	SzName   *uint16 // pointer to a string
+	padding  [4]byte
+	RawValue PDH_RAW_COUNTER
+}
+
 //PDH_COUNTER_INFO structure contains information describing the properties of a counter. This information also includes the counter path.
 type PDH_COUNTER_INFO struct {
 	//Size of the structure, including the appended strings, in bytes.
@@ -6,6 +6,8 @@ package win_perf_counters
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"syscall"
 	"time"
 	"unsafe"
@@ -17,16 +19,30 @@ type CounterValue struct {
 	Value        float64
 }
 
+// RawCounterValue is abstraction for PDH_RAW_COUNTER_ITEM
+type RawCounterValue struct {
+	InstanceName string
+	// Timestamp is the time the raw values were collected.
+	Timestamp   time.Time
+	FirstValue  int64
+	SecondValue int64
+}
+
 //PerformanceQuery provides wrappers around Windows performance counters API for easy usage in GO
 type PerformanceQuery interface {
 	Open() error
 	Close() error
 	AddCounterToQuery(counterPath string) (PDH_HCOUNTER, error)
 	AddEnglishCounterToQuery(counterPath string) (PDH_HCOUNTER, error)
+	RemoveCounterFromQuery(counterHandle PDH_HCOUNTER) error
+	LocalizeName(name string) (string, error)
 	GetCounterPath(counterHandle PDH_HCOUNTER) (string, error)
 	ExpandWildCardPath(counterPath string) ([]string, error)
 	GetFormattedCounterValueDouble(hCounter PDH_HCOUNTER) (float64, error)
 	GetFormattedCounterArrayDouble(hCounter PDH_HCOUNTER) ([]CounterValue, error)
+	GetCounterType(hCounter PDH_HCOUNTER) (uint32, error)
+	GetRawCounterValue(hCounter PDH_HCOUNTER) (RawCounterValue, error)
+	GetRawCounterArray(hCounter PDH_HCOUNTER) ([]RawCounterValue, error)
 	CollectData() error
 	CollectDataWithTime() (time.Time, error)
 	IsVistaOrNewer() bool
@@ -108,6 +124,41 @@ func (m *PerformanceQueryImpl) AddEnglishCounterToQuery(counterPath string) (PDH
 	return counterHandle, nil
 }
 
+// RemoveCounterFromQuery removes the counter from the query and closes its handle
+func (m *PerformanceQueryImpl) RemoveCounterFromQuery(counterHandle PDH_HCOUNTER) error {
+	if m.query == 0 {
+		return errors.New("uninitialized query")
+	}
+	if ret := PdhRemoveCounter(counterHandle); ret != ERROR_SUCCESS {
+		return NewPdhError(ret)
+	}
+	return nil
+}
+
+// LocalizeName returns the name of the object or counter in the language of the system. The name is given
+// by its index or by its English name.
+func (m *PerformanceQueryImpl) LocalizeName(name string) (string, error) {
+	index, err := strconv.ParseUint(name, 10, 32)
+	if err != nil {
+		indexes, err := englishNameIndexes()
+		if err != nil {
+			return "", err
+		}
+		i, ok := indexes[name]
+		if !ok {
+			return "", fmt.Errorf("unknown English name %q", name)
+		}
+		index = uint64(i)
+	}
+
+	buff := make([]uint16, PDH_MAX_COUNTER_NAME)
+	bufSize := uint32(len(buff))
+	if ret := PdhLookupPerfNameByIndex(uint32(index), &buff[0], &bufSize); ret != ERROR_SUCCESS {
+		return "", NewPdhError(ret)
+	}
+	return syscall.UTF16ToString(buff), nil
+}
+
 //GetCounterPath return counter information for given handle
 func (m *PerformanceQueryImpl) GetCounterPath(counterHandle PDH_HCOUNTER) (string, error) {
 	var bufSize uint32
@@ -182,6 +233,69 @@ func (m *PerformanceQueryImpl) GetFormattedCounterArrayDouble(hCounter PDH_HCOUN
 	return nil, NewPdhError(ret)
 }
 
+// GetCounterType returns the type of the counter, see the PERF_* constants
+func (m *PerformanceQueryImpl) GetCounterType(hCounter PDH_HCOUNTER) (uint32, error) {
+	var bufSize uint32
+	var buff []byte
+	var ret uint32
+	if ret = PdhGetCounterInfo(hCounter, 0, &bufSize, nil); ret == PDH_MORE_DATA {
+		buff = make([]byte, bufSize)
+		bufSize = uint32(len(buff))
+		if ret = PdhGetCounterInfo(hCounter, 0, &bufSize, &buff[0]); ret == ERROR_SUCCESS {
+			ci := (*PDH_COUNTER_INFO)(unsafe.Pointer(&buff[0]))
+			return ci.DwType, nil
+		}
+	}
+	return 0, NewPdhError(ret)
+}
+
+//GetRawCounterValue returns the raw values of the counter of the last collected sample
+func (m *PerformanceQueryImpl) GetRawCounterValue(hCounter PDH_HCOUNTER) (RawCounterValue, error) {
+	var counterType uint32
+	var value PDH_RAW_COUNTER
+
+	if ret := PdhGetRawCounterValue(hCounter, &counterType, &value); ret != ERROR_SUCCESS {
+		return RawCounterValue{}, NewPdhError(ret)
+	}
+	if value.CStatus != PDH_CSTATUS_VALID_DATA && value.CStatus != PDH_CSTATUS_NEW_DATA {
+		return RawCounterValue{}, NewPdhError(value.CStatus)
+	}
+	timestamp, ok := LocalFileTimeToTime(value.TimeStamp)
+	if !ok {
+		return RawCounterValue{}, NewPdhError(ERROR_FAILURE)
+	}
+	return RawCounterValue{"", timestamp, value.FirstValue, value.SecondValue}, nil
+}
+
+//GetRawCounterArray returns the raw values of the instances of the counter of the last collected sample
+func (m *PerformanceQueryImpl) GetRawCounterArray(hCounter PDH_HCOUNTER) ([]RawCounterValue, error) {
+	var buffSize uint32
+	var itemCount uint32
+	var ret uint32
+
+	if ret = PdhGetRawCounterArray(hCounter, &buffSize, &itemCount, nil); ret == PDH_MORE_DATA {
+		buff := make([]byte, buffSize)
+
+		if ret = PdhGetRawCounterArray(hCounter, &buffSize, &itemCount, &buff[0]); ret == ERROR_SUCCESS {
+			items := (*[1 << 20]PDH_RAW_COUNTER_ITEM)(unsafe.Pointer(&buff[0]))[:itemCount]
+			values := make([]RawCounterValue, 0, itemCount)
+			for _, item := range items {
+				if item.RawValue.CStatus != PDH_CSTATUS_VALID_DATA && item.RawValue.CStatus != PDH_CSTATUS_NEW_DATA {
+					continue
+				}
+				timestamp, ok := LocalFileTimeToTime(item.RawValue.TimeStamp)
+				if !ok {
+					continue
+				}
+				values = append(values, RawCounterValue{UTF16PtrToString(item.SzName), timestamp,
+					item.RawValue.FirstValue, item.RawValue.SecondValue})
+			}
+			return values, nil
+		}
+	}
+	return nil, NewPdhError(ret)
+}
+
 func (m *PerformanceQueryImpl) CollectData() error {
 	var ret uint32
 	if m.query == 0 {
@@ -0,0 +1,113 @@
+//go:build windows
+// +build windows
+
+package win_perf_counters
+
+// rawCounter is the type and the raw values by instance of a counter of the
+// last interval.
+type rawCounter struct {
+	counterType uint32
+	values      map[string]RawCounterValue
+}
+
+// rawCounterTypes are the counter types computed from the raw values, the
+// values of the other types are formatted by PDH.
+var rawCounterTypes = map[uint32]bool{
+	PERF_COUNTER_RAWCOUNT_HEX:        true,
+	PERF_COUNTER_LARGE_RAWCOUNT_HEX:  true,
+	PERF_COUNTER_RAWCOUNT:            true,
+	PERF_COUNTER_LARGE_RAWCOUNT:      true,
+	PERF_COUNTER_DELTA:               true,
+	PERF_COUNTER_LARGE_DELTA:         true,
+	PERF_COUNTER_COUNTER:             true,
+	PERF_COUNTER_BULK_COUNT:          true,
+	PERF_COUNTER_TIMER:               true,
+	PERF_COUNTER_TIMER_INV:           true,
+	PERF_100NSEC_TIMER:               true,
+	PERF_100NSEC_TIMER_INV:           true,
+	PERF_RAW_FRACTION:                true,
+	PERF_LARGE_RAW_FRACTION:          true,
+	PERF_SAMPLE_FRACTION:             true,
+	PERF_AVERAGE_BULK:                true,
+	PERF_COUNTER_QUEUELEN_TYPE:       true,
+	PERF_COUNTER_LARGE_QUEUELEN_TYPE: true,
+	PERF_COUNTER_100NS_QUEUELEN_TYPE: true,
+}
+
+// computeRawValue computes the value of the counter from the raw values of
+// the current and the previous sample, which is nil for the first sample. It
+// returns false if the value cannot be computed, e.g. for the first sample of
+// counters computed from two samples or if the counter was reset in between.
+func computeRawValue(counterType uint32, previous *RawCounterValue, current RawCounterValue) (float64, bool) {
+	switch counterType {
+	case PERF_COUNTER_RAWCOUNT_HEX, PERF_COUNTER_LARGE_RAWCOUNT_HEX, PERF_COUNTER_RAWCOUNT, PERF_COUNTER_LARGE_RAWCOUNT:
+		return float64(current.FirstValue), true
+	case PERF_RAW_FRACTION, PERF_LARGE_RAW_FRACTION:
+		if current.SecondValue == 0 {
+			return 0, true
+		}
+		return 100 * float64(current.FirstValue) / float64(current.SecondValue), true
+	}
+
+	if previous == nil {
+		return 0, false
+	}
+	delta, ok := counterDelta(counterType, previous.FirstValue, current.FirstValue)
+	if !ok {
+		return 0, false
+	}
+
+	switch counterType {
+	case PERF_COUNTER_DELTA, PERF_COUNTER_LARGE_DELTA:
+		return delta, true
+	case PERF_COUNTER_COUNTER, PERF_COUNTER_BULK_COUNT:
+		// Rates are computed using the time of the samples, so they are
+		// exact for short or irregular intervals.
+		elapsed := current.Timestamp.Sub(previous.Timestamp).Seconds()
+		if elapsed <= 0 {
+			return 0, false
+		}
+		return delta / elapsed, true
+	case PERF_SAMPLE_FRACTION, PERF_AVERAGE_BULK:
+		// The bases are 32-bit counters.
+		base := float64(uint32(current.SecondValue) - uint32(previous.SecondValue))
+		if base == 0 {
+			return 0, true
+		}
+		if counterType == PERF_SAMPLE_FRACTION {
+			return 100 * delta / base, true
+		}
+		return delta / base, true
+	}
+
+	// The second values of the timers and queue lengths are the time of the
+	// samples in the time base of the counter.
+	if current.SecondValue <= previous.SecondValue {
+		return 0, false
+	}
+	ratio := delta / float64(current.SecondValue-previous.SecondValue)
+	switch counterType {
+	case PERF_COUNTER_TIMER, PERF_100NSEC_TIMER:
+		return 100 * ratio, true
+	case PERF_COUNTER_TIMER_INV, PERF_100NSEC_TIMER_INV:
+		if ratio > 1 {
+			return 0, true
+		}
+		return 100 * (1 - ratio), true
+	case PERF_COUNTER_QUEUELEN_TYPE, PERF_COUNTER_LARGE_QUEUELEN_TYPE, PERF_COUNTER_100NS_QUEUELEN_TYPE:
+		return ratio, true
+	}
+	return 0, false
+}
+
+// counterDelta returns the increase of the counter between the values. The
+// 32-bit counters wrap around, a decrease of the 64-bit counters is a reset.
+func counterDelta(counterType uint32, previous, current int64) (float64, bool) {
+	if counterType&PERF_SIZE_LARGE == 0 {
+		return float64(uint32(current) - uint32(previous)), true
+	}
+	if current < previous {
+		return 0, false
+	}
+	return float64(current - previous), true
+}
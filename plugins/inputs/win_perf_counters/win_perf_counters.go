@@ -30,6 +30,16 @@ var sampleConfig = `
   #UseWildcardsExpansion = false
   # Period after which counters will be reread from configuration and wildcards in counter paths expanded
   CountersRefreshInterval="1m"
+  # Period after which wildcards in counter paths are expanded again to pick up new and drop vanished
+  # instances without rereading the configuration, requires UseWildcardsExpansion
+  # refresh_interval = "0s"
+  # If LocalizeCounterNames is set to true, object and counter names are given in English or by their index and
+  # translated to the language of the system, so the same configuration works on localized Windows.
+  #LocalizeCounterNames = false
+  # If UseRawValues is set to true, the raw values of the counters are collected and rates, deltas and averages are
+  # computed by the plugin from the samples of two intervals, using the time of the samples. Such counters are not
+  # reported on the first interval. Counter types not computed by the plugin are formatted as usual.
+  #UseRawValues = false
 
   [[inputs.win_perf_counters.object]]
     # Processor usage, alternative to native, reports on a per core.
@@ -146,12 +156,23 @@ type Win_PerfCounters struct {
 	Object                  []perfobject
 	CountersRefreshInterval config.Duration
 	UseWildcardsExpansion   bool
+	RefreshInterval         config.Duration `toml:"refresh_interval"`
+	LocalizeCounterNames    bool
+	UseRawValues            bool
 
 	Log telegraf.Logger
 
-	lastRefreshed time.Time
-	counters      []*counter
-	query         PerformanceQuery
+	lastRefreshed          time.Time
+	lastInstancesRefreshed time.Time
+	counters               []*counter
+	wildcardPaths          []wildcardPath
+	query                  PerformanceQuery
+
+	// localizedNames maps the localized object and counter names to the
+	// configured ones.
+	localizedNames map[string]string
+	// rawCounters are the raw values of the last interval by counter path.
+	rawCounters map[string]*rawCounter
 }
 
 type perfobject struct {
@@ -172,6 +193,18 @@ type counter struct {
 	measurement   string
 	includeTotal  bool
 	counterHandle PDH_HCOUNTER
+	// wildcardPath is the counter path the counter was expanded from, if it
+	// contains wildcards.
+	wildcardPath string
+}
+
+// wildcardPath is a counter path with wildcards, expanded again on every
+// instance refresh.
+type wildcardPath struct {
+	counterPath  string
+	instance     string
+	measurement  string
+	includeTotal bool
 }
 
 type instanceGrouping struct {
@@ -249,7 +282,7 @@ func (m *Win_PerfCounters) SampleConfig() string {
 func (m *Win_PerfCounters) AddItem(counterPath string, objectName string, instance string, counterName string, measurement string, includeTotal bool) error {
 	var err error
 	var counterHandle PDH_HCOUNTER
-	if !m.query.IsVistaOrNewer() {
+	if !m.query.IsVistaOrNewer() || m.LocalizeCounterNames {
 		counterHandle, err = m.query.AddCounterToQuery(counterPath)
 		if err != nil {
 			return err
@@ -273,6 +306,12 @@ func (m *Win_PerfCounters) AddItem(counterPath string, objectName string, instan
 			return err
 		}
 
+		var wildcard string
+		if strings.Contains(counterPath, "*") {
+			wildcard = counterPath
+			m.wildcardPaths = append(m.wildcardPaths, wildcardPath{counterPath, origInstance, measurement, includeTotal})
+		}
+
 		for _, counterPath := range counters {
 			var err error
 			counterHandle, err := m.query.AddCounterToQuery(counterPath)
@@ -281,13 +320,14 @@ func (m *Win_PerfCounters) AddItem(counterPath string, objectName string, instan
 			if err != nil {
 				return err
 			}
+			objectName, counterName = m.configuredName(objectName), m.configuredName(counterName)
 
 			if instance == "_Total" && origInstance == "*" && !includeTotal {
 				continue
 			}
 
 			newItem := &counter{counterPath, objectName, counterName, instance, measurement,
-				includeTotal, counterHandle}
+				includeTotal, counterHandle, wildcard}
 			m.counters = append(m.counters, newItem)
 
 			if m.PrintValid {
@@ -296,7 +336,7 @@ func (m *Win_PerfCounters) AddItem(counterPath string, objectName string, instan
 		}
 	} else {
 		newItem := &counter{counterPath, objectName, counterName, instance, measurement,
-			includeTotal, counterHandle}
+			includeTotal, counterHandle, ""}
 		m.counters = append(m.counters, newItem)
 		if m.PrintValid {
 			m.Log.Infof("Valid: %s", counterPath)
@@ -309,16 +349,39 @@ func (m *Win_PerfCounters) AddItem(counterPath string, objectName string, instan
 func (m *Win_PerfCounters) ParseConfig() error {
 	var counterPath string
 
+	m.localizedNames = make(map[string]string)
 	if len(m.Object) > 0 {
 		for _, PerfObject := range m.Object {
+			localObjectname, err := m.localizeName(PerfObject.ObjectName)
+			if err != nil {
+				if PerfObject.FailOnMissing || PerfObject.WarnOnMissing {
+					m.Log.Errorf("Invalid object name: '%s'. Error: %s\n", PerfObject.ObjectName, err.Error())
+				}
+				if PerfObject.FailOnMissing {
+					return err
+				}
+				continue
+			}
+
 			for _, counter := range PerfObject.Counters {
+				localCounter, err := m.localizeName(counter)
+				if err != nil {
+					if PerfObject.FailOnMissing || PerfObject.WarnOnMissing {
+						m.Log.Errorf("Invalid counter name: '%s'. Error: %s\n", counter, err.Error())
+					}
+					if PerfObject.FailOnMissing {
+						return err
+					}
+					continue
+				}
+
 				for _, instance := range PerfObject.Instances {
 					objectname := PerfObject.ObjectName
 
 					if instance == "------" {
-						counterPath = "\\" + objectname + "\\" + counter
+						counterPath = "\\" + localObjectname + "\\" + localCounter
 					} else {
-						counterPath = "\\" + objectname + "(" + instance + ")\\" + counter
+						counterPath = "\\" + localObjectname + "(" + instance + ")\\" + localCounter
 					}
 
 					err := m.AddItem(counterPath, objectname, instance, counter, PerfObject.Measurement, PerfObject.IncludeTotal)
@@ -342,6 +405,29 @@ func (m *Win_PerfCounters) ParseConfig() error {
 
 }
 
+// localizeName returns the object or counter name in the language of the
+// system if LocalizeCounterNames is set.
+func (m *Win_PerfCounters) localizeName(name string) (string, error) {
+	if !m.LocalizeCounterNames || name == "*" {
+		return name, nil
+	}
+	localName, err := m.query.LocalizeName(name)
+	if err != nil {
+		return "", fmt.Errorf("localizing name %q failed: %w", name, err)
+	}
+	m.localizedNames[localName] = name
+	return localName, nil
+}
+
+// configuredName returns the configured name of the localized object or
+// counter name, so the metrics do not depend on the language of the system.
+func (m *Win_PerfCounters) configuredName(name string) string {
+	if configured, ok := m.localizedNames[name]; ok {
+		return configured
+	}
+	return name
+}
+
 func (m *Win_PerfCounters) Gather(acc telegraf.Accumulator) error {
 	// Parse the config once
 	var err error
@@ -350,6 +436,7 @@ func (m *Win_PerfCounters) Gather(acc telegraf.Accumulator) error {
 		if m.counters != nil {
 			m.counters = m.counters[:0]
 		}
+		m.wildcardPaths = m.wildcardPaths[:0]
 
 		if err = m.query.Open(); err != nil {
 			return err
@@ -363,14 +450,18 @@ func (m *Win_PerfCounters) Gather(acc telegraf.Accumulator) error {
 			return err
 		}
 		m.lastRefreshed = time.Now()
+		m.lastInstancesRefreshed = m.lastRefreshed
 
 		time.Sleep(time.Second)
+	} else if m.UseWildcardsExpansion && m.RefreshInterval > 0 && m.lastInstancesRefreshed.Add(time.Duration(m.RefreshInterval)).Before(time.Now()) {
+		m.refreshInstances()
+		m.lastInstancesRefreshed = time.Now()
 	}
 
 	var collectFields = make(map[instanceGrouping]map[string]interface{})
 
 	var timestamp time.Time
-	if m.UsePerfCounterTime && m.query.IsVistaOrNewer() {
+	if (m.UsePerfCounterTime || m.UseRawValues) && m.query.IsVistaOrNewer() {
 		timestamp, err = m.query.CollectDataWithTime()
 		if err != nil {
 			return err
@@ -383,43 +474,19 @@ func (m *Win_PerfCounters) Gather(acc telegraf.Accumulator) error {
 	}
 
 	// For iterate over the known metrics and get the samples.
+	rawCounters := make(map[string]*rawCounter)
 	for _, metric := range m.counters {
-		// collect
-		if m.UseWildcardsExpansion {
-			value, err := m.query.GetFormattedCounterValueDouble(metric.counterHandle)
-			if err != nil {
-				//ignore invalid data  as some counters from process instances returns this sometimes
-				if !isKnownCounterDataError(err) {
-					return fmt.Errorf("error while getting value for counter %s: %v", metric.counterPath, err)
-				}
-				m.Log.Warnf("error while getting value for counter %q, will skip metric: %v", metric.counterPath, err)
-				continue
-			}
-			addCounterMeasurement(metric, metric.instance, value, collectFields)
+		var err error
+		if m.UseRawValues {
+			err = m.addRawCounter(metric, rawCounters, collectFields)
 		} else {
-			counterValues, err := m.query.GetFormattedCounterArrayDouble(metric.counterHandle)
-			if err != nil {
-				//ignore invalid data  as some counters from process instances returns this sometimes
-				if !isKnownCounterDataError(err) {
-					return fmt.Errorf("error while getting value for counter %s: %v", metric.counterPath, err)
-				}
-				m.Log.Warnf("error while getting value for counter %q, will skip metric: %v", metric.counterPath, err)
-				continue
-			}
-			for _, cValue := range counterValues {
-
-				if strings.Contains(metric.instance, "#") && strings.HasPrefix(metric.instance, cValue.InstanceName) {
-					// If you are using a multiple instance identifier such as "w3wp#1"
-					// phd.dll returns only the first 2 characters of the identifier.
-					cValue.InstanceName = metric.instance
-				}
-
-				if shouldIncludeMetric(metric, cValue) {
-					addCounterMeasurement(metric, cValue.InstanceName, cValue.Value, collectFields)
-				}
-			}
+			err = m.addFormattedCounter(metric, collectFields)
+		}
+		if err != nil {
+			return err
 		}
 	}
+	m.rawCounters = rawCounters
 
 	for instance, fields := range collectFields {
 		var tags = map[string]string{
@@ -434,6 +501,172 @@ func (m *Win_PerfCounters) Gather(acc telegraf.Accumulator) error {
 	return nil
 }
 
+// addFormattedCounter adds the values of the counter formatted by PDH.
+func (m *Win_PerfCounters) addFormattedCounter(metric *counter, collectFields map[instanceGrouping]map[string]interface{}) error {
+	if m.UseWildcardsExpansion {
+		value, err := m.query.GetFormattedCounterValueDouble(metric.counterHandle)
+		if err != nil {
+			//ignore invalid data  as some counters from process instances returns this sometimes
+			if !isKnownCounterDataError(err) {
+				return fmt.Errorf("error while getting value for counter %s: %v", metric.counterPath, err)
+			}
+			m.Log.Warnf("error while getting value for counter %q, will skip metric: %v", metric.counterPath, err)
+			return nil
+		}
+		addCounterMeasurement(metric, metric.instance, value, collectFields)
+		return nil
+	}
+
+	counterValues, err := m.query.GetFormattedCounterArrayDouble(metric.counterHandle)
+	if err != nil {
+		//ignore invalid data  as some counters from process instances returns this sometimes
+		if !isKnownCounterDataError(err) {
+			return fmt.Errorf("error while getting value for counter %s: %v", metric.counterPath, err)
+		}
+		m.Log.Warnf("error while getting value for counter %q, will skip metric: %v", metric.counterPath, err)
+		return nil
+	}
+	for _, cValue := range counterValues {
+
+		if strings.Contains(metric.instance, "#") && strings.HasPrefix(metric.instance, cValue.InstanceName) {
+			// If you are using a multiple instance identifier such as "w3wp#1"
+			// phd.dll returns only the first 2 characters of the identifier.
+			cValue.InstanceName = metric.instance
+		}
+
+		if shouldIncludeMetric(metric, cValue) {
+			addCounterMeasurement(metric, cValue.InstanceName, cValue.Value, collectFields)
+		}
+	}
+	return nil
+}
+
+// addRawCounter adds the values of the counter computed from the raw values
+// of this and the previous interval, which are kept in rawCounters. The values
+// of counter types not computed by the plugin are formatted by PDH.
+func (m *Win_PerfCounters) addRawCounter(metric *counter, rawCounters map[string]*rawCounter, collectFields map[instanceGrouping]map[string]interface{}) error {
+	previous := m.rawCounters[metric.counterPath]
+	current := &rawCounter{values: make(map[string]RawCounterValue)}
+	if previous != nil {
+		current.counterType = previous.counterType
+	} else {
+		counterType, err := m.query.GetCounterType(metric.counterHandle)
+		if err != nil {
+			return fmt.Errorf("error while getting type of counter %s: %v", metric.counterPath, err)
+		}
+		current.counterType = counterType
+	}
+	rawCounters[metric.counterPath] = current
+	if !rawCounterTypes[current.counterType] {
+		return m.addFormattedCounter(metric, collectFields)
+	}
+
+	var values []RawCounterValue
+	var err error
+	if m.UseWildcardsExpansion {
+		var value RawCounterValue
+		value, err = m.query.GetRawCounterValue(metric.counterHandle)
+		value.InstanceName = metric.instance
+		values = []RawCounterValue{value}
+	} else {
+		values, err = m.query.GetRawCounterArray(metric.counterHandle)
+	}
+	if err != nil {
+		//ignore invalid data  as some counters from process instances returns this sometimes
+		if !isKnownCounterDataError(err) {
+			return fmt.Errorf("error while getting value for counter %s: %v", metric.counterPath, err)
+		}
+		m.Log.Warnf("error while getting value for counter %q, will skip metric: %v", metric.counterPath, err)
+		return nil
+	}
+
+	for _, value := range values {
+		if !m.UseWildcardsExpansion {
+			if strings.Contains(metric.instance, "#") && strings.HasPrefix(metric.instance, value.InstanceName) {
+				// pdh.dll returns only the first characters of multiple instance identifiers such as "w3wp#1".
+				value.InstanceName = metric.instance
+			}
+			if !shouldIncludeMetric(metric, CounterValue{InstanceName: value.InstanceName}) {
+				continue
+			}
+		}
+		current.values[value.InstanceName] = value
+
+		var last *RawCounterValue
+		if previous != nil {
+			if v, ok := previous.values[value.InstanceName]; ok {
+				last = &v
+			}
+		}
+		if v, ok := computeRawValue(current.counterType, last, value); ok {
+			addCounterMeasurement(metric, value.InstanceName, v, collectFields)
+		}
+	}
+	return nil
+}
+
+// refreshInstances expands the wildcard counter paths again, adding the
+// counters of new instances and removing the counters of vanished ones without
+// reopening the query, so the values of the other counters are not lost.
+func (m *Win_PerfCounters) refreshInstances() {
+	known := make(map[string]bool, len(m.counters))
+	for _, metric := range m.counters {
+		known[metric.counterPath] = true
+	}
+
+	current := make(map[string]bool, len(m.counters))
+	failed := make(map[string]bool)
+	for _, wildcard := range m.wildcardPaths {
+		counterPaths, err := m.query.ExpandWildCardPath(wildcard.counterPath)
+		if err != nil {
+			// Keep the counters, the instances are checked again on the next refresh.
+			m.Log.Warnf("Expanding counter path %q failed: %v", wildcard.counterPath, err)
+			failed[wildcard.counterPath] = true
+			continue
+		}
+
+		for _, counterPath := range counterPaths {
+			current[counterPath] = true
+			if known[counterPath] {
+				continue
+			}
+
+			objectName, instance, counterName, err := extractCounterInfoFromCounterPath(counterPath)
+			if err != nil {
+				m.Log.Warnf("Parsing counter path %q failed: %v", counterPath, err)
+				continue
+			}
+			if instance == "_Total" && wildcard.instance == "*" && !wildcard.includeTotal {
+				continue
+			}
+			objectName, counterName = m.configuredName(objectName), m.configuredName(counterName)
+
+			counterHandle, err := m.query.AddCounterToQuery(counterPath)
+			if err != nil {
+				m.Log.Warnf("Adding counter %q failed: %v", counterPath, err)
+				continue
+			}
+			m.counters = append(m.counters, &counter{counterPath, objectName, counterName, instance,
+				wildcard.measurement, wildcard.includeTotal, counterHandle, wildcard.counterPath})
+			known[counterPath] = true
+			m.Log.Debugf("Added counter %q", counterPath)
+		}
+	}
+
+	counters := m.counters[:0]
+	for _, metric := range m.counters {
+		if metric.wildcardPath == "" || failed[metric.wildcardPath] || current[metric.counterPath] {
+			counters = append(counters, metric)
+			continue
+		}
+		if err := m.query.RemoveCounterFromQuery(metric.counterHandle); err != nil {
+			m.Log.Warnf("Removing counter %q failed: %v", metric.counterPath, err)
+		}
+		m.Log.Debugf("Removed counter %q", metric.counterPath)
+	}
+	m.counters = counters
+}
+
 func shouldIncludeMetric(metric *counter, cValue CounterValue) bool {
 	if metric.includeTotal {
 		// If IncludeTotal is set, include all.
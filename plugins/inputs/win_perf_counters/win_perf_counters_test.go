@@ -26,6 +26,10 @@ type FakePerformanceQuery struct {
 	vistaAndNewer bool
 	expandPaths   map[string][]string
 	openCalled    bool
+	removed       []string
+	localNames    map[string]string
+	counterTypes  map[string]uint32
+	rawValues     map[string]RawCounterValue
 }
 
 var MetricTime = time.Date(2018, 5, 28, 12, 0, 0, 0, time.UTC)
@@ -79,6 +83,25 @@ func (m *FakePerformanceQuery) AddEnglishCounterToQuery(counterPath string) (PDH
 	}
 }
 
+func (m *FakePerformanceQuery) RemoveCounterFromQuery(counterHandle PDH_HCOUNTER) error {
+	if !m.openCalled {
+		return errors.New("RemoveCounterFromQuery: uninitialized query")
+	}
+	c := m.findCounterByHandle(counterHandle)
+	if c == nil {
+		return fmt.Errorf("RemoveCounterFromQuery: invalid handle: %d", counterHandle)
+	}
+	m.removed = append(m.removed, c.path)
+	return nil
+}
+
+func (m *FakePerformanceQuery) LocalizeName(name string) (string, error) {
+	if n, ok := m.localNames[name]; ok {
+		return n, nil
+	}
+	return "", fmt.Errorf("LocalizeName: unknown name: %s", name)
+}
+
 func (m *FakePerformanceQuery) GetCounterPath(counterHandle PDH_HCOUNTER) (string, error) {
 	for _, counter := range m.counters {
 		if counter.handle == counterHandle {
@@ -156,6 +179,59 @@ func (m *FakePerformanceQuery) GetFormattedCounterArrayDouble(hCounter PDH_HCOUN
 	return nil, fmt.Errorf("GetFormattedCounterArrayDouble: invalid counter : %d, no paths found", hCounter)
 }
 
+func (m *FakePerformanceQuery) GetCounterType(hCounter PDH_HCOUNTER) (uint32, error) {
+	c := m.findCounterByHandle(hCounter)
+	if c == nil {
+		return 0, fmt.Errorf("GetCounterType: invalid handle: %d", hCounter)
+	}
+	if t, ok := m.counterTypes[c.path]; ok {
+		return t, nil
+	}
+	return 0, fmt.Errorf("GetCounterType: no type for counter: %s", c.path)
+}
+
+func (m *FakePerformanceQuery) GetRawCounterValue(hCounter PDH_HCOUNTER) (RawCounterValue, error) {
+	if !m.openCalled {
+		return RawCounterValue{}, errors.New("GetRawCounterValue: uninitialized query")
+	}
+	c := m.findCounterByHandle(hCounter)
+	if c == nil {
+		return RawCounterValue{}, fmt.Errorf("GetRawCounterValue: invalid handle: %d", hCounter)
+	}
+	if c.status > 0 {
+		return RawCounterValue{}, NewPdhError(c.status)
+	}
+	return m.rawValues[c.path], nil
+}
+
+func (m *FakePerformanceQuery) GetRawCounterArray(hCounter PDH_HCOUNTER) ([]RawCounterValue, error) {
+	if !m.openCalled {
+		return nil, errors.New("GetRawCounterArray: uninitialized query")
+	}
+	c := m.findCounterByHandle(hCounter)
+	if c == nil {
+		return nil, fmt.Errorf("GetRawCounterArray: invalid handle: %d", hCounter)
+	}
+	e, ok := m.expandPaths[c.path]
+	if !ok {
+		return nil, fmt.Errorf("GetRawCounterArray: invalid counter : %d", hCounter)
+	}
+	values := make([]RawCounterValue, 0, len(e))
+	for _, p := range e {
+		counter := m.findCounterByPath(p)
+		if counter == nil {
+			return nil, fmt.Errorf("GetRawCounterArray: invalid counter : %s", p)
+		}
+		if counter.status > 0 {
+			return nil, NewPdhError(counter.status)
+		}
+		value := m.rawValues[p]
+		value.InstanceName = counter.ToCounterValue().InstanceName
+		values = append(values, value)
+	}
+	return values, nil
+}
+
 func (m *FakePerformanceQuery) CollectData() error {
 	if !m.openCalled {
 		return errors.New("CollectData: uninitialized query")
@@ -328,6 +404,52 @@ func TestParseConfigBasic(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestParseConfigLocalized(t *testing.T) {
+	perfObjects := createPerfObject("m", "Processor", []string{"*"}, []string{"6", "% Idle Time"}, true, false)
+	cps := []string{"\\Prozessor(*)\\Prozessorzeit (%)", "\\Prozessor(*)\\Leerlaufzeit (%)",
+		"\\Prozessor(0)\\Prozessorzeit (%)", "\\Prozessor(0)\\Leerlaufzeit (%)"}
+	m := Win_PerfCounters{
+		Log:                  testutil.Logger{},
+		Object:               perfObjects,
+		LocalizeCounterNames: true,
+		query: &FakePerformanceQuery{
+			counters: createCounterMap(cps, []float64{0, 0, 1.1, 1.2}, []uint32{0, 0, 0, 0}),
+			expandPaths: map[string][]string{
+				cps[0]: {cps[2]},
+				cps[1]: {cps[3]},
+			},
+			localNames: map[string]string{
+				"Processor":   "Prozessor",
+				"6":           "Prozessorzeit (%)",
+				"% Idle Time": "Leerlaufzeit (%)",
+			},
+			vistaAndNewer: true,
+		}}
+	require.NoError(t, m.query.Open())
+	require.NoError(t, m.ParseConfig())
+	require.Len(t, m.counters, 2)
+	require.Equal(t, cps[0], m.counters[0].counterPath)
+	require.Equal(t, "6", m.counters[0].counter)
+	require.NoError(t, m.query.Close())
+
+	m.UseWildcardsExpansion = true
+	m.counters = nil
+	require.NoError(t, m.query.Open())
+	require.NoError(t, m.ParseConfig())
+	require.Len(t, m.counters, 2)
+	require.Equal(t, cps[2], m.counters[0].counterPath)
+	require.Equal(t, "Processor", m.counters[0].objectName)
+	require.Equal(t, "6", m.counters[0].counter)
+	require.Equal(t, "% Idle Time", m.counters[1].counter)
+	require.NoError(t, m.query.Close())
+
+	m.Object = createPerfObject("m", "Unknown", []string{"*"}, []string{"6"}, true, false)
+	m.counters = nil
+	require.NoError(t, m.query.Open())
+	require.Error(t, m.ParseConfig())
+	require.NoError(t, m.query.Close())
+}
+
 func TestParseConfigNoInstance(t *testing.T) {
 	var err error
 	perfObjects := createPerfObject("m", "O", []string{"------"}, []string{"C1", "C2"}, false, false)
@@ -806,6 +928,56 @@ func TestGatherRefreshingWithExpansion(t *testing.T) {
 
 }
 
+func TestGatherRefreshingInstances(t *testing.T) {
+	measurement := "test"
+	perfObjects := createPerfObject(measurement, "O", []string{"*"}, []string{"C1"}, true, false)
+	cps := []string{"\\O(I1)\\C1", "\\O(I2)\\C1", "\\O(I3)\\C1", "\\O(_Total)\\C1", "\\O(*)\\C1"}
+	fpm := &FakePerformanceQuery{
+		counters: createCounterMap(cps, []float64{1.1, 1.2, 1.3, 1.4, 0}, []uint32{0, 0, 0, 0, 0}),
+		expandPaths: map[string][]string{
+			"\\O(*)\\C1": {"\\O(I1)\\C1", "\\O(I2)\\C1", "\\O(_Total)\\C1"},
+		},
+		vistaAndNewer: true,
+	}
+	m := Win_PerfCounters{
+		Log:                   testutil.Logger{},
+		Object:                perfObjects,
+		UseWildcardsExpansion: true,
+		RefreshInterval:       config.Duration(time.Millisecond),
+		query:                 fpm,
+	}
+
+	var acc1 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc1))
+	require.Len(t, m.counters, 2)
+	require.Len(t, acc1.Metrics, 2)
+
+	// I1 vanished and I3 appeared
+	fpm.expandPaths["\\O(*)\\C1"] = []string{"\\O(I2)\\C1", "\\O(I3)\\C1", "\\O(_Total)\\C1"}
+	time.Sleep(time.Duration(m.RefreshInterval))
+
+	var acc2 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc2))
+	require.Len(t, m.counters, 2)
+	require.Equal(t, []string{"\\O(I1)\\C1"}, fpm.removed)
+	require.Len(t, acc2.Metrics, 2)
+	acc2.AssertContainsTaggedFields(t, measurement,
+		map[string]interface{}{"C1": float32(1.2)},
+		map[string]string{"instance": "I2", "objectname": "O"})
+	acc2.AssertContainsTaggedFields(t, measurement,
+		map[string]interface{}{"C1": float32(1.3)},
+		map[string]string{"instance": "I3", "objectname": "O"})
+
+	// Failing expansion keeps the counters
+	delete(fpm.expandPaths, "\\O(*)\\C1")
+	time.Sleep(time.Duration(m.RefreshInterval))
+
+	var acc3 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc3))
+	require.Len(t, m.counters, 2)
+	require.Len(t, fpm.removed, 1)
+}
+
 func TestGatherRefreshingWithoutExpansion(t *testing.T) {
 	var err error
 	if testing.Short() {
@@ -1011,6 +1183,171 @@ var stringArraySingleItem = []string{
 	"\\\\T480\\PhysicalDisk(0 C:)\\Current Disk Queue Length",
 }
 
+func TestGatherRawValues(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping long taking test in short mode")
+	}
+	measurement := "test"
+	perfObjects := createPerfObject(measurement, "O", []string{"*"}, []string{"C1", "C2", "C3"}, true, false)
+	cps := []string{"\\O(I1)\\C1", "\\O(I2)\\C1", "\\O(*)\\C1", "\\O(I1)\\C2", "\\O(*)\\C2", "\\O(I1)\\C3", "\\O(*)\\C3"}
+	fpm := &FakePerformanceQuery{
+		counters: createCounterMap(cps, []float64{0, 0, 0, 0, 0, 5.5, 0}, []uint32{0, 0, 0, 0, 0, 0, 0}),
+		expandPaths: map[string][]string{
+			"\\O(*)\\C1": {"\\O(I1)\\C1", "\\O(I2)\\C1"},
+			"\\O(*)\\C2": {"\\O(I1)\\C2"},
+			"\\O(*)\\C3": {"\\O(I1)\\C3"},
+		},
+		counterTypes: map[string]uint32{
+			"\\O(*)\\C1": PERF_COUNTER_COUNTER,
+			"\\O(*)\\C2": PERF_COUNTER_LARGE_RAWCOUNT,
+			"\\O(*)\\C3": 0xFFFFFFFF,
+		},
+		rawValues: map[string]RawCounterValue{
+			"\\O(I1)\\C1": {Timestamp: MetricTime, FirstValue: 4294967000},
+			"\\O(I2)\\C1": {Timestamp: MetricTime, FirstValue: 100},
+			"\\O(I1)\\C2": {Timestamp: MetricTime, FirstValue: 42},
+		},
+		vistaAndNewer: true,
+	}
+	m := Win_PerfCounters{
+		Log:          testutil.Logger{},
+		Object:       perfObjects,
+		UseRawValues: true,
+		query:        fpm,
+	}
+
+	// Rates are not reported on the first interval
+	var acc1 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc1))
+	require.Len(t, acc1.Metrics, 1)
+	acc1.AssertContainsTaggedFields(t, measurement,
+		map[string]interface{}{"C2": float32(42), "C3": float32(5.5)},
+		map[string]string{"instance": "I1", "objectname": "O"})
+	require.True(t, acc1.HasTimestamp(measurement, MetricTime))
+
+	// The 32-bit counter of I1 wrapped around
+	fpm.rawValues = map[string]RawCounterValue{
+		"\\O(I1)\\C1": {Timestamp: MetricTime.Add(2 * time.Second), FirstValue: 704},
+		"\\O(I2)\\C1": {Timestamp: MetricTime.Add(2 * time.Second), FirstValue: 150},
+		"\\O(I1)\\C2": {Timestamp: MetricTime.Add(2 * time.Second), FirstValue: 43},
+	}
+	var acc2 testutil.Accumulator
+	require.NoError(t, m.Gather(&acc2))
+	require.Len(t, acc2.Metrics, 2)
+	acc2.AssertContainsTaggedFields(t, measurement,
+		map[string]interface{}{"C1": float32(500), "C2": float32(43), "C3": float32(5.5)},
+		map[string]string{"instance": "I1", "objectname": "O"})
+	acc2.AssertContainsTaggedFields(t, measurement,
+		map[string]interface{}{"C1": float32(25)},
+		map[string]string{"instance": "I2", "objectname": "O"})
+}
+
+func TestComputeRawValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		counterType uint32
+		previous    *RawCounterValue
+		current     RawCounterValue
+		expected    float64
+		ok          bool
+	}{
+		{
+			name:        "raw count",
+			counterType: PERF_COUNTER_RAWCOUNT,
+			current:     RawCounterValue{FirstValue: 7},
+			expected:    7,
+			ok:          true,
+		},
+		{
+			name:        "raw fraction",
+			counterType: PERF_RAW_FRACTION,
+			current:     RawCounterValue{FirstValue: 25, SecondValue: 200},
+			expected:    12.5,
+			ok:          true,
+		},
+		{
+			name:        "rate without previous sample",
+			counterType: PERF_COUNTER_BULK_COUNT,
+			current:     RawCounterValue{Timestamp: MetricTime, FirstValue: 100},
+		},
+		{
+			name:        "rate",
+			counterType: PERF_COUNTER_BULK_COUNT,
+			previous:    &RawCounterValue{Timestamp: MetricTime, FirstValue: 100},
+			current:     RawCounterValue{Timestamp: MetricTime.Add(500 * time.Millisecond), FirstValue: 300},
+			expected:    400,
+			ok:          true,
+		},
+		{
+			name:        "rate of reset 64-bit counter",
+			counterType: PERF_COUNTER_BULK_COUNT,
+			previous:    &RawCounterValue{Timestamp: MetricTime, FirstValue: 300},
+			current:     RawCounterValue{Timestamp: MetricTime.Add(time.Second), FirstValue: 100},
+		},
+		{
+			name:        "rate of same sample",
+			counterType: PERF_COUNTER_COUNTER,
+			previous:    &RawCounterValue{Timestamp: MetricTime, FirstValue: 100},
+			current:     RawCounterValue{Timestamp: MetricTime, FirstValue: 100},
+		},
+		{
+			name:        "delta of wrapped 32-bit counter",
+			counterType: PERF_COUNTER_DELTA,
+			previous:    &RawCounterValue{FirstValue: 4294967290},
+			current:     RawCounterValue{FirstValue: 4},
+			expected:    10,
+			ok:          true,
+		},
+		{
+			name:        "100ns timer",
+			counterType: PERF_100NSEC_TIMER,
+			previous:    &RawCounterValue{FirstValue: 1000, SecondValue: 10000},
+			current:     RawCounterValue{FirstValue: 3500, SecondValue: 20000},
+			expected:    25,
+			ok:          true,
+		},
+		{
+			name:        "inverse 100ns timer",
+			counterType: PERF_100NSEC_TIMER_INV,
+			previous:    &RawCounterValue{FirstValue: 1000, SecondValue: 10000},
+			current:     RawCounterValue{FirstValue: 3500, SecondValue: 20000},
+			expected:    75,
+			ok:          true,
+		},
+		{
+			name:        "average",
+			counterType: PERF_AVERAGE_BULK,
+			previous:    &RawCounterValue{FirstValue: 4096, SecondValue: 1},
+			current:     RawCounterValue{FirstValue: 20480, SecondValue: 3},
+			expected:    8192,
+			ok:          true,
+		},
+		{
+			name:        "average without operations",
+			counterType: PERF_AVERAGE_BULK,
+			previous:    &RawCounterValue{FirstValue: 4096, SecondValue: 1},
+			current:     RawCounterValue{FirstValue: 4096, SecondValue: 1},
+			expected:    0,
+			ok:          true,
+		},
+		{
+			name:        "queue length",
+			counterType: PERF_COUNTER_100NS_QUEUELEN_TYPE,
+			previous:    &RawCounterValue{FirstValue: 0, SecondValue: 10000},
+			current:     RawCounterValue{FirstValue: 15000, SecondValue: 20000},
+			expected:    1.5,
+			ok:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := computeRawValue(tt.counterType, tt.previous, tt.current)
+			require.Equal(t, tt.ok, ok)
+			require.InDelta(t, tt.expected, value, 1e-9)
+		})
+	}
+}
+
 func TestUTF16ToStringArray(t *testing.T) {
 	singleItem := UTF16ToStringArray(unicodeStringListSingleItem)
 	assert.True(t, assert.ObjectsAreEqual(singleItem, stringArraySingleItem), "Not equal single arrays")
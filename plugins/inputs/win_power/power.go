@@ -0,0 +1,67 @@
+//go:build windows
+// +build windows
+
+package win_power
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modkernel32 = windows.NewLazySystemDLL("kernel32.dll")
+	modpowrprof = windows.NewLazySystemDLL("powrprof.dll")
+
+	procGetSystemPowerStatus  = modkernel32.NewProc("GetSystemPowerStatus")
+	procPowerGetActiveScheme  = modpowrprof.NewProc("PowerGetActiveScheme")
+	procPowerReadFriendlyName = modpowrprof.NewProc("PowerReadFriendlyName")
+)
+
+// systemPowerStatus is SYSTEM_POWER_STATUS.
+type systemPowerStatus struct {
+	ACLineStatus        uint8
+	BatteryFlag         uint8
+	BatteryLifePercent  uint8
+	SystemStatusFlag    uint8
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// localPowerStatus returns the power status and the active power scheme of
+// the local host.
+func localPowerStatus() (*powerStatus, error) {
+	var s systemPowerStatus
+	r, _, err := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&s)))
+	if r == 0 {
+		return nil, err
+	}
+	status := &powerStatus{
+		acLineStatus:   s.ACLineStatus,
+		batteryFlag:    s.BatteryFlag,
+		batteryPercent: s.BatteryLifePercent,
+		batterySaver:   s.SystemStatusFlag == 1,
+		batteryTime:    s.BatteryLifeTime,
+	}
+
+	// The power scheme is left out if it cannot be read
+	var scheme *windows.GUID
+	r, _, _ = procPowerGetActiveScheme.Call(0, uintptr(unsafe.Pointer(&scheme)))
+	if r != 0 {
+		return status, nil
+	}
+	defer windows.LocalFree(windows.Handle(uintptr(unsafe.Pointer(scheme)))) //nolint:errcheck // nothing to do on error
+	status.schemeGUID = scheme.String()
+
+	var size uint32
+	r, _, _ = procPowerReadFriendlyName.Call(0, uintptr(unsafe.Pointer(scheme)), 0, 0, 0, uintptr(unsafe.Pointer(&size)))
+	if r != 0 || size < 2 {
+		return status, nil
+	}
+	name := make([]uint16, size/2)
+	r, _, _ = procPowerReadFriendlyName.Call(0, uintptr(unsafe.Pointer(scheme)), 0, 0, uintptr(unsafe.Pointer(&name[0])), uintptr(unsafe.Pointer(&size)))
+	if r == 0 {
+		status.scheme = windows.UTF16ToString(name)
+	}
+	return status, nil
+}
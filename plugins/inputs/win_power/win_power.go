@@ -0,0 +1,217 @@
+//go:build windows
+// +build windows
+
+package win_power
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Collect the capacity and health of the batteries.
+  # batteries = true
+
+  ## Collect the frequency and performance limits of the processors, e.g. by
+  ## thermal or power throttling.
+  # processor_throttling = true
+`
+
+const (
+	namespace        = `root\cimv2`
+	batteryNamespace = `root\wmi`
+)
+
+// Values of the system power status.
+const (
+	acLineOffline    = 0
+	acLineOnline     = 1
+	batteryNoBattery = 128
+	batteryUnknown   = 255
+	batteryTimeUnset = math.MaxUint32
+)
+
+// powerStatus is the power status of the host.
+type powerStatus struct {
+	acLineStatus   uint8
+	batteryFlag    uint8
+	batteryPercent uint8
+	batterySaver   bool
+	batteryTime    uint32
+	scheme         string
+	schemeGUID     string
+}
+
+// WinPower collects the power source, power scheme, batteries and processor
+// throttling of the host.
+type WinPower struct {
+	Batteries           bool `toml:"batteries"`
+	ProcessorThrottling bool `toml:"processor_throttling"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	status func() (*powerStatus, error)
+	query  wmi.QueryFunc
+}
+
+func (w *WinPower) Description() string {
+	return "Collect the power source, batteries and processor throttling"
+}
+
+func (w *WinPower) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinPower) Gather(acc telegraf.Accumulator) error {
+	status, err := w.status()
+	if err != nil {
+		return fmt.Errorf("querying power status failed: %w", err)
+	}
+
+	fields := map[string]interface{}{"battery_saver": status.batterySaver}
+	switch status.acLineStatus {
+	case acLineOffline:
+		fields["ac_online"] = false
+	case acLineOnline:
+		fields["ac_online"] = true
+	}
+	hasBattery := status.batteryFlag != batteryNoBattery && status.batteryFlag != batteryUnknown
+	if hasBattery && status.batteryPercent <= 100 {
+		fields["battery_charge_percent"] = uint64(status.batteryPercent)
+	}
+	if hasBattery && status.batteryTime != batteryTimeUnset {
+		fields["battery_remaining_seconds"] = uint64(status.batteryTime)
+	}
+	if status.scheme != "" {
+		fields["power_scheme"] = status.scheme
+	}
+	if status.schemeGUID != "" {
+		fields["power_scheme_guid"] = status.schemeGUID
+	}
+
+	if w.ProcessorThrottling {
+		err := w.query(namespace,
+			"SELECT ProcessorFrequency, PercentofMaximumFrequency, PercentPerformanceLimit, PerformanceLimitFlags FROM Win32_PerfFormattedData_Counters_ProcessorInformation WHERE Name = '_Total'",
+			func(p map[string]interface{}) error {
+				wmi.AddUintFields(fields, p, map[string]string{
+					"ProcessorFrequency":        "processor_frequency_mhz",
+					"PercentofMaximumFrequency": "processor_max_frequency_percent",
+					"PercentPerformanceLimit":   "processor_performance_limit_percent",
+					"PerformanceLimitFlags":     "processor_performance_limit_flags",
+				})
+				return nil
+			})
+		if err != nil {
+			acc.AddError(fmt.Errorf("querying processor information failed: %w", err))
+		}
+	}
+	acc.AddFields("win_power", fields, nil)
+
+	// The battery classes fail on hosts without batteries
+	if w.Batteries && hasBattery {
+		if err := w.gatherBatteries(acc); err != nil {
+			acc.AddError(fmt.Errorf("querying batteries failed: %w", err))
+		}
+	}
+	return nil
+}
+
+// gatherBatteries adds the batteries from the classes of the battery driver,
+// which are joined by their instance name.
+func (w *WinPower) gatherBatteries(acc telegraf.Accumulator) error {
+	type battery struct {
+		tags   map[string]string
+		fields map[string]interface{}
+	}
+	var instances []string
+	batteries := make(map[string]*battery)
+	err := w.query(batteryNamespace, "SELECT InstanceName, DeviceName, ManufactureName, DesignedCapacity FROM BatteryStaticData",
+		func(p map[string]interface{}) error {
+			instance := fmt.Sprint(p["InstanceName"])
+			b := &battery{
+				tags: map[string]string{
+					"battery":      fmt.Sprint(p["DeviceName"]),
+					"manufacturer": fmt.Sprint(p["ManufactureName"]),
+				},
+				fields: make(map[string]interface{}),
+			}
+			wmi.AddUintFields(b.fields, p, map[string]string{"DesignedCapacity": "designed_capacity_mwh"})
+			instances = append(instances, instance)
+			batteries[instance] = b
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	queries := []struct {
+		query  string
+		fields map[string]string
+	}{
+		{
+			query:  "SELECT InstanceName, FullChargedCapacity FROM BatteryFullChargedCapacity",
+			fields: map[string]string{"FullChargedCapacity": "full_charged_capacity_mwh"},
+		},
+		{
+			query: "SELECT InstanceName, RemainingCapacity, ChargeRate, DischargeRate, Voltage, Charging, Discharging FROM BatteryStatus",
+			fields: map[string]string{
+				"RemainingCapacity": "remaining_capacity_mwh",
+				"ChargeRate":        "charge_rate_mw",
+				"DischargeRate":     "discharge_rate_mw",
+				"Voltage":           "voltage_mv",
+			},
+		},
+		{
+			query:  "SELECT InstanceName, CycleCount FROM BatteryCycleCount",
+			fields: map[string]string{"CycleCount": "cycle_count"},
+		},
+	}
+	for _, q := range queries {
+		err := w.query(batteryNamespace, q.query, func(p map[string]interface{}) error {
+			b, ok := batteries[fmt.Sprint(p["InstanceName"])]
+			if !ok {
+				return nil
+			}
+			wmi.AddUintFields(b.fields, p, q.fields)
+			for property, field := range map[string]string{"Charging": "charging", "Discharging": "discharging"} {
+				if v, ok := p[property].(bool); ok {
+					b.fields[field] = v
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			acc.AddError(err)
+		}
+	}
+
+	for _, instance := range instances {
+		b := batteries[instance]
+		designed, _ := b.fields["designed_capacity_mwh"].(uint64)
+		full, _ := b.fields["full_charged_capacity_mwh"].(uint64)
+		remaining, hasRemaining := b.fields["remaining_capacity_mwh"].(uint64)
+		if designed > 0 && full > 0 {
+			b.fields["health_percent"] = float64(full) / float64(designed) * 100
+		}
+		if full > 0 && hasRemaining {
+			b.fields["charge_percent"] = float64(remaining) / float64(full) * 100
+		}
+		acc.AddFields("win_power_battery", b.fields, b.tags)
+	}
+	return nil
+}
+
+func init() {
+	inputs.Add("win_power", func() telegraf.Input {
+		return &WinPower{
+			Batteries:           true,
+			ProcessorThrottling: true,
+			status:              localPowerStatus,
+			query:               (&wmi.Connection{}).Query,
+		}
+	})
+}
@@ -0,0 +1,159 @@
+//go:build windows
+// +build windows
+
+package win_power
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeQuery returns the objects of the class of the query.
+func fakeQuery(objects map[string][]map[string]interface{}) wmi.QueryFunc {
+	return func(_, query string, fn func(properties map[string]interface{}) error) error {
+		for class, list := range objects {
+			if !strings.Contains(query, "FROM "+class) {
+				continue
+			}
+			for _, o := range list {
+				if err := fn(o); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func TestGather(t *testing.T) {
+	w := &WinPower{
+		Batteries:           true,
+		ProcessorThrottling: true,
+		Log:                 testutil.Logger{},
+		status: func() (*powerStatus, error) {
+			return &powerStatus{
+				acLineStatus:   acLineOffline,
+				batteryFlag:    0,
+				batteryPercent: 80,
+				batteryTime:    7200,
+				scheme:         "Balanced",
+				schemeGUID:     "{381b4222-f694-41f0-9685-ff5bb260df2e}",
+			}, nil
+		},
+		query: fakeQuery(map[string][]map[string]interface{}{
+			"Win32_PerfFormattedData_Counters_ProcessorInformation": {
+				{
+					"ProcessorFrequency":        "1800",
+					"PercentofMaximumFrequency": "60",
+					"PercentPerformanceLimit":   "100",
+					"PerformanceLimitFlags":     "0",
+				},
+			},
+			"BatteryStaticData": {
+				{"InstanceName": `ACPI\PNP0C0A\1_0`, "DeviceName": "DELL 3RNFD", "ManufactureName": "SMP", "DesignedCapacity": uint32(60000)},
+			},
+			"BatteryFullChargedCapacity": {
+				{"InstanceName": `ACPI\PNP0C0A\1_0`, "FullChargedCapacity": uint32(48000)},
+			},
+			"BatteryStatus": {
+				{
+					"InstanceName":      `ACPI\PNP0C0A\1_0`,
+					"RemainingCapacity": uint32(38400),
+					"ChargeRate":        int32(0),
+					"DischargeRate":     int32(9500),
+					"Voltage":           uint32(12100),
+					"Charging":          false,
+					"Discharging":       true,
+				},
+			},
+			"BatteryCycleCount": {
+				{"InstanceName": `ACPI\PNP0C0A\1_0`, "CycleCount": uint32(212)},
+			},
+		}),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_power",
+			map[string]string{},
+			map[string]interface{}{
+				"ac_online":                           false,
+				"battery_saver":                       false,
+				"battery_charge_percent":              uint64(80),
+				"battery_remaining_seconds":           uint64(7200),
+				"power_scheme":                        "Balanced",
+				"power_scheme_guid":                   "{381b4222-f694-41f0-9685-ff5bb260df2e}",
+				"processor_frequency_mhz":             uint64(1800),
+				"processor_max_frequency_percent":     uint64(60),
+				"processor_performance_limit_percent": uint64(100),
+				"processor_performance_limit_flags":   uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_power_battery",
+			map[string]string{"battery": "DELL 3RNFD", "manufacturer": "SMP"},
+			map[string]interface{}{
+				"designed_capacity_mwh":     uint64(60000),
+				"full_charged_capacity_mwh": uint64(48000),
+				"remaining_capacity_mwh":    uint64(38400),
+				"charge_rate_mw":            uint64(0),
+				"discharge_rate_mw":         uint64(9500),
+				"voltage_mv":                uint64(12100),
+				"charging":                  false,
+				"discharging":               true,
+				"cycle_count":               uint64(212),
+				"health_percent":            float64(80),
+				"charge_percent":            float64(80),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherNoBattery(t *testing.T) {
+	w := &WinPower{
+		Batteries: true,
+		Log:       testutil.Logger{},
+		status: func() (*powerStatus, error) {
+			return &powerStatus{
+				acLineStatus:   acLineOnline,
+				batteryFlag:    batteryNoBattery,
+				batteryPercent: batteryUnknown,
+				batteryTime:    batteryTimeUnset,
+			}, nil
+		},
+		query: func(string, string, func(map[string]interface{}) error) error {
+			require.FailNow(t, "batteries queried")
+			return nil
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_power",
+			map[string]string{},
+			map[string]interface{}{
+				"ac_online":     true,
+				"battery_saver": false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,176 @@
+//go:build windows
+// +build windows
+
+package win_printing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Names of the printers to collect, all if empty. Globs accepted, the names
+  ## are case-insensitive.
+  # printers = []
+`
+
+const namespace = `root\cimv2`
+
+// Names of the printer states and detected error states.
+var (
+	printerStates = map[int64]string{
+		1: "other",
+		2: "unknown",
+		3: "idle",
+		4: "printing",
+		5: "warmup",
+		6: "stopped",
+		7: "offline",
+	}
+	errorStates = map[int64]string{
+		0:  "unknown",
+		1:  "other",
+		2:  "no_error",
+		3:  "low_paper",
+		4:  "no_paper",
+		5:  "low_toner",
+		6:  "no_toner",
+		7:  "door_open",
+		8:  "jammed",
+		9:  "offline",
+		10: "service_requested",
+		11: "output_bin_full",
+	}
+)
+
+// WinPrinting collects the print queues and the status of the printers.
+type WinPrinting struct {
+	Printers []string `toml:"printers"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter filter.Filter
+	query  wmi.QueryFunc
+}
+
+func (w *WinPrinting) Description() string {
+	return "Collect the print queues and status of the printers"
+}
+
+func (w *WinPrinting) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinPrinting) Init() error {
+	// Backslashes are escape characters in globs, so the names of network
+	// printers are matched with slashes as separators.
+	names := make([]string, 0, len(w.Printers))
+	for _, name := range w.Printers {
+		names = append(names, filterName(name))
+	}
+	f, err := filter.Compile(names)
+	if err != nil {
+		return fmt.Errorf("compiling printers failed: %w", err)
+	}
+	w.filter = f
+	return nil
+}
+
+func (w *WinPrinting) Gather(acc telegraf.Accumulator) error {
+	type printer struct {
+		tags   map[string]string
+		fields map[string]interface{}
+	}
+	var names []string
+	printers := make(map[string]*printer)
+	err := w.query(namespace, "SELECT Name, PortName, Shared, PrinterStatus, DetectedErrorState, WorkOffline FROM Win32_Printer",
+		func(p map[string]interface{}) error {
+			name := fmt.Sprint(p["Name"])
+			if w.filter != nil && !w.filter.Match(filterName(name)) {
+				return nil
+			}
+			pr := &printer{
+				tags:   map[string]string{"printer": name},
+				fields: make(map[string]interface{}),
+			}
+			if port, ok := p["PortName"].(string); ok && port != "" {
+				pr.tags["port"] = port
+			}
+			if shared, ok := p["Shared"].(bool); ok {
+				pr.tags["shared"] = strconv.FormatBool(shared)
+			}
+			if offline, ok := p["WorkOffline"].(bool); ok {
+				pr.fields["offline"] = offline
+			}
+			addStateFields(pr.fields, "status", printerStates, p["PrinterStatus"])
+			addStateFields(pr.fields, "error_state", errorStates, p["DetectedErrorState"])
+
+			names = append(names, name)
+			printers[strings.ToLower(name)] = pr
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("querying printers failed: %w", err)
+	}
+
+	err = w.query(namespace,
+		"SELECT Name, Jobs, JobErrors, JobsSpooling, NotReadyErrors, OutofPaperErrors, TotalJobsPrinted, TotalPagesPrinted FROM Win32_PerfRawData_Spooler_PrintQueue",
+		func(p map[string]interface{}) error {
+			pr, ok := printers[strings.ToLower(fmt.Sprint(p["Name"]))]
+			if !ok {
+				return nil
+			}
+			wmi.AddUintFields(pr.fields, p, map[string]string{
+				"Jobs":              "jobs",
+				"JobErrors":         "job_errors",
+				"JobsSpooling":      "jobs_spooling",
+				"NotReadyErrors":    "not_ready_errors",
+				"OutofPaperErrors":  "out_of_paper_errors",
+				"TotalJobsPrinted":  "jobs_printed",
+				"TotalPagesPrinted": "pages_printed",
+			})
+			return nil
+		})
+	if err != nil {
+		acc.AddError(fmt.Errorf("querying print queues failed: %w", err))
+	}
+
+	for _, name := range names {
+		pr := printers[strings.ToLower(name)]
+		acc.AddFields("win_printing", pr.fields, pr.tags)
+	}
+	return nil
+}
+
+// filterName returns the name of the printer as matched by the filter.
+func filterName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), `\`, "/")
+}
+
+// addStateFields adds the state and its code as fields with the prefix.
+func addStateFields(fields map[string]interface{}, prefix string, names map[int64]string, value interface{}) {
+	code, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil {
+		return
+	}
+	state, ok := names[code]
+	if !ok {
+		state = "unknown"
+	}
+	fields[prefix] = state
+	fields[prefix+"_code"] = code
+}
+
+func init() {
+	inputs.Add("win_printing", func() telegraf.Input {
+		return &WinPrinting{
+			query: (&wmi.Connection{}).Query,
+		}
+	})
+}
@@ -0,0 +1,152 @@
+//go:build windows
+// +build windows
+
+package win_printing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var printers = []map[string]interface{}{
+	{
+		"Name":               "Office HP",
+		"PortName":           "IP_10.0.0.20",
+		"Shared":             true,
+		"PrinterStatus":      uint16(3),
+		"DetectedErrorState": uint16(2),
+		"WorkOffline":        false,
+	},
+	{
+		"Name":               `\\PRINT01\Warehouse`,
+		"PortName":           "IP_10.0.1.30",
+		"Shared":             false,
+		"PrinterStatus":      uint16(7),
+		"DetectedErrorState": uint16(4),
+		"WorkOffline":        true,
+	},
+	{
+		"Name":          "Microsoft Print to PDF",
+		"PortName":      "PORTPROMPT:",
+		"Shared":        false,
+		"PrinterStatus": uint16(3),
+		"WorkOffline":   false,
+	},
+}
+
+var queues = []map[string]interface{}{
+	{"Name": "_Total", "Jobs": uint32(4)},
+	{
+		"Name":              "Office HP",
+		"Jobs":              uint32(3),
+		"JobErrors":         uint32(1),
+		"JobsSpooling":      uint32(0),
+		"NotReadyErrors":    uint32(2),
+		"OutofPaperErrors":  uint32(0),
+		"TotalJobsPrinted":  uint32(1500),
+		"TotalPagesPrinted": uint32(7200),
+	},
+}
+
+// fakeQuery returns the printers and the print queues.
+func fakeQuery(queueErr error) wmi.QueryFunc {
+	return func(_, query string, fn func(properties map[string]interface{}) error) error {
+		objects := printers
+		if strings.Contains(query, "PrintQueue") {
+			if queueErr != nil {
+				return queueErr
+			}
+			objects = queues
+		}
+		for _, o := range objects {
+			if err := fn(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestGather(t *testing.T) {
+	w := &WinPrinting{
+		Printers: []string{"office*", `\\print01\*`},
+		Log:      testutil.Logger{},
+		query:    fakeQuery(nil),
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_printing",
+			map[string]string{"printer": "Office HP", "port": "IP_10.0.0.20", "shared": "true"},
+			map[string]interface{}{
+				"status":              "idle",
+				"status_code":         int64(3),
+				"error_state":         "no_error",
+				"error_state_code":    int64(2),
+				"offline":             false,
+				"jobs":                uint64(3),
+				"job_errors":          uint64(1),
+				"jobs_spooling":       uint64(0),
+				"not_ready_errors":    uint64(2),
+				"out_of_paper_errors": uint64(0),
+				"jobs_printed":        uint64(1500),
+				"pages_printed":       uint64(7200),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_printing",
+			map[string]string{"printer": `\\PRINT01\Warehouse`, "port": "IP_10.0.1.30", "shared": "false"},
+			map[string]interface{}{
+				"status":           "offline",
+				"status_code":      int64(7),
+				"error_state":      "no_paper",
+				"error_state_code": int64(4),
+				"offline":          true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherQueueError(t *testing.T) {
+	w := &WinPrinting{
+		Printers: []string{"Microsoft Print to PDF"},
+		Log:      testutil.Logger{},
+		query:    fakeQuery(errors.New("invalid class")),
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+
+	// The status is added without the queue
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_printing",
+			map[string]string{"printer": "Microsoft Print to PDF", "port": "PORTPROMPT:", "shared": "false"},
+			map[string]interface{}{
+				"status":      "idle",
+				"status_code": int64(3),
+				"offline":     false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,80 @@
+//go:build windows
+// +build windows
+
+package win_registry
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// registryRoots are the root keys by abbreviation.
+var registryRoots = map[string]registry.Key{
+	"HKLM": registry.LOCAL_MACHINE,
+	"HKCU": registry.CURRENT_USER,
+	"HKU":  registry.USERS,
+	"HKCR": registry.CLASSES_ROOT,
+	"HKCC": registry.CURRENT_CONFIG,
+}
+
+// registryReader reads the registry of the local host.
+type registryReader struct{}
+
+func (registryReader) subKeys(root, path string) ([]string, error) {
+	key, err := openKey(root, path, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	return key.ReadSubKeyNames(-1)
+}
+
+func (registryReader) values(root, path string) (map[string]interface{}, error) {
+	key, err := openKey(root, path, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		_, typ, err := key.GetValue(name, nil)
+		if err != nil {
+			// The value may have been deleted after listing
+			continue
+		}
+		switch typ {
+		case registry.DWORD, registry.QWORD:
+			if v, _, err := key.GetIntegerValue(name); err == nil {
+				values[name] = v
+			}
+		case registry.SZ, registry.EXPAND_SZ:
+			if v, _, err := key.GetStringValue(name); err == nil {
+				values[name] = v
+			}
+		case registry.MULTI_SZ:
+			if v, _, err := key.GetStringsValue(name); err == nil {
+				values[name] = strings.Join(v, ",")
+			}
+		}
+	}
+	return values, nil
+}
+
+// openKey opens the key with the access, errNotExist is returned if the key
+// does not exist.
+func openKey(root, path string, access uint32) (registry.Key, error) {
+	key, err := registry.OpenKey(registryRoots[root], path, access)
+	if errors.Is(err, registry.ErrNotExist) {
+		return key, errNotExist
+	}
+	return key, err
+}
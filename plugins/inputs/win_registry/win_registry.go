@@ -0,0 +1,251 @@
+//go:build windows
+// +build windows
+
+package win_registry
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  [[inputs.win_registry.key]]
+    ## Path of the key including the root key, one of HKLM, HKCU, HKU, HKCR
+    ## or HKCC. Globs accepted in the subkey names, all matching keys are
+    ## read.
+    path = 'HKLM\SOFTWARE\Microsoft\Windows NT\CurrentVersion'
+
+    ## Names of the values to read, all if empty. Globs accepted.
+    values = ["CurrentBuild", "UBR"]
+
+    ## Name of the measurement
+    # measurement = "win_registry"
+
+    ## Types of the values: "int", "uint", "float", "string" or "bool". By
+    ## default DWORD and QWORD values are unsigned integers, string values
+    ## are strings and multi-string values are joined by commas.
+    [inputs.win_registry.key.types]
+      CurrentBuild = "uint"
+`
+
+// rootKeys are the abbreviations of the root keys by abbreviation and full
+// name.
+var rootKeys = map[string]string{
+	"HKLM":                "HKLM",
+	"HKCU":                "HKCU",
+	"HKU":                 "HKU",
+	"HKCR":                "HKCR",
+	"HKCC":                "HKCC",
+	"HKEY_LOCAL_MACHINE":  "HKLM",
+	"HKEY_CURRENT_USER":   "HKCU",
+	"HKEY_USERS":          "HKU",
+	"HKEY_CLASSES_ROOT":   "HKCR",
+	"HKEY_CURRENT_CONFIG": "HKCC",
+}
+
+// errNotExist is returned by the reader if the key does not exist.
+var errNotExist = errors.New("key does not exist")
+
+// reader reads the registry, the root key is given by its abbreviation and
+// the path is relative to the root key.
+type reader interface {
+	subKeys(root, path string) ([]string, error)
+	values(root, path string) (map[string]interface{}, error)
+}
+
+// WinRegistry reads values of the Windows registry.
+type WinRegistry struct {
+	Keys []Key `toml:"key"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	reader reader
+}
+
+// Key is a registry key and the values read from it.
+type Key struct {
+	Path        string            `toml:"path"`
+	Values      []string          `toml:"values"`
+	Measurement string            `toml:"measurement"`
+	Types       map[string]string `toml:"types"`
+
+	root        string
+	subKeys     []string
+	valueFilter filter.Filter
+}
+
+func (w *WinRegistry) Description() string {
+	return "Read values of the Windows registry"
+}
+
+func (w *WinRegistry) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinRegistry) Init() error {
+	if len(w.Keys) == 0 {
+		return errors.New("no keys configured")
+	}
+	for i := range w.Keys {
+		k := &w.Keys[i]
+		parts := strings.Split(strings.Trim(k.Path, `\`), `\`)
+		root, ok := rootKeys[strings.ToUpper(parts[0])]
+		if !ok {
+			return fmt.Errorf("invalid root key of path %q", k.Path)
+		}
+		k.root, k.subKeys = root, parts[1:]
+
+		var err error
+		if k.valueFilter, err = filter.Compile(k.Values); err != nil {
+			return fmt.Errorf("compiling values of path %q failed: %w", k.Path, err)
+		}
+		if k.Measurement == "" {
+			k.Measurement = "win_registry"
+		}
+		for name, typ := range k.Types {
+			if _, err := convertValue("", typ); errors.Is(err, errUnknownType) {
+				return fmt.Errorf("invalid type %q of value %q", typ, name)
+			}
+		}
+	}
+	return nil
+}
+
+func (w *WinRegistry) Gather(acc telegraf.Accumulator) error {
+	for i := range w.Keys {
+		k := &w.Keys[i]
+		paths, err := w.expand(k.root, "", k.subKeys)
+		if err != nil {
+			acc.AddError(fmt.Errorf("expanding path %q failed: %w", k.Path, err))
+			continue
+		}
+		for _, path := range paths {
+			if err := w.gatherKey(acc, k, path); err != nil {
+				acc.AddError(fmt.Errorf("reading key %q failed: %w", keyName(k.root, path), err))
+			}
+		}
+	}
+	return nil
+}
+
+// expand returns the paths of the existing keys matching the subkey names
+// below the parent path.
+func (w *WinRegistry) expand(root, parent string, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return []string{parent}, nil
+	}
+
+	var matches []string
+	if !strings.ContainsAny(names[0], "*?[") {
+		matches = []string{names[0]}
+	} else {
+		// Key names are case-insensitive.
+		f, err := filter.Compile([]string{strings.ToLower(names[0])})
+		if err != nil {
+			return nil, err
+		}
+		subKeys, err := w.reader.subKeys(root, parent)
+		if errors.Is(err, errNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range subKeys {
+			if f.Match(strings.ToLower(name)) {
+				matches = append(matches, name)
+			}
+		}
+	}
+
+	var paths []string
+	for _, name := range matches {
+		path := name
+		if parent != "" {
+			path = parent + `\` + name
+		}
+		expanded, err := w.expand(root, path, names[1:])
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, expanded...)
+	}
+	return paths, nil
+}
+
+// gatherKey adds the matching values of the key as metric.
+func (w *WinRegistry) gatherKey(acc telegraf.Accumulator, k *Key, path string) error {
+	values, err := w.reader.values(k.root, path)
+	if errors.Is(err, errNotExist) && strings.ContainsAny(k.Path, "*?[") {
+		// The key was deleted after expanding the globs
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{})
+	for name, value := range values {
+		if k.valueFilter != nil && !k.valueFilter.Match(name) {
+			continue
+		}
+		field := name
+		if field == "" {
+			field = "default"
+		}
+		if typ, ok := k.Types[name]; ok {
+			converted, err := convertValue(value, typ)
+			if err != nil {
+				w.Log.Warnf("Converting value %q of key %q failed: %v", name, path, err)
+				continue
+			}
+			value = converted
+		}
+		fields[field] = value
+	}
+	if len(fields) > 0 {
+		acc.AddFields(k.Measurement, fields, map[string]string{"key": keyName(k.root, path)})
+	}
+	return nil
+}
+
+// keyName returns the full name of the key.
+func keyName(root, path string) string {
+	if path == "" {
+		return root
+	}
+	return root + `\` + path
+}
+
+var errUnknownType = errors.New("unknown type")
+
+// convertValue converts the registry value to the type.
+func convertValue(value interface{}, typ string) (interface{}, error) {
+	s := strings.TrimSpace(fmt.Sprint(value))
+	switch typ {
+	case "int":
+		return strconv.ParseInt(s, 10, 64)
+	case "uint":
+		return strconv.ParseUint(s, 10, 64)
+	case "float":
+		return strconv.ParseFloat(s, 64)
+	case "string":
+		return s, nil
+	case "bool":
+		return strconv.ParseBool(s)
+	default:
+		return nil, errUnknownType
+	}
+}
+
+func init() {
+	inputs.Add("win_registry", func() telegraf.Input {
+		return &WinRegistry{reader: registryReader{}}
+	})
+}
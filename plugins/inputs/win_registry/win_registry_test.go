@@ -0,0 +1,141 @@
+//go:build windows
+// +build windows
+
+package win_registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeReader reads the keys from maps by root and path.
+type fakeReader map[string]map[string]interface{}
+
+func (r fakeReader) subKeys(root, path string) ([]string, error) {
+	prefix := keyName(root, path) + `\`
+	var names []string
+	for name := range r {
+		if len(name) > len(prefix) && name[:len(prefix)] == prefix {
+			names = append(names, name[len(prefix):])
+		}
+	}
+	if len(names) == 0 {
+		return nil, errNotExist
+	}
+	return names, nil
+}
+
+func (r fakeReader) values(root, path string) (map[string]interface{}, error) {
+	values, ok := r[keyName(root, path)]
+	if !ok {
+		return nil, errNotExist
+	}
+	return values, nil
+}
+
+func TestInit(t *testing.T) {
+	w := &WinRegistry{Keys: []Key{{Path: `HKEY_LOCAL_MACHINE\SOFTWARE\App`}}}
+	require.NoError(t, w.Init())
+	require.Equal(t, "HKLM", w.Keys[0].root)
+	require.Equal(t, []string{"SOFTWARE", "App"}, w.Keys[0].subKeys)
+	require.Equal(t, "win_registry", w.Keys[0].Measurement)
+
+	w = &WinRegistry{}
+	require.Error(t, w.Init())
+
+	w = &WinRegistry{Keys: []Key{{Path: `HKXX\SOFTWARE\App`}}}
+	require.Error(t, w.Init())
+
+	w = &WinRegistry{Keys: []Key{{Path: `HKLM\SOFTWARE\App`, Types: map[string]string{"Count": "uint128"}}}}
+	require.Error(t, w.Init())
+}
+
+func TestGather(t *testing.T) {
+	w := &WinRegistry{
+		Keys: []Key{
+			{
+				Path:   `HKLM\SOFTWARE\App`,
+				Values: []string{"License*"},
+				Types:  map[string]string{"LicenseCount": "int"},
+			},
+			{
+				Path:        `HKLM\SOFTWARE\App\Instances\*`,
+				Measurement: "app_instance",
+			},
+		},
+		Log: testutil.Logger{},
+		reader: fakeReader{
+			`HKLM\SOFTWARE\App`: {
+				"LicenseCount": "25",
+				"LicenseUsed":  uint64(12),
+				"Version":      "1.2.3",
+			},
+			`HKLM\SOFTWARE\App\Instances\Blue`: {
+				"":          "blue",
+				"Requests":  uint64(1000),
+				"Endpoints": "a,b",
+			},
+			`HKLM\SOFTWARE\App\Instances\Green`: {
+				"Requests": uint64(42),
+			},
+		},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"app_instance",
+			map[string]string{"key": `HKLM\SOFTWARE\App\Instances\Blue`},
+			map[string]interface{}{
+				"default":   "blue",
+				"Requests":  uint64(1000),
+				"Endpoints": "a,b",
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"app_instance",
+			map[string]string{"key": `HKLM\SOFTWARE\App\Instances\Green`},
+			map[string]interface{}{
+				"Requests": uint64(42),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_registry",
+			map[string]string{"key": `HKLM\SOFTWARE\App`},
+			map[string]interface{}{
+				"LicenseCount": int64(25),
+				"LicenseUsed":  uint64(12),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherMissingKey(t *testing.T) {
+	w := &WinRegistry{
+		Keys: []Key{
+			{Path: `HKLM\SOFTWARE\Missing`},
+			{Path: `HKLM\SOFTWARE\Missing\*`},
+		},
+		Log:    testutil.Logger{},
+		reader: fakeReader{},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,110 @@
+//go:build windows
+// +build windows
+
+package win_software
+
+import (
+	"errors"
+	"runtime"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// localMachineKeys returns the uninstall keys of the applications installed
+// for all users. 64-bit Windows keeps the 32-bit applications in a separate
+// view of the registry.
+func localMachineKeys() []uninstallKey {
+	is64Bit := runtime.GOARCH != "386"
+	if !is64Bit {
+		var wow64 bool
+		is64Bit = windows.IsWow64Process(windows.CurrentProcess(), &wow64) == nil && wow64
+	}
+	if !is64Bit {
+		return []uninstallKey{{root: "HKLM", path: uninstallPath, architecture: "x86"}}
+	}
+	return []uninstallKey{
+		{root: "HKLM", path: uninstallPath, architecture: "x64"},
+		{root: "HKLM", path: uninstallPath, wow64: true, architecture: "x86"},
+	}
+}
+
+// registryReader reads the registry of the local host.
+type registryReader struct{}
+
+func (registryReader) users() ([]string, error) {
+	key, err := registry.OpenKey(registry.USERS, "", registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	return key.ReadSubKeyNames(-1)
+}
+
+func (registryReader) entries(k uninstallKey) (map[string]map[string]interface{}, error) {
+	root := registry.LOCAL_MACHINE
+	if k.root == "HKU" {
+		root = registry.USERS
+	}
+	view := uint32(registry.WOW64_64KEY)
+	if k.wow64 {
+		view = registry.WOW64_32KEY
+	}
+
+	key, err := registry.OpenKey(root, k.path, registry.ENUMERATE_SUB_KEYS|view)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	names, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]map[string]interface{}, len(names))
+	for _, name := range names {
+		values, err := readValues(key, name, view)
+		if err != nil {
+			// The application may have been uninstalled after listing
+			continue
+		}
+		entries[name] = values
+	}
+	return entries, nil
+}
+
+// readValues returns the string and integer values of the subkey.
+func readValues(parent registry.Key, name string, view uint32) (map[string]interface{}, error) {
+	key, err := registry.OpenKey(parent, name, registry.QUERY_VALUE|view)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(-1)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		_, typ, err := key.GetValue(name, nil)
+		if err != nil {
+			continue
+		}
+		switch typ {
+		case registry.DWORD, registry.QWORD:
+			if v, _, err := key.GetIntegerValue(name); err == nil {
+				values[name] = v
+			}
+		case registry.SZ, registry.EXPAND_SZ:
+			if v, _, err := key.GetStringValue(name); err == nil {
+				values[name] = v
+			}
+		}
+	}
+	return values, nil
+}
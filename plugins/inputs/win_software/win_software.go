@@ -0,0 +1,189 @@
+//go:build windows
+// +build windows
+
+package win_software
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Installed software changes rarely, collect it at a slow interval.
+  interval = "12h"
+
+  ## Names of the applications to collect, all if empty, and to skip. Globs
+  ## accepted, the names are case-insensitive.
+  # name_include = []
+  # name_exclude = []
+
+  ## Collect the applications installed for single users, read from the
+  ## registry hives of the logged on users.
+  # user_installs = false
+
+  ## Collect the applications hidden from 'Programs and Features', mostly
+  ## components installed with other applications.
+  # system_components = false
+`
+
+// uninstallPath is the key of the installed applications.
+const uninstallPath = `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`
+
+// uninstallKey is a key with the installed applications as subkeys.
+type uninstallKey struct {
+	// root is the abbreviation of the root key, HKLM or HKU.
+	root string
+	path string
+	// wow64 is true for the 32-bit view of the registry.
+	wow64        bool
+	architecture string
+	// user is the SID of the user of per-user applications.
+	user string
+}
+
+// reader reads the registry.
+type reader interface {
+	// users returns the SIDs of the users whose registry hives are loaded.
+	users() ([]string, error)
+	// entries returns the values of the subkeys of the key by subkey name.
+	entries(key uninstallKey) (map[string]map[string]interface{}, error)
+}
+
+// WinSoftware collects the applications installed on Windows.
+type WinSoftware struct {
+	NameInclude      []string `toml:"name_include"`
+	NameExclude      []string `toml:"name_exclude"`
+	UserInstalls     bool     `toml:"user_installs"`
+	SystemComponents bool     `toml:"system_components"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter      filter.Filter
+	reader      reader
+	machineKeys []uninstallKey
+}
+
+func (w *WinSoftware) Description() string {
+	return "Collect the applications installed on Windows"
+}
+
+func (w *WinSoftware) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinSoftware) Init() error {
+	include := make([]string, 0, len(w.NameInclude))
+	for _, name := range w.NameInclude {
+		include = append(include, strings.ToLower(name))
+	}
+	exclude := make([]string, 0, len(w.NameExclude))
+	for _, name := range w.NameExclude {
+		exclude = append(exclude, strings.ToLower(name))
+	}
+	f, err := filter.NewIncludeExcludeFilter(include, exclude)
+	if err != nil {
+		return fmt.Errorf("compiling name filters failed: %w", err)
+	}
+	w.filter = f
+	return nil
+}
+
+func (w *WinSoftware) Gather(acc telegraf.Accumulator) error {
+	keys := w.machineKeys
+	if w.UserInstalls {
+		users, err := w.reader.users()
+		if err != nil {
+			acc.AddError(fmt.Errorf("listing users failed: %w", err))
+		}
+		for _, sid := range users {
+			// Only the hives of local and domain users, not of the
+			// service accounts or the classes of the users.
+			if !strings.HasPrefix(sid, "S-1-5-21-") || strings.HasSuffix(sid, "_Classes") {
+				continue
+			}
+			keys = append(keys, uninstallKey{root: "HKU", path: sid + `\` + uninstallPath, user: sid})
+		}
+	}
+
+	for _, key := range keys {
+		entries, err := w.reader.entries(key)
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading key %s\\%s failed: %w", key.root, key.path, err))
+			continue
+		}
+		for productCode, values := range entries {
+			w.addEntry(acc, key, productCode, values)
+		}
+	}
+	return nil
+}
+
+// addEntry adds the application of the entry of the uninstall key. Entries
+// without name, of updates and, unless configured, of system components are
+// skipped.
+func (w *WinSoftware) addEntry(acc telegraf.Accumulator, key uninstallKey, productCode string, values map[string]interface{}) {
+	name := stringValue(values, "DisplayName")
+	if name == "" || !w.filter.Match(strings.ToLower(name)) {
+		return
+	}
+	if stringValue(values, "ParentKeyName") != "" {
+		return
+	}
+	switch stringValue(values, "ReleaseType") {
+	case "Update", "Hotfix", "Security Update":
+		return
+	}
+	if v, ok := values["SystemComponent"].(uint64); ok && v == 1 && !w.SystemComponents {
+		return
+	}
+
+	tags := map[string]string{"name": name}
+	if version := stringValue(values, "DisplayVersion"); version != "" {
+		tags["version"] = version
+	}
+	if publisher := stringValue(values, "Publisher"); publisher != "" {
+		tags["publisher"] = publisher
+	}
+	if key.architecture != "" {
+		tags["architecture"] = key.architecture
+	}
+	if key.user != "" {
+		tags["scope"] = "user"
+		tags["user"] = key.user
+	} else {
+		tags["scope"] = "machine"
+	}
+
+	fields := map[string]interface{}{"product_code": productCode}
+	// The date is written as 'yyyyMMdd' by Windows Installer, other installers
+	// may write other formats.
+	if date, err := time.Parse("20060102", stringValue(values, "InstallDate")); err == nil {
+		fields["install_date"] = date.Format("2006-01-02")
+	}
+	if size, ok := values["EstimatedSize"].(uint64); ok {
+		// The size is given in kilobytes
+		fields["size_bytes"] = size * 1024
+	}
+	acc.AddFields("win_software", fields, tags)
+}
+
+// stringValue returns the trimmed string value with the name, empty if it is
+// missing or not a string.
+func stringValue(values map[string]interface{}, name string) string {
+	s, _ := values[name].(string)
+	return strings.TrimSpace(s)
+}
+
+func init() {
+	inputs.Add("win_software", func() telegraf.Input {
+		return &WinSoftware{
+			reader:      registryReader{},
+			machineKeys: localMachineKeys(),
+		}
+	})
+}
@@ -0,0 +1,200 @@
+//go:build windows
+// +build windows
+
+package win_software
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeReader returns the entries by root and path of the key, the 32-bit view
+// is marked by a 'WOW64\' prefix of the path.
+type fakeReader struct {
+	sids []string
+	keys map[string]map[string]map[string]interface{}
+}
+
+func (r *fakeReader) users() ([]string, error) {
+	return r.sids, nil
+}
+
+func (r *fakeReader) entries(k uninstallKey) (map[string]map[string]interface{}, error) {
+	path := k.root + `\` + k.path
+	if k.wow64 {
+		path = `WOW64\` + path
+	}
+	if path == `HKLM\broken` {
+		return nil, errors.New("access denied")
+	}
+	return r.keys[path], nil
+}
+
+var testKeys = []uninstallKey{
+	{root: "HKLM", path: uninstallPath, architecture: "x64"},
+	{root: "HKLM", path: uninstallPath, wow64: true, architecture: "x86"},
+}
+
+const userSID = "S-1-5-21-1004336348-1177238915-682003330-1001"
+
+func newTestReader() *fakeReader {
+	return &fakeReader{
+		sids: []string{".DEFAULT", "S-1-5-18", userSID, userSID + "_Classes"},
+		keys: map[string]map[string]map[string]interface{}{
+			`HKLM\` + uninstallPath: {
+				"{23170F69-40C1-2702-1900-000001000000}": {
+					"DisplayName":    "7-Zip 19.00 (x64 edition)",
+					"DisplayVersion": "19.00.00.0",
+					"Publisher":      "Igor Pavlov",
+					"InstallDate":    "20211014",
+					"EstimatedSize":  uint64(5120),
+				},
+				"{90160000-008C-0000-1000-0000000FF1CE}": {
+					"DisplayName":     "Office 16 Click-to-Run Extensibility Component",
+					"DisplayVersion":  "16.0.14326.20404",
+					"SystemComponent": uint64(1),
+				},
+				"KB5005565": {
+					"DisplayName":   "Security Update for Windows (KB5005565)",
+					"ParentKeyName": "OperatingSystem",
+				},
+				"Connection Manager": {
+					"SystemComponent": uint64(1),
+				},
+			},
+			`WOW64\HKLM\` + uninstallPath: {
+				"Notepad++": {
+					"DisplayName":    "Notepad++ (32-bit x86)",
+					"DisplayVersion": "8.1.5",
+					"Publisher":      "Notepad++ Team",
+					"InstallDate":    "14.10.2021",
+				},
+			},
+			`HKU\` + userSID + `\` + uninstallPath: {
+				"Teams": {
+					"DisplayName":    "Microsoft Teams",
+					"DisplayVersion": "1.4.00.26453",
+					"Publisher":      "Microsoft Corporation",
+					"InstallDate":    "20211001",
+				},
+			},
+		},
+	}
+}
+
+func TestGather(t *testing.T) {
+	w := &WinSoftware{
+		Log:         testutil.Logger{},
+		reader:      newTestReader(),
+		machineKeys: testKeys,
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_software",
+			map[string]string{
+				"name":         "7-Zip 19.00 (x64 edition)",
+				"version":      "19.00.00.0",
+				"publisher":    "Igor Pavlov",
+				"architecture": "x64",
+				"scope":        "machine",
+			},
+			map[string]interface{}{
+				"product_code": "{23170F69-40C1-2702-1900-000001000000}",
+				"install_date": "2021-10-14",
+				"size_bytes":   uint64(5242880),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_software",
+			map[string]string{
+				"name":         "Notepad++ (32-bit x86)",
+				"version":      "8.1.5",
+				"publisher":    "Notepad++ Team",
+				"architecture": "x86",
+				"scope":        "machine",
+			},
+			map[string]interface{}{
+				"product_code": "Notepad++",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherUserInstallsAndSystemComponents(t *testing.T) {
+	w := &WinSoftware{
+		NameExclude:      []string{"7-zip*", "notepad++*"},
+		UserInstalls:     true,
+		SystemComponents: true,
+		Log:              testutil.Logger{},
+		reader:           newTestReader(),
+		machineKeys:      testKeys,
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_software",
+			map[string]string{
+				"name":         "Office 16 Click-to-Run Extensibility Component",
+				"version":      "16.0.14326.20404",
+				"architecture": "x64",
+				"scope":        "machine",
+			},
+			map[string]interface{}{
+				"product_code": "{90160000-008C-0000-1000-0000000FF1CE}",
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_software",
+			map[string]string{
+				"name":      "Microsoft Teams",
+				"version":   "1.4.00.26453",
+				"publisher": "Microsoft Corporation",
+				"scope":     "user",
+				"user":      userSID,
+			},
+			map[string]interface{}{
+				"product_code": "Teams",
+				"install_date": "2021-10-01",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherNameInclude(t *testing.T) {
+	w := &WinSoftware{
+		NameInclude: []string{"7-Zip*"},
+		Log:         testutil.Logger{},
+		reader:      newTestReader(),
+		machineKeys: append(testKeys, uninstallKey{root: "HKLM", path: "broken"}),
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, "7-Zip 19.00 (x64 edition)", acc.Metrics[0].Tags["name"])
+}
@@ -0,0 +1,66 @@
+//go:build windows
+// +build windows
+
+package win_system
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// registryReader reads the registry of the local host.
+type registryReader struct{}
+
+func (registryReader) modTime(path string) (time.Time, error) {
+	key, err := openKey(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer key.Close()
+
+	info, err := key.Stat()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+func (registryReader) integerValue(path, name string) (uint64, error) {
+	key, err := openKey(path)
+	if err != nil {
+		return 0, err
+	}
+	defer key.Close()
+
+	v, _, err := key.GetIntegerValue(name)
+	if errors.Is(err, registry.ErrNotExist) {
+		return 0, errNotExist
+	}
+	return v, err
+}
+
+func (registryReader) stringsValue(path, name string) ([]string, error) {
+	key, err := openKey(path)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	v, _, err := key.GetStringsValue(name)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil, errNotExist
+	}
+	return v, err
+}
+
+// openKey opens the key below HKEY_LOCAL_MACHINE for reading, errNotExist is
+// returned if the key does not exist.
+func openKey(path string) (registry.Key, error) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return key, errNotExist
+	}
+	return key, err
+}
@@ -0,0 +1,184 @@
+//go:build windows
+// +build windows
+
+package win_system
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/host"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Count pending file rename operations as reason for a pending reboot.
+  ## Some software, e.g. anti-virus updates, schedules renames on every
+  ## update, the reason is reported in its own field regardless.
+  # file_rename_operations = true
+`
+
+// Registry keys and values of the reasons for a pending reboot and the fast
+// startup settings, relative to HKEY_LOCAL_MACHINE.
+const (
+	cbsKey            = `SOFTWARE\Microsoft\Windows\CurrentVersion\Component Based Servicing\RebootPending`
+	windowsUpdateKey  = `SOFTWARE\Microsoft\Windows\CurrentVersion\WindowsUpdate\Auto Update\RebootRequired`
+	sessionManagerKey = `SYSTEM\CurrentControlSet\Control\Session Manager`
+	fileRenameValue   = "PendingFileRenameOperations"
+	hiberbootKey      = `SYSTEM\CurrentControlSet\Control\Session Manager\Power`
+	hiberbootValue    = "HiberbootEnabled"
+	hibernateKey      = `SYSTEM\CurrentControlSet\Control\Power`
+	hibernateValue    = "HibernateEnabled"
+)
+
+// errNotExist is returned by the reader if the key or value does not exist.
+var errNotExist = errors.New("does not exist")
+
+// reader reads the registry below HKEY_LOCAL_MACHINE.
+type reader interface {
+	// modTime returns the last write time of the key.
+	modTime(path string) (time.Time, error)
+	integerValue(path, name string) (uint64, error)
+	stringsValue(path, name string) ([]string, error)
+}
+
+// WinSystem collects the boot time and the pending reboot state of Windows.
+type WinSystem struct {
+	FileRenameOperations bool `toml:"file_rename_operations"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	reader reader
+	uptime func() (time.Duration, error)
+	now    func() time.Time
+	// pendingSince is the time a pending reboot was first noticed.
+	pendingSince time.Time
+}
+
+func (w *WinSystem) Description() string {
+	return "Collect the boot time, uptime and pending reboot state of Windows"
+}
+
+func (w *WinSystem) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinSystem) Gather(acc telegraf.Accumulator) error {
+	uptime, err := w.uptime()
+	if err != nil {
+		return fmt.Errorf("querying uptime failed: %w", err)
+	}
+	now := w.now()
+	bootTime := now.Add(-uptime)
+	fields := map[string]interface{}{
+		"boot_time":      bootTime.Unix(),
+		"uptime_seconds": int64(uptime.Seconds()),
+	}
+
+	// The keys of servicing and Windows Update exist while a reboot is
+	// pending, they are created when the reboot becomes necessary.
+	since := now
+	pending := false
+	for field, path := range map[string]string{"reboot_pending_cbs": cbsKey, "reboot_pending_windows_update": windowsUpdateKey} {
+		modTime, err := w.reader.modTime(path)
+		if errors.Is(err, errNotExist) {
+			fields[field] = false
+			continue
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("reading key %q failed: %w", path, err))
+			continue
+		}
+		fields[field] = true
+		pending = true
+		if modTime.Before(since) {
+			since = modTime
+		}
+	}
+
+	renames, err := w.reader.stringsValue(sessionManagerKey, fileRenameValue)
+	switch {
+	case errors.Is(err, errNotExist):
+		fields["reboot_pending_file_rename"] = false
+	case err != nil:
+		acc.AddError(fmt.Errorf("reading value %q failed: %w", fileRenameValue, err))
+	default:
+		renamePending := len(renames) > 0
+		fields["reboot_pending_file_rename"] = renamePending
+		if renamePending && w.FileRenameOperations {
+			pending = true
+		}
+	}
+
+	fields["reboot_pending"] = pending
+	if pending {
+		// The last write time of the keys may be later than the time the
+		// reboot became necessary, the time the plugin first noticed the
+		// pending reboot is used if it is earlier.
+		if !w.pendingSince.IsZero() && w.pendingSince.Before(since) {
+			since = w.pendingSince
+		}
+		if since.Before(bootTime) {
+			since = bootTime
+		}
+		w.pendingSince = since
+		fields["reboot_pending_seconds"] = int64(now.Sub(since).Seconds())
+	} else {
+		w.pendingSince = time.Time{}
+	}
+
+	fastStartup, err := w.fastStartup()
+	if err != nil {
+		acc.AddError(fmt.Errorf("reading fast startup state failed: %w", err))
+	} else {
+		fields["fast_startup"] = fastStartup
+	}
+
+	acc.AddFields("win_system", fields, nil)
+	return nil
+}
+
+// fastStartup returns true if fast startup is enabled. Fast startup
+// hibernates the kernel on shutdown, so the boot time is not reset by
+// shutting down, and requires hibernation to be enabled.
+func (w *WinSystem) fastStartup() (bool, error) {
+	hiberboot, err := w.reader.integerValue(hiberbootKey, hiberbootValue)
+	if errors.Is(err, errNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	hibernate, err := w.reader.integerValue(hibernateKey, hibernateValue)
+	if errors.Is(err, errNotExist) {
+		// Hibernation is enabled by default
+		return hiberboot == 1, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return hiberboot == 1 && hibernate != 0, nil
+}
+
+// systemUptime returns the time since the system was booted.
+func systemUptime() (time.Duration, error) {
+	seconds, err := host.Uptime()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func init() {
+	inputs.Add("win_system", func() telegraf.Input {
+		return &WinSystem{
+			FileRenameOperations: true,
+			reader:               registryReader{},
+			uptime:               systemUptime,
+			now:                  time.Now,
+		}
+	})
+}
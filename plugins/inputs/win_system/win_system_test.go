@@ -0,0 +1,181 @@
+//go:build windows
+// +build windows
+
+package win_system
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeReader reads the keys and values from maps, missing keys and values do
+// not exist.
+type fakeReader struct {
+	keys     map[string]time.Time
+	integers map[string]uint64
+	strings  map[string][]string
+}
+
+func (r *fakeReader) modTime(path string) (time.Time, error) {
+	if t, ok := r.keys[path]; ok {
+		return t, nil
+	}
+	return time.Time{}, errNotExist
+}
+
+func (r *fakeReader) integerValue(path, name string) (uint64, error) {
+	if v, ok := r.integers[path+`\`+name]; ok {
+		return v, nil
+	}
+	return 0, errNotExist
+}
+
+func (r *fakeReader) stringsValue(path, name string) ([]string, error) {
+	if v, ok := r.strings[path+`\`+name]; ok {
+		return v, nil
+	}
+	return nil, errNotExist
+}
+
+var now = time.Date(2021, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func newWinSystem(r reader) *WinSystem {
+	return &WinSystem{
+		FileRenameOperations: true,
+		Log:                  testutil.Logger{},
+		reader:               r,
+		uptime:               func() (time.Duration, error) { return 90 * 24 * time.Hour, nil },
+		now:                  func() time.Time { return now },
+	}
+}
+
+func TestGatherNoPendingReboot(t *testing.T) {
+	w := newWinSystem(&fakeReader{
+		integers: map[string]uint64{
+			hiberbootKey + `\` + hiberbootValue: 1,
+			hibernateKey + `\` + hibernateValue: 0,
+		},
+	})
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_system",
+			map[string]string{},
+			map[string]interface{}{
+				"boot_time":                     now.Add(-90 * 24 * time.Hour).Unix(),
+				"uptime_seconds":                int64(90 * 24 * 3600),
+				"reboot_pending":                false,
+				"reboot_pending_cbs":            false,
+				"reboot_pending_windows_update": false,
+				"reboot_pending_file_rename":    false,
+				"fast_startup":                  false,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherPendingReboot(t *testing.T) {
+	r := &fakeReader{
+		keys: map[string]time.Time{
+			windowsUpdateKey: now.Add(-60 * 24 * time.Hour),
+		},
+		strings: map[string][]string{
+			sessionManagerKey + `\` + fileRenameValue: {`\??\C:\Temp\a.dll`, ""},
+		},
+		integers: map[string]uint64{
+			hiberbootKey + `\` + hiberbootValue: 1,
+		},
+	}
+	w := newWinSystem(r)
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_system",
+			map[string]string{},
+			map[string]interface{}{
+				"boot_time":                     now.Add(-90 * 24 * time.Hour).Unix(),
+				"uptime_seconds":                int64(90 * 24 * 3600),
+				"reboot_pending":                true,
+				"reboot_pending_cbs":            false,
+				"reboot_pending_windows_update": true,
+				"reboot_pending_file_rename":    true,
+				"reboot_pending_seconds":        int64(60 * 24 * 3600),
+				"fast_startup":                  true,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Rewriting the key does not reset the time the reboot is pending
+	r.keys[windowsUpdateKey] = now
+	acc.ClearMetrics()
+	require.NoError(t, w.Gather(&acc))
+	v, ok := acc.Get("win_system")
+	require.True(t, ok)
+	require.Equal(t, int64(60*24*3600), v.Fields["reboot_pending_seconds"])
+}
+
+func TestGatherFileRenameOperations(t *testing.T) {
+	r := &fakeReader{
+		strings: map[string][]string{
+			sessionManagerKey + `\` + fileRenameValue: {`\??\C:\Temp\a.dll`, ""},
+		},
+	}
+	w := newWinSystem(r)
+	w.FileRenameOperations = false
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	v, ok := acc.Get("win_system")
+	require.True(t, ok)
+	require.Equal(t, true, v.Fields["reboot_pending_file_rename"])
+	require.Equal(t, false, v.Fields["reboot_pending"])
+	require.NotContains(t, v.Fields, "reboot_pending_seconds")
+
+	// The pending time starts when the renames are first counted
+	w.FileRenameOperations = true
+	w.now = func() time.Time { return now.Add(time.Hour) }
+	acc.ClearMetrics()
+	require.NoError(t, w.Gather(&acc))
+	v, ok = acc.Get("win_system")
+	require.True(t, ok)
+	require.Equal(t, true, v.Fields["reboot_pending"])
+	require.Equal(t, int64(0), v.Fields["reboot_pending_seconds"])
+}
+
+type failingReader struct {
+	fakeReader
+}
+
+func (r *failingReader) modTime(path string) (time.Time, error) {
+	return time.Time{}, errors.New("access denied")
+}
+
+func TestGatherReadError(t *testing.T) {
+	w := newWinSystem(&failingReader{})
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	v, ok := acc.Get("win_system")
+	require.True(t, ok)
+	require.NotContains(t, v.Fields, "reboot_pending_cbs")
+	require.Equal(t, false, v.Fields["reboot_pending"])
+}
@@ -0,0 +1,175 @@
+//go:build windows
+// +build windows
+
+package win_time
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Timeout of the w32tm command.
+  # timeout = "5s"
+`
+
+// WinTime collects the status of the Windows Time service.
+type WinTime struct {
+	Timeout config.Duration `toml:"timeout"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	run func(timeout time.Duration) ([]byte, error)
+}
+
+func (w *WinTime) Description() string {
+	return "Collect the status and clock offset of the Windows Time service"
+}
+
+func (w *WinTime) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinTime) Gather(acc telegraf.Accumulator) error {
+	out, err := w.run(time.Duration(w.Timeout))
+	if err != nil {
+		return fmt.Errorf("running w32tm failed: %w - %s", err, strings.TrimSpace(string(out)))
+	}
+	fields, tags, err := parseStatus(string(out))
+	if err != nil {
+		return err
+	}
+	acc.AddFields("win_time", fields, tags)
+	return nil
+}
+
+// runW32tm returns the output of 'w32tm /query /status /verbose'.
+func runW32tm(timeout time.Duration) ([]byte, error) {
+	return internal.CombinedOutputTimeout(exec.Command("w32tm", "/query", "/status", "/verbose"), timeout)
+}
+
+// parseStatus parses the output of 'w32tm /query /status /verbose', like:
+//
+//	Leap Indicator: 0(no warning)
+//	Stratum: 2 (secondary reference - syncd by (S)NTP)
+//	Precision: -23 (119.209ns per tick)
+//	Root Delay: 0.0156250s
+//	Root Dispersion: 7.7774287s
+//	ReferenceId: 0x0A000001 (source IP:  10.0.0.1)
+//	Last Successful Sync Time: 10/14/2021 11:58:52 AM
+//	Source: dc01.corp.example.com
+//	Poll Interval: 6 (64s)
+//
+//	Phase Offset: 0.0000321s
+//	ClockRate: 0.0156250s
+//	State Machine: 2 (Sync)
+//	Time Source Flags: 0 (None)
+//	Server Role: 0 (None)
+//	Last Sync Error: 0 (The command completed successfully.)
+//	Time since Last Good Sync Time: 68.0937879s
+//
+// The output is localized, only the English output is supported.
+func parseStatus(out string) (map[string]interface{}, map[string]string, error) {
+	fields := make(map[string]interface{})
+	tags := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+
+		switch key {
+		case "Leap Indicator":
+			addIntField(fields, "leap_indicator", value)
+		case "Stratum":
+			addIntField(fields, "stratum", value)
+		case "Precision":
+			addIntField(fields, "precision", value)
+		case "Root Delay":
+			addSecondsField(fields, "root_delay_seconds", value)
+		case "Root Dispersion":
+			addSecondsField(fields, "root_dispersion_seconds", value)
+		case "Poll Interval":
+			// The interval is the power of two of the value in seconds
+			if n, ok := leadingInt(value); ok && n >= 0 && n < 63 {
+				fields["poll_interval_seconds"] = int64(1) << n
+			}
+		case "Phase Offset":
+			addSecondsField(fields, "phase_offset_seconds", value)
+		case "ClockRate":
+			addSecondsField(fields, "clock_rate_seconds", value)
+		case "State Machine":
+			if n, ok := leadingInt(value); ok {
+				fields["state"] = strings.ToLower(description(value))
+				fields["state_code"] = n
+			}
+		case "Last Sync Error":
+			addIntField(fields, "last_sync_error", value)
+		case "Time since Last Good Sync Time":
+			addSecondsField(fields, "last_sync_age_seconds", value)
+		case "Source":
+			// The source may be followed by its mode, e.g. 'time.windows.com,0x9'
+			if j := strings.LastIndex(value, ",0x"); j > 0 {
+				value = value[:j]
+			}
+			tags["source"] = value
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil, errors.New("unexpected output of w32tm, only the English output is supported")
+	}
+	return fields, tags, nil
+}
+
+// leadingInt returns the integer at the start of the value, e.g. of
+// '0(no warning)'.
+func leadingInt(value string) (int64, bool) {
+	end := 0
+	for end < len(value) && (value[end] >= '0' && value[end] <= '9' || end == 0 && value[end] == '-') {
+		end++
+	}
+	n, err := strconv.ParseInt(value[:end], 10, 64)
+	return n, err == nil
+}
+
+// description returns the text in parentheses following the value.
+func description(value string) string {
+	start := strings.Index(value, "(")
+	end := strings.LastIndex(value, ")")
+	if start < 0 || end < start {
+		return ""
+	}
+	return value[start+1 : end]
+}
+
+func addIntField(fields map[string]interface{}, name, value string) {
+	if n, ok := leadingInt(value); ok {
+		fields[name] = n
+	}
+}
+
+func addSecondsField(fields map[string]interface{}, name, value string) {
+	if v, err := strconv.ParseFloat(strings.TrimSuffix(value, "s"), 64); err == nil {
+		fields[name] = v
+	}
+}
+
+func init() {
+	inputs.Add("win_time", func() telegraf.Input {
+		return &WinTime{
+			Timeout: config.Duration(5 * time.Second),
+			run:     runW32tm,
+		}
+	})
+}
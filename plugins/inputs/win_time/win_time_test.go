@@ -0,0 +1,115 @@
+//go:build windows
+// +build windows
+
+package win_time
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const status = `Leap Indicator: 0(no warning)
+Stratum: 2 (secondary reference - syncd by (S)NTP)
+Precision: -23 (119.209ns per tick)
+Root Delay: 0.0156250s
+Root Dispersion: 7.7774287s
+ReferenceId: 0x0A000001 (source IP:  10.0.0.1)
+Last Successful Sync Time: 10/14/2021 11:58:52 AM
+Source: dc01.corp.example.com,0x8
+Poll Interval: 6 (64s)
+
+Phase Offset: -0.0000321s
+ClockRate: 0.0156250s
+State Machine: 2 (Sync)
+Time Source Flags: 0 (None)
+Server Role: 0 (None)
+Last Sync Error: 0 (The command completed successfully.)
+Time since Last Good Sync Time: 68.0937879s
+`
+
+func TestGather(t *testing.T) {
+	w := &WinTime{
+		Log: testutil.Logger{},
+		run: func(time.Duration) ([]byte, error) {
+			// w32tm uses Windows line endings
+			return []byte(strings.ReplaceAll(status, "\n", "\r\n")), nil
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_time",
+			map[string]string{"source": "dc01.corp.example.com"},
+			map[string]interface{}{
+				"leap_indicator":          int64(0),
+				"stratum":                 int64(2),
+				"precision":               int64(-23),
+				"root_delay_seconds":      0.015625,
+				"root_dispersion_seconds": 7.7774287,
+				"poll_interval_seconds":   int64(64),
+				"phase_offset_seconds":    -0.0000321,
+				"clock_rate_seconds":      0.015625,
+				"state":                   "sync",
+				"state_code":              int64(2),
+				"last_sync_error":         int64(0),
+				"last_sync_age_seconds":   68.0937879,
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherUnsynchronized(t *testing.T) {
+	w := &WinTime{
+		Log: testutil.Logger{},
+		run: func(time.Duration) ([]byte, error) {
+			return []byte("Leap Indicator: 3(not synchronized)\nStratum: 0 (unspecified)\nSource: Local CMOS Clock\nState Machine: 0 (Unset)\n"), nil
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_time",
+			map[string]string{"source": "Local CMOS Clock"},
+			map[string]interface{}{
+				"leap_indicator": int64(3),
+				"stratum":        int64(0),
+				"state":          "unset",
+				"state_code":     int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherError(t *testing.T) {
+	w := &WinTime{
+		Log: testutil.Logger{},
+		run: func(time.Duration) ([]byte, error) {
+			return []byte("The following error occurred: The service has not been started. (0x80070426)"), errors.New("exit status 1")
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.EqualError(t, w.Gather(&acc), "running w32tm failed: exit status 1 - The following error occurred: The service has not been started. (0x80070426)")
+}
+
+func TestParseStatusLocalized(t *testing.T) {
+	_, _, err := parseStatus("Indicateur de saut : 0(aucun avertissement)\nSource : Local CMOS Clock\n")
+	require.Error(t, err)
+}
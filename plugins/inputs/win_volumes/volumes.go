@@ -0,0 +1,124 @@
+//go:build windows
+// +build windows
+
+package win_volumes
+
+import (
+	"errors"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	fsctlIsVolumeDirty = 0x00090078
+	volumeIsDirty      = 0x00000001
+)
+
+// localVolumes returns the volumes of the local host, volumes without media,
+// e.g. empty optical drives, are skipped.
+func localVolumes() ([]volume, error) {
+	buf := make([]uint16, windows.MAX_PATH+1)
+	h, err := windows.FindFirstVolume(&buf[0], uint32(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+	defer windows.FindVolumeClose(h) //nolint:errcheck // nothing to do on error
+
+	var volumes []volume
+	for {
+		name := windows.UTF16ToString(buf)
+		if v, err := localVolume(name); err == nil {
+			volumes = append(volumes, *v)
+		}
+
+		err := windows.FindNextVolume(h, &buf[0], uint32(len(buf)))
+		if errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+			return volumes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// localVolume returns the volume with the GUID path, e.g.
+// '\\?\Volume{6f1b3c4e-0000-0000-0000-100000000000}\'.
+func localVolume(name string) (*volume, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &volume{name: name}
+	if v.paths, err = volumePaths(namePtr); err != nil {
+		return nil, err
+	}
+
+	var available uint64
+	if err := windows.GetDiskFreeSpaceEx(namePtr, &available, &v.total, &v.free); err != nil {
+		return nil, err
+	}
+
+	label := make([]uint16, windows.MAX_PATH+1)
+	fileSystem := make([]uint16, windows.MAX_PATH+1)
+	err = windows.GetVolumeInformation(namePtr, &label[0], uint32(len(label)), nil, nil, nil, &fileSystem[0], uint32(len(fileSystem)))
+	if err == nil {
+		v.label = windows.UTF16ToString(label)
+		v.fileSystem = windows.UTF16ToString(fileSystem)
+	}
+
+	// Querying the dirty bit requires administrator privileges
+	if dirty, err := volumeDirty(name); err == nil {
+		v.dirty = &dirty
+	}
+	return v, nil
+}
+
+// volumePaths returns the drive letters and mounted folders of the volume.
+func volumePaths(name *uint16) ([]string, error) {
+	size := uint32(windows.MAX_PATH + 1)
+	for {
+		buf := make([]uint16, size)
+		err := windows.GetVolumePathNamesForVolumeName(name, &buf[0], size, &size)
+		if errors.Is(err, windows.ERROR_MORE_DATA) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// The paths are a list of null-terminated strings ending with an
+		// empty string.
+		var paths []string
+		for len(buf) > 0 && buf[0] != 0 {
+			path := windows.UTF16ToString(buf)
+			paths = append(paths, path)
+			buf = buf[len(path)+1:]
+		}
+		return paths, nil
+	}
+}
+
+// volumeDirty returns true if the dirty bit of the volume is set, i.e. the
+// file system is checked at the next start.
+func volumeDirty(name string) (bool, error) {
+	// The volume device is opened without the trailing backslash
+	device, err := windows.UTF16PtrFromString(strings.Replace(strings.TrimSuffix(name, `\`), `\\?\`, `\\.\`, 1))
+	if err != nil {
+		return false, err
+	}
+	h, err := windows.CreateFile(device, windows.GENERIC_READ, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return false, err
+	}
+	defer windows.CloseHandle(h) //nolint:errcheck // nothing to do on error
+
+	var flags, returned uint32
+	err = windows.DeviceIoControl(h, fsctlIsVolumeDirty, nil, 0, (*byte)(unsafe.Pointer(&flags)), uint32(unsafe.Sizeof(flags)), &returned, nil)
+	if err != nil {
+		return false, err
+	}
+	return flags&volumeIsDirty != 0, nil
+}
@@ -0,0 +1,162 @@
+//go:build windows
+// +build windows
+
+package win_volumes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Collect the volumes without drive letter or mounted folder, e.g. the
+  ## recovery partitions.
+  # include_unmounted = false
+
+  ## File systems to ignore, e.g. ["FAT32"].
+  # ignore_file_system = []
+
+  ## Collect the BitLocker protection status of the volumes. Requires
+  ## administrator privileges.
+  # bitlocker = true
+`
+
+const bitlockerNamespace = `root\CIMV2\Security\MicrosoftVolumeEncryption`
+
+// Names of the protection states of the BitLocker volumes.
+var protectionStates = map[int64]string{
+	0: "off",
+	1: "on",
+	2: "unknown",
+}
+
+// volume is a volume of the local host.
+type volume struct {
+	// name is the GUID path of the volume
+	name       string
+	paths      []string
+	label      string
+	fileSystem string
+	total      uint64
+	free       uint64
+	// dirty is nil if the dirty bit could not be queried
+	dirty *bool
+}
+
+// WinVolumes collects the usage of the volumes including the ones mounted to
+// folders.
+type WinVolumes struct {
+	IncludeUnmounted bool     `toml:"include_unmounted"`
+	IgnoreFileSystem []string `toml:"ignore_file_system"`
+	BitLocker        bool     `toml:"bitlocker"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	volumes func() ([]volume, error)
+	query   wmi.QueryFunc
+}
+
+func (w *WinVolumes) Description() string {
+	return "Collect the usage of the volumes including the ones mounted to folders"
+}
+
+func (w *WinVolumes) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinVolumes) Gather(acc telegraf.Accumulator) error {
+	volumes, err := w.volumes()
+	if err != nil {
+		return fmt.Errorf("enumerating volumes failed: %w", err)
+	}
+
+	var protection map[string]int64
+	if w.BitLocker {
+		if protection, err = w.bitlockerProtection(); err != nil {
+			acc.AddError(fmt.Errorf("querying BitLocker volumes failed: %w", err))
+		}
+	}
+
+	for _, v := range volumes {
+		if len(v.paths) == 0 && !w.IncludeUnmounted || w.ignored(v.fileSystem) {
+			continue
+		}
+
+		tags := map[string]string{"volume": v.name}
+		if len(v.paths) > 0 {
+			tags["path"] = v.paths[0]
+		}
+		if v.label != "" {
+			tags["label"] = v.label
+		}
+		if v.fileSystem != "" {
+			tags["file_system"] = v.fileSystem
+		}
+
+		used := v.total - v.free
+		var usedPercent float64
+		if v.total > 0 {
+			usedPercent = float64(used) / float64(v.total) * 100
+		}
+		fields := map[string]interface{}{
+			"total":        v.total,
+			"free":         v.free,
+			"used":         used,
+			"used_percent": usedPercent,
+			"mount_points": strings.Join(v.paths, ";"),
+		}
+		if v.dirty != nil {
+			fields["dirty"] = *v.dirty
+		}
+		if code, ok := protection[strings.ToLower(v.name)]; ok {
+			status, ok := protectionStates[code]
+			if !ok {
+				status = "unknown"
+			}
+			fields["bitlocker_status"] = status
+			fields["bitlocker_status_code"] = code
+		}
+		acc.AddFields("win_volumes", fields, tags)
+	}
+	return nil
+}
+
+func (w *WinVolumes) ignored(fileSystem string) bool {
+	for _, fs := range w.IgnoreFileSystem {
+		if strings.EqualFold(fs, fileSystem) {
+			return true
+		}
+	}
+	return false
+}
+
+// bitlockerProtection returns the protection status of the encryptable
+// volumes by lower case GUID path.
+func (w *WinVolumes) bitlockerProtection() (map[string]int64, error) {
+	protection := make(map[string]int64)
+	err := w.query(bitlockerNamespace, "SELECT DeviceID, ProtectionStatus FROM Win32_EncryptableVolume",
+		func(p map[string]interface{}) error {
+			code, err := strconv.ParseInt(fmt.Sprint(p["ProtectionStatus"]), 10, 64)
+			if err != nil {
+				return nil
+			}
+			protection[strings.ToLower(fmt.Sprint(p["DeviceID"]))] = code
+			return nil
+		})
+	return protection, err
+}
+
+func init() {
+	inputs.Add("win_volumes", func() telegraf.Input {
+		return &WinVolumes{
+			BitLocker: true,
+			volumes:   localVolumes,
+			query:     (&wmi.Connection{}).Query,
+		}
+	})
+}
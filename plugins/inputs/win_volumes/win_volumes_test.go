@@ -0,0 +1,158 @@
+//go:build windows
+// +build windows
+
+package win_volumes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var (
+	clean = false
+	dirty = true
+)
+
+var volumes = []volume{
+	{
+		name:       `\\?\Volume{11111111-0000-0000-0000-100000000000}\`,
+		paths:      []string{`C:\`},
+		label:      "System",
+		fileSystem: "NTFS",
+		total:      1000,
+		free:       250,
+		dirty:      &clean,
+	},
+	{
+		name:       `\\?\Volume{22222222-0000-0000-0000-100000000000}\`,
+		paths:      []string{`D:\Mounts\Log\`, `E:\`},
+		fileSystem: "ReFS",
+		total:      4000,
+		free:       4000,
+		dirty:      &dirty,
+	},
+	{
+		name:       `\\?\Volume{33333333-0000-0000-0000-100000000000}\`,
+		label:      "Recovery",
+		fileSystem: "NTFS",
+		total:      500,
+		free:       100,
+	},
+	{
+		name:       `\\?\Volume{44444444-0000-0000-0000-100000000000}\`,
+		paths:      []string{`F:\`},
+		fileSystem: "FAT32",
+		total:      100,
+		free:       50,
+	},
+}
+
+func TestGather(t *testing.T) {
+	w := &WinVolumes{
+		IgnoreFileSystem: []string{"fat32"},
+		BitLocker:        true,
+		Log:              testutil.Logger{},
+		volumes:          func() ([]volume, error) { return volumes, nil },
+		query: func(_, _ string, fn func(properties map[string]interface{}) error) error {
+			if err := fn(map[string]interface{}{
+				"DeviceID":         `\\?\Volume{11111111-0000-0000-0000-100000000000}\`,
+				"ProtectionStatus": uint32(1),
+			}); err != nil {
+				return err
+			}
+			return fn(map[string]interface{}{
+				"DeviceID":         `\\?\Volume{22222222-0000-0000-0000-100000000000}\`,
+				"ProtectionStatus": uint32(0),
+			})
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_volumes",
+			map[string]string{
+				"volume":      `\\?\Volume{11111111-0000-0000-0000-100000000000}\`,
+				"path":        `C:\`,
+				"label":       "System",
+				"file_system": "NTFS",
+			},
+			map[string]interface{}{
+				"total":                 uint64(1000),
+				"free":                  uint64(250),
+				"used":                  uint64(750),
+				"used_percent":          float64(75),
+				"mount_points":          `C:\`,
+				"dirty":                 false,
+				"bitlocker_status":      "on",
+				"bitlocker_status_code": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"win_volumes",
+			map[string]string{
+				"volume":      `\\?\Volume{22222222-0000-0000-0000-100000000000}\`,
+				"path":        `D:\Mounts\Log\`,
+				"file_system": "ReFS",
+			},
+			map[string]interface{}{
+				"total":                 uint64(4000),
+				"free":                  uint64(4000),
+				"used":                  uint64(0),
+				"used_percent":          float64(0),
+				"mount_points":          `D:\Mounts\Log\;E:\`,
+				"dirty":                 true,
+				"bitlocker_status":      "off",
+				"bitlocker_status_code": int64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherUnmounted(t *testing.T) {
+	w := &WinVolumes{
+		IncludeUnmounted: true,
+		BitLocker:        true,
+		Log:              testutil.Logger{},
+		volumes:          func() ([]volume, error) { return volumes[2:3], nil },
+		query: func(string, string, func(map[string]interface{}) error) error {
+			return errors.New("access denied")
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"win_volumes",
+			map[string]string{
+				"volume":      `\\?\Volume{33333333-0000-0000-0000-100000000000}\`,
+				"label":       "Recovery",
+				"file_system": "NTFS",
+			},
+			map[string]interface{}{
+				"total":        uint64(500),
+				"free":         uint64(100),
+				"used":         uint64(400),
+				"used_percent": float64(80),
+				"mount_points": "",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,101 @@
+//go:build windows
+// +build windows
+
+package windows_defender
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const namespace = `root\Microsoft\Windows\Defender`
+
+// WindowsDefender reports the protection status of Microsoft Defender
+// Antivirus.
+type WindowsDefender struct {
+	Log telegraf.Logger `toml:"-"`
+
+	query wmi.QueryFunc
+}
+
+func (w *WindowsDefender) Description() string {
+	return "Report the protection status and threats of Microsoft Defender Antivirus"
+}
+
+func (w *WindowsDefender) SampleConfig() string { return "" }
+
+func (w *WindowsDefender) Gather(acc telegraf.Accumulator) error {
+	fields := make(map[string]interface{})
+	err := w.query(namespace,
+		"SELECT AMServiceEnabled, AntivirusEnabled, AntispywareEnabled, RealTimeProtectionEnabled, BehaviorMonitorEnabled, IoavProtectionEnabled, NISEnabled, IsTamperProtected, AMRunningMode, AntivirusSignatureAge, AntispywareSignatureAge, AntivirusSignatureLastUpdated, QuickScanEndTime, FullScanEndTime FROM MSFT_MpComputerStatus",
+		func(p map[string]interface{}) error {
+			for property, field := range map[string]string{
+				"AMServiceEnabled":          "service_enabled",
+				"AntivirusEnabled":          "antivirus_enabled",
+				"AntispywareEnabled":        "antispyware_enabled",
+				"RealTimeProtectionEnabled": "real_time_protection_enabled",
+				"BehaviorMonitorEnabled":    "behavior_monitor_enabled",
+				"IoavProtectionEnabled":     "ioav_protection_enabled",
+				"NISEnabled":                "nis_enabled",
+				"IsTamperProtected":         "tamper_protected",
+			} {
+				if v, ok := p[property].(bool); ok {
+					fields[field] = v
+				}
+			}
+			if mode, ok := p["AMRunningMode"].(string); ok {
+				fields["running_mode"] = mode
+			}
+			for property, field := range map[string]string{
+				"AntivirusSignatureAge":   "antivirus_signature_age_days",
+				"AntispywareSignatureAge": "antispyware_signature_age_days",
+			} {
+				if v, err := wmi.Uint64(p[property]); err == nil {
+					fields[field] = v
+				}
+			}
+			// Times that never occurred are returned as null or as the
+			// zero CIM datetime.
+			for property, field := range map[string]string{
+				"AntivirusSignatureLastUpdated": "signature_last_updated",
+				"QuickScanEndTime":              "last_quick_scan_time",
+				"FullScanEndTime":               "last_full_scan_time",
+			} {
+				if t, err := wmi.Time(p[property]); err == nil && t.Year() > 1601 {
+					fields[field] = t.Unix()
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("querying computer status failed: %w", err)
+	}
+
+	var detected, active int
+	err = w.query(namespace, "SELECT ThreatID, IsActive FROM MSFT_MpThreat",
+		func(p map[string]interface{}) error {
+			detected++
+			if isActive, _ := p["IsActive"].(bool); isActive {
+				active++
+			}
+			return nil
+		})
+	if err != nil {
+		acc.AddError(fmt.Errorf("querying threats failed: %w", err))
+	} else {
+		fields["threats_detected"] = detected
+		fields["threats_active"] = active
+	}
+
+	acc.AddFields("windows_defender", fields, nil)
+	return nil
+}
+
+func init() {
+	inputs.Add("windows_defender", func() telegraf.Input {
+		return &WindowsDefender{query: (&wmi.Connection{}).Query}
+	})
+}
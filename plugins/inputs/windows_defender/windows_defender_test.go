@@ -0,0 +1,85 @@
+//go:build windows
+// +build windows
+
+package windows_defender
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	w := &WindowsDefender{
+		query: wmi.FakeQuery(map[string][]map[string]interface{}{
+			"MSFT_MpComputerStatus": {
+				{
+					"AMServiceEnabled":              true,
+					"AntivirusEnabled":              true,
+					"AntispywareEnabled":            true,
+					"RealTimeProtectionEnabled":     false,
+					"BehaviorMonitorEnabled":        true,
+					"IoavProtectionEnabled":         true,
+					"NISEnabled":                    true,
+					"IsTamperProtected":             true,
+					"AMRunningMode":                 "Normal",
+					"AntivirusSignatureAge":         int32(1),
+					"AntispywareSignatureAge":       int32(1),
+					"AntivirusSignatureLastUpdated": "20211013060000.000000+000",
+					"QuickScanEndTime":              "20211014020000.000000+000",
+					"FullScanEndTime":               "16010101000000.000000+000",
+				},
+			},
+			"MSFT_MpThreat": {
+				{"ThreatID": "2147519003", "IsActive": true},
+				{"ThreatID": "2147735503", "IsActive": false},
+			},
+		}),
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("windows_defender",
+			map[string]string{},
+			map[string]interface{}{
+				"service_enabled":                true,
+				"antivirus_enabled":              true,
+				"antispyware_enabled":            true,
+				"real_time_protection_enabled":   false,
+				"behavior_monitor_enabled":       true,
+				"ioav_protection_enabled":        true,
+				"nis_enabled":                    true,
+				"tamper_protected":               true,
+				"running_mode":                   "Normal",
+				"antivirus_signature_age_days":   uint64(1),
+				"antispyware_signature_age_days": uint64(1),
+				"signature_last_updated":         time.Date(2021, 10, 13, 6, 0, 0, 0, time.UTC).Unix(),
+				"last_quick_scan_time":           time.Date(2021, 10, 14, 2, 0, 0, 0, time.UTC).Unix(),
+				"threats_detected":               2,
+				"threats_active":                 1,
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherNotInstalled(t *testing.T) {
+	w := &WindowsDefender{
+		query: func(string, string, func(map[string]interface{}) error) error {
+			return errors.New("invalid namespace")
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.Error(t, w.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,234 @@
+//go:build windows
+// +build windows
+
+package windows_firewall
+
+import (
+	"fmt"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+// Values of the NET_FW_RULE_DIRECTION and NET_FW_ACTION enumerations.
+const (
+	directionIn  = 1
+	directionOut = 2
+	actionBlock  = 0
+	actionAllow  = 1
+)
+
+// profileTypes are the NET_FW_PROFILE_TYPE2 values of the profiles by name.
+var profileTypes = []struct {
+	name string
+	typ  int32
+}{
+	{"domain", 1},
+	{"private", 2},
+	{"public", 4},
+}
+
+// profile is the configuration of a firewall profile.
+type profile struct {
+	enabled         bool
+	defaultInbound  int32
+	defaultOutbound int32
+}
+
+// rule is a firewall rule. The profiles are a bit mask of the profile types
+// the rule applies to.
+type rule struct {
+	enabled   bool
+	direction int32
+	action    int32
+	profiles  int32
+}
+
+// firewallStatus is the effective configuration of the firewall.
+type firewallStatus struct {
+	// profiles are the profiles by type.
+	profiles map[int32]profile
+	// current is a bit mask of the active profile types.
+	current int32
+	rules   []rule
+}
+
+// firewallPolicy returns the effective configuration of the firewall.
+type firewallPolicy interface {
+	status() (*firewallStatus, error)
+}
+
+// WindowsFirewall reports the state of the firewall profiles and rules.
+type WindowsFirewall struct {
+	Log telegraf.Logger `toml:"-"`
+
+	policy firewallPolicy
+}
+
+func (w *WindowsFirewall) Description() string {
+	return "Report the state of the Windows Firewall profiles and rules"
+}
+
+func (w *WindowsFirewall) SampleConfig() string { return "" }
+
+func (w *WindowsFirewall) Gather(acc telegraf.Accumulator) error {
+	status, err := w.policy.status()
+	if err != nil {
+		return fmt.Errorf("querying firewall policy failed: %w", err)
+	}
+
+	for _, pt := range profileTypes {
+		p, ok := status.profiles[pt.typ]
+		if !ok {
+			continue
+		}
+
+		var inboundAllow, inboundBlock, outboundAllow, outboundBlock int64
+		for _, r := range status.rules {
+			if !r.enabled || r.profiles&pt.typ == 0 {
+				continue
+			}
+			switch {
+			case r.direction == directionIn && r.action == actionAllow:
+				inboundAllow++
+			case r.direction == directionIn && r.action == actionBlock:
+				inboundBlock++
+			case r.direction == directionOut && r.action == actionAllow:
+				outboundAllow++
+			case r.direction == directionOut && r.action == actionBlock:
+				outboundBlock++
+			}
+		}
+
+		fields := map[string]interface{}{
+			"enabled":                 p.enabled,
+			"active":                  status.current&pt.typ != 0,
+			"default_inbound_action":  actionName(p.defaultInbound),
+			"default_outbound_action": actionName(p.defaultOutbound),
+			"inbound_allow_rules":     inboundAllow,
+			"inbound_block_rules":     inboundBlock,
+			"outbound_allow_rules":    outboundAllow,
+			"outbound_block_rules":    outboundBlock,
+		}
+		acc.AddFields("windows_firewall", fields, map[string]string{"profile": pt.name})
+	}
+	return nil
+}
+
+// actionName returns the name of the NET_FW_ACTION value.
+func actionName(action int32) string {
+	switch action {
+	case actionBlock:
+		return "block"
+	case actionAllow:
+		return "allow"
+	}
+	return "unknown"
+}
+
+// comPolicy queries the firewall using the HNetCfg.FwPolicy2 COM object.
+type comPolicy struct{}
+
+func (p comPolicy) status() (status *firewallStatus, err error) {
+	err = wmi.WithCOM(func() error {
+		status, err = p.statusCOM()
+		return err
+	})
+	return status, err
+}
+
+func (comPolicy) statusCOM() (*firewallStatus, error) {
+	unknown, err := oleutil.CreateObject("HNetCfg.FwPolicy2")
+	if err != nil {
+		return nil, fmt.Errorf("creating firewall policy failed: %w", err)
+	}
+	defer unknown.Release()
+
+	policy, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("creating firewall policy failed: %w", err)
+	}
+	defer policy.Release()
+
+	status := &firewallStatus{profiles: make(map[int32]profile)}
+	current, err := int32Property(policy, "CurrentProfileTypes")
+	if err != nil {
+		return nil, err
+	}
+	status.current = current
+
+	for _, pt := range profileTypes {
+		var p profile
+		enabled, err := oleutil.GetProperty(policy, "FirewallEnabled", pt.typ)
+		if err != nil {
+			return nil, fmt.Errorf("querying state of profile %q failed: %w", pt.name, err)
+		}
+		p.enabled, _ = enabled.Value().(bool)
+		enabled.Clear() //nolint:errcheck,revive // nothing to do on error
+
+		if p.defaultInbound, err = int32Property(policy, "DefaultInboundAction", pt.typ); err != nil {
+			return nil, err
+		}
+		if p.defaultOutbound, err = int32Property(policy, "DefaultOutboundAction", pt.typ); err != nil {
+			return nil, err
+		}
+		status.profiles[pt.typ] = p
+	}
+
+	rulesRaw, err := oleutil.GetProperty(policy, "Rules")
+	if err != nil {
+		return nil, fmt.Errorf("querying rules failed: %w", err)
+	}
+	defer rulesRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	err = oleutil.ForEach(rulesRaw.ToIDispatch(), func(v *ole.VARIANT) error {
+		item := v.ToIDispatch()
+		defer item.Release()
+
+		var r rule
+		enabled, err := oleutil.GetProperty(item, "Enabled")
+		if err != nil {
+			return err
+		}
+		r.enabled, _ = enabled.Value().(bool)
+		enabled.Clear() //nolint:errcheck,revive // nothing to do on error
+
+		if r.direction, err = int32Property(item, "Direction"); err != nil {
+			return err
+		}
+		if r.action, err = int32Property(item, "Action"); err != nil {
+			return err
+		}
+		if r.profiles, err = int32Property(item, "Profiles"); err != nil {
+			return err
+		}
+		status.rules = append(status.rules, r)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying rules failed: %w", err)
+	}
+	return status, nil
+}
+
+// int32Property returns the integer property of the object.
+func int32Property(disp *ole.IDispatch, name string, params ...interface{}) (int32, error) {
+	v, err := oleutil.GetProperty(disp, name, params...)
+	if err != nil {
+		return 0, fmt.Errorf("querying %s failed: %w", name, err)
+	}
+	defer v.Clear() //nolint:errcheck // nothing to do on error
+
+	i, _ := v.Value().(int32)
+	return i, nil
+}
+
+func init() {
+	inputs.Add("windows_firewall", func() telegraf.Input {
+		return &WindowsFirewall{policy: comPolicy{}}
+	})
+}
@@ -0,0 +1,89 @@
+//go:build windows
+// +build windows
+
+package windows_firewall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakePolicy returns the given status.
+type fakePolicy firewallStatus
+
+func (f *fakePolicy) status() (*firewallStatus, error) {
+	s := firewallStatus(*f)
+	return &s, nil
+}
+
+func TestGather(t *testing.T) {
+	w := &WindowsFirewall{
+		policy: &fakePolicy{
+			profiles: map[int32]profile{
+				1: {enabled: true, defaultInbound: actionBlock, defaultOutbound: actionAllow},
+				2: {enabled: true, defaultInbound: actionBlock, defaultOutbound: actionAllow},
+				4: {enabled: false, defaultInbound: actionAllow, defaultOutbound: actionAllow},
+			},
+			current: 1,
+			rules: []rule{
+				{enabled: true, direction: directionIn, action: actionAllow, profiles: 0x7FFFFFFF},
+				{enabled: true, direction: directionIn, action: actionAllow, profiles: 4},
+				{enabled: false, direction: directionIn, action: actionAllow, profiles: 1},
+				{enabled: true, direction: directionIn, action: actionBlock, profiles: 1 | 2},
+				{enabled: true, direction: directionOut, action: actionBlock, profiles: 2},
+				{enabled: true, direction: directionOut, action: actionAllow, profiles: 1},
+			},
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("windows_firewall",
+			map[string]string{"profile": "domain"},
+			map[string]interface{}{
+				"enabled":                 true,
+				"active":                  true,
+				"default_inbound_action":  "block",
+				"default_outbound_action": "allow",
+				"inbound_allow_rules":     int64(1),
+				"inbound_block_rules":     int64(1),
+				"outbound_allow_rules":    int64(1),
+				"outbound_block_rules":    int64(0),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("windows_firewall",
+			map[string]string{"profile": "private"},
+			map[string]interface{}{
+				"enabled":                 true,
+				"active":                  false,
+				"default_inbound_action":  "block",
+				"default_outbound_action": "allow",
+				"inbound_allow_rules":     int64(1),
+				"inbound_block_rules":     int64(1),
+				"outbound_allow_rules":    int64(0),
+				"outbound_block_rules":    int64(1),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("windows_firewall",
+			map[string]string{"profile": "public"},
+			map[string]interface{}{
+				"enabled":                 false,
+				"active":                  false,
+				"default_inbound_action":  "allow",
+				"default_outbound_action": "allow",
+				"inbound_allow_rules":     int64(2),
+				"inbound_block_rules":     int64(0),
+				"outbound_allow_rules":    int64(0),
+				"outbound_block_rules":    int64(0),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -0,0 +1,235 @@
+//go:build windows
+// +build windows
+
+package windows_scheduled_tasks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Paths of the tasks to collect, all if empty. The paths are matched with
+  ## forward slashes as separators, e.g. "/Backup/*" for the tasks in the
+  ## Backup folder. Globs accepted.
+  # task_paths = []
+
+  ## Paths of the tasks to skip, e.g. the tasks of Windows. Globs accepted.
+  # exclude_task_paths = ["/Microsoft/*"]
+`
+
+// taskEnumHidden includes hidden tasks in the enumeration of the tasks.
+const taskEnumHidden = 1
+
+// taskStates are the names of the TASK_STATE values.
+var taskStates = []string{"unknown", "disabled", "queued", "ready", "running"}
+
+// task is a registered task and its run status.
+type task struct {
+	// path is the path of the task, e.g. '\Backup\Nightly'.
+	path       string
+	enabled    bool
+	state      int32
+	lastRun    time.Time
+	lastResult uint32
+	nextRun    time.Time
+	missedRuns int32
+}
+
+// taskScheduler returns the tasks registered in the Task Scheduler.
+type taskScheduler interface {
+	tasks() ([]task, error)
+}
+
+// WindowsScheduledTasks reports the run status of the scheduled tasks.
+type WindowsScheduledTasks struct {
+	TaskPaths        []string `toml:"task_paths"`
+	ExcludeTaskPaths []string `toml:"exclude_task_paths"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	filter    filter.Filter
+	scheduler taskScheduler
+}
+
+func (w *WindowsScheduledTasks) Description() string {
+	return "Report the last and next run and the result of the Windows scheduled tasks"
+}
+
+func (w *WindowsScheduledTasks) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WindowsScheduledTasks) Init() error {
+	f, err := filter.NewIncludeExcludeFilter(w.TaskPaths, w.ExcludeTaskPaths)
+	if err != nil {
+		return fmt.Errorf("compiling task paths failed: %w", err)
+	}
+	w.filter = f
+	return nil
+}
+
+func (w *WindowsScheduledTasks) Gather(acc telegraf.Accumulator) error {
+	tasks, err := w.scheduler.tasks()
+	if err != nil {
+		return fmt.Errorf("querying tasks failed: %w", err)
+	}
+
+	for _, t := range tasks {
+		if !w.filter.Match(strings.ReplaceAll(t.path, `\`, "/")) {
+			continue
+		}
+
+		state := "unknown"
+		if t.state >= 0 && int(t.state) < len(taskStates) {
+			state = taskStates[t.state]
+		}
+		fields := map[string]interface{}{
+			"enabled":     t.enabled,
+			"state":       state,
+			"state_code":  int64(t.state),
+			"last_result": int64(t.lastResult),
+			"missed_runs": int64(t.missedRuns),
+		}
+		if validTime(t.lastRun) {
+			fields["last_run_time"] = t.lastRun.Unix()
+		}
+		if validTime(t.nextRun) {
+			fields["next_run_time"] = t.nextRun.Unix()
+		}
+
+		folder, name := `\`, t.path
+		if i := strings.LastIndex(t.path, `\`); i >= 0 {
+			name = t.path[i+1:]
+			if i > 0 {
+				folder = t.path[:i]
+			}
+		}
+		tags := map[string]string{"task_folder": folder, "task_name": name}
+		acc.AddFields("windows_scheduled_task", fields, tags)
+	}
+	return nil
+}
+
+// validTime returns false for the times of runs that did not happen or are
+// not scheduled. These are reported as 1899-12-30, the zero COM date, or as
+// 1999-11-30.
+func validTime(t time.Time) bool {
+	return t.Year() >= 2000
+}
+
+// comScheduler queries the Task Scheduler using its COM API.
+type comScheduler struct{}
+
+func (s comScheduler) tasks() (tasks []task, err error) {
+	err = wmi.WithCOM(func() error {
+		tasks, err = s.tasksCOM()
+		return err
+	})
+	return tasks, err
+}
+
+func (comScheduler) tasksCOM() ([]task, error) {
+	unknown, err := oleutil.CreateObject("Schedule.Service")
+	if err != nil {
+		return nil, fmt.Errorf("creating task service failed: %w", err)
+	}
+	defer unknown.Release()
+
+	service, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("creating task service failed: %w", err)
+	}
+	defer service.Release()
+
+	if _, err := oleutil.CallMethod(service, "Connect"); err != nil {
+		return nil, fmt.Errorf("connecting to task service failed: %w", err)
+	}
+
+	rootRaw, err := oleutil.CallMethod(service, "GetFolder", `\`)
+	if err != nil {
+		return nil, err
+	}
+	defer rootRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	var tasks []task
+	err = folderTasks(rootRaw.ToIDispatch(), &tasks)
+	return tasks, err
+}
+
+// folderTasks adds the tasks of the folder and its subfolders.
+func folderTasks(folder *ole.IDispatch, tasks *[]task) error {
+	tasksRaw, err := oleutil.CallMethod(folder, "GetTasks", taskEnumHidden)
+	if err != nil {
+		return err
+	}
+	defer tasksRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	err = oleutil.ForEach(tasksRaw.ToIDispatch(), func(v *ole.VARIANT) error {
+		item := v.ToIDispatch()
+		defer item.Release()
+
+		t, err := registeredTask(item)
+		if err != nil {
+			return err
+		}
+		*tasks = append(*tasks, t)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	foldersRaw, err := oleutil.CallMethod(folder, "GetFolders", 0)
+	if err != nil {
+		return err
+	}
+	defer foldersRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	return oleutil.ForEach(foldersRaw.ToIDispatch(), func(v *ole.VARIANT) error {
+		subfolder := v.ToIDispatch()
+		defer subfolder.Release()
+
+		return folderTasks(subfolder, tasks)
+	})
+}
+
+// registeredTask returns the properties of the IRegisteredTask.
+func registeredTask(item *ole.IDispatch) (task, error) {
+	var t task
+	for name, fn := range map[string]func(v interface{}){
+		"Path":               func(v interface{}) { t.path, _ = v.(string) },
+		"Enabled":            func(v interface{}) { t.enabled, _ = v.(bool) },
+		"State":              func(v interface{}) { t.state, _ = v.(int32) },
+		"LastRunTime":        func(v interface{}) { t.lastRun, _ = v.(time.Time) },
+		"LastTaskResult":     func(v interface{}) { r, _ := v.(int32); t.lastResult = uint32(r) },
+		"NextRunTime":        func(v interface{}) { t.nextRun, _ = v.(time.Time) },
+		"NumberOfMissedRuns": func(v interface{}) { t.missedRuns, _ = v.(int32) },
+	} {
+		raw, err := oleutil.GetProperty(item, name)
+		if err != nil {
+			return t, fmt.Errorf("querying %s failed: %w", name, err)
+		}
+		fn(raw.Value())
+		raw.Clear() //nolint:errcheck,revive // nothing to do on error
+	}
+	return t, nil
+}
+
+func init() {
+	inputs.Add("windows_scheduled_tasks", func() telegraf.Input {
+		return &WindowsScheduledTasks{
+			ExcludeTaskPaths: []string{"/Microsoft/*"},
+			scheduler:        comScheduler{},
+		}
+	})
+}
@@ -0,0 +1,84 @@
+//go:build windows
+// +build windows
+
+package windows_scheduled_tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeScheduler returns the given tasks.
+type fakeScheduler []task
+
+func (f fakeScheduler) tasks() ([]task, error) {
+	return f, nil
+}
+
+func TestGather(t *testing.T) {
+	lastRun := time.Date(2021, 10, 14, 2, 0, 0, 0, time.UTC)
+	nextRun := time.Date(2021, 10, 15, 2, 0, 0, 0, time.UTC)
+	w := &WindowsScheduledTasks{
+		ExcludeTaskPaths: []string{"/Microsoft/*"},
+		scheduler: fakeScheduler{
+			{path: `\Backup\Nightly`, enabled: true, state: 3, lastRun: lastRun, lastResult: 0x80070002, nextRun: nextRun},
+			{path: `\Cleanup`, enabled: false, state: 1, lastRun: time.Date(1999, 11, 30, 0, 0, 0, 0, time.UTC), lastResult: 0x41303, nextRun: time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)},
+			{path: `\Microsoft\Windows\Defrag\ScheduledDefrag`, enabled: true, state: 3},
+		},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("windows_scheduled_task",
+			map[string]string{"task_folder": `\Backup`, "task_name": "Nightly"},
+			map[string]interface{}{
+				"enabled":       true,
+				"state":         "ready",
+				"state_code":    int64(3),
+				"last_result":   int64(0x80070002),
+				"missed_runs":   int64(0),
+				"last_run_time": lastRun.Unix(),
+				"next_run_time": nextRun.Unix(),
+			},
+			time.Unix(0, 0)),
+		testutil.MustMetric("windows_scheduled_task",
+			map[string]string{"task_folder": `\`, "task_name": "Cleanup"},
+			map[string]interface{}{
+				"enabled":     false,
+				"state":       "disabled",
+				"state_code":  int64(1),
+				"last_result": int64(0x41303),
+				"missed_runs": int64(0),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherTaskPaths(t *testing.T) {
+	w := &WindowsScheduledTasks{
+		TaskPaths: []string{"/Backup/*"},
+		scheduler: fakeScheduler{
+			{path: `\Backup\Nightly`, state: 3},
+			{path: `\Backup\Weekly`, state: 3},
+			{path: `\Cleanup`, state: 3},
+		},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+	for _, m := range acc.GetTelegrafMetrics() {
+		folder, _ := m.GetTag("task_folder")
+		require.Equal(t, `\Backup`, folder)
+	}
+}
@@ -0,0 +1,242 @@
+//go:build windows
+// +build windows
+
+package windows_update
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Criteria of the updates counted as pending, see
+  ## https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesearcher-search
+  # search_criteria = "IsInstalled=0 and IsHidden=0"
+
+  ## Search the update service instead of the updates known from the last
+  ## search of Windows Update. An online search takes up to minutes and
+  ## should only be used with a long collection interval.
+  # online_search = false
+`
+
+// severities are the MSRC severities of the updates, updates without
+// severity are counted as unspecified.
+var severities = []string{"critical", "important", "moderate", "low", "unspecified"}
+
+// updateStatus is the update status of the system.
+type updateStatus struct {
+	// pending are the number of pending updates by lower case severity.
+	pending        map[string]int
+	lastInstall    time.Time
+	lastSearch     time.Time
+	rebootRequired bool
+}
+
+// updateAgent returns the update status of the system.
+type updateAgent interface {
+	status(criteria string, online bool) (*updateStatus, error)
+}
+
+// WindowsUpdate reports the update status of the system using the Windows
+// Update Agent API.
+type WindowsUpdate struct {
+	SearchCriteria string `toml:"search_criteria"`
+	OnlineSearch   bool   `toml:"online_search"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	agent updateAgent
+}
+
+func (w *WindowsUpdate) Description() string {
+	return "Report pending updates and the reboot status of Windows Update"
+}
+
+func (w *WindowsUpdate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WindowsUpdate) Gather(acc telegraf.Accumulator) error {
+	status, err := w.agent.status(w.SearchCriteria, w.OnlineSearch)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"reboot_required": status.rebootRequired,
+	}
+	var total int
+	for _, severity := range severities {
+		fields["pending_"+severity] = status.pending[severity]
+		total += status.pending[severity]
+	}
+	fields["pending_updates"] = total
+	if !status.lastInstall.IsZero() {
+		fields["last_install_time"] = status.lastInstall.Unix()
+	}
+	if !status.lastSearch.IsZero() {
+		fields["last_search_time"] = status.lastSearch.Unix()
+	}
+	acc.AddFields("windows_update", fields, nil)
+	return nil
+}
+
+// wuaAgent queries the Windows Update Agent using its COM API.
+type wuaAgent struct{}
+
+func (a wuaAgent) status(criteria string, online bool) (status *updateStatus, err error) {
+	err = wmi.WithCOM(func() error {
+		status, err = a.statusCOM(criteria, online)
+		return err
+	})
+	return status, err
+}
+
+func (wuaAgent) statusCOM(criteria string, online bool) (*updateStatus, error) {
+	status := &updateStatus{pending: make(map[string]int)}
+	if err := searchPending(status, criteria, online); err != nil {
+		return nil, fmt.Errorf("searching updates failed: %w", err)
+	}
+
+	auto, err := createObject("Microsoft.Update.AutoUpdate")
+	if err != nil {
+		return nil, err
+	}
+	defer auto.Release()
+
+	results, err := oleutil.GetProperty(auto, "Results")
+	if err != nil {
+		return nil, fmt.Errorf("querying update results failed: %w", err)
+	}
+	defer results.Clear() //nolint:errcheck // nothing to do on error
+	status.lastInstall = dateProperty(results.ToIDispatch(), "LastInstallationSuccessDate")
+	status.lastSearch = dateProperty(results.ToIDispatch(), "LastSearchSuccessDate")
+
+	info, err := createObject("Microsoft.Update.SystemInfo")
+	if err != nil {
+		return nil, err
+	}
+	defer info.Release()
+
+	reboot, err := oleutil.GetProperty(info, "RebootRequired")
+	if err != nil {
+		return nil, fmt.Errorf("querying reboot status failed: %w", err)
+	}
+	status.rebootRequired, _ = reboot.Value().(bool)
+	reboot.Clear() //nolint:errcheck,revive // nothing to do on error
+
+	return status, nil
+}
+
+// searchPending counts the updates matching the criteria by severity.
+func searchPending(status *updateStatus, criteria string, online bool) error {
+	session, err := createObject("Microsoft.Update.Session")
+	if err != nil {
+		return err
+	}
+	defer session.Release()
+
+	searcherRaw, err := oleutil.CallMethod(session, "CreateUpdateSearcher")
+	if err != nil {
+		return err
+	}
+	defer searcherRaw.Clear() //nolint:errcheck // nothing to do on error
+	searcher := searcherRaw.ToIDispatch()
+
+	if _, err := oleutil.PutProperty(searcher, "Online", online); err != nil {
+		return err
+	}
+	resultRaw, err := oleutil.CallMethod(searcher, "Search", criteria)
+	if err != nil {
+		return err
+	}
+	defer resultRaw.Clear() //nolint:errcheck // nothing to do on error
+
+	updatesRaw, err := oleutil.GetProperty(resultRaw.ToIDispatch(), "Updates")
+	if err != nil {
+		return err
+	}
+	defer updatesRaw.Clear() //nolint:errcheck // nothing to do on error
+	updates := updatesRaw.ToIDispatch()
+
+	countRaw, err := oleutil.GetProperty(updates, "Count")
+	if err != nil {
+		return err
+	}
+	count, _ := countRaw.Value().(int32)
+	countRaw.Clear() //nolint:errcheck,revive // nothing to do on error
+
+	for i := 0; i < int(count); i++ {
+		itemRaw, err := oleutil.GetProperty(updates, "Item", i)
+		if err != nil {
+			return err
+		}
+		severityRaw, err := oleutil.GetProperty(itemRaw.ToIDispatch(), "MsrcSeverity")
+		itemRaw.Clear() //nolint:errcheck,revive // nothing to do on error
+		if err != nil {
+			return err
+		}
+		severity, _ := severityRaw.Value().(string)
+		severityRaw.Clear() //nolint:errcheck,revive // nothing to do on error
+
+		status.pending[normalizeSeverity(severity)]++
+	}
+	return nil
+}
+
+// normalizeSeverity returns the lower case severity, unknown and missing
+// severities are unspecified.
+func normalizeSeverity(severity string) string {
+	severity = strings.ToLower(severity)
+	for _, s := range severities {
+		if s == severity {
+			return s
+		}
+	}
+	return "unspecified"
+}
+
+// dateProperty returns the date property of the object, the zero time if it
+// is not set.
+func dateProperty(disp *ole.IDispatch, name string) time.Time {
+	v, err := oleutil.GetProperty(disp, name)
+	if err != nil {
+		return time.Time{}
+	}
+	defer v.Clear() //nolint:errcheck // nothing to do on error
+
+	t, _ := v.Value().(time.Time)
+	return t
+}
+
+// createObject creates the COM object with the given program ID.
+func createObject(programID string) (*ole.IDispatch, error) {
+	unknown, err := oleutil.CreateObject(programID)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s failed: %w", programID, err)
+	}
+	defer unknown.Release()
+
+	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s failed: %w", programID, err)
+	}
+	return disp, nil
+}
+
+func init() {
+	inputs.Add("windows_update", func() telegraf.Input {
+		return &WindowsUpdate{
+			SearchCriteria: "IsInstalled=0 and IsHidden=0",
+			agent:          wuaAgent{},
+		}
+	})
+}
@@ -0,0 +1,95 @@
+//go:build windows
+// +build windows
+
+package windows_update
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeAgent returns the given status and records the search parameters.
+type fakeAgent struct {
+	result   *updateStatus
+	err      error
+	criteria string
+	online   bool
+}
+
+func (f *fakeAgent) status(criteria string, online bool) (*updateStatus, error) {
+	f.criteria = criteria
+	f.online = online
+	return f.result, f.err
+}
+
+func TestGather(t *testing.T) {
+	agent := &fakeAgent{
+		result: &updateStatus{
+			pending:        map[string]int{"critical": 2, "important": 1, "unspecified": 3},
+			lastInstall:    time.Unix(1634200000, 0),
+			lastSearch:     time.Unix(1634201000, 0),
+			rebootRequired: true,
+		},
+	}
+	w := &WindowsUpdate{
+		SearchCriteria: "IsInstalled=0",
+		OnlineSearch:   true,
+		agent:          agent,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Equal(t, "IsInstalled=0", agent.criteria)
+	require.True(t, agent.online)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("windows_update",
+			map[string]string{},
+			map[string]interface{}{
+				"pending_updates":     6,
+				"pending_critical":    2,
+				"pending_important":   1,
+				"pending_moderate":    0,
+				"pending_low":         0,
+				"pending_unspecified": 3,
+				"last_install_time":   int64(1634200000),
+				"last_search_time":    int64(1634201000),
+				"reboot_required":     true,
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherNeverSearched(t *testing.T) {
+	w := &WindowsUpdate{agent: &fakeAgent{result: &updateStatus{pending: map[string]int{}}}}
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	require.False(t, metrics[0].HasField("last_install_time"))
+	require.False(t, metrics[0].HasField("last_search_time"))
+}
+
+func TestGatherError(t *testing.T) {
+	w := &WindowsUpdate{agent: &fakeAgent{err: errors.New("search failed")}}
+
+	var acc testutil.Accumulator
+	require.Error(t, w.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestNormalizeSeverity(t *testing.T) {
+	require.Equal(t, "critical", normalizeSeverity("Critical"))
+	require.Equal(t, "low", normalizeSeverity("Low"))
+	require.Equal(t, "unspecified", normalizeSeverity(""))
+	require.Equal(t, "unspecified", normalizeSeverity("Unknown"))
+}
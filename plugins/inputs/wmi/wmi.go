@@ -0,0 +1,192 @@
+//go:build windows
+// +build windows
+
+package wmi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/wmi"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Hosts to query, the local host if empty. Querying remote hosts requires
+  ## the WMI firewall rules to be enabled on them.
+  # hosts = []
+
+  ## Credentials for the remote hosts, the account of Telegraf is used if
+  ## empty. Credentials cannot be used for the local host.
+  # username = ""
+  # password = ""
+
+  [[inputs.wmi.query]]
+    ## Namespace of the classes to query
+    # namespace = "root\\cimv2"
+
+    ## WQL query
+    query = "SELECT Name, FreeSpace, Size FROM Win32_LogicalDisk WHERE DriveType = 3"
+
+    ## Name of the measurement
+    measurement = "win_disk"
+
+    ## Properties added as tags, all other properties are added as fields
+    tag_properties = ["Name"]
+
+    ## Names of the tags and fields of the properties, the property name is
+    ## used if not given
+    # [inputs.wmi.query.rename]
+    #   Name = "drive"
+
+    ## Types of the properties: "int", "uint", "float", "string" or "bool".
+    ## 64 bit integers are returned as strings by WMI and need a type.
+    [inputs.wmi.query.types]
+      FreeSpace = "uint"
+      Size = "uint"
+`
+
+// WMI queries Windows Management Instrumentation classes using WQL.
+type WMI struct {
+	Hosts    []string `toml:"hosts"`
+	Username string   `toml:"username"`
+	Password string   `toml:"password"`
+	Queries  []Query  `toml:"query"`
+
+	Log telegraf.Logger `toml:"-"`
+}
+
+// Query is a WQL query and the mapping of the returned properties.
+type Query struct {
+	Namespace     string            `toml:"namespace"`
+	Query         string            `toml:"query"`
+	Measurement   string            `toml:"measurement"`
+	TagProperties []string          `toml:"tag_properties"`
+	Rename        map[string]string `toml:"rename"`
+	Types         map[string]string `toml:"types"`
+
+	tags map[string]bool
+}
+
+func (w *WMI) Description() string {
+	return "Query Windows Management Instrumentation classes using WQL"
+}
+
+func (w *WMI) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WMI) Init() error {
+	if len(w.Queries) == 0 {
+		return errors.New("no queries configured")
+	}
+	for i := range w.Queries {
+		q := &w.Queries[i]
+		if q.Query == "" {
+			return errors.New("query must not be empty")
+		}
+		if q.Namespace == "" {
+			q.Namespace = `root\cimv2`
+		}
+		if q.Measurement == "" {
+			q.Measurement = "wmi"
+		}
+		for property, typ := range q.Types {
+			if _, err := convertValue("", typ); errors.Is(err, errUnknownType) {
+				return fmt.Errorf("invalid type %q of property %q", typ, property)
+			}
+		}
+		q.tags = make(map[string]bool, len(q.TagProperties))
+		for _, property := range q.TagProperties {
+			q.tags[property] = true
+		}
+	}
+	return nil
+}
+
+func (w *WMI) Gather(acc telegraf.Accumulator) error {
+	hosts := w.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	}
+	for _, host := range hosts {
+		conn := &wmi.Connection{Host: host, Username: w.Username, Password: w.Password}
+		for i := range w.Queries {
+			if err := w.query(acc, conn, &w.Queries[i]); err != nil {
+				if host != "" {
+					err = fmt.Errorf("host %q: %w", host, err)
+				}
+				acc.AddError(fmt.Errorf("query %q: %w", w.Queries[i].Query, err))
+			}
+		}
+	}
+	return nil
+}
+
+// query runs the query and adds a metric per returned object.
+func (w *WMI) query(acc telegraf.Accumulator, conn *wmi.Connection, q *Query) error {
+	return conn.Query(q.Namespace, q.Query, func(properties map[string]interface{}) error {
+		tags := make(map[string]string)
+		if conn.Host != "" {
+			tags["source"] = conn.Host
+		}
+		fields := make(map[string]interface{})
+		for name, value := range properties {
+			if _, ok := value.([]interface{}); ok {
+				// Arrays are not supported
+				continue
+			}
+
+			key := name
+			if rename, ok := q.Rename[name]; ok {
+				key = rename
+			}
+			if q.tags[name] {
+				tags[key] = fmt.Sprint(value)
+				continue
+			}
+			if typ, ok := q.Types[name]; ok {
+				converted, err := convertValue(value, typ)
+				if err != nil {
+					w.Log.Warnf("Converting property %q failed: %v", name, err)
+					continue
+				}
+				value = converted
+			}
+			fields[key] = value
+		}
+		if len(fields) > 0 {
+			acc.AddFields(q.Measurement, fields, tags)
+		}
+		return nil
+	})
+}
+
+var errUnknownType = errors.New("unknown type")
+
+// convertValue converts the property value to the type.
+func convertValue(value interface{}, typ string) (interface{}, error) {
+	s := fmt.Sprint(value)
+	switch typ {
+	case "int":
+		return strconv.ParseInt(s, 10, 64)
+	case "uint":
+		return strconv.ParseUint(s, 10, 64)
+	case "float":
+		return strconv.ParseFloat(s, 64)
+	case "string":
+		return s, nil
+	case "bool":
+		return strconv.ParseBool(s)
+	default:
+		return nil, errUnknownType
+	}
+}
+
+func init() {
+	inputs.Add("wmi", func() telegraf.Input {
+		return &WMI{}
+	})
+}
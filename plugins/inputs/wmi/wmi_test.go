@@ -0,0 +1,80 @@
+//go:build windows
+// +build windows
+
+package wmi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInit(t *testing.T) {
+	w := &WMI{Queries: []Query{{Query: "SELECT Name FROM Win32_Process", TagProperties: []string{"Name"}}}}
+	require.NoError(t, w.Init())
+	require.Equal(t, `root\cimv2`, w.Queries[0].Namespace)
+	require.Equal(t, "wmi", w.Queries[0].Measurement)
+	require.True(t, w.Queries[0].tags["Name"])
+
+	w = &WMI{}
+	require.Error(t, w.Init())
+
+	w = &WMI{Queries: []Query{{}}}
+	require.Error(t, w.Init())
+
+	w = &WMI{Queries: []Query{{Query: "SELECT Size FROM Win32_LogicalDisk", Types: map[string]string{"Size": "uint128"}}}}
+	require.Error(t, w.Init())
+}
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		typ      string
+		expected interface{}
+	}{
+		{"-42", "int", int64(-42)},
+		{int32(42), "int", int64(42)},
+		{"18446744073709551615", "uint", uint64(18446744073709551615)},
+		{"1.5", "float", float64(1.5)},
+		{uint32(42), "string", "42"},
+		{"true", "bool", true},
+		{true, "bool", true},
+	}
+	for _, tt := range tests {
+		actual, err := convertValue(tt.value, tt.typ)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, actual)
+	}
+
+	_, err := convertValue("abc", "int")
+	require.Error(t, err)
+	_, err = convertValue("abc", "unknown")
+	require.ErrorIs(t, err, errUnknownType)
+}
+
+func TestGatherLocal(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	w := &WMI{
+		Queries: []Query{{
+			Query:         "SELECT Caption, NumberOfProcesses, TotalVisibleMemorySize FROM Win32_OperatingSystem",
+			Measurement:   "win_os",
+			TagProperties: []string{"Caption"},
+			Rename:        map[string]string{"Caption": "os"},
+			Types:         map[string]string{"TotalVisibleMemorySize": "uint"},
+		}},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.Metrics, 1)
+	require.True(t, acc.HasTag("win_os", "os"))
+	require.True(t, acc.HasUIntField("win_os", "TotalVisibleMemorySize"))
+}
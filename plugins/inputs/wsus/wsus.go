@@ -0,0 +1,199 @@
+//go:build windows
+// +build windows
+
+package wsus
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Name of the WSUS server, the local server if empty. The port and SSL
+  ## are only used for remote servers.
+  # server = ""
+  # port = 8530
+  # use_ssl = false
+
+  ## Computers not reported to the server for this duration are counted as
+  ## not reporting.
+  # not_reported_after = "720h"
+
+  ## Timeout of the query, loading the WSUS API takes several seconds.
+  # timeout = "60s"
+`
+
+// statusScript returns the status of the update server as JSON. It is
+// formatted with the expression connecting to the server and the time in
+// seconds after which computers are not reporting.
+const statusScript = `
+$ErrorActionPreference = 'Stop'
+$ProgressPreference = 'SilentlyContinue'
+try {
+  [void][Reflection.Assembly]::LoadWithPartialName('Microsoft.UpdateServices.Administration')
+  $server = %s
+  $status = $server.GetStatus()
+  $scope = New-Object Microsoft.UpdateServices.Administration.UpdateScope
+  $scope.ApprovedStates = 'NotApproved'
+  $scope.IncludedInstallationStates = 'NotInstalled, Downloaded, InstalledPendingReboot, Failed'
+  $computers = New-Object Microsoft.UpdateServices.Administration.ComputerTargetScope
+  $computers.ToLastReportedStatusTime = (Get-Date).AddSeconds(-%d)
+  $progress = $server.GetContentDownloadProgress()
+  [pscustomobject]@{
+    ComputerTargetCount = $status.ComputerTargetCount
+    ComputersUpToDateCount = $status.ComputersUpToDateCount
+    ComputerTargetsNeedingUpdatesCount = $status.ComputerTargetsNeedingUpdatesCount
+    ComputerTargetsWithUpdateErrorsCount = $status.ComputerTargetsWithUpdateErrorsCount
+    ComputersNotReportedCount = $server.GetComputerTargetCount($computers)
+    UpdateCount = $status.UpdateCount
+    ApprovedUpdateCount = $status.ApprovedUpdateCount
+    NotApprovedUpdateCount = $status.NotApprovedUpdateCount
+    DeclinedUpdateCount = $status.DeclinedUpdateCount
+    ExpiredUpdateCount = $status.ExpiredUpdateCount
+    CriticalOrSecurityUpdatesNotApprovedForInstallCount = $status.CriticalOrSecurityUpdatesNotApprovedForInstallCount
+    UpdatesNeedingApprovalCount = $server.GetUpdateCount($scope)
+    UpdatesNeedingFilesCount = $status.UpdatesNeedingFilesCount
+    DownloadedBytes = $progress.DownloadedBytes
+    TotalBytesToDownload = $progress.TotalBytesToDownload
+  } | ConvertTo-Json -Compress
+} catch {
+  Write-Output $_.Exception.Message
+  exit 1
+}
+`
+
+// WSUS collects the statistics of a Windows Server Update Services server.
+type WSUS struct {
+	Server           string          `toml:"server"`
+	Port             int             `toml:"port"`
+	UseSSL           bool            `toml:"use_ssl"`
+	NotReportedAfter config.Duration `toml:"not_reported_after"`
+	Timeout          config.Duration `toml:"timeout"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	script string
+	run    func(script string, timeout time.Duration) ([]byte, error)
+}
+
+// serverStatus is the output of the status script.
+type serverStatus struct {
+	ComputerTargetCount                                 int64
+	ComputersUpToDateCount                              int64
+	ComputerTargetsNeedingUpdatesCount                  int64
+	ComputerTargetsWithUpdateErrorsCount                int64
+	ComputersNotReportedCount                           int64
+	UpdateCount                                         int64
+	ApprovedUpdateCount                                 int64
+	NotApprovedUpdateCount                              int64
+	DeclinedUpdateCount                                 int64
+	ExpiredUpdateCount                                  int64
+	CriticalOrSecurityUpdatesNotApprovedForInstallCount int64
+	UpdatesNeedingApprovalCount                         int64
+	UpdatesNeedingFilesCount                            int64
+	DownloadedBytes                                     int64
+	TotalBytesToDownload                                int64
+}
+
+func (w *WSUS) Description() string {
+	return "Collect the statistics of a Windows Server Update Services server"
+}
+
+func (w *WSUS) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WSUS) Init() error {
+	if w.NotReportedAfter <= 0 {
+		return errors.New("not_reported_after must be positive")
+	}
+	server := "[Microsoft.UpdateServices.Administration.AdminProxy]::GetUpdateServer()"
+	if w.Server != "" {
+		if w.Port <= 0 || w.Port > 65535 {
+			return fmt.Errorf("invalid port %d", w.Port)
+		}
+		// Single quotes are escaped by doubling them in PowerShell strings.
+		name := strings.ReplaceAll(w.Server, "'", "''")
+		server = fmt.Sprintf("[Microsoft.UpdateServices.Administration.AdminProxy]::GetUpdateServer('%s', $%t, %d)", name, w.UseSSL, w.Port)
+	}
+	w.script = fmt.Sprintf(statusScript, server, int64(time.Duration(w.NotReportedAfter).Seconds()))
+	return nil
+}
+
+func (w *WSUS) Gather(acc telegraf.Accumulator) error {
+	out, err := w.run(w.script, time.Duration(w.Timeout))
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("querying server failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("querying server failed: %w", err)
+	}
+	var s serverStatus
+	if err := json.Unmarshal(out, &s); err != nil {
+		return fmt.Errorf("parsing output failed: %w", err)
+	}
+
+	backlog := s.TotalBytesToDownload - s.DownloadedBytes
+	if backlog < 0 {
+		backlog = 0
+	}
+	fields := map[string]interface{}{
+		"computers":                     s.ComputerTargetCount,
+		"computers_up_to_date":          s.ComputersUpToDateCount,
+		"computers_needing_updates":     s.ComputerTargetsNeedingUpdatesCount,
+		"computers_with_errors":         s.ComputerTargetsWithUpdateErrorsCount,
+		"computers_not_reported":        s.ComputersNotReportedCount,
+		"updates":                       s.UpdateCount,
+		"updates_approved":              s.ApprovedUpdateCount,
+		"updates_not_approved":          s.NotApprovedUpdateCount,
+		"updates_declined":              s.DeclinedUpdateCount,
+		"updates_expired":               s.ExpiredUpdateCount,
+		"updates_critical_not_approved": s.CriticalOrSecurityUpdatesNotApprovedForInstallCount,
+		"updates_needing_approval":      s.UpdatesNeedingApprovalCount,
+		"updates_needing_files":         s.UpdatesNeedingFilesCount,
+		"content_downloaded_bytes":      s.DownloadedBytes,
+		"content_total_bytes":           s.TotalBytesToDownload,
+		"content_backlog_bytes":         backlog,
+	}
+	server := w.Server
+	if server == "" {
+		server = "localhost"
+	}
+	acc.AddFields("wsus", fields, map[string]string{"server": server})
+	return nil
+}
+
+// runScript runs the PowerShell script. The script is passed encoded to
+// avoid quoting the command line.
+func runScript(script string, timeout time.Duration) ([]byte, error) {
+	chars := utf16.Encode([]rune(script))
+	encoded := make([]byte, 0, 2*len(chars))
+	for _, ch := range chars {
+		encoded = append(encoded, byte(ch), byte(ch>>8))
+	}
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-EncodedCommand",
+		base64.StdEncoding.EncodeToString(encoded))
+	return internal.CombinedOutputTimeout(cmd, timeout)
+}
+
+func init() {
+	inputs.Add("wsus", func() telegraf.Input {
+		return &WSUS{
+			Port:             8530,
+			NotReportedAfter: config.Duration(30 * 24 * time.Hour),
+			Timeout:          config.Duration(60 * time.Second),
+			run:              runScript,
+		}
+	})
+}
@@ -0,0 +1,105 @@
+//go:build windows
+// +build windows
+
+package wsus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const statusJSON = `{"ComputerTargetCount":120,"ComputersUpToDateCount":95,"ComputerTargetsNeedingUpdatesCount":20,` +
+	`"ComputerTargetsWithUpdateErrorsCount":5,"ComputersNotReportedCount":3,"UpdateCount":4210,"ApprovedUpdateCount":1800,` +
+	`"NotApprovedUpdateCount":2300,"DeclinedUpdateCount":110,"ExpiredUpdateCount":42,` +
+	`"CriticalOrSecurityUpdatesNotApprovedForInstallCount":7,"UpdatesNeedingApprovalCount":12,"UpdatesNeedingFilesCount":4,` +
+	"\r\n" + `"DownloadedBytes":1073741824,"TotalBytesToDownload":1610612736}`
+
+func TestGather(t *testing.T) {
+	var script string
+	w := &WSUS{
+		Server:           "wsus'01",
+		Port:             8531,
+		UseSSL:           true,
+		NotReportedAfter: config.Duration(7 * 24 * time.Hour),
+		run: func(s string, _ time.Duration) ([]byte, error) {
+			script = s
+			return []byte(statusJSON), nil
+		},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	require.Contains(t, script, `GetUpdateServer('wsus''01', $true, 8531)`)
+	require.Contains(t, script, `AddSeconds(-604800)`)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("wsus",
+			map[string]string{"server": "wsus'01"},
+			map[string]interface{}{
+				"computers":                     int64(120),
+				"computers_up_to_date":          int64(95),
+				"computers_needing_updates":     int64(20),
+				"computers_with_errors":         int64(5),
+				"computers_not_reported":        int64(3),
+				"updates":                       int64(4210),
+				"updates_approved":              int64(1800),
+				"updates_not_approved":          int64(2300),
+				"updates_declined":              int64(110),
+				"updates_expired":               int64(42),
+				"updates_critical_not_approved": int64(7),
+				"updates_needing_approval":      int64(12),
+				"updates_needing_files":         int64(4),
+				"content_downloaded_bytes":      int64(1073741824),
+				"content_total_bytes":           int64(1610612736),
+				"content_backlog_bytes":         int64(536870912),
+			},
+			time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherLocal(t *testing.T) {
+	w := &WSUS{
+		NotReportedAfter: config.Duration(time.Hour),
+		run: func(s string, _ time.Duration) ([]byte, error) {
+			require.Contains(t, s, "GetUpdateServer()")
+			return []byte(`{"ComputerTargetCount":1,"DownloadedBytes":10,"TotalBytesToDownload":5}`), nil
+		},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, w.Gather(&acc))
+	m := acc.GetTelegrafMetrics()
+	require.Len(t, m, 1)
+	require.Equal(t, map[string]string{"server": "localhost"}, m[0].Tags())
+	backlog, ok := m[0].GetField("content_backlog_bytes")
+	require.True(t, ok)
+	require.Equal(t, int64(0), backlog)
+}
+
+func TestGatherError(t *testing.T) {
+	w := &WSUS{
+		NotReportedAfter: config.Duration(time.Hour),
+		run: func(string, time.Duration) ([]byte, error) {
+			return []byte("You cannot call a method on a null-valued expression.\r\n"), errors.New("exit status 1")
+		},
+	}
+	require.NoError(t, w.Init())
+
+	var acc testutil.Accumulator
+	require.EqualError(t, w.Gather(&acc), "querying server failed: exit status 1: You cannot call a method on a null-valued expression.")
+}
+
+func TestInitInvalidPort(t *testing.T) {
+	w := &WSUS{Server: "wsus01", NotReportedAfter: config.Duration(time.Hour)}
+	require.Error(t, w.Init())
+}
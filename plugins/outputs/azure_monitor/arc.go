@@ -0,0 +1,190 @@
+package azure_monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultArcIdentityEndpoint = "http://localhost:40342/metadata/identity/oauth2/token"
+	defaultArcMetadataEndpoint = "http://localhost:40342"
+	arcAPIVersion              = "2020-06-01"
+
+	// arcRefreshWithin is the time before the expiry of the token it is
+	// refreshed.
+	arcRefreshWithin = 5 * time.Minute
+)
+
+// arcToken is the token of the system-assigned managed identity of an Azure
+// Arc enabled server, read from the Hybrid Instance Metadata Service of the
+// Connected Machine agent. It implements adal.OAuthTokenProvider and
+// adal.RefresherWithContext for autorest.NewBearerAuthorizer.
+type arcToken struct {
+	endpoint string
+	resource string
+	// keyDirectory is the directory of the key files the service challenges
+	// the caller to read, proving it runs with administrative rights.
+	keyDirectory string
+	client       *http.Client
+	now          func() time.Time
+
+	sync.Mutex
+	token     string
+	expiresOn time.Time
+}
+
+func newArcToken(client *http.Client, resource string) *arcToken {
+	endpoint := os.Getenv("IDENTITY_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultArcIdentityEndpoint
+	}
+	return &arcToken{
+		endpoint:     endpoint,
+		resource:     resource,
+		keyDirectory: arcKeyDirectory(),
+		client:       client,
+		now:          time.Now,
+	}
+}
+
+// arcKeyDirectory returns the directory the Connected Machine agent writes
+// the key files to.
+func arcKeyDirectory() string {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" { // Should never happen
+			programData = "C:\\ProgramData"
+		}
+		return filepath.Join(programData, "AzureConnectedMachineAgent", "Tokens")
+	}
+	return "/var/opt/azcmagent/tokens"
+}
+
+func (t *arcToken) OAuthToken() string {
+	t.Lock()
+	defer t.Unlock()
+	return t.token
+}
+
+func (t *arcToken) EnsureFreshWithContext(ctx context.Context) error {
+	t.Lock()
+	defer t.Unlock()
+	if t.token != "" && t.now().Add(arcRefreshWithin).Before(t.expiresOn) {
+		return nil
+	}
+	return t.refresh(ctx)
+}
+
+func (t *arcToken) RefreshWithContext(ctx context.Context) error {
+	t.Lock()
+	defer t.Unlock()
+	return t.refresh(ctx)
+}
+
+func (t *arcToken) RefreshExchangeWithContext(ctx context.Context, resource string) error {
+	t.Lock()
+	defer t.Unlock()
+	t.resource = resource
+	return t.refresh(ctx)
+}
+
+// refresh requests a new token. The service answers the first request with a
+// challenge naming a key file, whose content authenticates the second one.
+func (t *arcToken) refresh(ctx context.Context) error {
+	params := url.Values{}
+	params.Set("api-version", arcAPIVersion)
+	params.Set("resource", t.resource)
+	u := t.endpoint + "?" + params.Encode()
+
+	resp, err := t.request(ctx, u, "")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		key, err := t.readKey(resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return err
+		}
+		if resp, err = t.request(ctx, u, key); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("requesting Azure Arc token failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresOn   json.Number `json:"expires_on"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("decoding Azure Arc token failed: %w", err)
+	}
+	expiresOn, err := strconv.ParseInt(token.ExpiresOn.String(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expiry %q of Azure Arc token", token.ExpiresOn)
+	}
+	t.token = token.AccessToken
+	t.expiresOn = time.Unix(expiresOn, 0)
+	return nil
+}
+
+func (t *arcToken) request(ctx context.Context, u, key string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	if key != "" {
+		req.Header.Set("Authorization", "Basic "+key)
+	}
+	return t.client.Do(req)
+}
+
+// readKey returns the content of the key file named by the challenge, e.g.
+// 'Basic realm=/var/opt/azcmagent/tokens/<id>.key'. Only key files of the
+// agent are read, so the endpoint cannot make Telegraf disclose other files.
+func (t *arcToken) readKey(challenge string) (string, error) {
+	const prefix = "basic realm="
+	if !strings.HasPrefix(strings.ToLower(challenge), prefix) {
+		return "", fmt.Errorf("unexpected Azure Arc challenge %q", challenge)
+	}
+	file := challenge[len(prefix):]
+	if !samePath(filepath.Dir(file), t.keyDirectory) || filepath.Ext(file) != ".key" {
+		return "", fmt.Errorf("Azure Arc key file %q not in %q", file, t.keyDirectory)
+	}
+	key, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("reading Azure Arc key failed, administrative rights are required: %w", err)
+	}
+	if len(key) == 0 {
+		return "", errors.New("empty Azure Arc key file")
+	}
+	return string(key), nil
+}
+
+// samePath returns true if the paths are the same, ignoring the case on
+// Windows.
+func samePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
@@ -0,0 +1,90 @@
+package azure_monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArcToken(t *testing.T) {
+	keyDir := t.TempDir()
+	keyFile := filepath.Join(keyDir, "0123.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("secret-key"), 0600))
+
+	now := time.Unix(1634212800, 0)
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "true", r.Header.Get("Metadata"))
+		require.Equal(t, arcAPIVersion, r.URL.Query().Get("api-version"))
+		require.Equal(t, defaultAuthResource, r.URL.Query().Get("resource"))
+		if r.Header.Get("Authorization") != "Basic secret-key" {
+			w.Header().Set("WWW-Authenticate", "Basic realm="+keyFile)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_on":"1634216400","token_type":"Bearer"}`))
+	}))
+	defer ts.Close()
+
+	token := &arcToken{
+		endpoint:     ts.URL,
+		resource:     defaultAuthResource,
+		keyDirectory: keyDir,
+		client:       ts.Client(),
+		now:          func() time.Time { return now },
+	}
+	require.NoError(t, token.EnsureFreshWithContext(context.Background()))
+	require.Equal(t, "token", token.OAuthToken())
+	require.Equal(t, 2, requests)
+
+	// The token is reused until shortly before it expires
+	now = now.Add(50 * time.Minute)
+	require.NoError(t, token.EnsureFreshWithContext(context.Background()))
+	require.Equal(t, 2, requests)
+
+	now = now.Add(6 * time.Minute)
+	require.NoError(t, token.EnsureFreshWithContext(context.Background()))
+	require.Equal(t, 4, requests)
+}
+
+func TestArcTokenKeyOutsideDirectory(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", "Basic realm=/etc/shadow.key")
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	token := &arcToken{
+		endpoint:     ts.URL,
+		resource:     defaultAuthResource,
+		keyDirectory: t.TempDir(),
+		client:       ts.Client(),
+		now:          time.Now,
+	}
+	err := token.EnsureFreshWithContext(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `Azure Arc key file "/etc/shadow.key" not in`)
+	require.Empty(t, token.OAuthToken())
+}
+
+func TestArcInstanceMetadata(t *testing.T) {
+	resourceID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.HybridCompute/machines/web01"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/metadata/instance", r.URL.Path)
+		require.Equal(t, arcAPIVersion, r.URL.Query().Get("api-version"))
+		_, _ = w.Write([]byte(`{"compute":{"location":"westeurope","name":"web01","resourceGroupName":"rg","subscriptionId":"sub","resourceId":"` + resourceID + `"}}`))
+	}))
+	defer ts.Close()
+
+	region, id, err := vmInstanceMetadata(ts.Client(), ts.URL+arcInstanceMetadataPath)
+	require.NoError(t, err)
+	require.Equal(t, "westeurope", region)
+	require.Equal(t, resourceID, id)
+}
@@ -5,18 +5,22 @@ import (
 	"compress/gzip"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"io"
 	"net/http"
+	"os"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/selfstat"
@@ -25,13 +29,16 @@ import (
 // AzureMonitor allows publishing of metrics to the Azure Monitor custom metrics
 // service
 type AzureMonitor struct {
-	Timeout             config.Duration
-	NamespacePrefix     string          `toml:"namespace_prefix"`
-	StringsAsDimensions bool            `toml:"strings_as_dimensions"`
-	Region              string          `toml:"region"`
-	ResourceID          string          `toml:"resource_id"`
-	EndpointURL         string          `toml:"endpoint_url"`
-	Log                 telegraf.Logger `toml:"-"`
+	Timeout                   config.Duration
+	NamespacePrefix           string          `toml:"namespace_prefix"`
+	StringsAsDimensions       bool            `toml:"strings_as_dimensions"`
+	Region                    string          `toml:"region"`
+	ResourceID                string          `toml:"resource_id"`
+	EndpointURL               string          `toml:"endpoint_url"`
+	AuthMethod                string          `toml:"auth_method"`
+	ManagedIdentityClientID   string          `toml:"managed_identity_client_id"`
+	ManagedIdentityResourceID string          `toml:"managed_identity_resource_id"`
+	Log                       telegraf.Logger `toml:"-"`
 
 	url    string
 	auth   autorest.Authorizer
@@ -51,10 +58,16 @@ type virtualMachineMetadata struct {
 		ResourceGroupName string `json:"resourceGroupName"`
 		SubscriptionID    string `json:"subscriptionId"`
 		VMScaleSetName    string `json:"vmScaleSetName"`
+		// ResourceID is only returned by newer API versions, e.g. by the
+		// metadata service of Azure Arc.
+		ResourceID string `json:"resourceId"`
 	} `json:"compute"`
 }
 
 func (m *virtualMachineMetadata) ResourceID() string {
+	if m.Compute.ResourceID != "" {
+		return m.Compute.ResourceID
+	}
 	if m.Compute.VMScaleSetName != "" {
 		return fmt.Sprintf(
 			resourceIDScaleSetTemplate,
@@ -93,6 +106,7 @@ const (
 	defaultAuthResource    = "https://monitoring.azure.com/"
 
 	vmInstanceMetadataURL      = "http://169.254.169.254/metadata/instance?api-version=2017-12-01"
+	arcInstanceMetadataPath    = "/metadata/instance?api-version=" + arcAPIVersion
 	resourceIDTemplate         = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s"
 	resourceIDScaleSetTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s"
 	urlTemplate                = "https://%s.monitoring.azure.com%s/metrics"
@@ -128,6 +142,20 @@ var sampleConfig = `
   ## cloud environment, set appropriate REST endpoint for receiving
   ## metrics. (Note: region may be unused in this context)
   # endpoint_url = "https://monitoring.core.usgovcloudapi.net"
+
+  ## Authentication method, one of
+  ##   "environment": client secret, certificate or user credentials set in
+  ##                  the AZURE_* environment variables, or the managed identity
+  ##                  if none are set.
+  ##   "managed_identity": managed identity of the Azure VM or scale set.
+  ##   "azure_arc": managed identity of the Azure Arc enabled server, requires
+  ##                administrative rights.
+  # auth_method = "environment"
+
+  ## Client ID or resource ID of the user-assigned managed identity to use
+  ## with "managed_identity", the system-assigned identity is used if empty.
+  # managed_identity_client_id = ""
+  # managed_identity_resource_id = ""
 `
 
 // Description provides a description of the plugin
@@ -140,6 +168,22 @@ func (a *AzureMonitor) SampleConfig() string {
 	return sampleConfig
 }
 
+func (a *AzureMonitor) Init() error {
+	switch a.AuthMethod {
+	case "", "environment", "managed_identity":
+	case "azure_arc":
+		if a.ManagedIdentityClientID != "" || a.ManagedIdentityResourceID != "" {
+			return errors.New("azure_arc only supports the system-assigned managed identity")
+		}
+	default:
+		return fmt.Errorf("unknown auth_method %q", a.AuthMethod)
+	}
+	if a.ManagedIdentityClientID != "" && a.ManagedIdentityResourceID != "" {
+		return errors.New("only one of managed_identity_client_id and managed_identity_resource_id can be set")
+	}
+	return nil
+}
+
 // Connect initializes the plugin and validates connectivity
 func (a *AzureMonitor) Connect() error {
 	a.cache = make(map[time.Time]map[uint64]*aggregate, 36)
@@ -150,7 +194,7 @@ func (a *AzureMonitor) Connect() error {
 
 	a.client = &http.Client{
 		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
+			Proxy: internal.Proxy,
 		},
 		Timeout: time.Duration(a.Timeout),
 	}
@@ -162,7 +206,11 @@ func (a *AzureMonitor) Connect() error {
 
 	if a.Region == "" || a.ResourceID == "" {
 		// Pull region and resource identifier
-		region, resourceID, err = vmInstanceMetadata(a.client)
+		metadataURL := vmInstanceMetadataURL
+		if a.AuthMethod == "azure_arc" {
+			metadataURL = arcMetadataEndpoint() + arcInstanceMetadataPath
+		}
+		region, resourceID, err = vmInstanceMetadata(a.client, metadataURL)
 		if err != nil {
 			return err
 		}
@@ -191,7 +239,7 @@ func (a *AzureMonitor) Connect() error {
 
 	a.Log.Debugf("Writing to Azure Monitor URL: %s", a.url)
 
-	a.auth, err = auth.NewAuthorizerFromEnvironmentWithResource(defaultAuthResource)
+	a.auth, err = a.authorizer()
 	if err != nil {
 		return err
 	}
@@ -207,9 +255,39 @@ func (a *AzureMonitor) Connect() error {
 	return nil
 }
 
-// vmMetadata retrieves metadata about the current Azure VM
-func vmInstanceMetadata(c *http.Client) (string, string, error) {
-	req, err := http.NewRequest("GET", vmInstanceMetadataURL, nil)
+// authorizer returns the authorizer of the configured authentication method.
+func (a *AzureMonitor) authorizer() (autorest.Authorizer, error) {
+	switch a.AuthMethod {
+	case "managed_identity":
+		options := &adal.ManagedIdentityOptions{
+			ClientID:           a.ManagedIdentityClientID,
+			IdentityResourceID: a.ManagedIdentityResourceID,
+		}
+		token, err := adal.NewServicePrincipalTokenFromManagedIdentity(defaultAuthResource, options)
+		if err != nil {
+			return nil, fmt.Errorf("using managed identity failed: %w", err)
+		}
+		return autorest.NewBearerAuthorizer(token), nil
+	case "azure_arc":
+		return autorest.NewBearerAuthorizer(newArcToken(a.client, defaultAuthResource)), nil
+	default:
+		return auth.NewAuthorizerFromEnvironmentWithResource(defaultAuthResource)
+	}
+}
+
+// arcMetadataEndpoint returns the address of the metadata service of the Azure
+// Arc Connected Machine agent.
+func arcMetadataEndpoint() string {
+	if endpoint := os.Getenv("IMDS_ENDPOINT"); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/")
+	}
+	return defaultArcMetadataEndpoint
+}
+
+// vmMetadata retrieves metadata about the current Azure VM or Azure Arc
+// enabled server
+func vmInstanceMetadata(c *http.Client, metadataURL string) (string, string, error) {
+	req, err := http.NewRequest("GET", metadataURL, nil)
 	if err != nil {
 		return "", "", fmt.Errorf("error creating request: %v", err)
 	}
@@ -227,7 +305,7 @@ func vmInstanceMetadata(c *http.Client) (string, string, error) {
 	}
 	if resp.StatusCode >= 300 || resp.StatusCode < 200 {
 		return "", "", fmt.Errorf("unable to fetch instance metadata: [%s] %d",
-			vmInstanceMetadataURL, resp.StatusCode)
+			metadataURL, resp.StatusCode)
 	}
 
 	var metadata virtualMachineMetadata
@@ -235,6 +235,56 @@ func TestAggregate(t *testing.T) {
 	}
 }
 
+func TestInitAuthMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		plugin *AzureMonitor
+		err    string
+	}{
+		{
+			name:   "default",
+			plugin: &AzureMonitor{},
+		},
+		{
+			name:   "user-assigned managed identity",
+			plugin: &AzureMonitor{AuthMethod: "managed_identity", ManagedIdentityClientID: "00000000-0000-0000-0000-000000000000"},
+		},
+		{
+			name:   "azure arc",
+			plugin: &AzureMonitor{AuthMethod: "azure_arc"},
+		},
+		{
+			name:   "unknown",
+			plugin: &AzureMonitor{AuthMethod: "password"},
+			err:    `unknown auth_method "password"`,
+		},
+		{
+			name:   "user-assigned identity on azure arc",
+			plugin: &AzureMonitor{AuthMethod: "azure_arc", ManagedIdentityClientID: "00000000-0000-0000-0000-000000000000"},
+			err:    "azure_arc only supports the system-assigned managed identity",
+		},
+		{
+			name: "client and resource id",
+			plugin: &AzureMonitor{
+				AuthMethod:                "managed_identity",
+				ManagedIdentityClientID:   "00000000-0000-0000-0000-000000000000",
+				ManagedIdentityResourceID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/telegraf",
+			},
+			err: "only one of managed_identity_client_id and managed_identity_resource_id can be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.plugin.Init()
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
 func TestWrite(t *testing.T) {
 	readBody := func(r *http.Request) ([]*azureMonitorMetric, error) {
 		gz, err := gzip.NewReader(r.Body)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 
@@ -236,7 +237,7 @@ func (d *Dynatrace) Init() error {
 
 	d.client = &http.Client{
 		Transport: &http.Transport{
-			Proxy:           http.ProxyFromEnvironment,
+			Proxy:           internal.Proxy,
 			TLSClientConfig: tlsCfg,
 		},
 		Timeout: time.Duration(d.Timeout),
@@ -70,7 +70,7 @@ func (f *File) Connect() error {
 			writers = append(writers, os.Stdout)
 		} else {
 			of, err := rotate.NewFileWriter(
-				file, time.Duration(f.RotationInterval), int64(f.RotationMaxSize), f.RotationMaxArchives)
+				file, time.Duration(f.RotationInterval), int64(f.RotationMaxSize), f.RotationMaxArchives, 0)
 			if err != nil {
 				return err
 			}
@@ -99,7 +99,7 @@ func NewHTTPClient(config *HTTPConfig) (*httpClient, error) {
 	if config.Proxy != nil {
 		proxy = http.ProxyURL(config.Proxy)
 	} else {
-		proxy = http.ProxyFromEnvironment
+		proxy = internal.Proxy
 	}
 
 	serializer := config.Serializer
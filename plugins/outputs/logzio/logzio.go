@@ -11,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -74,7 +75,7 @@ func (l *Logzio) Connect() error {
 
 	l.client = &http.Client{
 		Transport: &http.Transport{
-			Proxy:           http.ProxyFromEnvironment,
+			Proxy:           internal.Proxy,
 			TLSClientConfig: tlsCfg,
 		},
 		Timeout: time.Duration(l.Timeout),
@@ -86,7 +86,7 @@ func (l *Loki) createClient(ctx context.Context) (*http.Client, error) {
 	client := &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: tlsCfg,
-			Proxy:           http.ProxyFromEnvironment,
+			Proxy:           internal.Proxy,
 		},
 		Timeout: time.Duration(l.Timeout),
 	}
@@ -141,7 +141,7 @@ func (s *SumoLogic) SetSerializer(serializer serializers.Serializer) {
 func (s *SumoLogic) createClient() *http.Client {
 	return &http.Client{
 		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
+			Proxy: internal.Proxy,
 		},
 		Timeout: time.Duration(s.Timeout),
 	}
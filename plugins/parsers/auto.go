@@ -0,0 +1,144 @@
+package parsers
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// DefaultAutoFormats are the data formats probed by the auto parser if no
+// candidates are configured.
+var DefaultAutoFormats = []string{"influx", "json", "prometheus", "graphite"}
+
+// AutoParser detects the data format of the payload by probing an ordered
+// list of candidate parsers. The detected format is cached and tried first
+// for subsequent payloads; if it fails the candidates are probed again, so
+// inputs receiving traffic in different formats keep working.
+type AutoParser struct {
+	Formats []string
+
+	parsers  []Parser
+	detected int
+	mu       sync.Mutex
+}
+
+func newAutoParser(config *Config) (*AutoParser, error) {
+	formats := config.AutoFormats
+	if len(formats) == 0 {
+		formats = DefaultAutoFormats
+	}
+
+	p := &AutoParser{
+		Formats:  formats,
+		parsers:  make([]Parser, 0, len(formats)),
+		detected: -1,
+	}
+	for _, format := range formats {
+		if format == "auto" {
+			return nil, fmt.Errorf("auto_formats must not contain %q", format)
+		}
+		c := *config
+		c.DataFormat = format
+		parser, err := newParser(&c)
+		if err != nil {
+			return nil, fmt.Errorf("creating parser for %q failed: %w", format, err)
+		}
+		p.parsers = append(p.parsers, parser)
+	}
+	return p, nil
+}
+
+// DetectedFormat returns the data format of the last successfully parsed
+// payload or an empty string if no payload was parsed yet.
+func (p *AutoParser) DetectedFormat() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.detected < 0 {
+		return ""
+	}
+	return p.Formats[p.detected]
+}
+
+func (p *AutoParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var metrics []telegraf.Metric
+	matched, err := p.probe(func(parser Parser) (bool, error) {
+		var err error
+		metrics, err = parser.Parse(buf)
+		return len(metrics) > 0, err
+	})
+	if !matched {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+func (p *AutoParser) ParseLine(line string) (telegraf.Metric, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var m telegraf.Metric
+	matched, err := p.probe(func(parser Parser) (bool, error) {
+		var err error
+		m, err = parser.ParseLine(line)
+		return m != nil, err
+	})
+	if !matched {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (p *AutoParser) SetDefaultTags(tags map[string]string) {
+	for _, parser := range p.parsers {
+		parser.SetDefaultTags(tags)
+	}
+}
+
+// probe calls parse with the cached parser first and with the remaining
+// candidates in order until one produces metrics without an error.
+// Payloads parsed without error but without metrics do not change the
+// detection and are not reported as an error.
+func (p *AutoParser) probe(parse func(Parser) (bool, error)) (bool, error) {
+	var empty bool
+	if p.detected >= 0 {
+		ok, err := parse(p.parsers[p.detected])
+		if err == nil {
+			if ok {
+				return true, nil
+			}
+			empty = true
+		}
+	}
+
+	var firstErr error
+	for i, parser := range p.parsers {
+		if i == p.detected {
+			continue
+		}
+		ok, err := parse(parser)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !ok {
+			empty = true
+			continue
+		}
+		log.Printf("D! [parsers.auto] Detected data format %q", p.Formats[i])
+		p.detected = i
+		return true, nil
+	}
+
+	if empty || firstErr == nil {
+		return false, nil
+	}
+	return false, fmt.Errorf("no data format out of %v matched: %w", p.Formats, firstErr)
+}
@@ -0,0 +1,97 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		format   string
+		expected string
+	}{
+		{
+			name:     "influx",
+			input:    "cpu,host=a usage=1 1600000000000000000\n",
+			format:   "influx",
+			expected: "cpu",
+		},
+		{
+			name:     "json",
+			input:    `{"usage": 1}`,
+			format:   "json",
+			expected: "auto_test",
+		},
+		{
+			name:     "prometheus",
+			input:    "# TYPE cpu_usage gauge\ncpu_usage{host=\"a\"} 1\n",
+			format:   "prometheus",
+			expected: "prometheus",
+		},
+		{
+			name:     "graphite",
+			input:    "cpu.usage 1 1600000000\n",
+			format:   "graphite",
+			expected: "cpu.usage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(&Config{
+				DataFormat: "auto",
+				MetricName: "auto_test",
+			})
+			require.NoError(t, err)
+
+			metrics, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			require.NotEmpty(t, metrics)
+			require.Equal(t, tt.expected, metrics[0].Name())
+			require.Equal(t, tt.format, parser.(*AutoParser).DetectedFormat())
+		})
+	}
+}
+
+func TestAutoParserMixedTraffic(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat: "auto",
+		MetricName: "auto_test",
+	})
+	require.NoError(t, err)
+	auto := parser.(*AutoParser)
+	require.Empty(t, auto.DetectedFormat())
+
+	_, err = parser.Parse([]byte(`{"usage": 1}`))
+	require.NoError(t, err)
+	require.Equal(t, "json", auto.DetectedFormat())
+
+	_, err = parser.Parse([]byte(`{"usage": 2}`))
+	require.NoError(t, err)
+	require.Equal(t, "json", auto.DetectedFormat())
+
+	m, err := parser.ParseLine("cpu usage=1")
+	require.NoError(t, err)
+	require.Equal(t, "cpu", m.Name())
+	require.Equal(t, "influx", auto.DetectedFormat())
+}
+
+func TestAutoParserFormats(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:  "auto",
+		AutoFormats: []string{"json"},
+	})
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte("cpu usage=1"))
+	require.Error(t, err)
+
+	_, err = NewParser(&Config{
+		DataFormat:  "auto",
+		AutoFormats: []string{"json", "auto"},
+	})
+	require.Error(t, err)
+}
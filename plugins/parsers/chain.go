@@ -0,0 +1,150 @@
+package parsers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// ChainParser passes the content of a field parsed by the first parser
+// through a second parser. This allows for example to extract a JSON payload
+// from a log line using grok and to expand it using the JSON parser.
+type ChainParser struct {
+	// Parser is the first parser applied to the data.
+	Parser Parser
+	// Next is the parser applied to the content of SourceField.
+	Next Parser
+	// SourceField is the string field of the metrics produced by Parser
+	// which holds the data for the Next parser.
+	SourceField string
+}
+
+// NewChainParser returns a parser passing the SourceField of the metrics
+// parsed by first through next.
+func NewChainParser(first, next Parser, sourceField string) (*ChainParser, error) {
+	if sourceField == "" {
+		return nil, fmt.Errorf("next_parser requires a source_field")
+	}
+	return &ChainParser{
+		Parser:      first,
+		Next:        next,
+		SourceField: sourceField,
+	}, nil
+}
+
+func (p *ChainParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := p.Parser.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]telegraf.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		expanded, err := p.expand(m)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expanded...)
+	}
+	return result, nil
+}
+
+func (p *ChainParser) ParseLine(line string) (telegraf.Metric, error) {
+	m, err := p.Parser.ParseLine(line)
+	if err != nil || m == nil {
+		return m, err
+	}
+
+	expanded, err := p.expand(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(expanded) != 1 {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: chain", line)
+	}
+	return expanded[0], nil
+}
+
+func (p *ChainParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	metrics, err := ParseLines(p.Parser, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]telegraf.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		expanded, err := p.expand(m)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expanded...)
+	}
+	return result, nil
+}
+
+func (p *ChainParser) SetDefaultTags(tags map[string]string) {
+	p.Parser.SetDefaultTags(tags)
+}
+
+func (p *ChainParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *ChainParser) IsMultiline() bool {
+	return isMultiline(p.Parser)
+}
+
+func (p *ChainParser) IsNewLogLine(line string) bool {
+	return isNewLogLine(p.Parser, line)
+}
+
+func (p *ChainParser) MultilineFlushTimeout() time.Duration {
+	return multilineFlushTimeout(p.Parser)
+}
+
+func (p *ChainParser) MultilineLineLimit() int {
+	return multilineLineLimit(p.Parser)
+}
+
+func (p *ChainParser) process(m telegraf.Metric) ([]telegraf.Metric, error) {
+	return p.expand(m)
+}
+
+// expand parses the source field of the given metric using the next parser.
+// The resulting metrics inherit the name, timestamp, tags and remaining fields
+// of the original metric. Metrics without the source field are passed
+// through unchanged.
+func (p *ChainParser) expand(m telegraf.Metric) ([]telegraf.Metric, error) {
+	v, ok := m.GetField(p.SourceField)
+	if !ok {
+		return []telegraf.Metric{m}, nil
+	}
+	payload, ok := v.(string)
+	if !ok {
+		return []telegraf.Metric{m}, nil
+	}
+
+	inner, err := p.Next.Parse([]byte(payload))
+	if err != nil {
+		return nil, fmt.Errorf("parsing field %q failed: %w", p.SourceField, err)
+	}
+
+	m.RemoveField(p.SourceField)
+	if len(inner) == 0 {
+		return []telegraf.Metric{m}, nil
+	}
+
+	result := make([]telegraf.Metric, 0, len(inner))
+	for _, im := range inner {
+		out := m.Copy()
+		for _, tag := range im.TagList() {
+			out.AddTag(tag.Key, tag.Value)
+		}
+		for _, field := range im.FieldList() {
+			out.AddField(field.Key, field.Value)
+		}
+		result = append(result, out)
+	}
+	return result, nil
+}
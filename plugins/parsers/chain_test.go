@@ -0,0 +1,96 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestChainParser(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:   "grok",
+		MetricName:   "app",
+		GrokPatterns: []string{`%{NUMBER:timestamp:ts-epoch} %{WORD:level:tag} %{GREEDYDATA:json_payload}`},
+		NextParser: &Config{
+			DataFormat:  "json",
+			MetricName:  "ignored",
+			TagKeys:     []string{"host"},
+			SourceField: "json_payload",
+		},
+	})
+	require.NoError(t, err)
+
+	actual, err := parser.Parse([]byte(`1600000000 INFO [{"host": "a", "value": 1}, {"host": "b", "value": 2}]` + "\n"))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"app",
+			map[string]string{"level": "INFO", "host": "a"},
+			map[string]interface{}{"value": float64(1)},
+			time.Unix(1600000000, 0),
+		),
+		testutil.MustMetric(
+			"app",
+			map[string]string{"level": "INFO", "host": "b"},
+			map[string]interface{}{"value": float64(2)},
+			time.Unix(1600000000, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestChainParserMissingSourceField(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:   "grok",
+		MetricName:   "app",
+		GrokPatterns: []string{`%{NUMBER:timestamp:ts-epoch} %{WORD:level:tag} %{NUMBER:value:int}`},
+		NextParser: &Config{
+			DataFormat:  "json",
+			SourceField: "json_payload",
+		},
+	})
+	require.NoError(t, err)
+
+	m, err := parser.ParseLine("1600000000 INFO 42")
+	require.NoError(t, err)
+	testutil.RequireMetricEqual(t,
+		testutil.MustMetric(
+			"app",
+			map[string]string{"level": "INFO"},
+			map[string]interface{}{"value": int64(42)},
+			time.Unix(1600000000, 0),
+		),
+		m,
+	)
+}
+
+func TestChainParserInvalidPayload(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:   "grok",
+		GrokPatterns: []string{`%{GREEDYDATA:json_payload}`},
+		NextParser: &Config{
+			DataFormat:  "json",
+			SourceField: "json_payload",
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte("not json\n"))
+	require.Error(t, err)
+}
+
+func TestChainParserRequiresSourceField(t *testing.T) {
+	_, err := NewParser(&Config{
+		DataFormat:   "grok",
+		GrokPatterns: []string{`%{GREEDYDATA:json_payload}`},
+		NextParser: &Config{
+			DataFormat: "json",
+		},
+	})
+	require.Error(t, err)
+}
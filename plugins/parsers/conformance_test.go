@@ -0,0 +1,82 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParserConformance(t *testing.T) {
+	tests := []struct {
+		config      Config
+		conformance testutil.ParserConformance
+	}{
+		{
+			config: Config{DataFormat: "influx"},
+			conformance: testutil.ParserConformance{
+				Valid:   []byte("cpu,host=a usage=42 1600000000000000000\nmem free=1i\n"),
+				Invalid: []byte("cpu usage=\n"),
+				Line:    "cpu,host=a usage=42",
+			},
+		},
+		{
+			config: Config{DataFormat: "graphite"},
+			conformance: testutil.ParserConformance{
+				Valid:   []byte("cpu.usage 42 1600000000\n"),
+				Invalid: []byte("cpu.usage\n"),
+				Line:    "cpu.usage 42",
+			},
+		},
+		{
+			config: Config{DataFormat: "json", MetricName: "json", TagKeys: []string{"host"}},
+			conformance: testutil.ParserConformance{
+				Valid:   []byte(`[{"host": "a", "usage": 42}, {"host": "b", "usage": 21}]`),
+				Invalid: []byte(`{"host": `),
+				Line:    `{"host": "a", "usage": 42}`,
+			},
+		},
+		{
+			config: Config{DataFormat: "logfmt", MetricName: "logfmt"},
+			conformance: testutil.ParserConformance{
+				Valid:   []byte("usage=42 state=ok\n"),
+				Invalid: []byte("usage=\"42\n"),
+				Line:    "usage=42",
+			},
+		},
+		{
+			config: Config{DataFormat: "wavefront"},
+			conformance: testutil.ParserConformance{
+				Valid:   []byte("cpu.usage 42 1600000000 source=a\n"),
+				Invalid: []byte("cpu.usage\n"),
+				Line:    "cpu.usage 42 source=a",
+				// Default tags take precedence over the point tags.
+				OverwritesTags: true,
+			},
+		},
+		{
+			config: Config{DataFormat: "value", MetricName: "value", DataType: "integer"},
+			conformance: testutil.ParserConformance{
+				Valid:   []byte("42\n"),
+				Invalid: []byte("forty-two\n"),
+				Line:    "42",
+			},
+		},
+		{
+			config: Config{DataFormat: "prometheus"},
+			conformance: testutil.ParserConformance{
+				Valid:   []byte("# TYPE cpu_usage gauge\ncpu_usage{host=\"a\"} 42\n"),
+				Invalid: []byte("cpu_usage{host=\"a\" 42\n"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		config := tt.config
+		tt.conformance.New = func() (testutil.Parser, error) {
+			return NewParser(&config)
+		}
+		t.Run(config.DataFormat, func(t *testing.T) {
+			testutil.RunParserConformance(t, tt.conformance)
+		})
+	}
+}
@@ -33,6 +33,13 @@ type Config struct {
 	TrimSpace         bool     `toml:"csv_trim_space"`
 	SkipValues        []string `toml:"csv_skip_values"`
 
+	// TimestampFormats are tried in order if the timestamp does not match
+	// TimestampFormat.
+	TimestampFormats []string
+	// TimestampFallbackToNow uses the current time if the timestamp is
+	// missing or does not match any of the formats.
+	TimestampFallbackToNow bool
+
 	gotColumnNames bool
 
 	TimeFunc    func() time.Time
@@ -42,6 +49,8 @@ type Config struct {
 // Parser is a CSV parser, you should use NewParser to create a new instance.
 type Parser struct {
 	*Config
+
+	timeParser *internal.TimestampParser
 }
 
 func NewParser(c *Config) (*Parser, error) {
@@ -73,7 +82,11 @@ func NewParser(c *Config) (*Parser, error) {
 		c.TimeFunc = time.Now
 	}
 
-	return &Parser{Config: c}, nil
+	formats := append([]string{c.TimestampFormat}, c.TimestampFormats...)
+	timeParser := internal.NewTimestampParser(formats, c.Timezone, c.TimestampFallbackToNow)
+	timeParser.Now = func() time.Time { return c.TimeFunc() }
+
+	return &Parser{Config: c, timeParser: timeParser}, nil
 }
 
 func (p *Parser) SetTimeFunc(fn TimeFunc) {
@@ -271,7 +284,7 @@ outer:
 		}
 	}
 
-	metricTime, err := parseTimestamp(p.TimeFunc, recordFields, p.TimestampColumn, p.TimestampFormat, p.Timezone)
+	metricTime, err := p.parseTimestamp(recordFields)
 	if err != nil {
 		return nil, err
 	}
@@ -285,30 +298,22 @@ outer:
 	return m, nil
 }
 
-// ParseTimestamp return a timestamp, if there is no timestamp on the csv it
+// parseTimestamp return a timestamp, if there is no timestamp on the csv it
 // will be the current timestamp, else it will try to parse the time according
-// to the format.
-func parseTimestamp(timeFunc func() time.Time, recordFields map[string]interface{},
-	timestampColumn, timestampFormat string, Timezone string,
-) (time.Time, error) {
-	if timestampColumn != "" {
-		if recordFields[timestampColumn] == nil {
-			return time.Time{}, fmt.Errorf("timestamp column: %v could not be found", timestampColumn)
-		}
+// to the formats.
+func (p *Parser) parseTimestamp(recordFields map[string]interface{}) (time.Time, error) {
+	if p.TimestampColumn == "" {
+		return p.TimeFunc(), nil
+	}
 
-		switch timestampFormat {
-		case "":
-			return time.Time{}, fmt.Errorf("timestamp format must be specified")
-		default:
-			metricTime, err := internal.ParseTimestamp(timestampFormat, recordFields[timestampColumn], Timezone)
-			if err != nil {
-				return time.Time{}, err
-			}
-			return metricTime, err
-		}
+	if len(p.timeParser.Formats) == 0 {
+		return time.Time{}, fmt.Errorf("timestamp format must be specified")
 	}
 
-	return timeFunc(), nil
+	if recordFields[p.TimestampColumn] == nil {
+		return p.timeParser.Missing(fmt.Errorf("timestamp column: %v could not be found", p.TimestampColumn))
+	}
+	return p.timeParser.Parse(recordFields[p.TimestampColumn])
 }
 
 // SetDefaultTags set the DefaultTags
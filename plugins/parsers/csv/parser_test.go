@@ -664,3 +664,28 @@ func TestSkipSpecifiedStringValue(t *testing.T) {
 	}
 	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime())
 }
+
+func TestTimestampFormatsFallback(t *testing.T) {
+	p, err := NewParser(
+		&Config{
+			HeaderRowCount:         1,
+			ColumnNames:            []string{"first", "second"},
+			TimestampColumn:        "first",
+			TimestampFormat:        "02/01/06 03:04:05 PM",
+			TimestampFormats:       []string{"unix"},
+			TimestampFallbackToNow: true,
+			TimeFunc:               DefaultTime,
+		},
+	)
+	require.NoError(t, err)
+	testCSV := `line1,line2
+23/05/09 04:05:06 PM,70
+1243094706,80
+invalid,90`
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	require.Equal(t, int64(1243094706000000000), metrics[0].Time().UnixNano())
+	require.Equal(t, int64(1243094706000000000), metrics[1].Time().UnixNano())
+	require.Equal(t, DefaultTime(), metrics[2].Time())
+}
@@ -0,0 +1,171 @@
+package parsers
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const defaultDeadLetterMaxLength = 1024
+
+// DeadLetterParser wraps a parser and counts its parse failures. The failing
+// payload can optionally be emitted as a dead-letter metric or appended to a
+// file so malformed data becomes visible instead of being silently dropped.
+type DeadLetterParser struct {
+	Parser Parser
+
+	// Measurement is the name of the dead-letter metric emitted instead of
+	// returning the parse error. Disabled if empty.
+	Measurement string
+	// File is the path of a file where failing payloads are appended to.
+	// Disabled if empty.
+	File string
+	// MaxLength is the maximum length of the payload stored in the
+	// dead-letter metric or file.
+	MaxLength int
+
+	dataFormat string
+	errors     selfstat.Stat
+	mu         sync.Mutex
+}
+
+// NewDeadLetterParser wraps the parser created for the given config.
+func NewDeadLetterParser(parser Parser, config *Config) *DeadLetterParser {
+	maxLength := config.ParseErrorsMaxLength
+	if maxLength <= 0 {
+		maxLength = defaultDeadLetterMaxLength
+	}
+
+	tags := map[string]string{
+		"input":       config.MetricName,
+		"data_format": config.DataFormat,
+	}
+	return &DeadLetterParser{
+		Parser:      parser,
+		Measurement: config.ParseErrorsMeasurement,
+		File:        config.ParseErrorsFile,
+		MaxLength:   maxLength,
+		dataFormat:  config.DataFormat,
+		errors:      selfstat.Register("parser", "errors", tags),
+	}
+}
+
+func (p *DeadLetterParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := p.Parser.Parse(buf)
+	if err == nil {
+		return metrics, nil
+	}
+
+	if m := p.handleError(string(buf), err); m != nil {
+		return []telegraf.Metric{m}, nil
+	}
+	return nil, err
+}
+
+func (p *DeadLetterParser) ParseLine(line string) (telegraf.Metric, error) {
+	m, err := p.Parser.ParseLine(line)
+	if err == nil {
+		return m, nil
+	}
+
+	if m := p.handleError(line, err); m != nil {
+		return m, nil
+	}
+	return nil, err
+}
+
+func (p *DeadLetterParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	metrics, err := ParseLines(p.Parser, lines)
+	if err == nil {
+		return metrics, nil
+	}
+
+	// Parse the lines one by one so only the failing lines are handled.
+	metrics = make([]telegraf.Metric, 0, len(lines))
+	for _, line := range lines {
+		m, err := p.ParseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+func (p *DeadLetterParser) SetDefaultTags(tags map[string]string) {
+	p.Parser.SetDefaultTags(tags)
+}
+
+func (p *DeadLetterParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *DeadLetterParser) IsMultiline() bool {
+	return isMultiline(p.Parser)
+}
+
+func (p *DeadLetterParser) IsNewLogLine(line string) bool {
+	return isNewLogLine(p.Parser, line)
+}
+
+func (p *DeadLetterParser) MultilineFlushTimeout() time.Duration {
+	return multilineFlushTimeout(p.Parser)
+}
+
+func (p *DeadLetterParser) MultilineLineLimit() int {
+	return multilineLineLimit(p.Parser)
+}
+
+// handleError counts the error and stores the payload at the configured
+// destinations. If a dead-letter measurement is configured the corresponding
+// metric is returned.
+func (p *DeadLetterParser) handleError(payload string, parseErr error) telegraf.Metric {
+	p.errors.Incr(1)
+
+	if len(payload) > p.MaxLength {
+		payload = payload[:p.MaxLength]
+	}
+	now := time.Now()
+
+	if p.File != "" {
+		if err := p.writeFile(now, payload, parseErr); err != nil {
+			log.Printf("E! [parsers.%s] Writing dead-letter file failed: %v", p.dataFormat, err)
+		}
+	}
+
+	if p.Measurement == "" {
+		return nil
+	}
+
+	tags := map[string]string{"data_format": p.dataFormat}
+	fields := map[string]interface{}{
+		"payload": payload,
+		"error":   parseErr.Error(),
+	}
+	return metric.New(p.Measurement, tags, fields, now)
+}
+
+func (p *DeadLetterParser) writeFile(ts time.Time, payload string, parseErr error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := os.OpenFile(p.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s %s %s %s\n",
+		ts.UTC().Format(time.RFC3339), p.dataFormat,
+		strconv.Quote(parseErr.Error()), strconv.Quote(payload))
+	return err
+}
@@ -0,0 +1,79 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterParserCountsErrors(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:       "influx",
+		MetricName:       "dead_letter_count",
+		ParseErrorsCount: true,
+	})
+	require.NoError(t, err)
+	dl, ok := parser.(*DeadLetterParser)
+	require.True(t, ok)
+	before := dl.errors.Get()
+
+	_, err = parser.Parse([]byte("cpu value=1\n"))
+	require.NoError(t, err)
+	_, err = parser.Parse([]byte("cpu value=\n"))
+	require.Error(t, err)
+	require.Equal(t, before+1, dl.errors.Get())
+}
+
+func TestDeadLetterParserMeasurement(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:             "influx",
+		MetricName:             "dead_letter_measurement",
+		ParseErrorsMeasurement: "parse_error",
+		ParseErrorsMaxLength:   8,
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte("cpu value=\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, "parse_error", metrics[0].Name())
+	require.Equal(t, map[string]string{"data_format": "influx"}, metrics[0].Tags())
+
+	payload, ok := metrics[0].GetField("payload")
+	require.True(t, ok)
+	require.Equal(t, "cpu valu", payload)
+	_, ok = metrics[0].GetField("error")
+	require.True(t, ok)
+}
+
+func TestDeadLetterParserFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dead_letter.log")
+	parser, err := NewParser(&Config{
+		DataFormat:      "influx",
+		MetricName:      "dead_letter_file",
+		ParseErrorsFile: filename,
+	})
+	require.NoError(t, err)
+
+	_, err = parser.ParseLine("cpu value=")
+	require.Error(t, err)
+	_, err = parser.ParseLine("mem value=")
+	require.Error(t, err)
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"cpu value="`)
+	require.Contains(t, lines[1], `"mem value="`)
+}
+
+func TestNewParserWithoutErrorHandling(t *testing.T) {
+	parser, err := NewParser(&Config{DataFormat: "influx"})
+	require.NoError(t, err)
+	_, ok := parser.(*DeadLetterParser)
+	require.False(t, ok)
+}
@@ -0,0 +1,95 @@
+package parsers
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/encoding"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// DecodingParser transcodes the payload from the configured character
+// encoding to UTF-8 before passing it to the wrapped parser.
+type DecodingParser struct {
+	Parser   Parser
+	Encoding string
+}
+
+// NewDecodingParser wraps the parser with a decoder for the given character
+// encoding.
+func NewDecodingParser(parser Parser, enc string) (*DecodingParser, error) {
+	// Check the encoding to fail early on unknown values
+	if _, err := encoding.NewDecoder(enc); err != nil {
+		return nil, fmt.Errorf("invalid character_encoding %q: %w", enc, err)
+	}
+	return &DecodingParser{Parser: parser, Encoding: enc}, nil
+}
+
+func (p *DecodingParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	decoded, err := p.decode(buf)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parser.Parse(decoded)
+}
+
+func (p *DecodingParser) ParseLine(line string) (telegraf.Metric, error) {
+	decoded, err := p.decode([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	return p.Parser.ParseLine(string(decoded))
+}
+
+func (p *DecodingParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	decoded := make([]string, 0, len(lines))
+	for _, line := range lines {
+		d, err := p.decode([]byte(line))
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, string(d))
+	}
+	return ParseLines(p.Parser, decoded)
+}
+
+func (p *DecodingParser) SetDefaultTags(tags map[string]string) {
+	p.Parser.SetDefaultTags(tags)
+}
+
+func (p *DecodingParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *DecodingParser) IsMultiline() bool {
+	return isMultiline(p.Parser)
+}
+
+func (p *DecodingParser) IsNewLogLine(line string) bool {
+	return isNewLogLine(p.Parser, line)
+}
+
+func (p *DecodingParser) MultilineFlushTimeout() time.Duration {
+	return multilineFlushTimeout(p.Parser)
+}
+
+func (p *DecodingParser) MultilineLineLimit() int {
+	return multilineLineLimit(p.Parser)
+}
+
+func (p *DecodingParser) decode(buf []byte) ([]byte, error) {
+	// Decoders keep state, so use a new one for every payload to stay
+	// thread-safe.
+	decoder, err := encoding.NewDecoder(p.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decoder.Bytes(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s failed: %w", p.Encoding, err)
+	}
+	return bytes.TrimPrefix(decoded, utf8BOM), nil
+}
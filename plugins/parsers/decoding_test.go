@@ -0,0 +1,74 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodingParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		input    []byte
+	}{
+		{
+			name:     "utf-16le with BOM",
+			encoding: "utf-16",
+			input:    []byte("\xff\xfec\x00p\x00u\x00 \x00v\x00=\x001\x00"),
+		},
+		{
+			name:     "utf-16be with BOM",
+			encoding: "utf-16",
+			input:    []byte("\xfe\xff\x00c\x00p\x00u\x00 \x00v\x00=\x001"),
+		},
+		{
+			name:     "utf-16le",
+			encoding: "utf-16le",
+			input:    []byte("c\x00p\x00u\x00 \x00v\x00=\x001\x00"),
+		},
+		{
+			name:     "utf-8 with BOM",
+			encoding: "utf-8",
+			input:    []byte("\xef\xbb\xbfcpu v=1"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(&Config{
+				DataFormat:        "influx",
+				CharacterEncoding: tt.encoding,
+			})
+			require.NoError(t, err)
+
+			metrics, err := parser.Parse(tt.input)
+			require.NoError(t, err)
+			require.Len(t, metrics, 1)
+			require.Equal(t, "cpu", metrics[0].Name())
+			require.Equal(t, map[string]interface{}{"v": float64(1)}, metrics[0].Fields())
+		})
+	}
+}
+
+func TestDecodingParserParseLine(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:        "value",
+		DataType:          "string",
+		MetricName:        "value",
+		CharacterEncoding: "windows-1252",
+	})
+	require.NoError(t, err)
+
+	m, err := parser.ParseLine("caf\xe9")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": "café"}, m.Fields())
+}
+
+func TestDecodingParserUnknownEncoding(t *testing.T) {
+	_, err := NewParser(&Config{
+		DataFormat:        "influx",
+		CharacterEncoding: "ebcdic",
+	})
+	require.Error(t, err)
+}
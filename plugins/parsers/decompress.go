@@ -0,0 +1,115 @@
+package parsers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// defaultMaxDecompressedSize is the default limit of the size of a
+// decompressed payload.
+const defaultMaxDecompressedSize = 500 * 1024 * 1024
+
+// DecompressingParser removes the content encoding of the payload, like gzip
+// compression, before passing it to the wrapped parser.
+type DecompressingParser struct {
+	Parser   Parser
+	Encoding string
+
+	decoder internal.ContentDecoder
+	mu      sync.Mutex
+}
+
+// NewDecompressingParser wraps the parser with a decoder for the given
+// content encoding. Payloads decompressing to more than maxSize bytes are
+// rejected, a size of zero uses the default of 500MB.
+func NewDecompressingParser(parser Parser, encoding string, maxSize int64) (*DecompressingParser, error) {
+	if maxSize == 0 {
+		maxSize = defaultMaxDecompressedSize
+	}
+	decoder, err := internal.NewContentDecoder(encoding, internal.WithMaxDecompressedSize(maxSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid content_encoding %q: %w", encoding, err)
+	}
+	return &DecompressingParser{
+		Parser:   parser,
+		Encoding: encoding,
+		decoder:  decoder,
+	}, nil
+}
+
+func (p *DecompressingParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	// The decoders reuse their output buffer, so hold the lock until the
+	// wrapped parser is done with the data.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	decoded, err := p.decoder.Decode(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s failed: %w", p.Encoding, err)
+	}
+	return p.Parser.Parse(decoded)
+}
+
+func (p *DecompressingParser) ParseLine(line string) (telegraf.Metric, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	decoded, err := p.decoder.Decode([]byte(line))
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s failed: %w", p.Encoding, err)
+	}
+	return p.Parser.ParseLine(string(decoded))
+}
+
+func (p *DecompressingParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	decoded, err := p.decodeLines(lines)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLines(p.Parser, decoded)
+}
+
+// decodeLines decodes the lines, copying them out of the reused buffer of
+// the decoder.
+func (p *DecompressingParser) decodeLines(lines []string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	decoded := make([]string, 0, len(lines))
+	for _, line := range lines {
+		d, err := p.decoder.Decode([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s failed: %w", p.Encoding, err)
+		}
+		decoded = append(decoded, string(d))
+	}
+	return decoded, nil
+}
+
+func (p *DecompressingParser) SetDefaultTags(tags map[string]string) {
+	p.Parser.SetDefaultTags(tags)
+}
+
+func (p *DecompressingParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *DecompressingParser) IsMultiline() bool {
+	return isMultiline(p.Parser)
+}
+
+func (p *DecompressingParser) IsNewLogLine(line string) bool {
+	return isNewLogLine(p.Parser, line)
+}
+
+func (p *DecompressingParser) MultilineFlushTimeout() time.Duration {
+	return multilineFlushTimeout(p.Parser)
+}
+
+func (p *DecompressingParser) MultilineLineLimit() int {
+	return multilineLineLimit(p.Parser)
+}
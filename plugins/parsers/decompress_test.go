@@ -0,0 +1,116 @@
+package parsers
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	switch encoding {
+	case "zstd":
+		encoder, err := zstd.NewWriter(nil)
+		require.NoError(t, err)
+		defer encoder.Close()
+		return encoder.EncodeAll(data, nil)
+	case "snappy":
+		return snappy.Encode(nil, data)
+	}
+	encoder, err := internal.NewContentEncoder(encoding)
+	require.NoError(t, err)
+	payload, err := encoder.Encode(data)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestDecompressingParser(t *testing.T) {
+	for _, encoding := range []string{"gzip", "zstd", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			payload := compress(t, encoding, []byte("cpu v=1\nmem v=2\n"))
+
+			parser, err := NewParser(&Config{
+				DataFormat:      "influx",
+				ContentEncoding: encoding,
+			})
+			require.NoError(t, err)
+
+			metrics, err := parser.Parse(payload)
+			require.NoError(t, err)
+			require.Len(t, metrics, 2)
+			require.Equal(t, "cpu", metrics[0].Name())
+			require.Equal(t, "mem", metrics[1].Name())
+		})
+	}
+}
+
+func TestDecompressingParserWithCharacterEncoding(t *testing.T) {
+	encoder, err := internal.NewContentEncoder("gzip")
+	require.NoError(t, err)
+	payload, err := encoder.Encode([]byte("c\x00p\x00u\x00 \x00v\x00=\x001\x00"))
+	require.NoError(t, err)
+
+	parser, err := NewParser(&Config{
+		DataFormat:        "influx",
+		ContentEncoding:   "gzip",
+		CharacterEncoding: "utf-16le",
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse(payload)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, "cpu", metrics[0].Name())
+}
+
+func TestDecompressingParserInvalidPayload(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:      "influx",
+		ContentEncoding: "gzip",
+	})
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte("cpu v=1"))
+	require.Error(t, err)
+}
+
+func TestDecompressingParserUnknownEncoding(t *testing.T) {
+	_, err := NewParser(&Config{
+		DataFormat:      "influx",
+		ContentEncoding: "lz4",
+	})
+	require.Error(t, err)
+}
+
+func TestDecompressingParserMaxSize(t *testing.T) {
+	data := bytes.Repeat([]byte("cpu v=1\n"), 1000)
+	for _, encoding := range []string{"gzip", "zstd", "snappy"} {
+		t.Run(encoding, func(t *testing.T) {
+			payload := compress(t, encoding, data)
+
+			parser, err := NewParser(&Config{
+				DataFormat:          "influx",
+				ContentEncoding:     encoding,
+				MaxDecompressedSize: int64(len(data)),
+			})
+			require.NoError(t, err)
+			metrics, err := parser.Parse(payload)
+			require.NoError(t, err)
+			require.Len(t, metrics, 1000)
+
+			parser, err = NewParser(&Config{
+				DataFormat:          "influx",
+				ContentEncoding:     encoding,
+				MaxDecompressedSize: int64(len(data)) - 1,
+			})
+			require.NoError(t, err)
+			_, err = parser.Parse(payload)
+			require.True(t, errors.Is(err, internal.ErrDecompressedSizeExceeded))
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package parsers
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// fieldType converts the fields matching the filter.
+type fieldType struct {
+	filter  filter.Filter
+	convert func(v interface{}) (interface{}, error)
+}
+
+// FieldTypesParser wraps a parser to convert the fields of the parsed metrics
+// to fixed types. This keeps the type of a field stable, e.g. if a value is
+// sometimes parsed as integer and sometimes as float. Fields failing to
+// convert are dropped.
+type FieldTypesParser struct {
+	Parser Parser
+
+	types []fieldType
+}
+
+// NewFieldTypesParser wraps the parser to apply the given map of field name
+// globs to types, which are one of "int", "uint", "float", "bool" or
+// "string". Exact names take precedence over globs, globs are tried in
+// alphabetical order.
+func NewFieldTypesParser(parser Parser, fieldTypes map[string]string) (*FieldTypesParser, error) {
+	patterns := make([]string, 0, len(fieldTypes))
+	for pattern := range fieldTypes {
+		patterns = append(patterns, pattern)
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		iGlob, jGlob := isGlob(patterns[i]), isGlob(patterns[j])
+		if iGlob != jGlob {
+			return !iGlob
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	types := make([]fieldType, 0, len(patterns))
+	for _, pattern := range patterns {
+		f, err := filter.Compile([]string{pattern})
+		if err != nil {
+			return nil, fmt.Errorf("invalid field_types pattern %q: %w", pattern, err)
+		}
+
+		var convert func(v interface{}) (interface{}, error)
+		switch fieldTypes[pattern] {
+		case "int", "integer":
+			convert = func(v interface{}) (interface{}, error) { return internal.ToInt64(v) }
+		case "uint", "unsigned":
+			convert = func(v interface{}) (interface{}, error) { return internal.ToUint64(v) }
+		case "float":
+			convert = func(v interface{}) (interface{}, error) { return internal.ToFloat64(v) }
+		case "bool", "boolean":
+			convert = func(v interface{}) (interface{}, error) { return internal.ToBool(v) }
+		case "string":
+			convert = func(v interface{}) (interface{}, error) { return internal.ToString(v) }
+		default:
+			return nil, fmt.Errorf("invalid field type %q for %q", fieldTypes[pattern], pattern)
+		}
+		types = append(types, fieldType{filter: f, convert: convert})
+	}
+
+	return &FieldTypesParser{Parser: parser, types: types}, nil
+}
+
+func (p *FieldTypesParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := p.Parser.Parse(buf)
+	for _, m := range metrics {
+		p.convert(m)
+	}
+	return metrics, err
+}
+
+func (p *FieldTypesParser) ParseLine(line string) (telegraf.Metric, error) {
+	m, err := p.Parser.ParseLine(line)
+	if m != nil {
+		p.convert(m)
+	}
+	return m, err
+}
+
+func (p *FieldTypesParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	metrics, err := ParseLines(p.Parser, lines)
+	for _, m := range metrics {
+		p.convert(m)
+	}
+	return metrics, err
+}
+
+func (p *FieldTypesParser) SetDefaultTags(tags map[string]string) {
+	p.Parser.SetDefaultTags(tags)
+}
+
+func (p *FieldTypesParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *FieldTypesParser) IsMultiline() bool {
+	return isMultiline(p.Parser)
+}
+
+func (p *FieldTypesParser) IsNewLogLine(line string) bool {
+	return isNewLogLine(p.Parser, line)
+}
+
+func (p *FieldTypesParser) MultilineFlushTimeout() time.Duration {
+	return multilineFlushTimeout(p.Parser)
+}
+
+func (p *FieldTypesParser) MultilineLineLimit() int {
+	return multilineLineLimit(p.Parser)
+}
+
+func (p *FieldTypesParser) process(m telegraf.Metric) ([]telegraf.Metric, error) {
+	p.convert(m)
+	return []telegraf.Metric{m}, nil
+}
+
+func (p *FieldTypesParser) convert(m telegraf.Metric) {
+	// Copy the fields as removing fields modifies the list.
+	fields := append([]*telegraf.Field(nil), m.FieldList()...)
+	for _, field := range fields {
+		for _, t := range p.types {
+			if !t.filter.Match(field.Key) {
+				continue
+			}
+
+			v, err := t.convert(field.Value)
+			if err != nil {
+				log.Printf("D! [parsers] Dropping field %q of %q, converting %v failed: %v", field.Key, m.Name(), field.Value, err)
+				m.RemoveField(field.Key)
+			} else {
+				m.AddField(field.Key, v)
+			}
+			break
+		}
+	}
+}
+
+func isGlob(pattern string) bool {
+	for _, c := range pattern {
+		switch c {
+		case '*', '?', '[', '{':
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestFieldTypesParser(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat: "influx",
+		FieldTypes: map[string]string{
+			"usage_*":  "float",
+			"usage_id": "string",
+			"count":    "int",
+			"up":       "bool",
+			"total":    "uint",
+		},
+	})
+	require.NoError(t, err)
+
+	input := "cpu usage_idle=42i,usage_id=3i,count=1.7,up=1i,total=5i,other=1i 1600000000000000000\n" +
+		"cpu usage_idle=42.5,usage_id=4i,count=\"x\",up=\"false\",total=6i,other=2.5 1600000001000000000\n"
+	actual, err := parser.Parse([]byte(input))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{},
+			map[string]interface{}{"usage_idle": 42.0, "usage_id": "3", "count": int64(1), "up": true, "total": uint64(5), "other": int64(1)},
+			time.Unix(1600000000, 0),
+		),
+		testutil.MustMetric("cpu",
+			map[string]string{},
+			map[string]interface{}{"usage_idle": 42.5, "usage_id": "4", "up": false, "total": uint64(6), "other": 2.5},
+			time.Unix(1600000001, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	m, err := parser.ParseLine("cpu usage_idle=1i")
+	require.NoError(t, err)
+	testutil.RequireMetricEqual(t,
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"usage_idle": 1.0}, time.Unix(0, 0)),
+		m,
+		testutil.IgnoreTime(),
+	)
+}
+
+func TestFieldTypesParserInvalid(t *testing.T) {
+	_, err := NewParser(&Config{
+		DataFormat: "influx",
+		FieldTypes: map[string]string{"value": "decimal"},
+	})
+	require.Error(t, err)
+
+	_, err = NewParser(&Config{
+		DataFormat: "influx",
+		FieldTypes: map[string]string{"value[": "int"},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package parsers
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+)
+
+// Creator creates a parser for a data format from the given config.
+type Creator func(config *Config) (Parser, error)
+
+// Format describes a data format available to NewParser.
+type Format struct {
+	// Description is a one-line summary of the data format.
+	Description string
+
+	// SampleConfig contains the parser options of the data format in the
+	// sample configuration style.
+	SampleConfig string
+
+	// Creator is used by NewParser for formats not built into Telegraf.
+	Creator Creator
+}
+
+// samples holds the sample configurations of the data formats, kept in the
+// sample.conf file of every parser.
+//
+//go:embed sample.conf sample_auto.conf */sample.conf
+var samples embed.FS
+
+// sampleConfig returns the sample configuration in the given file, starting
+// with an empty line like the plugin sample configurations.
+func sampleConfig(file string) string {
+	buf, err := samples.ReadFile(file)
+	if err != nil {
+		panic(err)
+	}
+	return "\n" + string(buf)
+}
+
+// Formats holds all registered data formats by name.
+var Formats = map[string]Format{}
+
+// Add registers a data format. It panics if the name is already taken.
+func Add(name string, format Format) {
+	if _, found := Formats[name]; found {
+		panic(fmt.Sprintf("data format %q registered twice", name))
+	}
+	Formats[name] = format
+}
+
+// FormatNames returns the names of all registered data formats in
+// alphabetical order.
+func FormatNames() []string {
+	names := make([]string, 0, len(Formats))
+	for name := range Formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Add("auto", Format{
+		Description:  "Detect the data format by probing candidate formats",
+		SampleConfig: sampleConfig("sample_auto.conf"),
+	})
+	Add("collectd", Format{
+		Description:  "Collectd binary network protocol",
+		SampleConfig: sampleConfig("collectd/sample.conf"),
+	})
+	Add("csv", Format{
+		Description:  "Comma separated values",
+		SampleConfig: sampleConfig("csv/sample.conf"),
+	})
+	Add("dropwizard", Format{
+		Description:  "Dropwizard JSON metric registry",
+		SampleConfig: sampleConfig("dropwizard/sample.conf"),
+	})
+	Add("form_urlencoded", Format{
+		Description:  "URL encoded form data",
+		SampleConfig: sampleConfig("form_urlencoded/sample.conf"),
+	})
+	Add("graphite", Format{
+		Description:  "Graphite plaintext protocol",
+		SampleConfig: sampleConfig("graphite/sample.conf"),
+	})
+	Add("grok", Format{
+		Description:  "Log lines matched against grok patterns",
+		SampleConfig: sampleConfig("grok/sample.conf"),
+	})
+	Add("html_table", Format{
+		Description:  "Rows of HTML tables",
+		SampleConfig: sampleConfig("html_table/sample.conf"),
+	})
+	Add("influx", Format{
+		Description:  "InfluxDB line protocol",
+		SampleConfig: sampleConfig("influx/sample.conf"),
+	})
+	Add("json", Format{
+		Description:  "JSON objects and arrays of objects",
+		SampleConfig: sampleConfig("json/sample.conf"),
+	})
+	Add("json_v2", Format{
+		Description:  "JSON parsed using GJSON path queries",
+		SampleConfig: sampleConfig("json_v2/sample.conf"),
+	})
+	Add("logfmt", Format{
+		Description:  "Logfmt key=value pairs",
+		SampleConfig: "",
+	})
+	Add("nagios", Format{
+		Description:  "Nagios plugin output",
+		SampleConfig: "",
+	})
+	Add("otlp", Format{
+		Description:  "OpenTelemetry OTLP metrics and logs",
+		SampleConfig: sampleConfig("otlp/sample.conf"),
+	})
+	Add("parquet", Format{
+		Description:  "Apache Parquet columnar files",
+		SampleConfig: sampleConfig("parquet/sample.conf"),
+	})
+	Add("prometheus", Format{
+		Description:  "Prometheus text exposition format",
+		SampleConfig: sampleConfig("prometheus/sample.conf"),
+	})
+	Add("prometheusremotewrite", Format{
+		Description:  "Prometheus remote write protocol buffers",
+		SampleConfig: "",
+	})
+	Add("value", Format{
+		Description:  "Single value per payload",
+		SampleConfig: sampleConfig("value/sample.conf"),
+	})
+	Add("wavefront", Format{
+		Description:  "Wavefront data format",
+		SampleConfig: "",
+	})
+	Add("xml", Format{
+		Description:  "XML documents queried using XPath",
+		SampleConfig: sampleConfig("xpath/sample.conf"),
+	})
+	Add("xpath_json", Format{
+		Description:  "JSON documents queried using XPath",
+		SampleConfig: sampleConfig("xpath/sample.conf"),
+	})
+	Add("xpath_msgpack", Format{
+		Description:  "MessagePack documents queried using XPath",
+		SampleConfig: sampleConfig("xpath/sample.conf"),
+	})
+	Add("xpath_protobuf", Format{
+		Description:  "Protocol buffer messages queried using XPath",
+		SampleConfig: sampleConfig("xpath/sample.conf"),
+	})
+	Add("xsv", Format{
+		Description:  "Delimiter separated values with detected delimiter and header",
+		SampleConfig: sampleConfig("xsv/sample.conf"),
+	})
+}
+
+// CommonSampleConfig contains the parser options applying to all data
+// formats.
+var CommonSampleConfig = sampleConfig("sample.conf")
@@ -0,0 +1,61 @@
+package parsers
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatsCoverBuiltinParsers(t *testing.T) {
+	for _, name := range FormatNames() {
+		_, err := newParser(&Config{DataFormat: name})
+		if err != nil {
+			require.NotContains(t, err.Error(), "Invalid data format", name)
+		}
+	}
+}
+
+func TestAddCreator(t *testing.T) {
+	Add("test_format", Format{
+		Description: "Format for testing",
+		Creator: func(config *Config) (Parser, error) {
+			return NewInfluxParser()
+		},
+	})
+	defer delete(Formats, "test_format")
+
+	require.Contains(t, FormatNames(), "test_format")
+
+	parser, err := NewParser(&Config{DataFormat: "test_format"})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte("cpu value=42\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+}
+
+func TestAddTwicePanics(t *testing.T) {
+	require.Panics(t, func() { Add("influx", Format{}) })
+}
+
+func TestSampleConfigsDocumented(t *testing.T) {
+	optionRe := regexp.MustCompile(`(?m)^\s*#?\s*(\w+)\s*=`)
+
+	files, err := fs.Glob(samples, "*/sample.conf")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		sample, err := samples.ReadFile(file)
+		require.NoError(t, err)
+		readme, err := os.ReadFile(path.Join(path.Dir(file), "README.md"))
+		require.NoError(t, err)
+
+		for _, match := range optionRe.FindAllStringSubmatch(string(sample), -1) {
+			require.Contains(t, string(readme), match[1], "option of %s", file)
+		}
+	}
+}
@@ -3,16 +3,21 @@ package grok
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/alecthomas/units"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/vjeantet/grok"
 )
 
@@ -44,6 +49,9 @@ const (
 	Float            = "float"
 	String           = "string"
 	Duration         = "duration"
+	Bytes            = "bytes"
+	Bool             = "bool"
+	EpochMilliField  = "epoch_milli"
 	Drop             = "drop"
 	Epoch            = "EPOCH"
 	EpochMilli       = "EPOCH_MILLI"
@@ -52,6 +60,8 @@ const (
 	GenericTimestamp = "GENERIC_TIMESTAMP"
 )
 
+var errMatchTimeout = errors.New("grok match timed out")
+
 var (
 	// matches named captures that contain a modifier.
 	//   ie,
@@ -87,9 +97,30 @@ type Parser struct {
 	Timezone string
 	loc      *time.Location
 
+	// TimestampFormats are tried in order for timestamps not matching the
+	// layout of their capture. Timestamps not matching any format are
+	// replaced by the current time.
+	TimestampFormats []string
+	timeParser       *internal.TimestampParser
+
 	// UniqueTimestamp when set to "disable", timestamp will not incremented if there is a duplicate.
 	UniqueTimestamp string
 
+	// MultilinePattern is a regular expression used to join several lines
+	// into a single log entry. Lines matching the pattern belong to the
+	// previous line, unless MultilineNegate is set in which case lines _not_
+	// matching the pattern belong to the previous line.
+	MultilinePattern string
+	MultilineNegate  bool
+	// MultilineTimeout is the time after which a pending entry is emitted
+	// by line-based consumers even if no new entry has started.
+	// Default: 5s
+	MultilineTimeout time.Duration
+	// MultilineMaxLines limits the number of lines joined into one entry.
+	// Default: 0 which means unlimited
+	MultilineMaxLines int
+	multilineRe       *regexp.Regexp
+
 	// typeMap is a map of patterns -> capture name -> modifier,
 	//   ie, {
 	//          "%{TESTLOG}":
@@ -124,35 +155,135 @@ type Parser struct {
 	timeFunc func() time.Time
 	g        *grok.Grok
 	tsModder *tsModder
+
+	// ReloadInterval is the interval for checking the custom pattern files
+	// for changes. Modified files are recompiled without restarting.
+	// Default: 0 which disables reloading
+	ReloadInterval    time.Duration
+	reloadMu          sync.Mutex
+	lastReloadCheck   time.Time
+	patternFilesState string
+	// mu protects the compiled patterns during a reload
+	mu sync.RWMutex
+
+	// Timeout limits the time spent matching a single line against all
+	// patterns. The deadline is checked after every pattern, lines exceeding
+	// the timeout are skipped and counted.
+	// Default: 0 which means no timeout
+	Timeout       time.Duration
+	matchTimeouts selfstat.Stat
+
+	// MaxLineLength is the maximum length of a line in bytes. Longer lines
+	// are skipped and counted without matching them. As the patterns are
+	// matched in linear time, this bounds the time spent on a single pattern.
+	// Default: 0 which means no limit
+	MaxLineLength  int
+	oversizedLines selfstat.Stat
+
+	// PatternTag is the name of the tag holding the name of the pattern that
+	// matched the line, useful if multiple patterns are configured. Patterns
+	// referencing a single named pattern, like %{COMBINED_LOG_FORMAT}, are
+	// tagged with that name and others with their position in Patterns, like
+	// pattern_2.
+	// Default: "" which disables the tag
+	PatternTag string
+
+	// UnmatchedMeasurement is the measurement name for lines not matching
+	// any of the patterns. The line is stored in the "message" field.
+	// Default: "" which drops unmatched lines
+	UnmatchedMeasurement string
+
+	// patternNames maps the internal pattern names to the names of the
+	// patterns given in Patterns used for the PatternTag.
+	patternNames map[string]string
+}
+
+// singlePatternRe matches patterns referencing a single named pattern,
+// optionally with a semantic and a type.
+var singlePatternRe = regexp.MustCompile(`^%{(\w+)(:[^}]*)?}$`)
+
+// patternName returns the name of the pattern at the given index of
+// Patterns for the PatternTag.
+func patternName(pattern string, index int) string {
+	if match := singlePatternRe.FindStringSubmatch(pattern); match != nil {
+		return match[1]
+	}
+	return "pattern_" + strconv.Itoa(index+1)
 }
 
 // Compile is a bound method to Parser which will process the options for our parser
 func (p *Parser) Compile() error {
+	p.tsModder = &tsModder{}
+	var err error
+
+	if p.UniqueTimestamp == "" {
+		p.UniqueTimestamp = "auto"
+	}
+
+	p.loc, err = time.LoadLocation(p.Timezone)
+	if err != nil {
+		log.Printf("W! improper timezone supplied (%s), setting loc to UTC", p.Timezone)
+		p.loc, _ = time.LoadLocation("UTC")
+	}
+	p.timeParser = internal.NewTimestampParser(p.TimestampFormats, p.loc.String(), false)
+
+	if p.timeFunc == nil {
+		p.timeFunc = time.Now
+	}
+
+	if p.MultilinePattern != "" {
+		p.multilineRe, err = regexp.Compile(p.MultilinePattern)
+		if err != nil {
+			return fmt.Errorf("compiling multiline pattern failed: %w", err)
+		}
+		if p.MultilineTimeout == 0 {
+			p.MultilineTimeout = 5 * time.Second
+		}
+	}
+
+	if p.Timeout > 0 {
+		p.matchTimeouts = selfstat.Register("grok", "match_timeouts", map[string]string{
+			"measurement": p.Measurement,
+		})
+	}
+	if p.MaxLineLength > 0 {
+		p.oversizedLines = selfstat.Register("grok", "oversized_lines", map[string]string{
+			"measurement": p.Measurement,
+		})
+	}
+
+	p.patternFilesState = p.customPatternFilesState()
+	p.lastReloadCheck = time.Now()
+
+	return p.compilePatterns()
+}
+
+// compilePatterns builds the grok patterns from the configured patterns,
+// custom patterns and custom pattern files.
+func (p *Parser) compilePatterns() error {
 	p.typeMap = make(map[string]map[string]string)
 	p.tsMap = make(map[string]map[string]string)
 	p.patterns = make(map[string]string)
-	p.tsModder = &tsModder{}
 	var err error
 	p.g, err = grok.NewWithConfig(&grok.Config{NamedCapturesOnly: true})
 	if err != nil {
 		return err
 	}
 
-	if p.UniqueTimestamp == "" {
-		p.UniqueTimestamp = "auto"
-	}
-
 	// Give Patterns fake names so that they can be treated as named
 	// "custom patterns"
+	customPatterns := p.CustomPatterns
 	p.NamedPatterns = make([]string, 0, len(p.Patterns))
+	p.patternNames = make(map[string]string, len(p.Patterns))
 	for i, pattern := range p.Patterns {
 		pattern = strings.TrimSpace(pattern)
 		if pattern == "" {
 			continue
 		}
 		name := fmt.Sprintf("GROK_INTERNAL_PATTERN_%d", i)
-		p.CustomPatterns += "\n" + name + " " + pattern + "\n"
+		customPatterns += "\n" + name + " " + pattern + "\n"
 		p.NamedPatterns = append(p.NamedPatterns, "%{"+name+"}")
+		p.patternNames["%{"+name+"}"] = patternName(pattern, i)
 	}
 
 	if len(p.NamedPatterns) == 0 {
@@ -161,11 +292,9 @@ func (p *Parser) Compile() error {
 
 	// Combine user-supplied CustomPatterns with DEFAULT_PATTERNS and parse
 	// them together as the same type of pattern.
-	p.CustomPatterns = DefaultPatterns + p.CustomPatterns
-	if len(p.CustomPatterns) != 0 {
-		scanner := bufio.NewScanner(strings.NewReader(p.CustomPatterns))
-		p.addCustomPatterns(scanner)
-	}
+	customPatterns = DefaultPatterns + customPatterns
+	scanner := bufio.NewScanner(strings.NewReader(customPatterns))
+	p.addCustomPatterns(scanner)
 
 	// Parse any custom pattern files supplied.
 	for _, filename := range p.CustomPatternFiles {
@@ -176,40 +305,128 @@ func (p *Parser) Compile() error {
 
 		scanner := bufio.NewScanner(bufio.NewReader(file))
 		p.addCustomPatterns(scanner)
+		file.Close()
 	}
 
-	p.loc, err = time.LoadLocation(p.Timezone)
-	if err != nil {
-		log.Printf("W! improper timezone supplied (%s), setting loc to UTC", p.Timezone)
-		p.loc, _ = time.LoadLocation("UTC")
+	return p.compileCustomPatterns()
+}
+
+// reloadCustomPatternFiles recompiles the patterns if any of the custom
+// pattern files changed since the last check. If the new patterns are invalid
+// the previously compiled patterns are kept.
+func (p *Parser) reloadCustomPatternFiles() {
+	if p.ReloadInterval <= 0 || len(p.CustomPatternFiles) == 0 {
+		return
 	}
 
-	if p.timeFunc == nil {
-		p.timeFunc = time.Now
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(p.lastReloadCheck) < p.ReloadInterval {
+		return
 	}
+	p.lastReloadCheck = now
 
-	return p.compileCustomPatterns()
+	state := p.customPatternFilesState()
+	if state == p.patternFilesState {
+		return
+	}
+	// Remember the state even on failure to only report an invalid file once.
+	p.patternFilesState = state
+
+	candidate := &Parser{
+		Patterns:           p.Patterns,
+		CustomPatterns:     p.CustomPatterns,
+		CustomPatternFiles: p.CustomPatternFiles,
+	}
+	if err := candidate.compilePatterns(); err != nil {
+		log.Printf("E! Reloading grok custom pattern files failed, keeping previous patterns: %v", err)
+		return
+	}
+
+	p.mu.Lock()
+	p.NamedPatterns = candidate.NamedPatterns
+	p.patternNames = candidate.patternNames
+	p.typeMap = candidate.typeMap
+	p.tsMap = candidate.tsMap
+	p.patterns = candidate.patterns
+	p.g = candidate.g
+	p.mu.Unlock()
+
+	log.Printf("I! Reloaded grok custom pattern files %v", p.CustomPatternFiles)
+}
+
+// customPatternFilesState returns a fingerprint of the modification time and
+// size of all custom pattern files.
+func (p *Parser) customPatternFilesState() string {
+	var state strings.Builder
+	for _, filename := range p.CustomPatternFiles {
+		info, err := os.Stat(filename)
+		if err != nil {
+			fmt.Fprintf(&state, "%s:missing;", filename)
+			continue
+		}
+		fmt.Fprintf(&state, "%s:%d:%d;", filename, info.ModTime().UnixNano(), info.Size())
+	}
+	return state.String()
 }
 
 // ParseLine is the primary function to process individual lines, returning the metrics
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
-	var err error
-	// values are the parsed fields from the log line
-	var values map[string]string
-	// the matching pattern string
-	var patternName string
-	for _, pattern := range p.NamedPatterns {
-		if values, err = p.g.Parse(pattern, line); err != nil {
+	p.reloadCustomPatternFiles()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.parseLine(line)
+}
+
+// ParseLines parses a batch of lines like ParseLine. The custom pattern files
+// are checked and the patterns are locked only once for the whole batch.
+func (p *Parser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	p.reloadCustomPatternFiles()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	metrics := make([]telegraf.Metric, 0, len(lines))
+	for _, line := range lines {
+		m, err := p.parseLine(line)
+		if err != nil {
 			return nil, err
 		}
-		if len(values) != 0 {
-			patternName = pattern
-			break
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+// parseLine parses a single line, the caller must hold the read lock.
+func (p *Parser) parseLine(line string) (telegraf.Metric, error) {
+	if p.MaxLineLength > 0 && len(line) > p.MaxLineLength {
+		p.oversizedLines.Incr(1)
+		log.Printf("W! Grok skipping line of %d bytes exceeding the maximum of %d", len(line), p.MaxLineLength)
+		return nil, nil
+	}
+
+	// values are the parsed fields from the log line and patternName is
+	// the matching pattern string
+	values, patternName, err := p.matchPatterns(line)
+	if err != nil {
+		if errors.Is(err, errMatchTimeout) {
+			log.Printf("W! Grok match timed out after %s for: %q", p.Timeout, line)
+			return nil, nil
 		}
+		return nil, err
 	}
 
 	if len(values) == 0 {
 		log.Printf("D! Grok no match found for: %q", line)
+		if p.UnmatchedMeasurement != "" {
+			return p.unmatchedMetric(line), nil
+		}
 		return nil, nil
 	}
 
@@ -221,6 +438,11 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 		tags[k] = v
 	}
 
+	// record the matching pattern
+	if p.PatternTag != "" {
+		tags[p.PatternTag] = p.patternNames[patternName]
+	}
+
 	timestamp := time.Now()
 	for k, v := range values {
 		if k == "" || v == "" {
@@ -264,12 +486,33 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 				fields[k] = fv
 			}
 		case Duration:
-			d, err := time.ParseDuration(v)
+			d, err := parseDuration(v)
 			if err != nil {
 				log.Printf("E! Error parsing %s to duration: %s", v, err)
 			} else {
 				fields[k] = int64(d)
 			}
+		case Bytes:
+			b, err := parseBytes(v)
+			if err != nil {
+				log.Printf("E! Error parsing %s to bytes: %s", v, err)
+			} else {
+				fields[k] = b
+			}
+		case Bool:
+			b, err := parseBool(v)
+			if err != nil {
+				log.Printf("E! Error parsing %s to bool: %s", v, err)
+			} else {
+				fields[k] = b
+			}
+		case EpochMilliField:
+			ns, err := parseEpochMilli(v)
+			if err != nil {
+				log.Printf("E! Error parsing %s to epoch: %s", v, err)
+			} else {
+				fields[k] = ns
+			}
 		case Tag:
 			tags[k] = v
 		case String:
@@ -347,6 +590,9 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 					}
 				}
 			}
+			if !foundTs {
+				timestamp, foundTs = p.parseTimestampFormats(v, timestamp)
+			}
 			// if we still haven't found a timestamp layout, log it and we will
 			// just use time.Now()
 			if !foundTs {
@@ -363,6 +609,8 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 					ts = ts.AddDate(timestamp.Year(), 0, 0)
 				}
 				timestamp = ts
+			} else if ts, ok := p.parseTimestampFormats(v, timestamp); ok {
+				timestamp = ts
 			} else {
 				log.Printf("E! Error parsing %s to time layout [%s]: %s", v, t, err)
 			}
@@ -376,24 +624,193 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	return metric.New(p.Measurement, tags, fields, p.tsModder.tsMod(timestamp)), nil
 }
 
+// parseTimestampFormats parses the value using the configured timestamp
+// formats. The given default is returned if none of the formats matches.
+func (p *Parser) parseTimestampFormats(v string, def time.Time) (time.Time, bool) {
+	if len(p.timeParser.Formats) == 0 {
+		return def, false
+	}
+	ts, err := p.timeParser.Parse(v)
+	if err != nil {
+		return def, false
+	}
+	return ts, true
+}
+
+// parseDuration parses Go durations like "5.23ms" as well as clock notation
+// like "01:02:03.5" or "02:03".
+func parseDuration(v string) (time.Duration, error) {
+	if !strings.Contains(v, ":") {
+		return time.ParseDuration(v)
+	}
+
+	parts := strings.Split(v, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid clock duration %q", v)
+	}
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("invalid clock duration %q", v)
+	}
+	d := time.Duration(seconds * float64(time.Second))
+	for i, unit := range []time.Duration{time.Minute, time.Hour} {
+		idx := len(parts) - 2 - i
+		if idx < 0 {
+			break
+		}
+		n, err := strconv.ParseUint(parts[idx], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid clock duration %q", v)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+// parseBytes parses sizes like "1.5GB", "512 KiB" or "10M" into bytes. Units
+// with "iB" suffix are base 2, all others are base 10.
+func parseBytes(v string) (int64, error) {
+	v = strings.Join(strings.Fields(v), "")
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return n, nil
+	}
+	if strings.HasSuffix(v, "b") {
+		v = strings.TrimSuffix(v, "b") + "B"
+	}
+	if !strings.HasSuffix(v, "B") {
+		v += "B"
+	}
+	return units.ParseStrictBytes(v)
+}
+
+// parseBool parses booleans accepting the values of strconv.ParseBool as well
+// as "yes", "no", "on" and "off".
+func parseBool(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(strings.ToLower(v))
+}
+
+// parseEpochMilli converts milliseconds since unix epoch, possibly with a
+// decimal part, to nanoseconds.
+func parseEpochMilli(v string) (int64, error) {
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return ms * int64(time.Millisecond), nil
+	}
+	parts := strings.SplitN(v, ".", 2)
+	ms, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || len(parts) != 2 {
+		return 0, fmt.Errorf("invalid epoch %q", v)
+	}
+	padded := fmt.Sprintf("%-6s", parts[1])
+	ns, err := strconv.ParseUint(strings.Replace(padded[:6], " ", "0", -1), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid epoch %q", v)
+	}
+	if strings.HasPrefix(parts[0], "-") {
+		return ms*int64(time.Millisecond) - int64(ns), nil
+	}
+	return ms*int64(time.Millisecond) + int64(ns), nil
+}
+
+// unmatchedMetric returns a metric containing a line not matching any of the
+// patterns.
+func (p *Parser) unmatchedMetric(line string) telegraf.Metric {
+	tags := make(map[string]string, len(p.DefaultTags))
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	fields := map[string]interface{}{"message": line}
+	return metric.New(p.UnmatchedMeasurement, tags, fields, p.timeFunc())
+}
+
+// matchPatterns tries all patterns in order and returns the values of the
+// first matching pattern. If a timeout is configured, the deadline is checked
+// after every pattern and errMatchTimeout is returned once it passed.
+func (p *Parser) matchPatterns(line string) (map[string]string, string, error) {
+	var deadline time.Time
+	if p.Timeout > 0 {
+		deadline = time.Now().Add(p.Timeout)
+	}
+
+	for _, pattern := range p.NamedPatterns {
+		values, err := p.g.Parse(pattern, line)
+		if err != nil {
+			return nil, "", err
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			p.matchTimeouts.Incr(1)
+			return nil, "", errMatchTimeout
+		}
+		if len(values) != 0 {
+			return values, pattern, nil
+		}
+	}
+	return nil, "", nil
+}
+
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
-	metrics := make([]telegraf.Metric, 0)
+	return p.ParseLines(p.splitEntries(buf))
+}
+
+// IsMultiline returns true if the parser is configured to join several lines
+// into a single log entry.
+func (p *Parser) IsMultiline() bool {
+	return p.multilineRe != nil
+}
+
+// IsNewLogLine returns true if the given line starts a new log entry and false
+// if it is a continuation of the previous entry.
+func (p *Parser) IsNewLogLine(line string) bool {
+	if p.multilineRe == nil {
+		return true
+	}
+	return p.multilineRe.MatchString(line) == p.MultilineNegate
+}
+
+// MultilineFlushTimeout returns the time after which a pending multiline entry
+// should be flushed.
+func (p *Parser) MultilineFlushTimeout() time.Duration {
+	return p.MultilineTimeout
+}
+
+// MultilineLineLimit returns the maximum number of lines joined into a single
+// entry, zero means unlimited.
+func (p *Parser) MultilineLineLimit() int {
+	return p.MultilineMaxLines
+}
+
+// splitEntries splits the buffer into log entries. Without multiline
+// configuration every line is an entry of its own, otherwise continuation
+// lines are joined to the preceding line using a newline.
+func (p *Parser) splitEntries(buf []byte) []string {
+	var entries []string
+	var current []string
 
 	scanner := bufio.NewScanner(bytes.NewReader(buf))
 	for scanner.Scan() {
 		line := scanner.Text()
-		m, err := p.ParseLine(line)
-		if err != nil {
-			return nil, err
+		if !p.IsMultiline() {
+			entries = append(entries, line)
+			continue
 		}
 
-		if m == nil {
-			continue
+		full := p.MultilineMaxLines > 0 && len(current) >= p.MultilineMaxLines
+		if len(current) > 0 && (p.IsNewLogLine(line) || full) {
+			entries = append(entries, strings.Join(current, "\n"))
+			current = current[:0]
 		}
-		metrics = append(metrics, m)
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		entries = append(entries, strings.Join(current, "\n"))
 	}
 
-	return metrics, nil
+	return entries
 }
 
 func (p *Parser) SetDefaultTags(tags map[string]string) {
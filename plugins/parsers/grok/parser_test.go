@@ -2,6 +2,9 @@ package grok
 
 import (
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -655,6 +658,63 @@ func TestParseErrors_WrongDurationType(t *testing.T) {
 		testutil.MustMetric("grok", map[string]string{}, map[string]interface{}{}, time.Unix(0, 0)))
 }
 
+func TestParseExtendedTypes(t *testing.T) {
+	p := &Parser{
+		Measurement: "grok",
+		Patterns:    []string{"%{TEST_LOG}"},
+		CustomPatterns: `
+			SIZE %{NUMBER}\s?[a-zA-Z]*
+			TEST_LOG %{NUMBER:ts:ts-epoch} %{SIZE:size:bytes} %{NOTSPACE:elapsed:duration} %{WORD:cached:bool} %{NOTSPACE:started:epoch_milli}
+		`,
+	}
+	require.NoError(t, p.Compile())
+
+	tests := []struct {
+		line   string
+		fields map[string]interface{}
+	}{
+		{
+			line: "1 1.5GB 01:02:03.5 true 1633089600123",
+			fields: map[string]interface{}{
+				"size":    int64(1500000000),
+				"elapsed": int64(time.Hour + 2*time.Minute + 3500*time.Millisecond),
+				"cached":  true,
+				"started": int64(1633089600123000000),
+			},
+		},
+		{
+			line: "2 512 KiB 250ms no 1633089600123.456",
+			fields: map[string]interface{}{
+				"size":    int64(512 * 1024),
+				"elapsed": int64(250 * time.Millisecond),
+				"cached":  false,
+				"started": int64(1633089600123456000),
+			},
+		},
+		{
+			line: "3 10M 02:03 ON 0",
+			fields: map[string]interface{}{
+				"size":    int64(10000000),
+				"elapsed": int64(2*time.Minute + 3*time.Second),
+				"cached":  true,
+				"started": int64(0),
+			},
+		},
+		{
+			line:   "4 12QB 1:2:3:4 maybe 16330896001x",
+			fields: map[string]interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			m, err := p.ParseLine(tt.line)
+			require.NoError(t, err)
+			require.NotNil(t, m)
+			require.Equal(t, tt.fields, m.Fields())
+		})
+	}
+}
+
 func TestParseErrors_WrongTimeLayout(t *testing.T) {
 	p := &Parser{
 		Measurement: "grok",
@@ -1114,3 +1174,206 @@ func TestTrimRegression(t *testing.T) {
 	)
 	require.Equal(t, expected, actual)
 }
+
+func TestMultilineParse(t *testing.T) {
+	p := &Parser{
+		Measurement:      "java",
+		Patterns:         []string{`(?s)%{TIMESTAMP_ISO8601:timestamp:ts-"2006-01-02 15:04:05"} %{LOGLEVEL:level:tag} %{GREEDYDATA:message}`},
+		MultilinePattern: `^\d{4}-\d{2}-\d{2}`,
+		MultilineNegate:  true,
+	}
+	require.NoError(t, p.Compile())
+	require.True(t, p.IsMultiline())
+	require.True(t, p.IsNewLogLine("2021-11-02 10:00:00 INFO started"))
+	require.False(t, p.IsNewLogLine("\tat com.foo.Main.run(Main.java:12)"))
+
+	buf := []byte(`2021-11-02 10:00:00 INFO started
+2021-11-02 10:00:01 ERROR failed
+java.lang.NullPointerException: boom
+	at com.foo.Main.run(Main.java:12)
+2021-11-02 10:00:02 INFO recovered
+`)
+	metrics, err := p.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	require.Equal(t, "ERROR", metrics[1].Tags()["level"])
+	require.Equal(t,
+		"failed\njava.lang.NullPointerException: boom\n\tat com.foo.Main.run(Main.java:12)",
+		metrics[1].Fields()["message"],
+	)
+	require.Equal(t, "recovered", metrics[2].Fields()["message"])
+}
+
+func TestMultilineMaxLines(t *testing.T) {
+	p := &Parser{
+		Patterns:          []string{`(?s)%{GREEDYDATA:message}`},
+		MultilinePattern:  `^\s`,
+		MultilineMaxLines: 2,
+	}
+	require.NoError(t, p.Compile())
+
+	metrics, err := p.Parse([]byte("first\n second\n third\n fourth\nfifth"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	require.Equal(t, "first\n second", metrics[0].Fields()["message"])
+	require.Equal(t, " third\n fourth", metrics[1].Fields()["message"])
+	require.Equal(t, "fifth", metrics[2].Fields()["message"])
+}
+
+func TestMultilineInvalidPattern(t *testing.T) {
+	p := &Parser{
+		Patterns:         []string{`%{GREEDYDATA:message}`},
+		MultilinePattern: `(`,
+	}
+	require.Error(t, p.Compile())
+}
+
+func TestReloadCustomPatternFiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "patterns")
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_LOG %{NUMBER:value:int}\n"), 0600))
+
+	p := &Parser{
+		Patterns:           []string{"%{TEST_LOG}"},
+		CustomPatternFiles: []string{filename},
+		ReloadInterval:     time.Nanosecond,
+	}
+	require.NoError(t, p.Compile())
+
+	m, err := p.ParseLine("42")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, m.Fields())
+
+	// Use a larger file to guarantee a change in the file state
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_LOG value=%{NUMBER:value:float}\n"), 0600))
+	m, err = p.ParseLine("value=42")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": float64(42)}, m.Fields())
+
+	// Invalid patterns must keep the previous set
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_LOG %{NUMBER:value:float} %{NUMBER:ts1:ts} %{NUMBER:ts2:ts}\n"), 0600))
+	m, err = p.ParseLine("value=43")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": float64(43)}, m.Fields())
+}
+
+func TestMatchTimeout(t *testing.T) {
+	p := &Parser{
+		Measurement: "grok_timeout_test",
+		Patterns:    []string{`%{GREEDYDATA:first} %{GREEDYDATA:second}x$`},
+		Timeout:     time.Nanosecond,
+	}
+	require.NoError(t, p.Compile())
+	before := p.matchTimeouts.Get()
+
+	line := strings.Repeat("a b ", 1<<18)
+	m, err := p.ParseLine(line)
+	require.NoError(t, err)
+	require.Nil(t, m)
+	require.Equal(t, before+1, p.matchTimeouts.Get())
+}
+
+func TestMatchWithinTimeout(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{`%{NUMBER:value:int}`},
+		Timeout:  time.Minute,
+	}
+	require.NoError(t, p.Compile())
+
+	m, err := p.ParseLine("42")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, m.Fields())
+}
+
+func TestParseLines(t *testing.T) {
+	p := &Parser{
+		Measurement: "grok",
+		Patterns:    []string{`^%{WORD:op} %{NUMBER:value:int}$`},
+		Timeout:     time.Minute,
+	}
+	require.NoError(t, p.Compile())
+
+	metrics, err := p.ParseLines([]string{"get 1", "no match", "put 2", "get 3"})
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	for i, expected := range []int64{1, 2, 3} {
+		require.Equal(t, "grok", metrics[i].Name())
+		v, ok := metrics[i].GetField("value")
+		require.True(t, ok)
+		require.Equal(t, expected, v)
+	}
+}
+
+func TestParseLinesTimeout(t *testing.T) {
+	p := &Parser{
+		Measurement: "grok_timeout_test",
+		Patterns:    []string{`%{GREEDYDATA:first} %{GREEDYDATA:second}x$`},
+		Timeout:     100 * time.Millisecond,
+	}
+	require.NoError(t, p.Compile())
+	before := p.matchTimeouts.Get()
+
+	// A timed out line must not affect the following ones.
+	slow := strings.Repeat("a b ", 1<<18)
+	metrics, err := p.ParseLines([]string{"a bx", slow, "c dx"})
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, before+1, p.matchTimeouts.Get())
+}
+
+func TestMaxLineLength(t *testing.T) {
+	p := &Parser{
+		Measurement:   "grok_max_line_length_test",
+		Patterns:      []string{`%{WORD:first} %{WORD:second}`},
+		MaxLineLength: 16,
+	}
+	require.NoError(t, p.Compile())
+	before := p.oversizedLines.Get()
+
+	metrics, err := p.ParseLines([]string{"a b", strings.Repeat("a b ", 10), "c d"})
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, before+1, p.oversizedLines.Get())
+}
+
+func TestPatternTag(t *testing.T) {
+	p := &Parser{
+		Measurement: "grok",
+		Patterns:    []string{`^a=%{NUMBER:a:int}$`, `^b=%{NUMBER:b:int}$`, `%{TEST_LOG_C}`},
+		CustomPatterns: `
+			TEST_LOG_C ^c=%{NUMBER:c:int}$
+		`,
+		PatternTag: "grok_pattern",
+	}
+	require.NoError(t, p.Compile())
+
+	m, err := p.ParseLine("b=2")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]string{"grok_pattern": "pattern_2"}, m.Tags())
+	require.Equal(t, map[string]interface{}{"b": int64(2)}, m.Fields())
+
+	m, err = p.ParseLine("c=3")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]string{"grok_pattern": "TEST_LOG_C"}, m.Tags())
+	require.Equal(t, map[string]interface{}{"c": int64(3)}, m.Fields())
+}
+
+func TestUnmatchedMeasurement(t *testing.T) {
+	p := &Parser{
+		Measurement:          "grok",
+		Patterns:             []string{`^a=%{NUMBER:a:int}$`},
+		UnmatchedMeasurement: "grok_unmatched",
+		DefaultTags:          map[string]string{"host": "localhost"},
+	}
+	require.NoError(t, p.Compile())
+
+	metrics, err := p.Parse([]byte("a=1\nsomething else\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, "grok", metrics[0].Name())
+	require.Equal(t, "grok_unmatched", metrics[1].Name())
+	require.Equal(t, map[string]string{"host": "localhost"}, metrics[1].Tags())
+	require.Equal(t, map[string]interface{}{"message": "something else"}, metrics[1].Fields())
+}
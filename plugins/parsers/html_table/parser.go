@@ -0,0 +1,311 @@
+package html_table
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Config holds the options of the html_table parser.
+type Config struct {
+	MetricName        string
+	Selector          string
+	TagColumns        []string
+	MeasurementColumn string
+	TimestampColumn   string
+	TimestampFormat   string
+	Timezone          string
+	DefaultTags       map[string]string
+
+	// TimestampFormats are tried in order if the timestamp does not match
+	// TimestampFormat.
+	TimestampFormats []string
+	// TimestampFallbackToNow uses the current time if the timestamp is
+	// missing or does not match any of the formats.
+	TimestampFallbackToNow bool
+
+	TimeFunc func() time.Time
+}
+
+// Parser extracts the rows of HTML tables as metrics. The tables are selected
+// by a CSS selector, the header row provides the field names and every other
+// row is turned into a metric.
+type Parser struct {
+	config     *Config
+	selector   cascadia.Selector
+	tagColumns map[string]bool
+	timeParser *internal.TimestampParser
+}
+
+// New returns a html_table parser for the given config.
+func New(config *Config) (*Parser, error) {
+	if config.MetricName == "" && config.MeasurementColumn == "" {
+		return nil, fmt.Errorf("metric name or measurement column required")
+	}
+	if config.Selector == "" {
+		config.Selector = "table"
+	}
+	selector, err := cascadia.Compile(config.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", config.Selector, err)
+	}
+	if config.TimeFunc == nil {
+		config.TimeFunc = time.Now
+	}
+
+	tagColumns := make(map[string]bool, len(config.TagColumns))
+	for _, name := range config.TagColumns {
+		tagColumns[name] = true
+	}
+
+	formats := append([]string{config.TimestampFormat}, config.TimestampFormats...)
+	timeParser := internal.NewTimestampParser(formats, config.Timezone, config.TimestampFallbackToNow)
+	timeParser.Now = func() time.Time { return config.TimeFunc() }
+
+	return &Parser{
+		config:     config,
+		selector:   selector,
+		tagColumns: tagColumns,
+		timeParser: timeParser,
+	}, nil
+}
+
+// Parse returns the metrics of the rows of all tables matching the selector.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if len(bytes.TrimSpace(buf)) == 0 {
+		return nil, nil
+	}
+
+	doc, err := html.Parse(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("parsing html failed: %w", err)
+	}
+
+	var metrics []telegraf.Metric
+	for _, table := range p.selector.MatchAll(doc) {
+		if table.Type != html.ElementNode || table.Data != "table" {
+			return nil, fmt.Errorf("selector %q matches %q instead of a table", p.config.Selector, table.Data)
+		}
+		m, err := p.parseTable(table)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m...)
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(_ string) (telegraf.Metric, error) {
+	return nil, fmt.Errorf("parsing lines is not supported by the html_table data format")
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.config.DefaultTags = tags
+}
+
+// parseTable uses the first row of the table head or, if there is no head,
+// the first row of the table as header.
+func (p *Parser) parseTable(table *html.Node) ([]telegraf.Metric, error) {
+	rows := tableRows(table)
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header, data := rows[0], rows[1:]
+	for i, row := range rows {
+		if row.head {
+			header = row
+			data = append(rows[:i:i], rows[i+1:]...)
+			break
+		}
+	}
+
+	columns := make([]string, 0, len(header.cells))
+	for i, name := range header.cells {
+		if name == "" {
+			name = "column_" + strconv.Itoa(i+1)
+		}
+		columns = append(columns, name)
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(data))
+	for i, row := range data {
+		m, err := p.parseRow(columns, row.cells)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+func (p *Parser) parseRow(columns, cells []string) (telegraf.Metric, error) {
+	name := p.config.MetricName
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+	var timestamp string
+	for i, value := range cells {
+		if i >= len(columns) || value == "" {
+			continue
+		}
+		column := columns[i]
+
+		switch {
+		case column == p.config.MeasurementColumn:
+			name = value
+		case column == p.config.TimestampColumn:
+			timestamp = value
+		case p.tagColumns[column]:
+			tags[column] = value
+		default:
+			fields[column] = convert(value)
+		}
+	}
+
+	// Rows without any field, e.g. separators, are skipped.
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf("missing measurement name")
+	}
+
+	var ts time.Time
+	var err error
+	switch {
+	case p.config.TimestampColumn == "":
+		ts = p.config.TimeFunc()
+	case timestamp == "":
+		ts, err = p.timeParser.Missing(fmt.Errorf("timestamp column %q is empty", p.config.TimestampColumn))
+	default:
+		ts, err = p.timeParser.Parse(timestamp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range p.config.DefaultTags {
+		if _, found := tags[k]; !found {
+			tags[k] = v
+		}
+	}
+	return metric.New(name, tags, fields, ts), nil
+}
+
+// row holds the text of the cells of a table row.
+type row struct {
+	cells []string
+	// head is true for rows of the table head or consisting of header
+	// cells only.
+	head bool
+}
+
+// tableRows returns the rows of the table without the ones of nested tables.
+// Cells spanning several columns are repeated to keep the columns aligned.
+func tableRows(table *html.Node) []row {
+	var rows []row
+	var walk func(n *html.Node, head bool)
+	walk = func(n *html.Node, head bool) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "table":
+			case "thead":
+				walk(c, true)
+			case "tr":
+				rows = append(rows, tableRow(c, head))
+			default:
+				walk(c, head)
+			}
+		}
+	}
+	walk(table, false)
+	return rows
+}
+
+// maxColspan is the maximum number of columns a cell spans, as clamped by
+// browsers, so a huge colspan cannot exhaust the memory.
+const maxColspan = 1000
+
+func tableRow(tr *html.Node, head bool) row {
+	r := row{head: head}
+	onlyHeaderCells := true
+	for c := tr.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+			continue
+		}
+		if c.Data == "td" {
+			onlyHeaderCells = false
+		}
+
+		span := 1
+		for _, attr := range c.Attr {
+			if attr.Key == "colspan" {
+				if n, err := strconv.Atoi(attr.Val); err == nil && n > 1 {
+					span = n
+				}
+				if span > maxColspan {
+					span = maxColspan
+				}
+			}
+		}
+		value := text(c)
+		for i := 0; i < span; i++ {
+			r.cells = append(r.cells, value)
+		}
+	}
+	if len(r.cells) > 0 && onlyHeaderCells {
+		r.head = true
+	}
+	return r
+}
+
+// text returns the text content of the node with collapsed whitespace,
+// ignoring nested tables.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			b.WriteByte(' ')
+		case n.Type == html.ElementNode && n.Data == "table":
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// convert returns the value as integer, float or boolean if possible and as
+// string otherwise.
+func convert(value string) interface{} {
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseBool(value); err == nil {
+		return v
+	}
+	return value
+}
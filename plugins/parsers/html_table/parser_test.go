@@ -0,0 +1,139 @@
+package html_table
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<body>
+  <h1>Status</h1>
+  <table id="outlets">
+    <thead>
+      <tr><th>Outlet</th><th>State</th><th>Load <small>(A)</small></th><th>Changed</th></tr>
+    </thead>
+    <tbody>
+      <tr><td> 1 </td><td>on</td><td>1.2</td><td>2021-10-01 12:00:00</td></tr>
+      <tr><td>2</td><td>off</td><td>0</td><td>2021-10-01 13:00:00</td></tr>
+      <tr><td>3</td><td>on</td><td></td><td>2021-10-01 14:00:00</td></tr>
+    </tbody>
+  </table>
+  <table class="sensors">
+    <tr><td>Sensor</td><td>Value</td><td></td></tr>
+    <tr><td>temp</td><td>23.5<table><tr><td>nested</td></tr></table></td><td>true</td></tr>
+    <tr><td colspan="3"></td></tr>
+  </table>
+</body>
+</html>
+`
+
+func TestParse(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName:      "pdu",
+		Selector:        "table#outlets",
+		TagColumns:      []string{"Outlet"},
+		TimestampColumn: "Changed",
+		TimestampFormat: "2006-01-02 15:04:05",
+	})
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("pdu",
+			map[string]string{"Outlet": "1"},
+			map[string]interface{}{"State": "on", "Load (A)": 1.2},
+			time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC),
+		),
+		testutil.MustMetric("pdu",
+			map[string]string{"Outlet": "2"},
+			map[string]interface{}{"State": "off", "Load (A)": int64(0)},
+			time.Date(2021, 10, 1, 13, 0, 0, 0, time.UTC),
+		),
+		testutil.MustMetric("pdu",
+			map[string]string{"Outlet": "3"},
+			map[string]interface{}{"State": "on"},
+			time.Date(2021, 10, 1, 14, 0, 0, 0, time.UTC),
+		),
+	}
+
+	metrics, err := parser.Parse([]byte(page))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseAllTables(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName:        "status",
+		MeasurementColumn: "Sensor",
+		TimeFunc:          func() time.Time { return time.Unix(0, 0) },
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(page))
+	require.NoError(t, err)
+	require.Len(t, metrics, 4)
+	require.Equal(t, "status", metrics[0].Name())
+
+	// The second table has no header cells, so its first row is the header.
+	expected := testutil.MustMetric("temp",
+		map[string]string{},
+		map[string]interface{}{"Value": 23.5, "column_3": true},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, metrics[3:])
+}
+
+func TestColspan(t *testing.T) {
+	parser, err := New(&Config{MetricName: "table"})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(`<table>
+		<tr><th colspan="2">Disk</th><th>Free</th></tr>
+		<tr><td>sda</td><td>sda1</td><td>42</td></tr>
+	</table>`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"Disk": "sda1", "Free": int64(42)}, metrics[0].Fields())
+}
+
+func TestColspanLimit(t *testing.T) {
+	parser, err := New(&Config{MetricName: "table"})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(`<table>
+		<tr><th colspan="2000000000">Value</th></tr>
+		<tr><td>42</td></tr>
+	</table>`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"Value": int64(42)}, metrics[0].Fields())
+}
+
+func TestInvalid(t *testing.T) {
+	_, err := New(&Config{MetricName: "table", Selector: "table["})
+	require.Error(t, err)
+	_, err = New(&Config{})
+	require.Error(t, err)
+
+	parser, err := New(&Config{MetricName: "table", Selector: "h1"})
+	require.NoError(t, err)
+	_, err = parser.Parse([]byte(page))
+	require.Error(t, err)
+
+	_, err = parser.ParseLine("<table></table>")
+	require.Error(t, err)
+}
+
+func TestParserConformance(t *testing.T) {
+	testutil.RunParserConformance(t, testutil.ParserConformance{
+		New: func() (testutil.Parser, error) {
+			return New(&Config{MetricName: "table", Selector: "table.sensors"})
+		},
+		Valid: []byte(page),
+	})
+}
@@ -0,0 +1,229 @@
+package influx
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// slabSize is the number of tags, fields and list entries allocated at once
+// by the FastMetricHandler.
+const slabSize = 256
+
+// FastMetricHandler implements the Handler interface and produces
+// telegraf.Metric with as few allocations as possible. In contrast to the
+// MetricHandler it
+//
+//   - references the strings of names, keys and values without escapes
+//     directly in the parsed buffer instead of copying them, see SetBuffer,
+//   - hands out tags, fields and their lists from preallocated blocks instead
+//     of allocating each of them separately.
+//
+// As a consequence a parsed buffer and a block is only released once all
+// metrics referencing it are released. The lists of the metrics are capped
+// so modifying a metric never touches memory used by another one.
+type FastMetricHandler struct {
+	timePrecision time.Duration
+	timeFunc      TimeFunc
+
+	name   string
+	tm     time.Time
+	tags   []*telegraf.Tag
+	fields []*telegraf.Field
+
+	tagSlab       []telegraf.Tag
+	fieldSlab     []telegraf.Field
+	tagListSlab   []*telegraf.Tag
+	fieldListSlab []*telegraf.Field
+}
+
+func NewFastMetricHandler() *FastMetricHandler {
+	return &FastMetricHandler{
+		timePrecision: time.Nanosecond,
+		timeFunc:      time.Now,
+	}
+}
+
+func (h *FastMetricHandler) SetTimePrecision(p time.Duration) {
+	h.timePrecision = p
+}
+
+func (h *FastMetricHandler) SetTimeFunc(f TimeFunc) {
+	h.timeFunc = f
+}
+
+// SetBuffer returns a private copy of the input to be parsed. The strings of
+// the metrics reference the copy, so the caller is free to reuse the input.
+func (h *FastMetricHandler) SetBuffer(input []byte) []byte {
+	return append(make([]byte, 0, len(input)), input...)
+}
+
+func (h *FastMetricHandler) Metric() (telegraf.Metric, error) {
+	tm := h.tm
+	if tm.IsZero() {
+		tm = h.timeFunc().Truncate(h.timePrecision)
+	}
+
+	// Tags are usually sent sorted, so insertion sort is cheap.
+	for i := 1; i < len(h.tags); i++ {
+		for j := i; j > 0 && h.tags[j].Key < h.tags[j-1].Key; j-- {
+			h.tags[j], h.tags[j-1] = h.tags[j-1], h.tags[j]
+		}
+	}
+
+	var tags []*telegraf.Tag
+	if len(h.tags) > 0 {
+		if len(h.tagListSlab) < len(h.tags) {
+			h.tagListSlab = make([]*telegraf.Tag, slabLen(len(h.tags)))
+		}
+		tags = h.tagListSlab[:len(h.tags):len(h.tags)]
+		h.tagListSlab = h.tagListSlab[len(h.tags):]
+		copy(tags, h.tags)
+	}
+
+	var fields []*telegraf.Field
+	if len(h.fields) > 0 {
+		if len(h.fieldListSlab) < len(h.fields) {
+			h.fieldListSlab = make([]*telegraf.Field, slabLen(len(h.fields)))
+		}
+		fields = h.fieldListSlab[:len(h.fields):len(h.fields)]
+		h.fieldListSlab = h.fieldListSlab[len(h.fields):]
+		copy(fields, h.fields)
+	}
+
+	return metric.FromLists(h.name, tags, fields, tm, telegraf.Untyped), nil
+}
+
+func (h *FastMetricHandler) SetMeasurement(name []byte) error {
+	h.name = fastUnescape(name, nameEscapes, nameUnescaper)
+	h.tm = time.Time{}
+	h.tags = h.tags[:0]
+	h.fields = h.fields[:0]
+	return nil
+}
+
+func (h *FastMetricHandler) AddTag(key []byte, value []byte) error {
+	tk := fastUnescape(key, escapes, unescaper)
+	tv := fastUnescape(value, escapes, unescaper)
+	for _, tag := range h.tags {
+		if tag.Key == tk {
+			tag.Value = tv
+			return nil
+		}
+	}
+
+	if len(h.tagSlab) == 0 {
+		h.tagSlab = make([]telegraf.Tag, slabSize)
+	}
+	tag := &h.tagSlab[0]
+	h.tagSlab = h.tagSlab[1:]
+	tag.Key, tag.Value = tk, tv
+	h.tags = append(h.tags, tag)
+	return nil
+}
+
+func (h *FastMetricHandler) AddInt(key []byte, value []byte) error {
+	fv, err := parseIntBytes(bytes.TrimSuffix(value, []byte("i")), 10, 64)
+	if err != nil {
+		if numerr, ok := err.(*strconv.NumError); ok {
+			return numerr.Err
+		}
+		return err
+	}
+	h.addField(key, fv)
+	return nil
+}
+
+func (h *FastMetricHandler) AddUint(key []byte, value []byte) error {
+	fv, err := parseUintBytes(bytes.TrimSuffix(value, []byte("u")), 10, 64)
+	if err != nil {
+		if numerr, ok := err.(*strconv.NumError); ok {
+			return numerr.Err
+		}
+		return err
+	}
+	h.addField(key, fv)
+	return nil
+}
+
+func (h *FastMetricHandler) AddFloat(key []byte, value []byte) error {
+	fv, err := parseFloatBytes(value, 64)
+	if err != nil {
+		if numerr, ok := err.(*strconv.NumError); ok {
+			return numerr.Err
+		}
+		return err
+	}
+	h.addField(key, fv)
+	return nil
+}
+
+func (h *FastMetricHandler) AddString(key []byte, value []byte) error {
+	h.addField(key, fastUnescape(value, stringFieldEscapes, stringFieldUnescaper))
+	return nil
+}
+
+func (h *FastMetricHandler) AddBool(key []byte, value []byte) error {
+	fv, err := parseBoolBytes(value)
+	if err != nil {
+		return errors.New("unparseable bool")
+	}
+	h.addField(key, fv)
+	return nil
+}
+
+func (h *FastMetricHandler) SetTimestamp(tm []byte) error {
+	v, err := parseIntBytes(tm, 10, 64)
+	if err != nil {
+		if numerr, ok := err.(*strconv.NumError); ok {
+			return numerr.Err
+		}
+		return err
+	}
+
+	//time precision is overloaded to mean time unit here
+	ns := v * int64(h.timePrecision)
+	h.tm = time.Unix(0, ns)
+	return nil
+}
+
+func (h *FastMetricHandler) addField(key []byte, value interface{}) {
+	fk := fastUnescape(key, escapes, unescaper)
+	for _, field := range h.fields {
+		if field.Key == fk {
+			field.Value = value
+			return
+		}
+	}
+
+	if len(h.fieldSlab) == 0 {
+		h.fieldSlab = make([]telegraf.Field, slabSize)
+	}
+	field := &h.fieldSlab[0]
+	h.fieldSlab = h.fieldSlab[1:]
+	field.Key, field.Value = fk, value
+	h.fields = append(h.fields, field)
+}
+
+// fastUnescape works like unescape but returns a string referencing the given
+// bytes if there is nothing to unescape. The bytes must not be modified
+// afterwards.
+func fastUnescape(b []byte, chars string, r *strings.Replacer) string {
+	if bytes.ContainsAny(b, chars) {
+		return r.Replace(unsafeBytesToString(b))
+	}
+	return unsafeBytesToString(b)
+}
+
+// slabLen returns the size of a block holding at least n entries.
+func slabLen(n int) int {
+	if n > slabSize {
+		return n
+	}
+	return slabSize
+}
@@ -0,0 +1,11 @@
+//go:build gofuzz
+// +build gofuzz
+
+package influx
+
+import "github.com/influxdata/telegraf/testutil"
+
+// Fuzz is the entry point for go-fuzz.
+func Fuzz(data []byte) int {
+	return testutil.FuzzParser(NewParser(NewMetricHandler()), data)
+}
@@ -1,6 +1,7 @@
 package influx
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -56,6 +57,13 @@ func (e *ParseError) Error() string {
 	return fmt.Sprintf("metric parse error: %s at %d:%d: %q", e.msg, e.LineNumber, e.Column, buffer)
 }
 
+// metricHandler is a Handler producing telegraf.Metric.
+type metricHandler interface {
+	Handler
+	Metric() (telegraf.Metric, error)
+	SetTimeFunc(f TimeFunc)
+}
+
 // Parser is an InfluxDB Line Protocol parser that implements the
 // parsers.Parser interface.
 type Parser struct {
@@ -63,7 +71,8 @@ type Parser struct {
 
 	sync.Mutex
 	*machine
-	handler *MetricHandler
+	handler metricHandler
+	fast    *FastMetricHandler
 }
 
 // NewParser returns a Parser than accepts line protocol
@@ -82,6 +91,19 @@ func NewSeriesParser(handler *MetricHandler) *Parser {
 	}
 }
 
+// NewFastParser returns a Parser that accepts line protocol using the
+// FastMetricHandler. It allocates considerably less than the default parser
+// at the expense of keeping the parsed input in memory as long as any of the
+// metrics is in use.
+func NewFastParser() *Parser {
+	handler := NewFastMetricHandler()
+	return &Parser{
+		machine: NewMachine(handler),
+		handler: handler,
+		fast:    handler,
+	}
+}
+
 func (p *Parser) SetTimeFunc(f TimeFunc) {
 	p.handler.SetTimeFunc(f)
 }
@@ -90,6 +112,10 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 	p.Lock()
 	defer p.Unlock()
 	metrics := make([]telegraf.Metric, 0)
+	if p.fast != nil {
+		input = p.fast.SetBuffer(input)
+		metrics = make([]telegraf.Metric, 0, bytes.Count(input, []byte("\n"))+1)
+	}
 	p.machine.SetData(input)
 
 	for {
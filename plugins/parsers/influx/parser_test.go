@@ -602,6 +602,74 @@ func BenchmarkParser(b *testing.B) {
 	}
 }
 
+func TestFastParser(t *testing.T) {
+	for _, tt := range ptests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewFastParser()
+			parser.SetTimeFunc(DefaultTime)
+			if tt.timeFunc != nil {
+				parser.SetTimeFunc(tt.timeFunc)
+			}
+
+			metrics, err := parser.Parse(tt.input)
+			require.Equal(t, tt.err, err)
+
+			require.Equal(t, len(tt.metrics), len(metrics))
+			for i, expected := range tt.metrics {
+				require.Equal(t, expected.Name(), metrics[i].Name())
+				require.Equal(t, expected.Tags(), metrics[i].Tags())
+				require.Equal(t, expected.Fields(), metrics[i].Fields())
+				require.Equal(t, expected.Time(), metrics[i].Time())
+			}
+		})
+	}
+}
+
+func TestFastParserOwnsInput(t *testing.T) {
+	input := []byte("cpu,host=a,cpu=cpu0 usage_idle=42,state=\"idle\" 42\ncpu,host=b value=43i 43\n")
+	expected := []telegraf.Metric{
+		metric.New("cpu",
+			map[string]string{"cpu": "cpu0", "host": "a"},
+			map[string]interface{}{"usage_idle": 42.0, "state": "idle"},
+			time.Unix(0, 42),
+		),
+		metric.New("cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"value": int64(43)},
+			time.Unix(0, 43),
+		),
+	}
+
+	parser := NewFastParser()
+	metrics, err := parser.Parse(input)
+	require.NoError(t, err)
+
+	// Reusing the input must not modify the metrics.
+	for i := range input {
+		input[i] = 'x'
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	// Modifying a metric must not modify its neighbours.
+	metrics[0].AddTag("zone", "eu")
+	metrics[0].AddField("user", 1.0)
+	metrics[0].RemoveTag("cpu")
+	testutil.RequireMetricsEqual(t, expected[1:], metrics[1:])
+}
+
+func BenchmarkFastParser(b *testing.B) {
+	for _, tt := range ptests {
+		b.Run(tt.name, func(b *testing.B) {
+			parser := NewFastParser()
+			for n := 0; n < b.N; n++ {
+				metrics, err := parser.Parse(tt.input)
+				_ = err
+				_ = metrics
+			}
+		})
+	}
+}
+
 func TestStreamParser(t *testing.T) {
 	for _, tt := range ptests {
 		t.Run(tt.name, func(t *testing.T) {
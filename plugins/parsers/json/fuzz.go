@@ -0,0 +1,15 @@
+//go:build gofuzz
+// +build gofuzz
+
+package json
+
+import "github.com/influxdata/telegraf/testutil"
+
+// Fuzz is the entry point for go-fuzz.
+func Fuzz(data []byte) int {
+	parser, err := New(&Config{MetricName: "fuzz", Strict: true})
+	if err != nil {
+		panic(err)
+	}
+	return testutil.FuzzParser(parser, data)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
 	"time"
 
@@ -19,6 +20,20 @@ import (
 var (
 	utf8BOM      = []byte("\xef\xbb\xbf")
 	ErrWrongType = errors.New("must be an object or an array of objects")
+
+	errTooManySets = fmt.Errorf("exploding arrays creates more than %d metrics", maxExplodedSets)
+)
+
+const (
+	// ArrayModeIndex flattens arrays into fields suffixed by the element index.
+	ArrayModeIndex = "index"
+	// ArrayModeExplode creates a separate metric for every array element.
+	ArrayModeExplode = "explode"
+
+	// maxExplodedSets limits the number of field sets, and thus metrics,
+	// created from a single document in ArrayModeExplode, as multiple arrays
+	// multiply the number of sets.
+	maxExplodedSets = 10000
 )
 
 type Config struct {
@@ -32,6 +47,21 @@ type Config struct {
 	Timezone     string
 	DefaultTags  map[string]string
 	Strict       bool
+	// TimeFormats are tried in order if the time does not match TimeFormat.
+	TimeFormats []string
+	// TimeFallbackToNow uses the current time if the time key is missing or
+	// does not match any of the formats.
+	TimeFallbackToNow bool
+	// Lines treats every line of the input as a separate JSON document.
+	Lines bool
+
+	// FlattenSeparator joins the keys of nested objects, defaults to "_".
+	FlattenSeparator string
+	// FlattenMaxDepth limits the flattening of nested objects and arrays,
+	// deeper values are kept as JSON encoded strings. Zero means unlimited.
+	FlattenMaxDepth int
+	// ArrayMode is either ArrayModeIndex (default) or ArrayModeExplode.
+	ArrayMode string
 }
 
 type Parser struct {
@@ -41,10 +71,14 @@ type Parser struct {
 	nameKey      string
 	query        string
 	timeKey      string
-	timeFormat   string
-	timezone     string
+	timeParser   *internal.TimestampParser
 	defaultTags  map[string]string
 	strict       bool
+	lines        bool
+
+	flattenSeparator string
+	flattenMaxDepth  int
+	arrayMode        string
 }
 
 func New(config *Config) (*Parser, error) {
@@ -58,6 +92,17 @@ func New(config *Config) (*Parser, error) {
 		return nil, err
 	}
 
+	switch config.ArrayMode {
+	case "", ArrayModeIndex, ArrayModeExplode:
+	default:
+		return nil, fmt.Errorf("invalid array mode %q", config.ArrayMode)
+	}
+	if config.FlattenMaxDepth < 0 {
+		return nil, fmt.Errorf("invalid flatten depth %d", config.FlattenMaxDepth)
+	}
+
+	timeFormats := append([]string{config.TimeFormat}, config.TimeFormats...)
+
 	return &Parser{
 		metricName:   config.MetricName,
 		tagKeys:      tagKeyFilter,
@@ -65,10 +110,14 @@ func New(config *Config) (*Parser, error) {
 		stringFields: stringFilter,
 		query:        config.Query,
 		timeKey:      config.TimeKey,
-		timeFormat:   config.TimeFormat,
-		timezone:     config.Timezone,
+		timeParser:   internal.NewTimestampParser(timeFormats, config.Timezone, config.TimeFallbackToNow),
 		defaultTags:  config.DefaultTags,
 		strict:       config.Strict,
+		lines:        config.Lines,
+
+		flattenSeparator: config.FlattenSeparator,
+		flattenMaxDepth:  config.FlattenMaxDepth,
+		arrayMode:        config.ArrayMode,
 	}, nil
 }
 
@@ -95,22 +144,49 @@ func (p *Parser) parseArray(data []interface{}, timestamp time.Time) ([]telegraf
 }
 
 func (p *Parser) parseObject(data map[string]interface{}, timestamp time.Time) ([]telegraf.Metric, error) {
+	f := JSONFlattener{
+		Separator: p.flattenSeparator,
+		MaxDepth:  p.flattenMaxDepth,
+	}
+
+	var fieldSets []map[string]interface{}
+	if p.arrayMode == ArrayModeExplode {
+		var err error
+		fieldSets, err = f.ExplodeJSON("", data, true, true)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err := f.FullFlattenJSON("", data, true, true)
+		if err != nil {
+			return nil, err
+		}
+		fieldSets = []map[string]interface{}{f.Fields}
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(fieldSets))
+	for _, fields := range fieldSets {
+		m, err := p.newMetric(fields, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// newMetric creates a metric from the flattened fields of an object.
+func (p *Parser) newMetric(fields map[string]interface{}, timestamp time.Time) (telegraf.Metric, error) {
 	tags := make(map[string]string)
 	for k, v := range p.defaultTags {
 		tags[k] = v
 	}
 
-	f := JSONFlattener{}
-	err := f.FullFlattenJSON("", data, true, true)
-	if err != nil {
-		return nil, err
-	}
-
 	name := p.metricName
 
 	// checks if json_name_key is set
 	if p.nameKey != "" {
-		switch field := f.Fields[p.nameKey].(type) {
+		switch field := fields[p.nameKey].(type) {
 		case string:
 			name = field
 		}
@@ -118,22 +194,22 @@ func (p *Parser) parseObject(data map[string]interface{}, timestamp time.Time) (
 
 	// if time key is specified, set timestamp to it
 	if p.timeKey != "" {
-		if p.timeFormat == "" {
+		if len(p.timeParser.Formats) == 0 {
 			err := fmt.Errorf("use of 'json_time_key' requires 'json_time_format'")
 			return nil, err
 		}
 
-		if f.Fields[p.timeKey] == nil {
-			err := fmt.Errorf("JSON time key could not be found")
-			return nil, err
+		var err error
+		if fields[p.timeKey] == nil {
+			timestamp, err = p.timeParser.Missing(fmt.Errorf("JSON time key could not be found"))
+		} else {
+			timestamp, err = p.timeParser.Parse(fields[p.timeKey])
 		}
-
-		timestamp, err = internal.ParseTimestamp(p.timeFormat, f.Fields[p.timeKey], p.timezone)
 		if err != nil {
 			return nil, err
 		}
 
-		delete(f.Fields, p.timeKey)
+		delete(fields, p.timeKey)
 
 		// if the year is 0, set to current year
 		if timestamp.Year() == 0 {
@@ -141,10 +217,8 @@ func (p *Parser) parseObject(data map[string]interface{}, timestamp time.Time) (
 		}
 	}
 
-	tags, nFields := p.switchFieldToTag(tags, f.Fields)
-	m := metric.New(name, tags, nFields, timestamp)
-
-	return []telegraf.Metric{m}, nil
+	tags, nFields := p.switchFieldToTag(tags, fields)
+	return metric.New(name, tags, nFields, timestamp), nil
 }
 
 // will take in field map with strings and bools,
@@ -190,6 +264,44 @@ func (p *Parser) switchFieldToTag(tags map[string]string, fields map[string]inte
 }
 
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if p.lines {
+		return p.parseLines(buf)
+	}
+	return p.parseDocument(buf)
+}
+
+// parseLines parses every line of the buffer as a separate JSON document.
+// Lines failing to parse are skipped, an error is only returned if none of
+// the lines could be parsed.
+func (p *Parser) parseLines(buf []byte) ([]telegraf.Metric, error) {
+	results := make([]telegraf.Metric, 0)
+
+	var firstErr error
+	var parsed bool
+	for i, line := range bytes.Split(buf, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		metrics, err := p.parseDocument(line)
+		if err != nil {
+			log.Printf("W! [parsers.json] Skipping line %d: %v", i+1, err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("line %d: %w", i+1, err)
+			}
+			continue
+		}
+		parsed = true
+		results = append(results, metrics...)
+	}
+
+	if !parsed && firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+func (p *Parser) parseDocument(buf []byte) ([]telegraf.Metric, error) {
 	if p.query != "" {
 		result := gjson.GetBytes(buf, p.query)
 		buf = []byte(result.Raw)
@@ -247,6 +359,13 @@ func (p *Parser) SetDefaultTags(tags map[string]string) {
 
 type JSONFlattener struct {
 	Fields map[string]interface{}
+
+	// Separator joins the keys of nested values, defaults to "_".
+	Separator string
+	// MaxDepth limits the flattening of nested objects and arrays. Deeper
+	// values are stored as JSON encoded strings if strings are converted
+	// and dropped otherwise. Zero means unlimited.
+	MaxDepth int
 }
 
 // FlattenJSON flattens nested maps/interfaces into a fields map (ignoring bools and string)
@@ -271,41 +390,49 @@ func (f *JSONFlattener) FullFlattenJSON(
 		f.Fields = make(map[string]interface{})
 	}
 
+	return f.flatten(f.Fields, fieldname, v, 0, convertString, convertBool)
+}
+
+func (f *JSONFlattener) flatten(
+	fields map[string]interface{},
+	fieldname string,
+	v interface{},
+	depth int,
+	convertString bool,
+	convertBool bool,
+) error {
 	switch t := v.(type) {
 	case map[string]interface{}:
+		if f.truncate(fields, fieldname, v, depth, convertString) {
+			return nil
+		}
 		for k, v := range t {
-			fieldkey := k
-			if fieldname != "" {
-				fieldkey = fieldname + "_" + fieldkey
-			}
-
-			err := f.FullFlattenJSON(fieldkey, v, convertString, convertBool)
+			err := f.flatten(fields, f.key(fieldname, k), v, depth+1, convertString, convertBool)
 			if err != nil {
 				return err
 			}
 		}
 	case []interface{}:
+		if f.truncate(fields, fieldname, v, depth, convertString) {
+			return nil
+		}
 		for i, v := range t {
-			fieldkey := strconv.Itoa(i)
-			if fieldname != "" {
-				fieldkey = fieldname + "_" + fieldkey
-			}
-			err := f.FullFlattenJSON(fieldkey, v, convertString, convertBool)
+			err := f.flatten(fields, f.key(fieldname, strconv.Itoa(i)), v, depth+1, convertString, convertBool)
 			if err != nil {
 				return nil
 			}
 		}
 	case float64:
-		f.Fields[fieldname] = t
+		fields[fieldname] = t
 	case string:
 		if convertString {
-			f.Fields[fieldname] = v.(string)
+			fields[fieldname] = v.(string)
 		} else {
 			return nil
 		}
 	case bool:
 		if convertBool {
-			f.Fields[fieldname] = v.(bool)
+			fields[fieldname] = v.(bool)
 		} else {
 			return nil
 		}
@@ -317,3 +444,125 @@ func (f *JSONFlattener) FullFlattenJSON(
 	}
 	return nil
 }
+
+// ExplodeJSON flattens nested maps like FullFlattenJSON but returns a separate
+// set of fields for every element of the arrays found. Fields outside of the
+// arrays are part of all sets, multiple arrays result in all combinations of
+// their elements.
+func (f *JSONFlattener) ExplodeJSON(
+	fieldname string,
+	v interface{},
+	convertString bool,
+	convertBool bool,
+) ([]map[string]interface{}, error) {
+	return f.explode(fieldname, v, 0, convertString, convertBool)
+}
+
+func (f *JSONFlattener) explode(
+	fieldname string,
+	v interface{},
+	depth int,
+	convertString bool,
+	convertBool bool,
+) ([]map[string]interface{}, error) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		fields := make(map[string]interface{})
+		if f.truncate(fields, fieldname, v, depth, convertString) {
+			return []map[string]interface{}{fields}, nil
+		}
+
+		// Sort the keys to produce the combinations in a stable order
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		result := []map[string]interface{}{fields}
+		for _, k := range keys {
+			sets, err := f.explode(f.key(fieldname, k), t[k], depth+1, convertString, convertBool)
+			if err != nil {
+				return nil, err
+			}
+			if result, err = combineFields(result, sets); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case []interface{}:
+		fields := make(map[string]interface{})
+		if f.truncate(fields, fieldname, v, depth, convertString) {
+			return []map[string]interface{}{fields}, nil
+		}
+
+		result := make([]map[string]interface{}, 0, len(t))
+		for _, v := range t {
+			sets, err := f.explode(fieldname, v, depth+1, convertString, convertBool)
+			if err != nil {
+				return nil, err
+			}
+			if len(result)+len(sets) > maxExplodedSets {
+				return nil, errTooManySets
+			}
+			result = append(result, sets...)
+		}
+		if len(result) == 0 {
+			result = append(result, fields)
+		}
+		return result, nil
+	default:
+		fields := make(map[string]interface{})
+		if err := f.flatten(fields, fieldname, v, depth, convertString, convertBool); err != nil {
+			return nil, err
+		}
+		return []map[string]interface{}{fields}, nil
+	}
+}
+
+// combineFields returns the union of every set in a with every set in b.
+func combineFields(a, b []map[string]interface{}) ([]map[string]interface{}, error) {
+	if len(a)*len(b) > maxExplodedSets {
+		return nil, errTooManySets
+	}
+	result := make([]map[string]interface{}, 0, len(a)*len(b))
+	for _, sa := range a {
+		for _, sb := range b {
+			fields := make(map[string]interface{}, len(sa)+len(sb))
+			for k, v := range sa {
+				fields[k] = v
+			}
+			for k, v := range sb {
+				fields[k] = v
+			}
+			result = append(result, fields)
+		}
+	}
+	return result, nil
+}
+
+// key joins the name of the parent with the key of a nested value.
+func (f *JSONFlattener) key(fieldname, key string) string {
+	if fieldname == "" {
+		return key
+	}
+	separator := f.Separator
+	if separator == "" {
+		separator = "_"
+	}
+	return fieldname + separator + key
+}
+
+// truncate stores the value as JSON encoded string if the maximum depth is
+// reached and returns true in this case.
+func (f *JSONFlattener) truncate(fields map[string]interface{}, fieldname string, v interface{}, depth int, convertString bool) bool {
+	if f.MaxDepth <= 0 || depth < f.MaxDepth {
+		return false
+	}
+	if convertString {
+		if buf, err := json.Marshal(v); err == nil {
+			fields[fieldname] = string(buf)
+		}
+	}
+	return true
+}
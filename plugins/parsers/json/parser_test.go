@@ -2,6 +2,8 @@ package json
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -816,6 +818,32 @@ func TestTimeErrors(t *testing.T) {
 	require.Equal(t, fmt.Errorf("JSON time key could not be found"), err)
 }
 
+func TestTimeFormatsFallback(t *testing.T) {
+	now := time.Now()
+	parser, err := New(&Config{
+		MetricName:        "json_test",
+		TimeKey:           "time",
+		TimeFormat:        "rfc3339",
+		TimeFormats:       []string{"unix_ms"},
+		TimeFallbackToNow: true,
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte(`[
+		{"value": 1, "time": "2021-03-04T05:06:07Z"},
+		{"value": 2, "time": 1614834367000},
+		{"value": 3, "time": "invalid"},
+		{"value": 4}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 4)
+	expected := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.True(t, expected.Equal(metrics[0].Time()))
+	require.True(t, expected.Equal(metrics[1].Time()))
+	require.False(t, metrics[2].Time().Before(now))
+	require.False(t, metrics[3].Time().Before(now))
+}
+
 func TestShareTimestamp(t *testing.T) {
 	parser, err := New(&Config{
 		MetricName: "json_test",
@@ -1370,3 +1398,144 @@ func TestParseArrayWithWildcardTagKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLines(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName: "json_lines_test",
+		Lines:      true,
+	})
+	require.NoError(t, err)
+
+	input := []byte(`{"a": 1}
+{"a": 2, "b": {"c": 3}}
+
+{"a": 4,
+[{"a": 5}, {"a": 6}]
+`)
+	actual, err := parser.Parse(input)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("json_lines_test", map[string]string{}, map[string]interface{}{"a": float64(1)}, time.Unix(0, 0)),
+		testutil.MustMetric("json_lines_test", map[string]string{}, map[string]interface{}{"a": float64(2), "b_c": float64(3)}, time.Unix(0, 0)),
+		testutil.MustMetric("json_lines_test", map[string]string{}, map[string]interface{}{"a": float64(5)}, time.Unix(0, 0)),
+		testutil.MustMetric("json_lines_test", map[string]string{}, map[string]interface{}{"a": float64(6)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestParseLinesAllInvalid(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName: "json_lines_test",
+		Lines:      true,
+	})
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte("{\"a\": \n\"b\"\n"))
+	require.Error(t, err)
+
+	// Without lines mode a multi-line document is still parsed as a whole
+	parser, err = New(&Config{MetricName: "json_lines_test"})
+	require.NoError(t, err)
+	_, err = parser.Parse([]byte("{\"a\": 1}\n{\"a\": 2}\n"))
+	require.Error(t, err)
+}
+
+func TestParseFlattenOptions(t *testing.T) {
+	input := []byte(`{"a": {"b": {"c": 1}}, "d": [1, 2], "e": 3}`)
+
+	tests := []struct {
+		name     string
+		config   *Config
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "separator",
+			config: &Config{MetricName: "json_test", FlattenSeparator: "."},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("json_test", map[string]string{},
+					map[string]interface{}{"a.b.c": float64(1), "d.0": float64(1), "d.1": float64(2), "e": float64(3)},
+					time.Unix(0, 0)),
+			},
+		},
+		{
+			name: "max depth",
+			config: &Config{
+				MetricName:      "json_test",
+				FlattenMaxDepth: 2,
+				StringFields:    []string{"a_b"},
+			},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("json_test", map[string]string{},
+					map[string]interface{}{"a_b": `{"c":1}`, "d_0": float64(1), "d_1": float64(2), "e": float64(3)},
+					time.Unix(0, 0)),
+			},
+		},
+		{
+			name:   "explode",
+			config: &Config{MetricName: "json_test", ArrayMode: ArrayModeExplode},
+			expected: []telegraf.Metric{
+				testutil.MustMetric("json_test", map[string]string{},
+					map[string]interface{}{"a_b_c": float64(1), "d": float64(1), "e": float64(3)},
+					time.Unix(0, 0)),
+				testutil.MustMetric("json_test", map[string]string{},
+					map[string]interface{}{"a_b_c": float64(1), "d": float64(2), "e": float64(3)},
+					time.Unix(0, 0)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := New(tt.config)
+			require.NoError(t, err)
+
+			actual, err := parser.Parse(input)
+			require.NoError(t, err)
+
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestParseExplodeArrayOfObjects(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName: "json_test",
+		TagKeys:    []string{"host", "disks_name"},
+		ArrayMode:  ArrayModeExplode,
+	})
+	require.NoError(t, err)
+
+	input := []byte(`{"host": "a", "disks": [{"name": "sda", "used": 1}, {"name": "sdb", "used": 2}]}`)
+	actual, err := parser.Parse(input)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("json_test", map[string]string{"host": "a", "disks_name": "sda"},
+			map[string]interface{}{"disks_used": float64(1)}, time.Unix(0, 0)),
+		testutil.MustMetric("json_test", map[string]string{"host": "a", "disks_name": "sdb"},
+			map[string]interface{}{"disks_used": float64(2)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+}
+
+func TestParseInvalidArrayMode(t *testing.T) {
+	_, err := New(&Config{MetricName: "json_test", ArrayMode: "zip"})
+	require.Error(t, err)
+}
+
+func TestParseExplodeLimit(t *testing.T) {
+	parser, err := New(&Config{MetricName: "json_test", ArrayMode: ArrayModeExplode})
+	require.NoError(t, err)
+
+	// Three arrays of 100 elements combine into a million metrics
+	elements := make([]string, 100)
+	for i := range elements {
+		elements[i] = strconv.Itoa(i)
+	}
+	array := "[" + strings.Join(elements, ",") + "]"
+	input := []byte(`{"a": ` + array + `, "b": ` + array + `, "c": ` + array + `}`)
+
+	_, err = parser.Parse(input)
+	require.ErrorIs(t, err, errTooManySets)
+}
@@ -0,0 +1,64 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/influxdata/telegraf"
+)
+
+// StreamParser parses newline delimited JSON documents from a reader one
+// document at a time.  It is not safe for concurrent use in multiple
+// goroutines.
+type StreamParser struct {
+	parser  *Parser
+	reader  *bufio.Reader
+	pending []telegraf.Metric
+	line    int
+}
+
+// NewStreamParser returns a StreamParser reading JSON documents from r using
+// the settings of the given parser.
+func NewStreamParser(parser *Parser, r io.Reader) *StreamParser {
+	return &StreamParser{
+		parser: parser,
+		reader: bufio.NewReader(r),
+	}
+}
+
+// Next returns the next metric from the stream.  io.EOF is returned once the
+// stream is exhausted.  If a line cannot be parsed the error is returned and
+// the next call continues with the following line.
+func (sp *StreamParser) Next() (telegraf.Metric, error) {
+	for len(sp.pending) == 0 {
+		buf, err := sp.reader.ReadBytes('\n')
+		if len(buf) == 0 && err != nil {
+			return nil, err
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		sp.line++
+
+		if len(bytes.TrimSpace(buf)) == 0 {
+			continue
+		}
+
+		metrics, err := sp.parser.Parse(buf)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", sp.line, err)
+		}
+		sp.pending = metrics
+	}
+
+	m := sp.pending[0]
+	sp.pending = sp.pending[1:]
+	return m, nil
+}
+
+// LineNumber returns the number of the line last read.
+func (sp *StreamParser) LineNumber() int {
+	return sp.line
+}
@@ -0,0 +1,68 @@
+package json
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestStreamParser(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName: "json_test",
+		TagKeys:    []string{"host"},
+	})
+	require.NoError(t, err)
+
+	input := `{"host": "a", "value": 1}
+
+[{"host": "b", "value": 2}, {"host": "c", "value": 3}]
+{"host": "d", "value": 4}`
+	sp := NewStreamParser(parser, strings.NewReader(input))
+
+	var actual []telegraf.Metric
+	for {
+		m, err := sp.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		actual = append(actual, m)
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("json_test", map[string]string{"host": "a"}, map[string]interface{}{"value": float64(1)}, time.Unix(0, 0)),
+		testutil.MustMetric("json_test", map[string]string{"host": "b"}, map[string]interface{}{"value": float64(2)}, time.Unix(0, 0)),
+		testutil.MustMetric("json_test", map[string]string{"host": "c"}, map[string]interface{}{"value": float64(3)}, time.Unix(0, 0)),
+		testutil.MustMetric("json_test", map[string]string{"host": "d"}, map[string]interface{}{"value": float64(4)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+	require.Equal(t, 4, sp.LineNumber())
+}
+
+func TestStreamParserContinuesAfterError(t *testing.T) {
+	parser, err := New(&Config{MetricName: "json_test"})
+	require.NoError(t, err)
+
+	sp := NewStreamParser(parser, strings.NewReader("{\"value\": 1}\n{\"value\": \n{\"value\": 3}\n"))
+
+	m, err := sp.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": float64(1)}, m.Fields())
+
+	_, err = sp.Next()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 2")
+
+	m, err = sp.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"value": float64(3)}, m.Fields())
+
+	_, err = sp.Next()
+	require.Equal(t, io.EOF, err)
+}
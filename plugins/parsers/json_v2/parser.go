@@ -141,13 +141,16 @@ func (p *Parser) Parse(input []byte) ([]telegraf.Metric, error) {
 			return nil, err
 		}
 
-		metrics = append(metrics, cartesianProduct(tags, fields)...)
+		// Only combine the results of the current config, every config
+		// produces its own set of metrics
+		configMetrics := cartesianProduct(tags, fields)
 
-		if len(objects) != 0 && len(metrics) != 0 {
-			metrics = cartesianProduct(objects, metrics)
+		if len(objects) != 0 && len(configMetrics) != 0 {
+			configMetrics = cartesianProduct(objects, configMetrics)
 		} else {
-			metrics = append(metrics, objects...)
+			configMetrics = append(configMetrics, objects...)
 		}
+		metrics = append(metrics, configMetrics...)
 	}
 
 	for k, v := range p.DefaultTags {
@@ -0,0 +1,128 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParseLines(t *testing.T) {
+	expected := []telegraf.Metric{
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 1),
+		),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"value": 43.0},
+			time.Unix(0, 2),
+		),
+	}
+
+	tests := []struct {
+		name   string
+		config *Config
+		lines  []string
+	}{
+		{
+			name:   "adapter",
+			config: &Config{DataFormat: "influx"},
+			lines:  []string{"cpu,host=a value=42 1", "cpu,host=b value=43 2"},
+		},
+		{
+			name: "batch",
+			config: &Config{
+				DataFormat:   "grok",
+				MetricName:   "cpu",
+				GrokPatterns: []string{`^%{WORD:host:tag} %{NUMBER:value:float} %{NUMBER:ts:ts-epochnano}$`},
+			},
+			lines: []string{"a 42 1", "no match", "b 43 2"},
+		},
+		{
+			name: "wrapped batch",
+			config: &Config{
+				DataFormat:   "grok",
+				MetricName:   "cpu",
+				GrokPatterns: []string{`^%{NUMBER:value} %{NUMBER:ts:ts-epochnano}$`},
+				DefaultTags:  map[string]string{"host": "{{ if gt .fields.value 42.5 }}b{{ else }}a{{ end }}"},
+				FieldTypes:   map[string]string{"value": "float"},
+			},
+			lines: []string{"42 1", "43 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(tt.config)
+			require.NoError(t, err)
+
+			metrics, err := ParseLines(parser, tt.lines)
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, expected, metrics)
+		})
+	}
+}
+
+func TestParseLinesError(t *testing.T) {
+	parser, err := NewParser(&Config{DataFormat: "influx"})
+	require.NoError(t, err)
+
+	metrics, err := ParseLines(parser, []string{"cpu value=42", "cpu value="})
+	require.Error(t, err)
+	require.Nil(t, metrics)
+}
+
+// batchParser counts the batches passed to the parser.
+type batchParser struct {
+	Parser
+	batches int
+}
+
+func (p *batchParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	p.batches++
+	return ParseLines(p.Parser, lines)
+}
+
+func TestParseLinesWrapped(t *testing.T) {
+	inner, err := NewParser(&Config{DataFormat: "influx"})
+	require.NoError(t, err)
+	batch := &batchParser{Parser: inner}
+
+	var parser Parser = batch
+	parser, err = NewDecodingParser(parser, "utf-8")
+	require.NoError(t, err)
+	parser, err = NewDecompressingParser(parser, "identity", 0)
+	require.NoError(t, err)
+	parser, err = NewChainParser(parser, inner, "payload")
+	require.NoError(t, err)
+	parser, err = NewParseModeParser(parser, &Config{DataFormat: "influx", ParseMode: ParseModeStrict})
+	require.NoError(t, err)
+	parser = NewDeadLetterParser(parser, &Config{DataFormat: "influx"})
+
+	// All wrappers pass the batch on at once
+	metrics, err := ParseLines(parser, []string{"cpu value=42 1", "cpu value=43 2"})
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, 1, batch.batches)
+}
+
+func TestParseLinesInvalidLines(t *testing.T) {
+	lines := []string{"cpu value=42 1", "cpu value=", "cpu value=43 2"}
+
+	parser, err := NewParser(&Config{DataFormat: "influx", ParseMode: ParseModeBestEffort})
+	require.NoError(t, err)
+	metrics, err := ParseLines(parser, lines)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	parser, err = NewParser(&Config{DataFormat: "influx", ParseErrorsMeasurement: "parse_error"})
+	require.NoError(t, err)
+	metrics, err = ParseLines(parser, lines)
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	require.Equal(t, "parse_error", metrics[1].Name())
+}
@@ -0,0 +1,154 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// MeasurementParser wraps a parser to name the parsed metrics after their
+// content, either using the value of a field or tag or by evaluating a Go
+// template, e.g. '{{ .tags.source }}_{{ .fields.type }}'. Metrics keep their
+// name if the key is missing or the template evaluates to an empty string.
+type MeasurementParser struct {
+	Parser Parser
+
+	key      string
+	template *template.Template
+}
+
+// NewMeasurementParser wraps the parser to take the measurement name from the
+// field or tag of the given key, which is removed from the metric, or from the
+// given template. Only one of both may be set.
+func NewMeasurementParser(parser Parser, key, tmpl string) (*MeasurementParser, error) {
+	if key != "" && tmpl != "" {
+		return nil, errors.New("measurement_key and measurement_template are mutually exclusive")
+	}
+
+	p := &MeasurementParser{Parser: parser, key: key}
+	if tmpl != "" {
+		text := envTemplateRe.ReplaceAllString(tmpl, `{{ env "$1" }}`)
+		t, err := template.New("measurement").Funcs(tagTemplateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing measurement template failed: %w", err)
+		}
+		p.template = t
+	}
+	return p, nil
+}
+
+func (p *MeasurementParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := p.Parser.Parse(buf)
+	if aerr := p.applyAll(metrics); aerr != nil {
+		return nil, aerr
+	}
+	return metrics, err
+}
+
+func (p *MeasurementParser) ParseLine(line string) (telegraf.Metric, error) {
+	m, err := p.Parser.ParseLine(line)
+	if m != nil {
+		if aerr := p.apply(m); aerr != nil {
+			return nil, aerr
+		}
+	}
+	return m, err
+}
+
+func (p *MeasurementParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	metrics, err := ParseLines(p.Parser, lines)
+	if aerr := p.applyAll(metrics); aerr != nil {
+		return nil, aerr
+	}
+	return metrics, err
+}
+
+func (p *MeasurementParser) SetDefaultTags(tags map[string]string) {
+	p.Parser.SetDefaultTags(tags)
+}
+
+func (p *MeasurementParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *MeasurementParser) IsMultiline() bool {
+	return isMultiline(p.Parser)
+}
+
+func (p *MeasurementParser) IsNewLogLine(line string) bool {
+	return isNewLogLine(p.Parser, line)
+}
+
+func (p *MeasurementParser) MultilineFlushTimeout() time.Duration {
+	return multilineFlushTimeout(p.Parser)
+}
+
+func (p *MeasurementParser) MultilineLineLimit() int {
+	return multilineLineLimit(p.Parser)
+}
+
+func (p *MeasurementParser) process(m telegraf.Metric) ([]telegraf.Metric, error) {
+	if err := p.apply(m); err != nil {
+		return nil, err
+	}
+	return []telegraf.Metric{m}, nil
+}
+
+func (p *MeasurementParser) applyAll(metrics []telegraf.Metric) error {
+	for _, m := range metrics {
+		if err := p.apply(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *MeasurementParser) apply(m telegraf.Metric) error {
+	if p.template == nil {
+		p.applyKey(m)
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"name":   m.Name(),
+		"tags":   m.Tags(),
+		"fields": m.Fields(),
+	}
+	var b strings.Builder
+	if err := p.template.Execute(&b, data); err != nil {
+		return fmt.Errorf("evaluating measurement template failed: %w", err)
+	}
+	name := b.String()
+	if name == "" || strings.Contains(name, "<no value>") {
+		return nil
+	}
+	m.SetName(name)
+	return nil
+}
+
+// applyKey names the metric after the field or, if there is no such field,
+// the tag of the configured key. The field is kept if it is the only one, as
+// metrics without fields are invalid.
+func (p *MeasurementParser) applyKey(m telegraf.Metric) {
+	if v, found := m.GetField(p.key); found {
+		name, err := internal.ToString(v)
+		if err != nil || name == "" {
+			return
+		}
+		m.SetName(name)
+		if len(m.FieldList()) > 1 {
+			m.RemoveField(p.key)
+		}
+		return
+	}
+
+	if name, found := m.GetTag(p.key); found && name != "" {
+		m.SetName(name)
+		m.RemoveTag(p.key)
+	}
+}
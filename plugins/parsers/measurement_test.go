@@ -0,0 +1,148 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestMeasurementParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		input    string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "key from json field",
+			config: &Config{
+				DataFormat:       "json",
+				MetricName:       "json",
+				TagKeys:          []string{"host"},
+				JSONStringFields: []string{"name"},
+				MeasurementKey:   "name",
+			},
+			input: `[{"name": "cpu", "host": "a", "value": 42}, {"host": "b", "value": 43}]`,
+			expected: []telegraf.Metric{
+				testutil.MustMetric("cpu",
+					map[string]string{"host": "a"},
+					map[string]interface{}{"value": 42.0},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric("json",
+					map[string]string{"host": "b"},
+					map[string]interface{}{"value": 43.0},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "key from csv column",
+			config: &Config{
+				DataFormat:        "csv",
+				MetricName:        "csv",
+				CSVHeaderRowCount: 1,
+				CSVTagColumns:     []string{"kind"},
+				MeasurementKey:    "kind",
+			},
+			input: "kind,value\nmem,1\n",
+			expected: []telegraf.Metric{
+				testutil.MustMetric("mem",
+					map[string]string{},
+					map[string]interface{}{"value": int64(1)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "template",
+			config: &Config{
+				DataFormat:          "influx",
+				MeasurementTemplate: "{{ .name }}_{{ .tags.source }}",
+			},
+			input: "events,source=disk value=1 1\nevents value=2 2\n",
+			expected: []telegraf.Metric{
+				testutil.MustMetric("events_disk",
+					map[string]string{"source": "disk"},
+					map[string]interface{}{"value": 1.0},
+					time.Unix(0, 1),
+				),
+				testutil.MustMetric("events",
+					map[string]string{},
+					map[string]interface{}{"value": 2.0},
+					time.Unix(0, 2),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(tt.config)
+			require.NoError(t, err)
+
+			metrics, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.expected, metrics, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestMeasurementParserLine(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:     "influx",
+		MeasurementKey: "type",
+	})
+	require.NoError(t, err)
+
+	m, err := parser.ParseLine(`events type="login",user="alice" 1`)
+	require.NoError(t, err)
+	require.Equal(t, "login", m.Name())
+	require.Equal(t, map[string]interface{}{"user": "alice"}, m.Fields())
+}
+
+func TestMeasurementParserInvalid(t *testing.T) {
+	_, err := NewParser(&Config{
+		DataFormat:          "influx",
+		MeasurementKey:      "type",
+		MeasurementTemplate: "{{ .fields.type }}",
+	})
+	require.Error(t, err)
+
+	_, err = NewParser(&Config{
+		DataFormat:          "influx",
+		MeasurementTemplate: "{{ .fields.type",
+	})
+	require.Error(t, err)
+}
+
+func TestMeasurementParserOnlyKeyField(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:     "influx",
+		MeasurementKey: "type",
+	})
+	require.NoError(t, err)
+
+	// The key field is kept as metrics need at least one field
+	m, err := parser.ParseLine(`events type="login" 1`)
+	require.NoError(t, err)
+	require.Equal(t, "login", m.Name())
+	require.Equal(t, map[string]interface{}{"type": "login"}, m.Fields())
+}
+
+func TestMeasurementParserTemplateError(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:          "influx",
+		MeasurementTemplate: `{{ .fields.value.unit }}`,
+	})
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte("events value=1 1\n"))
+	require.Error(t, err)
+
+	_, err = parser.ParseLine("events value=1 1")
+	require.Error(t, err)
+}
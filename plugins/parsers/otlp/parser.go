@@ -0,0 +1,171 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"github.com/influxdata/influxdb-observability/otel2influx"
+	"go.opentelemetry.io/collector/model/otlp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+var metricsSchemata = map[string]common.MetricsSchema{
+	"prometheus-v1": common.MetricsSchemaTelegrafPrometheusV1,
+	"prometheus-v2": common.MetricsSchemaTelegrafPrometheusV2,
+}
+
+// Parser decodes OTLP metrics or logs export requests, encoded either as
+// protocol buffers or as JSON, into metrics. The conversion follows the
+// opentelemetry input plugin.
+type Parser struct {
+	// Signal is the type of the payload, either "metrics" or "logs".
+	Signal string
+	// Encoding of the payload, either "protobuf" or "json". The encoding is
+	// detected from the payload if empty.
+	Encoding string
+	// MetricsSchema is the schema metrics are converted to, either
+	// "prometheus-v1" or "prometheus-v2".
+	MetricsSchema string
+	DefaultTags   map[string]string
+
+	metrics *otel2influx.OtelMetricsToLineProtocol
+	logs    *otel2influx.OtelLogsToLineProtocol
+}
+
+// Init checks the config and creates the converters.
+func (p *Parser) Init() error {
+	switch p.Encoding {
+	case "", "protobuf", "json":
+	default:
+		return fmt.Errorf("invalid encoding %q", p.Encoding)
+	}
+
+	switch p.Signal {
+	case "", "metrics":
+		p.Signal = "metrics"
+		if p.MetricsSchema == "" {
+			p.MetricsSchema = "prometheus-v1"
+		}
+		schema, found := metricsSchemata[p.MetricsSchema]
+		if !found {
+			return fmt.Errorf("schema %q not recognized", p.MetricsSchema)
+		}
+		converter, err := otel2influx.NewOtelMetricsToLineProtocol(logger{}, schema)
+		if err != nil {
+			return err
+		}
+		p.metrics = converter
+	case "logs":
+		p.logs = otel2influx.NewOtelLogsToLineProtocol(logger{})
+	default:
+		return fmt.Errorf("invalid signal %q", p.Signal)
+	}
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if len(bytes.TrimSpace(buf)) == 0 {
+		return nil, nil
+	}
+
+	w := &metricWriter{defaultTags: p.DefaultTags}
+	ctx := context.Background()
+
+	jsonEncoded := p.isJSON(buf)
+	switch p.Signal {
+	case "metrics":
+		unmarshaler := otlp.NewProtobufMetricsUnmarshaler()
+		if jsonEncoded {
+			unmarshaler = otlp.NewJSONMetricsUnmarshaler()
+		}
+		md, err := unmarshaler.UnmarshalMetrics(buf)
+		if err != nil {
+			return nil, fmt.Errorf("decoding metrics failed: %w", err)
+		}
+		if err := p.metrics.WriteMetrics(ctx, md, w); err != nil {
+			return nil, err
+		}
+	case "logs":
+		unmarshaler := otlp.NewProtobufLogsUnmarshaler()
+		if jsonEncoded {
+			unmarshaler = otlp.NewJSONLogsUnmarshaler()
+		}
+		ld, err := unmarshaler.UnmarshalLogs(buf)
+		if err != nil {
+			return nil, fmt.Errorf("decoding logs failed: %w", err)
+		}
+		if err := p.logs.WriteLogs(ctx, ld, w); err != nil {
+			return nil, err
+		}
+	}
+	return w.metrics, nil
+}
+
+func (p *Parser) ParseLine(_ string) (telegraf.Metric, error) {
+	return nil, fmt.Errorf("parsing lines is not supported by the otlp data format")
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+// isJSON returns true for JSON payloads. A protocol buffer encoded export
+// request never starts with a curly brace as it would denote a group of field
+// 15, which is not part of the OTLP messages.
+func (p *Parser) isJSON(buf []byte) bool {
+	if p.Encoding != "" {
+		return p.Encoding == "json"
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(buf), []byte("{"))
+}
+
+// metricWriter collects the points written by the converters.
+type metricWriter struct {
+	defaultTags map[string]string
+	metrics     []telegraf.Metric
+}
+
+func (w *metricWriter) WritePoint(_ context.Context, measurement string, tags map[string]string, fields map[string]interface{}, ts time.Time, vType common.InfluxMetricValueType) error {
+	var tp telegraf.ValueType
+	switch vType {
+	case common.InfluxMetricValueTypeUntyped:
+		tp = telegraf.Untyped
+	case common.InfluxMetricValueTypeGauge:
+		tp = telegraf.Gauge
+	case common.InfluxMetricValueTypeSum:
+		tp = telegraf.Counter
+	case common.InfluxMetricValueTypeHistogram:
+		tp = telegraf.Histogram
+	case common.InfluxMetricValueTypeSummary:
+		tp = telegraf.Summary
+	default:
+		return fmt.Errorf("unrecognized InfluxMetricValueType %q", vType)
+	}
+
+	if tags == nil {
+		tags = make(map[string]string, len(w.defaultTags))
+	}
+	for k, v := range w.defaultTags {
+		if _, found := tags[k]; !found {
+			tags[k] = v
+		}
+	}
+	w.metrics = append(w.metrics, metric.New(measurement, tags, fields, ts, tp))
+	return nil
+}
+
+// logger forwards the conversion messages of the converters to the debug
+// log.
+type logger struct{}
+
+func (logger) Debug(msg string, kv ...interface{}) {
+	format := msg + strings.Repeat(" %s=%q", len(kv)/2)
+	log.Printf("D! [parsers.otlp] "+format, kv...)
+}
@@ -0,0 +1,158 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var testTime = time.Unix(1633089600, 0)
+
+func testMetrics() pdata.Metrics {
+	md := pdata.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("service.name", "checkout")
+	ilm := rm.InstrumentationLibraryMetrics().AppendEmpty()
+
+	m := ilm.Metrics().AppendEmpty()
+	m.SetName("cpu_temperature")
+	m.SetDataType(pdata.MetricDataTypeGauge)
+	dp := m.Gauge().DataPoints().AppendEmpty()
+	dp.Attributes().InsertString("core", "0")
+	dp.SetTimestamp(pdata.NewTimestampFromTime(testTime))
+	dp.SetDoubleVal(42.5)
+
+	m = ilm.Metrics().AppendEmpty()
+	m.SetName("requests")
+	m.SetDataType(pdata.MetricDataTypeSum)
+	m.Sum().SetIsMonotonic(true)
+	m.Sum().SetAggregationTemporality(pdata.AggregationTemporalityCumulative)
+	dp = m.Sum().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pdata.NewTimestampFromTime(testTime))
+	dp.SetIntVal(7)
+	return md
+}
+
+func testLogs() pdata.Logs {
+	ld := pdata.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("service.name", "checkout")
+	lr := rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	lr.SetTimestamp(pdata.NewTimestampFromTime(testTime))
+	lr.SetSeverityText("ERROR")
+	lr.SetSeverityNumber(pdata.SeverityNumberERROR)
+	lr.Body().SetStringVal("payment failed")
+	return ld
+}
+
+func expectedMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		testutil.MustMetric("cpu_temperature",
+			map[string]string{"service.name": "checkout", "core": "0"},
+			map[string]interface{}{"gauge": 42.5},
+			testTime,
+			telegraf.Gauge,
+		),
+		testutil.MustMetric("requests",
+			map[string]string{"service.name": "checkout"},
+			map[string]interface{}{"counter": int64(7)},
+			testTime,
+			telegraf.Counter,
+		),
+	}
+}
+
+func TestParseMetrics(t *testing.T) {
+	protobuf, err := otlp.NewProtobufMetricsMarshaler().MarshalMetrics(testMetrics())
+	require.NoError(t, err)
+	json, err := otlp.NewJSONMetricsMarshaler().MarshalMetrics(testMetrics())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		encoding string
+		payload  []byte
+	}{
+		{name: "protobuf", payload: protobuf},
+		{name: "json", payload: json},
+		{name: "explicit protobuf", encoding: "protobuf", payload: protobuf},
+		{name: "explicit json", encoding: "json", payload: json},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{Encoding: tt.encoding}
+			require.NoError(t, parser.Init())
+
+			metrics, err := parser.Parse(tt.payload)
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, expectedMetrics(), metrics, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestParseLogs(t *testing.T) {
+	protobuf, err := otlp.NewProtobufLogsMarshaler().MarshalLogs(testLogs())
+	require.NoError(t, err)
+	json, err := otlp.NewJSONLogsMarshaler().MarshalLogs(testLogs())
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("logs",
+			map[string]string{"service.name": "checkout"},
+			map[string]interface{}{"body": "payment failed", "severity_number": int64(17), "severity_text": "ERROR"},
+			testTime,
+		),
+	}
+
+	for _, payload := range [][]byte{protobuf, json} {
+		parser := &Parser{Signal: "logs"}
+		require.NoError(t, parser.Init())
+
+		metrics, err := parser.Parse(payload)
+		require.NoError(t, err)
+		testutil.RequireMetricsEqual(t, expected, metrics)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := &Parser{}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.Parse([]byte(`{"resourceMetrics": [`))
+	require.Error(t, err)
+	_, err = parser.Parse([]byte{0x0a, 0xff})
+	require.Error(t, err)
+	_, err = parser.ParseLine("cpu")
+	require.Error(t, err)
+
+	metrics, err := parser.Parse(nil)
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+}
+
+func TestInit(t *testing.T) {
+	require.Error(t, (&Parser{Signal: "traces"}).Init())
+	require.Error(t, (&Parser{Encoding: "xml"}).Init())
+	require.Error(t, (&Parser{MetricsSchema: "prometheus-v3"}).Init())
+	require.NoError(t, (&Parser{MetricsSchema: "prometheus-v2"}).Init())
+}
+
+func TestParserConformance(t *testing.T) {
+	protobuf, err := otlp.NewProtobufMetricsMarshaler().MarshalMetrics(testMetrics())
+	require.NoError(t, err)
+
+	testutil.RunParserConformance(t, testutil.ParserConformance{
+		New: func() (testutil.Parser, error) {
+			parser := &Parser{}
+			return parser, parser.Init()
+		},
+		Valid:   protobuf,
+		Invalid: protobuf[:len(protobuf)-4],
+	})
+}
@@ -0,0 +1,401 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	pq "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/types"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Config holds the options of the parquet parser.
+type Config struct {
+	MetricName        string
+	TagColumns        []string
+	MeasurementColumn string
+	TimestampColumn   string
+	TimestampFormat   string
+	Timezone          string
+	DefaultTags       map[string]string
+
+	// TimestampFormats are tried in order if the timestamp does not match
+	// TimestampFormat.
+	TimestampFormats []string
+	// TimestampFallbackToNow uses the current time if the timestamp is
+	// missing or does not match any of the formats.
+	TimestampFallbackToNow bool
+
+	TimeFunc func() time.Time
+}
+
+// Parser reads the rows of parquet files as metrics. Every row is turned
+// into a metric, the columns are added as fields unless they are configured
+// as tag, measurement or timestamp column. Nested columns are named by
+// joining the path with underscores, repeated columns are skipped.
+type Parser struct {
+	config     *Config
+	tagColumns map[string]bool
+	timeParser *internal.TimestampParser
+}
+
+// column is a leaf column of the parquet schema.
+type column struct {
+	index   int64
+	name    string
+	element *pq.SchemaElement
+}
+
+// New returns a parquet parser for the given config.
+func New(config *Config) (*Parser, error) {
+	if config.MetricName == "" && config.MeasurementColumn == "" {
+		return nil, fmt.Errorf("metric name or measurement column required")
+	}
+	if config.TimeFunc == nil {
+		config.TimeFunc = time.Now
+	}
+
+	tagColumns := make(map[string]bool, len(config.TagColumns))
+	for _, name := range config.TagColumns {
+		tagColumns[name] = true
+	}
+
+	var formats []string
+	if config.TimestampFormat != "" {
+		formats = append(formats, config.TimestampFormat)
+	}
+	formats = append(formats, config.TimestampFormats...)
+	timeParser := internal.NewTimestampParser(formats, config.Timezone, config.TimestampFallbackToNow)
+	timeParser.Now = func() time.Time { return config.TimeFunc() }
+
+	return &Parser{
+		config:     config,
+		tagColumns: tagColumns,
+		timeParser: timeParser,
+	}, nil
+}
+
+// Parse returns the metrics of all rows of the parquet file in buf.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if len(buf) == 0 {
+		return nil, nil
+	}
+
+	rgr, err := p.newRowGroupReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+	defer rgr.close()
+
+	var metrics []telegraf.Metric
+	for rgr.more() {
+		rowGroup, err := rgr.next()
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, rowGroup...)
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(_ string) (telegraf.Metric, error) {
+	return nil, fmt.Errorf("parsing lines is not supported by the parquet data format")
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.config.DefaultTags = tags
+}
+
+// rowGroupReader decodes a parquet file one row group at a time, so only
+// the columns of a single row group are held in memory.
+type rowGroupReader struct {
+	parser   *Parser
+	reader   *reader.ParquetReader
+	columns  []column
+	numRows  []int64
+	rowGroup int
+}
+
+func (p *Parser) newRowGroupReader(r io.ReaderAt, size int64) (rgr *rowGroupReader, err error) {
+	// The parquet library panics on some malformed files.
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("invalid parquet file: %v", v)
+		}
+	}()
+
+	pr, err := reader.NewParquetColumnReader(newReaderAtFile(r, size), 1)
+	if err != nil {
+		return nil, fmt.Errorf("reading parquet footer failed: %w", err)
+	}
+
+	columns, err := leafColumns(pr)
+	if err != nil {
+		pr.ReadStop()
+		return nil, err
+	}
+
+	rowGroups := pr.Footer.GetRowGroups()
+	numRows := make([]int64, 0, len(rowGroups))
+	for _, rowGroup := range rowGroups {
+		numRows = append(numRows, rowGroup.GetNumRows())
+	}
+
+	return &rowGroupReader{
+		parser:  p,
+		reader:  pr,
+		columns: columns,
+		numRows: numRows,
+	}, nil
+}
+
+// more returns true if there are row groups left.
+func (r *rowGroupReader) more() bool {
+	return r.rowGroup < len(r.numRows)
+}
+
+// next returns the metrics of the next row group.
+func (r *rowGroupReader) next() (metrics []telegraf.Metric, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("invalid parquet file: %v", v)
+		}
+	}()
+
+	numRows := r.numRows[r.rowGroup]
+	r.rowGroup++
+	if numRows <= 0 {
+		return nil, nil
+	}
+
+	values := make([][]interface{}, len(r.columns))
+	for i, c := range r.columns {
+		v, _, _, err := r.reader.ReadColumnByIndex(c.index, numRows)
+		if err != nil {
+			return nil, fmt.Errorf("reading column %q failed: %w", c.name, err)
+		}
+		if int64(len(v)) != numRows {
+			return nil, fmt.Errorf("column %q has %d values for %d rows", c.name, len(v), numRows)
+		}
+		values[i] = v
+	}
+
+	metrics = make([]telegraf.Metric, 0, numRows)
+	for row := int64(0); row < numRows; row++ {
+		m, err := r.parser.parseRow(r.columns, values, row)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+func (r *rowGroupReader) close() {
+	r.reader.ReadStop()
+}
+
+// readerAtFile is a read-only source.ParquetFile reading the file from an
+// io.ReaderAt. The parquet library opens the file once per column, these
+// share the reader but keep their own offset.
+type readerAtFile struct {
+	*io.SectionReader
+	r    io.ReaderAt
+	size int64
+}
+
+func newReaderAtFile(r io.ReaderAt, size int64) *readerAtFile {
+	return &readerAtFile{
+		SectionReader: io.NewSectionReader(r, 0, size),
+		r:             r,
+		size:          size,
+	}
+}
+
+func (f *readerAtFile) Open(_ string) (source.ParquetFile, error) {
+	return newReaderAtFile(f.r, f.size), nil
+}
+
+func (f *readerAtFile) Create(_ string) (source.ParquetFile, error) {
+	return nil, errors.New("parquet files are read-only")
+}
+
+func (f *readerAtFile) Write(_ []byte) (int, error) {
+	return 0, errors.New("parquet files are read-only")
+}
+
+func (f *readerAtFile) Close() error {
+	return nil
+}
+
+// leafColumns returns the non-repeated leaf columns of the file schema.
+func leafColumns(pr *reader.ParquetReader) ([]column, error) {
+	sh := pr.SchemaHandler
+	columns := make([]column, 0, len(sh.ValueColumns))
+	for i, inPath := range sh.ValueColumns {
+		path := strings.Split(inPath, "\x01")
+		rl, err := sh.MaxRepetitionLevel(path)
+		if err != nil {
+			return nil, err
+		}
+		if rl > 0 {
+			continue
+		}
+
+		exPath := strings.Split(sh.InPathToExPath[inPath], "\x01")
+		columns = append(columns, column{
+			index:   int64(i),
+			name:    strings.Join(exPath[1:], "_"),
+			element: sh.SchemaElements[sh.MapIndex[inPath]],
+		})
+	}
+	return columns, nil
+}
+
+func (p *Parser) parseRow(columns []column, values [][]interface{}, row int64) (telegraf.Metric, error) {
+	name := p.config.MetricName
+	tags := make(map[string]string)
+	fields := make(map[string]interface{})
+	var timestamp interface{}
+	var timestampElement *pq.SchemaElement
+
+	for i, c := range columns {
+		v := values[i][row]
+		if v == nil {
+			continue
+		}
+
+		switch {
+		case c.name == p.config.TimestampColumn:
+			timestamp = v
+			timestampElement = c.element
+		case c.name == p.config.MeasurementColumn:
+			name = fmt.Sprint(convert(v, c.element))
+		case p.tagColumns[c.name]:
+			tags[c.name] = fmt.Sprint(convert(v, c.element))
+		default:
+			fields[c.name] = convert(v, c.element)
+		}
+	}
+
+	// Rows without any field are skipped as there is nothing to record.
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf("row %d: missing measurement name", row)
+	}
+
+	var ts time.Time
+	var err error
+	switch {
+	case p.config.TimestampColumn == "":
+		ts = p.config.TimeFunc()
+	case timestamp == nil:
+		ts, err = p.timeParser.Missing(fmt.Errorf("row %d: timestamp column %q is empty", row, p.config.TimestampColumn))
+	default:
+		ts, err = p.parseTimestamp(timestamp, timestampElement)
+		if err != nil {
+			err = fmt.Errorf("row %d: %w", row, err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range p.config.DefaultTags {
+		if _, found := tags[k]; !found {
+			tags[k] = v
+		}
+	}
+	return metric.New(name, tags, fields, ts), nil
+}
+
+// parseTimestamp uses the logical type of the column if it is a timestamp
+// and the configured formats otherwise.
+func (p *Parser) parseTimestamp(v interface{}, element *pq.SchemaElement) (time.Time, error) {
+	switch element.GetType() {
+	case pq.Type_INT96:
+		if s, ok := v.(string); ok && len(s) == 12 {
+			return types.INT96ToTime(s), nil
+		}
+	case pq.Type_INT64:
+		if unit := timestampUnit(element); unit != "" && len(p.timeParser.Formats) == 0 {
+			return internal.ParseTimestamp(unit, v, "")
+		}
+	}
+	return p.timeParser.Parse(v)
+}
+
+// timestampUnit returns the unix timestamp format of timestamp columns.
+func timestampUnit(element *pq.SchemaElement) string {
+	if lt := element.GetLogicalType(); lt != nil && lt.IsSetTIMESTAMP() {
+		unit := lt.GetTIMESTAMP().GetUnit()
+		switch {
+		case unit.IsSetMILLIS():
+			return "unix_ms"
+		case unit.IsSetMICROS():
+			return "unix_us"
+		case unit.IsSetNANOS():
+			return "unix_ns"
+		}
+	}
+	if !element.IsSetConvertedType() {
+		return ""
+	}
+	switch element.GetConvertedType() {
+	case pq.ConvertedType_TIMESTAMP_MILLIS:
+		return "unix_ms"
+	case pq.ConvertedType_TIMESTAMP_MICROS:
+		return "unix_us"
+	}
+	return ""
+}
+
+// convert returns the field value of the physical value of a column.
+func convert(v interface{}, element *pq.SchemaElement) interface{} {
+	decimal := element.IsSetConvertedType() && element.GetConvertedType() == pq.ConvertedType_DECIMAL
+	scale := math.Pow10(int(element.GetScale()))
+
+	switch v := v.(type) {
+	case int32:
+		if decimal {
+			return float64(v) / scale
+		}
+		if element.IsSetConvertedType() && element.GetConvertedType() == pq.ConvertedType_UINT_32 {
+			return uint64(uint32(v))
+		}
+		return int64(v)
+	case int64:
+		if decimal {
+			return float64(v) / scale
+		}
+		if element.IsSetConvertedType() && element.GetConvertedType() == pq.ConvertedType_UINT_64 {
+			return uint64(v)
+		}
+		return v
+	case float32:
+		return float64(v)
+	case string:
+		switch {
+		case element.GetType() == pq.Type_INT96 && len(v) == 12:
+			return types.INT96ToTime(v).UnixNano()
+		case decimal:
+			return types.DECIMAL_BYTE_ARRAY_ToString([]byte(v), int(element.GetPrecision()), int(element.GetScale()))
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,291 @@
+package parquet
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go/types"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var defaultTime = func() time.Time {
+	return time.Unix(3600, 0)
+}
+
+type row struct {
+	Host    string  `parquet:"name=host, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Region  *string `parquet:"name=region, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Usage   float64 `parquet:"name=usage, type=DOUBLE"`
+	Count   int32   `parquet:"name=count, type=INT32"`
+	Healthy bool    `parquet:"name=healthy, type=BOOLEAN"`
+	Price   int64   `parquet:"name=price, type=INT64, convertedtype=DECIMAL, scale=2, precision=10"`
+	Time    int64   `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+}
+
+type sparkRow struct {
+	Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Time string `parquet:"name=time, type=INT96"`
+	Date string `parquet:"name=date, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Load int64  `parquet:"name=load, type=INT64"`
+}
+
+type nestedRow struct {
+	Host   string  `parquet:"name=host, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Disk   disk    `parquet:"name=disk"`
+	Labels []int32 `parquet:"name=labels, type=INT32, repetitiontype=REPEATED"`
+}
+
+type disk struct {
+	Free int64 `parquet:"name=free, type=INT64"`
+	Used int64 `parquet:"name=used, type=INT64"`
+}
+
+// writeFile returns a parquet file with a row group for every group of rows.
+func writeFile(t *testing.T, schema interface{}, groups ...[]interface{}) []byte {
+	var buf bytes.Buffer
+	pw, err := writer.NewParquetWriterFromWriter(&buf, schema, 1)
+	require.NoError(t, err)
+	for _, rows := range groups {
+		for _, r := range rows {
+			require.NoError(t, pw.Write(r))
+		}
+		require.NoError(t, pw.Flush(true))
+	}
+	require.NoError(t, pw.WriteStop())
+	return buf.Bytes()
+}
+
+func testFile(t *testing.T) []byte {
+	region := "eu"
+	return writeFile(t, new(row),
+		[]interface{}{
+			row{Host: "a", Region: &region, Usage: 42.5, Count: 3, Healthy: true, Price: 1234, Time: 1600000000000},
+			row{Host: "b", Usage: 21, Count: 1, Price: 5, Time: 1600000001000},
+		},
+		[]interface{}{
+			row{Host: "c", Region: &region, Usage: 0.5, Count: 7, Time: 1600000002000},
+		},
+	)
+}
+
+func TestParse(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName:      "parquet",
+		TagColumns:      []string{"host", "region"},
+		TimestampColumn: "time",
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse(testFile(t))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("parquet",
+			map[string]string{"host": "a", "region": "eu"},
+			map[string]interface{}{"usage": 42.5, "count": int64(3), "healthy": true, "price": 12.34},
+			time.Unix(1600000000, 0),
+		),
+		testutil.MustMetric("parquet",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage": 21.0, "count": int64(1), "healthy": false, "price": 0.05},
+			time.Unix(1600000001, 0),
+		),
+		testutil.MustMetric("parquet",
+			map[string]string{"host": "c", "region": "eu"},
+			map[string]interface{}{"usage": 0.5, "count": int64(7), "healthy": false, "price": 0.0},
+			time.Unix(1600000002, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseMeasurementColumn(t *testing.T) {
+	parser, err := New(&Config{
+		MeasurementColumn: "host",
+		TimeFunc:          defaultTime,
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse(testFile(t))
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		require.Equal(t, name, metrics[i].Name())
+		require.Equal(t, defaultTime(), metrics[i].Time())
+		require.False(t, metrics[i].HasField("host"))
+	}
+	v, ok := metrics[0].GetField("region")
+	require.True(t, ok)
+	require.Equal(t, "eu", v)
+	require.False(t, metrics[1].HasField("region"))
+}
+
+func TestParseSparkTimestamps(t *testing.T) {
+	ts := time.Date(2021, 10, 1, 12, 30, 0, 0, time.UTC)
+	buf := writeFile(t, new(sparkRow), []interface{}{
+		sparkRow{Name: "job", Time: types.TimeToINT96(ts), Date: "2021-10-01 14:30:00", Load: 5},
+	})
+
+	parser, err := New(&Config{
+		MetricName:      "spark",
+		TagColumns:      []string{"name"},
+		TimestampColumn: "time",
+	})
+	require.NoError(t, err)
+	metrics, err := parser.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, ts, metrics[0].Time().UTC())
+
+	// Timestamps stored as strings require a format
+	parser, err = New(&Config{
+		MetricName:      "spark",
+		TagColumns:      []string{"name"},
+		TimestampColumn: "date",
+		TimestampFormat: "2006-01-02 15:04:05",
+		Timezone:        "Europe/Berlin",
+	})
+	require.NoError(t, err)
+	metrics, err = parser.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, ts, metrics[0].Time().UTC())
+	v, ok := metrics[0].GetField("time")
+	require.True(t, ok)
+	require.Equal(t, ts.UnixNano(), v)
+
+	parser, err = New(&Config{
+		MetricName:      "spark",
+		TimestampColumn: "date",
+		TimestampFormat: "unix",
+	})
+	require.NoError(t, err)
+	_, err = parser.Parse(buf)
+	require.Error(t, err)
+}
+
+func TestParseNestedColumns(t *testing.T) {
+	buf := writeFile(t, new(nestedRow), []interface{}{
+		nestedRow{Host: "a", Disk: disk{Free: 10, Used: 5}, Labels: []int32{1, 2, 3}},
+		nestedRow{Host: "b", Disk: disk{Free: 20, Used: 1}},
+	})
+
+	parser, err := New(&Config{
+		MetricName: "disk",
+		TagColumns: []string{"host"},
+		TimeFunc:   defaultTime,
+	})
+	require.NoError(t, err)
+	metrics, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("disk",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"disk_free": int64(10), "disk_used": int64(5)},
+			defaultTime(),
+		),
+		testutil.MustMetric("disk",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"disk_free": int64(20), "disk_used": int64(1)},
+			defaultTime(),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser, err := New(&Config{MetricName: "parquet"})
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte("this is not a parquet file"))
+	require.Error(t, err)
+
+	// Truncated file
+	buf := testFile(t)
+	_, err = parser.Parse(buf[:len(buf)/2])
+	require.Error(t, err)
+
+	_, err = parser.ParseLine("a")
+	require.Error(t, err)
+}
+
+func TestNewRequiresName(t *testing.T) {
+	_, err := New(&Config{})
+	require.Error(t, err)
+}
+
+func TestStreamParser(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName:      "parquet",
+		TagColumns:      []string{"host", "region"},
+		TimestampColumn: "time",
+	})
+	require.NoError(t, err)
+
+	buf := testFile(t)
+	expected, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	sp := NewStreamParser(parser, bytes.NewReader(buf))
+	var metrics []telegraf.Metric
+	for {
+		m, err := sp.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+
+	_, err = sp.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestStreamParserBuffered(t *testing.T) {
+	parser, err := New(&Config{
+		MetricName:      "parquet",
+		TagColumns:      []string{"host", "region"},
+		TimestampColumn: "time",
+	})
+	require.NoError(t, err)
+
+	buf := testFile(t)
+	expected, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	// Readers without io.ReaderAt, like gzip readers, are read into memory
+	sp := NewStreamParser(parser, io.MultiReader(bytes.NewReader(buf)))
+	var metrics []telegraf.Metric
+	for {
+		m, err := sp.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+	testutil.RequireMetricsEqual(t, expected, metrics)
+}
+
+func TestParserConformance(t *testing.T) {
+	buf := testFile(t)
+	testutil.RunParserConformance(t, testutil.ParserConformance{
+		New: func() (testutil.Parser, error) {
+			return New(&Config{
+				MetricName:      "parquet",
+				TagColumns:      []string{"host", "region"},
+				TimestampColumn: "time",
+			})
+		},
+		Valid:   buf,
+		Invalid: buf[:len(buf)-8],
+	})
+}
@@ -0,0 +1,95 @@
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/influxdata/telegraf"
+)
+
+// StreamParser returns the rows of a parquet file read from a reader. As
+// the footer of a parquet file is located at its end, readers implementing
+// io.ReaderAt and io.Seeker, like *os.File, are read in place and other
+// readers are read into memory first. The rows are decoded one row group at a
+// time. It is not safe for concurrent use in multiple goroutines.
+type StreamParser struct {
+	parser  *Parser
+	reader  io.Reader
+	rows    *rowGroupReader
+	pending []telegraf.Metric
+}
+
+// NewStreamParser returns a StreamParser reading a parquet file from r using
+// the settings of the given parser.
+func NewStreamParser(parser *Parser, r io.Reader) *StreamParser {
+	return &StreamParser{
+		parser: parser,
+		reader: r,
+	}
+}
+
+// Next returns the next metric from the file. io.EOF is returned once all
+// row groups are read.
+func (sp *StreamParser) Next() (telegraf.Metric, error) {
+	if sp.rows == nil {
+		if sp.reader == nil {
+			return nil, io.EOF
+		}
+		r, size, err := readerAt(sp.reader)
+		sp.reader = nil
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, io.EOF
+		}
+		if sp.rows, err = sp.parser.newRowGroupReader(r, size); err != nil {
+			return nil, err
+		}
+	}
+
+	for len(sp.pending) == 0 {
+		if !sp.rows.more() {
+			sp.rows.close()
+			return nil, io.EOF
+		}
+		metrics, err := sp.rows.next()
+		if err != nil {
+			return nil, fmt.Errorf("row group %d: %w", sp.rows.rowGroup, err)
+		}
+		sp.pending = metrics
+	}
+
+	m := sp.pending[0]
+	sp.pending = sp.pending[1:]
+	return m, nil
+}
+
+// readerAt returns the reader as io.ReaderAt with the size of the file,
+// reading the remainder of the file into memory if it cannot be read in
+// place.
+func readerAt(r io.Reader) (io.ReaderAt, int64, error) {
+	if rs, ok := r.(interface {
+		io.ReaderAt
+		io.Seeker
+	}); ok {
+		// Read the file from the current offset, as the io.Reader would.
+		offset, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0, err
+		}
+		end, err := rs.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, 0, err
+		}
+		return io.NewSectionReader(rs, offset, end-offset), end - offset, nil
+	}
+
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(buf), int64(len(buf)), nil
+}
@@ -0,0 +1,163 @@
+package parsers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const (
+	// ParseModeStrict fails the whole payload on any error.
+	ParseModeStrict = "strict"
+	// ParseModeBestEffort returns all metrics that could be parsed and
+	// counts the skipped data.
+	ParseModeBestEffort = "best_effort"
+)
+
+// lineFormats are the data formats where every line of the payload can be
+// parsed on its own.
+var lineFormats = []string{"graphite", "grok", "influx", "logfmt", "wavefront"}
+
+// ParseModeParser wraps a parser to apply the configured parse mode. In
+// strict mode metrics parsed before an error are dropped together with the
+// rest of the payload. In best-effort mode the parsed metrics are kept and
+// payloads of line based formats are parsed line by line after an error, so
+// only the invalid lines are skipped. Skipped data is counted in the
+// `skipped` field of the `internal_parser` measurement.
+type ParseModeParser struct {
+	Parser     Parser
+	BestEffort bool
+
+	dataFormat string
+	lineBased  bool
+	skipped    selfstat.Stat
+}
+
+// NewParseModeParser wraps the parser created for the given config.
+func NewParseModeParser(parser Parser, config *Config) (*ParseModeParser, error) {
+	switch config.ParseMode {
+	case ParseModeStrict, ParseModeBestEffort:
+	default:
+		return nil, fmt.Errorf("invalid parse_mode %q", config.ParseMode)
+	}
+
+	lineBased := choice.Contains(config.DataFormat, lineFormats)
+	if mp, ok := parser.(MultilineParser); ok && mp.IsMultiline() {
+		lineBased = false
+	}
+
+	tags := map[string]string{
+		"input":       config.MetricName,
+		"data_format": config.DataFormat,
+	}
+	return &ParseModeParser{
+		Parser:     parser,
+		BestEffort: config.ParseMode == ParseModeBestEffort,
+		dataFormat: config.DataFormat,
+		lineBased:  lineBased,
+		skipped:    selfstat.Register("parser", "skipped", tags),
+	}, nil
+}
+
+func (p *ParseModeParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := p.Parser.Parse(buf)
+	if err == nil {
+		return metrics, nil
+	}
+	if !p.BestEffort {
+		return nil, err
+	}
+
+	skipped := 1
+	if p.lineBased {
+		metrics, skipped = p.parseLines(strings.Split(string(buf), "\n"))
+	}
+	if len(metrics) == 0 {
+		return nil, err
+	}
+
+	p.skipped.Incr(int64(skipped))
+	log.Printf("W! [parsers.%s] Skipped %d invalid entries: %v", p.dataFormat, skipped, err)
+	return metrics, nil
+}
+
+func (p *ParseModeParser) ParseLine(line string) (telegraf.Metric, error) {
+	return p.Parser.ParseLine(line)
+}
+
+func (p *ParseModeParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	metrics, err := ParseLines(p.Parser, lines)
+	if err == nil {
+		return metrics, nil
+	}
+	if !p.BestEffort {
+		return nil, err
+	}
+
+	metrics, skipped := p.parseLines(lines)
+	if skipped == 0 {
+		return metrics, nil
+	}
+
+	p.skipped.Incr(int64(skipped))
+	log.Printf("W! [parsers.%s] Skipped %d invalid entries: %v", p.dataFormat, skipped, err)
+	return metrics, nil
+}
+
+func (p *ParseModeParser) SetDefaultTags(tags map[string]string) {
+	p.Parser.SetDefaultTags(tags)
+}
+
+func (p *ParseModeParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *ParseModeParser) IsMultiline() bool {
+	return isMultiline(p.Parser)
+}
+
+func (p *ParseModeParser) IsNewLogLine(line string) bool {
+	return isNewLogLine(p.Parser, line)
+}
+
+func (p *ParseModeParser) MultilineFlushTimeout() time.Duration {
+	return multilineFlushTimeout(p.Parser)
+}
+
+func (p *ParseModeParser) MultilineLineLimit() int {
+	return multilineLineLimit(p.Parser)
+}
+
+// parseLines parses every line separately and returns the parsed metrics
+// and the number of lines failing to parse. Blank and comment lines are
+// not counted as skipped.
+func (p *ParseModeParser) parseLines(lines []string) ([]telegraf.Metric, int) {
+	metrics := make([]telegraf.Metric, 0, len(lines))
+	var skipped int
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		m, err := p.Parser.ParseLine(line)
+		if err == influx.ErrNoMetric {
+			// Comment lines of the line protocol
+			continue
+		}
+		if err != nil {
+			skipped++
+			continue
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, skipped
+}
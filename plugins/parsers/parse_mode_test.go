@@ -0,0 +1,117 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModeInvalid(t *testing.T) {
+	_, err := NewParser(&Config{
+		DataFormat: "influx",
+		ParseMode:  "lenient",
+	})
+	require.Error(t, err)
+}
+
+func TestParseModeStrictDropsPartialMetrics(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat: "graphite",
+		ParseMode:  ParseModeStrict,
+	})
+	require.NoError(t, err)
+
+	metrics, err := parser.Parse([]byte("cpu.idle 42 1600000000\ncpu.busy\n"))
+	require.Error(t, err)
+	require.Empty(t, metrics)
+}
+
+func TestParseModeBestEffortLines(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat: "influx",
+		MetricName: "parse_mode_lines",
+		ParseMode:  ParseModeBestEffort,
+	})
+	require.NoError(t, err)
+	pm, ok := parser.(*ParseModeParser)
+	require.True(t, ok)
+	before := pm.skipped.Get()
+
+	metrics, err := parser.Parse([]byte("# comment\ncpu value=1\ncpu value=\nmem value=2\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, "cpu", metrics[0].Name())
+	require.Equal(t, "mem", metrics[1].Name())
+	require.Equal(t, before+1, pm.skipped.Get())
+}
+
+func TestParseModeBestEffortNothingParsed(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat: "influx",
+		ParseMode:  ParseModeBestEffort,
+	})
+	require.NoError(t, err)
+
+	_, err = parser.Parse([]byte("cpu value=\n"))
+	require.Error(t, err)
+}
+
+func TestParseModeJSON(t *testing.T) {
+	input := []byte(`[{"value": 1, "time": 1600000000}, {"value": 2, "time": "invalid"}]`)
+
+	parser, err := NewParser(&Config{
+		DataFormat:     "json",
+		MetricName:     "json",
+		JSONTimeKey:    "time",
+		JSONTimeFormat: "unix",
+		JSONStrict:     false,
+		ParseMode:      ParseModeStrict,
+	})
+	require.NoError(t, err)
+	_, err = parser.Parse(input)
+	require.Error(t, err)
+
+	parser, err = NewParser(&Config{
+		DataFormat:     "json",
+		MetricName:     "json",
+		JSONTimeKey:    "time",
+		JSONTimeFormat: "unix",
+		JSONStrict:     true,
+		ParseMode:      ParseModeBestEffort,
+	})
+	require.NoError(t, err)
+	metrics, err := parser.Parse(input)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+}
+
+func TestParseModeBestEffortParseLinesComment(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat: "influx",
+		MetricName: "parse_mode_parse_lines",
+		ParseMode:  ParseModeBestEffort,
+	})
+	require.NoError(t, err)
+	pm, ok := parser.(*ParseModeParser)
+	require.True(t, ok)
+	before := pm.skipped.Get()
+
+	metrics, err := pm.ParseLines([]string{"# comment", "cpu value=1", "", "cpu value=", "mem value=2"})
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, "cpu", metrics[0].Name())
+	require.Equal(t, "mem", metrics[1].Name())
+	require.Equal(t, before+1, pm.skipped.Get())
+}
+
+func TestParseModeStrictParseLinesComment(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat: "influx",
+		ParseMode:  ParseModeStrict,
+	})
+	require.NoError(t, err)
+
+	metrics, err := ParseLines(parser, []string{"# comment", "cpu value=1"})
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+}
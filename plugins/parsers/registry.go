@@ -2,6 +2,8 @@ package parsers
 
 import (
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/parsers/collectd"
@@ -10,16 +12,20 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/form_urlencoded"
 	"github.com/influxdata/telegraf/plugins/parsers/graphite"
 	"github.com/influxdata/telegraf/plugins/parsers/grok"
+	"github.com/influxdata/telegraf/plugins/parsers/html_table"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/json_v2"
 	"github.com/influxdata/telegraf/plugins/parsers/logfmt"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
+	"github.com/influxdata/telegraf/plugins/parsers/otlp"
+	"github.com/influxdata/telegraf/plugins/parsers/parquet"
 	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
 	"github.com/influxdata/telegraf/plugins/parsers/prometheusremotewrite"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 	"github.com/influxdata/telegraf/plugins/parsers/wavefront"
 	"github.com/influxdata/telegraf/plugins/parsers/xpath"
+	"github.com/influxdata/telegraf/plugins/parsers/xsv"
 )
 
 type ParserFunc func() (Parser, error)
@@ -62,6 +68,77 @@ type Parser interface {
 	SetDefaultTags(tags map[string]string)
 }
 
+// StreamParser is an interface for parsers reading metrics incrementally from
+// a stream instead of requiring the whole payload in a single buffer.
+type StreamParser interface {
+	// Next returns the next metric from the stream. io.EOF is returned once
+	// the stream is exhausted. After a parse error Next can be called again
+	// to continue with the following data.
+	//
+	// Not safe for concurrent use.
+	Next() (telegraf.Metric, error)
+}
+
+// MultilineParser is an interface for parsers that are able to join several
+// physical lines into a single logical log entry. Line based inputs use it to
+// accumulate lines before handing the entry to the parser.
+type MultilineParser interface {
+	Parser
+
+	// IsMultiline returns true if the parser is configured to join lines.
+	IsMultiline() bool
+
+	// IsNewLogLine returns true if the given line starts a new log entry and
+	// false if it continues the previous one.
+	IsNewLogLine(line string) bool
+
+	// MultilineFlushTimeout returns the time after which a pending entry is
+	// handed to the parser even if no new entry has started.
+	MultilineFlushTimeout() time.Duration
+
+	// MultilineLineLimit returns the maximum number of lines joined into a
+	// single entry, zero means unlimited.
+	MultilineLineLimit() int
+}
+
+// LinesParser is an interface for parsers that are able to parse a batch of
+// lines at once, e.g. to amortize locking or reuse matching state across the
+// batch. Use ParseLines to parse lines with any parser.
+type LinesParser interface {
+	Parser
+
+	// ParseLines parses each of the given lines like ParseLine and returns
+	// the metrics of all lines. Lines without metric are skipped, parsing
+	// stops at the first error.
+	//
+	// Must be thread-safe.
+	ParseLines(lines []string) ([]telegraf.Metric, error)
+}
+
+// ParseLines parses the batch of lines with the given parser. Parsers not
+// implementing LinesParser are called with ParseLine for every line, comment
+// lines of the line protocol are skipped.
+func ParseLines(parser Parser, lines []string) ([]telegraf.Metric, error) {
+	if lp, ok := parser.(LinesParser); ok {
+		return lp.ParseLines(lines)
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(lines))
+	for _, line := range lines {
+		m, err := parser.ParseLine(line)
+		if err == influx.ErrNoMetric {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
 // Config is a struct that covers the data types needed for all parser types,
 // and can be used to instantiate _any_ of the parsers.
 type Config struct {
@@ -97,6 +174,14 @@ type Config struct {
 	// Whether to continue if a JSON object can't be coerced
 	JSONStrict bool `toml:"json_strict"`
 
+	// JSONLines parses every line of the input as a separate JSON document
+	JSONLines bool `toml:"json_lines"`
+
+	// Flattening of nested objects and arrays
+	JSONFlattenSeparator string `toml:"json_flatten_separator"`
+	JSONFlattenMaxDepth  int    `toml:"json_flatten_max_depth"`
+	JSONArrayMode        string `toml:"json_array_mode"`
+
 	// Authentication file for collectd
 	CollectdAuthFile string `toml:"collectd_auth_file"`
 	// One of none (default), sign, or encrypt
@@ -111,6 +196,9 @@ type Config struct {
 	DataType string `toml:"data_type"`
 
 	// DefaultTags are the default tags that will be added to all parsed metrics.
+	// Values may contain environment variables like ${VAR} or Go templates
+	// referencing the parsed metric, e.g. '{{ .fields.app }}', which are
+	// evaluated for every metric.
 	DefaultTags map[string]string `toml:"default_tags"`
 
 	// an optional json path containing the metric registry object
@@ -136,6 +224,22 @@ type Config struct {
 	GrokCustomPatternFiles []string `toml:"grok_custom_pattern_files"`
 	GrokTimezone           string   `toml:"grok_timezone"`
 	GrokUniqueTimestamp    string   `toml:"grok_unique_timestamp"`
+	// interval for reloading modified custom pattern files
+	GrokReloadInterval time.Duration `toml:"grok_reload_interval"`
+	// maximum time for matching a single line
+	GrokTimeout time.Duration `toml:"grok_timeout"`
+	// maximum length of a line matched
+	GrokMaxLineLength int `toml:"grok_max_line_length"`
+	// tag holding the name of the matching pattern
+	GrokPatternTag string `toml:"grok_pattern_tag"`
+	// measurement for lines not matching any pattern
+	GrokUnmatchedMeasurement string `toml:"grok_unmatched_measurement"`
+
+	// grok multiline configuration
+	GrokMultilinePattern  string        `toml:"grok_multiline_pattern"`
+	GrokMultilineNegate   bool          `toml:"grok_multiline_negate"`
+	GrokMultilineTimeout  time.Duration `toml:"grok_multiline_timeout"`
+	GrokMultilineMaxLines int           `toml:"grok_multiline_max_lines"`
 
 	//csv configuration
 	CSVColumnNames       []string `toml:"csv_column_names"`
@@ -153,9 +257,36 @@ type Config struct {
 	CSVTrimSpace         bool     `toml:"csv_trim_space"`
 	CSVSkipValues        []string `toml:"csv_skip_values"`
 
+	// XSV configuration, the csv options apply as well
+	XSVDelimiters []string `toml:"xsv_delimiters"`
+
+	// HTML table configuration
+	HTMLTableSelector          string   `toml:"html_table_selector"`
+	HTMLTableTagColumns        []string `toml:"html_table_tag_columns"`
+	HTMLTableMeasurementColumn string   `toml:"html_table_measurement_column"`
+	HTMLTableTimestampColumn   string   `toml:"html_table_timestamp_column"`
+	HTMLTableTimestampFormat   string   `toml:"html_table_timestamp_format"`
+	HTMLTableTimezone          string   `toml:"html_table_timezone"`
+
+	// Parquet configuration
+	ParquetTagColumns        []string `toml:"parquet_tag_columns"`
+	ParquetMeasurementColumn string   `toml:"parquet_measurement_column"`
+	ParquetTimestampColumn   string   `toml:"parquet_timestamp_column"`
+	ParquetTimestampFormat   string   `toml:"parquet_timestamp_format"`
+	ParquetTimezone          string   `toml:"parquet_timezone"`
+
 	// FormData configuration
 	FormUrlencodedTagKeys []string `toml:"form_urlencoded_tag_keys"`
 
+	// InfluxParserType selects the line protocol parser, either "default" or
+	// "fast"
+	InfluxParserType string `toml:"influx_parser_type"`
+
+	// OTLP configuration
+	OTLPSignal        string `toml:"otlp_signal"`
+	OTLPEncoding      string `toml:"otlp_encoding"`
+	OTLPMetricsSchema string `toml:"otlp_metrics_schema"`
+
 	// Prometheus configuration
 	PrometheusIgnoreTimestamp bool `toml:"prometheus_ignore_timestamp"`
 
@@ -170,6 +301,55 @@ type Config struct {
 
 	// JSONPath configuration
 	JSONV2Config []JSONV2Config `toml:"json_v2"`
+
+	// AutoFormats are the candidate data formats probed in order by the auto
+	// data format.
+	AutoFormats []string `toml:"auto_formats"`
+
+	// CharacterEncoding of the payload which is transcoded to UTF-8 before
+	// parsing.
+	CharacterEncoding string `toml:"character_encoding"`
+
+	// ContentEncoding of the payload, e.g. gzip, which is decompressed before
+	// decoding the character encoding and parsing.
+	ContentEncoding string `toml:"content_encoding"`
+	// MaxDecompressedSize limits the size of the decompressed payload in
+	// bytes, defaults to 500MB.
+	MaxDecompressedSize int64 `toml:"max_decompressed_size"`
+
+	// Parse error handling applying to all data formats. Errors are counted
+	// if any of these options is set.
+	ParseErrorsCount       bool   `toml:"parse_errors_count"`
+	ParseErrorsMeasurement string `toml:"parse_errors_measurement"`
+	ParseErrorsFile        string `toml:"parse_errors_file"`
+	ParseErrorsMaxLength   int    `toml:"parse_errors_max_length"`
+
+	// Timestamp handling of the json, csv, grok and xml data formats.
+	// TimestampFormats are tried in order after the format specific option,
+	// TimestampTimezone overrides the format specific timezone.
+	TimestampFormats       []string `toml:"timestamp_formats"`
+	TimestampTimezone      string   `toml:"timestamp_timezone"`
+	TimestampFallbackToNow bool     `toml:"timestamp_fallback_to_now"`
+
+	// FieldTypes maps field name globs to the type the fields are converted
+	// to after parsing, one of "int", "uint", "float", "bool" or "string".
+	FieldTypes map[string]string `toml:"field_types"`
+
+	// MeasurementKey is the field or tag the metric name is taken from.
+	MeasurementKey string `toml:"measurement_key"`
+	// MeasurementTemplate is a Go template evaluated to the metric name.
+	MeasurementTemplate string `toml:"measurement_template"`
+
+	// ParseMode is either "strict" to fail the whole payload on any error or
+	// "best_effort" to keep the metrics that could be parsed. If empty every
+	// parser keeps its own error handling.
+	ParseMode string `toml:"parse_mode"`
+
+	// NextParser is applied to the SourceField of the metrics produced by
+	// this parser.
+	NextParser *Config `toml:"next_parser"`
+	// SourceField only applies to the NextParser config.
+	SourceField string `toml:"source_field"`
 }
 
 type XPathConfig xpath.Config
@@ -180,29 +360,99 @@ type JSONV2Config struct {
 
 // NewParser returns a Parser interface based on the given config.
 func NewParser(config *Config) (Parser, error) {
+	// Default tags with templates are evaluated by a wrapper, so keep them
+	// away from the data format parser.
+	tagTemplates := hasTagTemplates(config.DefaultTags)
+	base := config
+	if tagTemplates {
+		c := *config
+		c.DefaultTags = nil
+		base = &c
+	}
+
+	parser, err := newParser(base)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ParseMode != "" {
+		parser, err = NewParseModeParser(parser, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.NextParser != nil {
+		next, err := NewParser(config.NextParser)
+		if err != nil {
+			return nil, fmt.Errorf("creating next parser failed: %w", err)
+		}
+		parser, err = NewChainParser(parser, next, config.NextParser.SourceField)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.FieldTypes) > 0 {
+		parser, err = NewFieldTypesParser(parser, config.FieldTypes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.MeasurementKey != "" || config.MeasurementTemplate != "" {
+		parser, err = NewMeasurementParser(parser, config.MeasurementKey, config.MeasurementTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tagTemplates {
+		parser, err = NewTagTemplateParser(parser, config.DefaultTags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.CharacterEncoding != "" && config.CharacterEncoding != "none" {
+		parser, err = NewDecodingParser(parser, config.CharacterEncoding)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.ContentEncoding != "" && config.ContentEncoding != "identity" {
+		parser, err = NewDecompressingParser(parser, config.ContentEncoding, config.MaxDecompressedSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.ParseErrorsCount || config.ParseErrorsMeasurement != "" || config.ParseErrorsFile != "" {
+		parser = NewDeadLetterParser(parser, config)
+	}
+	return parser, nil
+}
+
+// newParser returns the parser for the data format of the given config.
+func newParser(config *Config) (Parser, error) {
 	var err error
 	var parser Parser
 	switch config.DataFormat {
 	case "json":
-		parser, err = json.New(
-			&json.Config{
-				MetricName:   config.MetricName,
-				TagKeys:      config.TagKeys,
-				NameKey:      config.JSONNameKey,
-				StringFields: config.JSONStringFields,
-				Query:        config.JSONQuery,
-				TimeKey:      config.JSONTimeKey,
-				TimeFormat:   config.JSONTimeFormat,
-				Timezone:     config.JSONTimezone,
-				DefaultTags:  config.DefaultTags,
-				Strict:       config.JSONStrict,
-			},
-		)
+		parser, err = json.New(newJSONConfig(config))
 	case "value":
 		parser, err = NewValueParser(config.MetricName,
 			config.DataType, config.ValueFieldName, config.DefaultTags)
 	case "influx":
-		parser, err = NewInfluxParser()
+		switch config.InfluxParserType {
+		case "", "default":
+			parser, err = NewInfluxParser()
+		case "fast":
+			parser = influx.NewFastParser()
+		default:
+			err = fmt.Errorf("invalid influx parser type %q", config.InfluxParserType)
+		}
 	case "nagios":
 		parser, err = NewNagiosParser()
 	case "graphite":
@@ -224,35 +474,20 @@ func NewParser(config *Config) (Parser, error) {
 	case "wavefront":
 		parser, err = NewWavefrontParser(config.DefaultTags)
 	case "grok":
-		parser, err = newGrokParser(
-			config.MetricName,
-			config.GrokPatterns,
-			config.GrokNamedPatterns,
-			config.GrokCustomPatterns,
-			config.GrokCustomPatternFiles,
-			config.GrokTimezone,
-			config.GrokUniqueTimestamp)
+		parser, err = newGrokParser(config)
 	case "csv":
-		config := &csv.Config{
-			MetricName:        config.MetricName,
-			HeaderRowCount:    config.CSVHeaderRowCount,
-			SkipRows:          config.CSVSkipRows,
-			SkipColumns:       config.CSVSkipColumns,
-			Delimiter:         config.CSVDelimiter,
-			Comment:           config.CSVComment,
-			TrimSpace:         config.CSVTrimSpace,
-			ColumnNames:       config.CSVColumnNames,
-			ColumnTypes:       config.CSVColumnTypes,
-			TagColumns:        config.CSVTagColumns,
-			MeasurementColumn: config.CSVMeasurementColumn,
-			TimestampColumn:   config.CSVTimestampColumn,
-			TimestampFormat:   config.CSVTimestampFormat,
-			Timezone:          config.CSVTimezone,
-			DefaultTags:       config.DefaultTags,
-			SkipValues:        config.CSVSkipValues,
-		}
-
-		return csv.NewParser(config)
+		parser, err = csv.NewParser(newCSVConfig(config))
+	case "xsv":
+		parser, err = xsv.New(&xsv.Config{
+			Config:     *newCSVConfig(config),
+			Delimiters: config.XSVDelimiters,
+		})
+	case "otlp":
+		parser, err = newOTLPParser(config)
+	case "parquet":
+		parser, err = parquet.New(newParquetConfig(config))
+	case "html_table":
+		parser, err = html_table.New(newHTMLTableConfig(config))
 	case "logfmt":
 		parser, err = NewLogFmtParser(config.MetricName, config.DefaultTags)
 	case "form_urlencoded":
@@ -276,27 +511,181 @@ func NewParser(config *Config) (Parser, error) {
 			PrintDocument:       config.XPathPrintDocument,
 			DefaultTags:         config.DefaultTags,
 			Configs:             NewXPathParserConfigs(config.MetricName, config.XPathConfig),
+
+			TimestampFormats:       config.TimestampFormats,
+			TimestampTimezone:      config.TimestampTimezone,
+			TimestampFallbackToNow: config.TimestampFallbackToNow,
 		}
 	case "json_v2":
 		parser, err = NewJSONPathParser(config.JSONV2Config)
+	case "auto":
+		parser, err = newAutoParser(config)
 	default:
-		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
+		format, found := Formats[config.DataFormat]
+		if !found || format.Creator == nil {
+			return nil, fmt.Errorf("Invalid data format: %s", config.DataFormat)
+		}
+		parser, err = format.Creator(config)
 	}
 	return parser, err
 }
 
-func newGrokParser(metricName string,
-	patterns []string, nPatterns []string,
-	cPatterns string, cPatternFiles []string,
-	tZone string, uniqueTimestamp string) (Parser, error) {
+// NewStreamParser returns a StreamParser reading from r based on the given
+// config. Only the influx, json and parquet formats support streaming, for
+// json every line of the stream has to contain a separate document and
+// parquet files are decoded one row group at a time.
+func NewStreamParser(config *Config, r io.Reader) (StreamParser, error) {
+	switch config.DataFormat {
+	case "influx":
+		return &influxStreamParser{
+			parser:      influx.NewStreamParser(r),
+			defaultTags: config.DefaultTags,
+		}, nil
+	case "json":
+		parser, err := json.New(newJSONConfig(config))
+		if err != nil {
+			return nil, err
+		}
+		return json.NewStreamParser(parser, r), nil
+	case "parquet":
+		parser, err := parquet.New(newParquetConfig(config))
+		if err != nil {
+			return nil, err
+		}
+		return parquet.NewStreamParser(parser, r), nil
+	default:
+		return nil, fmt.Errorf("data format %q does not support streaming", config.DataFormat)
+	}
+}
+
+func newCSVConfig(config *Config) *csv.Config {
+	return &csv.Config{
+		MetricName:        config.MetricName,
+		HeaderRowCount:    config.CSVHeaderRowCount,
+		SkipRows:          config.CSVSkipRows,
+		SkipColumns:       config.CSVSkipColumns,
+		Delimiter:         config.CSVDelimiter,
+		Comment:           config.CSVComment,
+		TrimSpace:         config.CSVTrimSpace,
+		ColumnNames:       config.CSVColumnNames,
+		ColumnTypes:       config.CSVColumnTypes,
+		TagColumns:        config.CSVTagColumns,
+		MeasurementColumn: config.CSVMeasurementColumn,
+		TimestampColumn:   config.CSVTimestampColumn,
+		TimestampFormat:   config.CSVTimestampFormat,
+		Timezone:          timezone(config.CSVTimezone, config),
+		DefaultTags:       config.DefaultTags,
+		SkipValues:        config.CSVSkipValues,
+
+		TimestampFormats:       config.TimestampFormats,
+		TimestampFallbackToNow: config.TimestampFallbackToNow,
+	}
+}
+
+func newJSONConfig(config *Config) *json.Config {
+	strict := config.JSONStrict
+	switch config.ParseMode {
+	case ParseModeStrict:
+		strict = true
+	case ParseModeBestEffort:
+		strict = false
+	}
+
+	return &json.Config{
+		MetricName:   config.MetricName,
+		TagKeys:      config.TagKeys,
+		NameKey:      config.JSONNameKey,
+		StringFields: config.JSONStringFields,
+		Query:        config.JSONQuery,
+		TimeKey:      config.JSONTimeKey,
+		TimeFormat:   config.JSONTimeFormat,
+		Timezone:     timezone(config.JSONTimezone, config),
+		DefaultTags:  config.DefaultTags,
+		Strict:       strict,
+		Lines:        config.JSONLines,
+
+		TimeFormats:       config.TimestampFormats,
+		TimeFallbackToNow: config.TimestampFallbackToNow,
+
+		FlattenSeparator: config.JSONFlattenSeparator,
+		FlattenMaxDepth:  config.JSONFlattenMaxDepth,
+		ArrayMode:        config.JSONArrayMode,
+	}
+}
+
+func newOTLPParser(config *Config) (Parser, error) {
+	parser := &otlp.Parser{
+		Signal:        config.OTLPSignal,
+		Encoding:      config.OTLPEncoding,
+		MetricsSchema: config.OTLPMetricsSchema,
+		DefaultTags:   config.DefaultTags,
+	}
+	if err := parser.Init(); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
+
+func newParquetConfig(config *Config) *parquet.Config {
+	return &parquet.Config{
+		MetricName:        config.MetricName,
+		TagColumns:        config.ParquetTagColumns,
+		MeasurementColumn: config.ParquetMeasurementColumn,
+		TimestampColumn:   config.ParquetTimestampColumn,
+		TimestampFormat:   config.ParquetTimestampFormat,
+		Timezone:          timezone(config.ParquetTimezone, config),
+		DefaultTags:       config.DefaultTags,
+
+		TimestampFormats:       config.TimestampFormats,
+		TimestampFallbackToNow: config.TimestampFallbackToNow,
+	}
+}
+
+func newHTMLTableConfig(config *Config) *html_table.Config {
+	return &html_table.Config{
+		MetricName:        config.MetricName,
+		Selector:          config.HTMLTableSelector,
+		TagColumns:        config.HTMLTableTagColumns,
+		MeasurementColumn: config.HTMLTableMeasurementColumn,
+		TimestampColumn:   config.HTMLTableTimestampColumn,
+		TimestampFormat:   config.HTMLTableTimestampFormat,
+		Timezone:          timezone(config.HTMLTableTimezone, config),
+		DefaultTags:       config.DefaultTags,
+
+		TimestampFormats:       config.TimestampFormats,
+		TimestampFallbackToNow: config.TimestampFallbackToNow,
+	}
+}
+
+// timezone returns the format specific timezone unless it is overridden by
+// the timestamp_timezone option.
+func timezone(tz string, config *Config) string {
+	if config.TimestampTimezone != "" {
+		return config.TimestampTimezone
+	}
+	return tz
+}
+
+func newGrokParser(config *Config) (Parser, error) {
 	parser := grok.Parser{
-		Measurement:        metricName,
-		Patterns:           patterns,
-		NamedPatterns:      nPatterns,
-		CustomPatterns:     cPatterns,
-		CustomPatternFiles: cPatternFiles,
-		Timezone:           tZone,
-		UniqueTimestamp:    uniqueTimestamp,
+		Measurement:        config.MetricName,
+		Patterns:           config.GrokPatterns,
+		NamedPatterns:      config.GrokNamedPatterns,
+		CustomPatterns:     config.GrokCustomPatterns,
+		CustomPatternFiles: config.GrokCustomPatternFiles,
+		Timezone:           timezone(config.GrokTimezone, config),
+		TimestampFormats:   config.TimestampFormats,
+		UniqueTimestamp:    config.GrokUniqueTimestamp,
+		MultilinePattern:   config.GrokMultilinePattern,
+		MultilineNegate:    config.GrokMultilineNegate,
+		MultilineTimeout:   config.GrokMultilineTimeout,
+		MultilineMaxLines:  config.GrokMultilineMaxLines,
+		ReloadInterval:     config.GrokReloadInterval,
+		Timeout:            config.GrokTimeout,
+		MaxLineLength:      config.GrokMaxLineLength,
+
+		PatternTag:           config.GrokPatternTag,
+		UnmatchedMeasurement: config.GrokUnmatchedMeasurement,
 	}
 
 	err := parser.Compile()
@@ -0,0 +1,32 @@
+package parsers
+
+import (
+	"io"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+)
+
+// influxStreamParser adapts the influx stream parser to the StreamParser
+// interface.
+type influxStreamParser struct {
+	parser      *influx.StreamParser
+	defaultTags map[string]string
+}
+
+func (sp *influxStreamParser) Next() (telegraf.Metric, error) {
+	m, err := sp.parser.Next()
+	if err == influx.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range sp.defaultTags {
+		if !m.HasTag(k) {
+			m.AddTag(k, v)
+		}
+	}
+	return m, nil
+}
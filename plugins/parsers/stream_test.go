@@ -0,0 +1,52 @@
+package parsers
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStreamParserInflux(t *testing.T) {
+	sp, err := NewStreamParser(&Config{
+		DataFormat:  "influx",
+		DefaultTags: map[string]string{"host": "localhost", "region": "eu"},
+	}, strings.NewReader("cpu,region=us value=1 1\ncpu value=2 2\n"))
+	require.NoError(t, err)
+
+	m, err := sp.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "localhost", "region": "us"}, m.Tags())
+
+	m, err = sp.Next()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "localhost", "region": "eu"}, m.Tags())
+
+	_, err = sp.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestNewStreamParserJSON(t *testing.T) {
+	sp, err := NewStreamParser(&Config{
+		DataFormat: "json",
+		MetricName: "json",
+	}, strings.NewReader("{\"value\": 1}\n{\"value\": 2}\n"))
+	require.NoError(t, err)
+
+	var count int
+	for {
+		_, err := sp.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		count++
+	}
+	require.Equal(t, 2, count)
+}
+
+func TestNewStreamParserUnsupported(t *testing.T) {
+	_, err := NewStreamParser(&Config{DataFormat: "csv"}, strings.NewReader(""))
+	require.Error(t, err)
+}
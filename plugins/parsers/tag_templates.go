@@ -0,0 +1,160 @@
+package parsers
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// TagTemplateParser adds default tags whose values are evaluated for every
+// parsed metric. Values may reference environment variables using the
+// ${VAR} syntax and the parsed metric using Go templates, e.g.
+// '{{ .fields.app_name }}'. As with all default tags, tags already present in
+// the metric are kept.
+type TagTemplateParser struct {
+	Parser Parser
+
+	static    map[string]string
+	templates map[string]*template.Template
+}
+
+// envTemplateRe matches environment variable references in tag templates.
+var envTemplateRe = regexp.MustCompile(`\$\{(\w+)\}`)
+
+var tagTemplateFuncs = template.FuncMap{"env": os.Getenv}
+
+// hasTagTemplates returns true if any of the tag values needs to be evaluated
+// at parse time.
+func hasTagTemplates(tags map[string]string) bool {
+	for _, v := range tags {
+		if isTagTemplate(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTagTemplate(value string) bool {
+	return strings.Contains(value, "{{") || envTemplateRe.MatchString(value)
+}
+
+// NewTagTemplateParser wraps the parser adding the given default tags
+// evaluating the templates for every metric.
+func NewTagTemplateParser(parser Parser, tags map[string]string) (*TagTemplateParser, error) {
+	p := &TagTemplateParser{
+		Parser:    parser,
+		static:    make(map[string]string),
+		templates: make(map[string]*template.Template),
+	}
+	for k, v := range tags {
+		if !isTagTemplate(v) {
+			p.static[k] = v
+			continue
+		}
+		// Turn environment variable references into template calls so they
+		// are looked up at parse time as well.
+		text := envTemplateRe.ReplaceAllString(v, `{{ env "$1" }}`)
+		tmpl, err := template.New(k).Funcs(tagTemplateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template of default tag %q failed: %w", k, err)
+		}
+		p.templates[k] = tmpl
+	}
+	return p, nil
+}
+
+func (p *TagTemplateParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := p.Parser.Parse(buf)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range metrics {
+		p.apply(m)
+	}
+	return metrics, nil
+}
+
+func (p *TagTemplateParser) ParseLine(line string) (telegraf.Metric, error) {
+	m, err := p.Parser.ParseLine(line)
+	if err != nil || m == nil {
+		return m, err
+	}
+	p.apply(m)
+	return m, nil
+}
+
+func (p *TagTemplateParser) ParseLines(lines []string) ([]telegraf.Metric, error) {
+	metrics, err := ParseLines(p.Parser, lines)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range metrics {
+		p.apply(m)
+	}
+	return metrics, nil
+}
+
+func (p *TagTemplateParser) SetDefaultTags(tags map[string]string) {
+	p.Parser.SetDefaultTags(tags)
+}
+
+func (p *TagTemplateParser) Unwrap() Parser {
+	return p.Parser
+}
+
+func (p *TagTemplateParser) IsMultiline() bool {
+	return isMultiline(p.Parser)
+}
+
+func (p *TagTemplateParser) IsNewLogLine(line string) bool {
+	return isNewLogLine(p.Parser, line)
+}
+
+func (p *TagTemplateParser) MultilineFlushTimeout() time.Duration {
+	return multilineFlushTimeout(p.Parser)
+}
+
+func (p *TagTemplateParser) MultilineLineLimit() int {
+	return multilineLineLimit(p.Parser)
+}
+
+func (p *TagTemplateParser) process(m telegraf.Metric) ([]telegraf.Metric, error) {
+	p.apply(m)
+	return []telegraf.Metric{m}, nil
+}
+
+func (p *TagTemplateParser) apply(m telegraf.Metric) {
+	for k, v := range p.static {
+		if !m.HasTag(k) {
+			m.AddTag(k, v)
+		}
+	}
+
+	if len(p.templates) == 0 {
+		return
+	}
+	data := map[string]interface{}{
+		"name":   m.Name(),
+		"tags":   m.Tags(),
+		"fields": m.Fields(),
+	}
+	for k, tmpl := range p.templates {
+		if m.HasTag(k) {
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			continue
+		}
+		value := b.String()
+		if value == "" || value == "<no value>" {
+			continue
+		}
+		m.AddTag(k, value)
+	}
+}
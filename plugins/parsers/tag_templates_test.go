@@ -0,0 +1,66 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagTemplateParser(t *testing.T) {
+	t.Setenv("TEST_DC_REGION", "eu-west")
+
+	parser, err := NewParser(&Config{
+		DataFormat: "influx",
+		DefaultTags: map[string]string{
+			"region":  "${TEST_DC_REGION}",
+			"app":     "{{ .fields.app_name }}",
+			"source":  "{{ .name }}-{{ .tags.host }}",
+			"static":  "value",
+			"host":    "overridden",
+			"missing": "{{ .fields.unknown }}",
+		},
+	})
+	require.NoError(t, err)
+	require.IsType(t, &TagTemplateParser{}, parser)
+
+	metrics, err := parser.Parse([]byte(`cpu,host=a app_name="web",v=1`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]string{
+		"host":   "a",
+		"region": "eu-west",
+		"app":    "web",
+		"source": "cpu-a",
+		"static": "value",
+	}, metrics[0].Tags())
+}
+
+func TestTagTemplateParserParseLine(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:  "influx",
+		DefaultTags: map[string]string{"app": "{{ .fields.app_name }}"},
+	})
+	require.NoError(t, err)
+
+	m, err := parser.ParseLine(`cpu app_name="web",v=1`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"app": "web"}, m.Tags())
+}
+
+func TestTagTemplateParserStaticTags(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:  "influx",
+		DefaultTags: map[string]string{"static": "value"},
+	})
+	require.NoError(t, err)
+	_, ok := parser.(*TagTemplateParser)
+	require.False(t, ok)
+}
+
+func TestTagTemplateParserInvalidTemplate(t *testing.T) {
+	_, err := NewParser(&Config{
+		DataFormat:  "influx",
+		DefaultTags: map[string]string{"app": "{{ .fields.app_name"},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package parsers
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Wrapper is implemented by the parsers wrapping the parser of the data
+// format to apply the options common to all data formats, e.g. the parse mode
+// or the field types. The wrappers forward the optional interfaces of the
+// wrapped parser, like MultilineParser.
+type Wrapper interface {
+	// Unwrap returns the wrapped parser.
+	Unwrap() Parser
+}
+
+// Unwrap returns the parser of the data format wrapped by the given parser.
+// Inputs use it to check for the type of the parser, e.g. to handle CSV
+// headers, independent of the options set.
+func Unwrap(parser Parser) Parser {
+	for {
+		w, ok := parser.(Wrapper)
+		if !ok {
+			return parser
+		}
+		parser = w.Unwrap()
+	}
+}
+
+// The multiline helpers forward the MultilineParser interface from the
+// wrappers to the wrapped parser, parsers not implementing it are reported as
+// not joining lines.
+
+func isMultiline(parser Parser) bool {
+	mp, ok := parser.(MultilineParser)
+	return ok && mp.IsMultiline()
+}
+
+func isNewLogLine(parser Parser, line string) bool {
+	if mp, ok := parser.(MultilineParser); ok {
+		return mp.IsNewLogLine(line)
+	}
+	return true
+}
+
+func multilineFlushTimeout(parser Parser) time.Duration {
+	if mp, ok := parser.(MultilineParser); ok {
+		return mp.MultilineFlushTimeout()
+	}
+	return 0
+}
+
+func multilineLineLimit(parser Parser) int {
+	if mp, ok := parser.(MultilineParser); ok {
+		return mp.MultilineLineLimit()
+	}
+	return 0
+}
+
+// metricProcessor is implemented by the wrappers modifying the metrics
+// produced by the wrapped parser, so the modifications can be applied to the
+// metrics of a stream as well.
+type metricProcessor interface {
+	process(m telegraf.Metric) ([]telegraf.Metric, error)
+}
+
+// WrapStreamParser applies the modifications of the wrappers of the given
+// parser, e.g. the field types or the measurement name, to the metrics read
+// from the stream parser of the data format. Wrappers working on the payload,
+// like the character encoding, do not apply to streams.
+func WrapStreamParser(parser Parser, sp StreamParser) StreamParser {
+	var processors []metricProcessor
+	for {
+		if mp, ok := parser.(metricProcessor); ok {
+			// The innermost wrapper is applied first
+			processors = append([]metricProcessor{mp}, processors...)
+		}
+		w, ok := parser.(Wrapper)
+		if !ok {
+			break
+		}
+		parser = w.Unwrap()
+	}
+	if len(processors) == 0 {
+		return sp
+	}
+	return &wrappedStreamParser{parser: sp, processors: processors}
+}
+
+type wrappedStreamParser struct {
+	parser     StreamParser
+	processors []metricProcessor
+	pending    []telegraf.Metric
+}
+
+func (sp *wrappedStreamParser) Next() (telegraf.Metric, error) {
+	for len(sp.pending) == 0 {
+		m, err := sp.parser.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		metrics := []telegraf.Metric{m}
+		for _, p := range sp.processors {
+			processed := make([]telegraf.Metric, 0, len(metrics))
+			for _, m := range metrics {
+				result, err := p.process(m)
+				if err != nil {
+					return nil, err
+				}
+				processed = append(processed, result...)
+			}
+			metrics = processed
+		}
+		sp.pending = metrics
+	}
+
+	m := sp.pending[0]
+	sp.pending = sp.pending[1:]
+	return m, nil
+}
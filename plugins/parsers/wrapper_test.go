@@ -0,0 +1,101 @@
+package parsers
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestUnwrap(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:             "csv",
+		CSVHeaderRowCount:      1,
+		ParseMode:              ParseModeStrict,
+		FieldTypes:             map[string]string{"value": "float"},
+		MeasurementKey:         "name",
+		CharacterEncoding:      "utf-16le",
+		ParseErrorsMeasurement: "parse_error",
+	})
+	require.NoError(t, err)
+	require.IsType(t, &DeadLetterParser{}, parser)
+	require.IsType(t, &csv.Parser{}, Unwrap(parser))
+
+	// Parsers without wrappers are returned as is
+	require.Equal(t, Unwrap(parser), Unwrap(Unwrap(parser)))
+}
+
+func TestWrapperMultiline(t *testing.T) {
+	parser, err := NewParser(&Config{
+		DataFormat:             "grok",
+		GrokPatterns:           []string{"(?s)%{GREEDYDATA:message}"},
+		GrokMultilinePattern:   `^\s`,
+		GrokMultilineTimeout:   3 * time.Second,
+		GrokMultilineMaxLines:  10,
+		FieldTypes:             map[string]string{"message": "string"},
+		ParseErrorsMeasurement: "parse_error",
+	})
+	require.NoError(t, err)
+
+	mp, ok := parser.(MultilineParser)
+	require.True(t, ok)
+	require.True(t, mp.IsMultiline())
+	require.True(t, mp.IsNewLogLine("first line"))
+	require.False(t, mp.IsNewLogLine("  continued"))
+	require.Equal(t, 3*time.Second, mp.MultilineFlushTimeout())
+	require.Equal(t, 10, mp.MultilineLineLimit())
+
+	// Wrapped parsers without multiline support do not join lines
+	parser, err = NewParser(&Config{
+		DataFormat:             "influx",
+		ParseErrorsMeasurement: "parse_error",
+	})
+	require.NoError(t, err)
+	mp, ok = parser.(MultilineParser)
+	require.True(t, ok)
+	require.False(t, mp.IsMultiline())
+}
+
+func TestWrapStreamParser(t *testing.T) {
+	config := &Config{
+		DataFormat:     "influx",
+		FieldTypes:     map[string]string{"value": "float"},
+		MeasurementKey: "name",
+		DefaultTags:    map[string]string{"source": "{{ .tags.host }}"},
+	}
+	parser, err := NewParser(config)
+	require.NoError(t, err)
+
+	stream, err := NewStreamParser(&Config{DataFormat: "influx"},
+		strings.NewReader("cpu,host=a name=\"load\",value=1i 1\ncpu,host=b value=2i 2\n"))
+	require.NoError(t, err)
+	sp := WrapStreamParser(parser, stream)
+
+	var actual []telegraf.Metric
+	for {
+		m, err := sp.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		actual = append(actual, m)
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("load",
+			map[string]string{"host": "a", "source": "a"},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, 1)),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "b", "source": "b"},
+			map[string]interface{}{"value": 2.0},
+			time.Unix(0, 2)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
@@ -9,6 +9,7 @@ import (
 	path "github.com/antchfx/xpath"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -31,6 +32,16 @@ type Parser struct {
 	DefaultTags         map[string]string
 	Log                 telegraf.Logger
 
+	// TimestampFormats are tried in order if the timestamp does not match
+	// the format of the config.
+	TimestampFormats []string
+	// TimestampTimezone is the location of timestamps without timezone
+	// information.
+	TimestampTimezone string
+	// TimestampFallbackToNow uses the current time if the timestamp does
+	// not match any of the formats.
+	TimestampFallbackToNow bool
+
 	document dataDocument
 }
 
@@ -187,30 +198,17 @@ func (p *Parser) parseQuery(starttime time.Time, doc, selected dataNode, config
 		case string:
 			// Parse the string with the given format or assume the string to contain
 			// a unix timestamp in seconds if no format is given.
-			if len(config.TimestampFmt) < 1 || strings.HasPrefix(config.TimestampFmt, "unix") {
-				var nanoseconds int64
-
-				t, err := strconv.ParseFloat(v, 64)
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse unix timestamp: %v", err)
-				}
+			format := config.TimestampFmt
+			if format == "" {
+				format = "unix"
+			}
+			formats := append([]string{format}, p.TimestampFormats...)
+			timeParser := internal.NewTimestampParser(formats, p.TimestampTimezone, p.TimestampFallbackToNow)
+			timeParser.Now = func() time.Time { return starttime }
 
-				switch config.TimestampFmt {
-				case "unix_ns":
-					nanoseconds = int64(t)
-				case "unix_us":
-					nanoseconds = int64(t * 1e3)
-				case "unix_ms":
-					nanoseconds = int64(t * 1e6)
-				default:
-					nanoseconds = int64(t * 1e9)
-				}
-				timestamp = time.Unix(0, nanoseconds)
-			} else {
-				timestamp, err = time.Parse(config.TimestampFmt, v)
-				if err != nil {
-					return nil, fmt.Errorf("failed to query timestamp format: %v", err)
-				}
+			timestamp, err = timeParser.Parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse timestamp: %v", err)
 			}
 		case float64:
 			// Assume the value to contain a timestamp in seconds and fractions thereof.
@@ -0,0 +1,316 @@
+package xsv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	csvparser "github.com/influxdata/telegraf/plugins/parsers/csv"
+)
+
+// DefaultDelimiters are the delimiters tried if none are configured.
+var DefaultDelimiters = []string{",", "\t", "|", ";"}
+
+// sampleLines is the number of lines inspected to detect the format.
+const sampleLines = 10
+
+// Config of the parser. The csv options apply as for the csv data format,
+// except that the delimiter is detected if Delimiter is empty and the header
+// is detected if ColumnNames is empty.
+type Config struct {
+	csvparser.Config
+
+	// Delimiters are the candidates for the delimiter detection.
+	Delimiters []string
+}
+
+// Parser is a parser for delimiter separated values which detects the
+// delimiter and whether there is a header by sampling the first lines of
+// every payload. Use New to create a new instance.
+type Parser struct {
+	config     Config
+	delimiters []rune
+
+	sync.Mutex
+	// last is the parser of the last payload used for parsing single lines.
+	last *csvparser.Parser
+}
+
+func New(config *Config) (*Parser, error) {
+	candidates := config.Delimiters
+	if len(candidates) == 0 {
+		candidates = DefaultDelimiters
+	}
+	delimiters := make([]rune, 0, len(candidates))
+	for _, d := range candidates {
+		r := []rune(d)
+		if len(r) != 1 {
+			return nil, fmt.Errorf("delimiter must be a single character, got: %q", d)
+		}
+		delimiters = append(delimiters, r[0])
+	}
+
+	// Check the remaining options using a valid format.
+	check := config.Config
+	check.Delimiter = string(delimiters[0])
+	check.HeaderRowCount = 1
+	if _, err := csvparser.NewParser(&check); err != nil {
+		return nil, err
+	}
+
+	return &Parser{config: *config, delimiters: delimiters}, nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	parser, err := p.detect(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	p.last = parser
+	p.Unlock()
+
+	return parser.Parse(buf)
+}
+
+// ParseLine parses the line using the format detected for the last payload.
+// If nothing was parsed yet, the format is detected from the first line and
+// kept for the following lines. A first line looking like a header is
+// consumed as the header, returning no metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	p.Lock()
+	parser := p.last
+	if parser == nil {
+		var header bool
+		var err error
+		if parser, header, err = p.detectLine(line); err != nil {
+			p.Unlock()
+			return nil, err
+		}
+		p.last = parser
+		if header {
+			p.Unlock()
+			return nil, nil
+		}
+	}
+	p.Unlock()
+
+	return parser.ParseLine(line)
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.config.DefaultTags = tags
+}
+
+// detect returns a csv parser for the format of the given payload.
+func (p *Parser) detect(buf []byte) (*csvparser.Parser, error) {
+	lines := p.sample(buf)
+
+	c := p.config.Config
+	if c.Delimiter == "" {
+		c.Delimiter = string(p.detectDelimiter(lines))
+	}
+
+	if len(c.ColumnNames) == 0 {
+		rows := p.split(lines, []rune(c.Delimiter)[0])
+		if detectHeader(rows) {
+			c.HeaderRowCount = 1
+		} else {
+			c.HeaderRowCount = 0
+			c.ColumnNames = columnNames(rows)
+		}
+	}
+
+	return csvparser.NewParser(&c)
+}
+
+// detectLine returns a csv parser for the format of the given line and
+// whether the line is the header. As there are no rows to compare with, the
+// line is taken as header if it contains neither empty nor numeric values.
+func (p *Parser) detectLine(line string) (*csvparser.Parser, bool, error) {
+	line = strings.TrimRight(line, "\r")
+	if line == "" || (p.config.Comment != "" && strings.HasPrefix(line, p.config.Comment)) {
+		return nil, false, fmt.Errorf("cannot detect the format from line %q", line)
+	}
+
+	c := p.config.Config
+	if c.Delimiter == "" {
+		c.Delimiter = string(p.detectDelimiter([]string{line}))
+	}
+
+	var header bool
+	if len(c.ColumnNames) == 0 {
+		rows := p.split([]string{line}, []rune(c.Delimiter)[0])
+		if len(rows) == 0 {
+			return nil, false, fmt.Errorf("cannot detect the format from line %q", line)
+		}
+		header = isHeader(rows[0])
+		if header {
+			c.ColumnNames = make([]string, 0, len(rows[0]))
+			for _, name := range rows[0] {
+				if c.TrimSpace {
+					name = strings.Trim(name, " ")
+				}
+				c.ColumnNames = append(c.ColumnNames, name)
+			}
+		} else {
+			c.ColumnNames = columnNames(rows)
+		}
+	}
+	// Single lines never contain a header, it was consumed above if any.
+	c.HeaderRowCount = 0
+	c.SkipRows = 0
+
+	parser, err := csvparser.NewParser(&c)
+	return parser, header, err
+}
+
+// sample returns the first lines of the payload after the skipped rows,
+// ignoring empty and comment lines.
+func (p *Parser) sample(buf []byte) []string {
+	lines := make([]string, 0, sampleLines)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for i := 0; scanner.Scan() && len(lines) < sampleLines; i++ {
+		if i < p.config.SkipRows {
+			continue
+		}
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || (p.config.Comment != "" && strings.HasPrefix(line, p.config.Comment)) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// detectDelimiter returns the candidate occurring the same number of times in
+// every line, preferring the one occurring most often. If no candidate is
+// consistent, the one with the highest minimum count is used.
+func (p *Parser) detectDelimiter(lines []string) rune {
+	best := p.delimiters[0]
+	var bestConsistent bool
+	var bestCount int
+	for _, d := range p.delimiters {
+		minCount, consistent := -1, true
+		for _, line := range lines {
+			n := countUnquoted(line, d)
+			if minCount >= 0 && n != minCount {
+				consistent = false
+			}
+			if minCount < 0 || n < minCount {
+				minCount = n
+			}
+		}
+		if minCount <= 0 {
+			continue
+		}
+
+		if (consistent && !bestConsistent) || (consistent == bestConsistent && minCount > bestCount) {
+			best, bestConsistent, bestCount = d, consistent, minCount
+		}
+	}
+	return best
+}
+
+// split splits the lines into records, lines failing to split are ignored.
+func (p *Parser) split(lines []string, delimiter rune) [][]string {
+	rows := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		r := csv.NewReader(strings.NewReader(line))
+		r.Comma = delimiter
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = p.config.TrimSpace
+		r.LazyQuotes = true
+		record, err := r.Read()
+		if err != nil {
+			continue
+		}
+		if p.config.SkipColumns < len(record) {
+			record = record[p.config.SkipColumns:]
+		} else {
+			record = nil
+		}
+		rows = append(rows, record)
+	}
+	return rows
+}
+
+// countUnquoted counts the occurrences of the delimiter outside of quotes.
+func countUnquoted(line string, delimiter rune) int {
+	var n int
+	var quoted bool
+	for _, c := range line {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case delimiter:
+			if !quoted {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// detectHeader returns true if the first row looks like a header, i.e. it
+// contains neither empty nor numeric values and either any of the other rows
+// contains a number or none of the header values reappears in its column.
+func detectHeader(rows [][]string) bool {
+	if len(rows) < 2 || !isHeader(rows[0]) {
+		return false
+	}
+
+	var numbers, repeated bool
+	for _, row := range rows[1:] {
+		for i, value := range row {
+			value = strings.TrimSpace(value)
+			if isNumber(value) {
+				numbers = true
+			}
+			if i < len(rows[0]) && value == strings.TrimSpace(rows[0][i]) {
+				repeated = true
+			}
+		}
+	}
+	return numbers || !repeated
+}
+
+// isHeader returns true if the row contains neither empty nor numeric values.
+func isHeader(row []string) bool {
+	if len(row) == 0 {
+		return false
+	}
+	for _, name := range row {
+		name = strings.TrimSpace(name)
+		if name == "" || isNumber(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// columnNames returns generic names for files without header.
+func columnNames(rows [][]string) []string {
+	var n int
+	for _, row := range rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	names := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		names = append(names, "column_"+strconv.Itoa(i))
+	}
+	return names
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
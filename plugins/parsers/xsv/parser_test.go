@@ -0,0 +1,202 @@
+package xsv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	csvparser "github.com/influxdata/telegraf/plugins/parsers/csv"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newTestParser(t *testing.T, c csvparser.Config) *Parser {
+	c.MetricName = "xsv"
+	c.TimeFunc = func() time.Time { return time.Unix(0, 0) }
+	parser, err := New(&Config{Config: c})
+	require.NoError(t, err)
+	return parser
+}
+
+func TestDetect(t *testing.T) {
+	withHeader := []telegraf.Metric{
+		testutil.MustMetric("xsv",
+			map[string]string{"host": "db01"},
+			map[string]interface{}{"usage": 42.5, "count": int64(3)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("xsv",
+			map[string]string{"host": "db02"},
+			map[string]interface{}{"usage": 17.1, "count": int64(4)},
+			time.Unix(0, 0),
+		),
+	}
+
+	tests := []struct {
+		name       string
+		tagColumns []string
+		input      string
+		expected   []telegraf.Metric
+	}{
+		{
+			name:     "comma",
+			input:    "host,usage,count\ndb01,42.5,3\ndb02,17.1,4\n",
+			expected: withHeader,
+		},
+		{
+			name:     "tab",
+			input:    "host\tusage\tcount\ndb01\t42.5\t3\ndb02\t17.1\t4\n",
+			expected: withHeader,
+		},
+		{
+			name:     "pipe",
+			input:    "host|usage|count\r\ndb01|42.5|3\r\ndb02|17.1|4\r\n",
+			expected: withHeader,
+		},
+		{
+			name:     "semicolon with empty lines",
+			input:    "host;usage;count\ndb01;42.5;3\n\ndb02;17.1;4\n",
+			expected: withHeader,
+		},
+		{
+			name:  "quoted delimiters",
+			input: "host;usage;count\n\"db01, primary\";42.5;3\n",
+			expected: []telegraf.Metric{
+				testutil.MustMetric("xsv",
+					map[string]string{"host": "db01, primary"},
+					map[string]interface{}{"usage": 42.5, "count": int64(3)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:       "no header",
+			tagColumns: []string{"column_1"},
+			input:      "db01|42.5|3\ndb02|17.1|4\n",
+			expected: []telegraf.Metric{
+				testutil.MustMetric("xsv",
+					map[string]string{"column_1": "db01"},
+					map[string]interface{}{"column_2": 42.5, "column_3": int64(3)},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric("xsv",
+					map[string]string{"column_1": "db02"},
+					map[string]interface{}{"column_2": 17.1, "column_3": int64(4)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name:  "strings only with header",
+			input: "host\tstate\ndb01\tup\ndb02\tdown\n",
+			expected: []telegraf.Metric{
+				testutil.MustMetric("xsv",
+					map[string]string{"host": "db01"},
+					map[string]interface{}{"state": "up"},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric("xsv",
+					map[string]string{"host": "db02"},
+					map[string]interface{}{"state": "down"},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagColumns := tt.tagColumns
+			if tagColumns == nil {
+				tagColumns = []string{"host"}
+			}
+			parser := newTestParser(t, csvparser.Config{TagColumns: tagColumns})
+
+			metrics, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.expected, metrics)
+		})
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	parser := newTestParser(t, csvparser.Config{TagColumns: []string{"host"}})
+
+	// Without a previous payload the format is detected from the line.
+	m, err := parser.ParseLine("db01;42.5")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{}, m.Tags())
+	require.Equal(t, map[string]interface{}{"column_1": "db01", "column_2": 42.5}, m.Fields())
+
+	// Afterwards the format of the last payload is used.
+	_, err = parser.Parse([]byte("host|usage\ndb01|42.5\n"))
+	require.NoError(t, err)
+	m, err = parser.ParseLine("db02|17.1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "db02"}, m.Tags())
+	require.Equal(t, map[string]interface{}{"usage": 17.1}, m.Fields())
+}
+
+func TestParseLineByLine(t *testing.T) {
+	parser := newTestParser(t, csvparser.Config{TagColumns: []string{"host"}})
+
+	// The header is consumed and the format is kept for the following lines.
+	m, err := parser.ParseLine("host|usage|count")
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	var actual []telegraf.Metric
+	for _, line := range []string{"db01|42.5|3", "db02|17.1|4"} {
+		m, err := parser.ParseLine(line)
+		require.NoError(t, err)
+		actual = append(actual, m)
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("xsv",
+			map[string]string{"host": "db01"},
+			map[string]interface{}{"usage": 42.5, "count": int64(3)},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("xsv",
+			map[string]string{"host": "db02"},
+			map[string]interface{}{"usage": 17.1, "count": int64(4)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	// Lines looking like a header later on are data.
+	m, err = parser.ParseLine("db03|idle|none")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "db03"}, m.Tags())
+	require.Equal(t, map[string]interface{}{"usage": "idle", "count": "none"}, m.Fields())
+}
+
+func TestFixedOptions(t *testing.T) {
+	// A fixed delimiter and column names disable the detection.
+	parser := newTestParser(t, csvparser.Config{
+		Delimiter:      ",",
+		ColumnNames:    []string{"a", "b"},
+		HeaderRowCount: 1,
+	})
+	metrics, err := parser.Parse([]byte("x;y,z\n1;2,3\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, map[string]interface{}{"a": "1;2", "b": int64(3)}, metrics[0].Fields())
+}
+
+func TestInvalidDelimiters(t *testing.T) {
+	_, err := New(&Config{Delimiters: []string{"::"}})
+	require.Error(t, err)
+}
+
+func TestParserConformance(t *testing.T) {
+	testutil.RunParserConformance(t, testutil.ParserConformance{
+		New: func() (testutil.Parser, error) {
+			return New(&Config{Config: csvparser.Config{MetricName: "xsv"}})
+		},
+		Valid:   []byte("host|usage\ndb01|42.5\n"),
+		Invalid: []byte("host|usage\n\"db01|42.5\n"),
+	})
+}
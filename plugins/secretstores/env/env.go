@@ -0,0 +1,44 @@
+package env
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+const sampleConfig = `
+  ## Name of the store in the secret references, e.g. '@{env:DB_PASSWORD}'.
+  id = "env"
+
+  ## Prefix of the environment variables, prepended to the keys.
+  # prefix = "TELEGRAF_"
+`
+
+// Env reads the secrets from the environment variables of the agent.
+type Env struct {
+	Prefix string `toml:"prefix"`
+}
+
+func (e *Env) Description() string {
+	return "Read secrets from environment variables"
+}
+
+func (e *Env) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Env) Get(key string) (string, error) {
+	value, ok := os.LookupEnv(e.Prefix + key)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q not set", e.Prefix+key)
+	}
+	return value, nil
+}
+
+func init() {
+	secretstores.Add("env", func() telegraf.SecretStore {
+		return &Env{}
+	})
+}
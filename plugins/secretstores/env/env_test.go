@@ -0,0 +1,19 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	t.Setenv("TELEGRAF_DB_PASSWORD", "secret")
+
+	e := &Env{Prefix: "TELEGRAF_"}
+	secret, err := e.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "secret", secret)
+
+	_, err = e.Get("MISSING")
+	require.EqualError(t, err, `environment variable "TELEGRAF_MISSING" not set`)
+}
@@ -0,0 +1,61 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+const sampleConfig = `
+  ## Name of the store in the secret references, e.g. '@{file:db_password}'.
+  id = "file"
+
+  ## Directory with one file per secret, named like the key, e.g. the
+  ## secrets mounted by Docker or Kubernetes. The directory should only be
+  ## readable by the user running Telegraf.
+  directory = "/run/secrets"
+`
+
+// File reads the secrets from the files of a directory.
+type File struct {
+	Directory string `toml:"directory"`
+}
+
+func (f *File) Description() string {
+	return "Read secrets from the files of a directory"
+}
+
+func (f *File) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *File) Init() error {
+	if f.Directory == "" {
+		return errors.New("directory is required")
+	}
+	return nil
+}
+
+func (f *File) Get(key string) (string, error) {
+	// The key must name a file in the directory, not outside of it
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	content, err := os.ReadFile(filepath.Join(f.Directory, key))
+	if err != nil {
+		return "", err
+	}
+	// Editors and 'echo' add a line break to the end of the file
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func init() {
+	secretstores.Add("file", func() telegraf.SecretStore {
+		return &File{}
+	})
+}
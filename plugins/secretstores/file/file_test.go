@@ -0,0 +1,34 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db_password"), []byte(" p@ss word\n"), 0600))
+
+	f := &File{Directory: dir}
+	require.NoError(t, f.Init())
+
+	secret, err := f.Get("db_password")
+	require.NoError(t, err)
+	require.Equal(t, " p@ss word", secret)
+
+	_, err = f.Get("missing")
+	require.Error(t, err)
+
+	for _, key := range []string{"", "..", "../db_password", `sub\key`} {
+		_, err = f.Get(key)
+		require.EqualError(t, err, fmt.Sprintf("invalid key %q", key))
+	}
+}
+
+func TestInitMissingDirectory(t *testing.T) {
+	require.EqualError(t, (&File{}).Init(), "directory is required")
+}
@@ -0,0 +1,198 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+const sampleConfig = `
+  ## Name of the store in the secret references, e.g. '@{vault:password}'
+  ## for the 'password' field of the secret.
+  id = "vault"
+
+  ## Address of the Vault server.
+  url = "https://vault.example.com:8200"
+
+  ## Token to authenticate with, or file to read the token from, e.g. the
+  ## sink of a Vault agent.
+  # token = ""
+  # token_file = ""
+
+  ## Vault Enterprise namespace.
+  # namespace = ""
+
+  ## Mount point and version of the key/value secrets engine and path of
+  ## the secret holding the fields referenced.
+  # mount = "secret"
+  # kv_version = 2
+  path = "telegraf"
+
+  ## Timeout for reading the secret.
+  # timeout = "5s"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+// Vault reads the secrets from the fields of a secret of the key/value secrets
+// engine of HashiCorp Vault.
+type Vault struct {
+	URL       string          `toml:"url"`
+	Token     string          `toml:"token"`
+	TokenFile string          `toml:"token_file"`
+	Namespace string          `toml:"namespace"`
+	Mount     string          `toml:"mount"`
+	KVVersion int             `toml:"kv_version"`
+	Path      string          `toml:"path"`
+	Timeout   config.Duration `toml:"timeout"`
+	tls.ClientConfig
+
+	client *http.Client
+	fields map[string]interface{}
+}
+
+func (v *Vault) Description() string {
+	return "Read secrets from the key/value secrets engine of HashiCorp Vault"
+}
+
+func (v *Vault) SampleConfig() string {
+	return sampleConfig
+}
+
+func (v *Vault) Init() error {
+	if v.URL == "" {
+		return errors.New("url is required")
+	}
+	if v.Path == "" {
+		return errors.New("path is required")
+	}
+	if v.Token == "" && v.TokenFile == "" {
+		return errors.New("token or token_file is required")
+	}
+	if v.KVVersion != 1 && v.KVVersion != 2 {
+		return fmt.Errorf("invalid kv_version %d", v.KVVersion)
+	}
+
+	tlsCfg, err := v.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	v.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           internal.Proxy,
+			TLSClientConfig: tlsCfg,
+		},
+		Timeout: time.Duration(v.Timeout),
+	}
+	return nil
+}
+
+func (v *Vault) Get(key string) (string, error) {
+	// The secret is read once for all fields referenced
+	if v.fields == nil {
+		fields, err := v.read()
+		if err != nil {
+			return "", fmt.Errorf("reading secret %q failed: %w", v.Path, err)
+		}
+		v.fields = fields
+	}
+
+	value, ok := v.fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret %q has no field %q", v.Path, key)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("field %q of secret %q is not a string", key, v.Path)
+	}
+	return s, nil
+}
+
+// read returns the fields of the secret.
+func (v *Vault) read() (map[string]interface{}, error) {
+	token := v.Token
+	if v.TokenFile != "" {
+		content, err := os.ReadFile(v.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(string(content))
+	}
+
+	mount := strings.Trim(v.Mount, "/")
+	path := strings.Trim(v.Path, "/")
+	u := strings.TrimSuffix(v.URL, "/") + "/v1/" + mount + "/" + path
+	if v.KVVersion == 2 {
+		u = strings.TrimSuffix(v.URL, "/") + "/v1/" + mount + "/data/" + path
+	}
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	// Version 2 of the engine nests the fields with the metadata of the secret
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, err
+	}
+	data := secret.Data
+	if v.KVVersion == 2 {
+		if err := json.Unmarshal(data, &secret); err != nil {
+			return nil, err
+		}
+		data = secret.Data
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, errors.New("secret not found")
+	}
+	return fields, nil
+}
+
+func init() {
+	secretstores.Add("vault", func() telegraf.SecretStore {
+		return &Vault{
+			Mount:     "secret",
+			KVVersion: 2,
+			Timeout:   config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestGet(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/telegraf":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"p@ss","port":8086},"metadata":{"version":3}}}`))
+		case "/v1/kv/telegraf":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer ts.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s.token\n"), 0600))
+
+	v := &Vault{
+		URL:       ts.URL,
+		TokenFile: tokenFile,
+		Mount:     "secret",
+		KVVersion: 2,
+		Path:      "telegraf",
+		Timeout:   config.Duration(5 * time.Second),
+	}
+	require.NoError(t, v.Init())
+
+	secret, err := v.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "p@ss", secret)
+
+	_, err = v.Get("user")
+	require.EqualError(t, err, `secret "telegraf" has no field "user"`)
+	_, err = v.Get("port")
+	require.EqualError(t, err, `field "port" of secret "telegraf" is not a string`)
+	require.Equal(t, 1, requests)
+
+	v1 := &Vault{URL: ts.URL, Token: "s.token", Mount: "kv", KVVersion: 1, Path: "telegraf"}
+	require.NoError(t, v1.Init())
+	secret, err = v1.Get("password")
+	require.NoError(t, err)
+	require.Equal(t, "v1", secret)
+
+	denied := &Vault{URL: ts.URL, Token: "wrong", Mount: "secret", KVVersion: 2, Path: "telegraf"}
+	require.NoError(t, denied.Init())
+	_, err = denied.Get("password")
+	require.EqualError(t, err, `reading secret "telegraf" failed: 403 Forbidden: {"errors":["permission denied"]}`)
+}
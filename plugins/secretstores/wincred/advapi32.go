@@ -0,0 +1,63 @@
+//go:build windows
+// +build windows
+
+package wincred
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const credTypeGeneric = 1
+
+var (
+	modadvapi32 = windows.NewLazySystemDLL("advapi32.dll")
+
+	procCredReadW = modadvapi32.NewProc("CredReadW")
+	procCredFree  = modadvapi32.NewProc("CredFree")
+)
+
+// credential is CREDENTIALW.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// readCredential returns the password of the generic credential with the
+// target name. The password is stored as UTF-16 by the Credential Manager and
+// by 'cmdkey'.
+func readCredential(target string) (string, error) {
+	name, err := windows.UTF16PtrFromString(target)
+	if err != nil {
+		return "", err
+	}
+
+	var cred *credential
+	r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		return "", fmt.Errorf("reading credential %q failed: %w", target, err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred))) //nolint:errcheck // CredFree has no return value
+
+	if cred.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	password := make([]uint16, len(blob)/2)
+	for i := range password {
+		password[i] = uint16(blob[2*i]) | uint16(blob[2*i+1])<<8
+	}
+	return windows.UTF16ToString(password), nil
+}
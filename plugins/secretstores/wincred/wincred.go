@@ -0,0 +1,43 @@
+//go:build windows
+// +build windows
+
+package wincred
+
+import (
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+const sampleConfig = `
+  ## Name of the store in the secret references, e.g. '@{wincred:influxdb}'.
+  id = "wincred"
+
+  ## Prefix of the target names of the credentials, prepended to the keys.
+  # prefix = "telegraf:"
+`
+
+// WinCred reads the secrets from the generic credentials of the Windows
+// Credential Manager of the user running Telegraf.
+type WinCred struct {
+	Prefix string `toml:"prefix"`
+
+	read func(target string) (string, error)
+}
+
+func (w *WinCred) Description() string {
+	return "Read secrets from the Windows Credential Manager"
+}
+
+func (w *WinCred) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WinCred) Get(key string) (string, error) {
+	return w.read(w.Prefix + key)
+}
+
+func init() {
+	secretstores.Add("wincred", func() telegraf.SecretStore {
+		return &WinCred{read: readCredential}
+	})
+}
@@ -0,0 +1,12 @@
+package telegraf
+
+// SecretStore is a store of secrets, e.g. passwords or tokens, referenced in
+// the plugin settings as '@{<store id>:<key>}' instead of writing the secret
+// into the configuration file.
+type SecretStore interface {
+	PluginDescriber
+
+	// Get returns the secret with the given key or an error if the secret
+	// does not exist or cannot be read.
+	Get(key string) (string, error)
+}
@@ -0,0 +1,216 @@
+package testutil
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Parser is the interface of plugins/parsers.Parser. It is repeated here as
+// the parsers depend on this package in their tests.
+type Parser interface {
+	Parse(buf []byte) ([]telegraf.Metric, error)
+	ParseLine(line string) (telegraf.Metric, error)
+	SetDefaultTags(tags map[string]string)
+}
+
+// ParserConformance describes a parser checked by RunParserConformance.
+type ParserConformance struct {
+	// New returns a new, configured parser instance.
+	New func() (Parser, error)
+	// Valid is a payload producing at least one metric.
+	Valid []byte
+	// Invalid is a payload the parser has to reject, skipped if nil.
+	Invalid []byte
+	// Line is a single line accepted by ParseLine, skipped if empty.
+	Line string
+	// OverwritesTags skips the check that default tags do not overwrite tags
+	// of the data, for parsers keeping this legacy behavior.
+	OverwritesTags bool
+	// Concurrency is the number of goroutines parsing concurrently,
+	// defaults to 8.
+	Concurrency int
+}
+
+// RunParserConformance checks the behavior every parser registered via
+// parsers.Add has to provide:
+//   - valid payloads produce metrics with at least one field
+//   - empty payloads do not panic and never produce metrics with an error
+//   - invalid payloads are rejected
+//   - default tags are added without overwriting tags of the data or
+//     modifying the given map
+//   - Parse and ParseLine are safe for concurrent use
+//
+// Run the tests with the race detector to reliably find data races.
+func RunParserConformance(t *testing.T, c ParserConformance) {
+	require.NotNil(t, c.New, "New function required")
+	require.NotEmpty(t, c.Valid, "valid payload required")
+
+	t.Run("valid", func(t *testing.T) {
+		parser := newConformanceParser(t, c)
+		metrics, err := parser.Parse(c.Valid)
+		require.NoError(t, err)
+		require.NotEmpty(t, metrics)
+		for _, m := range metrics {
+			require.NotEmpty(t, m.Name(), "metric without name")
+			require.NotEmpty(t, m.FieldList(), "metric %q without fields", m.Name())
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		parser := newConformanceParser(t, c)
+		for _, buf := range [][]byte{nil, {}, []byte("\n")} {
+			var metrics []telegraf.Metric
+			var err error
+			require.NotPanics(t, func() { metrics, err = parser.Parse(buf) }, "payload %q", buf)
+			if err != nil {
+				require.Empty(t, metrics, "metrics returned with error for payload %q", buf)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if c.Invalid == nil {
+			t.Skip("no invalid payload")
+		}
+		parser := newConformanceParser(t, c)
+		var err error
+		require.NotPanics(t, func() { _, err = parser.Parse(c.Invalid) })
+		require.Error(t, err)
+	})
+
+	t.Run("default tags", func(t *testing.T) {
+		parser := newConformanceParser(t, c)
+		expected, err := parser.Parse(c.Valid)
+		require.NoError(t, err)
+		require.NotEmpty(t, expected)
+
+		tags := map[string]string{"conformance_default_tag": "default"}
+		// Use a tag of the data to check it is not overwritten.
+		existingKey, existingValue := "", ""
+		if tagList := expected[0].TagList(); len(tagList) > 0 {
+			existingKey, existingValue = tagList[0].Key, tagList[0].Value
+			tags[existingKey] = existingValue + "_default"
+		}
+		original := make(map[string]string, len(tags))
+		for k, v := range tags {
+			original[k] = v
+		}
+
+		parser = newConformanceParser(t, c)
+		parser.SetDefaultTags(tags)
+		metrics, err := parser.Parse(c.Valid)
+		require.NoError(t, err)
+		require.Len(t, metrics, len(expected))
+		require.Equal(t, original, tags, "default tags modified by parser")
+
+		for _, m := range metrics {
+			v, ok := m.GetTag("conformance_default_tag")
+			require.True(t, ok, "default tag missing in %q", m.Name())
+			require.Equal(t, "default", v)
+		}
+		if existingKey != "" && !c.OverwritesTags {
+			v, _ := metrics[0].GetTag(existingKey)
+			require.Equal(t, existingValue, v, "tag %q overwritten by default tag", existingKey)
+		}
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		parser := newConformanceParser(t, c)
+		expected, err := parser.Parse(c.Valid)
+		require.NoError(t, err)
+
+		var expectedLine telegraf.Metric
+		if c.Line != "" {
+			expectedLine, err = parser.ParseLine(c.Line)
+			require.NoError(t, err)
+		}
+
+		concurrency := c.Concurrency
+		if concurrency <= 0 {
+			concurrency = 8
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 2*concurrency)
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					metrics, err := parser.Parse(c.Valid)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if len(metrics) != len(expected) {
+						errs <- fmt.Errorf("expected %d metrics but got %d", len(expected), len(metrics))
+						return
+					}
+					for k := range metrics {
+						if !MetricEqual(expected[k], metrics[k], IgnoreTime()) {
+							errs <- fmt.Errorf("metric %d differs: %v", k, metrics[k])
+							return
+						}
+					}
+
+					if expectedLine == nil {
+						continue
+					}
+					m, err := parser.ParseLine(c.Line)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if !MetricEqual(expectedLine, m, IgnoreTime()) {
+						errs <- fmt.Errorf("line metric differs: %v", m)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+	})
+}
+
+func newConformanceParser(t *testing.T, c ParserConformance) Parser {
+	parser, err := c.New()
+	require.NoError(t, err)
+	require.NotNil(t, parser)
+	return parser
+}
+
+// FuzzParser is an entry point for go-fuzz. It parses the data and panics if
+// the parser violates its contract, i.e. it returns metrics without a name
+// or fields. It returns 1 if the data was parsed successfully and 0
+// otherwise, as expected by go-fuzz.
+//
+// Use it from a function in a file with the gofuzz build tag:
+//
+//	func Fuzz(data []byte) int {
+//		return testutil.FuzzParser(NewParser(), data)
+//	}
+func FuzzParser(parser Parser, data []byte) int {
+	metrics, err := parser.Parse(data)
+	if err != nil {
+		return 0
+	}
+	for _, m := range metrics {
+		if m.Name() == "" {
+			panic(fmt.Sprintf("metric without name for %q", data))
+		}
+		if len(m.FieldList()) == 0 {
+			panic(fmt.Sprintf("metric %q without fields for %q", m.Name(), data))
+		}
+	}
+	return 1
+}